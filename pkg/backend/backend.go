@@ -214,6 +214,14 @@ type SpecificDeploymentExporter interface {
 	ExportDeploymentForVersion(ctx context.Context, stack Stack, version string) (*apitype.UntypedDeployment, error)
 }
 
+// LockInspector is an interface defining an additional capability of a Backend, specifically the ability to describe
+// the updates that hold the lock on a stack. This isn't a requirement for all backends and should be checked for
+// dynamically.
+type LockInspector interface {
+	// LockHolders describes the updates that currently hold the lock on the given stack, if there are any.
+	LockHolders(ctx context.Context, stackRef StackReference) ([]LockHolder, error)
+}
+
 // UpdateOperation is a complete stack update operation (preview, update, import, refresh, or destroy).
 type UpdateOperation struct {
 	Proj               *workspace.Project
@@ -253,6 +261,13 @@ type UpdateOptions struct {
 	AutoApprove bool
 	// SkipPreview, when true, causes the preview step to be skipped.
 	SkipPreview bool
+	// PreviewOnly, when true, causes only the preview step to be run; the operation itself is not performed.
+	PreviewOnly bool
+	// Destroy selects the resources that a destroy deletes.
+	Destroy DestroyOptions
+	// WaitForLock is how long to wait for another update of the stack to finish, if one holds its lock, before
+	// failing with a ConflictingUpdateError. Zero fails immediately.
+	WaitForLock time.Duration
 }
 
 // QueryOptions configures a query to operate against a backend and the engine.
@@ -286,13 +301,14 @@ type backendClient struct {
 	backend Backend
 }
 
-// GetStackOutputs returns the outputs of the stack with the given name.
+// GetStackOutputs returns the outputs of the stack with the given name. The name may refer to a stack in another
+// backend by prefixing it with the backend's URL, as in `<backend-url>#<stack>`.
 func (c *backendClient) GetStackOutputs(ctx context.Context, name string) (resource.PropertyMap, error) {
-	ref, err := c.backend.ParseStackReference(name)
+	b, ref, err := ResolveStackReference(ctx, c.backend, name)
 	if err != nil {
 		return nil, err
 	}
-	s, err := c.backend.GetStack(ctx, ref)
+	s, err := b.GetStack(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
@@ -315,11 +331,11 @@ func (c *backendClient) GetStackOutputs(ctx context.Context, name string) (resou
 
 func (c *backendClient) GetStackResourceOutputs(
 	ctx context.Context, name string) (resource.PropertyMap, error) {
-	ref, err := c.backend.ParseStackReference(name)
+	b, ref, err := ResolveStackReference(ctx, c.backend, name)
 	if err != nil {
 		return nil, err
 	}
-	s, err := c.backend.GetStack(ctx, ref)
+	s, err := b.GetStack(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
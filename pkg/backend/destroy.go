@@ -0,0 +1,70 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// DestroyOptions selects the resources that a destroy deletes. DestroyStack applies them to the engine options, so
+// that callers of Stack.Destroy get the same behavior as `pulumi destroy` without computing the selection themselves.
+type DestroyOptions struct {
+	// Targets, if non-empty, limits the destroy to these resources.
+	Targets []resource.URN
+	// TargetDependents, when true, also destroys the resources that depend on the targets.
+	TargetDependents bool
+	// ExcludeProtected, when true, leaves protected resources and everything they depend on untouched.
+	ExcludeProtected bool
+}
+
+// ProtectedResourceURNs returns the URNs of the resources in a snapshot that cannot be changed without affecting a
+// protected resource: the protected resources themselves and everything they depend on.
+func ProtectedResourceURNs(snap *deploy.Snapshot) []resource.URN {
+	if snap == nil {
+		return nil
+	}
+
+	_, protected := graph.SeparateProtected(snap.Resources)
+	seen := make(map[resource.URN]bool)
+	var urns []resource.URN
+	for _, res := range protected {
+		if !seen[res.URN] {
+			seen[res.URN] = true
+			urns = append(urns, res.URN)
+		}
+	}
+	return urns
+}
+
+// applyDestroyOptions folds the destroy options of opts into its engine options.
+func applyDestroyOptions(ctx context.Context, s Stack, opts *UpdateOptions) error {
+	d := opts.Destroy
+	opts.Engine.DestroyTargets = append(opts.Engine.DestroyTargets, d.Targets...)
+	opts.Engine.TargetDependents = opts.Engine.TargetDependents || d.TargetDependents
+
+	if d.ExcludeProtected {
+		snap, err := s.Snapshot(ctx)
+		if err != nil {
+			return fmt.Errorf("getting snapshot: %w", err)
+		}
+		opts.Engine.ExcludeTargets = append(opts.Engine.ExcludeTargets, ProtectedResourceURNs(snap)...)
+	}
+	return nil
+}
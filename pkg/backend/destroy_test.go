@@ -0,0 +1,65 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestProtectedResourceURNs(t *testing.T) {
+	t.Parallel()
+
+	a := &resource.State{URN: "urn:pulumi:stack::project::t::a"}
+	b := &resource.State{URN: "urn:pulumi:stack::project::t::b", Dependencies: []resource.URN{a.URN}, Protect: true}
+	c := &resource.State{URN: "urn:pulumi:stack::project::t::c", Parent: b.URN}
+	d := &resource.State{URN: "urn:pulumi:stack::project::t::d"}
+
+	assert.Nil(t, ProtectedResourceURNs(nil))
+	assert.Equal(t, []resource.URN{a.URN, b.URN},
+		ProtectedResourceURNs(&deploy.Snapshot{Resources: []*resource.State{a, b, c, d}}))
+	assert.Empty(t, ProtectedResourceURNs(&deploy.Snapshot{Resources: []*resource.State{a, c, d}}))
+}
+
+func TestApplyDestroyOptions(t *testing.T) {
+	t.Parallel()
+
+	a := &resource.State{URN: "urn:pulumi:stack::project::t::a", Protect: true}
+	b := &resource.State{URN: "urn:pulumi:stack::project::t::b"}
+	s := &MockStack{
+		SnapshotF: func(ctx context.Context) (*deploy.Snapshot, error) {
+			return &deploy.Snapshot{Resources: []*resource.State{a, b}}, nil
+		},
+	}
+
+	opts := UpdateOptions{
+		Engine: engine.UpdateOptions{ExcludeTargets: []resource.URN{"urn:pulumi:stack::project::t::c"}},
+		Destroy: DestroyOptions{
+			Targets:          []resource.URN{b.URN},
+			TargetDependents: true,
+			ExcludeProtected: true,
+		},
+	}
+	assert.NoError(t, applyDestroyOptions(context.Background(), s, &opts))
+	assert.Equal(t, []resource.URN{b.URN}, opts.Engine.DestroyTargets)
+	assert.True(t, opts.Engine.TargetDependents)
+	assert.Equal(t, []resource.URN{"urn:pulumi:stack::project::t::c", a.URN}, opts.Engine.ExcludeTargets)
+}
@@ -0,0 +1,197 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/ciutil"
+)
+
+// ciAnnotator renders the log commands with which a CI system groups related lines of output, raises annotations
+// and tracks the progress of a build.
+type ciAnnotator interface {
+	// StartGroup returns the command that starts a collapsible group of lines with the given title.
+	StartGroup(title string) string
+	// EndGroup returns the command that ends the group with the given title.
+	EndGroup(title string) string
+	// Annotate returns the command that raises an annotation for a diagnostic, or "" if the severity of the
+	// diagnostic is not annotated.
+	Annotate(severity diag.Severity, title, message string) string
+	// Progress returns the command that reports that done of total resource operations have finished, the last of
+	// which is described by message, or "" if the CI system does not track progress.
+	Progress(done, total int, message string) string
+	// Summary returns the commands that record the number of resources changed by each kind of operation.
+	Summary(changes engine.ResourceChanges) []string
+}
+
+// newCIAnnotator returns the annotator for the given CI system, or nil if the system is not supported.
+func newCIAnnotator(system ciutil.SystemName) ciAnnotator {
+	switch system {
+	case ciutil.GitHubActions:
+		return githubAnnotator{}
+	case ciutil.AzurePipelines:
+		return azurePipelinesAnnotator{}
+	case ciutil.TeamCity:
+		return teamCityAnnotator{}
+	default:
+		return nil
+	}
+}
+
+// summaryOps returns the operations with a non-zero count in changes, in display order.
+func summaryOps(changes engine.ResourceChanges) []deploy.StepOp {
+	var ops []deploy.StepOp
+	for _, op := range deploy.StepOps {
+		if changes[op] > 0 {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// githubAnnotator renders GitHub Actions workflow commands.
+type githubAnnotator struct{}
+
+func (githubAnnotator) StartGroup(title string) string {
+	return "::group::" + title
+}
+
+func (githubAnnotator) EndGroup(title string) string {
+	return "::endgroup::"
+}
+
+func (githubAnnotator) Annotate(severity diag.Severity, title, message string) string {
+	var command string
+	switch severity {
+	case diag.Error:
+		command = "error"
+	case diag.Warning:
+		command = "warning"
+	default:
+		return ""
+	}
+	return "::" + command + " title=" + escapeGitHubProperty(title) + "::" + escapeGitHubData(message)
+}
+
+func (githubAnnotator) Progress(done, total int, message string) string {
+	return ""
+}
+
+func (githubAnnotator) Summary(changes engine.ResourceChanges) []string {
+	return nil
+}
+
+// escapeGitHubData escapes the message of a GitHub Actions workflow command.
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a GitHub Actions workflow command.
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// azurePipelinesAnnotator renders Azure Pipelines logging commands. The change counts of the summary are set as the
+// pipeline variables pulumi.<op>, e.g. pulumi.create.
+type azurePipelinesAnnotator struct{}
+
+func (azurePipelinesAnnotator) StartGroup(title string) string {
+	return "##[group]" + title
+}
+
+func (azurePipelinesAnnotator) EndGroup(title string) string {
+	return "##[endgroup]"
+}
+
+func (azurePipelinesAnnotator) Annotate(severity diag.Severity, title, message string) string {
+	var typ string
+	switch severity {
+	case diag.Error:
+		typ = "error"
+	case diag.Warning:
+		typ = "warning"
+	default:
+		return ""
+	}
+	return "##vso[task.logissue type=" + typ + "]" + escapeAzurePipelinesData(title+": "+message)
+}
+
+func (azurePipelinesAnnotator) Progress(done, total int, message string) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("##vso[task.setprogress value=%d;]%s", done*100/total, escapeAzurePipelinesData(message))
+}
+
+func (azurePipelinesAnnotator) Summary(changes engine.ResourceChanges) []string {
+	var commands []string
+	for _, op := range summaryOps(changes) {
+		commands = append(commands, fmt.Sprintf("##vso[task.setvariable variable=pulumi.%s]%d", op, changes[op]))
+	}
+	return commands
+}
+
+// escapeAzurePipelinesData escapes the message of an Azure Pipelines logging command.
+func escapeAzurePipelinesData(s string) string {
+	return strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// teamCityAnnotator renders TeamCity service messages. The change counts of the summary are reported as the build
+// statistics pulumi.<op>, e.g. pulumi.create.
+type teamCityAnnotator struct{}
+
+func (teamCityAnnotator) StartGroup(title string) string {
+	return "##teamcity[blockOpened name='" + escapeTeamCityValue(title) + "']"
+}
+
+func (teamCityAnnotator) EndGroup(title string) string {
+	return "##teamcity[blockClosed name='" + escapeTeamCityValue(title) + "']"
+}
+
+func (teamCityAnnotator) Annotate(severity diag.Severity, title, message string) string {
+	var status string
+	switch severity {
+	case diag.Error:
+		status = "ERROR"
+	case diag.Warning:
+		status = "WARNING"
+	default:
+		return ""
+	}
+	return "##teamcity[message text='" + escapeTeamCityValue(title+": "+message) + "' status='" + status + "']"
+}
+
+func (teamCityAnnotator) Progress(done, total int, message string) string {
+	return fmt.Sprintf("##teamcity[progressMessage '(%d/%d) %s']", done, total, escapeTeamCityValue(message))
+}
+
+func (teamCityAnnotator) Summary(changes engine.ResourceChanges) []string {
+	var commands []string
+	for _, op := range summaryOps(changes) {
+		commands = append(commands,
+			fmt.Sprintf("##teamcity[buildStatisticValue key='pulumi.%s' value='%d']", op, changes[op]))
+	}
+	return commands
+}
+
+// escapeTeamCityValue escapes a value of a TeamCity service message.
+func escapeTeamCityValue(s string) string {
+	return strings.NewReplacer("|", "||", "'", "|'", "\n", "|n", "\r", "|r", "[", "|[", "]", "|]").Replace(s)
+}
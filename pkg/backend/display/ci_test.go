@@ -0,0 +1,81 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/ciutil"
+)
+
+func TestGitHubAnnotator(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, newCIAnnotator(ciutil.Travis))
+
+	a := newCIAnnotator(ciutil.GitHubActions)
+	if !assert.NotNil(t, a) {
+		return
+	}
+	assert.Equal(t, "::group::aws:s3:Bucket (site)", a.StartGroup("aws:s3:Bucket (site)"))
+	assert.Equal(t, "::endgroup::", a.EndGroup("aws:s3:Bucket (site)"))
+	assert.Equal(t, "::error title=aws%3As3%3ABucket (site)::access denied%0Aretry at 100%25",
+		a.Annotate(diag.Error, "aws:s3:Bucket (site)", "access denied\nretry at 100%"))
+	assert.Equal(t, "::warning title=a%2Cb::deprecated", a.Annotate(diag.Warning, "a,b", "deprecated"))
+	assert.Equal(t, "", a.Annotate(diag.Info, "aws:s3:Bucket (site)", "created"))
+	assert.Equal(t, "", a.Progress(1, 2, "create aws:s3:Bucket (site)"))
+	assert.Nil(t, a.Summary(engine.ResourceChanges{deploy.OpCreate: 2}))
+}
+
+func TestAzurePipelinesAnnotator(t *testing.T) {
+	t.Parallel()
+
+	a := newCIAnnotator(ciutil.AzurePipelines)
+	if !assert.NotNil(t, a) {
+		return
+	}
+	assert.Equal(t, "##[group]aws:s3:Bucket (site)", a.StartGroup("aws:s3:Bucket (site)"))
+	assert.Equal(t, "##[endgroup]", a.EndGroup("aws:s3:Bucket (site)"))
+	assert.Equal(t, "##vso[task.logissue type=error]site: access denied%0Aretry at 100%AZP25",
+		a.Annotate(diag.Error, "site", "access denied\nretry at 100%"))
+	assert.Equal(t, "##vso[task.setprogress value=25;]create aws:s3:Bucket (site)",
+		a.Progress(1, 4, "create aws:s3:Bucket (site)"))
+	assert.Equal(t, []string{
+		"##vso[task.setvariable variable=pulumi.create]2",
+		"##vso[task.setvariable variable=pulumi.delete]1",
+	}, a.Summary(engine.ResourceChanges{deploy.OpCreate: 2, deploy.OpDelete: 1, deploy.OpUpdate: 0}))
+}
+
+func TestTeamCityAnnotator(t *testing.T) {
+	t.Parallel()
+
+	a := newCIAnnotator(ciutil.TeamCity)
+	if !assert.NotNil(t, a) {
+		return
+	}
+	assert.Equal(t, "##teamcity[blockOpened name='aws:s3:Bucket (site)']", a.StartGroup("aws:s3:Bucket (site)"))
+	assert.Equal(t, "##teamcity[blockClosed name='aws:s3:Bucket (site)']", a.EndGroup("aws:s3:Bucket (site)"))
+	assert.Equal(t, "##teamcity[message text='site: can|'t |[retry|]|n' status='WARNING']",
+		a.Annotate(diag.Warning, "site", "can't [retry]\n"))
+	assert.Equal(t, "##teamcity[progressMessage '(1/4) create aws:s3:Bucket (site)']",
+		a.Progress(1, 4, "create aws:s3:Bucket (site)"))
+	assert.Equal(t, []string{"##teamcity[buildStatisticValue key='pulumi.create' value='2']"},
+		a.Summary(engine.ResourceChanges{deploy.OpCreate: 2}))
+}
@@ -0,0 +1,254 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// costEstimatorTimeout bounds the time spent waiting for a cost estimator to respond.
+const costEstimatorTimeout = 30 * time.Second
+
+// costEstimateRequest is the JSON payload POSTed to a cost estimator: the steps of a preview.
+type costEstimateRequest struct {
+	Resources []costEstimateStep `json:"resources"`
+}
+
+// costEstimateStep is a single step of a preview. Secret inputs are replaced with "[secret]".
+type costEstimateStep struct {
+	URN       resource.URN           `json:"urn"`
+	Type      tokens.Type            `json:"type"`
+	Op        deploy.StepOp          `json:"op"`
+	Provider  string                 `json:"provider,omitempty"`
+	OldInputs map[string]interface{} `json:"oldInputs,omitempty"`
+	NewInputs map[string]interface{} `json:"newInputs,omitempty"`
+	Diffs     []resource.PropertyKey `json:"diffs,omitempty"`
+}
+
+// costEstimateResponse is the JSON document a cost estimator responds with. Resources that the estimator cannot
+// price may be left out.
+type costEstimateResponse struct {
+	Currency  string                 `json:"currency"`
+	Resources []resourceCostEstimate `json:"resources"`
+}
+
+// resourceCostEstimate is the estimated monthly cost of a single resource, as sent by a cost estimator and as
+// included in the JSON output of a preview.
+type resourceCostEstimate struct {
+	URN              resource.URN `json:"urn"`
+	MonthlyCost      float64      `json:"monthlyCost"`
+	MonthlyCostDelta float64      `json:"monthlyCostDelta"`
+}
+
+// costEstimates is the JSON form of engine.CostEstimates.
+type costEstimates struct {
+	Currency         string                 `json:"currency"`
+	MonthlyCostDelta float64                `json:"monthlyCostDelta"`
+	Resources        []resourceCostEstimate `json:"resources"`
+}
+
+// newCostEstimateStep returns the step sent to a cost estimator for the given step metadata.
+func newCostEstimateStep(m engine.StepEventMetadata) costEstimateStep {
+	step := costEstimateStep{
+		URN:      m.URN,
+		Type:     m.Type,
+		Op:       m.Op,
+		Provider: m.Provider,
+		Diffs:    m.Diffs,
+	}
+	if m.Old != nil && m.Old.State != nil {
+		step.OldInputs = costEstimateInputs(m.Old.State.Inputs)
+	}
+	if m.New != nil && m.New.State != nil {
+		step.NewInputs = costEstimateInputs(m.New.State.Inputs)
+	}
+	return step
+}
+
+// costEstimateInputs serializes a resource's inputs as they are in a preview's JSON output.
+func costEstimateInputs(inputs resource.PropertyMap) map[string]interface{} {
+	m, err := stack.SerializeProperties(MassageSecrets(inputs, false), config.NewPanicCrypter(), false /* showSecrets */)
+	if err != nil {
+		logging.V(7).Infof("not sending inputs to the cost estimator as there was an error serializing: %s", err)
+		return nil
+	}
+	return m
+}
+
+// startCostEstimator collects the steps of a preview and, before passing on its summary event, POSTs them to the
+// cost estimator at opts.CostEstimatorURL and attaches the estimates it responds with to the summary. A failure to
+// estimate costs is reported as a warning, and never fails the preview.
+func startCostEstimator(events <-chan engine.Event, done chan<- bool, opts Options) (<-chan engine.Event, chan<- bool) {
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	client := &http.Client{Timeout: costEstimatorTimeout}
+
+	outEvents, outDone := make(chan engine.Event), make(chan bool)
+	go func() {
+		defer close(done)
+
+		steps := []costEstimateStep{}
+		for e := range events {
+			switch e.Type {
+			case engine.ResourcePreEvent:
+				if m := e.Payload().(engine.ResourcePreEventPayload).Metadata; !isRootStack(m) {
+					steps = append(steps, newCostEstimateStep(m))
+				}
+			case engine.SummaryEvent:
+				p := e.Payload().(engine.SummaryEventPayload)
+				estimates, err := requestCostEstimates(client, opts.CostEstimatorURL, steps)
+				if err != nil {
+					fmt.Fprintf(stderr, "warning: could not estimate costs: %v\n", err)
+				} else {
+					p.CostEstimates = estimates
+					e = engine.NewEvent(engine.SummaryEvent, p)
+				}
+			}
+
+			outEvents <- e
+
+			if e.Type == engine.CancelEvent {
+				break
+			}
+		}
+
+		<-outDone
+	}()
+
+	return outEvents, outDone
+}
+
+// requestCostEstimates POSTs the given steps to a cost estimator and returns the estimates it responds with.
+func requestCostEstimates(client *http.Client, url string, steps []costEstimateStep) (*engine.CostEstimates, error) {
+	body, err := json.Marshal(costEstimateRequest{Resources: steps})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+
+	var response costEstimateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("reading the response from %s: %w", url, err)
+	}
+	estimates := &engine.CostEstimates{Currency: response.Currency}
+	for _, r := range response.Resources {
+		estimates.Resources = append(estimates.Resources, engine.ResourceCostEstimate{
+			URN:              r.URN,
+			MonthlyCost:      r.MonthlyCost,
+			MonthlyCostDelta: r.MonthlyCostDelta,
+		})
+	}
+	return estimates, nil
+}
+
+// costEstimatesForJSON returns the JSON form of the given estimates, or nil if there are none.
+func costEstimatesForJSON(estimates *engine.CostEstimates) *costEstimates {
+	if estimates == nil {
+		return nil
+	}
+	result := &costEstimates{Currency: estimates.Currency, Resources: []resourceCostEstimate{}}
+	for _, r := range estimates.Resources {
+		result.MonthlyCostDelta += r.MonthlyCostDelta
+		result.Resources = append(result.Resources, resourceCostEstimate{
+			URN:              r.URN,
+			MonthlyCost:      r.MonthlyCost,
+			MonthlyCostDelta: r.MonthlyCostDelta,
+		})
+	}
+	return result
+}
+
+// formatCostDelta formats a change in cost with an explicit sign.
+func formatCostDelta(delta float64) string {
+	if delta < 0 {
+		return fmt.Sprintf("-%.2f", -delta)
+	}
+	return fmt.Sprintf("+%.2f", delta)
+}
+
+// renderCostEstimates renders a table of the estimated monthly cost of each priced resource and its change, followed
+// by the total change.
+func renderCostEstimates(out io.Writer, estimates *engine.CostEstimates, opts Options) {
+	if estimates == nil || len(estimates.Resources) == 0 {
+		return
+	}
+	currency := estimates.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	fprintIgnoreError(out, opts.Color.Colorize(fmt.Sprintf("\n%sEstimated monthly costs (%s):%s\n",
+		colors.SpecHeadline, currency, colors.Reset)))
+
+	const nameColHeader, costColHeader, deltaColHeader = "Resource", "Cost", "Change"
+	rows := make([][3]string, 0, len(estimates.Resources)+1)
+	var total float64
+	for _, r := range estimates.Resources {
+		total += r.MonthlyCostDelta
+		rows = append(rows, [3]string{
+			fmt.Sprintf("%s (%s)", r.URN.Name(), r.URN.Type()),
+			fmt.Sprintf("%.2f", r.MonthlyCost),
+			formatCostDelta(r.MonthlyCostDelta),
+		})
+	}
+	rows = append(rows, [3]string{"Total", "", formatCostDelta(total)})
+
+	maxNameLen, maxCostLen := len(nameColHeader), len(costColHeader)
+	for _, row := range rows {
+		if l := len(row[0]); l > maxNameLen {
+			maxNameLen = l
+		}
+		if l := len(row[1]); l > maxCostLen {
+			maxCostLen = l
+		}
+	}
+
+	fprintIgnoreError(out, opts.Color.Colorize(
+		fmt.Sprintf("    %s%s%s%s%s\n",
+			columnHeader(nameColHeader), messagePadding(nameColHeader, maxNameLen, 2),
+			columnHeader(costColHeader), messagePadding(costColHeader, maxCostLen, 2),
+			columnHeader(deltaColHeader))))
+	for _, row := range rows {
+		fprintIgnoreError(out, opts.Color.Colorize(
+			fmt.Sprintf("    %s%s%s%s%s\n",
+				row[0], messagePadding(row[0], maxNameLen, 2),
+				row[1], messagePadding(row[1], maxCostLen, 2),
+				row[2])))
+	}
+}
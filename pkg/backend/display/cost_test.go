@@ -0,0 +1,121 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestCostEstimator(t *testing.T) {
+	t.Parallel()
+
+	root := resource.URN("urn:pulumi:dev::website::pulumi:pulumi:Stack::website-dev")
+	bucket := resource.URN("urn:pulumi:dev::website::aws:s3/bucket:Bucket::site")
+
+	var request costEstimateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, err := w.Write([]byte(`{"currency":"EUR","resources":[` +
+			`{"urn":"` + string(bucket) + `","monthlyCost":12.5,"monthlyCostDelta":12.5}]}`))
+		assert.NoError(t, err)
+	}))
+	defer server.Close()
+
+	events, done := make(chan engine.Event), make(chan bool)
+	var stderr bytes.Buffer
+	outEvents, outDone := startCostEstimator(events, done, Options{CostEstimatorURL: server.URL, Stderr: &stderr})
+
+	go func() {
+		for _, urn := range []resource.URN{root, bucket} {
+			events <- engine.NewEvent(engine.ResourcePreEvent, engine.ResourcePreEventPayload{
+				Metadata: engine.StepEventMetadata{
+					URN:  urn,
+					Op:   deploy.OpCreate,
+					Type: urn.Type(),
+					New: &engine.StepEventStateMetadata{State: &resource.State{
+						URN:    urn,
+						Inputs: resource.PropertyMap{"acl": resource.MakeSecret(resource.NewStringProperty("private"))},
+					}},
+				},
+			})
+		}
+		events <- engine.NewEvent(engine.SummaryEvent, engine.SummaryEventPayload{IsPreview: true})
+		events <- engine.NewEvent(engine.CancelEvent, nil)
+		close(events)
+	}()
+
+	var summary *engine.SummaryEventPayload
+	for e := range outEvents {
+		if e.Type == engine.SummaryEvent {
+			p := e.Payload().(engine.SummaryEventPayload)
+			summary = &p
+		}
+		if e.Type == engine.CancelEvent {
+			break
+		}
+	}
+	close(outDone)
+	<-done
+
+	assert.Empty(t, stderr.String())
+	require.Len(t, request.Resources, 1)
+	assert.Equal(t, bucket, request.Resources[0].URN)
+	assert.Equal(t, deploy.OpCreate, request.Resources[0].Op)
+	assert.Equal(t, map[string]interface{}{"acl": "[secret]"}, request.Resources[0].NewInputs)
+
+	require.NotNil(t, summary)
+	assert.Equal(t, &engine.CostEstimates{
+		Currency:  "EUR",
+		Resources: []engine.ResourceCostEstimate{{URN: bucket, MonthlyCost: 12.5, MonthlyCostDelta: 12.5}},
+	}, summary.CostEstimates)
+}
+
+func TestRenderCostEstimates(t *testing.T) {
+	t.Parallel()
+
+	estimates := &engine.CostEstimates{
+		Resources: []engine.ResourceCostEstimate{
+			{URN: "urn:pulumi:dev::website::aws:s3/bucket:Bucket::site", MonthlyCost: 12.5, MonthlyCostDelta: 12.5},
+			{URN: "urn:pulumi:dev::website::aws:ec2/instance:Instance::web", MonthlyCost: 30, MonthlyCostDelta: -15.25},
+		},
+	}
+
+	var out bytes.Buffer
+	renderCostEstimates(&out, estimates, Options{Color: colors.Never})
+	assert.Equal(t, "\n"+
+		"Estimated monthly costs (USD):\n"+
+		"    Resource                         Cost   Change\n"+
+		"    site (aws:s3/bucket:Bucket)      12.50  +12.50\n"+
+		"    web (aws:ec2/instance:Instance)  30.00  -15.25\n"+
+		"    Total                                   -2.75\n", out.String())
+
+	digest := costEstimatesForJSON(estimates)
+	assert.Equal(t, "", digest.Currency)
+	assert.Equal(t, -2.75, digest.MonthlyCostDelta)
+	assert.Len(t, digest.Resources, 2)
+	assert.Nil(t, costEstimatesForJSON(nil))
+}
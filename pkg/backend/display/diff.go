@@ -117,8 +117,14 @@ func RenderDiffEvent(action apitype.UpdateKind, event engine.Event,
 	case engine.ResourceOperationFailed:
 		return renderDiffResourceOperationFailedEvent(event.Payload().(engine.ResourceOperationFailedPayload), opts)
 	case engine.ResourceOutputsEvent:
+		if opts.Quiet {
+			return ""
+		}
 		return renderDiffResourceOutputsEvent(event.Payload().(engine.ResourceOutputsEventPayload), seen, opts)
 	case engine.ResourcePreEvent:
+		if opts.Quiet {
+			return ""
+		}
 		return renderDiffResourcePreEvent(event.Payload().(engine.ResourcePreEventPayload), seen, opts)
 	case engine.DiagEvent:
 		return renderDiffDiagEvent(event.Payload().(engine.DiagEventPayload), opts)
@@ -230,6 +236,9 @@ func renderSummaryEvent(action apitype.UpdateKind, event engine.SummaryEventPayl
 	// Print policy packs loaded. Data is rendered as a table of {policy-pack-name, version}.
 	renderPolicyPacks(out, event.PolicyPacks, opts)
 
+	// Print the estimated costs of the changes, if a cost estimator ran.
+	renderCostEstimates(out, event.CostEstimates, opts)
+
 	// For actual deploys, we print some additional summary information
 	if !event.IsPreview {
 		// Round up to the nearest second.  It's not useful to spit out time with 9 digits of
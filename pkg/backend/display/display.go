@@ -39,9 +39,27 @@ func ShowEvents(
 	op string, action apitype.UpdateKind, stack tokens.QName, proj tokens.PackageName,
 	events <-chan engine.Event, done chan<- bool, opts Options, isPreview bool) {
 
+	opts = opts.withVerbosity()
+
+	// Cost estimates are attached to the summary event, so this stage must come before any that record or show it.
+	if opts.CostEstimatorURL != "" && isPreview {
+		events, done = startCostEstimator(events, done, opts)
+	}
+	if opts.ProfileResources && !isPreview {
+		events, done = startResourceProfiler(events, done, opts)
+	}
 	if opts.EventLogPath != "" {
 		events, done = startEventLogger(events, done, opts)
 	}
+	if opts.EventStream != nil {
+		events, done = startEventStreamer(opts.EventStream, events, done, opts, nil)
+	}
+	if opts.JUnitReportPath != "" {
+		events, done = startJUnitReporter(fmt.Sprintf("%s/%s %s", proj, stack, action), events, done, opts)
+	}
+	if len(opts.EventSinks) > 0 {
+		events, done = startEventSinks(events, done, opts)
+	}
 
 	streamPreview := cmdutil.IsTruthy(os.Getenv("PULUMI_ENABLE_STREAMING_JSON_PREVIEW"))
 
@@ -80,43 +98,63 @@ func logJSONEvent(encoder *json.Encoder, event engine.Event, opts Options, seq i
 	// If opts.Color == "never" (i.e. NO_COLOR is specified or --color=never), clean up the color directives
 	// from the emitted events.
 	if opts.Color == colors.Never {
-		switch {
-		case apiEvent.DiagnosticEvent != nil:
-			apiEvent.DiagnosticEvent.Message = colors.Never.Colorize(apiEvent.DiagnosticEvent.Message)
-			apiEvent.DiagnosticEvent.Prefix = colors.Never.Colorize(apiEvent.DiagnosticEvent.Prefix)
-			apiEvent.DiagnosticEvent.Color = string(colors.Never)
-		case apiEvent.StdoutEvent != nil:
-			apiEvent.StdoutEvent.Message = colors.Never.Colorize(apiEvent.StdoutEvent.Message)
-			apiEvent.StdoutEvent.Color = string(colors.Never)
-		case apiEvent.PolicyEvent != nil:
-			apiEvent.PolicyEvent.Message = colors.Never.Colorize(apiEvent.PolicyEvent.Message)
-			apiEvent.PolicyEvent.Color = string(colors.Never)
-		}
+		uncolorizeEngineEvent(&apiEvent)
 	}
 
 	return encoder.Encode(apiEvent)
 }
 
+// uncolorizeEngineEvent removes the color directives from the messages of an event.
+func uncolorizeEngineEvent(apiEvent *apitype.EngineEvent) {
+	switch {
+	case apiEvent.DiagnosticEvent != nil:
+		apiEvent.DiagnosticEvent.Message = colors.Never.Colorize(apiEvent.DiagnosticEvent.Message)
+		apiEvent.DiagnosticEvent.Prefix = colors.Never.Colorize(apiEvent.DiagnosticEvent.Prefix)
+		apiEvent.DiagnosticEvent.Color = string(colors.Never)
+	case apiEvent.StdoutEvent != nil:
+		apiEvent.StdoutEvent.Message = colors.Never.Colorize(apiEvent.StdoutEvent.Message)
+		apiEvent.StdoutEvent.Color = string(colors.Never)
+	case apiEvent.PolicyEvent != nil:
+		apiEvent.PolicyEvent.Message = colors.Never.Colorize(apiEvent.PolicyEvent.Message)
+		apiEvent.PolicyEvent.Color = string(colors.Never)
+	}
+}
+
 func startEventLogger(events <-chan engine.Event, done chan<- bool, opts Options) (<-chan engine.Event, chan<- bool) {
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
 	// Before moving further, attempt to open the log file.
 	logFile, err := os.Create(opts.EventLogPath)
 	if err != nil {
-		logging.V(7).Infof("could not create event log: %v", err)
+		fmt.Fprintf(stderr, "warning: could not create event log: %v\n", err)
 		return events, done
 	}
 
+	return startEventStreamer(logFile, events, done, opts, func() {
+		contract.IgnoreError(logFile.Close())
+	})
+}
+
+// startEventStreamer writes each of the events that pass through it to w as a line of JSON, and calls closer, if
+// any, once all events have been received.
+func startEventStreamer(w io.Writer, events <-chan engine.Event, done chan<- bool, opts Options,
+	closer func()) (<-chan engine.Event, chan<- bool) {
+
 	outEvents, outDone := make(chan engine.Event), make(chan bool)
 	go func() {
 		defer close(done)
-		defer func() {
-			contract.IgnoreError(logFile.Close())
-		}()
+		if closer != nil {
+			defer closer()
+		}
 
 		sequence := 0
-		encoder := json.NewEncoder(logFile)
+		encoder := json.NewEncoder(w)
 		encoder.SetEscapeHTML(false)
 		for e := range events {
-			if err = logJSONEvent(encoder, e, opts, sequence); err != nil {
+			if err := logJSONEvent(encoder, e, opts, sequence); err != nil {
 				logging.V(7).Infof("failed to log event: %v", err)
 			}
 			sequence++
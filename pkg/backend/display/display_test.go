@@ -0,0 +1,93 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+)
+
+func TestEventLogger(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "events.json")
+	events, done := make(chan engine.Event), make(chan bool)
+	outEvents, outDone := startEventLogger(events, done, Options{EventLogPath: path, Color: colors.Never})
+
+	go func() {
+		events <- engine.NewEvent(engine.PreludeEvent, engine.PreludeEventPayload{Config: map[string]string{}})
+		events <- engine.NewEvent(engine.DiagEvent, engine.DiagEventPayload{
+			Message:  colors.Red + "oops" + colors.Reset,
+			Severity: diag.Warning,
+		})
+		events <- engine.NewEvent(engine.CancelEvent, nil)
+		close(events)
+	}()
+	for e := range outEvents {
+		if e.Type == engine.CancelEvent {
+			break
+		}
+	}
+	close(outDone)
+	<-done
+
+	// Each event is logged as a line of JSON in the stable apitype.EngineEvent format.
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var logged []apitype.EngineEvent
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		var e apitype.EngineEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &e))
+		logged = append(logged, e)
+	}
+	require.Len(t, logged, 3)
+	for i, e := range logged {
+		assert.Equal(t, i, e.Sequence)
+	}
+	assert.NotNil(t, logged[0].PreludeEvent)
+	require.NotNil(t, logged[1].DiagnosticEvent)
+	assert.Equal(t, "oops", logged[1].DiagnosticEvent.Message)
+	assert.Equal(t, "warning", logged[1].DiagnosticEvent.Severity)
+	assert.NotNil(t, logged[2].CancelEvent)
+}
+
+func TestEventLoggerCreateError(t *testing.T) {
+	t.Parallel()
+
+	var stderr bytes.Buffer
+	events, done := make(chan engine.Event), make(chan bool)
+	outEvents, outDone := startEventLogger(events, done, Options{
+		EventLogPath: filepath.Join(t.TempDir(), "missing", "events.json"),
+		Stderr:       &stderr,
+	})
+
+	// If the log can't be created, events are passed straight through and a warning is written.
+	assert.Equal(t, (<-chan engine.Event)(events), outEvents)
+	assert.Equal(t, (chan<- bool)(done), outDone)
+	assert.Contains(t, stderr.String(), "warning: could not create event log")
+}
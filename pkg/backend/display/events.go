@@ -2,12 +2,18 @@ package display
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
 
@@ -220,3 +226,226 @@ func convertStepEventStateMetadata(md *engine.StepEventStateMetadata) *apitype.S
 		InitErrors: md.InitErrors,
 	}
 }
+
+// ConvertJSONEvent converts an apitype.EngineEvent, such as one read from an event log, back into an engine.Event.
+// isPreview is recorded in the prelude and summary events, which don't carry it in their API form.
+//
+// IMPORTANT: ConvertEngineEvent is lossy, so the converted event may lack details of the original, e.g. secret values
+// are "[secret]" and the duration of a step is unknown.
+func ConvertJSONEvent(apiEvent apitype.EngineEvent, isPreview bool) (engine.Event, error) {
+	switch {
+	case apiEvent.CancelEvent != nil:
+		return engine.NewEvent(engine.CancelEvent, nil), nil
+
+	case apiEvent.StdoutEvent != nil:
+		p := apiEvent.StdoutEvent
+		return engine.NewEvent(engine.StdoutColorEvent, engine.StdoutEventPayload{
+			Message: p.Message,
+			Color:   colors.Colorization(p.Color),
+		}), nil
+
+	case apiEvent.DiagnosticEvent != nil:
+		p := apiEvent.DiagnosticEvent
+		return engine.NewEvent(engine.DiagEvent, engine.DiagEventPayload{
+			URN:       resource.URN(p.URN),
+			Prefix:    p.Prefix,
+			Message:   p.Message,
+			Color:     colors.Colorization(p.Color),
+			Severity:  diag.Severity(p.Severity),
+			StreamID:  int32(p.StreamID),
+			Ephemeral: p.Ephemeral,
+		}), nil
+
+	case apiEvent.PolicyEvent != nil:
+		p := apiEvent.PolicyEvent
+		return engine.NewEvent(engine.PolicyViolationEvent, engine.PolicyViolationEventPayload{
+			ResourceURN:       resource.URN(p.ResourceURN),
+			Message:           p.Message,
+			Color:             colors.Colorization(p.Color),
+			PolicyName:        p.PolicyName,
+			PolicyPackName:    p.PolicyPackName,
+			PolicyPackVersion: p.PolicyPackVersion,
+			EnforcementLevel:  apitype.EnforcementLevel(p.EnforcementLevel),
+		}), nil
+
+	case apiEvent.PreludeEvent != nil:
+		cfg := make(map[string]string)
+		for k, v := range apiEvent.PreludeEvent.Config {
+			cfg[k] = v
+		}
+		return engine.NewEvent(engine.PreludeEvent, engine.PreludeEventPayload{
+			IsPreview: isPreview,
+			Config:    cfg,
+		}), nil
+
+	case apiEvent.SummaryEvent != nil:
+		p := apiEvent.SummaryEvent
+		changes := make(engine.ResourceChanges)
+		for op, count := range p.ResourceChanges {
+			changes[deploy.StepOp(op)] = count
+		}
+		return engine.NewEvent(engine.SummaryEvent, engine.SummaryEventPayload{
+			IsPreview:       isPreview,
+			MaybeCorrupt:    p.MaybeCorrupt,
+			Duration:        time.Duration(p.DurationSeconds) * time.Second,
+			ResourceChanges: changes,
+			PolicyPacks:     p.PolicyPacks,
+		}), nil
+
+	case apiEvent.ResourcePreEvent != nil:
+		p := apiEvent.ResourcePreEvent
+		md, err := convertJSONStepEventMetadata(p.Metadata)
+		if err != nil {
+			return engine.Event{}, err
+		}
+		return engine.NewEvent(engine.ResourcePreEvent, engine.ResourcePreEventPayload{
+			Metadata: md,
+			Planning: p.Planning,
+		}), nil
+
+	case apiEvent.ResOutputsEvent != nil:
+		p := apiEvent.ResOutputsEvent
+		md, err := convertJSONStepEventMetadata(p.Metadata)
+		if err != nil {
+			return engine.Event{}, err
+		}
+		return engine.NewEvent(engine.ResourceOutputsEvent, engine.ResourceOutputsEventPayload{
+			Metadata: md,
+			Planning: p.Planning,
+		}), nil
+
+	case apiEvent.ResOpFailedEvent != nil:
+		p := apiEvent.ResOpFailedEvent
+		md, err := convertJSONStepEventMetadata(p.Metadata)
+		if err != nil {
+			return engine.Event{}, err
+		}
+		return engine.NewEvent(engine.ResourceOperationFailed, engine.ResourceOperationFailedPayload{
+			Metadata: md,
+			Status:   resource.Status(p.Status),
+			Steps:    p.Steps,
+		}), nil
+
+	default:
+		return engine.Event{}, fmt.Errorf("unknown event %d", apiEvent.Sequence)
+	}
+}
+
+func convertJSONStepEventMetadata(md apitype.StepEventMetadata) (engine.StepEventMetadata, error) {
+	keys := make([]resource.PropertyKey, len(md.Keys))
+	for i, v := range md.Keys {
+		keys[i] = resource.PropertyKey(v)
+	}
+	var diffs []resource.PropertyKey
+	for _, v := range md.Diffs {
+		diffs = append(diffs, resource.PropertyKey(v))
+	}
+	var detailedDiff map[string]plugin.PropertyDiff
+	if md.DetailedDiff != nil {
+		detailedDiff = make(map[string]plugin.PropertyDiff)
+		for k, v := range md.DetailedDiff {
+			var d plugin.DiffKind
+			switch v.Kind {
+			case apitype.DiffAdd:
+				d = plugin.DiffAdd
+			case apitype.DiffAddReplace:
+				d = plugin.DiffAddReplace
+			case apitype.DiffDelete:
+				d = plugin.DiffDelete
+			case apitype.DiffDeleteReplace:
+				d = plugin.DiffDeleteReplace
+			case apitype.DiffUpdate:
+				d = plugin.DiffUpdate
+			case apitype.DiffUpdateReplace:
+				d = plugin.DiffUpdateReplace
+			default:
+				return engine.StepEventMetadata{}, fmt.Errorf("unrecognized diff kind %q", v.Kind)
+			}
+			detailedDiff[k] = plugin.PropertyDiff{
+				Kind:      d,
+				InputDiff: v.InputDiff,
+			}
+		}
+	}
+
+	oldState, err := convertJSONStepEventStateMetadata(md.Old)
+	if err != nil {
+		return engine.StepEventMetadata{}, err
+	}
+	newState, err := convertJSONStepEventStateMetadata(md.New)
+	if err != nil {
+		return engine.StepEventMetadata{}, err
+	}
+	res := newState
+	if res == nil {
+		res = oldState
+	}
+
+	return engine.StepEventMetadata{
+		Op:   deploy.StepOp(md.Op),
+		URN:  resource.URN(md.URN),
+		Type: tokens.Type(md.Type),
+
+		Old: oldState,
+		New: newState,
+		Res: res,
+
+		Keys:         keys,
+		Diffs:        diffs,
+		DetailedDiff: detailedDiff,
+		Logical:      md.Logical,
+		Provider:     md.Provider,
+	}, nil
+}
+
+// blindedSecretDecrypter decrypts the secrets blinded by convertStepEventStateMetadata into the string "[secret]".
+type blindedSecretDecrypter struct{}
+
+func (blindedSecretDecrypter) DecryptValue(ciphertext string) (string, error) {
+	return `"[secret]"`, nil
+}
+
+// convertJSONStepEventStateMetadata converts the API type for a resource's state back into the internal
+// StepEventStateMetadata. Secret values, which were blinded when the API type was written, are "[secret]".
+func convertJSONStepEventStateMetadata(md *apitype.StepEventStateMetadata) (*engine.StepEventStateMetadata, error) {
+	if md == nil {
+		return nil, nil
+	}
+
+	inputs, err := stack.DeserializeProperties(md.Inputs, blindedSecretDecrypter{}, config.BlindingCrypter)
+	if err != nil {
+		return nil, err
+	}
+	outputs, err := stack.DeserializeProperties(md.Outputs, blindedSecretDecrypter{}, config.BlindingCrypter)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &resource.State{
+		Type:       tokens.Type(md.Type),
+		URN:        resource.URN(md.URN),
+		Custom:     md.Custom,
+		Delete:     md.Delete,
+		ID:         resource.ID(md.ID),
+		Parent:     resource.URN(md.Parent),
+		Protect:    md.Protect,
+		Inputs:     inputs,
+		Outputs:    outputs,
+		Provider:   md.Provider,
+		InitErrors: md.InitErrors,
+	}
+	return &engine.StepEventStateMetadata{
+		State:      state,
+		Type:       state.Type,
+		URN:        state.URN,
+		Custom:     state.Custom,
+		Delete:     state.Delete,
+		ID:         state.ID,
+		Parent:     state.Parent,
+		Protect:    state.Protect,
+		Inputs:     inputs,
+		Outputs:    outputs,
+		Provider:   state.Provider,
+		InitErrors: state.InitErrors,
+	}, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+)
+
+func TestConvertJSONEvent(t *testing.T) {
+	t.Parallel()
+
+	urn := resource.URN("urn:pulumi:dev::website::aws:s3/bucket:Bucket::site")
+	state := &engine.StepEventStateMetadata{
+		Type: urn.Type(),
+		URN:  urn,
+		ID:   "site-1234",
+		Inputs: resource.PropertyMap{
+			"acl":      resource.NewStringProperty("private"),
+			"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		},
+		Outputs: resource.PropertyMap{"arn": resource.NewStringProperty("arn:aws:s3:::site")},
+	}
+	original := engine.NewEvent(engine.ResourceOutputsEvent, engine.ResourceOutputsEventPayload{
+		Metadata: engine.StepEventMetadata{
+			Op:           deploy.OpUpdate,
+			URN:          urn,
+			Type:         urn.Type(),
+			Old:          state,
+			New:          state,
+			Diffs:        []resource.PropertyKey{"acl"},
+			DetailedDiff: map[string]plugin.PropertyDiff{"acl": {Kind: plugin.DiffUpdate, InputDiff: true}},
+		},
+	})
+
+	apiEvent, err := ConvertEngineEvent(original)
+	require.NoError(t, err)
+	converted, err := ConvertJSONEvent(apiEvent, false)
+	require.NoError(t, err)
+
+	require.Equal(t, engine.ResourceOutputsEvent, converted.Type)
+	md := converted.Payload().(engine.ResourceOutputsEventPayload).Metadata
+	assert.Equal(t, deploy.OpUpdate, md.Op)
+	assert.Equal(t, urn, md.URN)
+	assert.Equal(t, []resource.PropertyKey{"acl"}, md.Diffs)
+	assert.Equal(t, plugin.PropertyDiff{Kind: plugin.DiffUpdate, InputDiff: true}, md.DetailedDiff["acl"])
+	require.NotNil(t, md.New)
+	assert.Equal(t, md.New, md.Res)
+	assert.Equal(t, resource.ID("site-1234"), md.New.State.ID)
+	assert.Equal(t, "private", md.New.Inputs["acl"].StringValue())
+	assert.Equal(t, "arn:aws:s3:::site", md.New.Outputs["arn"].StringValue())
+	// Secrets are blinded when events are converted to their API form, and can't be recovered.
+	assert.Equal(t, "[secret]", md.New.Inputs["password"].SecretValue().Element.StringValue())
+
+	summary, err := ConvertJSONEvent(apitype.EngineEvent{SummaryEvent: &apitype.SummaryEvent{
+		DurationSeconds: 90,
+		ResourceChanges: map[apitype.OpType]int{apitype.OpCreate: 2},
+	}}, true)
+	require.NoError(t, err)
+	assert.Equal(t, engine.SummaryEventPayload{
+		IsPreview:       true,
+		Duration:        90 * time.Second,
+		ResourceChanges: engine.ResourceChanges{deploy.OpCreate: 2},
+	}, summary.Payload())
+
+	_, err = ConvertJSONEvent(apitype.EngineEvent{}, false)
+	assert.Error(t, err)
+}
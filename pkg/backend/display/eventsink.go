@@ -0,0 +1,100 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// startEventSinks loads the event sink plugins named by opts.EventSinks and sends them each of the events that pass
+// through it. A sink that cannot be loaded is reported as a warning, and a sink that fails to handle an event is
+// logged; neither affects the operation.
+func startEventSinks(events <-chan engine.Event, done chan<- bool, opts Options) (<-chan engine.Event, chan<- bool) {
+	stderr := opts.Stderr
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+
+	// Before moving further, attempt to load the sinks, so that any problem is reported up front.
+	pwd, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: could not load event sinks: %v\n", err)
+		return events, done
+	}
+	ctx, err := plugin.NewContext(cmdutil.Diag(), cmdutil.Diag(), nil, nil, pwd, nil, false, nil)
+	if err != nil {
+		fmt.Fprintf(stderr, "warning: could not load event sinks: %v\n", err)
+		return events, done
+	}
+	var sinks []plugin.EventSink
+	for _, name := range opts.EventSinks {
+		sink, err := plugin.NewEventSink(ctx.Host, ctx, tokens.QName(name))
+		if err != nil {
+			fmt.Fprintf(stderr, "warning: could not load event sink %v: %v\n", name, err)
+			continue
+		}
+		sinks = append(sinks, sink)
+	}
+	if len(sinks) == 0 {
+		contract.IgnoreClose(ctx)
+		return events, done
+	}
+
+	outEvents, outDone := make(chan engine.Event), make(chan bool)
+	go func() {
+		defer close(done)
+		defer contract.IgnoreClose(ctx)
+		defer func() {
+			for _, sink := range sinks {
+				contract.IgnoreClose(sink)
+			}
+		}()
+
+		sequence := 0
+		for e := range events {
+			if apiEvent, err := ConvertEngineEvent(e); err != nil {
+				logging.V(7).Infof("could not convert event for event sinks: %v", err)
+			} else {
+				apiEvent.Sequence, apiEvent.Timestamp = sequence, int(time.Now().Unix())
+				uncolorizeEngineEvent(&apiEvent)
+				for _, sink := range sinks {
+					if err := sink.OnEvent(apiEvent); err != nil {
+						logging.V(7).Infof("event sink %v failed to handle event: %v", sink.Name(), err)
+					}
+				}
+			}
+			sequence++
+
+			outEvents <- e
+
+			if e.Type == engine.CancelEvent {
+				break
+			}
+		}
+
+		<-outDone
+	}()
+
+	return outEvents, outDone
+}
@@ -84,10 +84,14 @@ func stateForJSONOutput(s *resource.State, opts Options) *resource.State {
 		outputs = resource.PropertyMap{}
 	}
 
-	return resource.NewState(s.Type, s.URN, s.Custom, s.Delete, s.ID, inputs,
+	state := resource.NewState(s.Type, s.URN, s.Custom, s.Delete, s.ID, inputs,
 		outputs, s.Parent, s.Protect, s.External, s.Dependencies, s.InitErrors, s.Provider,
 		s.PropertyDependencies, s.PendingReplacement, s.AdditionalSecretOutputs, s.Aliases, &s.CustomTimeouts,
 		s.ImportID)
+	state.RemoveAfter = s.RemoveAfter
+	state.DeletedWith = s.DeletedWith
+	state.Locked = s.Locked
+	return state
 }
 
 // ShowJSONEvents renders incremental engine events to stdout.
@@ -211,6 +215,7 @@ func ShowPreviewDigest(events <-chan engine.Event, done chan<- bool, opts Option
 			digest.Duration = p.Duration
 			digest.ChangeSummary = p.ResourceChanges
 			digest.MaybeCorrupt = p.MaybeCorrupt
+			digest.CostEstimates = costEstimatesForJSON(p.CostEstimates)
 		default:
 			contract.Failf("unknown event type '%s'", e.Type)
 		}
@@ -238,6 +243,8 @@ type previewDigest struct {
 	ChangeSummary engine.ResourceChanges `json:"changeSummary,omitempty"`
 	// MaybeCorrupt indicates whether one or more resources may be corrupt.
 	MaybeCorrupt bool `json:"maybeCorrupt,omitempty"`
+	// CostEstimates contains the estimated monthly costs of the changed resources, if a cost estimator ran.
+	CostEstimates *costEstimates `json:"costEstimates,omitempty"`
 }
 
 // propertyDiff contains information about the difference in a single property value.
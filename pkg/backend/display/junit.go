@@ -0,0 +1,208 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitOperation records the outcome of a single resource operation.
+type junitOperation struct {
+	urn         resource.URN
+	op          deploy.StepOp
+	start, end  time.Time
+	failed      bool
+	failure     string
+	diagnostics []string
+}
+
+// junitReport accumulates the resource operations of an update as JUnit test cases.
+type junitReport struct {
+	name       string
+	start      time.Time
+	operations []*junitOperation
+	current    map[resource.URN]*junitOperation
+}
+
+func newJUnitReport(name string) *junitReport {
+	return &junitReport{name: name, start: time.Now(), current: map[resource.URN]*junitOperation{}}
+}
+
+// ProcessEvent records the information in an engine event that pertains to a resource operation.
+func (r *junitReport) ProcessEvent(e engine.Event) {
+	switch e.Type {
+	case engine.ResourcePreEvent:
+		m := e.Payload().(engine.ResourcePreEventPayload).Metadata
+		if m.Op == deploy.OpSame {
+			return
+		}
+		op := &junitOperation{urn: m.URN, op: m.Op, start: time.Now()}
+		r.operations, r.current[m.URN] = append(r.operations, op), op
+	case engine.ResourceOutputsEvent:
+		if op, ok := r.current[e.Payload().(engine.ResourceOutputsEventPayload).Metadata.URN]; ok {
+			op.end = time.Now()
+		}
+	case engine.ResourceOperationFailed:
+		if op, ok := r.current[e.Payload().(engine.ResourceOperationFailedPayload).Metadata.URN]; ok {
+			op.end, op.failed = time.Now(), true
+		}
+	case engine.DiagEvent:
+		p := e.Payload().(engine.DiagEventPayload)
+		if op, ok := r.current[p.URN]; ok && !p.Ephemeral && p.Severity != diag.Debug {
+			message := strings.TrimSpace(colors.Never.Colorize(p.Message))
+			op.diagnostics = append(op.diagnostics, fmt.Sprintf("%v: %v", p.Severity, message))
+			if p.Severity == diag.Error {
+				op.failed, op.failure = true, message
+			}
+		}
+	case engine.PolicyViolationEvent:
+		p := e.Payload().(engine.PolicyViolationEventPayload)
+		if op, ok := r.current[p.ResourceURN]; ok {
+			message := strings.TrimSpace(colors.Never.Colorize(p.Message))
+			op.diagnostics = append(op.diagnostics, fmt.Sprintf("%v: %v", p.EnforcementLevel, message))
+			if p.EnforcementLevel == apitype.Mandatory {
+				op.failed, op.failure = true, message
+			}
+		}
+	}
+}
+
+// Write writes the report as JUnit XML. Operations that had not finished are reported as taking until now.
+func (r *junitReport) Write(w io.Writer) error {
+	now := time.Now()
+	suite := junitTestSuite{
+		Name:      r.name,
+		Tests:     len(r.operations),
+		Time:      junitDuration(now.Sub(r.start)),
+		Timestamp: r.start.UTC().Format("2006-01-02T15:04:05"),
+	}
+	for _, op := range r.operations {
+		end := op.end
+		if end.IsZero() {
+			end = now
+		}
+		c := junitTestCase{
+			Name:      fmt.Sprintf("%v %v", op.op, op.urn.Name()),
+			ClassName: string(op.urn.Type()),
+			Time:      junitDuration(end.Sub(op.start)),
+			SystemOut: strings.Join(op.diagnostics, "\n"),
+		}
+		if op.failed {
+			suite.Failures++
+			message := op.failure
+			if message == "" {
+				message = fmt.Sprintf("failed to %v %v", op.op, op.urn)
+			}
+			c.Failure = &junitFailure{Message: message, Type: string(op.op), Text: string(op.urn)}
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitDuration(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// startJUnitReporter records the resource operations in the events that pass through it, and writes them as a
+// JUnit XML report to opts.JUnitReportPath once all events have been received.
+func startJUnitReporter(name string, events <-chan engine.Event, done chan<- bool,
+	opts Options) (<-chan engine.Event, chan<- bool) {
+
+	// Before moving further, attempt to create the report file, so that any problem is reported up front.
+	f, err := os.Create(opts.JUnitReportPath)
+	if err != nil {
+		stderr := opts.Stderr
+		if stderr == nil {
+			stderr = os.Stderr
+		}
+		fmt.Fprintf(stderr, "warning: could not create JUnit report: %v\n", err)
+		return events, done
+	}
+
+	outEvents, outDone := make(chan engine.Event), make(chan bool)
+	go func() {
+		defer close(done)
+		defer contract.IgnoreClose(f)
+
+		report := newJUnitReport(name)
+		for e := range events {
+			report.ProcessEvent(e)
+			outEvents <- e
+			if e.Type == engine.CancelEvent {
+				break
+			}
+		}
+		<-outDone
+
+		if err := report.Write(f); err != nil {
+			logging.V(7).Infof("could not write JUnit report: %v", err)
+		}
+	}()
+
+	return outEvents, outDone
+}
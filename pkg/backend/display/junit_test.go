@@ -0,0 +1,91 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestJUnitReport(t *testing.T) {
+	t.Parallel()
+
+	bucket := resource.URN("urn:pulumi:dev::website::aws:s3/bucket:Bucket::site")
+	object := resource.URN("urn:pulumi:dev::website::aws:s3/bucketObject:BucketObject::index")
+	unchanged := resource.URN("urn:pulumi:dev::website::aws:s3/bucketPolicy:BucketPolicy::policy")
+
+	pre := func(urn resource.URN, op deploy.StepOp) engine.Event {
+		return engine.NewEvent(engine.ResourcePreEvent, engine.ResourcePreEventPayload{
+			Metadata: engine.StepEventMetadata{URN: urn, Op: op, Type: urn.Type()},
+		})
+	}
+
+	report := newJUnitReport("website/dev update")
+	for _, e := range []engine.Event{
+		pre(bucket, deploy.OpCreate),
+		pre(object, deploy.OpUpdate),
+		pre(unchanged, deploy.OpSame),
+		engine.NewEvent(engine.ResourceOutputsEvent, engine.ResourceOutputsEventPayload{
+			Metadata: engine.StepEventMetadata{URN: bucket, Op: deploy.OpCreate},
+		}),
+		engine.NewEvent(engine.DiagEvent, engine.DiagEventPayload{
+			URN: bucket, Message: "bucket names are global", Severity: diag.Warning,
+		}),
+		engine.NewEvent(engine.DiagEvent, engine.DiagEventPayload{
+			URN: object, Message: "access denied\n", Severity: diag.Error,
+		}),
+		engine.NewEvent(engine.ResourceOperationFailed, engine.ResourceOperationFailedPayload{
+			Metadata: engine.StepEventMetadata{URN: object, Op: deploy.OpUpdate},
+		}),
+	} {
+		report.ProcessEvent(e)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.Write(&buf))
+
+	var suites junitTestSuites
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	if !assert.Len(t, suites.Suites, 1) {
+		return
+	}
+	suite := suites.Suites[0]
+	assert.Equal(t, "website/dev update", suite.Name)
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	if !assert.Len(t, suite.Cases, 2) {
+		return
+	}
+
+	assert.Equal(t, "create site", suite.Cases[0].Name)
+	assert.Equal(t, "aws:s3/bucket:Bucket", suite.Cases[0].ClassName)
+	assert.Nil(t, suite.Cases[0].Failure)
+	assert.Equal(t, "warning: bucket names are global", suite.Cases[0].SystemOut)
+
+	assert.Equal(t, "update index", suite.Cases[1].Name)
+	if assert.NotNil(t, suite.Cases[1].Failure) {
+		assert.Equal(t, "access denied", suite.Cases[1].Failure.Message)
+		assert.Equal(t, "update", suite.Cases[1].Failure.Type)
+		assert.Equal(t, string(object), suite.Cases[1].Failure.Text)
+	}
+}
@@ -16,8 +16,10 @@ package display
 
 import (
 	"io"
+	"time"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/ciutil"
 )
 
 // Type of output to display.
@@ -48,7 +50,39 @@ type Options struct {
 	Type                 Type                // type of display (rich diff, progress, or query).
 	JSONDisplay          bool                // true if we should emit the entire diff as JSON.
 	EventLogPath         string              // the path to the file to use for logging events, if any.
+	JUnitReportPath      string              // the path to which to write a JUnit XML report of operations, if any.
+	EventSinks           []string            // the names of the event sink plugins to send engine events to.
+	EventStream          io.Writer           // a writer to which to stream engine events as lines of JSON, if any.
+	CostEstimatorURL     string              // the URL of a webhook that estimates the costs of a preview, if any.
+	ProfileResources     bool                // true to show the slowest resource operations once an update ends.
+	SlowStepThreshold    time.Duration       // the time after which a resource operation is flagged as slow, if any.
+	HeartbeatInterval    time.Duration       // the interval at which to list in-flight resources when non-interactive.
+	CIAnnotations        bool                // true to group diagnostics and raise annotations in CI logs.
+	CIFormat             ciutil.SystemName   // the CI system whose log commands to write, instead of the detected one.
+	Quiet                bool                // true to show only diagnostics and the summary, not each resource.
+	Verbosity            int                 // the level of detail to show, from 0 (the default) to MaxVerbosity.
+	NoTruncate           bool                // true to wrap lines that are too wide for the display, not truncate them.
+	DisplayWidth         int                 // the width to fit lines to, instead of the terminal's width, if any.
 	Debug                bool                // true to enable debug output.
 	Stdout               io.Writer           // the writer to use for stdout. Defaults to os.Stdout if unset.
 	Stderr               io.Writer           // the writer to use for stderr. Defaults to os.Stderr if unset.
 }
+
+// MaxVerbosity is the highest level of detail that a display can be asked to show.
+const MaxVerbosity = 3
+
+// withVerbosity returns the options with the details implied by their verbosity level turned on: replacement steps
+// and reads from level 1, unchanged resources from level 2, and configuration at level 3.
+func (opts Options) withVerbosity() Options {
+	if opts.Verbosity >= 1 {
+		opts.ShowReplacementSteps = true
+		opts.ShowReads = true
+	}
+	if opts.Verbosity >= 2 {
+		opts.ShowSameResources = true
+	}
+	if opts.Verbosity >= 3 {
+		opts.ShowConfig = true
+	}
+	return opts
+}
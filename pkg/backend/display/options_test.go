@@ -0,0 +1,40 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithVerbosity(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Options{}, Options{}.withVerbosity())
+	assert.Equal(t, Options{ShowSameResources: true}, Options{ShowSameResources: true}.withVerbosity())
+
+	assert.Equal(t, Options{Verbosity: 1, ShowReplacementSteps: true, ShowReads: true},
+		Options{Verbosity: 1}.withVerbosity())
+	assert.Equal(t, Options{Verbosity: 2, ShowReplacementSteps: true, ShowReads: true, ShowSameResources: true},
+		Options{Verbosity: 2}.withVerbosity())
+	assert.Equal(t, Options{
+		Verbosity:            MaxVerbosity,
+		ShowReplacementSteps: true,
+		ShowReads:            true,
+		ShowSameResources:    true,
+		ShowConfig:           true,
+	}, Options{Verbosity: MaxVerbosity}.withVerbosity())
+}
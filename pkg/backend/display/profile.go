@@ -0,0 +1,244 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// resourceProfileLimit is the number of operations shown in the table of the slowest resource operations. The JSON
+// form of the profile includes every operation.
+const resourceProfileLimit = 20
+
+// resourceOperationTime is the wall-clock time taken by a single resource operation.
+type resourceOperationTime struct {
+	URN      resource.URN  `json:"urn"`
+	Op       deploy.StepOp `json:"op"`
+	Failed   bool          `json:"failed,omitempty"`
+	Duration time.Duration `json:"-"`
+	Seconds  float64       `json:"seconds"`
+}
+
+// providerConfigureTime is the wall-clock time taken to configure a provider.
+type providerConfigureTime struct {
+	URN      resource.URN  `json:"urn"`
+	Failed   bool          `json:"failed,omitempty"`
+	Duration time.Duration `json:"-"`
+	Seconds  float64       `json:"seconds"`
+}
+
+// resourceProfile accumulates the durations of the resource operations of an update, and of the configuration of its
+// providers.
+type resourceProfile struct {
+	operations []resourceOperationTime
+	providers  []providerConfigureTime
+}
+
+// ProcessEvent records the duration of a resource operation that has ended, or the provider configuration times
+// reported by the summary of the update.
+func (p *resourceProfile) ProcessEvent(e engine.Event) {
+	var m engine.StepEventMetadata
+	failed := false
+	switch e.Type {
+	case engine.ResourceOutputsEvent:
+		m = e.Payload().(engine.ResourceOutputsEventPayload).Metadata
+	case engine.ResourceOperationFailed:
+		m, failed = e.Payload().(engine.ResourceOperationFailedPayload).Metadata, true
+	case engine.SummaryEvent:
+		for _, t := range e.Payload().(engine.SummaryEventPayload).ProviderConfigureTimes {
+			p.providers = append(p.providers, providerConfigureTime{
+				URN:      t.URN,
+				Failed:   t.Failed,
+				Duration: t.Duration,
+				Seconds:  t.Duration.Seconds(),
+			})
+		}
+		return
+	default:
+		return
+	}
+	if m.Op == deploy.OpSame || m.Duration == 0 || isRootStack(m) {
+		return
+	}
+	p.operations = append(p.operations, resourceOperationTime{
+		URN:      m.URN,
+		Op:       m.Op,
+		Failed:   failed,
+		Duration: m.Duration,
+		Seconds:  m.Duration.Seconds(),
+	})
+}
+
+// Sorted returns the recorded operations, slowest first.
+func (p *resourceProfile) Sorted() []resourceOperationTime {
+	ops := append([]resourceOperationTime{}, p.operations...)
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].Duration > ops[j].Duration
+	})
+	return ops
+}
+
+// SortedProviders returns the recorded provider configuration times, slowest first.
+func (p *resourceProfile) SortedProviders() []providerConfigureTime {
+	providers := append([]providerConfigureTime{}, p.providers...)
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].Duration > providers[j].Duration
+	})
+	return providers
+}
+
+// Write writes tables of the slowest operations and provider configurations or, if jsonOut is true, a JSON document
+// of every operation and provider configuration.
+func (p *resourceProfile) Write(w io.Writer, jsonOut bool, opts Options) error {
+	ops, providers := p.Sorted(), p.SortedProviders()
+	if jsonOut {
+		b, err := json.Marshal(struct {
+			ResourceProfile       []resourceOperationTime `json:"resourceProfile"`
+			ProviderConfiguration []providerConfigureTime `json:"providerConfiguration,omitempty"`
+		}{ops, providers})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	if len(ops) != 0 {
+		heading := "Slowest resource operations:"
+		if len(ops) > resourceProfileLimit {
+			heading = fmt.Sprintf("Slowest resource operations (%d of %d):", resourceProfileLimit, len(ops))
+			ops = ops[:resourceProfileLimit]
+		}
+		rows := make([][]string, len(ops))
+		for i, op := range ops {
+			description := string(op.Op)
+			if op.Failed {
+				description += " (failed)"
+			}
+			rows[i] = []string{
+				op.Duration.Round(100 * time.Millisecond).String(),
+				description,
+				fmt.Sprintf("%s (%s)", op.URN.Name(), op.URN.Type()),
+			}
+		}
+		if err := writeProfileTable(w, opts, heading, []string{"Duration", "Operation", "Resource"}, rows); err != nil {
+			return err
+		}
+	}
+
+	if len(providers) != 0 {
+		rows := make([][]string, len(providers))
+		for i, provider := range providers {
+			name := fmt.Sprintf("%s (%s)", provider.URN.Name(), provider.URN.Type())
+			if provider.Failed {
+				name += " (failed)"
+			}
+			rows[i] = []string{provider.Duration.Round(100 * time.Millisecond).String(), name}
+		}
+		if err := writeProfileTable(w, opts, "Provider configuration:", []string{"Duration", "Provider"},
+			rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeProfileTable writes a table of a profile under the given heading, with a column for each header. The last
+// column is not padded.
+func writeProfileTable(w io.Writer, opts Options, heading string, headers []string, rows [][]string) error {
+	if _, err := fmt.Fprint(w, opts.Color.Colorize(
+		fmt.Sprintf("\n%s%s%s\n", colors.SpecHeadline, heading, colors.Reset))); err != nil {
+		return err
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	header := "    "
+	for i, h := range headers {
+		header += columnHeader(h)
+		if i < len(headers)-1 {
+			header += messagePadding(h, widths[i], 2)
+		}
+	}
+	if _, err := fmt.Fprint(w, opts.Color.Colorize(header+"\n")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		line := "    "
+		for i, cell := range row {
+			line += cell
+			if i < len(row)-1 {
+				line += messagePadding(cell, widths[i], 2)
+			}
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startResourceProfiler records the duration of each resource operation that passes through it, and of the
+// configuration of each provider, and once all events have been displayed, writes the slowest of them to stdout.
+func startResourceProfiler(events <-chan engine.Event, done chan<- bool,
+	opts Options) (<-chan engine.Event, chan<- bool) {
+
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
+	outEvents, outDone := make(chan engine.Event), make(chan bool)
+	go func() {
+		defer close(done)
+
+		var profile resourceProfile
+		for e := range events {
+			profile.ProcessEvent(e)
+			outEvents <- e
+			if e.Type == engine.CancelEvent {
+				break
+			}
+		}
+		<-outDone
+
+		if err := profile.Write(stdout, opts.JSONDisplay, opts); err != nil {
+			logging.V(7).Infof("could not write resource profile: %v", err)
+		}
+	}()
+
+	return outEvents, outDone
+}
@@ -0,0 +1,109 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestResourceProfile(t *testing.T) {
+	t.Parallel()
+
+	bucket := resource.URN("urn:pulumi:dev::website::aws:s3/bucket:Bucket::site")
+	cluster := resource.URN("urn:pulumi:dev::website::aws:eks/cluster:Cluster::main")
+	object := resource.URN("urn:pulumi:dev::website::aws:s3/bucketObject:BucketObject::index")
+	unchanged := resource.URN("urn:pulumi:dev::website::aws:s3/bucketPolicy:BucketPolicy::policy")
+
+	outputs := func(urn resource.URN, op deploy.StepOp, d time.Duration) engine.Event {
+		return engine.NewEvent(engine.ResourceOutputsEvent, engine.ResourceOutputsEventPayload{
+			Metadata: engine.StepEventMetadata{URN: urn, Op: op, Type: urn.Type(), Duration: d},
+		})
+	}
+
+	var profile resourceProfile
+	for _, e := range []engine.Event{
+		outputs(bucket, deploy.OpCreate, 2*time.Second),
+		outputs(cluster, deploy.OpUpdate, 12*time.Minute+30*time.Second),
+		outputs(unchanged, deploy.OpSame, time.Millisecond),
+		engine.NewEvent(engine.ResourceOperationFailed, engine.ResourceOperationFailedPayload{
+			Metadata: engine.StepEventMetadata{URN: object, Op: deploy.OpDelete, Duration: 45 * time.Second},
+		}),
+	} {
+		profile.ProcessEvent(e)
+	}
+
+	sorted := profile.Sorted()
+	if assert.Len(t, sorted, 3) {
+		assert.Equal(t, cluster, sorted[0].URN)
+		assert.Equal(t, object, sorted[1].URN)
+		assert.True(t, sorted[1].Failed)
+		assert.Equal(t, bucket, sorted[2].URN)
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, profile.Write(&out, false, Options{Color: colors.Never}))
+	assert.Equal(t, "\n"+
+		"Slowest resource operations:\n"+
+		"    Duration  Operation        Resource\n"+
+		"    12m30s    update           main (aws:eks/cluster:Cluster)\n"+
+		"    45s       delete (failed)  index (aws:s3/bucketObject:BucketObject)\n"+
+		"    2s        create           site (aws:s3/bucket:Bucket)\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, profile.Write(&out, true, Options{}))
+	assert.Equal(t, `{"resourceProfile":[`+
+		`{"urn":"`+string(cluster)+`","op":"update","seconds":750},`+
+		`{"urn":"`+string(object)+`","op":"delete","failed":true,"seconds":45},`+
+		`{"urn":"`+string(bucket)+`","op":"create","seconds":2}]}`+"\n", out.String())
+}
+
+func TestResourceProfileProviderConfiguration(t *testing.T) {
+	t.Parallel()
+
+	aws := resource.URN("urn:pulumi:dev::website::pulumi:providers:aws::default_4_0_0")
+	k8s := resource.URN("urn:pulumi:dev::website::pulumi:providers:kubernetes::cluster")
+
+	var profile resourceProfile
+	profile.ProcessEvent(engine.NewEvent(engine.SummaryEvent, engine.SummaryEventPayload{
+		ProviderConfigureTimes: []providers.ConfigureTime{
+			{URN: aws, Duration: 1500 * time.Millisecond},
+			{URN: k8s, Duration: 3 * time.Second, Failed: true},
+		},
+	}))
+
+	var out bytes.Buffer
+	assert.NoError(t, profile.Write(&out, false, Options{Color: colors.Never}))
+	assert.Equal(t, "\n"+
+		"Provider configuration:\n"+
+		"    Duration  Provider\n"+
+		"    3s        cluster (pulumi:providers:kubernetes) (failed)\n"+
+		"    1.5s      default_4_0_0 (pulumi:providers:aws)\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, profile.Write(&out, true, Options{}))
+	assert.Equal(t, `{"resourceProfile":[],"providerConfiguration":[`+
+		`{"urn":"`+string(k8s)+`","failed":true,"seconds":3},`+
+		`{"urn":"`+string(aws)+`","seconds":1.5}]}`+"\n", out.String())
+}
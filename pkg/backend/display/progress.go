@@ -28,6 +28,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/docker/docker/pkg/term"
+	"github.com/dustin/go-humanize/english"
 	"golang.org/x/crypto/ssh/terminal"
 
 	"github.com/pulumi/pulumi/pkg/v3/engine"
@@ -37,6 +38,7 @@ import (
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/ciutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
@@ -51,6 +53,9 @@ type Progress struct {
 	Action  string
 }
 
+// heartbeatResourceLimit is the number of in-flight resources named in a non-interactive display's heartbeat line.
+const heartbeatResourceLimit = 5
+
 func makeMessageProgress(message string) Progress {
 	return Progress{Message: message}
 }
@@ -164,6 +169,9 @@ type ProgressDisplay struct {
 	// Cache of lines we've already printed.  We don't print a progress message again if it hasn't
 	// changed between the last time we printed and now.
 	printedProgressCache map[string]Progress
+
+	// Renders the log groups and annotations of the CI system we're running under, if enabled.
+	annotator ciAnnotator
 }
 
 var (
@@ -316,6 +324,11 @@ func ShowProgressEvents(op string, action apitype.UpdateKind, stack tokens.QName
 		displayOrderCounter:    1,
 		nonInteractiveSpinner:  spinner,
 	}
+	ciSystem := opts.CIFormat
+	if ciSystem == "" && opts.CIAnnotations {
+		ciSystem = ciutil.DetectVars().Name
+	}
+	display.annotator = newCIAnnotator(ciSystem)
 
 	// Assume we are not displaying in a terminal by default.
 	display.isTerminal = false
@@ -323,7 +336,8 @@ func ShowProgressEvents(op string, action apitype.UpdateKind, stack tokens.QName
 		terminalWidth, terminalHeight, err := terminal.GetSize(int(os.Stdout.Fd()))
 		if err == nil {
 			// If the terminal has a size, use it.
-			display.isTerminal = opts.IsInteractive
+			// A quiet display shows no resource rows, so there is nothing to redraw in place.
+			display.isTerminal = opts.IsInteractive && !opts.Quiet
 			display.terminalWidth = terminalWidth
 			display.terminalHeight = terminalHeight
 
@@ -339,8 +353,17 @@ func ShowProgressEvents(op string, action apitype.UpdateKind, stack tokens.QName
 		}
 	}
 
+	// In a non-interactive display, periodically write the resources that are still in flight so that CI systems
+	// that kill jobs after a period without output don't give up on long-running operations.
+	var heartbeat *time.Ticker
+	if !display.isTerminal && opts.HeartbeatInterval > 0 {
+		heartbeat = time.NewTicker(opts.HeartbeatInterval)
+	} else {
+		heartbeat = time.NewTicker(math.MaxInt64)
+	}
+
 	go func() {
-		display.processEvents(ticker, events)
+		display.processEvents(ticker, heartbeat, events)
 
 		// no more progress events from this point on.  By closing the pipe, this will then cause
 		// DisplayJSONMessagesToStream to finish once it processes the last message is receives from
@@ -351,6 +374,7 @@ func ShowProgressEvents(op string, action apitype.UpdateKind, stack tokens.QName
 	ShowProgressOutput(progressOutput, stdout, display.isTerminal)
 
 	ticker.Stop()
+	heartbeat.Stop()
 
 	// let our caller know we're done.
 	close(done)
@@ -374,7 +398,7 @@ func (display *ProgressDisplay) getMessagePadding(
 // Gets the fully padded message to be shown.  The message will always include the ID of the
 // status, then some amount of optional padding, then some amount of msgWithColors, then the
 // suffix.  Importantly, if there isn't enough room to display all of that on the terminal, then
-// the msg will be truncated to try to make it fit.
+// the msg will be truncated to try to make it fit, unless truncation has been turned off.
 func (display *ProgressDisplay) getPaddedMessage(
 	colorizedColumns, uncolorizedColumns []string, maxColumnLengths []int) string {
 
@@ -385,22 +409,35 @@ func (display *ProgressDisplay) getPaddedMessage(
 		colorizedMessage += padding + colorizedColumns[i]
 	}
 
-	if display.isTerminal {
+	if maxMsgLength, ok := display.maxMessageLength(); ok && !display.opts.NoTruncate {
 		// Ensure we don't go past the end of the terminal.  Note: this is made complex due to
 		// msgWithColors having the color code information embedded with it.  So we need to get
 		// the right substring of it, assuming that embedded colors are just markup and do not
 		// actually contribute to the length
-		maxMsgLength := display.terminalWidth - 1
-		if maxMsgLength < 0 {
-			maxMsgLength = 0
-		}
-
 		colorizedMessage = colors.TrimColorizedString(colorizedMessage, maxMsgLength)
 	}
 
 	return colorizedMessage
 }
 
+// maxMessageLength returns the number of characters that fit on a line of the display, if there is a limit: the
+// width requested in the options or, failing that, the width of the terminal.
+func (display *ProgressDisplay) maxMessageLength() (int, bool) {
+	width := display.opts.DisplayWidth
+	if width <= 0 {
+		if !display.isTerminal {
+			return 0, false
+		}
+		width = display.terminalWidth
+	}
+
+	maxMsgLength := width - 1
+	if maxMsgLength < 0 {
+		maxMsgLength = 0
+	}
+	return maxMsgLength, true
+}
+
 func (display *ProgressDisplay) uncolorizeString(v string) string {
 	uncolorized, has := display.colorizedToUncolorized[v]
 	if !has {
@@ -422,6 +459,10 @@ func (display *ProgressDisplay) uncolorizeColumns(columns []string) []string {
 }
 
 func (display *ProgressDisplay) refreshSingleRow(id string, row Row, maxColumnLengths []int) {
+	if display.opts.Quiet {
+		return
+	}
+
 	colorizedColumns := row.ColorizedColumns()
 	colorizedColumns[display.suffixColumn] += row.ColorizedSuffix()
 	display.refreshColumns(id, colorizedColumns, maxColumnLengths)
@@ -441,6 +482,26 @@ func (display *ProgressDisplay) refreshColumns(
 	}
 }
 
+// refreshWrappedColumns refreshes the lines of the terminal starting with the given one with the given columns, which
+// are wrapped over as many lines as they need if truncation has been turned off, and returns the number of lines used.
+func (display *ProgressDisplay) refreshWrappedColumns(
+	firstLine int, colorizedColumns []string, maxColumnLengths []int) int {
+
+	maxMsgLength, ok := display.maxMessageLength()
+	if !display.opts.NoTruncate || !ok {
+		display.refreshColumns(fmt.Sprintf("%v", firstLine), colorizedColumns, maxColumnLengths)
+		return 1
+	}
+
+	uncolorizedColumns := display.uncolorizeColumns(colorizedColumns)
+	msg := display.getPaddedMessage(colorizedColumns, uncolorizedColumns, maxColumnLengths)
+	lines := colors.WrapColorizedString(msg, maxMsgLength)
+	for i, line := range lines {
+		display.colorizeAndWriteProgress(makeActionProgress(fmt.Sprintf("%v", firstLine+i), line))
+	}
+	return len(lines)
+}
+
 // Ensure our stored dimension info is up to date.
 func (display *ProgressDisplay) updateTerminalDimensions() {
 	// don't do any refreshing if we're not in a terminal
@@ -652,12 +713,11 @@ func (display *ProgressDisplay) refreshAllRowsIfInTerminal() {
 
 		removeInfoColumnIfUnneeded(rows)
 
-		for i, row := range rows {
-			display.refreshColumns(fmt.Sprintf("%v", i), row, maxColumnLengths)
+		systemID := 0
+		for _, row := range rows {
+			systemID += display.refreshWrappedColumns(systemID, row, maxColumnLengths)
 		}
 
-		systemID := len(rows)
-
 		printedHeader := false
 		for _, payload := range display.systemEventPayloads {
 			msg := payload.Color.Colorize(payload.Message)
@@ -753,6 +813,11 @@ func (display *ProgressDisplay) printDiagnostics() bool {
 		// The header for the diagnogistics grouped by resource, e.g. "aws:apigateway:RestApi (accountsApi):"
 		wroteResourceHeader := false
 
+		// The CI annotations for the resource's errors and warnings, which are written after its diagnostics.
+		var annotations []string
+		title := display.uncolorizeString(row.ColorizedColumns()[typeColumn] + " (" +
+			row.ColorizedColumns()[nameColumn] + ")")
+
 		// Each row in the display corresponded with a resource, and that resource could have emitted
 		// diagnostics to various streams.
 		for id, payloads := range row.DiagInfo().StreamIDToDiagPayloads {
@@ -790,6 +855,9 @@ func (display *ProgressDisplay) printDiagnostics() bool {
 				// If we haven't printed the header for the resource, do so now.
 				if !wroteResourceHeader {
 					wroteResourceHeader = true
+					if display.annotator != nil {
+						display.writeSimpleMessage(display.annotator.StartGroup(title))
+					}
 					columns := row.ColorizedColumns()
 					display.writeSimpleMessage("  " +
 						display.opts.Color.Colorize(
@@ -801,6 +869,13 @@ func (display *ProgressDisplay) printDiagnostics() bool {
 					display.writeSimpleMessage("    " + line)
 				}
 
+				if display.annotator != nil {
+					message := strings.TrimSpace(display.uncolorizeString(v.Message))
+					if a := display.annotator.Annotate(v.Severity, title, message); a != "" {
+						annotations = append(annotations, a)
+					}
+				}
+
 				wrote = true
 			}
 
@@ -809,6 +884,12 @@ func (display *ProgressDisplay) printDiagnostics() bool {
 			}
 		}
 
+		if wroteResourceHeader && display.annotator != nil {
+			display.writeSimpleMessage(display.annotator.EndGroup(title))
+			for _, a := range annotations {
+				display.writeSimpleMessage(a)
+			}
+		}
 	}
 	return wroteDiagnosticHeader
 }
@@ -916,6 +997,40 @@ func (display *ProgressDisplay) printSummary(wroteDiagnosticHeader bool) {
 
 	msg := renderSummaryEvent(display.action, *display.summaryEventPayload, wroteDiagnosticHeader, display.opts)
 	display.writeSimpleMessage(msg)
+
+	if display.annotator != nil {
+		for _, command := range display.annotator.Summary(display.summaryEventPayload.ResourceChanges) {
+			display.writeSimpleMessage(command)
+		}
+	}
+}
+
+// writeCIProgress reports the progress of the operation to the CI system, if any, once the given row is done.
+func (display *ProgressDisplay) writeCIProgress(row ResourceRow, failed bool) {
+	step := row.Step()
+	if display.annotator == nil || display.isTerminal || step.URN == "" || step.URN == display.stackUrn ||
+		step.Op == deploy.OpSame {
+		return
+	}
+
+	done, total := 0, 0
+	for _, r := range display.eventUrnToResourceRow {
+		if r.Step().URN == display.stackUrn || r.Step().Op == deploy.OpSame {
+			continue
+		}
+		total++
+		if r.IsDone() {
+			done++
+		}
+	}
+
+	message := fmt.Sprintf("%v %v (%v)", display.getStepOp(step), simplifyTypeName(step.URN.Type()), step.URN.Name())
+	if failed {
+		message = "failed to " + message
+	}
+	if command := display.annotator.Progress(done, total, message); command != "" {
+		display.writeSimpleMessage(command)
+	}
 }
 
 func (display *ProgressDisplay) mergeStreamPayloadsToSinglePayload(
@@ -970,6 +1085,30 @@ func (display *ProgressDisplay) processTick() {
 	}
 }
 
+// processHeartbeat writes a line naming the resources whose operations are still in flight, if there are any.
+func (display *ProgressDisplay) processHeartbeat() {
+	var waiting []string
+	for _, row := range display.resourceRows {
+		step := row.Step()
+		if row.IsDone() || isRootStack(step) || display.getStepOp(step) == deploy.OpSame {
+			continue
+		}
+		waiting = append(waiting, fmt.Sprintf("%s (%s)", step.URN.Name(), step.URN.Type()))
+	}
+	if len(waiting) == 0 {
+		return
+	}
+
+	msg := fmt.Sprintf("still waiting on %d %s: ", len(waiting), english.PluralWord(len(waiting), "resource", ""))
+	if len(waiting) > heartbeatResourceLimit {
+		msg += strings.Join(waiting[:heartbeatResourceLimit], ", ") +
+			fmt.Sprintf(" and %d more", len(waiting)-heartbeatResourceLimit)
+	} else {
+		msg += strings.Join(waiting, ", ")
+	}
+	display.writeSimpleMessage(colors.SpecInfo + msg + colors.Reset)
+}
+
 func (display *ProgressDisplay) getRowForURN(urn resource.URN, metadata *engine.StepEventMetadata) ResourceRow {
 	// If there's already a row for this URN, return it.
 	row, has := display.eventUrnToResourceRow[urn]
@@ -999,6 +1138,7 @@ func (display *ProgressDisplay) getRowForURN(urn resource.URN, metadata *engine.
 	row = &resourceRowData{
 		display:              display,
 		tick:                 display.currentTick,
+		started:              time.Now(),
 		diagInfo:             &DiagInfo{},
 		policyPayloads:       policyPayloads,
 		step:                 step,
@@ -1099,6 +1239,7 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 	if event.Type == engine.ResourcePreEvent {
 		step := event.Payload().(engine.ResourcePreEventPayload).Metadata
 		row.SetStep(step)
+		row.SetStarted(time.Now())
 	} else if event.Type == engine.ResourceOutputsEvent {
 		isRefresh := display.getStepOp(row.Step()) == deploy.OpRefresh
 		step := event.Payload().(engine.ResourceOutputsEventPayload).Metadata
@@ -1110,6 +1251,7 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 
 		row.SetStep(step)
 		row.AddOutputStep(step)
+		display.writeCIProgress(row, false)
 
 		// If we're not in a terminal, we may not want to display this row again: if we're displaying a preview or if
 		// this step is a no-op for a custom resource, refreshing this row will simply duplicate its earlier output.
@@ -1120,6 +1262,7 @@ func (display *ProgressDisplay) processNormalEvent(event engine.Event) {
 		}
 	} else if event.Type == engine.ResourceOperationFailed {
 		row.SetFailed()
+		display.writeCIProgress(row, true)
 	} else if event.Type == engine.DiagEvent {
 		// also record this diagnostic so we print it at the end.
 		row.RecordDiagEvent(event)
@@ -1182,7 +1325,7 @@ func (display *ProgressDisplay) ensureHeaderAndStackRows() {
 	display.resourceRows = append(display.resourceRows, stackRow)
 }
 
-func (display *ProgressDisplay) processEvents(ticker *time.Ticker, events <-chan engine.Event) {
+func (display *ProgressDisplay) processEvents(ticker, heartbeat *time.Ticker, events <-chan engine.Event) {
 	// Main processing loop.  The purpose of this func is to read in events from the engine
 	// and translate them into Status objects and progress messages to be presented to the
 	// command line.
@@ -1191,6 +1334,9 @@ func (display *ProgressDisplay) processEvents(ticker *time.Ticker, events <-chan
 		case <-ticker.C:
 			display.processTick()
 
+		case <-heartbeat.C:
+			display.processHeartbeat()
+
 		case event := <-events:
 			if event.Type == "" || event.Type == engine.CancelEvent {
 				// Engine finished sending events.  Do all the final processing and return
@@ -0,0 +1,94 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestProcessHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	output := make(chan Progress, 10)
+	display := &ProgressDisplay{
+		opts:                  Options{Color: colors.Never},
+		progressOutput:        output,
+		printedProgressCache:  make(map[string]Progress),
+		nonInteractiveSpinner: &nopSpinner{},
+	}
+	addRow := func(urn resource.URN, op deploy.StepOp) *resourceRowData {
+		row := &resourceRowData{display: display, step: engine.StepEventMetadata{URN: urn, Op: op}}
+		display.resourceRows = append(display.resourceRows, row)
+		return row
+	}
+
+	addRow("urn:pulumi:dev::website::pulumi:pulumi:Stack::website-dev", deploy.OpCreate)
+	addRow("urn:pulumi:dev::website::aws:s3/bucket:Bucket::site", deploy.OpSame)
+	done := addRow("urn:pulumi:dev::website::aws:s3/bucket:Bucket::logs", deploy.OpCreate)
+	done.outputSteps = []engine.StepEventMetadata{done.step}
+	addRow("urn:pulumi:dev::website::aws:eks/cluster:Cluster::main", deploy.OpUpdate)
+
+	display.processHeartbeat()
+	assert.Equal(t, "still waiting on 1 resource: main (aws:eks/cluster:Cluster)", (<-output).Message)
+
+	for i := 0; i < 6; i++ {
+		addRow(resource.URN(fmt.Sprintf("urn:pulumi:dev::website::aws:sqs/queue:Queue::q%d", i)), deploy.OpCreate)
+	}
+	display.processHeartbeat()
+	assert.Equal(t, "still waiting on 7 resources: main (aws:eks/cluster:Cluster), q0 (aws:sqs/queue:Queue), "+
+		"q1 (aws:sqs/queue:Queue), q2 (aws:sqs/queue:Queue), q3 (aws:sqs/queue:Queue) and 2 more", (<-output).Message)
+
+	display.done = true
+	display.processHeartbeat()
+	assert.Empty(t, output)
+}
+
+func TestRefreshWrappedColumns(t *testing.T) {
+	t.Parallel()
+
+	output := make(chan Progress, 10)
+	display := &ProgressDisplay{
+		opts:                   Options{Color: colors.Never},
+		progressOutput:         output,
+		printedProgressCache:   make(map[string]Progress),
+		colorizedToUncolorized: make(map[string]string),
+		isTerminal:             true,
+		terminalWidth:          11,
+	}
+	columns := []string{"+", colors.SpecCreate + "create" + colors.Reset, "[diff: +acl]"}
+	maxColumnLengths := []int{1, 6, 12}
+
+	assert.Equal(t, 1, display.refreshWrappedColumns(0, columns, maxColumnLengths))
+	assert.Equal(t, Progress{ID: "0", Action: "+  create "}, <-output)
+
+	display.opts.NoTruncate = true
+	assert.Equal(t, 3, display.refreshWrappedColumns(1, columns, maxColumnLengths))
+	assert.Equal(t, Progress{ID: "1", Action: "+  create "}, <-output)
+	assert.Equal(t, Progress{ID: "2", Action: " [diff: +a"}, <-output)
+	assert.Equal(t, Progress{ID: "3", Action: "cl]"}, <-output)
+
+	display.opts.DisplayWidth = 21
+	assert.Equal(t, 2, display.refreshWrappedColumns(4, columns, maxColumnLengths))
+	assert.Equal(t, Progress{ID: "4", Action: "+  create  [diff: +a"}, <-output)
+	assert.Equal(t, Progress{ID: "5", Action: "cl]"}, <-output)
+}
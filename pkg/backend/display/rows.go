@@ -20,10 +20,12 @@ import (
 	"io"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/dustin/go-humanize/english"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
@@ -48,6 +50,10 @@ type ResourceRow interface {
 	SetStep(step engine.StepEventMetadata)
 	AddOutputStep(step engine.StepEventMetadata)
 
+	// Records the time at which the engine started the row's current step.  Used to warn about
+	// operations that are taking a long time.
+	SetStarted(started time.Time)
+
 	// The tick we were on when we created this row.  Purely used for generating an
 	// ellipses to show progress for in-flight resources.
 	Tick() int
@@ -121,6 +127,9 @@ type resourceRowData struct {
 	// ellipses to show progress for in-flight resources.
 	tick int
 
+	// The time at which the engine started the current step.
+	started time.Time
+
 	// If we failed this operation for any reason.
 	failed bool
 
@@ -164,6 +173,10 @@ func (data *resourceRowData) AddOutputStep(step engine.StepEventMetadata) {
 	data.outputSteps = append(data.outputSteps, step)
 }
 
+func (data *resourceRowData) SetStarted(started time.Time) {
+	data.started = started
+}
+
 func (data *resourceRowData) Tick() int {
 	return data.tick
 }
@@ -370,6 +383,12 @@ func (data *resourceRowData) getInfoColumn() string {
 				c, colors.SpecDebug, english.PluralWord(c, "debug", ""), colors.Reset))
 		}
 	} else {
+		// If the step has been running for longer than the configured threshold, say so, so that a slow
+		// operation stands out from those that are merely in progress.
+		if warning := data.slowOperationWarning(time.Now()); warning != "" {
+			appendDiagMessage(warning)
+		}
+
 		// If we're not totally done, and we're in the tree-view, just print out the last error (if
 		// there is one) next to the status message. This is helpful for long running tasks to know
 		// something bad has happened. However, once done, we print the diagnostics at the bottom, so we don't
@@ -400,6 +419,26 @@ func (data *resourceRowData) getInfoColumn() string {
 	return diagMsg
 }
 
+// slowOperationWarning returns a warning if the row's step has been running for longer than the display's slow
+// operation threshold, or the empty string otherwise.
+func (data *resourceRowData) slowOperationWarning(now time.Time) string {
+	threshold := data.display.opts.SlowStepThreshold
+	if threshold <= 0 || data.display.isPreview || data.started.IsZero() || data.IsDone() ||
+		isRootStack(data.step) || data.display.getStepOp(data.step) == deploy.OpSame {
+		return ""
+	}
+	elapsed := now.Sub(data.started)
+	if elapsed < threshold {
+		return ""
+	}
+
+	msg := fmt.Sprintf("still running after %v", elapsed.Round(time.Second))
+	if ref, err := providers.ParseReference(data.step.Provider); err == nil {
+		msg += fmt.Sprintf(" (provider %s)", providers.GetProviderPackage(ref.URN().Type()))
+	}
+	return colors.SpecWarning + msg + colors.Reset
+}
+
 func getDiffInfo(step engine.StepEventMetadata, action apitype.UpdateKind) string {
 	diffOutputs := action == apitype.RefreshUpdate
 	changesBuf := &bytes.Buffer{}
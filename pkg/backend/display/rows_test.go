@@ -0,0 +1,51 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+)
+
+func TestSlowOperationWarning(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	display := &ProgressDisplay{opts: Options{SlowStepThreshold: time.Minute}}
+	step := engine.StepEventMetadata{
+		URN:      "urn:pulumi:dev::website::aws:eks/cluster:Cluster::main",
+		Op:       deploy.OpCreate,
+		Provider: "urn:pulumi:dev::website::pulumi:providers:aws::default::provider-id",
+	}
+	row := &resourceRowData{display: display, step: step, started: now.Add(-30 * time.Second)}
+
+	assert.Equal(t, "", row.slowOperationWarning(now))
+
+	row.started = now.Add(-6 * time.Minute)
+	assert.Equal(t, "still running after 6m0s (provider aws)", colors.Never.Colorize(row.slowOperationWarning(now)))
+
+	row.outputSteps = []engine.StepEventMetadata{step}
+	assert.Equal(t, "", row.slowOperationWarning(now))
+
+	row.outputSteps = nil
+	display.opts.SlowStepThreshold = 0
+	assert.Equal(t, "", row.slowOperationWarning(now))
+}
@@ -2,15 +2,69 @@ package backend
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 )
 
 // ConflictingUpdateError represents an error which occurred while starting an update/destroy operation.
 // Another update of the same stack was in progress, so the operation got cancelled due to this conflict.
 type ConflictingUpdateError struct {
 	Err error // The error that occurred while starting the operation.
+
+	// Holders describes the updates that hold the stack's lock, if the backend knows about them.
+	Holders []LockHolder
 }
 
 func (c ConflictingUpdateError) Error() string {
-	return fmt.Sprintf("%s\nTo learn more about possible reasons and resolution, visit "+
-		"https://www.pulumi.com/docs/troubleshooting/#conflict", c.Err.Error())
+	var holders strings.Builder
+	for _, h := range c.Holders {
+		fmt.Fprintf(&holders, "\n  %s", h)
+	}
+	return fmt.Sprintf("%s%s\nTo learn more about possible reasons and resolution, visit "+
+		"https://www.pulumi.com/docs/troubleshooting/#conflict", c.Err.Error(), holders.String())
+}
+
+// LockHolder describes an update that holds the lock on a stack, preventing other updates of it from starting.
+// Fields the backend does not know about are left empty.
+type LockHolder struct {
+	// Lock is the location of the lock, for backends that store locks as files.
+	Lock string `json:"lock,omitempty"`
+	// User is the user that started the update.
+	User string `json:"user,omitempty"`
+	// Host is the machine the update is running on.
+	Host string `json:"host,omitempty"`
+	// PID is the ID of the process running the update.
+	PID int `json:"pid,omitempty"`
+	// Kind is the kind of update, e.g. "update" or "destroy".
+	Kind apitype.UpdateKind `json:"kind,omitempty"`
+	// Since is when the lock was taken.
+	Since time.Time `json:"since"`
+}
+
+func (h LockHolder) String() string {
+	var sb strings.Builder
+	if h.Lock != "" {
+		fmt.Fprintf(&sb, "%s: ", h.Lock)
+	}
+	sb.WriteString("held by ")
+	if h.User != "" {
+		sb.WriteString(h.User)
+	} else {
+		sb.WriteString("an unknown user")
+	}
+	if h.Host != "" {
+		fmt.Fprintf(&sb, "@%s", h.Host)
+	}
+	if h.PID != 0 {
+		fmt.Fprintf(&sb, " (pid %d)", h.PID)
+	}
+	if h.Kind != "" {
+		fmt.Fprintf(&sb, " running %s", h.Kind)
+	}
+	if !h.Since.IsZero() {
+		fmt.Fprintf(&sb, " since %s", h.Since.Format(time.RFC3339))
+	}
+	return sb.String()
 }
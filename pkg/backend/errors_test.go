@@ -0,0 +1,43 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func TestLockHolderString(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, "held by an unknown user", LockHolder{}.String())
+	assert.Equal(t, "held by alice running update since 2021-06-01T12:00:00Z",
+		LockHolder{User: "alice", Kind: apitype.UpdateUpdate, Since: since}.String())
+	assert.Equal(t, "file:///state/.pulumi/locks/dev/1.json: held by bob@ci (pid 42) since 2021-06-01T12:00:00Z",
+		LockHolder{Lock: "file:///state/.pulumi/locks/dev/1.json", User: "bob", Host: "ci", PID: 42, Since: since}.String())
+
+	err := ConflictingUpdateError{
+		Err:     errors.New("the stack is locked"),
+		Holders: []LockHolder{{User: "alice"}, {User: "bob"}},
+	}
+	assert.True(t, strings.HasPrefix(err.Error(), "the stack is locked\n  held by alice\n  held by bob\n"))
+}
@@ -24,6 +24,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -62,6 +63,16 @@ import (
 type Backend interface {
 	backend.Backend
 	local() // at the moment, no local specific info, so just use a marker function.
+
+	// AddToHistory records an operation that changed a stack outside of an update, such as a rotation of its secrets,
+	// in the stack's history.
+	AddToHistory(ctx context.Context, stackRef backend.StackReference, update backend.UpdateInfo) error
+
+	// VerifyCheckpointSignature checks that the stack's current checkpoint is signed, and matches its signature.
+	VerifyCheckpointSignature(ctx context.Context, stackRef backend.StackReference) error
+
+	// CancelCurrentUpdate clears the locks held on the stack by updates that are no longer running.
+	CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference, force bool) ([]backend.LockHolder, error)
 }
 
 type localBackend struct {
@@ -79,6 +90,10 @@ type localBackend struct {
 	lockID string
 }
 
+// Assert we implement the backend.SpecificDeploymentExporter and backend.LockInspector interfaces.
+var _ backend.SpecificDeploymentExporter = &localBackend{}
+var _ backend.LockInspector = &localBackend{}
+
 type localBackendReference struct {
 	name tokens.QName
 }
@@ -215,6 +230,12 @@ func Login(d diag.Sink, url string) (Backend, error) {
 
 func (b *localBackend) local() {}
 
+func (b *localBackend) AddToHistory(ctx context.Context, stackRef backend.StackReference,
+	update backend.UpdateInfo) error {
+
+	return b.addToHistory(stackRef.Name(), update)
+}
+
 func (b *localBackend) Name() string {
 	name, err := os.Hostname()
 	contract.IgnoreError(err)
@@ -430,6 +451,7 @@ func (b *localBackend) RenameStack(ctx context.Context, stack backend.Stack,
 	// To remove the old stack, just make a backup of the file and don't write out anything new.
 	file := b.stackPath(stackName)
 	backupTarget(b.bucket, file)
+	removeCheckpointSignature(b.bucket, file)
 
 	// And rename the histoy folder as well.
 	if err = b.renameHistory(stackName, newName); err != nil {
@@ -464,7 +486,7 @@ func (b *localBackend) Preview(ctx context.Context, stack backend.Stack,
 	op backend.UpdateOperation) (engine.ResourceChanges, result.Result) {
 
 	if cmdutil.IsTruthy(os.Getenv(PulumiFilestateLockingEnvVar)) {
-		err := b.Lock(ctx, stack.Ref())
+		err := b.lockForUpdate(ctx, stack.Ref(), apitype.PreviewUpdate, op.Opts.WaitForLock)
 		if err != nil {
 			return nil, result.FromError(err)
 		}
@@ -483,7 +505,7 @@ func (b *localBackend) Update(ctx context.Context, stack backend.Stack,
 	op backend.UpdateOperation) (engine.ResourceChanges, result.Result) {
 
 	if cmdutil.IsTruthy(os.Getenv(PulumiFilestateLockingEnvVar)) {
-		err := b.Lock(ctx, stack.Ref())
+		err := b.lockForUpdate(ctx, stack.Ref(), apitype.UpdateUpdate, op.Opts.WaitForLock)
 		if err != nil {
 			return nil, result.FromError(err)
 		}
@@ -497,7 +519,7 @@ func (b *localBackend) Import(ctx context.Context, stack backend.Stack,
 	op backend.UpdateOperation, imports []deploy.Import) (engine.ResourceChanges, result.Result) {
 
 	if cmdutil.IsTruthy(os.Getenv(PulumiFilestateLockingEnvVar)) {
-		err := b.Lock(ctx, stack.Ref())
+		err := b.lockForUpdate(ctx, stack.Ref(), apitype.ResourceImportUpdate, op.Opts.WaitForLock)
 		if err != nil {
 			return nil, result.FromError(err)
 		}
@@ -512,7 +534,7 @@ func (b *localBackend) Refresh(ctx context.Context, stack backend.Stack,
 	op backend.UpdateOperation) (engine.ResourceChanges, result.Result) {
 
 	if cmdutil.IsTruthy(os.Getenv(PulumiFilestateLockingEnvVar)) {
-		err := b.Lock(ctx, stack.Ref())
+		err := b.lockForUpdate(ctx, stack.Ref(), apitype.RefreshUpdate, op.Opts.WaitForLock)
 		if err != nil {
 			return nil, result.FromError(err)
 		}
@@ -525,7 +547,7 @@ func (b *localBackend) Refresh(ctx context.Context, stack backend.Stack,
 func (b *localBackend) Destroy(ctx context.Context, stack backend.Stack,
 	op backend.UpdateOperation) (engine.ResourceChanges, result.Result) {
 
-	err := b.Lock(ctx, stack.Ref())
+	err := b.lockForUpdate(ctx, stack.Ref(), apitype.DestroyUpdate, op.Opts.WaitForLock)
 	if err != nil {
 		return nil, result.FromError(err)
 	}
@@ -554,9 +576,14 @@ func (b *localBackend) apply(
 	stackName := stackRef.Name()
 	actionLabel := backend.ActionLabel(kind, opts.DryRun)
 
+	stdout := op.Opts.Display.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
 	if !(op.Opts.Display.JSONDisplay || op.Opts.Display.Type == display.DisplayWatch) {
 		// Print a banner so it's clear this is a local deployment.
-		fmt.Printf(op.Opts.Display.Color.Colorize(
+		fmt.Fprintf(stdout, op.Opts.Display.Color.Colorize(
 			colors.SpecHeadline+"%s (%s):"+colors.Reset+"\n"), actionLabel, stackRef)
 	}
 
@@ -602,6 +629,9 @@ func (b *localBackend) apply(
 		BackendClient:   backend.NewBackendClient(b),
 	}
 
+	// Capture the stack's outputs before the update so that we can record how they changed.
+	oldOutputs := engine.StackOutputs(update.GetTarget().Snapshot).Copy()
+
 	// Perform the update
 	start := time.Now().Unix()
 	var changes engine.ResourceChanges
@@ -637,11 +667,20 @@ func (b *localBackend) apply(
 	if updateRes != nil {
 		backendUpdateResult = backend.FailedResult
 	}
+	// Record who performed the update, so that the stack's history can be audited.
+	environment := make(map[string]string, len(op.M.Environment)+1)
+	for k, v := range op.M.Environment {
+		environment[k] = v
+	}
+	if user, err := b.CurrentUser(); err == nil && user != "" {
+		environment[backend.BackendUser] = user
+	}
+
 	info := backend.UpdateInfo{
 		Kind:        kind,
 		StartTime:   start,
 		Message:     op.M.Message,
-		Environment: op.M.Environment,
+		Environment: environment,
 		Config:      update.GetTarget().Config,
 		Result:      backendUpdateResult,
 		EndTime:     end,
@@ -654,6 +693,14 @@ func (b *localBackend) apply(
 	var saveErr error
 	var backupErr error
 	if !opts.DryRun {
+		// The snapshot manager has been closed, so the last snapshot that it saved is the stack's new state. If it
+		// saved none, the update changed nothing.
+		newSnap := persister.saved
+		if newSnap == nil {
+			newSnap = update.GetTarget().Snapshot
+		}
+		info.OutputChanges = engine.DiffStackOutputs(oldOutputs, engine.StackOutputs(newSnap))
+
 		saveErr = b.addToHistory(stackName, info)
 		backupErr = b.backupStack(stackName)
 	}
@@ -699,7 +746,7 @@ func (b *localBackend) apply(
 		}
 
 		if link != "" {
-			fmt.Printf(op.Opts.Display.Color.Colorize(
+			fmt.Fprintf(stdout, op.Opts.Display.Color.Colorize(
 				colors.SpecHeadline+"Permalink: "+
 					colors.Underline+colors.BrightBlue+"%s"+colors.Reset+"\n"), link)
 		}
@@ -792,6 +839,38 @@ func (b *localBackend) ExportDeployment(ctx context.Context,
 	}, nil
 }
 
+// ExportDeploymentForVersion exports the deployment that was recorded for the given version of a stack. As with
+// `pulumi stack history`, versions are positive integers numbering the stack's updates from oldest to newest.
+func (b *localBackend) ExportDeploymentForVersion(
+	ctx context.Context, stk backend.Stack, version string) (*apitype.UntypedDeployment, error) {
+
+	versionNumber, err := strconv.Atoi(version)
+	if err != nil || versionNumber <= 0 {
+		return nil, fmt.Errorf(
+			"%q is not a valid stack version. It should be a positive integer",
+			version)
+	}
+
+	chk, err := b.getHistoricalCheckpoint(stk.Ref().Name(), versionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	sdep := chk.Latest
+	if sdep == nil {
+		sdep = &apitype.DeploymentV3{}
+	}
+	data, err := json.Marshal(sdep)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitype.UntypedDeployment{
+		Version:    3,
+		Deployment: json.RawMessage(data),
+	}, nil
+}
+
 func (b *localBackend) ImportDeployment(ctx context.Context, stk backend.Stack,
 	deployment *apitype.UntypedDeployment) error {
 
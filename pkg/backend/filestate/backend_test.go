@@ -187,3 +187,54 @@ func TestListStacksWithMultiplePassphrases(t *testing.T) {
 	}
 
 }
+
+func TestExportDeploymentForVersion(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "filestatebackend")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	b, err := New(cmdutil.Diag(), "file://"+filepath.ToSlash(tmpDir))
+	assert.NoError(t, err)
+	lb := b.(*localBackend)
+	ctx := context.Background()
+
+	stackRef, err := b.ParseStackReference("a")
+	assert.NoError(t, err)
+	s, err := b.CreateStack(ctx, stackRef, nil)
+	assert.NoError(t, err)
+
+	// Record two updates, the first with one resource and the second with two.
+	var resources []*resource.State
+	for _, name := range []tokens.QName{"r1", "r2"} {
+		resources = append(resources, &resource.State{
+			URN:  resource.NewURN("a", "proj", "", "a:b:c", name),
+			Type: "a:b:c",
+		})
+		manifest := deploy.Manifest{}
+		manifest.Magic = manifest.NewMagic()
+		snap := deploy.NewSnapshot(manifest, nil, resources, nil)
+		_, err = lb.saveStack("a", snap, nil)
+		assert.NoError(t, err)
+		err = lb.addToHistory("a", backend.UpdateInfo{Kind: apitype.UpdateUpdate})
+		assert.NoError(t, err)
+	}
+
+	history, err := b.GetHistory(ctx, stackRef, 0, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, 2, history[0].Version)
+		assert.Equal(t, 1, history[1].Version)
+	}
+
+	for version, count := range map[string]int{"1": 1, "2": 2} {
+		deployment, err := lb.ExportDeploymentForVersion(ctx, s, version)
+		assert.NoError(t, err)
+		var sdep apitype.DeploymentV3
+		assert.NoError(t, json.Unmarshal(deployment.Deployment, &sdep))
+		assert.Len(t, sdep.Resources, count)
+	}
+
+	_, err = lb.ExportDeploymentForVersion(ctx, s, "3")
+	assert.Error(t, err)
+	_, err = lb.ExportDeploymentForVersion(ctx, s, "latest")
+	assert.Error(t, err)
+}
@@ -17,16 +17,18 @@ package filestate
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"os"
 	"os/user"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/fsutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
@@ -35,14 +37,18 @@ import (
 // PulumiFilestateLockingEnvVar is an env var that must be truthy to enable locking when using a filestate backend.
 const PulumiFilestateLockingEnvVar = "PULUMI_SELF_MANAGED_STATE_LOCKING"
 
+// lockPollInterval is how often a stack's lock is checked while waiting for another update to release it.
+var lockPollInterval = 2 * time.Second
+
 type lockContent struct {
-	Pid       int       `json:"pid"`
-	Username  string    `json:"username"`
-	Hostname  string    `json:"hostname"`
-	Timestamp time.Time `json:"timestamp"`
+	Pid       int                `json:"pid"`
+	Username  string             `json:"username"`
+	Hostname  string             `json:"hostname"`
+	Timestamp time.Time          `json:"timestamp"`
+	Kind      apitype.UpdateKind `json:"kind,omitempty"`
 }
 
-func newLockContent() (*lockContent, error) {
+func newLockContent(kind apitype.UpdateKind) (*lockContent, error) {
 	u, err := user.Current()
 	if err != nil {
 		return nil, err
@@ -56,10 +62,12 @@ func newLockContent() (*lockContent, error) {
 		Username:  u.Username,
 		Hostname:  hostname,
 		Timestamp: time.Now(),
+		Kind:      kind,
 	}, nil
 }
 
-// checkForLock looks for any existing locks for this stack, and returns a helpful diagnostic if there is one.
+// checkForLock looks for any existing locks for this stack, and returns a backend.ConflictingUpdateError describing
+// their holders if there are any.
 func (b *localBackend) checkForLock(ctx context.Context, stackRef backend.StackReference) error {
 	allFiles, err := listBucket(b.bucket, stackLockDir(stackRef.Name()))
 	if err != nil {
@@ -77,8 +85,11 @@ func (b *localBackend) checkForLock(ctx context.Context, stackRef backend.StackR
 	}
 
 	if len(lockKeys) > 0 {
-		errorString := fmt.Sprintf("the stack is currently locked by %v lock(s). Either wait for the other "+
-			"process(es) to end or manually delete the lock file(s).", len(lockKeys))
+		conflict := backend.ConflictingUpdateError{
+			Err: fmt.Errorf("the stack is currently locked by %v lock(s). Either wait for the other "+
+				"process(es) to end, pass --wait-for-lock to wait for them, or manually delete the lock file(s).",
+				len(lockKeys)),
+		}
 
 		for _, lock := range lockKeys {
 			content, err := b.bucket.ReadAll(ctx, lock)
@@ -91,27 +102,42 @@ func (b *localBackend) checkForLock(ctx context.Context, stackRef backend.StackR
 				return err
 			}
 
-			errorString += fmt.Sprintf("\n  %v: created by %v@%v (pid %v) at %v",
-				b.url+"/"+lock,
-				l.Username,
-				l.Hostname,
-				l.Pid,
-				l.Timestamp.Format(time.RFC3339),
-			)
+			conflict.Holders = append(conflict.Holders, backend.LockHolder{
+				Lock:  b.url + "/" + lock,
+				User:  l.Username,
+				Host:  l.Hostname,
+				PID:   l.Pid,
+				Kind:  l.Kind,
+				Since: l.Timestamp,
+			})
 		}
 
-		return errors.New(errorString)
+		return conflict
 	}
 	return nil
 }
 
+// LockHolders describes the updates that currently hold locks on the stack.
+func (b *localBackend) LockHolders(ctx context.Context, stackRef backend.StackReference) ([]backend.LockHolder, error) {
+	err := b.checkForLock(ctx, stackRef)
+	if conflict, ok := err.(backend.ConflictingUpdateError); ok {
+		return conflict.Holders, nil
+	}
+	return nil, err
+}
+
 func (b *localBackend) Lock(ctx context.Context, stackRef backend.StackReference) error {
-	//
+	return b.lock(ctx, stackRef, "")
+}
+
+// lock takes the lock on the stack for an update of the given kind, which may be empty for operations that are not
+// updates.
+func (b *localBackend) lock(ctx context.Context, stackRef backend.StackReference, kind apitype.UpdateKind) error {
 	err := b.checkForLock(ctx, stackRef)
 	if err != nil {
 		return err
 	}
-	lockContent, err := newLockContent()
+	lockContent, err := newLockContent(kind)
 	if err != nil {
 		return err
 	}
@@ -131,6 +157,84 @@ func (b *localBackend) Lock(ctx context.Context, stackRef backend.StackReference
 	return nil
 }
 
+// lockForUpdate takes the lock on the stack for an update of the given kind. If another update holds the lock, it
+// waits up to the given duration for it to be released, letting the user know who holds it.
+func (b *localBackend) lockForUpdate(ctx context.Context, stackRef backend.StackReference, kind apitype.UpdateKind,
+	wait time.Duration) error {
+
+	deadline := time.Now().Add(wait)
+	lastHolders := ""
+	for {
+		err := b.lock(ctx, stackRef, kind)
+		conflict, ok := err.(backend.ConflictingUpdateError)
+		if !ok || !time.Now().Add(lockPollInterval).Before(deadline) {
+			return err
+		}
+
+		holders := make([]string, len(conflict.Holders))
+		for i, h := range conflict.Holders {
+			h.Lock = ""
+			holders[i] = h.String()
+		}
+		if msg := strings.Join(holders, ", "); msg != lastHolders {
+			b.d.Infoerrf(diag.Message("", "waiting for lock %s"), msg)
+			lastHolders = msg
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// CancelCurrentUpdate clears the locks held on a stack by updates that are no longer running, so that the stack is
+// ready for further updates. Locks held by processes on this machine are only cleared once those processes have
+// exited. Whether processes on other machines are still running can't be checked, so their locks are only cleared if
+// force is true. The holders of the cleared locks are returned.
+func (b *localBackend) CancelCurrentUpdate(ctx context.Context, stackRef backend.StackReference,
+	force bool) ([]backend.LockHolder, error) {
+
+	err := b.checkForLock(ctx, stackRef)
+	if err == nil {
+		return nil, fmt.Errorf("stack '%s' has no update in progress", stackRef)
+	}
+	conflict, ok := err.(backend.ConflictingUpdateError)
+	if !ok {
+		return nil, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range conflict.Holders {
+		if h.Host != hostname {
+			if !force {
+				return nil, fmt.Errorf("the update holding lock %s is running on %s, so we can't check that it has "+
+					"stopped; pass --force to cancel it anyway", h.Lock, h.Host)
+			}
+			continue
+		}
+		running, err := cmdutil.ProcessExists(h.PID)
+		if err != nil {
+			return nil, fmt.Errorf("checking whether process %d is running: %w", h.PID, err)
+		}
+		if running {
+			return nil, fmt.Errorf("the update holding lock %s is still running as process %d; stop it before "+
+				"canceling it", h.Lock, h.PID)
+		}
+	}
+
+	for _, h := range conflict.Holders {
+		if err := b.bucket.Delete(ctx, strings.TrimPrefix(h.Lock, b.url+"/")); err != nil {
+			return nil, fmt.Errorf("removing lock %s: %w", h.Lock, err)
+		}
+	}
+	return conflict.Holders, nil
+}
+
 func (b *localBackend) Unlock(ctx context.Context, stackRef backend.StackReference) {
 	err := b.bucket.Delete(ctx, b.lockPath(stackRef.Name()))
 	if err != nil {
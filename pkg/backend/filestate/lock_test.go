@@ -0,0 +1,125 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func TestLockForUpdate(t *testing.T) {
+	oldInterval := lockPollInterval
+	lockPollInterval = 10 * time.Millisecond
+	defer func() { lockPollInterval = oldInterval }()
+
+	// Two backends for the same state directory stand in for two processes.
+	url := "file://" + filepath.ToSlash(t.TempDir())
+	b1, err := New(cmdutil.Diag(), url)
+	require.NoError(t, err)
+	b2, err := New(cmdutil.Diag(), url)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	stackRef, err := b1.ParseStackReference("a")
+	require.NoError(t, err)
+
+	lb1, lb2 := b1.(*localBackend), b2.(*localBackend)
+	require.NoError(t, lb1.lockForUpdate(ctx, stackRef, apitype.DestroyUpdate, 0))
+
+	// Without waiting, the second update fails with a description of the first.
+	err = lb2.lockForUpdate(ctx, stackRef, apitype.UpdateUpdate, 0)
+	conflict, ok := err.(backend.ConflictingUpdateError)
+	require.True(t, ok, "unexpected error: %v", err)
+	require.Len(t, conflict.Holders, 1)
+	assert.Equal(t, apitype.DestroyUpdate, conflict.Holders[0].Kind)
+	assert.Equal(t, os.Getpid(), conflict.Holders[0].PID)
+	assert.False(t, conflict.Holders[0].Since.IsZero())
+
+	// Waiting too briefly also fails.
+	err = lb2.lockForUpdate(ctx, stackRef, apitype.UpdateUpdate, 50*time.Millisecond)
+	assert.IsType(t, backend.ConflictingUpdateError{}, err)
+
+	// Waiting long enough for the first update to finish succeeds.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lb1.Unlock(ctx, stackRef)
+	}()
+	require.NoError(t, lb2.lockForUpdate(ctx, stackRef, apitype.UpdateUpdate, time.Minute))
+	lb2.Unlock(ctx, stackRef)
+}
+
+func TestCancelCurrentUpdate(t *testing.T) {
+	url := "file://" + filepath.ToSlash(t.TempDir())
+	b1, err := New(cmdutil.Diag(), url)
+	require.NoError(t, err)
+	b2, err := New(cmdutil.Diag(), url)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	stackRef, err := b1.ParseStackReference("a")
+	require.NoError(t, err)
+	lb1, lb2 := b1.(*localBackend), b2.(*localBackend)
+
+	// There's nothing to cancel until an update takes the lock.
+	_, err = lb2.CancelCurrentUpdate(ctx, stackRef, false)
+	assert.Error(t, err)
+
+	// A lock held by a running process is never cleared.
+	require.NoError(t, lb1.lockForUpdate(ctx, stackRef, apitype.UpdateUpdate, 0))
+	_, err = lb2.CancelCurrentUpdate(ctx, stackRef, true)
+	assert.Error(t, err)
+
+	// A lock held by a process that has exited is cleared.
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	writeLock := func(hostname string) {
+		content, err := newLockContent(apitype.UpdateUpdate)
+		require.NoError(t, err)
+		content.Pid = cmd.Process.Pid
+		if hostname != "" {
+			content.Hostname = hostname
+		}
+		bytes, err := json.Marshal(content)
+		require.NoError(t, err)
+		require.NoError(t, lb1.bucket.WriteAll(ctx, lb1.lockPath(stackRef.Name()), bytes, nil))
+	}
+	writeLock("")
+	holders, err := lb2.CancelCurrentUpdate(ctx, stackRef, false)
+	require.NoError(t, err)
+	require.Len(t, holders, 1)
+	assert.Equal(t, cmd.Process.Pid, holders[0].PID)
+	assert.NoError(t, lb2.checkForLock(ctx, stackRef))
+
+	// A lock held by a process on another machine is only cleared when forced.
+	writeLock("some-other-host")
+	_, err = lb2.CancelCurrentUpdate(ctx, stackRef, false)
+	assert.Error(t, err)
+	holders, err = lb2.CancelCurrentUpdate(ctx, stackRef, true)
+	require.NoError(t, err)
+	require.Len(t, holders, 1)
+	assert.NoError(t, lb2.checkForLock(ctx, stackRef))
+}
@@ -0,0 +1,191 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"gocloud.dev/gcerrors"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// PulumiCheckpointSigningKeyEnvVar is an env var that holds a key to sign checkpoints with. When it is set, every
+// checkpoint written by a filestate backend is signed with an HMAC-SHA256 of its contents, and every checkpoint read
+// is checked against its signature, so that state files that were changed outside of Pulumi are detected. Only the
+// current checkpoint of a stack is signed; its backups and history are not.
+const PulumiCheckpointSigningKeyEnvVar = "PULUMI_CHECKPOINT_SIGNING_KEY"
+
+// PulumiCheckpointSigningKeyFileEnvVar is an env var that holds the path of a file containing the key to sign
+// checkpoints with, as an alternative to PulumiCheckpointSigningKeyEnvVar.
+const PulumiCheckpointSigningKeyFileEnvVar = "PULUMI_CHECKPOINT_SIGNING_KEY_FILE"
+
+// PulumiAllowUnsignedCheckpointsEnvVar is an env var that, when truthy, lets checkpoints without a signature be read
+// while signing is enabled, so that the checkpoints of stacks written before signing was enabled can be signed by
+// their next update. Checkpoints that do not match their signature are never read.
+const PulumiAllowUnsignedCheckpointsEnvVar = "PULUMI_ALLOW_UNSIGNED_CHECKPOINTS"
+
+// ErrCheckpointSigningDisabled is returned when a checkpoint's signature is verified without a signing key.
+var ErrCheckpointSigningDisabled = fmt.Errorf(
+	"checkpoint signing is not enabled; set %s or %s", PulumiCheckpointSigningKeyEnvVar,
+	PulumiCheckpointSigningKeyFileEnvVar)
+
+// errCheckpointUnsigned is returned when a checkpoint has no signature.
+var errCheckpointUnsigned = errors.New("the checkpoint is not signed")
+
+// ReadSigningKey returns the key in the given file if one is given, and otherwise the given key, without surrounding
+// whitespace. It returns nil if there is no key.
+func ReadSigningKey(keyFile, key string) ([]byte, error) {
+	if keyFile != "" {
+		b, err := ioutil.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading signing key: %w", err)
+		}
+		key = string(b)
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, nil
+	}
+	return []byte(key), nil
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of the given data with the given key.
+func Sign(key, data []byte) string {
+	mac := hmac.New(sha256.New, key)
+	_, err := mac.Write(data)
+	contract.AssertNoError(err)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckSignature returns true if the given signature, as returned by Sign, is that of the given data with the given
+// key.
+func CheckSignature(key, data []byte, signature string) bool {
+	return hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(Sign(key, data)))
+}
+
+// checkpointSigningKey returns the key to sign checkpoints with, or nil if checkpoint signing is not enabled.
+func checkpointSigningKey() ([]byte, error) {
+	key, keyFile := os.Getenv(PulumiCheckpointSigningKeyEnvVar), ""
+	if key == "" {
+		keyFile = os.Getenv(PulumiCheckpointSigningKeyFileEnvVar)
+	}
+	return ReadSigningKey(keyFile, key)
+}
+
+// signaturePath returns the path of the signature of the checkpoint at the given path.
+func signaturePath(checkpointPath string) string {
+	return checkpointPath + ".sig"
+}
+
+// removeCheckpointSignature removes the signature of the checkpoint at the given path, if it has one.
+func removeCheckpointSignature(bucket Bucket, checkpointPath string) {
+	err := bucket.Delete(context.TODO(), signaturePath(checkpointPath))
+	contract.IgnoreError(err) // the checkpoint may not be signed.
+}
+
+// writeCheckpointSignature writes the signature of the checkpoint at the given path if signing is enabled, and
+// otherwise removes the signature of the checkpoint that it replaced, which no longer matches.
+func (b *localBackend) writeCheckpointSignature(checkpointPath string, checkpoint []byte) error {
+	key, err := checkpointSigningKey()
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		removeCheckpointSignature(b.bucket, checkpointPath)
+		return nil
+	}
+	sig := Sign(key, checkpoint)
+	if err := b.bucket.WriteAll(context.TODO(), signaturePath(checkpointPath), []byte(sig), nil); err != nil {
+		return fmt.Errorf("An IO error occurred while writing the checkpoint signature: %w", err)
+	}
+	return nil
+}
+
+// checkCheckpointSignature checks the given checkpoint contents against the signature stored alongside the checkpoint
+// at the given path. It returns ErrCheckpointSigningDisabled if there is no signing key, and errCheckpointUnsigned if
+// the checkpoint has no signature.
+func (b *localBackend) checkCheckpointSignature(checkpointPath string, checkpoint []byte) error {
+	key, err := checkpointSigningKey()
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return ErrCheckpointSigningDisabled
+	}
+
+	sig, err := b.bucket.ReadAll(context.TODO(), signaturePath(checkpointPath))
+	if err != nil {
+		if gcerrors.Code(drillError(err)) == gcerrors.NotFound {
+			return errCheckpointUnsigned
+		}
+		return fmt.Errorf("reading checkpoint signature: %w", err)
+	}
+	if !CheckSignature(key, checkpoint, string(sig)) {
+		return fmt.Errorf("%s: the checkpoint does not match its signature; it may have been modified outside of "+
+			"Pulumi", checkpointPath)
+	}
+	return nil
+}
+
+// verifyCheckpointSignature checks a checkpoint that is about to be used against its signature, if signing is
+// enabled. A checkpoint without a signature is an error, as removing the signature would otherwise be enough to have a
+// modified checkpoint used, unless PULUMI_ALLOW_UNSIGNED_CHECKPOINTS is set to let the checkpoints of stacks written
+// before signing was enabled be read until they are signed the next time they are saved.
+func (b *localBackend) verifyCheckpointSignature(checkpointPath string, checkpoint []byte) error {
+	switch err := b.checkCheckpointSignature(checkpointPath, checkpoint); err {
+	case nil, ErrCheckpointSigningDisabled:
+		return nil
+	case errCheckpointUnsigned:
+		if cmdutil.IsTruthy(os.Getenv(PulumiAllowUnsignedCheckpointsEnvVar)) {
+			b.d.Warningf(diag.Message("",
+				"%s: the checkpoint is not signed; it will be signed the next time it is saved"), checkpointPath)
+			return nil
+		}
+		return fmt.Errorf("%s: %w; set %s to read checkpoints written before signing was enabled until they are "+
+			"next saved", checkpointPath, err, PulumiAllowUnsignedCheckpointsEnvVar)
+	default:
+		return err
+	}
+}
+
+// VerifyCheckpointSignature checks the current checkpoint of a stack against its signature. Unlike the check made
+// whenever a checkpoint is read, a checkpoint without a signature is an error even if unsigned checkpoints are
+// allowed. Only the current checkpoint is signed: the backups and history of a stack are not.
+func (b *localBackend) VerifyCheckpointSignature(ctx context.Context, stackRef backend.StackReference) error {
+	checkpointPath := b.stackPath(stackRef.Name())
+	checkpoint, err := b.bucket.ReadAll(ctx, checkpointPath)
+	if err != nil {
+		return err
+	}
+	if err := b.checkCheckpointSignature(checkpointPath, checkpoint); err != nil {
+		if err == errCheckpointUnsigned {
+			return fmt.Errorf("%s: %w", checkpointPath, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filestate
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func TestCheckpointSigning(t *testing.T) {
+	t.Setenv(PulumiCheckpointSigningKeyEnvVar, "")
+	t.Setenv(PulumiCheckpointSigningKeyFileEnvVar, "")
+
+	b, err := New(cmdutil.Diag(), "file://"+filepath.ToSlash(t.TempDir()))
+	require.NoError(t, err)
+	lb := b.(*localBackend)
+	ctx := context.Background()
+
+	stackRef, err := b.ParseStackReference("a")
+	require.NoError(t, err)
+	_, err = b.CreateStack(ctx, stackRef, nil)
+	require.NoError(t, err)
+
+	save := func() {
+		manifest := deploy.Manifest{}
+		manifest.Magic = manifest.NewMagic()
+		snap := deploy.NewSnapshot(manifest, nil, []*resource.State{{
+			URN:  resource.NewURN("a", "proj", "", "a:b:c", "r1"),
+			Type: "a:b:c",
+		}}, nil)
+		_, err := lb.saveStack("a", snap, nil)
+		require.NoError(t, err)
+	}
+
+	// Without a key, checkpoints are not signed.
+	save()
+	assert.Equal(t, ErrCheckpointSigningDisabled, lb.VerifyCheckpointSignature(ctx, stackRef))
+	exists, err := lb.bucket.Exists(ctx, signaturePath(lb.stackPath("a")))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Once a key is set, unsigned checkpoints are only read if they are explicitly allowed, and always fail explicit
+	// verification.
+	t.Setenv(PulumiCheckpointSigningKeyEnvVar, "signing-key")
+	_, err = lb.getCheckpoint("a")
+	assert.Error(t, err)
+	t.Setenv(PulumiAllowUnsignedCheckpointsEnvVar, "true")
+	_, err = lb.getCheckpoint("a")
+	assert.NoError(t, err)
+	assert.Error(t, lb.VerifyCheckpointSignature(ctx, stackRef))
+	t.Setenv(PulumiAllowUnsignedCheckpointsEnvVar, "")
+
+	// The next save signs the checkpoint.
+	save()
+	assert.NoError(t, lb.VerifyCheckpointSignature(ctx, stackRef))
+	_, _, err = lb.getStack("a")
+	assert.NoError(t, err)
+
+	// A different key doesn't match the signature.
+	t.Setenv(PulumiCheckpointSigningKeyEnvVar, "other-key")
+	assert.Error(t, lb.VerifyCheckpointSignature(ctx, stackRef))
+	_, err = lb.getCheckpoint("a")
+	assert.Error(t, err)
+	t.Setenv(PulumiCheckpointSigningKeyEnvVar, "signing-key")
+
+	// Changing the checkpoint outside of Pulumi is detected, even if integrity checking is disabled.
+	checkpoint, err := lb.bucket.ReadAll(ctx, lb.stackPath("a"))
+	require.NoError(t, err)
+	tampered := bytes.Replace(checkpoint, []byte(`::r1"`), []byte(`::r2"`), 1)
+	require.NotEqual(t, checkpoint, tampered)
+	require.NoError(t, lb.bucket.WriteAll(ctx, lb.stackPath("a"), tampered, nil))
+	assert.Error(t, lb.VerifyCheckpointSignature(ctx, stackRef))
+	_, err = lb.getCheckpoint("a")
+	assert.Error(t, err)
+	DisableIntegrityChecking = true
+	_, err = lb.getCheckpoint("a")
+	DisableIntegrityChecking = false
+	assert.Error(t, err)
+
+	// So is removing its signature.
+	require.NoError(t, lb.bucket.WriteAll(ctx, lb.stackPath("a"), checkpoint, nil))
+	removeCheckpointSignature(lb.bucket, lb.stackPath("a"))
+	_, err = lb.getCheckpoint("a")
+	assert.Error(t, err)
+
+	// Saving a checkpoint without a key removes the signature of the checkpoint it replaces.
+	save()
+	t.Setenv(PulumiCheckpointSigningKeyEnvVar, "")
+	save()
+	exists, err = lb.bucket.Exists(ctx, signaturePath(lb.stackPath("a")))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Removing the stack removes its signature.
+	require.NoError(t, lb.removeStack("a"))
+	exists, err = lb.bucket.Exists(ctx, signaturePath(lb.stackPath("a")))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -26,6 +26,8 @@ type localSnapshotPersister struct {
 	name    tokens.QName
 	backend *localBackend
 	sm      secrets.Manager
+	// saved is the snapshot that was most recently saved, if any.
+	saved *deploy.Snapshot
 }
 
 func (sp *localSnapshotPersister) SecretsManager() secrets.Manager {
@@ -33,9 +35,11 @@ func (sp *localSnapshotPersister) SecretsManager() secrets.Manager {
 }
 
 func (sp *localSnapshotPersister) Save(snapshot *deploy.Snapshot) error {
-	_, err := sp.backend.saveStack(sp.name, snapshot, sp.sm)
-	return err
-
+	if _, err := sp.backend.saveStack(sp.name, snapshot, sp.sm); err != nil {
+		return err
+	}
+	sp.saved = snapshot
+	return nil
 }
 
 func (b *localBackend) newSnapshotPersister(stackName tokens.QName, sm secrets.Manager) *localSnapshotPersister {
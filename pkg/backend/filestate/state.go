@@ -159,6 +159,11 @@ func (b *localBackend) getCheckpoint(stackName tokens.QName) (*apitype.Checkpoin
 	if err != nil {
 		return nil, err
 	}
+	// Unlike the integrity of a snapshot, its signature is always checked: a checkpoint that does not match its
+	// signature may have been changed by anyone, and is not merely broken.
+	if err := b.verifyCheckpointSignature(chkpath, bytes); err != nil {
+		return nil, err
+	}
 
 	return stack.UnmarshalVersionedCheckpointToLatestCheckpoint(bytes)
 }
@@ -221,6 +226,10 @@ func (b *localBackend) saveStack(name tokens.QName, snap *deploy.Snapshot, sm se
 
 	logging.V(7).Infof("Saved stack %s checkpoint to: %s (backup=%s)", name, file, bck)
 
+	if err = b.writeCheckpointSignature(file, byts); err != nil {
+		return "", err
+	}
+
 	// And if we are retaining historical checkpoint information, write it out again
 	if cmdutil.IsTruthy(os.Getenv("PULUMI_RETAIN_CHECKPOINTS")) {
 		if err = b.bucket.WriteAll(context.TODO(), fmt.Sprintf("%v.%v", file, time.Now().UnixNano()), byts, nil); err != nil {
@@ -250,6 +259,7 @@ func (b *localBackend) removeStack(name tokens.QName) error {
 	// Just make a backup of the file and don't write out anything new.
 	file := b.stackPath(name)
 	backupTarget(b.bucket, file)
+	removeCheckpointSignature(b.bucket, file)
 
 	historyDir := b.historyDirectory(name)
 	return removeAllByPrefix(b.bucket, historyDir)
@@ -329,21 +339,13 @@ func (b *localBackend) getHistory(name tokens.QName, pageSize int, page int) ([]
 		return nil, err
 	}
 
-	var historyEntries []*blob.ListObject
-
 	// filter down to just history entries, reversing list to be in most recent order.
 	// listBucket returns the array sorted by file name, but because of how we name files, older updates come before
 	// newer ones.
-	for i := len(allFiles) - 1; i >= 0; i-- {
-		file := allFiles[i]
-		filepath := file.Key
-
-		// ignore checkpoints
-		if !strings.HasSuffix(filepath, ".history.json") {
-			continue
-		}
-
-		historyEntries = append(historyEntries, file)
+	chronological := filterHistoryFiles(allFiles)
+	historyEntries := make([]*blob.ListObject, len(chronological))
+	for i, file := range chronological {
+		historyEntries[len(chronological)-1-i] = file
 	}
 
 	start := 0
@@ -375,12 +377,54 @@ func (b *localBackend) getHistory(name tokens.QName, pageSize int, page int) ([]
 			return nil, fmt.Errorf("reading history file %s: %w", filepath, err)
 		}
 
+		// Updates are numbered from 1, oldest first.
+		update.Version = len(historyEntries) - i
+
 		updates = append(updates, update)
 	}
 
 	return updates, nil
 }
 
+// filterHistoryFiles filters the given history directory listing down to just the update history files, which are
+// returned oldest first.
+func filterHistoryFiles(files []*blob.ListObject) []*blob.ListObject {
+	var entries []*blob.ListObject
+	for _, file := range files {
+		// ignore checkpoints
+		if strings.HasSuffix(file.Key, ".history.json") {
+			entries = append(entries, file)
+		}
+	}
+	return entries
+}
+
+// getHistoricalCheckpoint returns the checkpoint that was recorded for the given version of a stack, where versions
+// are numbered from 1, oldest first, as in getHistory.
+func (b *localBackend) getHistoricalCheckpoint(name tokens.QName, version int) (*apitype.CheckpointV3, error) {
+	contract.Require(name != "", "name")
+
+	allFiles, err := listBucket(b.bucket, b.historyDirectory(name))
+	if err != nil && gcerrors.Code(drillError(err)) != gcerrors.NotFound {
+		return nil, err
+	}
+
+	entries := filterHistoryFiles(allFiles)
+	if version < 1 || version > len(entries) {
+		return nil, fmt.Errorf("stack '%s' has no version %d", name, version)
+	}
+
+	// The checkpoint copy shares its prefix with the history file it was recorded alongside.
+	historyFile := entries[version-1].Key
+	checkpointFile := strings.TrimSuffix(historyFile, ".history.json") + ".checkpoint.json"
+	bytes, err := b.bucket.ReadAll(context.TODO(), checkpointFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint file %s: %w", checkpointFile, err)
+	}
+
+	return stack.UnmarshalVersionedCheckpointToLatestCheckpoint(bytes)
+}
+
 func (b *localBackend) renameHistory(oldName tokens.QName, newName tokens.QName) error {
 	contract.Require(oldName != "", "oldName")
 	contract.Require(newName != "", "newName")
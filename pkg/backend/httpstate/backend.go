@@ -119,8 +119,9 @@ type cloudBackend struct {
 	currentProject *workspace.Project
 }
 
-// Assert we implement the backend.Backend and backend.SpecificDeploymentExporter interfaces.
+// Assert we implement the backend.Backend, backend.SpecificDeploymentExporter, and backend.LockInspector interfaces.
 var _ backend.SpecificDeploymentExporter = &cloudBackend{}
+var _ backend.LockInspector = &cloudBackend{}
 
 // New creates a new Pulumi backend for the given cloud API URL and token.
 func New(d diag.Sink, cloudURL string) (Backend, error) {
@@ -911,12 +912,8 @@ func (b *cloudBackend) createAndStartUpdate(
 	if err != nil {
 		return client.UpdateIdentifier{}, 0, "", fmt.Errorf("getting stack tags: %w", err)
 	}
-	version, token, err := b.client.StartUpdate(ctx, update, tags)
+	version, token, err := b.startUpdate(ctx, stackRef, update, tags, op.Opts.WaitForLock)
 	if err != nil {
-		if err, ok := err.(*apitype.ErrorResponse); ok && err.Code == 409 {
-			conflict := backend.ConflictingUpdateError{Err: err}
-			return client.UpdateIdentifier{}, 0, "", conflict
-		}
 		return client.UpdateIdentifier{}, 0, "", err
 	}
 	// Any non-preview update will be considered part of the stack's update history.
@@ -927,6 +924,72 @@ func (b *cloudBackend) createAndStartUpdate(
 	return update, version, token, nil
 }
 
+// lockPollInterval is how often we try to start an update again while waiting for another update of the stack to
+// finish.
+var lockPollInterval = 5 * time.Second
+
+// startUpdate starts the given update. If another update of the stack is in progress, it waits up to the given
+// duration for it to finish, letting the user know who is running it, before failing with a
+// backend.ConflictingUpdateError that describes it.
+func (b *cloudBackend) startUpdate(ctx context.Context, stackRef backend.StackReference,
+	update client.UpdateIdentifier, tags map[apitype.StackTagName]string, wait time.Duration) (int, string, error) {
+
+	deadline := time.Now().Add(wait)
+	waiting := false
+	for {
+		version, token, err := b.client.StartUpdate(ctx, update, tags)
+		if errResp, ok := err.(*apitype.ErrorResponse); !ok || errResp.Code != http.StatusConflict {
+			return version, token, err
+		}
+
+		conflict := backend.ConflictingUpdateError{Err: err, Holders: b.getLockHolders(ctx, stackRef)}
+		if !time.Now().Add(lockPollInterval).Before(deadline) {
+			return 0, "", conflict
+		}
+		if !waiting {
+			holder := "held by another update"
+			if len(conflict.Holders) > 0 {
+				holder = conflict.Holders[0].String()
+			}
+			b.d.Infoerrf(diag.Message("", "waiting for lock %s"), holder)
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, "", conflict
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// getLockHolders describes the update of the stack that is in progress, if we can find it. Previews are not recorded
+// in the stack's history, so nothing is returned for those.
+func (b *cloudBackend) getLockHolders(ctx context.Context, stackRef backend.StackReference) []backend.LockHolder {
+	updates, err := b.GetHistory(ctx, stackRef, 1 /*pageSize*/, 1 /*page*/)
+	if err != nil || len(updates) == 0 || updates[0].Result != backend.InProgressResult {
+		return nil
+	}
+
+	update := updates[0]
+	holder := backend.LockHolder{
+		Kind:  update.Kind,
+		Since: time.Unix(update.StartTime, 0),
+	}
+	for _, key := range []string{backend.CIActor, backend.GitAuthor, backend.GitCommitter} {
+		if user := update.Environment[key]; user != "" {
+			holder.User = user
+			break
+		}
+	}
+	return []backend.LockHolder{holder}
+}
+
+// LockHolders describes the update of the stack that is in progress, if there is one.
+func (b *cloudBackend) LockHolders(ctx context.Context, stackRef backend.StackReference) ([]backend.LockHolder, error) {
+	return b.getLockHolders(ctx, stackRef), nil
+}
+
 // apply actually performs the provided type of update on a stack hosted in the Pulumi Cloud.
 func (b *cloudBackend) apply(
 	ctx context.Context, kind apitype.UpdateKind, stack backend.Stack,
@@ -935,9 +998,14 @@ func (b *cloudBackend) apply(
 
 	actionLabel := backend.ActionLabel(kind, opts.DryRun)
 
+	stdout := op.Opts.Display.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+
 	if !(op.Opts.Display.JSONDisplay || op.Opts.Display.Type == display.DisplayWatch) {
 		// Print a banner so it's clear this is going to the cloud.
-		fmt.Printf(op.Opts.Display.Color.Colorize(
+		fmt.Fprintf(stdout, op.Opts.Display.Color.Colorize(
 			colors.SpecHeadline+"%s (%s)"+colors.Reset+"\n\n"), actionLabel, stack.Ref())
 	}
 
@@ -968,7 +1036,11 @@ func (b *cloudBackend) printLink(
 		link = b.CloudConsoleURL(base, "previews", update.UpdateID)
 	}
 	if link != "" {
-		fmt.Printf(op.Opts.Display.Color.Colorize(
+		stdout := op.Opts.Display.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		fmt.Fprintf(stdout, op.Opts.Display.Color.Colorize(
 			colors.SpecHeadline+"View Live: "+
 				colors.Underline+colors.BrightBlue+"%s"+colors.Reset+"\n\n"), link)
 	}
@@ -1484,8 +1556,8 @@ type httpstateBackendClient struct {
 
 func (c httpstateBackendClient) GetStackOutputs(ctx context.Context, name string) (resource.PropertyMap, error) {
 	// When using the cloud backend, require that stack references are fully qualified so they
-	// look like "<org>/<project>/<stack>"
-	if strings.Count(name, "/") != 2 {
+	// look like "<org>/<project>/<stack>", unless they refer to a stack in another backend.
+	if _, _, ok := backend.SplitStackReferenceName(name); !ok && strings.Count(name, "/") != 2 {
 		return nil, fmt.Errorf("a stack reference's name should be of the form " +
 			"'<organization>/<project>/<stack>'. See https://pulumi.io/help/stack-reference for more information.")
 	}
@@ -0,0 +1,37 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReadOnly, when true, makes every operation that would write a stack's state, or ask its providers to change
+// resources, fail with ErrReadOnly before it does anything. The CLI sets it for `--read-only` and `PULUMI_READ_ONLY`,
+// so that tools that only inspect stacks can safely run against production.
+var ReadOnly bool
+
+// ErrReadOnly is returned by operations that are refused because ReadOnly is set.
+var ErrReadOnly = errors.New("pulumi is running in read-only mode")
+
+// CheckWritable returns an error wrapping ErrReadOnly if ReadOnly is set, naming the operation that was refused.
+func CheckWritable(operation string) error {
+	if ReadOnly {
+		return fmt.Errorf("cannot %s: %w; remove --read-only and unset PULUMI_READ_ONLY to make changes",
+			operation, ErrReadOnly)
+	}
+	return nil
+}
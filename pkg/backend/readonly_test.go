@@ -0,0 +1,64 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// TestReadOnly does not run in parallel, because it sets the global ReadOnly flag.
+func TestReadOnly(t *testing.T) {
+	ReadOnly = true
+	defer func() { ReadOnly = false }()
+
+	var called bool
+	be := &MockBackend{
+		PreviewF: func(context.Context, Stack, UpdateOperation) (engine.ResourceChanges, result.Result) {
+			called = true
+			return nil, nil
+		},
+		UpdateF: func(context.Context, Stack, UpdateOperation) (engine.ResourceChanges, result.Result) {
+			called = true
+			return nil, nil
+		},
+		UpdateStackTagsF: func(context.Context, Stack, map[apitype.StackTagName]string) error {
+			called = true
+			return nil
+		},
+	}
+	s := &MockStack{BackendF: func() Backend { return be }}
+	ctx := context.Background()
+
+	// Previews don't change anything, so they are still allowed.
+	_, res := PreviewStack(ctx, s, UpdateOperation{})
+	assert.Nil(t, res)
+	assert.True(t, called)
+
+	called = false
+	_, res = UpdateStack(ctx, s, UpdateOperation{})
+	if assert.NotNil(t, res) {
+		assert.True(t, errors.Is(res.Error(), ErrReadOnly))
+	}
+	assert.True(t, errors.Is(UpdateStackTags(ctx, s, nil), ErrReadOnly))
+	assert.False(t, called)
+}
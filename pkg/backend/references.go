@@ -0,0 +1,87 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Opener opens the backend at the given URL, using the credentials that are stored for that URL rather than those
+// of the current backend.
+type Opener func(ctx context.Context, url string) (Backend, error)
+
+var (
+	openerLock sync.Mutex
+	opener     Opener
+	opened     = map[string]Backend{}
+)
+
+// RegisterOpener sets the function used to open the backends named by stack references to other backends. Until an
+// opener is registered, such references fail to resolve.
+func RegisterOpener(o Opener) {
+	openerLock.Lock()
+	defer openerLock.Unlock()
+	opener, opened = o, map[string]Backend{}
+}
+
+// SplitStackReferenceName splits the name of a stack reference to a stack in another backend, of the form
+// `<backend-url>#<stack>`, into the URL of the backend and the name of the stack within it. It returns false if the
+// name refers to a stack in the current backend.
+func SplitStackReferenceName(name string) (string, string, bool) {
+	i := strings.LastIndex(name, "#")
+	if i <= 0 || i == len(name)-1 || !strings.Contains(name[:i], "://") {
+		return "", "", false
+	}
+	return name[:i], name[i+1:], true
+}
+
+// openBackend returns the backend at the given URL, opening it with the registered opener the first time it is used.
+func openBackend(ctx context.Context, url string) (Backend, error) {
+	openerLock.Lock()
+	defer openerLock.Unlock()
+
+	if b, ok := opened[url]; ok {
+		return b, nil
+	}
+	if opener == nil {
+		return nil, fmt.Errorf("stack references to other backends are not supported; cannot open %s", url)
+	}
+	b, err := opener(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("opening backend %s: %w", url, err)
+	}
+	opened[url] = b
+	return b, nil
+}
+
+// ResolveStackReference returns the backend that manages the stack with the given stack reference name, and the
+// reference to that stack within the backend. Names without a backend URL refer to stacks in the given backend.
+func ResolveStackReference(ctx context.Context, b Backend, name string) (Backend, StackReference, error) {
+	if url, stackName, ok := SplitStackReferenceName(name); ok {
+		other, err := openBackend(ctx, url)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, name = other, stackName
+	}
+	ref, err := b.ParseStackReference(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, ref, nil
+}
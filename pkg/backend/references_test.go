@@ -0,0 +1,90 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestSplitStackReferenceName(t *testing.T) {
+	url, name, ok := SplitStackReferenceName("https://api.pulumi.com#org/proj/dev")
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.pulumi.com", url)
+	assert.Equal(t, "org/proj/dev", name)
+
+	url, name, ok = SplitStackReferenceName("s3://bucket/path?region=us-west-2#dev")
+	assert.True(t, ok)
+	assert.Equal(t, "s3://bucket/path?region=us-west-2", url)
+	assert.Equal(t, "dev", name)
+
+	for _, name := range []string{"dev", "org/proj/dev", "https://api.pulumi.com#", "#dev", "bucket#dev"} {
+		_, _, ok = SplitStackReferenceName(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestGetStackOutputsFromOtherBackend(t *testing.T) {
+	newBackend := func(outputs resource.PropertyMap) *MockBackend {
+		return &MockBackend{
+			ParseStackReferenceF: func(s string) (StackReference, error) {
+				return nil, nil
+			},
+			GetStackF: func(ctx context.Context, stackRef StackReference) (Stack, error) {
+				return &MockStack{
+					SnapshotF: func(ctx context.Context) (*deploy.Snapshot, error) {
+						return &deploy.Snapshot{Resources: []*resource.State{
+							liveState("pulumi:pulumi:Stack", "test-test", outputs),
+						}}, nil
+					},
+				}, nil
+			},
+		}
+	}
+	current := newBackend(resource.PropertyMap{"where": resource.NewStringProperty("current")})
+	other := newBackend(resource.PropertyMap{"where": resource.NewStringProperty("other")})
+
+	client := &backendClient{backend: current}
+
+	// Without an opener, references to other backends cannot be resolved.
+	RegisterOpener(nil)
+	_, err := client.GetStackOutputs(context.Background(), "https://example.com#org/proj/dev")
+	assert.Error(t, err)
+
+	var openedURLs []string
+	RegisterOpener(func(ctx context.Context, url string) (Backend, error) {
+		openedURLs = append(openedURLs, url)
+		return other, nil
+	})
+	defer RegisterOpener(nil)
+
+	outs, err := client.GetStackOutputs(context.Background(), "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, "current", outs["where"].StringValue())
+
+	for i := 0; i < 2; i++ {
+		outs, err = client.GetStackOutputs(context.Background(), "https://example.com#org/proj/dev")
+		assert.NoError(t, err)
+		assert.Equal(t, "other", outs["where"].StringValue())
+	}
+
+	// The other backend is only opened once.
+	assert.Equal(t, []string{"https://example.com"}, openedURLs)
+}
@@ -177,6 +177,7 @@ func (ssm *sameSnapshotMutation) mustWrite(step *deploy.SameStep) bool {
 	contract.Assert(old.Delete == new.Delete)
 	contract.Assert(old.External == new.External)
 	contract.Assert(!step.IsSkippedCreate())
+	contract.Assert(!step.IsExpired())
 
 	// If the URN of this resource has changed, we must write the checkpoint. This should only be possible when a
 	// resource is aliased.
@@ -225,6 +226,25 @@ func (ssm *sameSnapshotMutation) mustWrite(step *deploy.SameStep) bool {
 		return true
 	}
 
+	// If the deprecation of this resource has changed, we must write the checkpoint.
+	if (old.RemoveAfter == nil) != (new.RemoveAfter == nil) ||
+		(old.RemoveAfter != nil && !old.RemoveAfter.Equal(*new.RemoveAfter)) {
+		logging.V(9).Infof("SnapshotManager: mustWrite() true because of RemoveAfter")
+		return true
+	}
+
+	// If the lock on this resource has changed, we must write the checkpoint.
+	if old.Locked != new.Locked {
+		logging.V(9).Infof("SnapshotManager: mustWrite() true because of Locked")
+		return true
+	}
+
+	// If the resource this resource is deleted with has changed, we must write the checkpoint.
+	if old.DeletedWith != new.DeletedWith {
+		logging.V(9).Infof("SnapshotManager: mustWrite() true because of DeletedWith")
+		return true
+	}
+
 	// If the inputs or outputs of this resource have changed, we must write the checkpoint. Note that it is possible
 	// for the inputs of a "same" resource to have changed even if the contents of the input bags are different if the
 	// resource's provider deems the physical change to be semantically irrelevant.
@@ -277,6 +297,12 @@ func (ssm *sameSnapshotMutation) End(step deploy.Step, successful bool) error {
 			return false
 		}
 
+		// Likewise, a deprecated resource that is past its removal date is about to be deleted, so there is no new
+		// state to record.
+		if sameStep.IsExpired() {
+			return false
+		}
+
 		ssm.manager.markNew(step.New())
 
 		// Note that "Same" steps only consider input and provider diffs, so it is possible to see a same step for a
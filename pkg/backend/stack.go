@@ -64,11 +64,17 @@ type Stack interface {
 
 // RemoveStack returns the stack, or returns an error if it cannot.
 func RemoveStack(ctx context.Context, s Stack, force bool) (bool, error) {
+	if err := CheckWritable("remove a stack"); err != nil {
+		return false, err
+	}
 	return s.Backend().RemoveStack(ctx, s, force)
 }
 
 // RenameStack renames the stack, or returns an error if it cannot.
 func RenameStack(ctx context.Context, s Stack, newName tokens.QName) (StackReference, error) {
+	if err := CheckWritable("rename a stack"); err != nil {
+		return nil, err
+	}
 	return s.Backend().RenameStack(ctx, s, newName)
 }
 
@@ -79,6 +85,9 @@ func PreviewStack(ctx context.Context, s Stack, op UpdateOperation) (engine.Reso
 
 // UpdateStack updates the target stack with the current workspace's contents (config and code).
 func UpdateStack(ctx context.Context, s Stack, op UpdateOperation) (engine.ResourceChanges, result.Result) {
+	if err := CheckWritable("update a stack"); err != nil {
+		return nil, result.FromError(err)
+	}
 	return s.Backend().Update(ctx, s, op)
 }
 
@@ -86,22 +95,37 @@ func UpdateStack(ctx context.Context, s Stack, op UpdateOperation) (engine.Resou
 func ImportStack(ctx context.Context, s Stack, op UpdateOperation,
 	imports []deploy.Import) (engine.ResourceChanges, result.Result) {
 
+	if err := CheckWritable("import resources"); err != nil {
+		return nil, result.FromError(err)
+	}
 	return s.Backend().Import(ctx, s, op, imports)
 }
 
 // RefreshStack refresh's the stack's state from the cloud provider.
 func RefreshStack(ctx context.Context, s Stack, op UpdateOperation) (engine.ResourceChanges, result.Result) {
+	if err := CheckWritable("refresh a stack"); err != nil {
+		return nil, result.FromError(err)
+	}
 	return s.Backend().Refresh(ctx, s, op)
 }
 
-// DestroyStack destroys all of this stack's resources.
+// DestroyStack destroys this stack's resources, or those selected by op.Opts.Destroy.
 func DestroyStack(ctx context.Context, s Stack, op UpdateOperation) (engine.ResourceChanges, result.Result) {
+	if err := CheckWritable("destroy a stack"); err != nil {
+		return nil, result.FromError(err)
+	}
+	if err := applyDestroyOptions(ctx, s, &op.Opts); err != nil {
+		return nil, result.FromError(err)
+	}
 	return s.Backend().Destroy(ctx, s, op)
 }
 
 // WatchStack watches the projects working directory for changes and automatically updates the
 // active stack.
 func WatchStack(ctx context.Context, s Stack, op UpdateOperation, paths []string) result.Result {
+	if err := CheckWritable("watch a stack"); err != nil {
+		return result.FromError(err)
+	}
 	return s.Backend().Watch(ctx, s, op, paths)
 }
 
@@ -123,6 +147,9 @@ func ExportStackDeployment(ctx context.Context, s Stack) (*apitype.UntypedDeploy
 
 // ImportStackDeployment imports the given deployment into the indicated stack.
 func ImportStackDeployment(ctx context.Context, s Stack, deployment *apitype.UntypedDeployment) error {
+	if err := CheckWritable("import a deployment"); err != nil {
+		return err
+	}
 	return s.Backend().ImportDeployment(ctx, s, deployment)
 }
 
@@ -133,6 +160,9 @@ func GetStackTags(ctx context.Context, s Stack) (map[apitype.StackTagName]string
 
 // UpdateStackTags updates the stacks's tags, replacing all existing tags.
 func UpdateStackTags(ctx context.Context, s Stack, tags map[apitype.StackTagName]string) error {
+	if err := CheckWritable("update stack tags"); err != nil {
+		return err
+	}
 	return s.Backend().UpdateStackTags(ctx, s, tags)
 }
 
@@ -52,3 +52,55 @@ func SetCurrentStack(name string) error {
 	w.Settings().Stack = name
 	return w.Save()
 }
+
+// ResolveStackAlias returns the stack name that the given alias stands for in the current workspace. If the name is not
+// an alias, or there is no current workspace, the name is returned unchanged.
+func ResolveStackAlias(name string) string {
+	w, err := workspace.New()
+	if err != nil {
+		return name
+	}
+	if stackName, ok := w.Settings().StackAliases[name]; ok {
+		return stackName
+	}
+	return name
+}
+
+// StackAliases returns the stack aliases defined in the current workspace.
+func StackAliases() (map[string]string, error) {
+	w, err := workspace.New()
+	if err != nil {
+		return nil, err
+	}
+	return w.Settings().StackAliases, nil
+}
+
+// SetStackAlias records an alias for the given stack name in the current workspace.
+func SetStackAlias(alias, name string) error {
+	w, err := workspace.New()
+	if err != nil {
+		return err
+	}
+
+	settings := w.Settings()
+	if settings.StackAliases == nil {
+		settings.StackAliases = make(map[string]string)
+	}
+	settings.StackAliases[alias] = name
+	return w.Save()
+}
+
+// RemoveStackAlias removes an alias from the current workspace. It returns false if the alias did not exist.
+func RemoveStackAlias(alias string) (bool, error) {
+	w, err := workspace.New()
+	if err != nil {
+		return false, err
+	}
+
+	settings := w.Settings()
+	if _, ok := settings.StackAliases[alias]; !ok {
+		return false, nil
+	}
+	delete(settings.StackAliases, alias)
+	return true, w.Save()
+}
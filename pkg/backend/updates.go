@@ -85,11 +85,31 @@ const (
 	// CIPRNumber is the PR number, for which the current CI job may be executing.
 	// Combining this information with the `VCSRepoKind` will give us the PR URL.
 	CIPRNumber = "ci.pr.number"
+	// CIActor is the user or account that triggered the CI build, if the CI system reports one.
+	CIActor = "ci.actor"
+
+	// BackendUser is the user of the backend that performed the update, as reported by Backend.CurrentUser.
+	BackendUser = "backend.user"
 
 	// ExecutionKind indicates how the update was executed. One of "cli", "auto.local", or "auto.inline".
 	ExecutionKind = "exec.kind"
 	// ExecutionAgent indicates the user agent of the updater for automated scenarios (GHA, Kubernetes Operator).
 	ExecutionAgent = "exec.agent"
+
+	// ToolchainCLIVersion is the version of the Pulumi CLI that performed the update.
+	ToolchainCLIVersion = "toolchain.cli.version"
+	// ToolchainOS is the operating system on which the update was performed, e.g. "linux" or "darwin".
+	ToolchainOS = "toolchain.os"
+	// ToolchainArch is the processor architecture on which the update was performed, e.g. "amd64" or "arm64".
+	ToolchainArch = "toolchain.arch"
+	// ToolchainRuntime is the language runtime of the project, e.g. "nodejs" or "python".
+	ToolchainRuntime = "toolchain.runtime"
+	// ToolchainRuntimeVersion is the version of the language runtime's executable, e.g. "v14.17.0".
+	ToolchainRuntimeVersion = "toolchain.runtime.version"
+
+	// EphemeralConfig is a comma-separated list of the config keys that were overridden on the command line for a
+	// single update without being saved to the stack's configuration. The values themselves are not recorded.
+	EphemeralConfig = "config.ephemeral"
 )
 
 // UpdateInfo describes a previous update.
@@ -113,4 +133,7 @@ type UpdateInfo struct {
 	Result          UpdateResult           `json:"result"`
 	EndTime         int64                  `json:"endTime"`
 	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
+
+	// OutputChanges records how the stack's outputs changed relative to the previous update, if known.
+	OutputChanges engine.OutputChanges `json:"outputChanges,omitempty"`
 }
@@ -0,0 +1,127 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// approvalGate is an approval gate whose type glob has been compiled.
+type approvalGate struct {
+	typ *regexp.Regexp
+	ops map[deploy.StepOp]bool
+}
+
+// stepApprover asks for each step of an update that matches one of a stack's approval gates to be approved.
+type stepApprover struct {
+	gates       []approvalGate
+	interactive bool
+	// confirm asks the user to approve a step. Only one step is confirmed at a time.
+	confirm func(prompt string) bool
+	lock    sync.Mutex
+}
+
+// newStepApprover returns an approver for the given gates, or nil if there are none. Steps can only be approved when
+// the session is interactive.
+func newStepApprover(gates []workspace.ApprovalGate, interactive bool,
+	confirm func(prompt string) bool) (*stepApprover, error) {
+
+	if len(gates) == 0 {
+		return nil, nil
+	}
+
+	compiled := make([]approvalGate, len(gates))
+	for i, g := range gates {
+		if g.Type == "" {
+			return nil, fmt.Errorf("approval gate %d is missing a 'type' attribute", i)
+		}
+		compiled[i].typ = compileTargetGlob(g.Type)
+		if len(g.Ops) > 0 {
+			compiled[i].ops = make(map[deploy.StepOp]bool)
+			for _, op := range g.Ops {
+				compiled[i].ops[deploy.StepOp(op)] = true
+			}
+		}
+	}
+	return &stepApprover{gates: compiled, interactive: interactive, confirm: confirm}, nil
+}
+
+// gated returns true if a step with the given operation on a resource of the given type must be approved. Gates
+// without operations apply to every step that changes a resource, rather than to sames and reads.
+func (a *stepApprover) gated(op deploy.StepOp, typ tokens.Type) bool {
+	for _, g := range a.gates {
+		if !g.typ.MatchString(string(typ)) {
+			continue
+		}
+		if g.ops != nil {
+			if g.ops[op] {
+				return true
+			}
+			continue
+		}
+		switch op {
+		case deploy.OpSame, deploy.OpRead, deploy.OpReadDiscard, deploy.OpReadReplacement, deploy.OpRefresh:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// approve asks for a step to be approved if it matches a gate, and returns true if it may proceed.
+func (a *stepApprover) approve(step deploy.Step) (bool, error) {
+	if !a.gated(step.Op(), step.Type()) {
+		return true, nil
+	}
+	if !a.interactive {
+		return false, fmt.Errorf("the %s of %s must be approved interactively because of the stack's approval gates",
+			step.Op(), step.URN())
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.confirm(fmt.Sprintf("The stack's approval gates require the %s of %s to be approved.",
+		step.Op(), step.URN())), nil
+}
+
+// applyApprovalGates makes an update of the stack ask for the steps that match the stack's approval gates to be
+// approved one by one, whether or not the update itself was approved with --yes. The prompts are printed between the
+// lines of the non-interactive display, which they would otherwise be drawn over.
+func applyApprovalGates(s backend.Stack, interactive bool, opts *backend.UpdateOptions) error {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return fmt.Errorf("loading stack settings: %w", err)
+	}
+	approver, err := newStepApprover(ps.ApprovalGates, interactive, func(prompt string) bool {
+		return confirmPrompt(prompt, "yes", opts.Display)
+	})
+	if err != nil {
+		return err
+	}
+	if approver == nil {
+		return nil
+	}
+
+	opts.Engine.ApproveStep = approver.approve
+	opts.Display.IsInteractive = false
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestStepApprover(t *testing.T) {
+	approver, err := newStepApprover(nil, true, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, approver)
+
+	_, err = newStepApprover([]workspace.ApprovalGate{{Ops: []string{"delete"}}}, true, nil)
+	assert.Error(t, err)
+
+	var prompts []string
+	answer := true
+	approver, err = newStepApprover([]workspace.ApprovalGate{
+		{Type: "aws:rds/instance:Instance", Ops: []string{"delete", "replace"}},
+		{Type: "aws:s3/*"},
+	}, true, func(prompt string) bool {
+		prompts = append(prompts, prompt)
+		return answer
+	})
+	assert.NoError(t, err)
+
+	assert.True(t, approver.gated(deploy.OpDelete, "aws:rds/instance:Instance"))
+	assert.False(t, approver.gated(deploy.OpUpdate, "aws:rds/instance:Instance"))
+	assert.True(t, approver.gated(deploy.OpUpdate, "aws:s3/bucket:Bucket"))
+	assert.False(t, approver.gated(deploy.OpSame, "aws:s3/bucket:Bucket"))
+	assert.False(t, approver.gated(deploy.OpDelete, "aws:ec2/instance:Instance"))
+
+	db := deploy.NewDeleteStep(nil, &resource.State{
+		URN:      "urn:pulumi:dev::proj::aws:rds/instance:Instance::db",
+		Type:     "aws:rds/instance:Instance",
+		ID:       "db-1",
+		Custom:   true,
+		Provider: "urn:pulumi:dev::proj::pulumi:providers:aws::default::0",
+	})
+	web := deploy.NewDeleteStep(nil, &resource.State{
+		URN:      "urn:pulumi:dev::proj::aws:ec2/instance:Instance::web",
+		Type:     "aws:ec2/instance:Instance",
+		ID:       "i-1",
+		Custom:   true,
+		Provider: "urn:pulumi:dev::proj::pulumi:providers:aws::default::0",
+	})
+
+	approved, err := approver.approve(web)
+	assert.NoError(t, err)
+	assert.True(t, approved)
+	assert.Empty(t, prompts)
+
+	approved, err = approver.approve(db)
+	assert.NoError(t, err)
+	assert.True(t, approved)
+	assert.Len(t, prompts, 1)
+
+	answer = false
+	approved, err = approver.approve(db)
+	assert.NoError(t, err)
+	assert.False(t, approved)
+
+	// Gated steps cannot be approved without a terminal to approve them.
+	approver.interactive = false
+	_, err = approver.approve(db)
+	assert.Error(t, err)
+	assert.Len(t, prompts, 2)
+}
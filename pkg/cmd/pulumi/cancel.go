@@ -21,7 +21,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/v3/backend/httpstate"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
@@ -30,6 +32,7 @@ import (
 func newCancelCmd() *cobra.Command {
 	var yes bool
 	var stack string
+	var force bool
 	var cmd = &cobra.Command{
 		Use:   "cancel [<stack-name>]",
 		Args:  cmdutil.MaximumNArgs(1),
@@ -41,7 +44,13 @@ func newCancelCmd() *cobra.Command {
 			"inconsistent state if a resource operation was pending when the update was canceled.\n" +
 			"\n" +
 			"After this command completes successfully, the stack will be ready for further\n" +
-			"updates.",
+			"updates.\n" +
+			"\n" +
+			"For stacks in self-managed backends, this command clears the locks left behind by\n" +
+			"updates whose processes are no longer running, for example because they crashed or\n" +
+			"the machine running them was shut down. Locks held by processes that are still running\n" +
+			"on this machine are never cleared. Whether processes on other machines are still\n" +
+			"running can't be checked, so their locks are only cleared if --force is passed.",
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			// Use the stack provided or, if missing, default to the current one.
 			if len(args) > 0 {
@@ -61,10 +70,14 @@ func newCancelCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			// Ensure that we are targeting the Pulumi cloud.
-			backend, ok := s.Backend().(httpstate.Backend)
-			if !ok {
-				return result.Error("the `cancel` command is not supported for local stacks")
+			_, isCloud := s.Backend().(httpstate.Backend)
+			localBackend, isLocal := s.Backend().(filestate.Backend)
+			if !isCloud && !isLocal {
+				return result.Errorf("the `cancel` command is not supported for the %s backend", s.Backend().Name())
+			}
+
+			if err := backend.CheckWritable("cancel an update"); err != nil {
+				return result.FromError(err)
 			}
 
 			// Ensure the user really wants to do this.
@@ -76,7 +89,16 @@ func newCancelCmd() *cobra.Command {
 			}
 
 			// Cancel the update.
-			if err := backend.CancelCurrentUpdate(commandContext(), s.Ref()); err != nil {
+			if isLocal {
+				holders, err := localBackend.CancelCurrentUpdate(commandContext(), s.Ref(), force)
+				if err != nil {
+					return result.FromError(err)
+				}
+				for _, h := range holders {
+					fmt.Printf("Cleared lock %s\n", h)
+				}
+			} else if err := s.Backend().(httpstate.Backend).CancelCurrentUpdate(
+				commandContext(), s.Ref()); err != nil {
 				return result.FromError(err)
 			}
 
@@ -92,6 +114,9 @@ func newCancelCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&yes, "yes", "y", false,
 		"Skip confirmation prompts, and proceed with cancellation anyway")
+	cmd.PersistentFlags().BoolVarP(
+		&force, "force", "f", false,
+		"For self-managed backends, also clear locks held by processes on other machines, which can't be checked")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -0,0 +1,283 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// stackReferenceType is the type token of the resource a program uses to read the outputs of another stack.
+const stackReferenceType tokens.Type = "pulumi:pulumi:StackReference"
+
+// cascadeOperation is an operation that `--cascade` applies to a stack and the stacks that depend on it.
+type cascadeOperation string
+
+const (
+	// cascadeUpdate updates the stacks in dependency order, so that each stack sees the new outputs of the stacks it
+	// depends on.
+	cascadeUpdate cascadeOperation = "update"
+	// cascadeDestroy destroys the stacks in reverse dependency order, so that no stack outlives a stack it depends on.
+	cascadeDestroy cascadeOperation = "destroy"
+)
+
+// stackGraph records the stacks of a project and the stacks that each of them depends on.
+type stackGraph struct {
+	refs map[string]backend.StackReference
+	deps map[string][]string
+}
+
+// loadStackGraph computes the dependencies between the stacks of a project: those declared by the project's
+// stackDependencies, and those discovered from the StackReferences in each stack's latest snapshot.
+func loadStackGraph(ctx context.Context, b backend.Backend, proj *workspace.Project) (*stackGraph, error) {
+	summaries, err := listProjectStacks(ctx, b, proj.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &stackGraph{refs: map[string]backend.StackReference{}, deps: map[string][]string{}}
+	for _, summary := range summaries {
+		ref := summary.Name()
+		name := string(ref.Name())
+		g.refs[name] = ref
+
+		deps := map[string]bool{}
+		for _, dep := range proj.StackDependencies[name] {
+			deps[dep] = true
+		}
+
+		s, err := b.GetStack(ctx, ref)
+		if err != nil {
+			return nil, fmt.Errorf("getting stack %v: %w", ref, err)
+		}
+		if s != nil {
+			snap, err := s.Snapshot(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("getting snapshot of stack %v: %w", ref, err)
+			}
+			if snap != nil {
+				for _, res := range snap.Resources {
+					if res.Type != stackReferenceType || res.Delete {
+						continue
+					}
+					if v, ok := res.Inputs["name"]; ok && v.IsString() {
+						if dep, ok := referencedStackName(v.StringValue(), proj.Name); ok {
+							deps[dep] = true
+						}
+					}
+				}
+			}
+		}
+
+		delete(deps, name)
+		for dep := range deps {
+			g.deps[name] = append(g.deps[name], dep)
+		}
+		sort.Strings(g.deps[name])
+	}
+
+	// Stacks that are declared by the project but have not been created yet are still part of the graph, so that
+	// operating on them reports that they do not exist rather than silently skipping them.
+	for name, deps := range proj.StackDependencies {
+		if _, ok := g.deps[name]; !ok {
+			g.deps[name] = append([]string(nil), deps...)
+		}
+	}
+	return g, nil
+}
+
+// listProjectStacks returns the summaries of every stack of the given project.
+func listProjectStacks(ctx context.Context, b backend.Backend,
+	project tokens.PackageName) ([]backend.StackSummary, error) {
+
+	name := string(project)
+	return listAllStacks(ctx, b, backend.ListStacksFilter{Project: &name})
+}
+
+// listAllStacks returns the summaries of every stack that matches the given filter, following continuation tokens.
+func listAllStacks(ctx context.Context, b backend.Backend,
+	filter backend.ListStacksFilter) ([]backend.StackSummary, error) {
+
+	var summaries []backend.StackSummary
+	var inContToken backend.ContinuationToken
+	for {
+		page, outContToken, err := b.ListStacks(ctx, filter, inContToken)
+		if err != nil {
+			return nil, fmt.Errorf("could not query backend for stacks: %w", err)
+		}
+		summaries = append(summaries, page...)
+		if outContToken == nil {
+			return summaries, nil
+		}
+		inContToken = outContToken
+	}
+}
+
+// referencedStackName returns the name of the stack of the given project that a StackReference name refers to, if
+// any. The name may be a bare stack name or a fully qualified `org/project/stack` name.
+func referencedStackName(name string, project tokens.PackageName) (string, bool) {
+	parts := strings.Split(name, "/")
+	switch len(parts) {
+	case 1:
+		return name, true
+	case 3:
+		if parts[1] == string(project) {
+			return parts[2], true
+		}
+	}
+	return "", false
+}
+
+// cascadeOrder returns the given stack and every stack that transitively depends on it, ordered such that each
+// stack follows the stacks it depends on.
+func cascadeOrder(root string, deps map[string][]string) ([]string, error) {
+	dependents := map[string][]string{}
+	for name, ds := range deps {
+		for _, d := range ds {
+			dependents[d] = append(dependents[d], name)
+		}
+	}
+
+	included := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, d := range dependents[name] {
+			if !included[d] {
+				included[d] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(included))
+	for name := range included {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var order []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("the dependencies of stack %v form a cycle", name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		ds := append([]string(nil), deps[name]...)
+		sort.Strings(ds)
+		for _, d := range ds {
+			if included[d] {
+				if err := visit(d); err != nil {
+					return err
+				}
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// runCascade runs an operation on the given stack and every stack of its project that depends on it, stopping at the
+// first failure, and then prints the outcome for each stack. Each run is responsible for its own approval.
+func runCascade(stackName string, opts display.Options, op cascadeOperation,
+	run func(stackName string) result.Result) result.Result {
+
+	s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+	if err != nil {
+		return result.FromError(err)
+	}
+	proj, _, err := readProject()
+	if err != nil {
+		return result.FromError(err)
+	}
+
+	ctx := commandContext()
+	g, err := loadStackGraph(ctx, s.Backend(), proj)
+	if err != nil {
+		return result.FromError(err)
+	}
+	order, err := cascadeOrder(string(s.Ref().Name()), g.deps)
+	if err != nil {
+		return result.FromError(err)
+	}
+	if op == cascadeDestroy {
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+
+	fmt.Printf(opts.Color.Colorize(colors.SpecHeadline+"Cascading the %s to %d stacks: %s"+colors.Reset+"\n\n"),
+		op, len(order), strings.Join(order, ", "))
+
+	outcomes := make([]string, len(order))
+	for i := range outcomes {
+		outcomes[i] = "skipped"
+	}
+	var res result.Result
+	for i, name := range order {
+		qualified := name
+		if ref, ok := g.refs[name]; ok {
+			qualified = ref.String()
+		}
+
+		if res = run(qualified); res != nil {
+			outcomes[i] = "failed"
+			break
+		}
+		outcomes[i] = "succeeded"
+	}
+
+	printStackOutcomes(opts, "Cascade summary", order, outcomes)
+	return res
+}
+
+// printStackOutcomes prints the outcome of a multi-stack operation for each of the named stacks.
+func printStackOutcomes(opts display.Options, title string, names, outcomes []string) {
+	fmt.Printf(opts.Color.Colorize(colors.SpecHeadline + "\n" + title + ":" + colors.Reset + "\n"))
+	width := 0
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for i, name := range names {
+		fmt.Printf("    %-*s  %s\n", width, name, outcomes[i])
+	}
+}
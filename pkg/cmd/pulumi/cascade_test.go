@@ -0,0 +1,70 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCascadeOrder(t *testing.T) {
+	// network <- cluster <- app, network <- db <- app, and an unrelated stack.
+	deps := map[string][]string{
+		"network": nil,
+		"cluster": {"network"},
+		"db":      {"network"},
+		"app":     {"cluster", "db"},
+		"other":   {"unrelated"},
+	}
+
+	order, err := cascadeOrder("network", deps)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"network", "cluster", "db", "app"}, order)
+
+	order, err = cascadeOrder("db", deps)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"db", "app"}, order)
+
+	order, err = cascadeOrder("app", deps)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app"}, order)
+}
+
+func TestCascadeOrderCycle(t *testing.T) {
+	deps := map[string][]string{
+		"a": {"c"},
+		"b": {"a"},
+		"c": {"b"},
+	}
+	_, err := cascadeOrder("a", deps)
+	assert.Error(t, err)
+}
+
+func TestReferencedStackName(t *testing.T) {
+	name, ok := referencedStackName("dev", "proj")
+	assert.True(t, ok)
+	assert.Equal(t, "dev", name)
+
+	name, ok = referencedStackName("org/proj/dev", "proj")
+	assert.True(t, ok)
+	assert.Equal(t, "dev", name)
+
+	_, ok = referencedStackName("org/other/dev", "proj")
+	assert.False(t, ok)
+
+	_, ok = referencedStackName("proj/dev", "proj")
+	assert.False(t, ok)
+}
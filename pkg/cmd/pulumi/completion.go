@@ -0,0 +1,204 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/state"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// completionCacheDir is the directory in the Pulumi home directory that caches the values that flags are completed
+// with, so that completing them doesn't query the backend on every keypress.
+const completionCacheDir = "completion"
+
+// completionCacheTTL is how long the cached values that flags are completed with are used before they are refreshed.
+var completionCacheTTL = 5 * time.Minute
+
+// urnFlags are the names of the flags that take resource URNs, which are completed from the stack's snapshot.
+var urnFlags = []string{"target", "target-replace", "replace"}
+
+// registerDynamicCompletions registers completion functions for each command in the tree rooted at cmd that has a
+// --stack flag, which is completed with the names of the backend's stacks, or a flag that takes resource URNs, which
+// is completed with the URNs of the resources in the selected stack.
+func registerDynamicCompletions(cmd *cobra.Command) {
+	register := func(name string, complete func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)) {
+		// Only register flags that the command itself defines; flags inherited from a parent command are registered
+		// with the parent.
+		if cmd.PersistentFlags().Lookup(name) == nil && cmd.LocalNonPersistentFlags().Lookup(name) == nil {
+			return
+		}
+		if err := cmd.RegisterFlagCompletionFunc(name, complete); err != nil {
+			logging.V(7).Infof("could not register completion for --%s of %s: %v", name, cmd.CommandPath(), err)
+		}
+	}
+
+	register("stack", completeStackNames)
+	for _, name := range urnFlags {
+		register(name, completeResourceURNs)
+	}
+	for _, child := range cmd.Commands() {
+		registerDynamicCompletions(child)
+	}
+}
+
+// completeStackNames completes the names of the current project's stacks, or of all stacks if there is no current
+// project.
+func completeStackNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	b, err := currentBackend(display.Options{Color: cmdutil.GetGlobalColorization()})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var filter backend.ListStacksFilter
+	if proj, _, err := readProject(); err == nil {
+		name := string(proj.Name)
+		filter.Project = &name
+	}
+
+	key := b.URL() + "\x00stacks"
+	if filter.Project != nil {
+		key += "\x00" + *filter.Project
+	}
+	names, ok := readCompletionCache(key)
+	if !ok {
+		summaries, err := listAllStacks(commandContext(), b, filter)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		for _, summary := range summaries {
+			names = append(names, summary.Name().String())
+		}
+		writeCompletionCache(key, names)
+	}
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeResourceURNs completes the URNs of the resources in the stack selected by the command's --stack flag, or of
+// the current stack if the flag isn't set.
+func completeResourceURNs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx := commandContext()
+	b, err := currentBackend(display.Options{Color: cmdutil.GetGlobalColorization()})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var s backend.Stack
+	if name, _ := cmd.Flags().GetString("stack"); name != "" {
+		ref, err := b.ParseStackReference(name)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		s, err = b.GetStack(ctx, ref)
+	} else {
+		s, err = state.CurrentStack(ctx, b)
+	}
+	if err != nil || s == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	key := b.URL() + "\x00urns\x00" + s.Ref().String()
+	urns, ok := readCompletionCache(key)
+	if !ok {
+		snap, err := s.Snapshot(ctx)
+		if err != nil || snap == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		for _, res := range snap.Resources {
+			urns = append(urns, string(res.URN))
+		}
+		writeCompletionCache(key, urns)
+	}
+	return filterCompletions(urns, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterCompletions returns the sorted values that start with the given prefix.
+func filterCompletions(values []string, prefix string) []string {
+	var result []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// completionCacheEntry is the on disk format of a set of cached completion values.
+type completionCacheEntry struct {
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+}
+
+// completionCachePath returns the path of the file that caches the completion values with the given key.
+func completionCachePath(key string) (string, error) {
+	sum := sha256.Sum256([]byte(key))
+	return workspace.GetPulumiPath(completionCacheDir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// readCompletionCache returns the cached completion values with the given key, if they were cached recently enough.
+func readCompletionCache(key string) ([]string, bool) {
+	path, err := completionCachePath(key)
+	if err != nil {
+		return nil, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > completionCacheTTL {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var entry completionCacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil || entry.Key != key {
+		return nil, false
+	}
+	return entry.Values, true
+}
+
+// writeCompletionCache caches the completion values with the given key. Failing to cache them only makes the next
+// completion slower, so errors are logged rather than returned.
+func writeCompletionCache(key string, values []string) {
+	path, err := completionCachePath(key)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+	}
+	var b []byte
+	if err == nil {
+		b, err = json.Marshal(completionCacheEntry{Key: key, Values: values})
+	}
+	if err == nil {
+		err = ioutil.WriteFile(path, b, 0600)
+	}
+	if err != nil {
+		logging.V(7).Infof("could not cache completions: %v", err)
+	}
+}
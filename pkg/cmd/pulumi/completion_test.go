@@ -0,0 +1,87 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestFilterCompletions(t *testing.T) {
+	urns := []string{
+		"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b",
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::a",
+		"urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev",
+	}
+	assert.Equal(t, []string{
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::a",
+		"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b",
+	}, filterCompletions(urns, "urn:pulumi:dev::proj::aws"))
+	assert.Len(t, filterCompletions(urns, ""), 3)
+	assert.Empty(t, filterCompletions(urns, "urn:pulumi:prod"))
+}
+
+func TestCompletionCache(t *testing.T) {
+	home, err := ioutil.TempDir("", "pulumi-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	old, had := os.LookupEnv(workspace.PulumiHomeEnvVar)
+	os.Setenv(workspace.PulumiHomeEnvVar, home)
+	defer func() {
+		if had {
+			os.Setenv(workspace.PulumiHomeEnvVar, old)
+		} else {
+			os.Unsetenv(workspace.PulumiHomeEnvVar)
+		}
+	}()
+
+	_, ok := readCompletionCache("stacks")
+	assert.False(t, ok)
+
+	writeCompletionCache("stacks", []string{"dev", "prod"})
+	values, ok := readCompletionCache("stacks")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"dev", "prod"}, values)
+
+	_, ok = readCompletionCache("urns")
+	assert.False(t, ok)
+
+	// Expired entries are ignored.
+	ttl := completionCacheTTL
+	completionCacheTTL = -time.Second
+	defer func() { completionCacheTTL = ttl }()
+	_, ok = readCompletionCache("stacks")
+	assert.False(t, ok)
+}
+
+func TestRegisterDynamicCompletions(t *testing.T) {
+	// A flag that is inherited by a child command is only registered once, with the command that defines it.
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().String("stack", "", "")
+	child := &cobra.Command{Use: "child", Run: func(*cobra.Command, []string) {}}
+	child.PersistentFlags().StringArray("target", nil, "")
+	root.AddCommand(child)
+
+	registerDynamicCompletions(root)
+	assert.Error(t, root.RegisterFlagCompletionFunc("stack", completeStackNames))
+	assert.Error(t, child.RegisterFlagCompletionFunc("target", completeResourceURNs))
+}
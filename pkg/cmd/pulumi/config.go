@@ -55,6 +55,10 @@ func newConfigCmd() *cobra.Command {
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
+			if err := checkShowSecretsFlag(showSecrets); err != nil {
+				return err
+			}
+
 			stack, err := requireStack(stack, true, opts, true /*setCurrent*/)
 			if err != nil {
 				return err
@@ -64,9 +68,7 @@ func newConfigCmd() *cobra.Command {
 		}),
 	}
 
-	cmd.Flags().BoolVar(
-		&showSecrets, "show-secrets", false,
-		"Show secret values when listing config instead of displaying blinded values")
+	addShowSecretsFlag(cmd, &showSecrets)
 	cmd.Flags().BoolVarP(
 		&jsonOut, "json", "j", false,
 		"Emit output as JSON")
@@ -84,6 +86,7 @@ func newConfigCmd() *cobra.Command {
 	cmd.AddCommand(newConfigSetAllCmd(&stack))
 	cmd.AddCommand(newConfigRefreshCmd(&stack))
 	cmd.AddCommand(newConfigCopyCmd(&stack))
+	cmd.AddCommand(newConfigEnvCmd(&stack))
 
 	return cmd
 }
@@ -243,15 +246,18 @@ func newConfigGetCmd(stack *string) *cobra.Command {
 	var path bool
 
 	getCmd := &cobra.Command{
-		Use:   "get <key>",
-		Short: "Get a single configuration value",
-		Long: "Get a single configuration value.\n\n" +
+		Use:   "get <key> [key...]",
+		Short: "Get one or more configuration values",
+		Long: "Get one or more configuration values.\n\n" +
 			"The `--path` flag can be used to get a value inside a map or list:\n\n" +
 			"  - `pulumi config get --path outer.inner` will get the value of the `inner` key, " +
 			"if the value of `outer` is a map `inner: value`.\n" +
 			"  - `pulumi config get --path names[0]` will get the value of the first item, " +
-			"if the value of `names` is a list.",
-		Args: cmdutil.SpecificArgs([]string{"key"}),
+			"if the value of `names` is a list.\n\n" +
+			"When more than one key is given, the values are printed as a table of keys and values, or with\n" +
+			"`--json` as an object that maps each key, as written, to its value:\n\n" +
+			"  - `pulumi config get --json --path dbHost db.port names[0]`",
+		Args: cmdutil.MinimumNArgs(1),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -262,12 +268,17 @@ func newConfigGetCmd(stack *string) *cobra.Command {
 				return err
 			}
 
-			key, err := parseConfigKey(args[0])
-			if err != nil {
-				return fmt.Errorf("invalid configuration key: %w", err)
+			keys := make([]config.Key, len(args))
+			for i, arg := range args {
+				if keys[i], err = parseConfigKey(arg); err != nil {
+					return fmt.Errorf("invalid configuration key '%s': %w", arg, err)
+				}
 			}
 
-			return getConfig(s, key, path, jsonOut)
+			if len(keys) == 1 {
+				return getConfig(s, keys[0], path, jsonOut)
+			}
+			return getConfigs(s, args, keys, path, jsonOut)
 		}),
 	}
 	getCmd.Flags().BoolVarP(
@@ -275,7 +286,7 @@ func newConfigGetCmd(stack *string) *cobra.Command {
 		"Emit output as JSON")
 	getCmd.PersistentFlags().BoolVar(
 		&path, "path", false,
-		"The key contains a path to a property in a map or list to get")
+		"The keys contain paths to properties in maps or lists to get")
 
 	return getCmd
 }
@@ -531,6 +542,17 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 				}
 			}
 
+			// Values set within a path are checked as part of the whole stack configuration by preview and update.
+			if !path {
+				proj, _, err := readProject()
+				if err != nil {
+					return err
+				}
+				if err = proj.ValidateConfigValue(key, value, secret); err != nil {
+					return err
+				}
+			}
+
 			ps, err := loadProjectStack(s)
 			if err != nil {
 				return err
@@ -561,6 +583,8 @@ func newConfigSetCmd(stack *string) *cobra.Command {
 func newConfigSetAllCmd(stack *string) *cobra.Command {
 	var plaintextArgs []string
 	var secretArgs []string
+	var plaintextFile string
+	var secretFile string
 	var path bool
 
 	setCmd := &cobra.Command{
@@ -576,7 +600,11 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 			"  - `pulumi config set-all --path --plaintext parent.nested=value --plaintext parent.other=value2` \n" +
 			"    will set the value of `parent` to a map `{nested: value, other: value2}`.\n" +
 			"  - `pulumi config set-all --path --plaintext '[\"parent.name\"].[\"nested.name\"]'=value` will set the \n" +
-			"    value of `parent.name` to a map `nested.name: value`.",
+			"    value of `parent.name` to a map `nested.name: value`.\n\n" +
+			"Values may also be read from a dotenv, JSON, or YAML document with `--file`, or with `--secret-file`\n" +
+			"to encrypt them. The format is chosen by the file's extension, and each name in the document is used\n" +
+			"as a config key:\n\n" +
+			"  - `pulumi config set-all --file settings.yaml --secret-file .env`",
 		Args: cmdutil.NoArgs,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
@@ -594,6 +622,21 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 				return err
 			}
 
+			if plaintextFile != "" {
+				if err = importConfigFile(ps.Config, plaintextFile, nil, path); err != nil {
+					return err
+				}
+			}
+			if secretFile != "" {
+				c, cerr := getStackEncrypter(s)
+				if cerr != nil {
+					return cerr
+				}
+				if err = importConfigFile(ps.Config, secretFile, c, path); err != nil {
+					return err
+				}
+			}
+
 			for _, ptArg := range plaintextArgs {
 				key, value, err := parseKeyValuePair(ptArg)
 				if err != nil {
@@ -641,6 +684,12 @@ func newConfigSetAllCmd(stack *string) *cobra.Command {
 	setCmd.PersistentFlags().StringArrayVar(
 		&secretArgs, "secret", []string{},
 		"Marks a value as secret to be encrypted")
+	setCmd.PersistentFlags().StringVar(
+		&plaintextFile, "file", "",
+		"Read plaintext values from a dotenv, JSON, or YAML file")
+	setCmd.PersistentFlags().StringVar(
+		&secretFile, "secret-file", "",
+		"Read values to be encrypted from a dotenv, JSON, or YAML file")
 
 	return setCmd
 }
@@ -741,9 +790,15 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 
 	cfg := ps.Config
 
-	// By default, we will use a blinding decrypter to show "[secret]". If requested, display secrets in plaintext.
+	var redactor *secretRedactor
+	if jsonOut {
+		redactor = newSecretRedactor(stack)
+	}
+
+	// By default, we will use a blinding decrypter to show "[secret]". If requested, display secrets in plaintext, or
+	// decrypt them to hash them.
 	decrypter := config.NewBlindingDecrypter()
-	if cfg.HasSecureValue() && showSecrets {
+	if cfg.HasSecureValue() && (showSecrets || redactor != nil) {
 		dec, decerr := getStackDecrypter(stack)
 		if decerr != nil {
 			return decerr
@@ -780,10 +835,13 @@ func listConfig(stack backend.Stack, showSecrets bool, jsonOut bool) error {
 				entry.ObjectValue = obj
 			}
 
-			// If the value was a secret value and we aren't showing secrets, then the above would have set value
-			// to "[secret]" which is reasonable when printing for human display, but for our JSON output, we'd rather
-			// just elide the value.
-			if cfg[key].Secure() && !showSecrets {
+			switch {
+			case cfg[key].Secure() && redactor != nil:
+				entry = redactor.redactConfigValue(entry, decrypted)
+			case cfg[key].Secure() && !showSecrets:
+				// If the value was a secret value and we aren't showing secrets, then the above would have set value
+				// to "[secret]" which is reasonable when printing for human display, but for our JSON output, we'd
+				// rather just elide the value.
 				entry.Value = nil
 				entry.ObjectValue = nil
 			}
@@ -820,54 +878,118 @@ func getConfig(stack backend.Stack, key config.Key, path, jsonOut bool) error {
 		return err
 	}
 
-	cfg := ps.Config
-
-	v, ok, err := cfg.Get(key, path)
+	value, raw, ok, err := lookupConfigValue(stack, ps.Config, key, path, nil)
 	if err != nil {
 		return err
 	}
-	if ok {
-		var d config.Decrypter
-		if v.Secure() {
-			var err error
-			if d, err = getStackDecrypter(stack); err != nil {
-				return fmt.Errorf("could not create a decrypter: %w", err)
-			}
-		} else {
-			d = config.NewPanicCrypter()
+	if !ok {
+		return fmt.Errorf("configuration key '%s' not found for stack '%s'", prettyKey(key), stack.Ref())
+	}
+
+	if jsonOut {
+		if redactor := newSecretRedactor(stack); redactor != nil {
+			value = redactor.redactConfigValue(value, raw)
 		}
-		raw, err := v.Value(d)
+		out, err := json.MarshalIndent(value, "", "  ")
 		if err != nil {
-			return fmt.Errorf("could not decrypt configuration value: %w", err)
+			return err
 		}
+		fmt.Println(string(out))
+	} else {
+		fmt.Printf("%v\n", raw)
+	}
+	return nil
+}
 
-		if jsonOut {
-			value := configValueJSON{
-				Value:  &raw,
-				Secret: v.Secure(),
-			}
+// getConfigs prints the values of several config keys, which are named in the output as they were given.
+func getConfigs(stack backend.Stack, names []string, keys []config.Key, path, jsonOut bool) error {
+	ps, err := loadProjectStack(stack)
+	if err != nil {
+		return err
+	}
 
-			if v.Object() {
-				var obj interface{}
-				if err := json.Unmarshal([]byte(raw), &obj); err != nil {
-					return err
-				}
-				value.ObjectValue = obj
-			}
+	var redactor *secretRedactor
+	if jsonOut {
+		redactor = newSecretRedactor(stack)
+	}
 
-			out, err := json.MarshalIndent(value, "", "  ")
-			if err != nil {
-				return err
-			}
-			fmt.Println(string(out))
-		} else {
-			fmt.Printf("%v\n", raw)
+	var decrypter config.Decrypter
+	values := make(map[string]configValueJSON, len(keys))
+	rows := []cmdutil.TableRow{}
+	var missing []string
+	for i, key := range keys {
+		value, raw, ok, err := lookupConfigValue(stack, ps.Config, key, path, &decrypter)
+		if err != nil {
+			return err
 		}
+		if !ok {
+			missing = append(missing, prettyKey(key))
+			continue
+		}
+		if redactor != nil {
+			value = redactor.redactConfigValue(value, raw)
+		}
+		values[names[i]] = value
+		rows = append(rows, cmdutil.TableRow{Columns: []string{names[i], raw}})
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("configuration keys '%s' not found for stack '%s'",
+			strings.Join(missing, "', '"), stack.Ref())
+	}
+
+	if jsonOut {
+		return printJSON(values)
+	}
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"KEY", "VALUE"},
+		Rows:    rows,
+	})
+	return nil
+}
 
-		return nil
+// lookupConfigValue returns the value of a config key, both as JSON and as a raw string, and false if it is not set.
+// Secure values are decrypted with the decrypter that decrypter points to, which is created on first use; if
+// decrypter is nil, one is created for each secure value.
+func lookupConfigValue(stack backend.Stack, cfg config.Map, key config.Key, path bool,
+	decrypter *config.Decrypter) (configValueJSON, string, bool, error) {
+
+	v, ok, err := cfg.Get(key, path)
+	if err != nil || !ok {
+		return configValueJSON{}, "", false, err
 	}
 
-	return fmt.Errorf("configuration key '%s' not found for stack '%s'", prettyKey(key), stack.Ref())
+	var d config.Decrypter
+	if v.Secure() {
+		if decrypter != nil && *decrypter != nil {
+			d = *decrypter
+		} else {
+			if d, err = getStackDecrypter(stack); err != nil {
+				return configValueJSON{}, "", false, fmt.Errorf("could not create a decrypter: %w", err)
+			}
+			if decrypter != nil {
+				*decrypter = d
+			}
+		}
+	} else {
+		d = config.NewPanicCrypter()
+	}
+	raw, err := v.Value(d)
+	if err != nil {
+		return configValueJSON{}, "", false, fmt.Errorf("could not decrypt configuration value: %w", err)
+	}
+
+	value := configValueJSON{
+		Value:  &raw,
+		Secret: v.Secure(),
+	}
+	if v.Object() {
+		var obj interface{}
+		if err := json.Unmarshal([]byte(raw), &obj); err != nil {
+			return configValueJSON{}, "", false, err
+		}
+		value.ObjectValue = obj
+	}
+	return value, raw, true, nil
 }
 
 var (
@@ -911,13 +1033,18 @@ func getStackConfiguration(stack backend.Stack, sm secrets.Manager) (backend.Sta
 	if err != nil {
 		return backend.StackConfiguration{}, fmt.Errorf("loading stack configuration: %w", err)
 	}
+	// The project stack is cached and may be saved later, so the interpolated configuration must not replace its own.
+	cfg, err := resolveConfigEnv(workspaceStack.Config)
+	if err != nil {
+		return backend.StackConfiguration{}, fmt.Errorf("resolving stack configuration: %w", err)
+	}
 
 	// If there are no secrets in the configuration, we should never use the decrypter, so it is safe to return
 	// one which panics if it is used. This provides for some nice UX in the common case (since, for example, building
 	// the correct decrypter for the local backend would involve prompting for a passphrase)
-	if !workspaceStack.Config.HasSecureValue() {
+	if !cfg.HasSecureValue() {
 		return backend.StackConfiguration{
-			Config:    workspaceStack.Config,
+			Config:    cfg,
 			Decrypter: config.NewPanicCrypter(),
 		}, nil
 	}
@@ -928,7 +1055,7 @@ func getStackConfiguration(stack backend.Stack, sm secrets.Manager) (backend.Sta
 	}
 
 	return backend.StackConfiguration{
-		Config:    workspaceStack.Config,
+		Config:    cfg,
 		Decrypter: crypter,
 	}, nil
 }
@@ -0,0 +1,393 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// The formats in which configuration can be exported and imported.
+const (
+	configFormatDotenv = "dotenv"
+	configFormatJSON   = "json"
+	configFormatYAML   = "yaml"
+)
+
+func newConfigEnvCmd(stack *string) *cobra.Command {
+	var format string
+	var showSecrets bool
+
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Export configuration as a dotenv, JSON or YAML document",
+		Long: "Export configuration as a dotenv, JSON or YAML document.\n" +
+			"\n" +
+			"This command writes the stack's configuration, with any environment variables interpolated, to\n" +
+			"standard out. JSON and YAML documents map each key, as shown by `pulumi config`, to its value;\n" +
+			"object values are written as nested documents. In dotenv documents, each key is turned into an\n" +
+			"environment variable name, e.g. `dbHost` becomes `DB_HOST` and `aws:region` becomes `AWS_REGION`.\n" +
+			"\n" +
+			"Secret values are left out unless `--show-secrets` is passed.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(*stack, true, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+			cfg, err := resolveConfigEnv(ps.Config)
+			if err != nil {
+				return err
+			}
+
+			var decrypter config.Decrypter
+			if cfg.HasSecureValue() && showSecrets {
+				if decrypter, err = getStackDecrypter(s); err != nil {
+					return err
+				}
+			}
+
+			values, omitted, err := configValuesForExport(cfg, decrypter)
+			if err != nil {
+				return err
+			}
+			if len(omitted) > 0 {
+				cmdutil.Diag().Warningf(diag.Message("",
+					"omitting secret values for %s; pass --show-secrets to include them"), strings.Join(omitted, ", "))
+			}
+			return writeConfigValues(os.Stdout, values, format)
+		}),
+	}
+
+	cmd.Flags().StringVar(
+		&format, "format", configFormatDotenv,
+		"The format of the document: dotenv, json, or yaml")
+	cmd.Flags().BoolVar(
+		&showSecrets, "show-secrets", false,
+		"Include decrypted secret values")
+
+	return cmd
+}
+
+// configValuesForExport returns the values of the given config by pretty key, decoding object values. Secure values
+// are decrypted with the given decrypter; if it is nil they are left out, and their keys are returned instead.
+func configValuesForExport(cfg config.Map,
+	decrypter config.Decrypter) (map[string]interface{}, []string, error) {
+
+	values := map[string]interface{}{}
+	var omitted []string
+	for key, v := range cfg {
+		name := prettyKey(key)
+		if v.Secure() && decrypter == nil {
+			omitted = append(omitted, name)
+			continue
+		}
+
+		value, err := v.Value(decrypter)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not decrypt configuration value: %w", err)
+		}
+		if !v.Object() {
+			values[name] = value
+			continue
+		}
+		var obj interface{}
+		if err := json.Unmarshal([]byte(value), &obj); err != nil {
+			return nil, nil, err
+		}
+		values[name] = obj
+	}
+	sort.Strings(omitted)
+	return values, omitted, nil
+}
+
+// writeConfigValues writes config values in the given format.
+func writeConfigValues(w io.Writer, values map[string]interface{}, format string) error {
+	switch format {
+	case configFormatDotenv:
+		names := make([]string, 0, len(values))
+		vars := map[string]string{}
+		for key, value := range values {
+			s, ok := value.(string)
+			if !ok {
+				b, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+				s = string(b)
+			}
+			name := configEnvVarName(key)
+			if _, ok := vars[name]; ok {
+				return fmt.Errorf("more than one config key maps to the environment variable %s", name)
+			}
+			names = append(names, name)
+			vars[name] = s
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", name, quoteDotenvValue(vars[name])); err != nil {
+				return err
+			}
+		}
+		return nil
+	case configFormatJSON:
+		jsonStr, err := makeJSONString(values)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(w, jsonStr)
+		return err
+	case configFormatYAML:
+		b, err := yaml.Marshal(values)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return fmt.Errorf("unknown config format %q; expected dotenv, json, or yaml", format)
+	}
+}
+
+// readConfigValues reads config values from a document in the given format. Keys are returned as written.
+func readConfigValues(r io.Reader, format string) (map[string]interface{}, error) {
+	switch format {
+	case configFormatDotenv:
+		return parseDotenv(r)
+	case configFormatJSON, configFormatYAML:
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]interface{}{}
+		if format == configFormatJSON {
+			err = json.Unmarshal(b, &values)
+		} else {
+			err = yaml.Unmarshal(b, &values)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", format, err)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown config format %q; expected dotenv, json, or yaml", format)
+	}
+}
+
+// configFormatForFile returns the format of a config document from its file name.
+func configFormatForFile(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".json":
+		return configFormatJSON, nil
+	case ext == ".yaml" || ext == ".yml":
+		return configFormatYAML, nil
+	case ext == ".env" || strings.HasPrefix(filepath.Base(path), ".env"):
+		return configFormatDotenv, nil
+	default:
+		return "", fmt.Errorf("cannot tell the format of %s from its name; expected a .env, .json, or .yaml file", path)
+	}
+}
+
+// importConfigFile sets every value of the config document at the given path in the given config, encrypting them
+// with the given encrypter if it is not nil.
+func importConfigFile(cfg config.Map, path string, encrypter config.Encrypter, keyPath bool) error {
+	format, err := configFormatForFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer contract.IgnoreClose(f)
+	values, err := readConfigValues(f, format)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key, err := parseConfigKey(name)
+		if err != nil {
+			return fmt.Errorf("invalid configuration key %q in %s: %w", name, path, err)
+		}
+		value, object, err := configValueString(values[name])
+		if err != nil {
+			return err
+		}
+
+		var v config.Value
+		switch {
+		case encrypter != nil && object:
+			return fmt.Errorf("config key '%s' in %s: secret values must be strings", key, path)
+		case encrypter != nil:
+			enc, err := encrypter.EncryptValue(value)
+			if err != nil {
+				return err
+			}
+			v = config.NewSecureValue(enc)
+		case object:
+			v = config.NewObjectValue(value)
+		default:
+			v = config.NewValue(value)
+		}
+		if err = cfg.Set(key, v, keyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configValueString returns the string form of an imported value, and whether it is an object value.
+func configValueString(value interface{}) (string, bool, error) {
+	switch value := value.(type) {
+	case string:
+		return value, false, nil
+	case bool, int, int64, float64, nil:
+		b, err := json.Marshal(value)
+		return string(b), false, err
+	default:
+		b, err := json.Marshal(value)
+		return string(b), true, err
+	}
+}
+
+// configEnvVarName turns a pretty config key into an environment variable name: words are split at namespace
+// delimiters, punctuation and case changes, upper-cased and joined with underscores.
+func configEnvVarName(key string) string {
+	var b strings.Builder
+	prev := rune(0)
+	for _, r := range key {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToUpper(r))
+		case prev != '_' && b.Len() > 0:
+			r = '_'
+			b.WriteRune(r)
+		default:
+			r = '_'
+		}
+		prev = r
+	}
+	return strings.TrimRight(b.String(), "_")
+}
+
+// quoteDotenvValue quotes a dotenv value if it contains characters that would otherwise be misread.
+func quoteDotenvValue(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\r\n\"'`\\$#=") {
+		return s
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, `$`, `\$`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// parseDotenv parses `NAME=value` lines, ignoring blank lines and comments, and allowing an `export` prefix and
+// single- or double-quoted values. Escape sequences are only interpreted within double quotes.
+func parseDotenv(r io.Reader) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+
+		eq := strings.Index(text, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("line %d: expected NAME=value", line)
+		}
+		name, value := strings.TrimSpace(text[:eq]), strings.TrimSpace(text[eq+1:])
+
+		switch {
+		case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+			value = value[1 : len(value)-1]
+		case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+			unquoted, err := unquoteDotenvValue(value[1 : len(value)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			value = unquoted
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		values[name] = value
+	}
+	return values, scanner.Err()
+}
+
+// unquoteDotenvValue interprets the escape sequences within a double-quoted dotenv value.
+func unquoteDotenvValue(s string) (string, error) {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", fmt.Errorf("unterminated escape sequence in %s", strconv.Quote(s))
+		}
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigEnvVarName(t *testing.T) {
+	assert.Equal(t, "DB_HOST", configEnvVarName("dbHost"))
+	assert.Equal(t, "AWS_REGION", configEnvVarName("aws:region"))
+	assert.Equal(t, "MY_PKG_API_KEY2", configEnvVarName("my-pkg:apiKey2"))
+	assert.Equal(t, "TLS_CERT", configEnvVarName("tls.cert."))
+}
+
+func TestConfigValuesDotenvRoundtrip(t *testing.T) {
+	values := map[string]interface{}{
+		"dbHost":     "db.example.com",
+		"greeting":   "hello \"world\"\nbye $HOME",
+		"aws:region": "us-west-2",
+		"tags":       map[string]interface{}{"team": "infra"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeConfigValues(&buf, values, configFormatDotenv))
+	assert.Equal(t, "AWS_REGION=us-west-2\n"+
+		"DB_HOST=db.example.com\n"+
+		`GREETING="hello \"world\"\nbye \$HOME"`+"\n"+
+		`TAGS="{\"team\":\"infra\"}"`+"\n", buf.String())
+
+	read, err := readConfigValues(&buf, configFormatDotenv)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"AWS_REGION": "us-west-2",
+		"DB_HOST":    "db.example.com",
+		"GREETING":   "hello \"world\"\nbye $HOME",
+		"TAGS":       `{"team":"infra"}`,
+	}, read)
+}
+
+func TestParseDotenv(t *testing.T) {
+	read, err := parseDotenv(strings.NewReader(`
+# a comment
+export A=1
+B = 'single $quoted'
+C=unquoted # trailing comment
+D="tab\tseparated"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"A": "1",
+		"B": "single $quoted",
+		"C": "unquoted",
+		"D": "tab\tseparated",
+	}, read)
+
+	_, err = parseDotenv(strings.NewReader("NOVALUE\n"))
+	assert.EqualError(t, err, "line 1: expected NAME=value")
+}
+
+func TestConfigValuesStructuredRoundtrip(t *testing.T) {
+	values := map[string]interface{}{
+		"count": "3",
+		"tags":  map[string]interface{}{"team": "infra"},
+	}
+	for _, format := range []string{configFormatJSON, configFormatYAML} {
+		var buf bytes.Buffer
+		require.NoError(t, writeConfigValues(&buf, values, format))
+		read, err := readConfigValues(&buf, format)
+		require.NoError(t, err)
+		assert.Equal(t, values, read, format)
+	}
+
+	assert.Error(t, writeConfigValues(&bytes.Buffer{}, values, "toml"))
+}
+
+func TestConfigFormatForFile(t *testing.T) {
+	for path, expected := range map[string]string{
+		"config.json":    configFormatJSON,
+		"config.YAML":    configFormatYAML,
+		"dir/config.yml": configFormatYAML,
+		".env":           configFormatDotenv,
+		".env.local":     configFormatDotenv,
+		"prod.env":       configFormatDotenv,
+	} {
+		format, err := configFormatForFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, format, path)
+	}
+
+	_, err := configFormatForFile("config.toml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// configEnvPattern matches a `${env:VAR}` placeholder in a config value, or the escaped form `$${env:VAR}`.
+var configEnvPattern = regexp.MustCompile(`\$?\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// resolveConfigEnv interpolates environment variables into the given config if the current project opts into it.
+func resolveConfigEnv(cfg config.Map) (config.Map, error) {
+	proj, err := workspace.DetectProject()
+	if err != nil || proj.Options == nil || !proj.Options.InterpolateConfigEnv {
+		return cfg, nil
+	}
+	return interpolateConfigEnv(cfg, os.LookupEnv)
+}
+
+// interpolateConfigEnv returns a copy of the given config in which the `${env:VAR}` placeholders of plaintext values,
+// including the strings within object values, are replaced with the values of the environment variables they name.
+// Secure values are left as they are. It is an error for a placeholder to name an unset variable.
+func interpolateConfigEnv(cfg config.Map, lookup func(string) (string, bool)) (config.Map, error) {
+	result := make(config.Map, len(cfg))
+	for k, v := range cfg {
+		result[k] = v
+		if v.Secure() {
+			continue
+		}
+
+		raw, err := v.Value(nil)
+		if err != nil {
+			return nil, err
+		}
+		if !configEnvPattern.MatchString(raw) {
+			continue
+		}
+
+		if !v.Object() {
+			s, err := expandConfigEnv(raw, lookup)
+			if err != nil {
+				return nil, fmt.Errorf("config key '%s': %w", k, err)
+			}
+			result[k] = config.NewValue(s)
+			continue
+		}
+
+		obj, err := v.ToObject()
+		if err != nil {
+			return nil, err
+		}
+		if obj, err = expandConfigEnvObject(obj, lookup); err != nil {
+			return nil, fmt.Errorf("config key '%s': %w", k, err)
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = config.NewObjectValue(string(b))
+	}
+	return result, nil
+}
+
+// expandConfigEnvObject replaces the placeholders in every string within a decoded object value.
+func expandConfigEnvObject(v interface{}, lookup func(string) (string, bool)) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return expandConfigEnv(v, lookup)
+	case []interface{}:
+		for i, e := range v {
+			expanded, err := expandConfigEnvObject(e, lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+		return v, nil
+	case map[string]interface{}:
+		for k, e := range v {
+			expanded, err := expandConfigEnvObject(e, lookup)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = expanded
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
+
+// expandConfigEnv replaces the placeholders in a string.
+func expandConfigEnv(s string, lookup func(string) (string, bool)) (string, error) {
+	var err error
+	expanded := configEnvPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		name := configEnvPattern.FindStringSubmatch(match)[1]
+		value, ok := lookup(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return value
+	})
+	return expanded, err
+}
@@ -0,0 +1,69 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+)
+
+func TestInterpolateConfigEnv(t *testing.T) {
+	env := map[string]string{"REGION": "us-west-2", "BUILD": "42"}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	region := config.MustMakeKey("proj", "region")
+	tags := config.MustMakeKey("proj", "tags")
+	literal := config.MustMakeKey("proj", "literal")
+	secret := config.MustMakeKey("proj", "secret")
+	cfg := config.Map{
+		region:  config.NewValue("${env:REGION}"),
+		tags:    config.NewObjectValue(`{"build":"b-${env:BUILD}","list":["${env:REGION}",1]}`),
+		literal: config.NewValue("$${env:REGION}"),
+		secret:  config.NewSecureValue("${env:UNSET}"),
+	}
+
+	result, err := interpolateConfigEnv(cfg, lookup)
+	require.NoError(t, err)
+
+	v, err := result[region].Value(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "us-west-2", v)
+
+	v, err = result[tags].Value(nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"build":"b-42","list":["us-west-2",1]}`, v)
+	assert.True(t, result[tags].Object())
+
+	v, err = result[literal].Value(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "${env:REGION}", v)
+
+	assert.Equal(t, cfg[secret], result[secret])
+
+	// The original configuration is left unchanged.
+	v, err = cfg[region].Value(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "${env:REGION}", v)
+
+	_, err = interpolateConfigEnv(config.Map{region: config.NewValue("${env:MISSING}")}, lookup)
+	assert.EqualError(t, err, "config key 'proj:region': environment variable MISSING is not set")
+}
@@ -42,3 +42,31 @@ func TestSecretDetection(t *testing.T) {
 	// The key name does not match the pattern, so even though this "looks like" a secret, we say it is not.
 	assert.False(t, looksLikeSecret(config.MustMakeKey("test", "okay"), "1415fc1f4eaeb5e096ee58c1480016638fff29bf"))
 }
+
+func TestLookupConfigValue(t *testing.T) {
+	cfg := config.Map{
+		config.MustMakeKey("test", "name"): config.NewValue("value"),
+		config.MustMakeKey("test", "db"):   config.NewObjectValue(`{"port":5432,"hosts":["a","b"]}`),
+	}
+
+	value, raw, ok, err := lookupConfigValue(nil, cfg, config.MustMakeKey("test", "name"), false, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "value", raw)
+	assert.Nil(t, value.ObjectValue)
+
+	value, raw, ok, err = lookupConfigValue(nil, cfg, config.MustMakeKey("test", "db"), false, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `{"port":5432,"hosts":["a","b"]}`, raw)
+	assert.Equal(t, map[string]interface{}{"port": float64(5432), "hosts": []interface{}{"a", "b"}}, value.ObjectValue)
+
+	_, raw, ok, err = lookupConfigValue(nil, cfg, config.MustMakeKey("test", "db.hosts[1]"), true, nil)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", raw)
+
+	_, _, ok, err = lookupConfigValue(nil, cfg, config.MustMakeKey("test", "missing"), false, nil)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
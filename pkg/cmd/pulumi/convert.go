@@ -0,0 +1,270 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/convert"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/hcl2/syntax"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/pcl"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// A convertFrontend translates the program at a path into PCL.
+type convertFrontend func(path string, loader schema.Loader) ([]byte, hcl.Diagnostics, error)
+
+// convertFrontends lists the source formats accepted by `pulumi convert --from`, keyed by name.
+var convertFrontends = map[string]convertFrontend{
+	"arm":            convert.ARMToPCL,
+	"cloudformation": convert.CloudFormationToPCL,
+	"kubernetes":     convert.KubernetesToPCL,
+	"terraform": func(path string, loader schema.Loader) ([]byte, hcl.Diagnostics, error) {
+		return convert.TerraformToPCL(path, convert.NewTerraformTypes(loader))
+	},
+}
+
+// convertRuntimes maps the languages accepted by `pulumi convert --language` to the runtimes of their projects.
+var convertRuntimes = map[string]string{
+	"csharp":     "dotnet",
+	"go":         "go",
+	"python":     "python",
+	"typescript": "nodejs",
+}
+
+func newConvertCmd() *cobra.Command {
+	var from string
+	var language string
+	var outDir string
+
+	cmd := &cobra.Command{
+		Use:   "convert [source]",
+		Short: "Convert a program written for another tool into a Pulumi program",
+		Long: "Convert a program written for another tool into a Pulumi program.\n" +
+			"\n" +
+			"The program at the source path, which defaults to the current directory, is converted into\n" +
+			"a Pulumi program in the language given by `--language` (csharp, go, python, typescript, or\n" +
+			"pcl), which is written to the directory given by `--out`. Constructs that cannot be\n" +
+			"converted are reported as warnings.\n" +
+			"\n" +
+			"The supported source formats are:\n" +
+			"\n" +
+			"    arm: an Azure Resource Manager template, a Bicep file, or a directory that contains a\n" +
+			"        main.bicep, azuredeploy.json, or template.json file. Bicep files are compiled with the\n" +
+			"        Bicep CLI. Parameters become configuration, and resources are mapped to the resources of\n" +
+			"        the azure-native provider in the resource group given by the resourceGroupName\n" +
+			"        configuration value.\n" +
+			"    cloudformation: a CloudFormation or AWS SAM template in YAML or JSON, or a directory that\n" +
+			"        contains a template.yaml, template.yml, or template.json file. Parameters become\n" +
+			"        configuration, and resources are mapped to the resources of the aws-native provider.\n" +
+			"        The Ref, Fn::GetAtt, Fn::Sub, Fn::Join, Fn::Split, Fn::Select, and Fn::FindInMap\n" +
+			"        intrinsics are converted; AWS SAM resources must be expanded before conversion.\n" +
+			"    kubernetes: a Kubernetes manifest, which may contain multiple documents, or a directory of\n" +
+			"        manifests. Directories that contain a kustomization are built with kustomize first. Each\n" +
+			"        object is mapped to the corresponding resource of the kubernetes provider, preserving its\n" +
+			"        namespace and labels.\n" +
+			"    terraform: a directory of Terraform HCL (.tf) files. Variables become configuration,\n" +
+			"        locals become local variables, and resources and data sources are mapped to the\n" +
+			"        resources and functions of the bridged Pulumi providers. Modules with local sources\n" +
+			"        are converted along with the program; other modules are not converted.\n",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			source := "."
+			if len(args) == 1 {
+				source = args[0]
+			}
+
+			frontend, ok := convertFrontends[from]
+			if !ok {
+				return fmt.Errorf("unsupported source format '%v'; supported formats are %v", from,
+					strings.Join(convertFrontendNames(), ", "))
+			}
+			if language = strings.ToLower(language); importLanguageAliases[language] != "" {
+				language = importLanguageAliases[language]
+			}
+			if _, ok := convertRuntimes[language]; !ok && language != "pcl" {
+				return fmt.Errorf("unsupported language '%v'; supported languages are csharp, go, python, "+
+					"typescript, and pcl", language)
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			sink := cmdutil.Diag()
+			ctx, err := plugin.NewContext(sink, sink, nil, nil, cwd, nil, true, nil)
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(ctx)
+			loader := schema.NewPluginLoader(ctx.Host)
+
+			program, diags, err := frontend(source, loader)
+			if err != nil {
+				return fmt.Errorf("could not convert %v: %w", source, err)
+			}
+			printConvertDiagnostics(diags)
+			if diags.HasErrors() {
+				return fmt.Errorf("could not convert %v", source)
+			}
+
+			if err := os.MkdirAll(outDir, 0700); err != nil {
+				return fmt.Errorf("could not create output directory: %w", err)
+			}
+			if language == "pcl" {
+				return writeConvertedFiles(outDir, map[string][]byte{"main.pp": program})
+			}
+
+			files, err := generateConvertedProgram(program, language, loader)
+			if err != nil {
+				// Keep the intermediate program so that it can be corrected by hand.
+				if werr := writeConvertedFiles(outDir, map[string][]byte{"main.pp": program}); werr == nil {
+					err = fmt.Errorf("%w; the converted PCL program has been written to %v", err,
+						filepath.Join(outDir, "main.pp"))
+				}
+				return err
+			}
+			if err := writeConvertedFiles(outDir, files); err != nil {
+				return err
+			}
+			return writeConvertedProject(outDir, convertRuntimes[language])
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&from, "from", "",
+		"The format of the program to convert: "+strings.Join(convertFrontendNames(), ", "))
+	cmd.PersistentFlags().StringVar(
+		&language, "language", "typescript",
+		"The language of the generated program: csharp, go, python, typescript, or pcl")
+	cmd.PersistentFlags().StringVarP(
+		&outDir, "out", "o", ".",
+		"The directory to which the generated program is written")
+
+	return cmd
+}
+
+func convertFrontendNames() []string {
+	names := make([]string, 0, len(convertFrontends))
+	for name := range convertFrontends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printConvertDiagnostics reports the constructs that could not be converted, followed by a summary.
+func printConvertDiagnostics(diags hcl.Diagnostics) {
+	if len(diags) == 0 {
+		return
+	}
+	writer := hcl.NewDiagnosticTextWriter(os.Stderr, nil, 0, cmdutil.GetGlobalColorization() != colors.Never)
+	contract.IgnoreError(writer.WriteDiagnostics(diags))
+
+	warnings := 0
+	for _, d := range diags {
+		if d.Severity == hcl.DiagWarning {
+			warnings++
+		}
+	}
+	if warnings != 0 {
+		fmt.Fprintf(os.Stderr, "%d construct(s) could not be converted; see the warnings above\n", warnings)
+	}
+}
+
+// generateConvertedProgram binds a PCL program and generates its source in the given language.
+func generateConvertedProgram(text []byte, language string, loader schema.Loader) (map[string][]byte, error) {
+	parser := syntax.NewParser()
+	if err := parser.ParseFile(bytes.NewReader(text), "main.pp"); err != nil {
+		return nil, err
+	}
+	if parser.Diagnostics.HasErrors() {
+		contract.IgnoreError(parser.NewDiagnosticWriter(os.Stderr, 0, true).WriteDiagnostics(parser.Diagnostics))
+		return nil, fmt.Errorf("the converted program is not valid")
+	}
+
+	program, diags, err := pcl.BindProgram(parser.Files, pcl.Loader(loader))
+	if err != nil {
+		return nil, err
+	}
+	if diags.HasErrors() {
+		contract.IgnoreError(program.NewDiagnosticWriter(os.Stderr, 0, true).WriteDiagnostics(diags))
+		return nil, fmt.Errorf("the converted program could not be bound")
+	}
+
+	files, diags, err := importLanguages[language].generator(program)
+	if err != nil {
+		return nil, err
+	}
+	if diags.HasErrors() {
+		contract.IgnoreError(program.NewDiagnosticWriter(os.Stderr, 0, true).WriteDiagnostics(diags))
+		return nil, fmt.Errorf("could not generate %v", language)
+	}
+	return files, nil
+}
+
+func writeConvertedFiles(dir string, files map[string][]byte) error {
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, contents, 0600); err != nil {
+			return fmt.Errorf("could not write %v: %w", path, err)
+		}
+		fmt.Printf("Wrote %v\n", path)
+	}
+	return nil
+}
+
+// writeConvertedProject writes a project file for the converted program, unless the output directory already
+// contains one.
+func writeConvertedProject(dir, runtime string) error {
+	path := filepath.Join(dir, "Pulumi.yaml")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	name := tokens.PackageName(filepath.Base(abs))
+	if !tokens.IsName(string(name)) {
+		name = "converted"
+	}
+
+	proj := &workspace.Project{
+		Name:    name,
+		Runtime: workspace.NewProjectRuntimeInfo(runtime, nil),
+	}
+	if err := proj.Save(path); err != nil {
+		return fmt.Errorf("could not write %v: %w", path, err)
+	}
+	fmt.Printf("Wrote %v\n", path)
+	return nil
+}
@@ -18,12 +18,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
@@ -34,6 +41,8 @@ func newDestroyCmd() *cobra.Command {
 	var stack string
 
 	var message string
+	var configArray []string
+	var configPath bool
 	var execKind string
 	var execAgent string
 
@@ -41,6 +50,7 @@ func newDestroyCmd() *cobra.Command {
 	var jsonDisplay bool
 	var diffDisplay bool
 	var eventLogPath string
+	var reports []string
 	var parallel int
 	var refresh string
 	var showConfig bool
@@ -48,10 +58,35 @@ func newDestroyCmd() *cobra.Command {
 	var showSames bool
 	var skipPreview bool
 	var suppressOutputs bool
+	var ciAnnotations bool
+	var ciFormat string
+	var quiet bool
+	var verbosity int
+	var noTruncate bool
+	var displayWidth int
+	var notifyURL string
+	var eventSinks []string
+	var heartbeatInterval time.Duration
+	var waitForLock time.Duration
+	var preflight bool
+	var providerVersions []string
+	var profileResources bool
+	var slowStepThreshold time.Duration
 	var suppressPermalink string
 	var yes bool
+	var previewOnly bool
+	var savePlan string
+	var expiredOnly bool
 	var targets *[]string
+	var targetFile string
 	var targetDependents bool
+	var excludeProtected bool
+	var cascade bool
+	var allStacks bool
+	var allStacksParallel int
+	var checkReferences string
+	var policyPackPaths []string
+	var policyPackConfigPaths []string
 
 	var cmd = &cobra.Command{
 		Use:        "destroy",
@@ -68,14 +103,43 @@ func newDestroyCmd() *cobra.Command {
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			yes = yes || skipConfirmations()
 			interactive := cmdutil.Interactive()
-			if !interactive && !yes {
+			switch {
+			case savePlan != "" && !previewOnly:
+				return result.FromError(errors.New("--save-plan can only be used with --preview-only"))
+			case previewOnly && (skipPreview || yes):
+				return result.FromError(errors.New("--preview-only cannot be used with --skip-preview or --yes"))
+			case preflight && skipPreview:
+				return result.FromError(errors.New("--preflight checks the operations of the preview, so it cannot be " +
+					"used with --skip-preview"))
+			case previewOnly && (allStacks || cascade):
+				return result.FromError(errors.New("--preview-only cannot be used with --all-stacks or --cascade"))
+			case !interactive && !yes && !previewOnly:
 				return result.FromError(errors.New("--yes must be passed in to proceed when running in non-interactive mode"))
 			}
 
-			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
+			// A preview changes nothing, so it needs no approval.
+			opts, err := updateFlagsToOptions(interactive, skipPreview, yes || previewOnly)
 			if err != nil {
 				return result.FromError(err)
 			}
+			opts.AutoApprove = yes
+			opts.PreviewOnly = previewOnly
+			if err = validatePolicyPackConfig(policyPackPaths, policyPackConfigPaths); err != nil {
+				return result.FromError(err)
+			}
+
+			if allStacks {
+				switch {
+				case stack != "":
+					return result.FromError(errors.New("--all-stacks cannot be used with --stack"))
+				case cascade:
+					return result.FromError(errors.New("--all-stacks cannot be used with --cascade"))
+				case len(*targets) > 0 || targetFile != "":
+					return result.FromError(errors.New("--all-stacks cannot be used with --target or --target-file"))
+				case allStacksParallel > 1 && !yes:
+					return result.FromError(errors.New("--yes must be passed in to destroy stacks in parallel"))
+				}
+			}
 
 			var displayType = display.DisplayProgress
 			if diffDisplay {
@@ -93,7 +157,24 @@ func newDestroyCmd() *cobra.Command {
 				EventLogPath:         eventLogPath,
 				Debug:                debug,
 				JSONDisplay:          jsonDisplay,
+				CIAnnotations:        ciAnnotations,
+				EventSinks:           eventSinks,
+				HeartbeatInterval:    heartbeatInterval,
+				NoTruncate:           noTruncate,
+				DisplayWidth:         displayWidth,
+				ProfileResources:     profileResources,
+				SlowStepThreshold:    slowStepThreshold,
+			}
+			if err := applyReportFlags(reports, &opts.Display); err != nil {
+				return result.FromError(err)
 			}
+			if err := applyCIFormatFlag(ciFormat, &opts.Display); err != nil {
+				return result.FromError(err)
+			}
+			if err := applyVerbosityFlags(quiet, verbosity, &opts.Display); err != nil {
+				return result.FromError(err)
+			}
+			opts.WaitForLock = waitForLock
 
 			// we only suppress permalinks if the user passes true. the default is an empty string
 			// which we pass as 'false'
@@ -116,69 +197,134 @@ func newDestroyCmd() *cobra.Command {
 				opts.Display.SuppressPermalink = true
 			}
 
-			s, err := requireStack(stack, false, opts.Display, false /*setCurrent*/)
-			if err != nil {
-				return result.FromError(err)
-			}
-			proj, root, err := readProject()
-			if err != nil {
-				return result.FromError(err)
-			}
+			var confirmLock sync.Mutex
+			destroyStack := func(stackName string) result.Result {
+				opts := opts
+				s, err := requireStack(stackName, false, opts.Display, false /*setCurrent*/)
+				if err != nil {
+					return result.FromError(err)
+				}
+				if expiredOnly {
+					expired, err := checkStackExpired(s, time.Now())
+					if err != nil || !expired {
+						return result.WrapIfNonNil(err)
+					}
+				}
+				if err = checkStackReferrers(s, checkReferences); err != nil {
+					return result.FromError(err)
+				}
+				// A preview destroys nothing. Confirming the destroy of every stack does not confirm the destroy of a
+				// stack that requires it, and stacks destroyed in parallel take turns to prompt.
+				if !previewOnly {
+					confirmLock.Lock()
+					err = confirmDestroy(s, interactive, opts.Display)
+					confirmLock.Unlock()
+					if err != nil {
+						return result.FromError(err)
+					}
+				}
+				proj, root, err := readProject()
+				if err != nil {
+					return result.FromError(err)
+				}
 
-			m, err := getUpdateMetadata(message, root, execKind, execAgent)
-			if err != nil {
-				return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
-			}
+				m, err := getUpdateMetadata(message, proj, root, execKind, execAgent)
+				if err != nil {
+					return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
+				}
 
-			sm, err := getStackSecretsManager(s)
-			if err != nil {
-				return result.FromError(fmt.Errorf("getting secrets manager: %w", err))
-			}
+				sm, err := getStackSecretsManager(s)
+				if err != nil {
+					return result.FromError(fmt.Errorf("getting secrets manager: %w", err))
+				}
 
-			cfg, err := getStackConfiguration(s, sm)
-			if err != nil {
-				return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
-			}
+				cfg, err := getStackConfiguration(s, sm)
+				if err != nil {
+					return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
+				}
+				if err = applyEphemeralConfig(&cfg, m, configArray, configPath); err != nil {
+					return result.FromError(err)
+				}
 
-			targetUrns := []resource.URN{}
-			for _, t := range *targets {
-				targetUrns = append(targetUrns, resource.URN(t))
-			}
+				targetUrns, err := getTargetURNs(s, *targets, targetFile, false)
+				if err != nil {
+					return result.FromError(err)
+				}
 
-			refreshOption, err := getRefreshOption(proj, refresh)
-			if err != nil {
-				return result.FromError(err)
-			}
-			opts.Engine = engine.UpdateOptions{
-				Parallel:                  parallel,
-				Debug:                     debug,
-				Refresh:                   refreshOption,
-				DestroyTargets:            targetUrns,
-				TargetDependents:          targetDependents,
-				UseLegacyDiff:             useLegacyDiff(),
-				DisableProviderPreview:    disableProviderPreview(),
-				DisableResourceReferences: disableResourceReferences(),
-				DisableOutputValues:       disableOutputValues(),
+				refreshOption, err := getRefreshOption(proj, refresh)
+				if err != nil {
+					return result.FromError(err)
+				}
+				providerVersionPins, err := parseProviderVersions(providerVersions)
+				if err != nil {
+					return result.FromError(err)
+				}
+				stepHooks, err := getStepHooks(proj, s)
+				if err != nil {
+					return result.FromError(err)
+				}
+				opts.Engine = engine.UpdateOptions{
+					LocalPolicyPacks:          makeLocalPolicyPacks(proj, root, policyPackPaths, policyPackConfigPaths),
+					Parallel:                  parallel,
+					Debug:                     debug,
+					Refresh:                   refreshOption,
+					UseLegacyDiff:             useLegacyDiff(),
+					DisableProviderPreview:    disableProviderPreview(),
+					DisableResourceReferences: disableResourceReferences(),
+					DisableOutputValues:       disableOutputValues(),
+					Preflight:                 preflight,
+					ProviderVersions:          providerVersionPins,
+					StepHooks:                 stepHooks,
+				}
+				if err = applyApprovalGates(s, interactive, &opts); err != nil {
+					return result.FromError(err)
+				}
+				opts.Destroy = backend.DestroyOptions{
+					Targets:          targetUrns,
+					TargetDependents: targetDependents,
+					ExcludeProtected: excludeProtected,
+				}
+
+				if savePlan != "" {
+					if err = saveDestroyPlan(s, savePlan, opts.Destroy); err != nil {
+						return result.FromError(err)
+					}
+				}
+
+				notifier := newOperationNotifier(notifyURL, s, proj, apitype.DestroyUpdate, cfg)
+				notifier.started()
+				changes, res := s.Destroy(commandContext(), backend.UpdateOperation{
+					Proj:               proj,
+					Root:               root,
+					M:                  m,
+					Opts:               opts,
+					StackConfiguration: cfg,
+					SecretsManager:     sm,
+					Scopes:             cancellationScopes,
+				})
+				notifier.finished(changes, res)
+
+				if res == nil && len(targetUrns) == 0 && !excludeProtected && !jsonDisplay && !previewOnly {
+					fmt.Printf("The resources in the stack have been deleted, but the history and configuration "+
+						"associated with the stack are still maintained. \nIf you want to remove the stack "+
+						"completely, run 'pulumi stack rm %s'.\n", s.Ref())
+				} else if res != nil && res.Error() == context.Canceled {
+					return result.FromError(errors.New("destroy cancelled"))
+				}
+				return PrintEngineResult(res)
 			}
 
-			_, res := s.Destroy(commandContext(), backend.UpdateOperation{
-				Proj:               proj,
-				Root:               root,
-				M:                  m,
-				Opts:               opts,
-				StackConfiguration: cfg,
-				SecretsManager:     sm,
-				Scopes:             cancellationScopes,
-			})
-
-			if res == nil && len(*targets) == 0 && !jsonDisplay {
-				fmt.Printf("The resources in the stack have been deleted, but the history and configuration "+
-					"associated with the stack are still maintained. \nIf you want to remove the stack "+
-					"completely, run 'pulumi stack rm %s'.\n", s.Ref())
-			} else if res != nil && res.Error() == context.Canceled {
-				return result.FromError(errors.New("destroy cancelled"))
+			if allStacks {
+				if allStacksParallel > 1 {
+					// Progress from concurrent destroys can only be interleaved line by line.
+					opts.Display.IsInteractive = false
+				}
+				return destroyAllStacks(opts.Display, interactive, allStacksParallel, destroyStack)
+			}
+			if cascade {
+				return runCascade(stack, opts.Display, cascadeDestroy, destroyStack)
 			}
-			return PrintEngineResult(res)
+			return destroyStack(stack)
 		}),
 	}
 
@@ -191,19 +337,58 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVar(
 		&stackConfigFile, "config-file", "",
 		"Use the configuration values in the specified file rather than detecting the file name")
+	cmd.PersistentFlags().StringArrayVarP(
+		&configArray, "config", "c", []string{},
+		"Config to use during the destroy. These values are not saved to the stack's configuration")
+	cmd.PersistentFlags().BoolVar(
+		&configPath, "config-path", false,
+		"Config keys contain a path to a property in a map or list to set")
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
-		"Optional message to associate with the destroy operation")
+		"Optional message to associate with the destroy operation. May contain placeholders such as "+
+			"{{.GitCommit}}, {{.GitBranch}} and {{.User}} that are expanded from the environment")
 
 	targets = cmd.PersistentFlags().StringArrayP(
 		"target", "t", []string{},
 		"Specify a single resource URN to destroy. All resources necessary to destroy this target will also be destroyed."+
 			" Multiple resources can be specified using: --target urn1 --target urn2")
+	cmd.PersistentFlags().StringVar(
+		&targetFile, "target-file", "",
+		"Read resource URNs to destroy from a file, one per line. Lines starting with # are ignored, and targets "+
+			"containing * or ? are globs that match the URNs of the stack's existing resources")
 	cmd.PersistentFlags().BoolVar(
 		&targetDependents, "target-dependents", false,
 		"Allows destroying of dependent targets discovered but not specified in --target list")
+	cmd.PersistentFlags().BoolVar(
+		&excludeProtected, "exclude-protected", false,
+		"Do not destroy protected resources or the resources they depend on")
+	cmd.PersistentFlags().BoolVar(
+		&expiredOnly, "expired-only", false,
+		"Only destroy the stack if it has expired, according to the time to live set with `pulumi stack set-ttl`, "+
+			"and otherwise do nothing")
+	cmd.PersistentFlags().BoolVar(
+		&cascade, "cascade", false,
+		"Also destroy every stack of the project that depends on this one, dependents first")
+	cmd.PersistentFlags().BoolVar(
+		&allStacks, "all-stacks", false,
+		"Destroy every stack of the project; requires typing the project name, or setting "+
+			destroyAllConfirmationEnvVar+" to it")
+	cmd.PersistentFlags().IntVar(
+		&allStacksParallel, "all-stacks-parallel", 1,
+		"With --all-stacks, destroy up to N stacks at once (1 to destroy them one at a time)")
+	cmd.PersistentFlags().StringVar(
+		&checkReferences, "check-references", "",
+		"Check for other stacks whose StackReferences read this stack's outputs, and either warn about them or "+
+			"refuse to destroy the stack (warn or refuse, the default when the flag is given without a value)")
+	cmd.Flag("check-references").NoOptDefVal = checkReferencesRefuse
 
 	// Flags for engine.UpdateOptions.
+	cmd.PersistentFlags().StringSliceVar(
+		&policyPackPaths, "policy-pack", []string{},
+		"Run one or more policy packs as part of this destroy")
+	cmd.PersistentFlags().StringSliceVar(
+		&policyPackConfigPaths, "policy-pack-config", []string{},
+		`Path to JSON file containing the config for the policy pack of the corresponding "--policy-pack" flag`)
 	cmd.PersistentFlags().BoolVar(
 		&diffDisplay, "diff", false,
 		"Display operation as a rich diff showing the overall change")
@@ -232,6 +417,55 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
+	cmd.PersistentFlags().BoolVar(
+		&ciAnnotations, "ci-annotations", defaultCIAnnotations(),
+		"Group output by resource and raise annotations for diagnostics when running under a supported CI system; "+
+			"defaults to the value of "+ciAnnotationsEnvVar)
+	cmd.PersistentFlags().StringVar(
+		&ciFormat, "ci-format", "",
+		"Write the log commands of the given CI system instead of the detected one: azdo, github or teamcity")
+	cmd.PersistentFlags().BoolVarP(
+		&quiet, "quiet", "q", false,
+		"Only show diagnostics and the summary, not the progress of each resource")
+	cmd.PersistentFlags().IntVar(
+		&verbosity, "verbosity", 0,
+		"Show more detail about each resource, from 0 to 3: 1 adds replacement steps and reads, 2 adds unchanged "+
+			"resources and 3 adds configuration")
+	cmd.PersistentFlags().BoolVar(
+		&noTruncate, "no-truncate", false,
+		"Wrap lines of the progress display that are too wide for the terminal instead of truncating them")
+	cmd.PersistentFlags().IntVar(
+		&displayWidth, "display-width", 0,
+		"Fit the lines of the progress display to this many columns instead of the width of the terminal")
+	cmd.PersistentFlags().StringVar(
+		&notifyURL, "notify-url", "",
+		"POST a JSON notification to this URL when the destroy starts, succeeds or fails; defaults to the "+
+			"pulumi:notifyUrl stack configuration value")
+	cmd.PersistentFlags().StringArrayVar(
+		&eventSinks, "event-sink", nil,
+		"Send the engine events of the operation to the named event sink plugin (pulumi-eventsink-<name>); "+
+			"may be specified more than once")
+	cmd.PersistentFlags().DurationVar(
+		&heartbeatInterval, "heartbeat-interval", defaultHeartbeatInterval,
+		"When the display is not interactive, list the resources still in progress this often (0 to disable)")
+	cmd.PersistentFlags().DurationVar(
+		&waitForLock, "wait-for-lock", 0,
+		"If another update of the stack is in progress, wait up to this long for it to finish (e.g. '10m') "+
+			"instead of failing immediately")
+	cmd.PersistentFlags().BoolVar(
+		&preflight, "preflight", false,
+		"During the preview, ask each provider to check that its credentials permit the destroy's operations. "+
+			"Experimental: providers must implement the pulumi:providers:checkPermissions function")
+	cmd.PersistentFlags().StringArrayVar(
+		&providerVersions, "provider-version", nil,
+		"Load this version of a package's provider plugin, as <package>=<version> (e.g. 'aws=4.1.0'), whatever "+
+			"version the program or the stack's state asks for; may be specified more than once")
+	cmd.PersistentFlags().BoolVar(
+		&profileResources, "profile-resources", false,
+		"Show how long the slowest resource operations of the destroy took once it completes")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-operation-threshold", defaultSlowStepThreshold,
+		"Flag resource operations that take longer than this in the interactive display (0 to disable)")
 	cmd.PersistentFlags().StringVar(
 		&suppressPermalink, "suppress-permalink", "",
 		"Suppress display of the state permalink")
@@ -240,12 +474,22 @@ func newDestroyCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the destroy after previewing it")
+	cmd.PersistentFlags().BoolVar(
+		&previewOnly, "preview-only", false,
+		"Only preview the destroy, without prompting or deleting anything")
+	cmd.PersistentFlags().StringVar(
+		&savePlan, "save-plan", "",
+		"With --preview-only, save the ordered list of the resources that the destroy would delete, with protected "+
+			"resources flagged, to a JSON file at this path")
 
-	if hasDebugCommands() {
-		cmd.PersistentFlags().StringVar(
-			&eventLogPath, "event-log", "",
-			"Log events to a file at this path")
-	}
+	cmd.PersistentFlags().StringArrayVar(
+		&reports, "report", nil,
+		"Write a report of each resource operation to a file, as <format>=<path>. The only supported format "+
+			"is junit, which writes JUnit XML")
+
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log each engine event to a file at this path as a line of JSON, alongside the usual display")
 
 	// internal flags
 	cmd.PersistentFlags().StringVar(&execKind, "exec-kind", "", "")
@@ -257,3 +501,136 @@ func newDestroyCmd() *cobra.Command {
 
 	return cmd
 }
+
+// destroyConfirmationEnvVar names the environment variable that confirms a non-interactive destroy of a stack whose
+// settings require destroys to be confirmed. Its value must be a one-time token issued for the stack by
+// `pulumi stack destroy-token`, so that automation cannot destroy such a stack unless someone has approved it.
+const destroyConfirmationEnvVar = "PULUMI_DESTROY_CONFIRMATION"
+
+// confirmDestroy enforces a stack's requireDestroyConfirmation setting. Such stacks can only be destroyed if the user
+// types the stack's name when prompted, or if PULUMI_DESTROY_CONFIRMATION is set to a token issued for the stack.
+// Neither --yes nor PULUMI_SKIP_CONFIRMATIONS is sufficient.
+func confirmDestroy(s backend.Stack, interactive bool, opts display.Options) error {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return fmt.Errorf("loading stack settings: %w", err)
+	}
+	if !ps.RequireDestroyConfirmation {
+		return nil
+	}
+	return checkDestroyConfirmation(s.Ref().String(), string(s.Ref().Name()), interactive, func(prompt, name string) bool {
+		return confirmPrompt(prompt, name, opts)
+	})
+}
+
+// The modes of `pulumi destroy --check-references`.
+const (
+	checkReferencesWarn   = "warn"
+	checkReferencesRefuse = "refuse"
+)
+
+// checkStackReferrers looks for other stacks in the stack's backend whose StackReferences read its outputs and, if
+// there are any, warns about them or refuses to destroy the stack according to the mode. An empty mode skips the
+// check, which has to read the latest snapshot of every stack.
+func checkStackReferrers(s backend.Stack, mode string) error {
+	switch mode {
+	case "":
+		return nil
+	case checkReferencesWarn, checkReferencesRefuse:
+	default:
+		return fmt.Errorf("unknown --check-references mode %q; expected %s or %s",
+			mode, checkReferencesWarn, checkReferencesRefuse)
+	}
+
+	ctx := commandContext()
+	summaries, err := listAllStacks(ctx, s.Backend(), backend.ListStacksFilter{})
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, summary := range summaries {
+		names = append(names, summary.Name().String())
+	}
+	sort.Strings(names)
+
+	g, err := makeStackReferenceGraph(names, backendStackSnapshotLoader(ctx, s.Backend()))
+	if err != nil {
+		return err
+	}
+	referrers := stackReferrers(g, s.Ref().String())
+	if len(referrers) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("stack '%s' is referenced by %d other stacks: %s",
+		s.Ref(), len(referrers), strings.Join(referrers, ", "))
+	if mode == checkReferencesRefuse {
+		return errors.New(msg + "; destroy them first, or pass --check-references=warn to proceed anyway")
+	}
+	cmdutil.Diag().Warningf(diag.Message("", "%s"), msg)
+	return nil
+}
+
+// stackReferrers returns the names of the stacks in the graph that reference the named stack, other than itself.
+func stackReferrers(g *stackReferenceGraph, name string) []string {
+	vertex, ok := g.vertices[name]
+	if !ok {
+		return nil
+	}
+	var referrers []string
+	for _, out := range vertex.outs {
+		if referrer := out.(*stackReferenceEdge).to.name; referrer != name {
+			referrers = append(referrers, referrer)
+		}
+	}
+	sort.Strings(referrers)
+	return referrers
+}
+
+// checkDestroyConfirmation confirms the destruction of a stack, either with the token in PULUMI_DESTROY_CONFIRMATION
+// or, when there is none and the session is interactive, by calling confirm with the stack's name. The token must
+// have been issued for the stack, identified by ref, and is consumed by a successful confirmation.
+func checkDestroyConfirmation(ref, name string, interactive bool, confirm func(prompt, name string) bool) error {
+	if token, ok := os.LookupEnv(destroyConfirmationEnvVar); ok {
+		return consumeDestroyToken(ref, token, time.Now())
+	}
+
+	if !interactive {
+		return fmt.Errorf("stack '%s' requires destroys to be confirmed; set %s to a token issued by "+
+			"`pulumi stack destroy-token` to proceed", name, destroyConfirmationEnvVar)
+	}
+
+	prompt := fmt.Sprintf("Stack '%s' requires confirmation before its resources are destroyed.", name)
+	if !confirm(prompt, name) {
+		return errors.New("confirmation declined")
+	}
+	return nil
+}
+
+// saveDestroyPlan computes the plan for destroying the stack's resources with the given options and writes it to the
+// given path. A protected resource would fail the destroy, so the plan flags such resources and a warning counts them.
+func saveDestroyPlan(s backend.Stack, path string, opts backend.DestroyOptions) error {
+	snap, err := s.Snapshot(commandContext())
+	if err != nil {
+		return fmt.Errorf("getting snapshot: %w", err)
+	}
+	var resources []*resource.State
+	if snap != nil {
+		resources = snap.Resources
+	}
+
+	plan, err := computeDestroyPlan(s.Ref().String(), resources, opts.Targets, opts.TargetDependents,
+		opts.ExcludeProtected)
+	if err != nil {
+		return err
+	}
+	if err = writeDestroyPlan(path, plan); err != nil {
+		return err
+	}
+	fmt.Printf("Saved a plan of %d delete steps to %s\n", len(plan.Steps), path)
+	if plan.Protected > 0 {
+		cmdutil.Diag().Warningf(diag.Message("", "%d of the resources in the plan are protected; the destroy will "+
+			"fail unless they are unprotected or --exclude-protected is passed"), plan.Protected)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// destroyAllStacks destroys every stack of the current project, running at most parallel destroys at once. Unlike a
+// cascade, a failure does not stop the remaining stacks from being destroyed.
+func destroyAllStacks(opts display.Options, interactive bool, parallel int,
+	destroy func(stackName string) result.Result) result.Result {
+
+	proj, _, err := readProject()
+	if err != nil {
+		return result.FromError(err)
+	}
+	b, err := currentBackend(opts)
+	if err != nil {
+		return result.FromError(err)
+	}
+	summaries, err := listProjectStacks(commandContext(), b, proj.Name)
+	if err != nil {
+		return result.FromError(err)
+	}
+	if len(summaries) == 0 {
+		fmt.Printf("Project '%s' has no stacks to destroy.\n", proj.Name)
+		return nil
+	}
+
+	names := make([]string, len(summaries))
+	for i, summary := range summaries {
+		names[i] = summary.Name().String()
+	}
+	sort.Strings(names)
+
+	fmt.Printf(opts.Color.Colorize(colors.SpecHeadline+"Destroying the %d stacks of project '%s':"+colors.Reset+"\n"),
+		len(names), proj.Name)
+	for _, name := range names {
+		fmt.Printf("    %s\n", name)
+	}
+	fmt.Println()
+
+	err = checkDestroyAllConfirmation(string(proj.Name), interactive, func(prompt, name string) bool {
+		return confirmPrompt(prompt, name, opts)
+	})
+	if err != nil {
+		return result.FromError(err)
+	}
+
+	if parallel < 1 {
+		parallel = 1
+	}
+	outcomes := make([]string, len(names))
+	errs := make([]error, len(names))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			outcomes[i] = "destroyed"
+			if res := destroy(name); res != nil {
+				// Errors that the engine has already displayed are not reported again.
+				outcomes[i], errs[i] = "failed", res.Error()
+			}
+		}(i, name)
+	}
+	wg.Wait()
+
+	printStackOutcomes(opts, "Destroy summary", names, outcomes)
+
+	failed := 0
+	for i, outcome := range outcomes {
+		if outcome != "failed" {
+			continue
+		}
+		failed++
+		if errs[i] != nil {
+			cmdutil.Diag().Errorf(diag.Message("", "stack '%s': %v"), names[i], errs[i])
+		}
+	}
+	if failed != 0 {
+		return result.FromError(fmt.Errorf("%d of %d stacks failed to destroy", failed, len(names)))
+	}
+	return nil
+}
+
+// destroyAllConfirmationEnvVar names the environment variable that confirms a non-interactive destroy of every stack
+// of a project. Its value must be the name of the project. Stacks whose settings require destroys to be confirmed
+// must still be confirmed with a token in destroyConfirmationEnvVar.
+const destroyAllConfirmationEnvVar = "PULUMI_DESTROY_ALL_CONFIRMATION"
+
+// checkDestroyAllConfirmation confirms the destruction of every stack of the named project, either with the value of
+// PULUMI_DESTROY_ALL_CONFIRMATION or, when there is none and the session is interactive, by calling confirm. Unlike
+// the confirmation of a single stack this is always required, even when --yes is passed.
+func checkDestroyAllConfirmation(project string, interactive bool, confirm func(prompt, name string) bool) error {
+	if confirmation, ok := os.LookupEnv(destroyAllConfirmationEnvVar); ok {
+		if confirmation != project {
+			return fmt.Errorf("%s does not match the name of project '%s'", destroyAllConfirmationEnvVar, project)
+		}
+		return nil
+	}
+
+	if !interactive {
+		return fmt.Errorf("destroying every stack must be confirmed; set %s to the project's name to proceed",
+			destroyAllConfirmationEnvVar)
+	}
+
+	prompt := fmt.Sprintf("This will destroy the resources of every stack of project '%s'.", project)
+	if !confirm(prompt, project) {
+		return errors.New("confirmation declined")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckDestroyAllConfirmation(t *testing.T) {
+	prompted := false
+	confirm := func(answer bool) func(prompt, name string) bool {
+		return func(prompt, name string) bool {
+			prompted = true
+			assert.Equal(t, "myproj", name)
+			return answer
+		}
+	}
+
+	// Without the environment variable, an interactive session prompts for the project name.
+	t.Setenv(destroyAllConfirmationEnvVar, "")
+	os.Unsetenv(destroyAllConfirmationEnvVar)
+	assert.NoError(t, checkDestroyAllConfirmation("myproj", true, confirm(true)))
+	assert.True(t, prompted)
+	assert.EqualError(t, checkDestroyAllConfirmation("myproj", true, confirm(false)), "confirmation declined")
+
+	// A non-interactive session cannot proceed without the environment variable.
+	prompted = false
+	err := checkDestroyAllConfirmation("myproj", false, confirm(true))
+	assert.EqualError(t, err,
+		"destroying every stack must be confirmed; set PULUMI_DESTROY_ALL_CONFIRMATION to the project's name to proceed")
+	assert.False(t, prompted)
+
+	// The environment variable confirms the destroy without a prompt if it matches the project name.
+	t.Setenv(destroyAllConfirmationEnvVar, "myproj")
+	assert.NoError(t, checkDestroyAllConfirmation("myproj", false, confirm(false)))
+	assert.False(t, prompted)
+
+	t.Setenv(destroyAllConfirmationEnvVar, "dev")
+	assert.EqualError(t, checkDestroyAllConfirmation("myproj", true, confirm(true)),
+		"PULUMI_DESTROY_ALL_CONFIRMATION does not match the name of project 'myproj'")
+	assert.False(t, prompted)
+}
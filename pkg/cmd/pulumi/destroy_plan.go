@@ -0,0 +1,158 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// destroyPlan is the plan written by `pulumi destroy --preview-only --save-plan`: the delete steps that destroying
+// the stack would perform, in the order that they would be performed.
+type destroyPlan struct {
+	Stack string    `json:"stack"`
+	Time  time.Time `json:"time"`
+	// Steps are the delete steps in order. Steps in the same wave do not depend on each other and may be performed in
+	// parallel. A step is performed as soon as the steps of earlier waves that delete the resources that depend on its
+	// resource have been, so it may overlap with steps of earlier waves that it does not wait for.
+	Steps []destroyPlanStep `json:"steps"`
+	// Protected is the number of steps that delete protected resources. The destroy fails when it reaches the first of
+	// them unless the resources are unprotected first.
+	Protected int `json:"protected"`
+}
+
+// destroyPlanStep is a single delete step of a destroy plan.
+type destroyPlanStep struct {
+	Wave      int           `json:"wave"`
+	Op        deploy.StepOp `json:"op"`
+	URN       resource.URN  `json:"urn"`
+	Type      string        `json:"type"`
+	ID        resource.ID   `json:"id,omitempty"`
+	Provider  string        `json:"provider,omitempty"`
+	Protected bool          `json:"protected,omitempty"`
+}
+
+// computeDestroyPlan computes the plan for destroying the given resources of a stack's snapshot, selecting them as
+// the engine does for `pulumi destroy` with the same targets and flags, and ordering them by the snapshot's
+// dependencies as the engine does.
+func computeDestroyPlan(stack string, resources []*resource.State, targets []resource.URN,
+	targetDependents, excludeProtected bool) (*destroyPlan, error) {
+
+	dg := graph.NewDependencyGraph(resources)
+
+	var selected map[*resource.State]bool
+	if len(targets) > 0 {
+		targeted := make(map[resource.URN]bool, len(targets))
+		for _, urn := range targets {
+			targeted[urn] = true
+		}
+		selected = make(map[*resource.State]bool)
+		for _, res := range resources {
+			if !targeted[res.URN] {
+				continue
+			}
+			selected[res] = true
+			for _, dep := range dg.DependingOn(res, nil, true) {
+				if !targeted[dep.URN] && !targetDependents {
+					return nil, fmt.Errorf("'%s' depends on '%s', which is targeted for destruction; target it too, "+
+						"or pass --target-dependents", dep.URN, res.URN)
+				}
+				selected[dep] = true
+			}
+		}
+	}
+
+	excluded := make(map[resource.URN]bool)
+	if excludeProtected {
+		_, protected := graph.SeparateProtected(resources)
+		for _, res := range protected {
+			excluded[res.URN] = true
+		}
+	}
+
+	// Deletes are generated by walking the snapshot backwards, as dependents follow their dependencies.
+	condemned := make(graph.ResourceSet)
+	order := make(map[*resource.State]int)
+	for i := len(resources) - 1; i >= 0; i-- {
+		res := resources[i]
+		if excluded[res.URN] || (selected != nil && !selected[res]) {
+			continue
+		}
+		condemned[res] = true
+		order[res] = len(order)
+	}
+
+	// Each wave is first found by peeling off the resources whose dependencies have all been peeled off already, which
+	// gives the waves in reverse.
+	var waves [][]*resource.State
+	for len(condemned) > 0 {
+		var wave []*resource.State
+		for res := range condemned {
+			if len(dg.DependenciesOf(res).Intersect(condemned)) == 0 {
+				wave = append(wave, res)
+			}
+		}
+		for _, res := range wave {
+			delete(condemned, res)
+		}
+		sort.Slice(wave, func(i, j int) bool { return order[wave[i]] < order[wave[j]] })
+		waves = append(waves, wave)
+	}
+
+	plan := &destroyPlan{Stack: stack, Time: time.Now().UTC(), Steps: []destroyPlanStep{}}
+	for i := len(waves) - 1; i >= 0; i-- {
+		for _, res := range waves[i] {
+			op := deploy.OpDelete
+			switch {
+			case res.External:
+				op = deploy.OpReadDiscard
+			case res.Delete:
+				op = deploy.OpDeleteReplaced
+			}
+			plan.Steps = append(plan.Steps, destroyPlanStep{
+				Wave:      len(waves) - i,
+				Op:        op,
+				URN:       res.URN,
+				Type:      string(res.Type),
+				ID:        res.ID,
+				Provider:  res.Provider,
+				Protected: res.Protect,
+			})
+			if res.Protect {
+				plan.Protected++
+			}
+		}
+	}
+	return plan, nil
+}
+
+// writeDestroyPlan writes a destroy plan to the given path as indented JSON.
+func writeDestroyPlan(path string, plan *destroyPlan) error {
+	b, err := json.MarshalIndent(plan, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0600); err != nil {
+		return fmt.Errorf("writing destroy plan: %w", err)
+	}
+	return nil
+}
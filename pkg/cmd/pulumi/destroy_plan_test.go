@@ -0,0 +1,79 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestComputeDestroyPlan(t *testing.T) {
+	const (
+		vpcURN    = resource.URN("urn:pulumi:dev::proj::aws:ec2/vpc:Vpc::vpc")
+		subnetURN = resource.URN("urn:pulumi:dev::proj::aws:ec2/subnet:Subnet::subnet")
+		dbURN     = resource.URN("urn:pulumi:dev::proj::aws:rds/instance:Instance::db")
+		webURN    = resource.URN("urn:pulumi:dev::proj::aws:ec2/instance:Instance::web")
+		amiURN    = resource.URN("urn:pulumi:dev::proj::aws:ec2/ami:Ami::ami")
+	)
+	resources := []*resource.State{
+		{URN: vpcURN, Type: "aws:ec2/vpc:Vpc", ID: "vpc-1", Custom: true},
+		{URN: subnetURN, Type: "aws:ec2/subnet:Subnet", ID: "subnet-1", Custom: true,
+			Dependencies: []resource.URN{vpcURN}},
+		{URN: dbURN, Type: "aws:rds/instance:Instance", ID: "db-1", Custom: true, Protect: true,
+			Dependencies: []resource.URN{subnetURN}},
+		{URN: amiURN, Type: "aws:ec2/ami:Ami", ID: "ami-1", Custom: true, External: true},
+		{URN: webURN, Type: "aws:ec2/instance:Instance", ID: "i-1", Custom: true,
+			Dependencies: []resource.URN{subnetURN, amiURN}},
+	}
+
+	urns := func(plan *destroyPlan) []resource.URN {
+		var urns []resource.URN
+		for _, step := range plan.Steps {
+			urns = append(urns, step.URN)
+		}
+		return urns
+	}
+
+	plan, err := computeDestroyPlan("dev", resources, nil, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{webURN, dbURN, subnetURN, amiURN, vpcURN}, urns(plan))
+	assert.Equal(t, []int{1, 1, 2, 3, 3}, []int{
+		plan.Steps[0].Wave, plan.Steps[1].Wave, plan.Steps[2].Wave, plan.Steps[3].Wave, plan.Steps[4].Wave})
+	assert.Equal(t, 1, plan.Protected)
+	assert.True(t, plan.Steps[1].Protected)
+	assert.Equal(t, deploy.OpReadDiscard, plan.Steps[3].Op)
+	assert.Equal(t, deploy.OpDelete, plan.Steps[4].Op)
+
+	// Excluding protected resources also leaves everything they depend on.
+	plan, err = computeDestroyPlan("dev", resources, nil, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{webURN, amiURN}, urns(plan))
+	assert.Zero(t, plan.Protected)
+
+	// Targeting a resource that others depend on requires them to be targeted too.
+	_, err = computeDestroyPlan("dev", resources, []resource.URN{subnetURN}, false, false)
+	assert.Error(t, err)
+	plan, err = computeDestroyPlan("dev", resources, []resource.URN{subnetURN}, true, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{webURN, dbURN, subnetURN}, urns(plan))
+
+	plan, err = computeDestroyPlan("dev", nil, nil, false, false)
+	assert.NoError(t, err)
+	assert.Empty(t, plan.Steps)
+}
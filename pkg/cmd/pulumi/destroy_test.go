@@ -0,0 +1,127 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestCheckDestroyConfirmation(t *testing.T) {
+	t.Setenv(workspace.PulumiHomeEnvVar, t.TempDir())
+
+	prompted := false
+	confirm := func(answer bool) func(prompt, name string) bool {
+		return func(prompt, name string) bool {
+			prompted = true
+			assert.Equal(t, "prod", name)
+			return answer
+		}
+	}
+
+	// Without the environment variable, an interactive session prompts for the stack name.
+	t.Setenv(destroyConfirmationEnvVar, "")
+	os.Unsetenv(destroyConfirmationEnvVar)
+	assert.NoError(t, checkDestroyConfirmation("org/proj/prod", "prod", true, confirm(true)))
+	assert.True(t, prompted)
+	assert.EqualError(t, checkDestroyConfirmation("org/proj/prod", "prod", true, confirm(false)),
+		"confirmation declined")
+
+	// A non-interactive session cannot proceed without the environment variable.
+	prompted = false
+	err := checkDestroyConfirmation("org/proj/prod", "prod", false, confirm(true))
+	assert.EqualError(t, err, "stack 'prod' requires destroys to be confirmed; set PULUMI_DESTROY_CONFIRMATION "+
+		"to a token issued by `pulumi stack destroy-token` to proceed")
+	assert.False(t, prompted)
+
+	// The stack name is not a token.
+	t.Setenv(destroyConfirmationEnvVar, "prod")
+	assert.EqualError(t, checkDestroyConfirmation("org/proj/prod", "prod", false, confirm(true)),
+		"no destroy confirmation token has been issued for stack 'org/proj/prod'; "+
+			"run `pulumi stack destroy-token` to issue one")
+
+	// An issued token confirms one destroy of its stack without a prompt.
+	token, err := issueDestroyToken("org/proj/prod", time.Now())
+	require.NoError(t, err)
+	t.Setenv(destroyConfirmationEnvVar, token)
+	assert.NoError(t, checkDestroyConfirmation("org/proj/prod", "prod", false, confirm(false)))
+	assert.False(t, prompted)
+	assert.EqualError(t, checkDestroyConfirmation("org/proj/prod", "prod", true, confirm(true)),
+		"no destroy confirmation token has been issued for stack 'org/proj/prod'; "+
+			"run `pulumi stack destroy-token` to issue one")
+	assert.False(t, prompted)
+}
+
+func TestConsumeDestroyToken(t *testing.T) {
+	t.Setenv(workspace.PulumiHomeEnvVar, t.TempDir())
+
+	now := time.Now()
+	token, err := issueDestroyToken("org/proj/prod", now)
+	require.NoError(t, err)
+
+	// A token only confirms a destroy of the stack it was issued for.
+	_, err = issueDestroyToken("org/proj/dev", now)
+	require.NoError(t, err)
+	assert.EqualError(t, consumeDestroyToken("org/proj/dev", token, now),
+		"PULUMI_DESTROY_CONFIRMATION is not the destroy confirmation token issued for stack 'org/proj/dev'")
+
+	assert.EqualError(t, consumeDestroyToken("org/proj/prod", "guess", now),
+		"PULUMI_DESTROY_CONFIRMATION is not the destroy confirmation token issued for stack 'org/proj/prod'")
+
+	// A token can only be used once.
+	assert.NoError(t, consumeDestroyToken("org/proj/prod", token, now))
+	assert.Error(t, consumeDestroyToken("org/proj/prod", token, now))
+
+	// Issuing a token revokes the previous one.
+	first, err := issueDestroyToken("org/proj/prod", now)
+	require.NoError(t, err)
+	second, err := issueDestroyToken("org/proj/prod", now)
+	require.NoError(t, err)
+	assert.Error(t, consumeDestroyToken("org/proj/prod", first, now))
+	assert.NoError(t, consumeDestroyToken("org/proj/prod", second, now))
+
+	// A token expires.
+	token, err = issueDestroyToken("org/proj/prod", now)
+	require.NoError(t, err)
+	assert.EqualError(t, consumeDestroyToken("org/proj/prod", token, now.Add(destroyTokenTTL+time.Second)),
+		"the destroy confirmation token for stack 'org/proj/prod' has expired; "+
+			"run `pulumi stack destroy-token` to issue another")
+}
+
+func TestStackReferrers(t *testing.T) {
+	snaps := map[string]*deploy.Snapshot{
+		"app":     newStackReferenceSnapshot(map[string][]string{"network": {"vpcId"}, "db": {"url"}}),
+		"db":      newStackReferenceSnapshot(map[string][]string{"network": {"subnetId"}}),
+		"network": newStackReferenceSnapshot(map[string][]string{"network": nil}),
+	}
+	load := func(name string) (string, *deploy.Snapshot, error) {
+		return name, snaps[name], nil
+	}
+
+	g, err := makeStackReferenceGraph([]string{"app", "db", "network"}, load)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"app", "db"}, stackReferrers(g, "network"))
+	assert.Equal(t, []string{"app"}, stackReferrers(g, "db"))
+	assert.Empty(t, stackReferrers(g, "app"))
+	assert.Empty(t, stackReferrers(g, "missing"))
+}
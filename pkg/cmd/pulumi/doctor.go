@@ -0,0 +1,334 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/state"
+	"github.com/pulumi/pulumi/pkg/v3/version"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// doctorStatus is the outcome of a single `pulumi doctor` check.
+type doctorStatus string
+
+const (
+	doctorOK      doctorStatus = "ok"
+	doctorWarning doctorStatus = "warning"
+	doctorError   doctorStatus = "error"
+	doctorSkipped doctorStatus = "skipped"
+)
+
+// doctorCheck is the result of a single `pulumi doctor` check, along with how to fix any problem that it found.
+type doctorCheck struct {
+	Name    string       `json:"name"`
+	Status  doctorStatus `json:"status"`
+	Message string       `json:"message"`
+	Fix     string       `json:"fix,omitempty"`
+}
+
+// doctorReport is the result of all of the `pulumi doctor` checks, in a form that can be attached to support tickets.
+type doctorReport struct {
+	CLIVersion string        `json:"cliVersion"`
+	OS         string        `json:"os"`
+	Arch       string        `json:"arch"`
+	Time       time.Time     `json:"time"`
+	Checks     []doctorCheck `json:"checks"`
+}
+
+// failed returns the number of checks that found errors.
+func (r doctorReport) failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == doctorError {
+			n++
+		}
+	}
+	return n
+}
+
+func newDoctorCmd() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with the Pulumi environment",
+		Long: "Diagnose problems with the Pulumi environment.\n" +
+			"\n" +
+			"Runs a series of checks and suggests how to fix any problems that they find:\n" +
+			" - whether a newer version of the CLI is available\n" +
+			" - whether the current backend can be reached\n" +
+			" - whether the current backend's credentials are valid\n" +
+			" - whether the plugins that the current project requires are installed\n" +
+			" - whether the current project's language runtime is available\n" +
+			" - whether the current stack is locked by another update\n" +
+			"\n" +
+			"Pass --json for a machine-readable report that can be attached to support tickets.\n" +
+			"The command fails if any check finds an error.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			report := runDoctorChecks(display.Options{Color: cmdutil.GetGlobalColorization()})
+			if jsonOut {
+				if err := printJSON(report); err != nil {
+					return result.FromError(err)
+				}
+			} else {
+				printDoctorReport(report, cmdutil.GetGlobalColorization())
+			}
+
+			if n := report.failed(); n > 0 {
+				return result.Errorf("%d check(s) found errors", n)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit the report as JSON")
+
+	return cmd
+}
+
+// runDoctorChecks runs each of the `pulumi doctor` checks. Checks that depend on something that is unavailable, such
+// as a project or a backend, are skipped.
+func runDoctorChecks(opts display.Options) doctorReport {
+	report := doctorReport{
+		CLIVersion: getCLIAbout().Version,
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Time:       time.Now().UTC(),
+	}
+	add := func(c doctorCheck) {
+		report.Checks = append(report.Checks, c)
+	}
+
+	add(checkCLIVersion())
+
+	b, err := currentBackend(opts)
+	if err != nil {
+		b = nil
+		add(doctorCheck{
+			Name:    "backend",
+			Status:  doctorError,
+			Message: fmt.Sprintf("could not connect to the backend: %v", err),
+			Fix:     "run `pulumi login` to log in to a backend",
+		})
+		add(doctorCheck{Name: "credentials", Status: doctorSkipped, Message: "no backend"})
+	} else {
+		add(checkBackendConnectivity(b))
+		add(checkCredentials(b))
+	}
+
+	proj, _, err := readProject()
+	if err != nil {
+		add(doctorCheck{Name: "plugins", Status: doctorSkipped, Message: "no current project"})
+		add(doctorCheck{Name: "runtime", Status: doctorSkipped, Message: "no current project"})
+	} else {
+		plugins, err := getProjectPluginsSilently()
+		if err != nil {
+			add(doctorCheck{
+				Name:    "plugins",
+				Status:  doctorError,
+				Message: fmt.Sprintf("could not determine the plugins that the project requires: %v", err),
+				Fix:     "install the project's dependencies, e.g. with `npm install`, and try again",
+			})
+		} else {
+			add(checkPluginsInstalled(plugins))
+		}
+		add(checkLanguageRuntime(proj))
+	}
+
+	if b == nil {
+		add(doctorCheck{Name: "state-lock", Status: doctorSkipped, Message: "no backend"})
+	} else {
+		add(checkStateLock(b))
+	}
+
+	return report
+}
+
+// checkCLIVersion checks whether a newer version of the CLI is available.
+func checkCLIVersion() doctorCheck {
+	check := doctorCheck{Name: "cli-version"}
+	current, err := semver.ParseTolerant(version.Version)
+	if err != nil || isDevVersion(current) {
+		check.Status, check.Message = doctorSkipped, fmt.Sprintf("development version %q", version.Version)
+		return check
+	}
+
+	latest, _, err := getCLIVersionInfo()
+	if err != nil {
+		check.Status = doctorWarning
+		check.Message = fmt.Sprintf("could not determine the latest version of the CLI: %v", err)
+		return check
+	}
+	return compareCLIVersions(current, latest)
+}
+
+// compareCLIVersions describes whether the current version of the CLI is the latest.
+func compareCLIVersions(current, latest semver.Version) doctorCheck {
+	check := doctorCheck{Name: "cli-version"}
+	if current.GTE(latest) {
+		check.Status, check.Message = doctorOK, fmt.Sprintf("v%s is the latest version", current)
+		return check
+	}
+
+	check.Status = doctorWarning
+	check.Message = fmt.Sprintf("v%s is installed, but v%s is available", current, latest)
+	if upgrade := getUpgradeCommand(); upgrade != "" {
+		check.Fix = fmt.Sprintf("run `%s`", upgrade)
+	} else {
+		check.Fix = "visit https://pulumi.com/docs/reference/install/ to upgrade"
+	}
+	return check
+}
+
+// checkBackendConnectivity checks that the backend can be reached by listing its stacks.
+func checkBackendConnectivity(b backend.Backend) doctorCheck {
+	check := doctorCheck{Name: "backend"}
+	if _, _, err := b.ListStacks(commandContext(), backend.ListStacksFilter{}, nil /*inContToken*/); err != nil {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("could not reach %s: %v", b.URL(), err)
+		check.Fix = "check your network connection and proxy settings, or that the state storage is accessible"
+		return check
+	}
+	check.Status, check.Message = doctorOK, fmt.Sprintf("connected to %s", b.URL())
+	return check
+}
+
+// checkCredentials checks that the backend accepts the current credentials.
+func checkCredentials(b backend.Backend) doctorCheck {
+	check := doctorCheck{Name: "credentials"}
+	user, err := b.CurrentUser()
+	if err != nil {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("the credentials for %s are not valid: %v", b.URL(), err)
+		check.Fix = fmt.Sprintf("run `pulumi login %s` to log in again", b.URL())
+		return check
+	}
+	check.Status, check.Message = doctorOK, fmt.Sprintf("logged in as %s", user)
+	return check
+}
+
+// checkPluginsInstalled checks that each of the given plugins is installed, at the required version or later.
+func checkPluginsInstalled(plugins []workspace.PluginInfo) doctorCheck {
+	check := doctorCheck{Name: "plugins"}
+	var missing []string
+	required := 0
+	for _, plugin := range plugins {
+		// Language plugins are distributed with the CLI.
+		if plugin.Kind == workspace.LanguagePlugin {
+			continue
+		}
+		required++
+		if has, _ := workspace.HasPluginGTE(plugin); !has {
+			missing = append(missing, fmt.Sprintf("%s %s", plugin.Kind, plugin))
+		}
+	}
+
+	if len(missing) > 0 {
+		check.Status = doctorError
+		check.Message = fmt.Sprintf("%d of %d required plugin(s) are not installed: %s",
+			len(missing), required, strings.Join(missing, ", "))
+		check.Fix = "run `pulumi plugin install`"
+		return check
+	}
+	check.Status, check.Message = doctorOK, fmt.Sprintf("all %d required plugin(s) are installed", required)
+	return check
+}
+
+// checkLanguageRuntime checks that the project's language runtime is available.
+func checkLanguageRuntime(proj *workspace.Project) doctorCheck {
+	check := doctorCheck{Name: "runtime"}
+	about, err := getProjectRuntimeAbout(proj)
+	if err != nil {
+		check.Status = doctorError
+		check.Message = err.Error()
+		check.Fix = fmt.Sprintf("install the %s runtime and make sure that it is on your PATH", proj.Runtime.Name())
+		return check
+	}
+	check.Status = doctorOK
+	check.Message = fmt.Sprintf("%s %s (%s)", about.Language, about.Version, about.Executable)
+	return check
+}
+
+// checkStateLock checks whether the current stack is locked by another update.
+func checkStateLock(b backend.Backend) doctorCheck {
+	check := doctorCheck{Name: "state-lock"}
+	inspector, ok := b.(backend.LockInspector)
+	if !ok {
+		check.Status, check.Message = doctorSkipped, fmt.Sprintf("not supported by the %s backend", b.Name())
+		return check
+	}
+	s, err := state.CurrentStack(commandContext(), b)
+	if err != nil || s == nil {
+		check.Status, check.Message = doctorSkipped, "no current stack"
+		return check
+	}
+
+	holders, err := inspector.LockHolders(commandContext(), s.Ref())
+	if err != nil {
+		check.Status = doctorWarning
+		check.Message = fmt.Sprintf("could not check whether %s is locked: %v", s.Ref(), err)
+		return check
+	}
+	if len(holders) > 0 {
+		descriptions := make([]string, len(holders))
+		for i, h := range holders {
+			descriptions[i] = h.String()
+		}
+		check.Status = doctorWarning
+		check.Message = fmt.Sprintf("%s is locked: %s", s.Ref(), strings.Join(descriptions, "; "))
+		check.Fix = "wait for the update to finish, or run `pulumi cancel` if it is no longer running"
+		return check
+	}
+	check.Status, check.Message = doctorOK, fmt.Sprintf("%s is not locked", s.Ref())
+	return check
+}
+
+// printDoctorReport prints the results of the checks as a table, followed by the fixes for any problems.
+func printDoctorReport(report doctorReport, color colors.Colorization) {
+	rows := make([]cmdutil.TableRow, len(report.Checks))
+	for i, c := range report.Checks {
+		rows[i] = cmdutil.TableRow{Columns: []string{c.Name, string(c.Status), c.Message}}
+	}
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"CHECK", "STATUS", "DETAILS"},
+		Rows:    rows,
+	})
+
+	var fixes []string
+	for _, c := range report.Checks {
+		if c.Fix != "" {
+			fixes = append(fixes, fmt.Sprintf("    %s: %s", c.Name, c.Fix))
+		}
+	}
+	if len(fixes) > 0 {
+		fmt.Println()
+		fmt.Println(color.Colorize(colors.SpecAttention + "Suggested fixes:" + colors.Reset))
+		fmt.Println(strings.Join(fixes, "\n"))
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestCompareCLIVersions(t *testing.T) {
+	check := compareCLIVersions(semver.MustParse("3.10.0"), semver.MustParse("3.10.0"))
+	assert.Equal(t, doctorOK, check.Status)
+	assert.Empty(t, check.Fix)
+
+	check = compareCLIVersions(semver.MustParse("3.9.0"), semver.MustParse("3.10.0"))
+	assert.Equal(t, doctorWarning, check.Status)
+	assert.Contains(t, check.Message, "v3.10.0 is available")
+	assert.NotEmpty(t, check.Fix)
+}
+
+func TestCheckPluginsInstalled(t *testing.T) {
+	home, err := ioutil.TempDir("", "pulumi-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	old, had := os.LookupEnv(workspace.PulumiHomeEnvVar)
+	os.Setenv(workspace.PulumiHomeEnvVar, home)
+	defer func() {
+		if had {
+			os.Setenv(workspace.PulumiHomeEnvVar, old)
+		} else {
+			os.Unsetenv(workspace.PulumiHomeEnvVar)
+		}
+	}()
+
+	v1, v2 := semver.MustParse("1.0.0"), semver.MustParse("2.0.0")
+	installed := workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: "test", Version: &v1}
+	dir, err := installed.DirPath()
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, installed.File()), []byte("#!/bin/sh\n"), 0700))
+
+	language := workspace.PluginInfo{Kind: workspace.LanguagePlugin, Name: "nodejs"}
+	check := checkPluginsInstalled([]workspace.PluginInfo{installed, language})
+	assert.Equal(t, doctorOK, check.Status)
+	assert.Contains(t, check.Message, "all 1 required plugin(s)")
+
+	newer := workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: "test", Version: &v2}
+	check = checkPluginsInstalled([]workspace.PluginInfo{installed, newer})
+	assert.Equal(t, doctorError, check.Status)
+	assert.Contains(t, check.Message, "resource test-2.0.0")
+	assert.Equal(t, "run `pulumi plugin install`", check.Fix)
+
+	assert.Equal(t, 1, doctorReport{Checks: []doctorCheck{check, {Status: doctorWarning}}}.failed())
+}
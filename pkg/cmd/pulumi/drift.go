@@ -0,0 +1,299 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// driftExitCode is the exit code of `pulumi drift` when drift was detected. Failures exit with -1 as usual, so that
+// scheduled jobs can tell the two apart.
+const driftExitCode = 2
+
+func newDriftCmd() *cobra.Command {
+	var stack string
+	var execKind string
+	var execAgent string
+	var jsonOut bool
+	var parallel int
+	var targets []string
+
+	var cmd = &cobra.Command{
+		Use:   "drift",
+		Short: "Detect resources whose actual state has drifted from the stack's state",
+		Long: "Detect resources whose actual state has drifted from the stack's state.\n" +
+			"\n" +
+			"This command reads the current state of every resource in the stack from its provider, as\n" +
+			"`pulumi refresh` does, and reports the resources that have changed or no longer exist, along\n" +
+			"with the properties that changed. Unlike refresh, the stack's state is never written.\n" +
+			"\n" +
+			"The command exits with code 0 if no drift was detected, and with code 2 if any resource has\n" +
+			"drifted, which makes it suitable for scheduled CI jobs. Pass `--json` to print a\n" +
+			"machine-readable report to stdout; progress is then written to stderr.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			interactive := cmdutil.Interactive()
+
+			opts := backend.UpdateOptions{
+				AutoApprove: true,
+				PreviewOnly: true,
+			}
+			opts.Display = display.Options{
+				Color:             cmdutil.GetGlobalColorization(),
+				IsInteractive:     interactive,
+				Type:              display.DisplayProgress,
+				SuppressPermalink: true,
+			}
+			if jsonOut {
+				opts.Display.IsInteractive = false
+				opts.Display.Stdout = os.Stderr
+			}
+
+			s, err := requireStack(stack, false, opts.Display, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			proj, root, err := readProject()
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			m, err := getUpdateMetadata("", proj, root, execKind, execAgent)
+			if err != nil {
+				return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
+			}
+
+			sm, err := getStackSecretsManager(s)
+			if err != nil {
+				return result.FromError(fmt.Errorf("getting secrets manager: %w", err))
+			}
+
+			cfg, err := getStackConfiguration(s, sm)
+			if err != nil {
+				return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
+			}
+
+			targetURNs := []resource.URN{}
+			for _, t := range targets {
+				targetURNs = append(targetURNs, resource.URN(t))
+			}
+
+			// The engine's events are recorded in an event log, from which the report is built once the reads
+			// are done.
+			dir, err := ioutil.TempDir("", "pulumi-drift-")
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer contract.IgnoreError(os.RemoveAll(dir))
+			opts.Display.EventLogPath = filepath.Join(dir, "events.json")
+
+			opts.Engine = engine.UpdateOptions{
+				Parallel:                  parallel,
+				UseLegacyDiff:             useLegacyDiff(),
+				DisableProviderPreview:    disableProviderPreview(),
+				DisableResourceReferences: disableResourceReferences(),
+				DisableOutputValues:       disableOutputValues(),
+				RefreshTargets:            targetURNs,
+			}
+
+			_, res := s.Refresh(commandContext(), backend.UpdateOperation{
+				Proj:               proj,
+				Root:               root,
+				M:                  m,
+				Opts:               opts,
+				StackConfiguration: cfg,
+				SecretsManager:     sm,
+				Scopes:             cancellationScopes,
+			})
+			switch {
+			case res != nil && res.Error() == context.Canceled:
+				return result.FromError(errors.New("drift detection cancelled"))
+			case res != nil:
+				return PrintEngineResult(res)
+			}
+
+			f, err := os.Open(opts.Display.EventLogPath)
+			if err != nil {
+				return result.FromError(fmt.Errorf("reading event log: %w", err))
+			}
+			defer contract.IgnoreClose(f)
+			report, err := readDriftReport(f)
+			if err != nil {
+				return result.FromError(fmt.Errorf("reading event log: %w", err))
+			}
+
+			if jsonOut {
+				if err := printJSON(report); err != nil {
+					return result.FromError(err)
+				}
+			} else {
+				printDriftReport(os.Stdout, report)
+			}
+
+			if report.Drifted {
+				os.Exit(driftExitCode)
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&stackConfigFile, "config-file", "",
+		"Use the configuration values in the specified file rather than detecting the file name")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false,
+		"Emit the drift report as JSON")
+	cmd.PersistentFlags().IntVarP(
+		&parallel, "parallel", "p", defaultParallel,
+		"Allow P resource operations to run in parallel at once (1 for no parallelism). Defaults to unbounded.")
+	cmd.PersistentFlags().StringArrayVarP(
+		&targets, "target", "t", []string{},
+		"Specify a single resource URN to check. Multiple resources can be specified using: --target urn1 --target urn2")
+
+	// internal flags
+	cmd.PersistentFlags().StringVar(&execKind, "exec-kind", "", "")
+	// ignore err, only happens if flag does not exist
+	_ = cmd.PersistentFlags().MarkHidden("exec-kind")
+	cmd.PersistentFlags().StringVar(&execAgent, "exec-agent", "", "")
+	// ignore err, only happens if flag does not exist
+	_ = cmd.PersistentFlags().MarkHidden("exec-agent")
+
+	return cmd
+}
+
+// driftReport is the machine-readable result of `pulumi drift`.
+type driftReport struct {
+	// Drifted is true if any resource has drifted.
+	Drifted bool `json:"drifted"`
+	// Resources lists the resources that have drifted, in the order in which they were read.
+	Resources []driftedResource `json:"resources,omitempty"`
+}
+
+// driftedResource describes a resource whose actual state differs from the stack's state.
+type driftedResource struct {
+	URN  string `json:"urn"`
+	Type string `json:"type"`
+	// Status is "changed" if the resource's properties differ, or "deleted" if it no longer exists.
+	Status string `json:"status"`
+	// Properties lists the top-level output properties that changed, if the resource still exists.
+	Properties []string `json:"properties,omitempty"`
+}
+
+// readDriftReport builds a drift report from the engine event log written during a refresh preview. Each resource's
+// refresh is reported by an outputs event whose operation is the result of the refresh.
+func readDriftReport(r io.Reader) (driftReport, error) {
+	report := driftReport{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var event apitype.EngineEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return driftReport{}, err
+		}
+		if event.ResOutputsEvent == nil {
+			continue
+		}
+
+		m := event.ResOutputsEvent.Metadata
+		switch m.Op {
+		case apitype.OpUpdate:
+			var olds, news map[string]interface{}
+			if m.Old != nil {
+				olds = m.Old.Outputs
+			}
+			if m.New != nil {
+				news = m.New.Outputs
+			}
+			report.Resources = append(report.Resources, driftedResource{
+				URN:        m.URN,
+				Type:       m.Type,
+				Status:     "changed",
+				Properties: changedProperties(olds, news),
+			})
+		case apitype.OpDelete:
+			report.Resources = append(report.Resources, driftedResource{
+				URN:    m.URN,
+				Type:   m.Type,
+				Status: "deleted",
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return driftReport{}, err
+	}
+
+	report.Drifted = len(report.Resources) > 0
+	return report, nil
+}
+
+// changedProperties returns the sorted names of the top-level properties whose values differ between two property
+// maps, including properties that are only present in one of them.
+func changedProperties(olds, news map[string]interface{}) []string {
+	var changed []string
+	for k, v := range olds {
+		if nv, ok := news[k]; !ok || !reflect.DeepEqual(v, nv) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range news {
+		if _, ok := olds[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// printDriftReport writes a human-readable summary of a drift report.
+func printDriftReport(w io.Writer, report driftReport) {
+	fmt.Fprintln(w)
+	if !report.Drifted {
+		fmt.Fprintln(w, "No drift detected.")
+		return
+	}
+
+	fmt.Fprintf(w, "Drift detected in %d resource(s):\n", len(report.Resources))
+	for _, res := range report.Resources {
+		fmt.Fprintf(w, "    %s (%s)\n", res.URN, res.Status)
+		for _, p := range res.Properties {
+			fmt.Fprintf(w, "        %s\n", p)
+		}
+	}
+}
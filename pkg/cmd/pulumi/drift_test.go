@@ -0,0 +1,71 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func TestReadDriftReport(t *testing.T) {
+	outputsEvent := func(urn string, op apitype.OpType, olds, news map[string]interface{}) apitype.EngineEvent {
+		return apitype.EngineEvent{
+			ResOutputsEvent: &apitype.ResOutputsEvent{
+				Metadata: apitype.StepEventMetadata{
+					Op:   op,
+					URN:  urn,
+					Type: "pkgA:m:typA",
+					Old:  &apitype.StepEventStateMetadata{Outputs: olds},
+					New:  &apitype.StepEventStateMetadata{Outputs: news},
+				},
+			},
+		}
+	}
+
+	var log bytes.Buffer
+	enc := json.NewEncoder(&log)
+	for _, e := range []apitype.EngineEvent{
+		{PreludeEvent: &apitype.PreludeEvent{}},
+		outputsEvent("urn:a", apitype.OpSame,
+			map[string]interface{}{"foo": "bar"}, map[string]interface{}{"foo": "bar"}),
+		outputsEvent("urn:b", apitype.OpUpdate,
+			map[string]interface{}{"foo": "bar", "baz": 1.0, "qux": true},
+			map[string]interface{}{"foo": "bar", "baz": 2.0, "zed": "new"}),
+		outputsEvent("urn:c", apitype.OpDelete, map[string]interface{}{"foo": "bar"}, nil),
+	} {
+		require.NoError(t, enc.Encode(e))
+	}
+
+	report, err := readDriftReport(&log)
+	require.NoError(t, err)
+	assert.Equal(t, driftReport{
+		Drifted: true,
+		Resources: []driftedResource{
+			{URN: "urn:b", Type: "pkgA:m:typA", Status: "changed", Properties: []string{"baz", "qux", "zed"}},
+			{URN: "urn:c", Type: "pkgA:m:typA", Status: "deleted"},
+		},
+	}, report)
+
+	report, err = readDriftReport(&bytes.Buffer{})
+	require.NoError(t, err)
+	assert.False(t, report.Drifted)
+	assert.Empty(t, report.Resources)
+}
@@ -0,0 +1,42 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+// excludeProtectedResources computes the resources that --exclude-protected leaves untouched for the given stack and
+// reports how many there are.
+func excludeProtectedResources(s backend.Stack, operation string) ([]resource.URN, error) {
+	snap, err := s.Snapshot(commandContext())
+	if err != nil {
+		return nil, fmt.Errorf("getting snapshot: %w", err)
+	}
+
+	urns := backend.ProtectedResourceURNs(snap)
+	noun := "resources"
+	if len(urns) == 1 {
+		noun = "resource"
+	}
+	cmdutil.Diag().Infoerrf(diag.RawMessage("" /*urn*/, fmt.Sprintf(
+		"Excluding %d protected %s from this %s", len(urns), noun, operation)))
+	return urns, nil
+}
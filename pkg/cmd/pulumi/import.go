@@ -21,8 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/hashicorp/hcl/v2"
@@ -124,13 +128,69 @@ func readImportFile(p string) (importFile, error) {
 	return result, nil
 }
 
-func parseImportFile(f importFile, protectResources bool) ([]deploy.Import, importer.NameTable, error) {
+func parseImportFile(f importFile, stackName tokens.QName, projectName tokens.PackageName,
+	protectResources bool) ([]deploy.Import, importer.NameTable, error) {
+
 	// Build the name table.
 	names := importer.NameTable{}
 	for name, urn := range f.NameTable {
 		names[urn] = name
 	}
 
+	// A resource may also name another resource in the file as its parent, in which case the parent is imported
+	// first. Index the resources by name so that such parents can be found.
+	byName := map[string]int{}
+	for i, spec := range f.Resources {
+		if _, has := byName[string(spec.Name)]; has {
+			byName[string(spec.Name)] = -1
+		} else {
+			byName[string(spec.Name)] = i
+		}
+	}
+
+	parents := make([]resource.URN, len(f.Resources))
+	resolved := make([]bool, len(f.Resources))
+	resolving := make([]bool, len(f.Resources))
+	var resolveParent func(i int) (resource.URN, error)
+	resolveParent = func(i int) (resource.URN, error) {
+		spec := f.Resources[i]
+		if resolved[i] || spec.Parent == "" {
+			return parents[i], nil
+		}
+		if resolving[i] {
+			return "", fmt.Errorf("the parent of resource '%v' of type '%v' depends on the resource itself",
+				spec.Name, spec.Type)
+		}
+		resolving[i] = true
+
+		urn, ok := f.NameTable[spec.Parent]
+		if !ok {
+			j, has := byName[spec.Parent]
+			if !has {
+				return "", fmt.Errorf("the parent '%v' for resource '%v' of type '%v' has no name",
+					spec.Parent, spec.Name, spec.Type)
+			}
+			if j == -1 {
+				return "", fmt.Errorf("the parent '%v' for resource '%v' of type '%v' names more than one resource",
+					spec.Parent, spec.Name, spec.Type)
+			}
+
+			grandparent, err := resolveParent(j)
+			if err != nil {
+				return "", err
+			}
+			var parentType tokens.Type
+			if grandparent != "" && grandparent.Type() != resource.RootStackType {
+				parentType = grandparent.QualifiedType()
+			}
+			urn = resource.NewURN(stackName, projectName, parentType, f.Resources[j].Type, f.Resources[j].Name)
+			names[urn] = spec.Parent
+		}
+
+		parents[i], resolved[i] = urn, true
+		return urn, nil
+	}
+
 	imports := make([]deploy.Import, len(f.Resources))
 	for i, spec := range f.Resources {
 		imp := deploy.Import{
@@ -140,14 +200,11 @@ func parseImportFile(f importFile, protectResources bool) ([]deploy.Import, impo
 			Protect: protectResources,
 		}
 
-		if spec.Parent != "" {
-			urn, ok := f.NameTable[spec.Parent]
-			if !ok {
-				return nil, nil, fmt.Errorf("the parent '%v' for resource '%v' of type '%v' has no name",
-					spec.Parent, spec.Name, spec.Type)
-			}
-			imp.Parent = urn
+		parent, err := resolveParent(i)
+		if err != nil {
+			return nil, nil, err
 		}
+		imp.Parent = parent
 
 		if spec.Provider != "" {
 			urn, ok := f.NameTable[spec.Provider]
@@ -267,11 +324,111 @@ func generateImportedDefinitions(out io.Writer, stackName tokens.QName, projectN
 	}, resources, names)
 }
 
+// importLanguage describes a language in which imported resource definitions can be generated.
+type importLanguage struct {
+	generator programGeneratorFunc
+	extension string
+}
+
+// importLanguages lists the languages accepted by `pulumi import --language`, keyed by name.
+var importLanguages = map[string]importLanguage{
+	"csharp":     {generator: dotnet.GenerateProgram, extension: "cs"},
+	"go":         {generator: gogen.GenerateProgram, extension: "go"},
+	"python":     {generator: python.GenerateProgram, extension: "py"},
+	"typescript": {generator: nodejs.GenerateProgram, extension: "ts"},
+}
+
+// importLanguageAliases maps runtime names to the names in importLanguages.
+var importLanguageAliases = map[string]string{
+	"dotnet": "csharp",
+	"nodejs": "typescript",
+}
+
+// parseImportLanguages parses a comma-separated list of language names, or "all", into a sorted list of distinct names
+// from importLanguages.
+func parseImportLanguages(spec string) ([]string, error) {
+	seen := map[string]bool{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := importLanguageAliases[name]; ok {
+			name = alias
+		}
+
+		switch _, ok := importLanguages[name]; {
+		case name == "all":
+			for name := range importLanguages {
+				seen[name] = true
+			}
+		case ok:
+			seen[name] = true
+		default:
+			return nil, fmt.Errorf("unsupported language '%v'; supported languages are csharp, go, python, "+
+				"typescript, and all", name)
+		}
+	}
+
+	languages := make([]string, 0, len(seen))
+	for name := range seen {
+		languages = append(languages, name)
+	}
+	sort.Strings(languages)
+	return languages, nil
+}
+
+// writeImportedDefinitionFiles generates the definitions of the imported resources in each of the given languages,
+// writing each language's definitions to a separate file in dir.
+func writeImportedDefinitionFiles(dir string, languages []string, stackName tokens.QName,
+	projectName tokens.PackageName, snap *deploy.Snapshot, names importer.NameTable, imports []deploy.Import,
+	protectResources bool) error {
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("could not create output directory: %w", err)
+	}
+
+	for _, name := range languages {
+		lang := importLanguages[name]
+
+		var contents bytes.Buffer
+		valid, err := generateImportedDefinitions(&contents, stackName, projectName, snap, lang.generator, names,
+			imports, protectResources)
+		if err != nil {
+			if _, ok := err.(*importer.DiagnosticsError); ok {
+				err = fmt.Errorf("internal error: %w", err)
+			}
+			return fmt.Errorf("generating %v definitions: %w", name, err)
+		}
+		if !valid {
+			return nil
+		}
+
+		path := filepath.Join(dir, "import."+lang.extension)
+		if err := ioutil.WriteFile(path, contents.Bytes(), 0600); err != nil {
+			return fmt.Errorf("could not write output file: %w", err)
+		}
+		fmt.Printf("Wrote %v definitions to %v\n", name, path)
+	}
+	return nil
+}
+
+// importResult converts the result of an import into the result of the import command.
+func importResult(res result.Result) result.Result {
+	if res != nil {
+		if res.Error() == context.Canceled {
+			return result.FromError(errors.New("import cancelled"))
+		}
+		return PrintEngineResult(res)
+	}
+	return nil
+}
+
 func newImportCmd() *cobra.Command {
 	var parentSpec string
 	var providerSpec string
 	var importFilePath string
 	var outputFilePath string
+	var languageSpec string
+	var terraformStatePath string
+	var manifestPath string
 
 	var debug bool
 	var message string
@@ -287,6 +444,7 @@ func newImportCmd() *cobra.Command {
 	var skipPreview bool
 	var suppressOutputs bool
 	var suppressPermalink string
+	var waitForLock time.Duration
 	var yes bool
 	var protectResources bool
 
@@ -335,7 +493,10 @@ func newImportCmd() *cobra.Command {
 			"The name table maps language names to parent and provider URNs. These names are\n" +
 			"used in the generated definitions, and should match the corresponding declarations\n" +
 			"in the source program. This table is required if any parents or providers are\n" +
-			"specified by the resources to import.\n" +
+			"specified by the resources to import, unless the parent is itself one of the\n" +
+			"resources to import, in which case the parent may be specified by its name.\n" +
+			"All of the resources are imported in a single update, with each parent imported\n" +
+			"before its children, and code is generated for all of them.\n" +
 			"\n" +
 			"The resources list contains the set of resources to import. Each resource is\n" +
 			"specified as a triple of its type, name, and ID. The format of the ID is specific\n" +
@@ -343,10 +504,29 @@ func newImportCmd() *cobra.Command {
 			"these names must correspond to entries in the name table. If a resource does not\n" +
 			"specify a provider, it will be imported using the default provider for its type. A\n" +
 			"resource that does specify a provider may specify the version of the provider\n" +
-			"that will be used for its import.\n",
+			"that will be used for its import.\n" +
+			"\n" +
+			"The generated definitions are in the language of the project by default. Pass\n" +
+			"`--language` with a comma-separated list of languages, or `all`, to generate\n" +
+			"definitions in several languages at once. Each language's definitions are written\n" +
+			"to a separate file in the directory given by `--out`, or the current directory.\n" +
+			"\n" +
+			"Pass `--from-terraform` with the path to a Terraform state file to import the\n" +
+			"resources it manages. Each Terraform resource type is mapped to the corresponding\n" +
+			"resource of the bridged Pulumi provider. The generated import manifest is written to\n" +
+			"the path given by `--manifest-out`, and code is generated as for any other import.\n",
 		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
 			var importFile importFile
-			if importFilePath != "" {
+			if terraformStatePath != "" {
+				if importFilePath != "" || len(args) != 0 || parentSpec != "" || providerSpec != "" {
+					return result.Errorf("other resources may not be specified in conjunction with a Terraform state file")
+				}
+				f, err := importFileFromTerraform(terraformStatePath, manifestPath)
+				if err != nil {
+					return result.FromError(err)
+				}
+				importFile = f
+			} else if importFilePath != "" {
 				if len(args) != 0 || parentSpec != "" || providerSpec != "" {
 					return result.Errorf("an inline resource may not be specified in conjunction with an import file")
 				}
@@ -366,9 +546,18 @@ func newImportCmd() *cobra.Command {
 				importFile = f
 			}
 
+			var languages []string
+			if languageSpec != "" {
+				langs, err := parseImportLanguages(languageSpec)
+				if err != nil {
+					return result.FromError(err)
+				}
+				languages = langs
+			}
+
 			var outputResult bytes.Buffer
 			output := io.Writer(&outputResult)
-			if outputFilePath != "" {
+			if outputFilePath != "" && len(languages) == 0 {
 				f, err := os.Create(outputFilePath)
 				if err != nil {
 					return result.Errorf("could not open output file: %v", err)
@@ -377,11 +566,6 @@ func newImportCmd() *cobra.Command {
 				output = f
 			}
 
-			imports, nameTable, err := parseImportFile(importFile, protectResources)
-			if err != nil {
-				return result.FromError(err)
-			}
-
 			yes = yes || skipConfirmations()
 			interactive := cmdutil.Interactive()
 			if !interactive && !yes {
@@ -407,6 +591,7 @@ func newImportCmd() *cobra.Command {
 				EventLogPath:    eventLogPath,
 				Debug:           debug,
 			}
+			opts.WaitForLock = waitForLock
 
 			// we only suppress permalinks if the user passes true. the default is an empty string
 			// which we pass as 'false'
@@ -444,7 +629,9 @@ func newImportCmd() *cobra.Command {
 			case "python":
 				programGenerator = python.GenerateProgram
 			default:
-				return result.Errorf("cannot generate resource definitions for %v", proj.Runtime.Name())
+				if len(languages) == 0 {
+					return result.Errorf("cannot generate resource definitions for %v", proj.Runtime.Name())
+				}
 			}
 
 			// Fetch the current stack.
@@ -453,7 +640,12 @@ func newImportCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			m, err := getUpdateMetadata(message, root, execKind, execAgent)
+			imports, nameTable, err := parseImportFile(importFile, s.Ref().Name(), proj.Name, protectResources)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			m, err := getUpdateMetadata(message, proj, root, execKind, execAgent)
 			if err != nil {
 				return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
 			}
@@ -489,6 +681,18 @@ func newImportCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
+			if len(languages) != 0 {
+				outputDir := outputFilePath
+				if outputDir == "" {
+					outputDir = "."
+				}
+				if err := writeImportedDefinitionFiles(outputDir, languages, s.Ref().Name(), proj.Name, deployment,
+					nameTable, imports, protectResources); err != nil {
+					return result.FromError(err)
+				}
+				return importResult(res)
+			}
+
 			validImports, err := generateImportedDefinitions(
 				output, s.Ref().Name(), proj.Name, deployment, programGenerator, nameTable, imports,
 				protectResources)
@@ -528,13 +732,7 @@ func newImportCmd() *cobra.Command {
 
 			fmt.Printf(outputResult.String())
 
-			if res != nil {
-				if res.Error() == context.Canceled {
-					return result.FromError(errors.New("import cancelled"))
-				}
-				return PrintEngineResult(res)
-			}
-			return nil
+			return importResult(res)
 		}),
 	}
 
@@ -548,13 +746,25 @@ func newImportCmd() *cobra.Command {
 		&importFilePath, "file", "f", "", "The path to a JSON-encoded file containing a list of resources to import")
 	cmd.PersistentFlags().StringVarP(
 		&outputFilePath, "out", "o", "", "The path to the file that will contain the generated resource declarations")
+	cmd.PersistentFlags().StringVar(
+		&terraformStatePath, "from-terraform", "",
+		"The path to a Terraform state file whose resources should be imported")
+	cmd.PersistentFlags().StringVar(
+		&manifestPath, "manifest-out", "import.json",
+		"The path to the file that will contain the import manifest generated by --from-terraform")
+	cmd.PersistentFlags().StringVar(
+		&languageSpec, "language", "",
+		"A comma-separated list of languages in which to generate the resource declarations (csharp, go, python, "+
+			"typescript, or all). Each language's declarations are written to a separate file in the directory given "+
+			"by --out")
 
 	cmd.PersistentFlags().BoolVarP(
 		&debug, "debug", "d", false,
 		"Print detailed debugging output during resource operations")
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
-		"Optional message to associate with the update operation")
+		"Optional message to associate with the update operation. May contain placeholders such as "+
+			"{{.GitCommit}}, {{.GitBranch}} and {{.User}} that are expanded from the environment")
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"The name of the stack to operate on. Defaults to the current stack")
@@ -579,6 +789,10 @@ func newImportCmd() *cobra.Command {
 		&suppressPermalink, "suppress-permalink", "",
 		"Suppress display of the state permalink")
 	cmd.Flag("suppress-permalink").NoOptDefVal = "false"
+	cmd.PersistentFlags().DurationVar(
+		&waitForLock, "wait-for-lock", 0,
+		"If another update of the stack is in progress, wait up to this long for it to finish (e.g. '10m') "+
+			"instead of failing immediately")
 	cmd.PersistentFlags().BoolVarP(
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the refresh after previewing it")
@@ -0,0 +1,192 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/convert"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// tfState is the subset of a Terraform state file (format version 4) that is needed to import its resources.
+type tfState struct {
+	Version   int          `json:"version"`
+	Resources []tfResource `json:"resources"`
+}
+
+type tfResource struct {
+	Module    string       `json:"module,omitempty"`
+	Mode      string       `json:"mode"`
+	Type      string       `json:"type"`
+	Name      string       `json:"name"`
+	Provider  string       `json:"provider"`
+	Instances []tfInstance `json:"instances"`
+}
+
+type tfInstance struct {
+	IndexKey   interface{}            `json:"index_key,omitempty"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+func readTerraformState(p string) (tfState, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return tfState{}, err
+	}
+	defer contract.IgnoreClose(f)
+
+	var state tfState
+	if err = json.NewDecoder(f).Decode(&state); err != nil {
+		return tfState{}, err
+	}
+	if state.Version != 4 {
+		return tfState{}, fmt.Errorf("unsupported Terraform state version %v; only version 4 is supported",
+			state.Version)
+	}
+	return state, nil
+}
+
+// tfProviderName returns the name of the Terraform provider that manages a resource, e.g. "aws" for
+// `provider["registry.terraform.io/hashicorp/aws"]`. If the provider cannot be determined from the resource's
+// provider address, it is inferred from the prefix of the resource's type.
+func tfProviderName(res tfResource) string {
+	addr := res.Provider
+	if i := strings.Index(addr, "provider["); i != -1 {
+		addr = strings.Trim(addr[i+len("provider["):], `"]`)
+		if j := strings.LastIndex(addr, "/"); j != -1 {
+			addr = addr[j+1:]
+		}
+		if j := strings.Index(addr, "."); j != -1 {
+			// Strip any provider alias, e.g. `provider["registry.terraform.io/hashicorp/aws"].west`.
+			addr = addr[:j]
+		}
+		if addr != "" {
+			return addr
+		}
+	}
+	return convert.TerraformProviderName(res.Type)
+}
+
+// tfTypeResolver maps a Terraform resource type managed by the given Terraform provider to a Pulumi type token.
+type tfTypeResolver func(provider, tfType string) (tokens.Type, bool)
+
+// terraformToImportFile converts the managed resources in a Terraform state into an import manifest. Each resource
+// is named after its Terraform name, suffixed with its index if it has more than one instance. Resources whose types
+// cannot be resolved are skipped and reported by the returned warnings.
+func terraformToImportFile(state tfState, resolve tfTypeResolver) (importFile, []string) {
+	var f importFile
+	var warnings []string
+	names := map[string]int{}
+	for _, res := range state.Resources {
+		if res.Mode != "managed" {
+			continue
+		}
+
+		address := res.Type + "." + res.Name
+		if res.Module != "" {
+			address = res.Module + "." + address
+		}
+
+		typ, ok := resolve(tfProviderName(res), res.Type)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("skipping %v: no Pulumi resource type corresponds to the "+
+				"Terraform type %v", address, res.Type))
+			continue
+		}
+
+		for _, inst := range res.Instances {
+			id, ok := inst.Attributes["id"].(string)
+			if !ok || id == "" {
+				warnings = append(warnings, fmt.Sprintf("skipping %v: the resource has no ID", address))
+				continue
+			}
+
+			name := res.Name
+			if inst.IndexKey != nil {
+				name = fmt.Sprintf("%v_%v", name, inst.IndexKey)
+			}
+			if n := names[name]; n > 0 {
+				names[name] = n + 1
+				name = fmt.Sprintf("%v_%v", name, n)
+			} else {
+				names[name] = 1
+			}
+
+			f.Resources = append(f.Resources, importSpec{
+				Type: typ,
+				Name: tokens.QName(name),
+				ID:   resource.ID(id),
+			})
+		}
+	}
+	return f, warnings
+}
+
+// importFileFromTerraform builds an import manifest from the Terraform state file at statePath and writes it to
+// manifestPath, so that it can be reviewed or used with `pulumi import --file`.
+func importFileFromTerraform(statePath, manifestPath string) (importFile, error) {
+	state, err := readTerraformState(statePath)
+	if err != nil {
+		return importFile{}, fmt.Errorf("could not read Terraform state: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return importFile{}, err
+	}
+	sink := cmdutil.Diag()
+	ctx, err := plugin.NewContext(sink, sink, nil, nil, cwd, nil, true, nil)
+	if err != nil {
+		return importFile{}, err
+	}
+	defer contract.IgnoreClose(ctx)
+
+	types := convert.NewTerraformTypes(schema.NewPluginLoader(ctx.Host))
+	f, warnings := terraformToImportFile(state, func(provider, tfType string) (tokens.Type, bool) {
+		r, ok := types.Resource(provider, tfType)
+		if !ok {
+			return "", false
+		}
+		return tokens.Type(r.Token), true
+	})
+	for _, w := range warnings {
+		sink.Warningf(diag.RawMessage("" /*urn*/, w))
+	}
+	if len(f.Resources) == 0 {
+		return importFile{}, errors.New("the Terraform state contains no resources that can be imported")
+	}
+
+	b, err := json.MarshalIndent(f, "", "    ")
+	if err != nil {
+		return importFile{}, err
+	}
+	if err := ioutil.WriteFile(manifestPath, append(b, '\n'), 0600); err != nil {
+		return importFile{}, fmt.Errorf("could not write import manifest: %w", err)
+	}
+	fmt.Printf("Wrote import manifest for %d resources to %v\n", len(f.Resources), manifestPath)
+	return f, nil
+}
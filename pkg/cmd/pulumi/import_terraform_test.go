@@ -0,0 +1,95 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+func TestTFProviderName(t *testing.T) {
+	assert.Equal(t, "aws", tfProviderName(tfResource{
+		Type:     "aws_s3_bucket",
+		Provider: `provider["registry.terraform.io/hashicorp/aws"]`,
+	}))
+	assert.Equal(t, "aws", tfProviderName(tfResource{
+		Type:     "aws_s3_bucket",
+		Provider: `module.foo.provider["registry.terraform.io/hashicorp/aws"].west`,
+	}))
+	assert.Equal(t, "azurerm", tfProviderName(tfResource{Type: "azurerm_resource_group"}))
+}
+
+func TestTerraformToImportFile(t *testing.T) {
+	const stateJSON = `{
+		"version": 4,
+		"resources": [
+			{
+				"mode": "data",
+				"type": "aws_ami",
+				"name": "ubuntu",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "ami-123"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_s3_bucket",
+				"name": "logs",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "my-logs"}}]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_instance",
+				"name": "web",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [
+					{"index_key": 0, "attributes": {"id": "i-0"}},
+					{"index_key": 1, "attributes": {"id": "i-1"}}
+				]
+			},
+			{
+				"mode": "managed",
+				"type": "aws_unknown_thing",
+				"name": "mystery",
+				"provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+				"instances": [{"attributes": {"id": "x"}}]
+			}
+		]
+	}`
+	var state tfState
+	require.NoError(t, json.Unmarshal([]byte(stateJSON), &state))
+
+	types := map[string]tokens.Type{
+		"aws_s3_bucket": "aws:s3/bucket:Bucket",
+		"aws_instance":  "aws:ec2/instance:Instance",
+	}
+	f, warnings := terraformToImportFile(state, func(provider, tfType string) (tokens.Type, bool) {
+		assert.Equal(t, "aws", provider)
+		typ, ok := types[tfType]
+		return typ, ok
+	})
+
+	assert.Equal(t, []importSpec{
+		{Type: "aws:s3/bucket:Bucket", Name: "logs", ID: "my-logs"},
+		{Type: "aws:ec2/instance:Instance", Name: "web_0", ID: "i-0"},
+		{Type: "aws:ec2/instance:Instance", Name: "web_1", ID: "i-1"},
+	}, f.Resources)
+	assert.Len(t, warnings, 1)
+}
@@ -0,0 +1,88 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestParseImportFileParents(t *testing.T) {
+	external := resource.URN("urn:pulumi:dev::proj::my:mod:Component::comp")
+	f := importFile{
+		NameTable: map[string]resource.URN{"comp": external},
+		Resources: []importSpec{
+			{Type: "pkgA:m:typA", Name: "child", ID: "id-child", Parent: "bucket"},
+			{Type: "pkgA:m:typA", Name: "bucket", ID: "id-bucket", Parent: "comp"},
+			{Type: "pkgA:m:typB", Name: "other", ID: "id-other"},
+		},
+	}
+
+	imports, names, err := parseImportFile(f, "dev", "proj", true)
+	require.NoError(t, err)
+	require.Len(t, imports, 3)
+
+	bucket := resource.URN("urn:pulumi:dev::proj::my:mod:Component$pkgA:m:typA::bucket")
+	assert.Equal(t, bucket, imports[0].Parent)
+	assert.Equal(t, external, imports[1].Parent)
+	assert.Equal(t, resource.URN(""), imports[2].Parent)
+	assert.True(t, imports[0].Protect)
+
+	assert.Equal(t, "comp", names[external])
+	assert.Equal(t, "bucket", names[bucket])
+}
+
+func TestParseImportFileParentErrors(t *testing.T) {
+	_, _, err := parseImportFile(importFile{
+		Resources: []importSpec{
+			{Type: "pkgA:m:typA", Name: "a", ID: "id-a", Parent: "missing"},
+		},
+	}, "dev", "proj", false)
+	assert.EqualError(t, err, "the parent 'missing' for resource 'a' of type 'pkgA:m:typA' has no name")
+
+	_, _, err = parseImportFile(importFile{
+		Resources: []importSpec{
+			{Type: "pkgA:m:typA", Name: "a", ID: "id-a", Parent: "b"},
+			{Type: "pkgA:m:typA", Name: "b", ID: "id-b", Parent: "a"},
+		},
+	}, "dev", "proj", false)
+	assert.Error(t, err)
+
+	_, _, err = parseImportFile(importFile{
+		Resources: []importSpec{
+			{Type: "pkgA:m:typA", Name: "a", ID: "id-a", Parent: "b"},
+			{Type: "pkgA:m:typA", Name: "b", ID: "id-b"},
+			{Type: "pkgA:m:typB", Name: "b", ID: "id-b2"},
+		},
+	}, "dev", "proj", false)
+	assert.Error(t, err)
+}
+
+func TestParseImportLanguages(t *testing.T) {
+	languages, err := parseImportLanguages("go, TypeScript,nodejs,python")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "python", "typescript"}, languages)
+
+	languages, err = parseImportLanguages("all")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"csharp", "go", "python", "typescript"}, languages)
+
+	_, err = parseImportLanguages("go,cobol")
+	assert.Error(t, err)
+}
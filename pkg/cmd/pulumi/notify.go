@@ -0,0 +1,181 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// notifyURLKey is the stack configuration key that holds the webhook URL used when `--notify-url` is not given.
+var notifyURLKey = config.MustMakeKey("pulumi", "notifyUrl")
+
+// notifyTimeout bounds the time spent delivering a single notification.
+const notifyTimeout = 10 * time.Second
+
+// The lifecycle events of an operation that are sent as notifications.
+const (
+	notifyStarted   = "started"
+	notifySucceeded = "succeeded"
+	notifyFailed    = "failed"
+)
+
+// operationNotification is the JSON payload POSTed to the webhook.
+type operationNotification struct {
+	Event           string                 `json:"event"`
+	Project         string                 `json:"project"`
+	Stack           string                 `json:"stack"`
+	Operation       apitype.UpdateKind     `json:"operation"`
+	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
+	OutputChanges   engine.OutputChanges   `json:"outputChanges,omitempty"`
+	Permalink       string                 `json:"permalink,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+	Timestamp       int64                  `json:"timestamp"`
+}
+
+// operationNotifier POSTs a notification to a webhook when an operation on a stack starts, succeeds or fails.
+// Failing to deliver a notification is reported as a warning, and never fails the operation itself.
+type operationNotifier struct {
+	url          string
+	client       *http.Client
+	stack        backend.Stack
+	notification operationNotification
+	// startTime is when the operation started, in Unix seconds.
+	startTime int64
+}
+
+// newOperationNotifier returns a notifier for the given operation, or nil if no webhook URL is given by url or
+// the stack's configuration.
+func newOperationNotifier(url string, s backend.Stack, proj *workspace.Project, kind apitype.UpdateKind,
+	cfg backend.StackConfiguration) *operationNotifier {
+
+	if url == "" {
+		v, ok := cfg.Config[notifyURLKey]
+		if !ok {
+			return nil
+		}
+		decrypted, err := v.Value(cfg.Decrypter)
+		if err != nil {
+			cmdutil.Diag().Warningf(diag.Message("", "could not read %v: %v"), notifyURLKey, err)
+			return nil
+		}
+		url = decrypted
+	}
+	if url == "" {
+		return nil
+	}
+
+	var permalink string
+	if cs, ok := s.(httpstate.Stack); ok {
+		if u, err := cs.ConsoleURL(); err == nil {
+			permalink = u
+		}
+	}
+
+	return &operationNotifier{
+		url:    url,
+		client: &http.Client{Timeout: notifyTimeout},
+		stack:  s,
+		notification: operationNotification{
+			Project:   string(proj.Name),
+			Stack:     string(s.Ref().Name()),
+			Operation: kind,
+			Permalink: permalink,
+		},
+	}
+}
+
+// started notifies the webhook that the operation has started.
+func (n *operationNotifier) started() {
+	if n == nil {
+		return
+	}
+	n.startTime = time.Now().Unix()
+	n.send(n.notification, notifyStarted)
+}
+
+// finished notifies the webhook that the operation has succeeded or, if res is not nil, failed.
+func (n *operationNotifier) finished(changes engine.ResourceChanges, res result.Result) {
+	if n == nil {
+		return
+	}
+
+	notification := n.notification
+	notification.ResourceChanges = changes
+	event := notifySucceeded
+	if res != nil {
+		event = notifyFailed
+		if err := res.Error(); err != nil {
+			notification.Error = err.Error()
+		}
+	} else if notification.Operation != apitype.PreviewUpdate {
+		notification.OutputChanges = n.outputChanges()
+	}
+	n.send(notification, event)
+}
+
+// outputChanges returns the output changes that the backend recorded for the operation, if it recorded any. The
+// most recent update in the stack's history is only the operation's own if it started after the operation did, which
+// is not the case for operations that are not recorded, such as a destroy with --preview-only.
+func (n *operationNotifier) outputChanges() engine.OutputChanges {
+	updates, err := n.stack.Backend().GetHistory(commandContext(), n.stack.Ref(), 1 /*pageSize*/, 1 /*page*/)
+	if err != nil {
+		logging.V(3).Infof("unable to read the output changes of the operation: %v", err)
+		return nil
+	}
+	if len(updates) == 0 || updates[0].StartTime < n.startTime {
+		return nil
+	}
+	return updates[0].OutputChanges
+}
+
+func (n *operationNotifier) send(notification operationNotification, event string) {
+	notification.Event, notification.Timestamp = event, time.Now().Unix()
+
+	body, err := json.Marshal(notification)
+	contract.AssertNoError(err)
+
+	if err = n.post(body); err != nil {
+		cmdutil.Diag().Warningf(diag.Message("", "could not send the %v notification: %v"), event, err)
+	}
+}
+
+func (n *operationNotifier) post(body []byte) error {
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	contract.IgnoreClose(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("the webhook responded with %v", resp.Status)
+	}
+	return nil
+}
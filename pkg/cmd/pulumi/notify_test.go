@@ -0,0 +1,98 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestOperationNotifier(t *testing.T) {
+	t.Parallel()
+
+	notifications := make(chan operationNotification, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var n operationNotification
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&n))
+		notifications <- n
+	}))
+	defer server.Close()
+
+	outputChanges := engine.OutputChanges{"url": {Kind: engine.OutputAdded, New: "https://example.com"}}
+	s := &backend.MockStack{
+		RefF: func() backend.StackReference { return &mockStackReference{name: "dev"} },
+		BackendF: func() backend.Backend {
+			return &backend.MockBackend{
+				GetHistoryF: func(context.Context, backend.StackReference, int, int) ([]backend.UpdateInfo, error) {
+					return []backend.UpdateInfo{{StartTime: time.Now().Unix(), OutputChanges: outputChanges}}, nil
+				},
+			}
+		},
+	}
+	proj := &workspace.Project{Name: "website"}
+
+	// Without a URL, either from the flag or the stack configuration, there is no notifier.
+	assert.Nil(t, newOperationNotifier("", s, proj, apitype.UpdateUpdate, backend.StackConfiguration{}))
+
+	cfg := backend.StackConfiguration{
+		Config:    config.Map{notifyURLKey: config.NewValue(server.URL)},
+		Decrypter: config.NewPanicCrypter(),
+	}
+	notifier := newOperationNotifier("", s, proj, apitype.UpdateUpdate, cfg)
+	if !assert.NotNil(t, notifier) {
+		return
+	}
+	notifier.started()
+	notifier.finished(engine.ResourceChanges{deploy.OpCreate: 2}, nil)
+	notifier.finished(nil, result.FromError(errors.New("access denied")))
+
+	close(notifications)
+
+	var received []operationNotification
+	for n := range notifications {
+		received = append(received, n)
+	}
+	if !assert.Len(t, received, 3) {
+		return
+	}
+	assert.Equal(t, notifyStarted, received[0].Event)
+	assert.Equal(t, "website", received[0].Project)
+	assert.Equal(t, "dev", received[0].Stack)
+	assert.Equal(t, apitype.UpdateUpdate, received[0].Operation)
+	assert.Nil(t, received[0].ResourceChanges)
+
+	assert.Equal(t, notifySucceeded, received[1].Event)
+	assert.Equal(t, engine.ResourceChanges{deploy.OpCreate: 2}, received[1].ResourceChanges)
+	assert.Equal(t, outputChanges, received[1].OutputChanges)
+
+	assert.Equal(t, notifyFailed, received[2].Event)
+	assert.Equal(t, "access denied", received[2].Error)
+}
@@ -43,8 +43,10 @@ func newPluginCmd() *cobra.Command {
 		Args: cmdutil.NoArgs,
 	}
 
+	cmd.AddCommand(newPluginBundleCmd())
 	cmd.AddCommand(newPluginInstallCmd())
 	cmd.AddCommand(newPluginLsCmd())
+	cmd.AddCommand(newPluginPruneCmd())
 	cmd.AddCommand(newPluginRmCmd())
 
 	return cmd
@@ -0,0 +1,289 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/blang/semver"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/archive"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// pluginBundleManifestFile is the name of the entry that comes first in a plugin bundle and describes its plugins.
+const pluginBundleManifestFile = "bundle.json"
+
+// pluginBundleManifest describes the plugins in a plugin bundle, and the platform that they run on.
+type pluginBundleManifest struct {
+	OS      string                      `json:"os"`
+	Arch    string                      `json:"arch"`
+	Plugins []pluginBundleManifestEntry `json:"plugins"`
+}
+
+// pluginBundleManifestEntry describes a single plugin in a plugin bundle. The plugin's files are stored as a tarball
+// in the bundle entry named File, which has the given SHA-256 checksum.
+type pluginBundleManifestEntry struct {
+	Kind    workspace.PluginKind `json:"kind"`
+	Name    string               `json:"name"`
+	Version string               `json:"version"`
+	File    string               `json:"file"`
+	SHA256  string               `json:"sha256"`
+}
+
+func newPluginBundleCmd() *cobra.Command {
+	var out string
+
+	var cmd = &cobra.Command{
+		Use:   "bundle",
+		Args:  cmdutil.NoArgs,
+		Short: "Package the plugins required by the current project for offline installation",
+		Long: "Package the plugins required by the current project for offline installation.\n" +
+			"\n" +
+			"This command computes the set of plugins that the current project requires, in the\n" +
+			"same way as `pulumi plugin install`, downloads any that are not installed yet, and\n" +
+			"writes them all to a single bundle file. The bundle can then be copied to hosts\n" +
+			"that cannot download plugins, such as air-gapped deployment hosts, and installed\n" +
+			"there with `pulumi plugin install --from-bundle <file>`.\n" +
+			"\n" +
+			"Plugins are platform specific, so the bundle must be created on a host with the\n" +
+			"same operating system and architecture as the hosts that it will be installed on.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			displayOpts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			plugins, err := getProjectPlugins()
+			if err != nil {
+				return err
+			}
+
+			var bundled []workspace.PluginInfo
+			for _, plugin := range plugins {
+				// Language plugins are distributed with the CLI, like `pulumi plugin install` assumes.
+				if plugin.Kind == workspace.LanguagePlugin {
+					continue
+				}
+				if plugin.Version == nil {
+					return fmt.Errorf("cannot bundle %s plugin %s: the project does not require a specific version",
+						plugin.Kind, plugin.Name)
+				}
+
+				label := fmt.Sprintf("[%s plugin %s]", plugin.Kind, plugin)
+				if !workspace.HasPlugin(plugin) {
+					cmdutil.Diag().Infoerrf(diag.Message("", "%s installing"), label)
+					if err := downloadAndInstallPlugin(plugin, label, displayOpts.Color); err != nil {
+						return err
+					}
+				}
+				bundled = append(bundled, plugin)
+			}
+			if len(bundled) == 0 {
+				return errors.New("the project does not require any plugins")
+			}
+
+			f, err := os.Create(out)
+			if err != nil {
+				return err
+			}
+			if err := writePluginBundle(f, bundled); err != nil {
+				contract.IgnoreClose(f)
+				contract.IgnoreError(os.Remove(out))
+				return fmt.Errorf("writing plugin bundle: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			fmt.Printf("Bundled %d plugin(s) into %s\n", len(bundled), out)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(&out,
+		"out", "o", "plugins.tgz", "The file to write the plugin bundle to")
+
+	return cmd
+}
+
+// writePluginBundle writes a bundle of the given installed plugins to w.
+func writePluginBundle(w io.Writer, plugins []workspace.PluginInfo) error {
+	manifest := pluginBundleManifest{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+	// Archive each plugin first, so that the manifest, which comes first, can record their checksums.
+	tarballs := make([][]byte, len(plugins))
+	for i, plugin := range plugins {
+		dir, err := plugin.DirPath()
+		if err != nil {
+			return err
+		}
+		logging.V(5).Infof("bundling %s plugin %s from %s", plugin.Kind, plugin, dir)
+		if tarballs[i], err = archive.TGZ(dir, "", false /*useDefaultExcludes*/); err != nil {
+			return fmt.Errorf("archiving %s plugin %s: %w", plugin.Kind, plugin, err)
+		}
+
+		sum := sha256.Sum256(tarballs[i])
+		manifest.Plugins = append(manifest.Plugins, pluginBundleManifestEntry{
+			Kind:    plugin.Kind,
+			Name:    plugin.Name,
+			Version: plugin.Version.String(),
+			File:    plugin.Dir() + ".tar.gz",
+			SHA256:  hex.EncodeToString(sum[:]),
+		})
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	writeEntry := func(name string, contents []byte) error {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			return err
+		}
+		_, err := tw.Write(contents)
+		return err
+	}
+
+	if err := writeEntry(pluginBundleManifestFile, manifestJSON); err != nil {
+		return err
+	}
+	for i, entry := range manifest.Plugins {
+		if err := writeEntry(entry.File, tarballs[i]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// installPluginBundle installs the plugins in the bundle read from r. Plugins that are already installed are skipped,
+// unless reinstall is true. It returns the plugins that it installed.
+func installPluginBundle(r io.Reader, reinstall bool) ([]workspace.PluginInfo, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin bundle: %w", err)
+	}
+	tr := tar.NewReader(gr)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != pluginBundleManifestFile {
+		return nil, errors.New("not a plugin bundle: it does not start with a manifest")
+	}
+	var manifest pluginBundleManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("reading plugin bundle manifest: %w", err)
+	}
+	if manifest.OS != runtime.GOOS || manifest.Arch != runtime.GOARCH {
+		return nil, fmt.Errorf("the plugin bundle is for %s-%s, but this host is %s-%s",
+			manifest.OS, manifest.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+
+	entries := make(map[string]pluginBundleManifestEntry)
+	for _, entry := range manifest.Plugins {
+		entries[entry.File] = entry
+	}
+
+	var installed []workspace.PluginInfo
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return installed, fmt.Errorf("reading plugin bundle: %w", err)
+		}
+
+		entry, ok := entries[header.Name]
+		if !ok {
+			return installed, fmt.Errorf("plugin bundle contains unexpected file %s", header.Name)
+		}
+		delete(entries, header.Name)
+
+		version, err := semver.ParseTolerant(entry.Version)
+		if err != nil {
+			return installed, fmt.Errorf("invalid version for %s plugin %s: %w", entry.Kind, entry.Name, err)
+		}
+		plugin := workspace.PluginInfo{Kind: entry.Kind, Name: entry.Name, Version: &version}
+		label := fmt.Sprintf("[%s plugin %s]", plugin.Kind, plugin)
+		if !workspace.IsPluginKind(string(entry.Kind)) {
+			return installed, fmt.Errorf("%s has an unrecognized plugin kind", label)
+		}
+
+		if workspace.HasPlugin(plugin) {
+			if !reinstall {
+				logging.V(1).Infof("%s skipping install (existing == match)", label)
+				continue
+			}
+			if err := plugin.Delete(); err != nil {
+				return installed, fmt.Errorf("%s removing existing install: %w", label, err)
+			}
+		}
+
+		// Read the plugin's tarball before installing it, so that it is only installed if it matches the checksum
+		// in the manifest.
+		var tarball bytes.Buffer
+		if _, err := io.Copy(&tarball, tr); err != nil {
+			return installed, fmt.Errorf("reading plugin bundle: %w", err)
+		}
+		sum := sha256.Sum256(tarball.Bytes())
+		if actual := hex.EncodeToString(sum[:]); actual != entry.SHA256 {
+			return installed, fmt.Errorf("%s does not match its checksum in the bundle: expected %s, got %s",
+				label, entry.SHA256, actual)
+		}
+
+		cmdutil.Diag().Infoerrf(diag.Message("", "%s installing from bundle"), label)
+		if err := plugin.Install(ioutil.NopCloser(&tarball)); err != nil {
+			return installed, fmt.Errorf("installing %s: %w", label, err)
+		}
+		installed = append(installed, plugin)
+	}
+
+	if len(entries) > 0 {
+		missing := make([]string, 0, len(entries))
+		for file := range entries {
+			missing = append(missing, file)
+		}
+		sort.Strings(missing)
+		return installed, fmt.Errorf("plugin bundle is missing %v", missing)
+	}
+	return installed, nil
+}
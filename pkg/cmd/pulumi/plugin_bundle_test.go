@@ -0,0 +1,72 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestPluginBundleRoundTrip(t *testing.T) {
+	home, err := ioutil.TempDir("", "pulumi-home")
+	assert.NoError(t, err)
+	defer os.RemoveAll(home)
+	old, had := os.LookupEnv(workspace.PulumiHomeEnvVar)
+	os.Setenv(workspace.PulumiHomeEnvVar, home)
+	defer func() {
+		if had {
+			os.Setenv(workspace.PulumiHomeEnvVar, old)
+		} else {
+			os.Unsetenv(workspace.PulumiHomeEnvVar)
+		}
+	}()
+
+	version := semver.MustParse("1.2.3")
+	plugin := workspace.PluginInfo{Kind: workspace.ResourcePlugin, Name: "test", Version: &version}
+	dir, err := plugin.DirPath()
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(dir, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, plugin.File()), []byte("#!/bin/sh\n"), 0700))
+
+	var bundle bytes.Buffer
+	assert.NoError(t, writePluginBundle(&bundle, []workspace.PluginInfo{plugin}))
+
+	// Plugins that are already installed are skipped.
+	installed, err := installPluginBundle(bytes.NewReader(bundle.Bytes()), false /*reinstall*/)
+	assert.NoError(t, err)
+	assert.Empty(t, installed)
+
+	assert.NoError(t, plugin.Delete())
+	assert.False(t, workspace.HasPlugin(plugin))
+
+	installed, err = installPluginBundle(bytes.NewReader(bundle.Bytes()), false /*reinstall*/)
+	assert.NoError(t, err)
+	assert.Len(t, installed, 1)
+	assert.True(t, workspace.HasPlugin(plugin))
+	b, err := ioutil.ReadFile(filepath.Join(dir, plugin.File()))
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(b))
+
+	_, err = installPluginBundle(bytes.NewReader([]byte("not a bundle")), false /*reinstall*/)
+	assert.Error(t, err)
+}
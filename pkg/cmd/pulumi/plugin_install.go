@@ -17,7 +17,6 @@ package main
 import (
 	"errors"
 	"fmt"
-	"io"
 	"os"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
@@ -28,7 +27,9 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
 
@@ -37,6 +38,7 @@ func newPluginInstallCmd() *cobra.Command {
 	var exact bool
 	var file string
 	var reinstall bool
+	var fromBundle string
 
 	var cmd = &cobra.Command{
 		Use:   "install [KIND NAME VERSION]",
@@ -56,6 +58,24 @@ func newPluginInstallCmd() *cobra.Command {
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
+			if fromBundle != "" {
+				if len(args) > 0 || file != "" {
+					return errors.New("--from-bundle installs every plugin in the bundle, and cannot be used " +
+						"with a specific plugin or --file (-f)")
+				}
+				f, err := os.Open(fromBundle)
+				if err != nil {
+					return err
+				}
+				defer contract.IgnoreClose(f)
+				installed, err := installPluginBundle(f, reinstall)
+				if err != nil {
+					return fmt.Errorf("installing plugins from %s: %w", fromBundle, err)
+				}
+				fmt.Printf("Installed %d plugin(s) from %s\n", len(installed), fromBundle)
+				return nil
+			}
+
 			// Parse the kind, name, and version, if specified.
 			var installs []workspace.PluginInfo
 			if len(args) > 0 {
@@ -96,6 +116,7 @@ func newPluginInstallCmd() *cobra.Command {
 			}
 
 			// Now for each kind, name, version pair, download it from the release website, and install it.
+			var installed []workspace.PluginInfo
 			for _, install := range installs {
 				label := fmt.Sprintf("[%s plugin %s]", install.Kind, install)
 
@@ -119,28 +140,29 @@ func newPluginInstallCmd() *cobra.Command {
 					diag.Message("", "%s installing"), label)
 
 				// If we got here, actually try to do the download.
-				var source string
-				var tarball io.ReadCloser
-				var err error
 				if file == "" {
-					var size int64
-					if tarball, size, err = install.Download(); err != nil {
-						return fmt.Errorf("%s downloading from %s: %w", label, install.ServerURL, err)
-					}
-					tarball = workspace.ReadCloserProgressBar(tarball, size, "Downloading plugin", displayOpts.Color)
-				} else {
-					source = file
-					logging.V(1).Infof("%s opening tarball from %s", label, file)
-					if tarball, err = os.Open(file); err != nil {
-						return fmt.Errorf("opening file %s: %w", source, err)
+					if err := downloadAndInstallPlugin(install, label, displayOpts.Color); err != nil {
+						return err
 					}
+					installed = append(installed, install)
+					continue
+				}
+
+				logging.V(1).Infof("%s opening tarball from %s", label, file)
+				tarball, err := os.Open(file)
+				if err != nil {
+					return fmt.Errorf("opening file %s: %w", file, err)
 				}
 				logging.V(1).Infof("%s installing tarball ...", label)
 				if err = install.Install(tarball); err != nil {
-					return fmt.Errorf("installing %s from %s: %w", label, source, err)
+					return fmt.Errorf("installing %s from %s: %w", label, file, err)
 				}
+				installed = append(installed, install)
 			}
 
+			if len(installed) > 0 {
+				autoPrunePlugins(installed)
+			}
 			return nil
 		}),
 	}
@@ -153,6 +175,24 @@ func newPluginInstallCmd() *cobra.Command {
 		"file", "f", "", "Install a plugin from a tarball file, instead of downloading it")
 	cmd.PersistentFlags().BoolVar(&reinstall,
 		"reinstall", false, "Reinstall a plugin even if it already exists")
+	cmd.PersistentFlags().StringVar(&fromBundle,
+		"from-bundle", "", "Install every plugin in a bundle created by `pulumi plugin bundle`, without downloading")
 
 	return cmd
 }
+
+// downloadAndInstallPlugin downloads the given plugin from its server, showing the progress of the download, and
+// installs it.
+func downloadAndInstallPlugin(install workspace.PluginInfo, label string, color colors.Colorization) error {
+	tarball, size, err := install.Download()
+	if err != nil {
+		return fmt.Errorf("%s downloading from %s: %w", label, install.ServerURL, err)
+	}
+	tarball = workspace.ReadCloserProgressBar(tarball, size, "Downloading plugin", color)
+
+	logging.V(1).Infof("%s installing tarball ...", label)
+	if err = install.Install(tarball); err != nil {
+		return fmt.Errorf("installing %s: %w", label, err)
+	}
+	return nil
+}
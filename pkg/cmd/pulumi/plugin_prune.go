@@ -0,0 +1,189 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func newPluginPruneCmd() *cobra.Command {
+	var keepLatest int
+	var maxSize string
+	var unusedDays int
+	var dryRun bool
+	var yes bool
+	var cmd = &cobra.Command{
+		Use:   "prune",
+		Args:  cmdutil.NoArgs,
+		Short: "Remove old and unused plugins from the download cache",
+		Long: "Remove old and unused plugins from the download cache.\n" +
+			"\n" +
+			"Plugins are removed according to one or more retention policies:\n" +
+			"\n" +
+			"    --keep-latest N    keeps only the newest N versions of each plugin\n" +
+			"    --unused-days N    removes plugins that have not been used for N days\n" +
+			"    --max-size SIZE    removes the least recently used plugins until the cache\n" +
+			"                       is no larger than SIZE (e.g. 10GB)\n" +
+			"\n" +
+			"If no policy is given, the `prune` policy in ~/.pulumi/plugins.yaml is used:\n" +
+			"\n" +
+			"    prune:\n" +
+			"      keepLatest: 2\n" +
+			"      unusedForDays: 90\n" +
+			"      maxSizeMB: 4096\n" +
+			"      auto: true\n" +
+			"\n" +
+			"If `auto` is set, the cache is also pruned with this policy whenever plugins are\n" +
+			"installed, though the plugins that are being installed are never removed.\n" +
+			"\n" +
+			"This removal cannot be undone.  If a removed plugin is subsequently required\n" +
+			"in order to execute a Pulumi program, it will be downloaded again.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			yes = yes || skipConfirmations()
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			policy := workspace.PluginPrunePolicy{KeepLatest: keepLatest, UnusedForDays: unusedDays}
+			if maxSize != "" {
+				size, err := humanize.ParseBytes(maxSize)
+				if err != nil {
+					return fmt.Errorf("invalid --max-size: %w", err)
+				}
+				policy.MaxSizeMB = int64(size / (1024 * 1024))
+				if policy.MaxSizeMB == 0 {
+					return errors.New("--max-size must be at least 1MB")
+				}
+			}
+			if policy.KeepLatest < 0 || policy.UnusedForDays < 0 {
+				return errors.New("--keep-latest and --unused-days must not be negative")
+			}
+			if policy.IsZero() {
+				registries, err := workspace.GetPluginRegistries()
+				if err != nil {
+					return err
+				}
+				if registries.Prune == nil || registries.Prune.IsZero() {
+					return errors.New("no retention policy given; pass --keep-latest, --unused-days, or --max-size, " +
+						"or set a prune policy in ~/.pulumi/plugins.yaml")
+				}
+				policy = *registries.Prune
+			}
+
+			plugins, err := workspace.GetPluginsWithMetadata()
+			if err != nil {
+				return fmt.Errorf("loading plugins: %w", err)
+			}
+			deletes := policy.SelectPluginsToPrune(plugins, nil, time.Now())
+			if len(deletes) == 0 {
+				cmdutil.Diag().Infof(
+					diag.Message("", "no plugins found to prune"))
+				return nil
+			}
+
+			var freed uint64
+			for _, del := range deletes {
+				freed += uint64(del.Size)
+			}
+			var suffix string
+			if len(deletes) != 1 {
+				suffix = "s"
+			}
+			verb := "This will remove"
+			if dryRun {
+				verb = "Would remove"
+			}
+			fmt.Print(
+				opts.Color.Colorize(
+					fmt.Sprintf("%s%s %d plugin%s (%s) from the cache:%s\n",
+						colors.SpecAttention, verb, len(deletes), suffix, humanize.Bytes(freed), colors.Reset)))
+			for _, del := range deletes {
+				fmt.Printf("    %s %s\n", del.Kind, del.String())
+			}
+			if dryRun {
+				return nil
+			}
+
+			if yes || confirmPrompt("", "yes", opts) {
+				var result error
+				for _, plugin := range deletes {
+					if err := plugin.Delete(); err != nil {
+						result = multierror.Append(
+							result, fmt.Errorf("failed to delete %s plugin %s: %w", plugin.Kind, plugin, err))
+					}
+				}
+				if result != nil {
+					return result
+				}
+			}
+
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().IntVar(
+		&keepLatest, "keep-latest", 0,
+		"Keep only the newest N versions of each plugin")
+	cmd.PersistentFlags().StringVar(
+		&maxSize, "max-size", "",
+		"Remove the least recently used plugins until the cache is no larger than this size (e.g. 10GB)")
+	cmd.PersistentFlags().IntVar(
+		&unusedDays, "unused-days", 0,
+		"Remove plugins that have not been used for this many days")
+	cmd.PersistentFlags().BoolVar(
+		&dryRun, "dry-run", false,
+		"Show the plugins that would be removed, without removing them")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false,
+		"Skip confirmation prompts, and proceed with removal anyway")
+
+	return cmd
+}
+
+// autoPrunePlugins prunes the plugin cache after the given plugins have been installed, if the plugin settings ask for
+// it. Failing to prune does not fail the install, so it is only reported as a warning.
+func autoPrunePlugins(installed []workspace.PluginInfo) {
+	removed, err := workspace.AutoPrunePlugins(func(plugin workspace.PluginInfo) bool {
+		for _, install := range installed {
+			if install.Kind == plugin.Kind && install.Name == plugin.Name &&
+				(install.Version == nil || plugin.Version == nil || install.Version.EQ(*plugin.Version)) {
+				return true
+			}
+		}
+		return false
+	})
+	for _, plugin := range removed {
+		logging.V(1).Infof("pruned %s plugin %s", plugin.Kind, plugin)
+	}
+	if len(removed) > 0 {
+		fmt.Printf("Pruned %d plugin(s) from the cache\n", len(removed))
+	}
+	if err != nil {
+		cmdutil.Diag().Warningf(diag.Message("", "failed to prune plugins: %v"), err)
+	}
+}
@@ -0,0 +1,442 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/spf13/cobra"
+
+	resourceanalyzer "github.com/pulumi/pulumi/pkg/v3/resource/analyzer"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// policyTestInput is the set of resources that a policy pack is tested against. Resources are the resources passed to
+// Analyze, with their inputs, and StackResources are those passed to AnalyzeStack, with their outputs.
+type policyTestInput struct {
+	Project        tokens.PackageName
+	Stack          tokens.QName
+	DryRun         bool
+	Resources      []plugin.AnalyzerResource
+	StackResources []plugin.AnalyzerStackResource
+}
+
+// policyViolation is a policy that a resource would violate.
+type policyViolation struct {
+	URN              resource.URN             `json:"urn,omitempty"`
+	PolicyPack       string                   `json:"policyPack"`
+	Policy           string                   `json:"policy"`
+	EnforcementLevel apitype.EnforcementLevel `json:"enforcementLevel"`
+	Message          string                   `json:"message"`
+}
+
+func newPolicyTestCmd() *cobra.Command {
+	var statePath string
+	var eventLogPath string
+	var configPath string
+	var jsonOut bool
+
+	var cmd = &cobra.Command{
+		Use:   "test <pack-path>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Run a local Policy Pack against recorded resources",
+		Long: "Run a local Policy Pack against recorded resources.\n" +
+			"\n" +
+			"This command runs the Policy Pack in the given directory against the resources of a state file\n" +
+			"written by `pulumi stack export`, passed with `--state`, or of an event log written by\n" +
+			"`pulumi preview --event-log` or `pulumi up --event-log`, passed with `--event-log`, and reports\n" +
+			"which resources would violate which policies. No stack or backend is used, so policies can be\n" +
+			"tested in CI against recorded fixtures.\n" +
+			"\n" +
+			"The command fails if any resource violates a mandatory policy.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if (statePath == "") == (eventLogPath == "") {
+				return errors.New("exactly one of --state or --event-log must be specified")
+			}
+
+			var input policyTestInput
+			var err error
+			if statePath != "" {
+				input, err = loadPolicyTestInputFromState(statePath)
+			} else {
+				input, err = loadPolicyTestInputFromEventLog(eventLogPath)
+			}
+			if err != nil {
+				return err
+			}
+
+			violations, err := testPolicyPack(args[0], configPath, input)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				if err := printJSON(violations); err != nil {
+					return err
+				}
+			} else if len(violations) == 0 {
+				fmt.Printf("No policy violations found in %d resource(s)\n", len(input.StackResources))
+			} else {
+				rows := make([]cmdutil.TableRow, len(violations))
+				for i, v := range violations {
+					urn := string(v.URN)
+					if urn == "" {
+						urn = "(stack)"
+					}
+					rows[i] = cmdutil.TableRow{
+						Columns: []string{urn, v.Policy, string(v.EnforcementLevel), v.Message},
+					}
+				}
+				cmdutil.PrintTable(cmdutil.Table{
+					Headers: []string{"RESOURCE", "POLICY", "LEVEL", "MESSAGE"},
+					Rows:    rows,
+				})
+			}
+
+			mandatory := 0
+			for _, v := range violations {
+				if v.EnforcementLevel == apitype.Mandatory {
+					mandatory++
+				}
+			}
+			if mandatory > 0 {
+				return fmt.Errorf("found %d mandatory policy violation(s)", mandatory)
+			}
+			return nil
+		}),
+	}
+
+	cmd.Flags().StringVar(&statePath, "state", "",
+		"The path to a state file written by `pulumi stack export`")
+	cmd.Flags().StringVar(&eventLogPath, "event-log", "",
+		"The path to an event log written by `pulumi preview --event-log` or `pulumi up --event-log`")
+	cmd.Flags().StringVar(&configPath, "config", "",
+		"The path to a JSON file containing the config for the Policy Pack")
+	cmd.Flags().BoolVarP(&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// testPolicyPack loads and configures the policy pack at the given path and runs it against the given resources.
+func testPolicyPack(packPath, configPath string, input policyTestInput) ([]policyViolation, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	sink := cmdutil.Diag()
+	ctx, err := plugin.NewContext(sink, sink, nil, nil, cwd, nil, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(ctx)
+
+	abs, err := filepath.Abs(packPath)
+	if err != nil {
+		return nil, err
+	}
+	a, err := ctx.Host.PolicyAnalyzer(tokens.QName(abs), packPath, &plugin.PolicyAnalyzerOptions{
+		Project: input.Project.String(),
+		Stack:   input.Stack.String(),
+		DryRun:  input.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	} else if a == nil {
+		return nil, fmt.Errorf("policy analyzer could not be loaded from path %q", packPath)
+	}
+
+	if err = configurePolicyPack(a, packPath, configPath); err != nil {
+		return nil, err
+	}
+	return runPolicyTest(a, input)
+}
+
+// configurePolicyPack configures a policy pack with the config file at the given path, if any, as a deployment would.
+func configurePolicyPack(a plugin.Analyzer, packPath, configPath string) error {
+	info, err := a.GetAnalyzerInfo()
+	if err != nil {
+		return err
+	}
+	if !info.SupportsConfig {
+		if configPath != "" {
+			return fmt.Errorf("policy pack %q at %q does not support config", info.Name, packPath)
+		}
+		return nil
+	}
+
+	var configFromFile map[string]plugin.AnalyzerPolicyConfig
+	if configPath != "" {
+		if configFromFile, err = resourceanalyzer.LoadPolicyPackConfigFromFile(configPath); err != nil {
+			return err
+		}
+	}
+	config, validationErrors, err := resourceanalyzer.ReconcilePolicyPackConfig(
+		info.Policies, info.InitialConfig, configFromFile)
+	if err != nil {
+		return fmt.Errorf("reconciling policy config for %q at %q: %w", info.Name, packPath, err)
+	}
+	if len(validationErrors) > 0 {
+		var result error
+		for _, msg := range validationErrors {
+			result = multierror.Append(result, fmt.Errorf("invalid policy config for %q: %s", info.Name, msg))
+		}
+		return result
+	}
+	return a.Configure(config)
+}
+
+// runPolicyTest runs the given analyzer against the resources of the input, returning the violations it reports in
+// the order they were reported. Violations of disabled policies are left out.
+func runPolicyTest(a plugin.Analyzer, input policyTestInput) ([]policyViolation, error) {
+	violations := []policyViolation{}
+	appendDiagnostics := func(urn resource.URN, diags []plugin.AnalyzeDiagnostic) {
+		for _, d := range diags {
+			if d.EnforcementLevel == apitype.Disabled {
+				continue
+			}
+			if d.URN != "" {
+				urn = d.URN
+			}
+			violations = append(violations, policyViolation{
+				URN:              urn,
+				PolicyPack:       d.PolicyPackName,
+				Policy:           d.PolicyName,
+				EnforcementLevel: d.EnforcementLevel,
+				Message:          d.Message,
+			})
+		}
+	}
+
+	for _, r := range input.Resources {
+		diags, err := a.Analyze(r)
+		if err != nil {
+			return nil, fmt.Errorf("analyzing %s: %w", r.URN, err)
+		}
+		appendDiagnostics(r.URN, diags)
+	}
+	diags, err := a.AnalyzeStack(input.StackResources)
+	if err != nil {
+		return nil, fmt.Errorf("analyzing stack: %w", err)
+	}
+	appendDiagnostics("", diags)
+	return violations, nil
+}
+
+// loadPolicyTestInputFromState reads the resources to test from a state file written by `pulumi stack export`.
+func loadPolicyTestInputFromState(path string) (policyTestInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return policyTestInput{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer contract.IgnoreClose(f)
+
+	var deployment apitype.UntypedDeployment
+	if err = json.NewDecoder(f).Decode(&deployment); err != nil {
+		return policyTestInput{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	snap, err := stack.DeserializeUntypedDeployment(&deployment, stack.DefaultSecretsProvider)
+	if err != nil {
+		return policyTestInput{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return policyTestInputFromSnapshot(snap), nil
+}
+
+// policyTestInputFromSnapshot returns the resources of a snapshot to test, leaving out those pending deletion.
+func policyTestInputFromSnapshot(snap *deploy.Snapshot) policyTestInput {
+	var input policyTestInput
+	if snap == nil {
+		return input
+	}
+
+	byURN := map[resource.URN]*resource.State{}
+	for _, res := range snap.Resources {
+		byURN[res.URN] = res
+	}
+	for _, res := range snap.Resources {
+		if res.Delete {
+			continue
+		}
+		if input.Stack == "" {
+			input.Project, input.Stack = res.URN.Project(), res.URN.Stack()
+		}
+
+		var provider *plugin.AnalyzerProviderResource
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			if p, ok := byURN[ref.URN()]; ok {
+				provider = &plugin.AnalyzerProviderResource{
+					URN:        p.URN,
+					Type:       p.Type,
+					Name:       p.URN.Name(),
+					Properties: p.Inputs,
+				}
+			}
+		}
+		options := plugin.AnalyzerResourceOptions{
+			Protect:                 res.Protect,
+			AdditionalSecretOutputs: res.AdditionalSecretOutputs,
+			Aliases:                 res.Aliases,
+			CustomTimeouts:          res.CustomTimeouts,
+		}
+
+		input.Resources = append(input.Resources, plugin.AnalyzerResource{
+			URN:        res.URN,
+			Type:       res.Type,
+			Name:       res.URN.Name(),
+			Properties: res.Inputs,
+			Options:    options,
+			Provider:   provider,
+		})
+		input.StackResources = append(input.StackResources, plugin.AnalyzerStackResource{
+			AnalyzerResource: plugin.AnalyzerResource{
+				URN:        res.URN,
+				Type:       res.Type,
+				Name:       res.URN.Name(),
+				Properties: res.Outputs,
+				Options:    options,
+				Provider:   provider,
+			},
+			Parent:               res.Parent,
+			Dependencies:         res.Dependencies,
+			PropertyDependencies: res.PropertyDependencies,
+		})
+	}
+	return input
+}
+
+// loadPolicyTestInputFromEventLog reads the resources to test from an event log written with `--event-log`.
+func loadPolicyTestInputFromEventLog(path string) (policyTestInput, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return policyTestInput{}, fmt.Errorf("could not open file: %w", err)
+	}
+	defer contract.IgnoreClose(f)
+
+	input, err := policyTestInputFromEvents(f)
+	if err != nil {
+		return policyTestInput{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return input, nil
+}
+
+// policyTestInputFromEvents returns the resources of an event log to test. The inputs of each resource are those of
+// its step, and its outputs are those of its outputs event, if any. Resources that the operation deletes are left
+// out. Secret values are not recorded in event logs, so policies see them as "[secret]".
+func policyTestInputFromEvents(r io.Reader) (policyTestInput, error) {
+	var input policyTestInput
+	var urns []resource.URN
+	states := map[resource.URN]*apitype.StepEventStateMetadata{}
+	outputs := map[resource.URN]map[string]interface{}{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e apitype.EngineEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return policyTestInput{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		switch {
+		case e.ResourcePreEvent != nil:
+			md := e.ResourcePreEvent.Metadata
+			input.DryRun = input.DryRun || e.ResourcePreEvent.Planning
+			switch md.Op {
+			case apitype.OpDelete, apitype.OpDeleteReplaced, apitype.OpReadDiscard, apitype.OpDiscardReplaced,
+				apitype.OpRemovePendingReplace:
+				continue
+			}
+			if md.New == nil {
+				continue
+			}
+			urn := resource.URN(md.New.URN)
+			if _, ok := states[urn]; !ok {
+				urns = append(urns, urn)
+			}
+			states[urn] = md.New
+		case e.ResOutputsEvent != nil:
+			if md := e.ResOutputsEvent.Metadata; md.New != nil {
+				outputs[resource.URN(md.New.URN)] = md.New.Outputs
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return policyTestInput{}, err
+	}
+
+	for _, urn := range urns {
+		state := states[urn]
+		if input.Stack == "" {
+			input.Project, input.Stack = urn.Project(), urn.Stack()
+		}
+
+		var provider *plugin.AnalyzerProviderResource
+		if ref, err := providers.ParseReference(state.Provider); err == nil {
+			if p, ok := states[ref.URN()]; ok {
+				provider = &plugin.AnalyzerProviderResource{
+					URN:        ref.URN(),
+					Type:       tokens.Type(p.Type),
+					Name:       ref.URN().Name(),
+					Properties: resource.NewPropertyMapFromMap(p.Inputs),
+				}
+			}
+		}
+		options := plugin.AnalyzerResourceOptions{Protect: state.Protect}
+
+		outs, ok := outputs[urn]
+		if !ok {
+			outs = state.Outputs
+		}
+		if len(outs) == 0 {
+			outs = state.Inputs
+		}
+
+		input.Resources = append(input.Resources, plugin.AnalyzerResource{
+			URN:        urn,
+			Type:       tokens.Type(state.Type),
+			Name:       urn.Name(),
+			Properties: resource.NewPropertyMapFromMap(state.Inputs),
+			Options:    options,
+			Provider:   provider,
+		})
+		input.StackResources = append(input.StackResources, plugin.AnalyzerStackResource{
+			AnalyzerResource: plugin.AnalyzerResource{
+				URN:        urn,
+				Type:       tokens.Type(state.Type),
+				Name:       urn.Name(),
+				Properties: resource.NewPropertyMapFromMap(outs),
+				Options:    options,
+				Provider:   provider,
+			},
+			Parent: resource.URN(state.Parent),
+		})
+	}
+	return input, nil
+}
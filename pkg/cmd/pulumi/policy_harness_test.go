@@ -0,0 +1,168 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// testAnalyzer is an analyzer that requires every bucket to be private, and the stack to have at most one bucket.
+type testAnalyzer struct{}
+
+func (testAnalyzer) Close() error                                           { return nil }
+func (testAnalyzer) Name() tokens.QName                                     { return "test" }
+func (testAnalyzer) Configure(map[string]plugin.AnalyzerPolicyConfig) error { return nil }
+
+func (testAnalyzer) Analyze(r plugin.AnalyzerResource) ([]plugin.AnalyzeDiagnostic, error) {
+	if r.Type != "aws:s3/bucket:Bucket" || r.Properties["acl"].DeepEquals(resource.NewStringProperty("private")) {
+		return nil, nil
+	}
+	return []plugin.AnalyzeDiagnostic{{
+		PolicyPackName:   "test",
+		PolicyName:       "private-buckets",
+		EnforcementLevel: apitype.Mandatory,
+		Message:          "buckets must be private",
+	}}, nil
+}
+
+func (testAnalyzer) AnalyzeStack(resources []plugin.AnalyzerStackResource) ([]plugin.AnalyzeDiagnostic, error) {
+	var buckets []resource.URN
+	for _, r := range resources {
+		if r.Type == "aws:s3/bucket:Bucket" {
+			buckets = append(buckets, r.URN)
+		}
+	}
+	if len(buckets) <= 1 {
+		return nil, nil
+	}
+	return []plugin.AnalyzeDiagnostic{
+		{
+			PolicyPackName:   "test",
+			PolicyName:       "one-bucket",
+			EnforcementLevel: apitype.Advisory,
+			Message:          "stacks should have at most one bucket",
+		},
+		{
+			PolicyPackName:   "test",
+			PolicyName:       "disabled",
+			EnforcementLevel: apitype.Disabled,
+			URN:              buckets[0],
+		},
+	}, nil
+}
+
+func (testAnalyzer) GetAnalyzerInfo() (plugin.AnalyzerInfo, error) {
+	return plugin.AnalyzerInfo{Name: "test"}, nil
+}
+
+func (testAnalyzer) GetPluginInfo() (workspace.PluginInfo, error) {
+	return workspace.PluginInfo{Name: "test"}, nil
+}
+
+const testBucketURN = "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::public"
+
+func TestPolicyTestInputFromSnapshot(t *testing.T) {
+	provURN := resource.URN("urn:pulumi:dev::proj::pulumi:providers:aws::default")
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{
+				URN:    provURN,
+				Type:   "pulumi:providers:aws",
+				ID:     "provider-id",
+				Inputs: resource.PropertyMap{"region": resource.NewStringProperty("us-west-2")},
+			},
+			{
+				URN:      testBucketURN,
+				Type:     "aws:s3/bucket:Bucket",
+				Provider: string(provURN) + "::provider-id",
+				Inputs:   resource.PropertyMap{"acl": resource.NewStringProperty("public-read")},
+				Outputs:  resource.PropertyMap{"arn": resource.NewStringProperty("arn:aws:s3:::public")},
+			},
+			{
+				URN:    "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::old",
+				Type:   "aws:s3/bucket:Bucket",
+				Delete: true,
+			},
+		},
+	}
+
+	input := policyTestInputFromSnapshot(snap)
+	assert.Equal(t, tokens.PackageName("proj"), input.Project)
+	assert.Equal(t, tokens.QName("dev"), input.Stack)
+	require.Len(t, input.Resources, 2)
+	require.Len(t, input.StackResources, 2)
+
+	bucket := input.Resources[1]
+	assert.Equal(t, "public-read", bucket.Properties["acl"].StringValue())
+	require.NotNil(t, bucket.Provider)
+	assert.Equal(t, provURN, bucket.Provider.URN)
+	assert.Equal(t, "arn:aws:s3:::public", input.StackResources[1].Properties["arn"].StringValue())
+
+	violations, err := runPolicyTest(testAnalyzer{}, input)
+	require.NoError(t, err)
+	assert.Equal(t, []policyViolation{{
+		URN:              testBucketURN,
+		PolicyPack:       "test",
+		Policy:           "private-buckets",
+		EnforcementLevel: apitype.Mandatory,
+		Message:          "buckets must be private",
+	}}, violations)
+}
+
+func TestPolicyTestInputFromEvents(t *testing.T) {
+	log := strings.Join([]string{
+		`{"sequence":0,"preludeEvent":{"config":{}}}`,
+		`{"sequence":1,"resourcePreEvent":{"metadata":{"op":"create","urn":"` + testBucketURN + `",` +
+			`"new":{"type":"aws:s3/bucket:Bucket","urn":"` + testBucketURN + `","inputs":{"acl":"public-read"}}},` +
+			`"planning":true}}`,
+		``,
+		`{"sequence":2,"resourcePreEvent":{"metadata":{"op":"same","urn":"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b",` +
+			`"new":{"type":"aws:s3/bucket:Bucket","urn":"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::b",` +
+			`"inputs":{"acl":"private"},"outputs":{"acl":"private","arn":"arn:aws:s3:::b"}}},"planning":true}}`,
+		`{"sequence":3,"resourcePreEvent":{"metadata":{"op":"delete","urn":"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::c",` +
+			`"old":{"type":"aws:s3/bucket:Bucket","urn":"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::c"}},"planning":true}}`,
+	}, "\n")
+
+	input, err := policyTestInputFromEvents(strings.NewReader(log))
+	require.NoError(t, err)
+	assert.True(t, input.DryRun)
+	assert.Equal(t, tokens.QName("dev"), input.Stack)
+	require.Len(t, input.StackResources, 2)
+	// Without outputs, a resource's inputs stand in for them.
+	assert.Equal(t, "public-read", input.StackResources[0].Properties["acl"].StringValue())
+	assert.Equal(t, "arn:aws:s3:::b", input.StackResources[1].Properties["arn"].StringValue())
+
+	violations, err := runPolicyTest(testAnalyzer{}, input)
+	require.NoError(t, err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, resource.URN(testBucketURN), violations[0].URN)
+	assert.Equal(t, apitype.Mandatory, violations[0].EnforcementLevel)
+	assert.Equal(t, resource.URN(""), violations[1].URN)
+	assert.Equal(t, "one-bucket", violations[1].Policy)
+
+	_, err = policyTestInputFromEvents(strings.NewReader("not json"))
+	assert.Error(t, err)
+}
@@ -17,12 +17,15 @@ package main
 import (
 	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
@@ -37,6 +40,7 @@ func newPreviewCmd() *cobra.Command {
 	var stack string
 	var configArray []string
 	var configPath bool
+	var saveConfigArray bool
 	var client string
 
 	// Flags for engine.UpdateOptions.
@@ -45,6 +49,7 @@ func newPreviewCmd() *cobra.Command {
 	var policyPackConfigPaths []string
 	var diffDisplay bool
 	var eventLogPath string
+	var reports []string
 	var parallel int
 	var refresh string
 	var showConfig bool
@@ -52,11 +57,25 @@ func newPreviewCmd() *cobra.Command {
 	var showSames bool
 	var showReads bool
 	var suppressOutputs bool
+	var ciAnnotations bool
+	var ciFormat string
+	var quiet bool
+	var verbosity int
+	var noTruncate bool
+	var displayWidth int
+	var notifyURL string
+	var eventSinks []string
+	var heartbeatInterval time.Duration
+	var waitForLock time.Duration
+	var costEstimatorURL string
+	var providerVersions []string
 	var suppressPermalink string
 	var targets []string
+	var targetFile string
 	var replaces []string
 	var targetReplaces []string
 	var targetDependents bool
+	var cleanupDefaultProviders bool
 
 	var cmd = &cobra.Command{
 		Use:        "preview",
@@ -91,9 +110,24 @@ func newPreviewCmd() *cobra.Command {
 				IsInteractive:        cmdutil.Interactive(),
 				Type:                 displayType,
 				JSONDisplay:          jsonDisplay,
+				CIAnnotations:        ciAnnotations,
+				EventSinks:           eventSinks,
+				HeartbeatInterval:    heartbeatInterval,
+				NoTruncate:           noTruncate,
+				DisplayWidth:         displayWidth,
+				CostEstimatorURL:     costEstimatorURL,
 				EventLogPath:         eventLogPath,
 				Debug:                debug,
 			}
+			if err := applyReportFlags(reports, &displayOpts); err != nil {
+				return result.FromError(err)
+			}
+			if err := applyCIFormatFlag(ciFormat, &displayOpts); err != nil {
+				return result.FromError(err)
+			}
+			if err := applyVerbosityFlags(quiet, verbosity, &displayOpts); err != nil {
+				return result.FromError(err)
+			}
 
 			// we only suppress permalinks if the user passes true. the default is an empty string
 			// which we pass as 'false'
@@ -122,9 +156,11 @@ func newPreviewCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			// Save any config values passed via flags.
-			if err = parseAndSaveConfigArray(s, configArray, configPath); err != nil {
-				return result.FromError(err)
+			// Save any config values passed via flags, unless they should only apply to this preview.
+			if saveConfigArray {
+				if err = parseAndSaveConfigArray(s, configArray, configPath); err != nil {
+					return result.FromError(err)
+				}
 			}
 
 			proj, root, err := readProjectForUpdate(client)
@@ -132,11 +168,13 @@ func newPreviewCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			m, err := getUpdateMetadata(message, root, execKind, execAgent)
+			m, err := getUpdateMetadata(message, proj, root, execKind, execAgent)
 			if err != nil {
 				return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
 			}
 
+			warnOnToolchainDrift(commandContext(), s, m.Environment)
+
 			sm, err := getStackSecretsManager(s)
 			if err != nil {
 				return result.FromError(fmt.Errorf("getting secrets manager: %w", err))
@@ -146,10 +184,18 @@ func newPreviewCmd() *cobra.Command {
 			if err != nil {
 				return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
 			}
+			if !saveConfigArray {
+				if err = applyEphemeralConfig(&cfg, m, configArray, configPath); err != nil {
+					return result.FromError(err)
+				}
+			}
+			if err = proj.ValidateConfig(cfg.Config, cfg.Decrypter); err != nil {
+				return result.FromError(fmt.Errorf("validating stack configuration: %w", err))
+			}
 
-			targetURNs := []resource.URN{}
-			for _, t := range targets {
-				targetURNs = append(targetURNs, resource.URN(t))
+			targetURNs, err := getTargetURNs(s, targets, targetFile, true)
+			if err != nil {
+				return result.FromError(err)
 			}
 
 			replaceURNs := []resource.URN{}
@@ -167,9 +213,14 @@ func newPreviewCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
+			providerVersionPins, err := parseProviderVersions(providerVersions)
+			if err != nil {
+				return result.FromError(err)
+			}
+
 			opts := backend.UpdateOptions{
 				Engine: engine.UpdateOptions{
-					LocalPolicyPacks:          engine.MakeLocalPolicyPacks(policyPackPaths, policyPackConfigPaths),
+					LocalPolicyPacks:          makeLocalPolicyPacks(proj, root, policyPackPaths, policyPackConfigPaths),
 					Parallel:                  parallel,
 					Debug:                     debug,
 					Refresh:                   refreshOption,
@@ -180,10 +231,15 @@ func newPreviewCmd() *cobra.Command {
 					DisableOutputValues:       disableOutputValues(),
 					UpdateTargets:             targetURNs,
 					TargetDependents:          targetDependents,
+					CleanupDefaultProviders:   cleanupDefaultProviders,
+					ProviderVersions:          providerVersionPins,
 				},
-				Display: displayOpts,
+				Display:     displayOpts,
+				WaitForLock: waitForLock,
 			}
 
+			notifier := newOperationNotifier(notifyURL, s, proj, apitype.PreviewUpdate, cfg)
+			notifier.started()
 			changes, res := s.Preview(commandContext(), backend.UpdateOperation{
 				Proj:               proj,
 				Root:               root,
@@ -193,6 +249,7 @@ func newPreviewCmd() *cobra.Command {
 				SecretsManager:     sm,
 				Scopes:             cancellationScopes,
 			})
+			notifier.finished(changes, res)
 
 			switch {
 			case res != nil:
@@ -223,6 +280,10 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&configPath, "config-path", false,
 		"Config keys contain a path to a property in a map or list to set")
+	cmd.PersistentFlags().BoolVar(
+		&saveConfigArray, "save-config", true,
+		"Save the values passed with --config to the stack's configuration. Pass --save-config=false to "+
+			"apply them to this preview only")
 
 	cmd.PersistentFlags().StringVar(
 		&client, "client", "", "The address of an existing language runtime host to connect to")
@@ -230,12 +291,18 @@ func newPreviewCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
-		"Optional message to associate with the preview operation")
+		"Optional message to associate with the preview operation. May contain placeholders such as "+
+			"{{.GitCommit}}, {{.GitBranch}} and {{.User}} that are expanded from the environment")
 
 	cmd.PersistentFlags().StringArrayVarP(
 		&targets, "target", "t", []string{},
 		"Specify a single resource URN to update. Other resources will not be updated."+
-			" Multiple resources can be specified using --target urn1 --target urn2")
+			" Multiple resources can be specified using --target urn1 --target urn2."+
+			" Use --target output:<name> to target the resources that contribute to a stack output")
+	cmd.PersistentFlags().StringVar(
+		&targetFile, "target-file", "",
+		"Read resource URNs to preview from a file, one per line. Lines starting with # are ignored, and targets "+
+			"containing * or ? are globs that match the URNs of the stack's existing resources")
 	cmd.PersistentFlags().StringArrayVar(
 		&replaces, "replace", []string{},
 		"Specify resources to replace. Multiple resources can be specified using --replace urn1 --replace urn2")
@@ -246,6 +313,9 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&targetDependents, "target-dependents", false,
 		"Allows updating of dependent targets discovered but not specified in --target list")
+	cmd.PersistentFlags().BoolVar(
+		&cleanupDefaultProviders, "cleanup-default-providers", false,
+		"Delete default providers that are no longer referenced by any resource")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -283,17 +353,63 @@ func newPreviewCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
+	cmd.PersistentFlags().BoolVar(
+		&ciAnnotations, "ci-annotations", defaultCIAnnotations(),
+		"Group output by resource and raise annotations for diagnostics when running under a supported CI system; "+
+			"defaults to the value of "+ciAnnotationsEnvVar)
+	cmd.PersistentFlags().StringVar(
+		&ciFormat, "ci-format", "",
+		"Write the log commands of the given CI system instead of the detected one: azdo, github or teamcity")
+	cmd.PersistentFlags().BoolVarP(
+		&quiet, "quiet", "q", false,
+		"Only show diagnostics and the summary, not the progress of each resource")
+	cmd.PersistentFlags().IntVar(
+		&verbosity, "verbosity", 0,
+		"Show more detail about each resource, from 0 to 3: 1 adds replacement steps and reads, 2 adds unchanged "+
+			"resources and 3 adds configuration")
+	cmd.PersistentFlags().BoolVar(
+		&noTruncate, "no-truncate", false,
+		"Wrap lines of the progress display that are too wide for the terminal instead of truncating them")
+	cmd.PersistentFlags().IntVar(
+		&displayWidth, "display-width", 0,
+		"Fit the lines of the progress display to this many columns instead of the width of the terminal")
+	cmd.PersistentFlags().StringVar(
+		&notifyURL, "notify-url", "",
+		"POST a JSON notification to this URL when the preview starts, succeeds or fails; defaults to the "+
+			"pulumi:notifyUrl stack configuration value")
+	cmd.PersistentFlags().StringArrayVar(
+		&eventSinks, "event-sink", nil,
+		"Send the engine events of the operation to the named event sink plugin (pulumi-eventsink-<name>); "+
+			"may be specified more than once")
+	cmd.PersistentFlags().DurationVar(
+		&heartbeatInterval, "heartbeat-interval", defaultHeartbeatInterval,
+		"When the display is not interactive, list the resources still in progress this often (0 to disable)")
+	cmd.PersistentFlags().DurationVar(
+		&waitForLock, "wait-for-lock", 0,
+		"If another update of the stack is in progress, wait up to this long for it to finish (e.g. '10m') "+
+			"instead of failing immediately")
+	cmd.PersistentFlags().StringArrayVar(
+		&providerVersions, "provider-version", nil,
+		"Load this version of a package's provider plugin, as <package>=<version> (e.g. 'aws=4.1.0'), whatever "+
+			"version the program or the stack's state asks for; may be specified more than once")
+	cmd.PersistentFlags().StringVar(
+		&costEstimatorURL, "cost-estimator-url", os.Getenv("PULUMI_COST_ESTIMATOR_URL"),
+		"POST the steps of the preview to this URL and show the per-resource monthly cost estimates it responds "+
+			"with; defaults to the PULUMI_COST_ESTIMATOR_URL environment variable")
 
 	cmd.PersistentFlags().StringVar(
 		&suppressPermalink, "suppress-permalink", "",
 		"Suppress display of the state permalink")
 	cmd.Flag("suppress-permalink").NoOptDefVal = "false"
 
-	if hasDebugCommands() {
-		cmd.PersistentFlags().StringVar(
-			&eventLogPath, "event-log", "",
-			"Log events to a file at this path")
-	}
+	cmd.PersistentFlags().StringArrayVar(
+		&reports, "report", nil,
+		"Write a report of each resource operation to a file, as <format>=<path>. The only supported format "+
+			"is junit, which writes JUnit XML")
+
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log each engine event to a file at this path as a line of JSON, alongside the usual display")
 
 	// internal flags
 	cmd.PersistentFlags().StringVar(&execKind, "exec-kind", "", "")
@@ -0,0 +1,159 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func newProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named stack creation profiles",
+		Long: "Manage named stack creation profiles.\n" +
+			"\n" +
+			"A profile captures the backend and secrets provider that new stacks should use, so that\n" +
+			"stacks can be created consistently with `pulumi stack init --profile <name>`.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newProfileCreateCmd())
+	cmd.AddCommand(newProfileLsCmd())
+	cmd.AddCommand(newProfileRmCmd())
+
+	return cmd
+}
+
+func newProfileCreateCmd() *cobra.Command {
+	var backendURL string
+	var secretsProvider string
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Create or replace a named profile",
+		Long: "Create or replace a named profile.\n" +
+			"\n" +
+			"For example, to create a profile that stores state in S3 and encrypts secrets with AWS KMS:\n" +
+			"\n" +
+			"    $ pulumi profile create team-prod --backend s3://my-state-bucket \\\n" +
+			"        --secrets-provider awskms://alias/ExampleAlias?region=us-east-1",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if backendURL == "" && secretsProvider == "" {
+				return errors.New("at least one of --backend or --secrets-provider must be specified")
+			}
+			if backendURL != "" {
+				if err := validateCloudBackendType(backendURL); err != nil {
+					return err
+				}
+			}
+			if secretsProvider != "" {
+				if err := validateSecretsProvider(secretsProvider); err != nil {
+					return err
+				}
+			}
+
+			return workspace.StoreProfile(args[0], workspace.Profile{
+				Backend:         backendURL,
+				SecretsProvider: secretsProvider,
+			})
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&backendURL, "backend", "", "The backend URL that stacks created with this profile should use")
+	cmd.PersistentFlags().StringVar(
+		&secretsProvider, "secrets-provider", "", possibleSecretsProviderChoices)
+
+	return cmd
+}
+
+func newProfileLsCmd() *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Args:  cmdutil.NoArgs,
+		Short: "List named profiles",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			config, err := workspace.GetPulumiConfig()
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				profiles := config.Profiles
+				if profiles == nil {
+					profiles = map[string]workspace.Profile{}
+				}
+				return printJSON(profiles)
+			}
+
+			names := make([]string, 0, len(config.Profiles))
+			for name := range config.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			rows := []cmdutil.TableRow{}
+			for _, name := range names {
+				profile := config.Profiles[name]
+				backendURL, secretsProvider := profile.Backend, profile.SecretsProvider
+				if backendURL == "" {
+					backendURL = naString
+				}
+				if secretsProvider == "" {
+					secretsProvider = naString
+				}
+				rows = append(rows, cmdutil.TableRow{Columns: []string{name, backendURL, secretsProvider}})
+			}
+
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"NAME", "BACKEND", "SECRETS PROVIDER"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+func newProfileRmCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm <name>",
+		Args:  cmdutil.ExactArgs(1),
+		Short: "Remove a named profile",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if err := workspace.DeleteProfile(args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("Profile '%s' has been removed\n", args[0])
+			return nil
+		}),
+	}
+
+	return cmd
+}
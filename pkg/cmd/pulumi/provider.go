@@ -0,0 +1,37 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func newProviderCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "provider",
+		Short: "Inspect the resource providers of a stack",
+		Long: "Inspect the resource providers of a stack.\n" +
+			"\n" +
+			"The provider family of commands works with the providers that a stack's resources are\n" +
+			"managed by, using the stack's state and configuration.",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newProviderCheckCmd())
+
+	return cmd
+}
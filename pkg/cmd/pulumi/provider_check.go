@@ -0,0 +1,351 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// providerHealthCheckFunction is the provider function that `pulumi provider check` invokes to find the endpoints
+// that a configured provider talks to, and whether each of them can be reached. It returns an "endpoints" list of
+// objects with "url", "reachable" and, for endpoints that can't be reached, "error" properties. Providers that don't
+// implement the function are checked without it.
+const providerHealthCheckFunction tokens.ModuleMember = "pulumi:providers:healthCheck"
+
+// The statuses that `pulumi provider check` reports for a provider.
+const (
+	providerStatusOK            = "ok"
+	providerStatusNotLoaded     = "failed to load"
+	providerStatusInvalidConfig = "invalid configuration"
+	providerStatusNotConfigured = "failed to configure"
+	providerStatusUnreachable   = "endpoints unreachable"
+)
+
+// providerEndpoint is an endpoint that a provider reported it talks to.
+type providerEndpoint struct {
+	URL       string `json:"url"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// providerCheckResult is the outcome of checking one of a stack's providers.
+type providerCheckResult struct {
+	URN       resource.URN       `json:"urn"`
+	Package   tokens.Package     `json:"package"`
+	Version   string             `json:"version,omitempty"`
+	Status    string             `json:"status"`
+	Error     string             `json:"error,omitempty"`
+	Endpoints []providerEndpoint `json:"endpoints,omitempty"`
+}
+
+// providerToCheck is a provider of a stack, along with the configuration that it would be given by an update.
+type providerToCheck struct {
+	URN     resource.URN
+	Package tokens.Package
+	Version *semver.Version
+	Inputs  resource.PropertyMap
+}
+
+func newProviderCheckCmd() *cobra.Command {
+	var stack string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check that a stack's providers can be loaded and configured",
+		Long: "Check that a stack's providers can be loaded and configured\n" +
+			"\n" +
+			"This command loads each provider of the stack, both those in its state and the default\n" +
+			"providers of the packages that its configuration sets, and configures it as an update\n" +
+			"would. For each provider, it reports the plugin version, whether its configuration is\n" +
+			"valid and its credentials are accepted, and, for providers that support it, whether the\n" +
+			"endpoints that it talks to can be reached. This is a quick way to find out why an update\n" +
+			"fails before it changes anything.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			proj, _, err := readProject()
+			if err != nil {
+				return result.FromError(err)
+			}
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return result.FromError(err)
+			}
+			sm, err := getStackSecretsManager(s)
+			if err != nil {
+				return result.FromError(err)
+			}
+			cfg, err := getStackConfiguration(s, sm)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			target := &deploy.Target{
+				Name:      s.Ref().Name(),
+				Config:    cfg.Config,
+				Decrypter: cfg.Decrypter,
+				Snapshot:  snap,
+			}
+			toCheck, err := stackProviders(target, proj.Name)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if len(toCheck) == 0 {
+				if jsonOut {
+					return result.FromError(printJSON([]providerCheckResult{}))
+				}
+				fmt.Println("This stack has no providers to check")
+				return nil
+			}
+
+			cwd, err := os.Getwd()
+			if err != nil {
+				return result.FromError(err)
+			}
+			sink := cmdutil.Diag()
+			ctx, err := plugin.NewContext(sink, sink, nil, nil, cwd, nil, true, nil)
+			if err != nil {
+				return result.FromError(err)
+			}
+			defer contract.IgnoreClose(ctx)
+
+			var results []providerCheckResult
+			failed := 0
+			for _, p := range toCheck {
+				r := checkProvider(ctx.Host, p)
+				if r.Status != providerStatusOK {
+					failed++
+				}
+				results = append(results, r)
+			}
+
+			if jsonOut {
+				if err := printJSON(results); err != nil {
+					return result.FromError(err)
+				}
+			} else {
+				printProviderCheckResults(results)
+			}
+			if failed > 0 {
+				return result.Errorf("%d of %d providers failed their checks", failed, len(results))
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// stackProviders returns the providers that an update of the target would configure: the provider resources in its
+// snapshot, and a default provider for each package that its configuration sets, or whose default provider settings it
+// sets, and that has no default provider in the snapshot. Default providers are given the target's current
+// configuration, rather than the configuration that they were last updated with.
+func stackProviders(target *deploy.Target, project tokens.PackageName) ([]providerToCheck, error) {
+	var result []providerToCheck
+	hasDefault := make(map[tokens.Package]bool)
+	if target.Snapshot != nil {
+		for _, res := range target.Snapshot.Resources {
+			if res.Delete || !providers.IsProviderType(res.Type) {
+				continue
+			}
+			pkg := providers.GetProviderPackage(res.Type)
+			version, err := providers.GetProviderVersion(res.Inputs)
+			if err != nil {
+				return nil, fmt.Errorf("provider %v: %w", res.URN, err)
+			}
+
+			inputs := res.Inputs
+			if providers.IsDefaultProvider(res.URN) {
+				hasDefault[pkg] = true
+				if inputs, err = target.GetPackageConfig(pkg); err != nil {
+					return nil, err
+				}
+				if v, ok := res.Inputs["version"]; ok {
+					inputs["version"] = v
+				}
+			}
+			result = append(result, providerToCheck{URN: res.URN, Package: pkg, Version: version, Inputs: inputs})
+		}
+	}
+
+	// Configuration in the project's own namespace, and the engine's, is not provider configuration, other than the
+	// default provider settings.
+	packages := make(map[tokens.Package]bool)
+	for k := range target.Config {
+		pkg := tokens.Package(k.Namespace())
+		if pkg != tokens.Package(project) && pkg != "pulumi" && !hasDefault[pkg] {
+			packages[pkg] = true
+		}
+	}
+	configured, err := target.GetDefaultProviderPackages()
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range configured {
+		if !hasDefault[pkg] {
+			packages[pkg] = true
+		}
+	}
+	sorted := make([]string, 0, len(packages))
+	for pkg := range packages {
+		sorted = append(sorted, string(pkg))
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		pkg := tokens.Package(name)
+		inputs, err := target.GetPackageConfig(pkg)
+		if err != nil {
+			return nil, err
+		}
+		urn := resource.NewURN(target.Name, project, "", providers.MakeProviderType(pkg), "default")
+		result = append(result, providerToCheck{URN: urn, Package: pkg, Inputs: inputs})
+	}
+	return result, nil
+}
+
+// checkProvider loads the given provider and configures it, reporting how far it got.
+func checkProvider(host plugin.Host, p providerToCheck) providerCheckResult {
+	r := providerCheckResult{URN: p.URN, Package: p.Package}
+	if p.Version != nil {
+		r.Version = p.Version.String()
+	}
+
+	prov, err := host.Provider(p.Package, p.Version)
+	if err == nil && prov == nil {
+		err = fmt.Errorf("no resource plugin '%s' found", p.Package)
+	}
+	if err != nil {
+		r.Status, r.Error = providerStatusNotLoaded, err.Error()
+		return r
+	}
+	defer contract.IgnoreError(host.CloseProvider(prov))
+
+	if info, err := prov.GetPluginInfo(); err == nil && info.Version != nil {
+		r.Version = info.Version.String()
+	}
+
+	inputs, failures, err := prov.CheckConfig(p.URN, p.Inputs, p.Inputs, false /*allowUnknowns*/)
+	if err == nil && len(failures) > 0 {
+		reasons := make([]string, len(failures))
+		for i, f := range failures {
+			reasons[i] = f.Reason
+			if f.Property != "" {
+				reasons[i] = fmt.Sprintf("%s: %s", f.Property, f.Reason)
+			}
+		}
+		err = fmt.Errorf("%s", strings.Join(reasons, "; "))
+	}
+	if err != nil {
+		r.Status, r.Error = providerStatusInvalidConfig, err.Error()
+		return r
+	}
+
+	if err := prov.Configure(inputs); err != nil {
+		r.Status, r.Error = providerStatusNotConfigured, err.Error()
+		return r
+	}
+
+	r.Status = providerStatusOK
+	ret, _, err := prov.Invoke(providerHealthCheckFunction, resource.PropertyMap{})
+	if err != nil {
+		// The provider doesn't report its endpoints.
+		return r
+	}
+	if endpoints, ok := ret["endpoints"]; ok && endpoints.IsArray() {
+		for _, v := range endpoints.ArrayValue() {
+			if !v.IsObject() {
+				continue
+			}
+			obj := v.ObjectValue()
+			var e providerEndpoint
+			if url := obj["url"]; url.IsString() {
+				e.URL = url.StringValue()
+			}
+			if reachable := obj["reachable"]; reachable.IsBool() {
+				e.Reachable = reachable.BoolValue()
+			}
+			if msg := obj["error"]; msg.IsString() {
+				e.Error = msg.StringValue()
+			}
+			if !e.Reachable {
+				r.Status = providerStatusUnreachable
+			}
+			r.Endpoints = append(r.Endpoints, e)
+		}
+	}
+	return r
+}
+
+func printProviderCheckResults(results []providerCheckResult) {
+	rows := make([]cmdutil.TableRow, len(results))
+	for i, r := range results {
+		version := r.Version
+		if version == "" {
+			version = "n/a"
+		}
+		rows[i] = cmdutil.TableRow{Columns: []string{string(r.URN.Name()), string(r.Package), version, r.Status}}
+	}
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"NAME", "PACKAGE", "VERSION", "STATUS"},
+		Rows:    rows,
+	})
+
+	for _, r := range results {
+		if r.Error == "" && len(r.Endpoints) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", r.URN)
+		if r.Error != "" {
+			fmt.Printf("    error: %s\n", r.Error)
+		}
+		for _, e := range r.Endpoints {
+			if e.Reachable {
+				fmt.Printf("    %s: reachable\n", e.URL)
+			} else {
+				fmt.Printf("    %s: unreachable: %s\n", e.URL, e.Error)
+			}
+		}
+	}
+}
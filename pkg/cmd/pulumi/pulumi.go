@@ -38,6 +38,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
 	"github.com/pulumi/pulumi/pkg/v3/backend/httpstate"
@@ -61,6 +62,8 @@ func NewPulumiCmd() *cobra.Command {
 	var tracingHeaderFlag string
 	var profiling string
 	var verbose int
+	var logTail string
+	var logTailFile *os.File
 	var color string
 
 	updateCheckResult := make(chan *diag.Diag)
@@ -109,13 +112,35 @@ func NewPulumiCmd() *cobra.Command {
 				}
 			}
 
+			// Apply the user's color theme, if any, from ~/.pulumi/display.yaml or PULUMI_DISPLAY_THEME.
+			displaySettingsPath, err := workspace.GetPulumiPath("display.yaml")
+			if err != nil {
+				return err
+			}
+			if err := cmdutil.LoadDisplayTheme(displaySettingsPath); err != nil {
+				return err
+			}
+
 			if cwd != "" {
 				if err := os.Chdir(cwd); err != nil {
 					return err
 				}
 			}
 
+			if cmdutil.IsTruthy(os.Getenv("PULUMI_READ_ONLY")) {
+				backend.ReadOnly = true
+			}
+
 			logging.InitLogging(logToStderr, verbose, logFlow)
+			if logTail != "" {
+				f, err := openLogTail(logTail)
+				if err != nil {
+					return err
+				}
+				logTailFile = f
+			}
+			watchVerbositySignals()
+			backend.RegisterOpener(openReferencedBackend)
 			cmdutil.InitTracing("pulumi-cli", "pulumi", tracing)
 			if tracingHeaderFlag != "" {
 				tracingHeader = tracingHeaderFlag
@@ -152,6 +177,10 @@ func NewPulumiCmd() *cobra.Command {
 			}
 
 			logging.Flush()
+			if logTailFile != nil {
+				logging.SetTail(nil)
+				contract.IgnoreClose(logTailFile)
+			}
 			cmdutil.CloseTracing()
 
 			if profiling != "" {
@@ -168,6 +197,9 @@ func NewPulumiCmd() *cobra.Command {
 		"Enable emojis in the output")
 	cmd.PersistentFlags().BoolVar(&filestate.DisableIntegrityChecking, "disable-integrity-checking", false,
 		"Disable integrity checking of checkpoint files")
+	cmd.PersistentFlags().BoolVar(&backend.ReadOnly, "read-only", false,
+		"Refuse to run any operation that would change a stack's state or resources, so that the CLI can safely "+
+			"be used to inspect production stacks. Setting PULUMI_READ_ONLY has the same effect")
 	cmd.PersistentFlags().BoolVar(&logFlow, "logflow", false,
 		"Flow log settings to child processes (like plugins)")
 	cmd.PersistentFlags().BoolVar(&logToStderr, "logtostderr", false,
@@ -179,9 +211,15 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&profiling, "profiling", "",
 		"Emit CPU and memory profiles and an execution trace to '[filename].[pid].{cpu,mem,trace}', respectively")
 	cmd.PersistentFlags().IntVarP(&verbose, "verbose", "v", 0,
-		"Enable verbose logging (e.g., v=3); anything >3 is very verbose")
+		"Enable verbose logging (e.g., v=3); anything >3 is very verbose. On Unix systems, sending the process "+
+			"SIGUSR1 raises the level while it runs and SIGUSR2 restores it")
+	cmd.PersistentFlags().StringVar(&logTail, "log-tail", "",
+		"Also write the log to the given `file` as it is produced, so that it can be followed while a command runs")
 	cmd.PersistentFlags().StringVar(
 		&color, "color", "auto", "Colorize output. Choices are: always, never, raw, auto")
+	cmd.PersistentFlags().BoolVar(&redactSecrets, "redact-secrets", false,
+		"Replace secret values in JSON output with stable hashes, so that it can be compared across runs "+
+			"without exposing secrets")
 
 	// Common commands:
 	//     - Getting Started Commands:
@@ -194,6 +232,7 @@ func NewPulumiCmd() *cobra.Command {
 	//     - Stack Management Commands:
 	cmd.AddCommand(newStackCmd())
 	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newProfileCmd())
 	//     - Service Commands:
 	cmd.AddCommand(newLoginCmd())
 	cmd.AddCommand(newLogoutCmd())
@@ -202,8 +241,13 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.AddCommand(newPolicyCmd())
 	//     - Advanced Commands:
 	cmd.AddCommand(newCancelCmd())
+	cmd.AddCommand(newConvertCmd())
+	cmd.AddCommand(newDriftCmd())
 	cmd.AddCommand(newImportCmd())
+	cmd.AddCommand(newProviderCmd())
 	cmd.AddCommand(newRefreshCmd())
+	cmd.AddCommand(newReplayCmd())
+	cmd.AddCommand(newServeCmd())
 	cmd.AddCommand(newStateCmd())
 	//     - Other Commands:
 	cmd.AddCommand(newLogsCmd())
@@ -211,6 +255,7 @@ func NewPulumiCmd() *cobra.Command {
 	cmd.AddCommand(newVersionCmd())
 	cmd.AddCommand(newConsoleCmd())
 	cmd.AddCommand(newAboutCmd())
+	cmd.AddCommand(newDoctorCmd())
 	cmd.AddCommand(newSchemaCmd())
 	cmd.AddCommand(newOrgCmd())
 
@@ -235,6 +280,9 @@ func NewPulumiCmd() *cobra.Command {
 		contract.IgnoreError(err)
 	}
 
+	// Complete stack names and resource URNs from the backend.
+	registerDynamicCompletions(cmd)
+
 	return cmd
 }
 
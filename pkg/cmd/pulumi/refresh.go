@@ -18,12 +18,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
@@ -47,9 +49,24 @@ func newRefreshCmd() *cobra.Command {
 	var showSames bool
 	var skipPreview bool
 	var suppressOutputs bool
+	var ciAnnotations bool
+	var ciFormat string
+	var quiet bool
+	var verbosity int
+	var noTruncate bool
+	var displayWidth int
+	var notifyURL string
+	var eventSinks []string
+	var heartbeatInterval time.Duration
+	var waitForLock time.Duration
+	var profileResources bool
+	var slowStepThreshold time.Duration
 	var suppressPermalink string
 	var yes bool
 	var targets *[]string
+	var targetDependents bool
+	var excludeProtected bool
+	var ignoreChanges []string
 
 	var cmd = &cobra.Command{
 		Use:   "refresh",
@@ -92,7 +109,21 @@ func newRefreshCmd() *cobra.Command {
 				EventLogPath:         eventLogPath,
 				Debug:                debug,
 				JSONDisplay:          jsonDisplay,
+				CIAnnotations:        ciAnnotations,
+				EventSinks:           eventSinks,
+				HeartbeatInterval:    heartbeatInterval,
+				NoTruncate:           noTruncate,
+				DisplayWidth:         displayWidth,
+				ProfileResources:     profileResources,
+				SlowStepThreshold:    slowStepThreshold,
 			}
+			if err := applyCIFormatFlag(ciFormat, &opts.Display); err != nil {
+				return result.FromError(err)
+			}
+			if err := applyVerbosityFlags(quiet, verbosity, &opts.Display); err != nil {
+				return result.FromError(err)
+			}
+			opts.WaitForLock = waitForLock
 
 			// we only suppress permalinks if the user passes true. the default is an empty string
 			// which we pass as 'false'
@@ -123,7 +154,7 @@ func newRefreshCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			m, err := getUpdateMetadata(message, root, execKind, execAgent)
+			m, err := getUpdateMetadata(message, proj, root, execKind, execAgent)
 			if err != nil {
 				return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
 			}
@@ -143,6 +174,18 @@ func newRefreshCmd() *cobra.Command {
 				targetUrns = append(targetUrns, resource.URN(t))
 			}
 
+			refreshIgnoreChanges, err := getRefreshIgnoreChanges(s, ignoreChanges)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			var excludeURNs []resource.URN
+			if excludeProtected {
+				if excludeURNs, err = excludeProtectedResources(s, "refresh"); err != nil {
+					return result.FromError(err)
+				}
+			}
+
 			opts.Engine = engine.UpdateOptions{
 				Parallel:                  parallel,
 				Debug:                     debug,
@@ -151,8 +194,13 @@ func newRefreshCmd() *cobra.Command {
 				DisableResourceReferences: disableResourceReferences(),
 				DisableOutputValues:       disableOutputValues(),
 				RefreshTargets:            targetUrns,
+				TargetDependents:          targetDependents,
+				ExcludeTargets:            excludeURNs,
+				RefreshIgnoreChanges:      refreshIgnoreChanges,
 			}
 
+			notifier := newOperationNotifier(notifyURL, s, proj, apitype.RefreshUpdate, cfg)
+			notifier.started()
 			changes, res := s.Refresh(commandContext(), backend.UpdateOperation{
 				Proj:               proj,
 				Root:               root,
@@ -162,6 +210,7 @@ func newRefreshCmd() *cobra.Command {
 				SecretsManager:     sm,
 				Scopes:             cancellationScopes,
 			})
+			notifier.finished(changes, res)
 
 			switch {
 			case res != nil && res.Error() == context.Canceled:
@@ -191,11 +240,22 @@ func newRefreshCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
-		"Optional message to associate with the update operation")
+		"Optional message to associate with the update operation. May contain placeholders such as "+
+			"{{.GitCommit}}, {{.GitBranch}} and {{.User}} that are expanded from the environment")
 
 	targets = cmd.PersistentFlags().StringArrayP(
 		"target", "t", []string{},
 		"Specify a single resource URN to refresh. Multiple resource can be specified using: --target urn1 --target urn2")
+	cmd.PersistentFlags().BoolVar(
+		&targetDependents, "target-dependents", false,
+		"Also refresh the resources that depend on the resources specified in the --target list")
+	cmd.PersistentFlags().BoolVar(
+		&excludeProtected, "exclude-protected", false,
+		"Do not refresh protected resources or the resources they depend on")
+	cmd.PersistentFlags().StringArrayVar(
+		&ignoreChanges, "ignore", []string{},
+		"Do not adopt changes to the given property path, e.g. `tags.lastModified`. Multiple paths can be specified "+
+			"using: --ignore path1 --ignore path2")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().BoolVar(
@@ -219,6 +279,47 @@ func newRefreshCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
+	cmd.PersistentFlags().BoolVar(
+		&ciAnnotations, "ci-annotations", defaultCIAnnotations(),
+		"Group output by resource and raise annotations for diagnostics when running under a supported CI system; "+
+			"defaults to the value of "+ciAnnotationsEnvVar)
+	cmd.PersistentFlags().StringVar(
+		&ciFormat, "ci-format", "",
+		"Write the log commands of the given CI system instead of the detected one: azdo, github or teamcity")
+	cmd.PersistentFlags().BoolVarP(
+		&quiet, "quiet", "q", false,
+		"Only show diagnostics and the summary, not the progress of each resource")
+	cmd.PersistentFlags().IntVar(
+		&verbosity, "verbosity", 0,
+		"Show more detail about each resource, from 0 to 3: 1 adds replacement steps and reads, 2 adds unchanged "+
+			"resources and 3 adds configuration")
+	cmd.PersistentFlags().BoolVar(
+		&noTruncate, "no-truncate", false,
+		"Wrap lines of the progress display that are too wide for the terminal instead of truncating them")
+	cmd.PersistentFlags().IntVar(
+		&displayWidth, "display-width", 0,
+		"Fit the lines of the progress display to this many columns instead of the width of the terminal")
+	cmd.PersistentFlags().StringVar(
+		&notifyURL, "notify-url", "",
+		"POST a JSON notification to this URL when the refresh starts, succeeds or fails; defaults to the "+
+			"pulumi:notifyUrl stack configuration value")
+	cmd.PersistentFlags().StringArrayVar(
+		&eventSinks, "event-sink", nil,
+		"Send the engine events of the operation to the named event sink plugin (pulumi-eventsink-<name>); "+
+			"may be specified more than once")
+	cmd.PersistentFlags().DurationVar(
+		&heartbeatInterval, "heartbeat-interval", defaultHeartbeatInterval,
+		"When the display is not interactive, list the resources still in progress this often (0 to disable)")
+	cmd.PersistentFlags().DurationVar(
+		&waitForLock, "wait-for-lock", 0,
+		"If another update of the stack is in progress, wait up to this long for it to finish (e.g. '10m') "+
+			"instead of failing immediately")
+	cmd.PersistentFlags().BoolVar(
+		&profileResources, "profile-resources", false,
+		"Show how long the slowest resource operations of the refresh took once it completes")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-operation-threshold", defaultSlowStepThreshold,
+		"Flag resource operations that take longer than this in the interactive display (0 to disable)")
 	cmd.PersistentFlags().StringVar(
 		&suppressPermalink, "suppress-permalink", "",
 		"Suppress display of the state permalink")
@@ -227,11 +328,9 @@ func newRefreshCmd() *cobra.Command {
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the refresh after previewing it")
 
-	if hasDebugCommands() {
-		cmd.PersistentFlags().StringVar(
-			&eventLogPath, "event-log", "",
-			"Log events to a file at this path")
-	}
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log each engine event to a file at this path as a line of JSON, alongside the usual display")
 
 	// internal flags
 	cmd.PersistentFlags().StringVar(&execKind, "exec-kind", "", "")
@@ -243,3 +342,20 @@ func newRefreshCmd() *cobra.Command {
 
 	return cmd
 }
+
+// getRefreshIgnoreChanges combines the property paths passed to --ignore with those in the stack's
+// refreshIgnoreChanges setting, checking that each of them is a valid property path.
+func getRefreshIgnoreChanges(s backend.Stack, flagPaths []string) ([]string, error) {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return nil, fmt.Errorf("loading stack settings: %w", err)
+	}
+
+	paths := append(append([]string{}, ps.RefreshIgnoreChanges...), flagPaths...)
+	for _, p := range paths {
+		if _, err := resource.ParsePropertyPath(p); err != nil {
+			return nil, fmt.Errorf("invalid property path %q: %w", p, err)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+func newReplayCmd() *cobra.Command {
+	var diffDisplay bool
+	var jsonDisplay bool
+	var showConfig bool
+	var showReplacementSteps bool
+	var showSames bool
+	var showReads bool
+	var suppressOutputs bool
+
+	var cmd = &cobra.Command{
+		Use:   "replay <event-log>",
+		Short: "Show the events of an event log as they were displayed",
+		Long: "Show the events of an event log as they were displayed.\n" +
+			"\n" +
+			"This command renders an event log written by the `--event-log` flag of `pulumi up`,\n" +
+			"`preview`, `destroy` or `refresh` through any of the displays, so that an operation\n" +
+			"that ran elsewhere, e.g. in CI, can be reviewed as though it had run locally.",
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer contract.IgnoreClose(f)
+
+			events, err := readEventLog(f)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", args[0], err)
+			}
+
+			displayType := display.DisplayProgress
+			if diffDisplay {
+				displayType = display.DisplayDiff
+			}
+			return replayEvents(events, display.Options{
+				Color:                cmdutil.GetGlobalColorization(),
+				ShowConfig:           showConfig,
+				ShowReplacementSteps: showReplacementSteps,
+				ShowSameResources:    showSames,
+				ShowReads:            showReads,
+				SuppressOutputs:      suppressOutputs,
+				SuppressPermalink:    true,
+				IsInteractive:        cmdutil.Interactive(),
+				Type:                 displayType,
+				JSONDisplay:          jsonDisplay,
+			})
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVar(
+		&diffDisplay, "diff", false,
+		"Display operation as a rich diff showing the overall change")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonDisplay, "json", "j", false,
+		"Serialize the events as JSON")
+	cmd.PersistentFlags().BoolVar(
+		&showConfig, "show-config", false,
+		"Show configuration keys and variables")
+	cmd.PersistentFlags().BoolVar(
+		&showReplacementSteps, "show-replacement-steps", false,
+		"Show detailed resource replacement creates and deletes instead of a single step")
+	cmd.PersistentFlags().BoolVar(
+		&showSames, "show-sames", false,
+		"Show resources that don't need to be updated because they haven't changed, alongside those that do")
+	cmd.PersistentFlags().BoolVar(
+		&showReads, "show-reads", false,
+		"Show resources that are being read in, alongside those being managed directly in the stack")
+	cmd.PersistentFlags().BoolVar(
+		&suppressOutputs, "suppress-outputs", false,
+		"Suppress display of stack outputs (in case they contain sensitive values)")
+
+	return cmd
+}
+
+// readEventLog reads the events of an event log, which contains one JSON-encoded apitype.EngineEvent per line.
+func readEventLog(r io.Reader) ([]apitype.EngineEvent, error) {
+	var events []apitype.EngineEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e apitype.EngineEvent
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// replayInfo infers what an event log records, which its events don't say directly: the kind of operation, the stack
+// and project it ran against, and whether it was a preview.
+func replayInfo(events []apitype.EngineEvent) (apitype.UpdateKind, tokens.QName, tokens.PackageName, bool) {
+	var stack tokens.QName
+	var proj tokens.PackageName
+	isPreview, refreshOnly, sawStep := false, true, false
+	for _, e := range events {
+		var md apitype.StepEventMetadata
+		switch {
+		case e.ResourcePreEvent != nil:
+			md = e.ResourcePreEvent.Metadata
+			isPreview = isPreview || e.ResourcePreEvent.Planning
+		case e.ResOutputsEvent != nil:
+			md = e.ResOutputsEvent.Metadata
+		default:
+			continue
+		}
+		if urn := resource.URN(md.URN); stack == "" && urn.IsValid() {
+			stack, proj = urn.Stack(), urn.Project()
+		}
+		if md.Op != apitype.OpSame {
+			sawStep = true
+			refreshOnly = refreshOnly && md.Op == apitype.OpRefresh
+		}
+	}
+
+	action := apitype.UpdateUpdate
+	switch {
+	case sawStep && refreshOnly:
+		action = apitype.RefreshUpdate
+	case isPreview:
+		action = apitype.PreviewUpdate
+	}
+	return action, stack, proj, isPreview
+}
+
+// replayEvents shows the given events through the display.
+func replayEvents(apiEvents []apitype.EngineEvent, opts display.Options) error {
+	action, stack, proj, isPreview := replayInfo(apiEvents)
+
+	var events []engine.Event
+	for _, e := range apiEvents {
+		event, err := display.ConvertJSONEvent(e, isPreview)
+		if err != nil {
+			return fmt.Errorf("event %d: %w", e.Sequence, err)
+		}
+		events = append(events, event)
+		if event.Type == engine.CancelEvent {
+			break
+		}
+	}
+	// The displays finish once they see a cancel event, which a log cut short may be missing.
+	if len(events) == 0 || events[len(events)-1].Type != engine.CancelEvent {
+		events = append(events, engine.NewEvent(engine.CancelEvent, nil))
+	}
+
+	eventsChannel, done := make(chan engine.Event), make(chan bool)
+	go func() {
+		for _, e := range events {
+			eventsChannel <- e
+		}
+		close(eventsChannel)
+	}()
+	display.ShowEvents("replaying", action, stack, proj, eventsChannel, done, opts, isPreview)
+	<-done
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+func TestReadEventLog(t *testing.T) {
+	log := strings.Join([]string{
+		`{"sequence":0,"timestamp":1,"preludeEvent":{"config":{}}}`,
+		``,
+		`{"sequence":1,"timestamp":1,"resourcePreEvent":{"metadata":{"op":"create",` +
+			`"urn":"urn:pulumi:dev::website::aws:s3/bucket:Bucket::site","type":"aws:s3/bucket:Bucket"},"planning":true}}`,
+		`{"sequence":2,"timestamp":2,"cancelEvent":{}}`,
+	}, "\n")
+
+	events, err := readEventLog(strings.NewReader(log))
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.NotNil(t, events[0].PreludeEvent)
+	assert.NotNil(t, events[2].CancelEvent)
+
+	action, stack, proj, isPreview := replayInfo(events)
+	assert.Equal(t, apitype.PreviewUpdate, action)
+	assert.Equal(t, tokens.QName("dev"), stack)
+	assert.Equal(t, tokens.PackageName("website"), proj)
+	assert.True(t, isPreview)
+
+	_, err = readEventLog(strings.NewReader("{}\nnot json"))
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "line 2: "))
+	}
+}
+
+func TestReplayInfo(t *testing.T) {
+	step := func(op apitype.OpType) apitype.EngineEvent {
+		return apitype.EngineEvent{ResOutputsEvent: &apitype.ResOutputsEvent{
+			Metadata: apitype.StepEventMetadata{Op: op, URN: "urn:pulumi:prod::website::aws:s3/bucket:Bucket::site"},
+		}}
+	}
+
+	action, stack, _, isPreview := replayInfo([]apitype.EngineEvent{step(apitype.OpSame), step(apitype.OpUpdate)})
+	assert.Equal(t, apitype.UpdateUpdate, action)
+	assert.Equal(t, tokens.QName("prod"), stack)
+	assert.False(t, isPreview)
+
+	action, _, _, _ = replayInfo([]apitype.EngineEvent{step(apitype.OpSame), step(apitype.OpRefresh)})
+	assert.Equal(t, apitype.RefreshUpdate, action)
+
+	action, stack, _, _ = replayInfo(nil)
+	assert.Equal(t, apitype.UpdateUpdate, action)
+	assert.Equal(t, tokens.QName(""), stack)
+}
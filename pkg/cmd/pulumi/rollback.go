@@ -0,0 +1,67 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// rollbackFailedUpdate rolls a stack back to the snapshot that it had before an update failed, which is nil if the
+// stack had none. The resources that the update created are deleted, the resources that it changed are updated back
+// to their previous inputs and the resources that it deleted are recreated, where their providers allow it.
+// Recreated resources get new IDs. The rollback is performed without a preview or a prompt, as asking for it with
+// --rollback-on-failure approves it up front, and it returns the update's own result unless the rollback fails.
+func rollbackFailedUpdate(s backend.Stack, op backend.UpdateOperation, prev *deploy.Snapshot,
+	updateRes result.Result) result.Result {
+
+	if prev == nil {
+		prev = &deploy.Snapshot{}
+	}
+
+	fmt.Printf("The update failed; rolling stack '%s' back to its previous state\n\n", s.Ref())
+
+	op.Opts.AutoApprove = true
+	op.Opts.SkipPreview = true
+	op.Opts.Engine.RollbackTo = prev
+	op.Opts.Engine.Refresh = false
+	op.Opts.Engine.RefreshTargets = nil
+	op.Opts.Engine.ReplaceTargets = nil
+	op.Opts.Engine.UpdateTargets = nil
+	op.Opts.Engine.RolloutWaves = nil
+	m := *op.M
+	m.Message = "Roll back a failed update"
+	if op.M.Message != "" {
+		m.Message += ": " + op.M.Message
+	}
+	op.M = &m
+
+	_, res := s.Update(commandContext(), op)
+	if res != nil {
+		const msg = "the update failed and rolling it back also failed, so the stack may have resources from both " +
+			"before and after the update"
+		if err := PrintEngineResult(res).Error(); err != nil {
+			return result.FromError(fmt.Errorf(msg+": %w", err))
+		}
+		return result.FromError(errors.New(msg))
+	}
+
+	fmt.Printf("Stack '%s' was rolled back to its state before the update\n", s.Ref())
+	return PrintEngineResult(updateRes)
+}
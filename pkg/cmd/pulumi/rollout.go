@@ -0,0 +1,62 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+)
+
+// applyRolloutWaves stages an update of the stack by the stack's rollout waves. Waves that pause ask whether to
+// proceed once they have completed, which requires an interactive session and a display that prints line by line, as
+// the interactive progress display would draw over the prompts.
+func applyRolloutWaves(s backend.Stack, interactive bool, opts *backend.UpdateOptions) error {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return fmt.Errorf("loading stack settings: %w", err)
+	}
+	if len(ps.RolloutWaves) == 0 {
+		return nil
+	}
+
+	pauses := false
+	for _, w := range ps.RolloutWaves {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+		if w.Pause {
+			if !interactive {
+				return fmt.Errorf("rollout wave %q pauses for confirmation, which requires an interactive session",
+					w.Name)
+			}
+			if opts.Display.IsInteractive && opts.Display.Type == display.DisplayProgress && !opts.Display.JSONDisplay {
+				return fmt.Errorf("rollout wave %q pauses for confirmation, which the interactive progress display "+
+					"cannot prompt for; pass --diff to use a display that can", w.Name)
+			}
+			pauses = true
+		}
+	}
+
+	opts.Engine.RolloutWaves = ps.RolloutWaves
+	if pauses {
+		opts.Engine.ConfirmRolloutWave = func(wave string) (bool, error) {
+			return confirmPrompt(fmt.Sprintf("Rollout wave %q has completed. Proceed to the next wave?", wave),
+				"yes", opts.Display), nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// redactSecrets is set by the global --redact-secrets flag, which makes commands replace secret values in their JSON
+// output with stable hashes.
+var redactSecrets bool
+
+// addShowSecretsFlag adds the --show-secrets flag, which has the same meaning for every command that displays secret
+// values: they are decrypted and displayed in plaintext rather than being hidden.
+func addShowSecretsFlag(cmd *cobra.Command, showSecrets *bool) {
+	cmd.Flags().BoolVar(
+		showSecrets, "show-secrets", false,
+		"Display secret values in plaintext instead of hiding them")
+}
+
+// checkShowSecretsFlag checks that --show-secrets is not combined with --redact-secrets.
+func checkShowSecretsFlag(showSecrets bool) error {
+	if showSecrets && redactSecrets {
+		return errors.New("only one of --show-secrets and --redact-secrets may be specified")
+	}
+	return nil
+}
+
+// secretRedactor replaces secret values with hashes of their plaintext, so that displays can be compared across
+// runs without exposing the secrets. Hashes are keyed by the stack's name, so that a value cannot be looked up by its
+// hash alone.
+type secretRedactor struct {
+	key []byte
+}
+
+// newSecretRedactor returns a redactor for the given stack if --redact-secrets was passed, and nil otherwise.
+func newSecretRedactor(s backend.Stack) *secretRedactor {
+	if !redactSecrets {
+		return nil
+	}
+	return &secretRedactor{key: []byte(s.Ref().Name())}
+}
+
+// redact returns the hash that stands in for a secret value.
+func (r *secretRedactor) redact(plaintext string) string {
+	mac := hmac.New(sha256.New, r.key)
+	_, err := mac.Write([]byte(plaintext))
+	contract.AssertNoError(err)
+	return "[secret:" + hex.EncodeToString(mac.Sum(nil))[:16] + "]"
+}
+
+// redactConfigValue replaces the value of a secret config value with the hash of its plaintext.
+func (r *secretRedactor) redactConfigValue(v configValueJSON, plaintext string) configValueJSON {
+	if !v.Secret {
+		return v
+	}
+	redacted := r.redact(plaintext)
+	return configValueJSON{Value: &redacted, Secret: true}
+}
+
+// redactProperties returns a copy of the given properties in which the value of every secret is replaced with the
+// hash of its plaintext. The hashes remain secret, so the properties can still be serialized with their secrets shown.
+func (r *secretRedactor) redactProperties(props resource.PropertyMap) (resource.PropertyMap, error) {
+	result := make(resource.PropertyMap, len(props))
+	for k, v := range props {
+		redacted, err := r.redactPropertyValue(v)
+		if err != nil {
+			return nil, err
+		}
+		result[k] = redacted
+	}
+	return result, nil
+}
+
+func (r *secretRedactor) redactPropertyValue(v resource.PropertyValue) (resource.PropertyValue, error) {
+	switch {
+	case v.IsSecret():
+		elem := v.SecretValue().Element
+		if elem.IsString() {
+			return resource.MakeSecret(resource.NewStringProperty(r.redact(elem.StringValue()))), nil
+		}
+		// Hash other values by their JSON, as it is displayed.
+		serialized, err := stack.SerializePropertyValue(elem, config.NewPanicCrypter(), true /*showSecrets*/)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		b, err := json.Marshal(serialized)
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		return resource.MakeSecret(resource.NewStringProperty(r.redact(string(b)))), nil
+	case v.IsArray():
+		arr := make([]resource.PropertyValue, len(v.ArrayValue()))
+		for i, e := range v.ArrayValue() {
+			redacted, err := r.redactPropertyValue(e)
+			if err != nil {
+				return resource.PropertyValue{}, err
+			}
+			arr[i] = redacted
+		}
+		return resource.NewArrayProperty(arr), nil
+	case v.IsObject():
+		obj, err := r.redactProperties(v.ObjectValue())
+		if err != nil {
+			return resource.PropertyValue{}, err
+		}
+		return resource.NewObjectProperty(obj), nil
+	default:
+		return v, nil
+	}
+}
+
+// redactSnapshot replaces the value of every secret in the inputs and outputs of a snapshot's resources with the
+// hash of its plaintext.
+func (r *secretRedactor) redactSnapshot(snap *deploy.Snapshot) error {
+	states := append([]*resource.State{}, snap.Resources...)
+	for _, op := range snap.PendingOperations {
+		states = append(states, op.Resource)
+	}
+	for _, res := range states {
+		inputs, err := r.redactProperties(res.Inputs)
+		if err != nil {
+			return err
+		}
+		outputs, err := r.redactProperties(res.Outputs)
+		if err != nil {
+			return err
+		}
+		res.Inputs, res.Outputs = inputs, outputs
+	}
+	return nil
+}
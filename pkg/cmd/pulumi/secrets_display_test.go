@@ -0,0 +1,68 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestSecretRedactor(t *testing.T) {
+	r := &secretRedactor{key: []byte("dev")}
+	other := &secretRedactor{key: []byte("prod")}
+
+	// Hashes are stable, differ between values and stacks, and never contain the value.
+	h := r.redact("hunter2")
+	assert.True(t, strings.HasPrefix(h, "[secret:"))
+	assert.NotContains(t, h, "hunter2")
+	assert.Equal(t, h, r.redact("hunter2"))
+	assert.NotEqual(t, h, r.redact("hunter3"))
+	assert.NotEqual(t, h, other.redact("hunter2"))
+
+	props, err := r.redactProperties(resource.PropertyMap{
+		"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+		"endpoint": resource.NewStringProperty("db.example.com"),
+		"nested": resource.NewObjectProperty(resource.PropertyMap{
+			"keys": resource.NewArrayProperty([]resource.PropertyValue{
+				resource.MakeSecret(resource.NewNumberProperty(42)),
+			}),
+		}),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, resource.MakeSecret(resource.NewStringProperty(h)), props["password"])
+	assert.Equal(t, resource.NewStringProperty("db.example.com"), props["endpoint"])
+	assert.Equal(t, resource.MakeSecret(resource.NewStringProperty(r.redact("42"))),
+		props["nested"].ObjectValue()["keys"].ArrayValue()[0])
+
+	value := "hunter2"
+	assert.Equal(t, configValueJSON{Value: &h, Secret: true},
+		r.redactConfigValue(configValueJSON{Value: &value, Secret: true}, value))
+	assert.Equal(t, configValueJSON{Value: &value},
+		r.redactConfigValue(configValueJSON{Value: &value}, value))
+}
+
+func TestCheckShowSecretsFlag(t *testing.T) {
+	defer func() { redactSecrets = false }()
+
+	assert.NoError(t, checkShowSecretsFlag(true))
+	redactSecrets = true
+	assert.NoError(t, checkShowSecretsFlag(false))
+	assert.Error(t, checkShowSecretsFlag(true))
+}
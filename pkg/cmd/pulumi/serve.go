@@ -0,0 +1,389 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/constant"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+func newServeCmd() *cobra.Command {
+	var address string
+	var grpcAddress string
+
+	var cmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Serve stack operations over local HTTP and gRPC APIs",
+		Long: "Serve stack operations over local HTTP and gRPC APIs.\n" +
+			"\n" +
+			"This command runs until interrupted, and lets orchestration systems drive the stacks of the project\n" +
+			"in the current directory without starting a CLI process per operation. Stack names may be fully\n" +
+			"qualified, e.g. `org/project/stack`.\n" +
+			"\n" +
+			"Each request must carry the bearer token that is printed at startup in an `Authorization: Bearer`\n" +
+			"header, and must be addressed to `localhost`, `127.0.0.1` or `::1`. Request bodies must be JSON, sent\n" +
+			"with the `application/json` content type.\n" +
+			"\n" +
+			"    POST /stacks/<stack>/preview\n" +
+			"    POST /stacks/<stack>/up\n" +
+			"    POST /stacks/<stack>/refresh\n" +
+			"    POST /stacks/<stack>/destroy\n" +
+			"        Runs the operation without prompting. The optional JSON body may set `message`,\n" +
+			"        `skipPreview`, `refresh`, `targets` and `parallel`. The response streams the engine events\n" +
+			"        of the operation as lines of JSON, in the format written by `--event-log`, followed by a\n" +
+			"        final line with `done` set to true and the operation's `resourceChanges` and `error`, if any.\n" +
+			"        A destroy is only run if the body sets `confirmDestroy` to true.\n" +
+			"\n" +
+			"    GET /stacks/<stack>/state\n" +
+			"        Returns the stack's current deployment, in the format written by `pulumi stack export`.\n" +
+			"\n" +
+			"The same operations are served at `--grpc-address` by the `pulumirpc.StackOperations` gRPC service,\n" +
+			"whose requests and responses are the JSON objects above as `google.protobuf.Struct` messages. Each\n" +
+			"request names its `stack`, and the bearer token is sent in `authorization` metadata.\n" +
+			"\n" +
+			"Operations are run one at a time, in the order in which they are received.",
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			lis, err := net.Listen("tcp", address)
+			if err != nil {
+				return fmt.Errorf("listening on %v: %w", address, err)
+			}
+			grpcLis, err := net.Listen("tcp", grpcAddress)
+			if err != nil {
+				return fmt.Errorf("listening on %v: %w", grpcAddress, err)
+			}
+			token, err := newServeToken()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Serving stack operations at http://%v\n", lis.Addr())
+			fmt.Printf("Serving the pulumirpc.StackOperations gRPC service at %v\n", grpcLis.Addr())
+			fmt.Printf("Bearer token: %v\n", token)
+
+			h := &serveHandler{token: token}
+			errs := make(chan error, 2)
+			go func() { errs <- http.Serve(lis, h) }()
+			go func() { errs <- newServeGRPCServer(h).Serve(grpcLis) }()
+			return <-errs
+		}),
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&address, "address", "localhost:0",
+		"The address on which to serve the HTTP API; a port of 0 picks an unused port")
+	cmd.PersistentFlags().StringVar(
+		&grpcAddress, "grpc-address", "localhost:0",
+		"The address on which to serve the gRPC API; a port of 0 picks an unused port")
+
+	return cmd
+}
+
+// serveOperationRequest is the optional body of a request to run an operation.
+type serveOperationRequest struct {
+	Message     string   `json:"message,omitempty"`
+	SkipPreview bool     `json:"skipPreview,omitempty"`
+	Refresh     bool     `json:"refresh,omitempty"`
+	Targets     []string `json:"targets,omitempty"`
+	Parallel    int      `json:"parallel,omitempty"`
+	// ConfirmDestroy must be set for a destroy to be run.
+	ConfirmDestroy bool `json:"confirmDestroy,omitempty"`
+}
+
+// serveOperationResult is the final line of the response to a request to run an operation.
+type serveOperationResult struct {
+	Done            bool                   `json:"done"`
+	ResourceChanges engine.ResourceChanges `json:"resourceChanges,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+}
+
+// serveHandler serves the `pulumi serve` API.
+type serveHandler struct {
+	// token is the bearer token that every request must carry.
+	token string
+	// Serializes requests, which share the process's working directory, credentials and cancellation scopes.
+	m sync.Mutex
+}
+
+// newServeToken generates a random bearer token for `pulumi serve`.
+func newServeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating bearer token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// isLoopbackHost returns true if a request's host, with or without a port, names the local host. IPv6 addresses may
+// be bracketed, as they are in URLs.
+func isLoopbackHost(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	} else {
+		host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// validToken returns true if the given bearer token is the handler's.
+func (h *serveHandler) validToken(token string) bool {
+	return h.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// authorize checks that a request carries the handler's bearer token and is addressed to the local host, which stops
+// web pages from driving the API through the user's browser, e.g. by rebinding a DNS name to the loopback address.
+func (h *serveHandler) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !isLoopbackHost(r.Host) {
+		http.Error(w, "requests must be addressed to localhost, 127.0.0.1 or ::1", http.StatusForbidden)
+		return false
+	}
+
+	if !h.validToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func (h *serveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorize(w, r) {
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/stacks/")
+	i := strings.LastIndex(rest, "/")
+	if rest == r.URL.Path || i <= 0 {
+		http.NotFound(w, r)
+		return
+	}
+	stackName, action := rest[:i], rest[i+1:]
+
+	var kind apitype.UpdateKind
+	switch action {
+	case "state":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.m.Lock()
+		defer h.m.Unlock()
+		h.serveState(w, r, stackName)
+		return
+	case "preview":
+		kind = apitype.PreviewUpdate
+	case "up":
+		kind = apitype.UpdateUpdate
+	case "refresh":
+		kind = apitype.RefreshUpdate
+	case "destroy":
+		kind = apitype.DestroyUpdate
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if r.ContentLength != 0 {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "application/json" {
+			http.Error(w, "request bodies must be application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
+	var req serveOperationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if kind == apitype.DestroyUpdate && !req.ConfirmDestroy {
+		http.Error(w, "a destroy must be confirmed by setting confirmDestroy to true", http.StatusBadRequest)
+		return
+	}
+
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.serveOperation(w, r, stackName, kind, req)
+}
+
+func (h *serveHandler) serveState(w http.ResponseWriter, r *http.Request, stackName string) {
+	s, err := requireStack(stackName, false, display.Options{Color: colors.Never}, false /*setCurrent*/)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	deployment, err := s.ExportDeployment(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	contract.IgnoreError(json.NewEncoder(w).Encode(deployment))
+}
+
+func (h *serveHandler) serveOperation(w http.ResponseWriter, r *http.Request, stackName string,
+	kind apitype.UpdateKind, req serveOperationRequest) {
+
+	stream := &flushingWriter{w: w}
+	s, op, err := prepareServeOperation(stackName, req, stream)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	changes, res := runServeOperation(r.Context(), s, kind, op)
+	contract.IgnoreError(json.NewEncoder(stream).Encode(newServeOperationResult(changes, res)))
+}
+
+// newServeOperationResult returns the final result of an operation.
+func newServeOperationResult(changes engine.ResourceChanges, res result.Result) serveOperationResult {
+	final := serveOperationResult{Done: true, ResourceChanges: changes}
+	if res != nil {
+		final.Error = "the operation failed"
+		if err := res.Error(); err != nil {
+			final.Error = err.Error()
+		}
+	}
+	return final
+}
+
+// prepareServeOperation loads the stack and project for an operation, whose engine events are streamed to events.
+func prepareServeOperation(stackName string, req serveOperationRequest,
+	events io.Writer) (backend.Stack, backend.UpdateOperation, error) {
+
+	displayOpts := display.Options{
+		Color:       colors.Never,
+		Type:        display.DisplayProgress,
+		Stdout:      ioutil.Discard,
+		Stderr:      ioutil.Discard,
+		EventStream: events,
+	}
+
+	s, err := requireStack(stackName, false, displayOpts, false /*setCurrent*/)
+	if err != nil {
+		return nil, backend.UpdateOperation{}, err
+	}
+	proj, root, err := readProjectForUpdate("")
+	if err != nil {
+		return nil, backend.UpdateOperation{}, err
+	}
+	m, err := getUpdateMetadata(req.Message, proj, root, constant.ExecKindServe, "")
+	if err != nil {
+		return nil, backend.UpdateOperation{}, fmt.Errorf("gathering environment metadata: %w", err)
+	}
+	sm, err := getStackSecretsManager(s)
+	if err != nil {
+		return nil, backend.UpdateOperation{}, fmt.Errorf("getting secrets manager: %w", err)
+	}
+	cfg, err := getStackConfiguration(s, sm)
+	if err != nil {
+		return nil, backend.UpdateOperation{}, fmt.Errorf("getting stack configuration: %w", err)
+	}
+
+	parallel := req.Parallel
+	if parallel <= 0 {
+		parallel = defaultParallel
+	}
+	var targets []resource.URN
+	for _, t := range req.Targets {
+		targets = append(targets, resource.URN(t))
+	}
+
+	return s, backend.UpdateOperation{
+		Proj: proj,
+		Root: root,
+		M:    m,
+		Opts: backend.UpdateOptions{
+			AutoApprove: true,
+			SkipPreview: req.SkipPreview,
+			Display:     displayOpts,
+			Engine: engine.UpdateOptions{
+				Parallel:                  parallel,
+				Refresh:                   req.Refresh,
+				RefreshTargets:            targets,
+				UpdateTargets:             targets,
+				DestroyTargets:            targets,
+				UseLegacyDiff:             useLegacyDiff(),
+				DisableProviderPreview:    disableProviderPreview(),
+				DisableResourceReferences: disableResourceReferences(),
+				DisableOutputValues:       disableOutputValues(),
+			},
+		},
+		StackConfiguration: cfg,
+		SecretsManager:     sm,
+		Scopes:             cancellationScopes,
+	}, nil
+}
+
+// runServeOperation runs the given kind of operation on a stack.
+func runServeOperation(ctx context.Context, s backend.Stack, kind apitype.UpdateKind,
+	op backend.UpdateOperation) (engine.ResourceChanges, result.Result) {
+
+	switch kind {
+	case apitype.PreviewUpdate:
+		return s.Preview(ctx, op)
+	case apitype.UpdateUpdate:
+		return s.Update(ctx, op)
+	case apitype.RefreshUpdate:
+		return s.Refresh(ctx, op)
+	case apitype.DestroyUpdate:
+		return s.Destroy(ctx, op)
+	default:
+		return nil, result.FromError(errors.New("unsupported operation " + string(kind)))
+	}
+}
+
+// flushingWriter flushes each write to an HTTP response, so that streamed events reach the client as they happen.
+type flushingWriter struct {
+	w http.ResponseWriter
+}
+
+func (f *flushingWriter) Write(b []byte) (int, error) {
+	n, err := f.w.Write(b)
+	if flusher, ok := f.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
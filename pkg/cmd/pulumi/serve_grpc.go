@@ -0,0 +1,228 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+// serveGRPCServer implements the pulumirpc.StackOperations service of `pulumi serve`, sharing the bearer token and
+// serialization of its HTTP API.
+type serveGRPCServer struct {
+	h *serveHandler
+}
+
+// newServeGRPCServer returns a gRPC server for the StackOperations service, which authorizes each call as the HTTP
+// API authorizes each request.
+func newServeGRPCServer(h *serveHandler) *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+			if err := h.authorizeRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+			handler grpc.StreamHandler) error {
+			if err := h.authorizeRPC(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	pulumirpc.RegisterStackOperationsServer(srv, &serveGRPCServer{h: h})
+	return srv
+}
+
+// authorizeRPC checks that a call carries the handler's bearer token in its `authorization` metadata and is addressed
+// to the local host.
+func (h *serveHandler) authorizeRPC(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	firstValue := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	if !isLoopbackHost(firstValue(":authority")) {
+		return status.Error(codes.PermissionDenied, "calls must be addressed to localhost, 127.0.0.1 or ::1")
+	}
+	if !h.validToken(strings.TrimPrefix(firstValue("authorization"), "Bearer ")) {
+		return status.Error(codes.Unauthenticated, "a valid bearer token is required")
+	}
+	return nil
+}
+
+// serveOperationStream is the stream of the response to any of the StackOperations operations.
+type serveOperationStream interface {
+	Send(*structpb.Struct) error
+	grpc.ServerStream
+}
+
+func (s *serveGRPCServer) Preview(req *structpb.Struct, stream pulumirpc.StackOperations_PreviewServer) error {
+	return s.serveOperation(req, stream, apitype.PreviewUpdate)
+}
+
+func (s *serveGRPCServer) Up(req *structpb.Struct, stream pulumirpc.StackOperations_UpServer) error {
+	return s.serveOperation(req, stream, apitype.UpdateUpdate)
+}
+
+func (s *serveGRPCServer) Refresh(req *structpb.Struct, stream pulumirpc.StackOperations_RefreshServer) error {
+	return s.serveOperation(req, stream, apitype.RefreshUpdate)
+}
+
+func (s *serveGRPCServer) Destroy(req *structpb.Struct, stream pulumirpc.StackOperations_DestroyServer) error {
+	return s.serveOperation(req, stream, apitype.DestroyUpdate)
+}
+
+func (s *serveGRPCServer) GetState(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	stackName, _, err := unmarshalServeRequest(req)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	s.h.m.Lock()
+	defer s.h.m.Unlock()
+
+	st, err := requireStack(stackName, false, display.Options{Color: colors.Never}, false /*setCurrent*/)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	deployment, err := st.ExportDeployment(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return marshalServeStruct(deployment)
+}
+
+func (s *serveGRPCServer) serveOperation(req *structpb.Struct, stream serveOperationStream,
+	kind apitype.UpdateKind) error {
+
+	stackName, opReq, err := unmarshalServeRequest(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if kind == apitype.DestroyUpdate && !opReq.ConfirmDestroy {
+		return status.Error(codes.InvalidArgument, "a destroy must be confirmed by setting confirmDestroy to true")
+	}
+
+	s.h.m.Lock()
+	defer s.h.m.Unlock()
+
+	events := &structStreamWriter{send: stream.Send}
+	st, op, err := prepareServeOperation(stackName, opReq, events)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	changes, res := runServeOperation(stream.Context(), st, kind, op)
+	final, err := marshalServeStruct(newServeOperationResult(changes, res))
+	if err != nil {
+		return err
+	}
+	return stream.Send(final)
+}
+
+// unmarshalServeRequest decodes a StackOperations request, which names its stack and may set the options of an
+// operation.
+func unmarshalServeRequest(req *structpb.Struct) (string, serveOperationRequest, error) {
+	props, err := plugin.UnmarshalProperties(req, plugin.MarshalOptions{Label: "StackOperations.request"})
+	if err != nil {
+		return "", serveOperationRequest{}, fmt.Errorf("invalid request: %w", err)
+	}
+	b, err := json.Marshal(props.Mappable())
+	if err != nil {
+		return "", serveOperationRequest{}, fmt.Errorf("invalid request: %w", err)
+	}
+
+	var r struct {
+		Stack string `json:"stack"`
+		serveOperationRequest
+	}
+	if err = json.Unmarshal(b, &r); err != nil {
+		return "", serveOperationRequest{}, fmt.Errorf("invalid request: %w", err)
+	}
+	if r.Stack == "" {
+		return "", serveOperationRequest{}, errors.New("invalid request: a stack is required")
+	}
+	return r.Stack, r.serveOperationRequest, nil
+}
+
+// marshalServeStruct converts a value to a Struct by way of its JSON representation.
+func marshalServeStruct(v interface{}) (*structpb.Struct, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]interface{}
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	return plugin.MarshalProperties(resource.NewPropertyMapFromMap(obj),
+		plugin.MarshalOptions{Label: "StackOperations.response"})
+}
+
+// structStreamWriter sends each line of JSON that is written to it as a Struct.
+type structStreamWriter struct {
+	send func(*structpb.Struct) error
+	buf  bytes.Buffer
+}
+
+func (w *structStreamWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		line := bytes.TrimSpace(w.buf.Next(i + 1))
+		if len(line) == 0 {
+			continue
+		}
+
+		var obj map[string]interface{}
+		if err := json.Unmarshal(line, &obj); err != nil {
+			return len(b), err
+		}
+		msg, err := plugin.MarshalProperties(resource.NewPropertyMapFromMap(obj),
+			plugin.MarshalOptions{Label: "StackOperations.event"})
+		if err != nil {
+			return len(b), err
+		}
+		if err = w.send(msg); err != nil {
+			return len(b), err
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+func TestServeHandlerRouting(t *testing.T) {
+	t.Parallel()
+
+	const token = "secret"
+	cases := []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodGet, "/", "", http.StatusNotFound},
+		{http.MethodGet, "/stacks/dev", "", http.StatusNotFound},
+		{http.MethodPost, "/stacks/org/project/dev/deploy", "", http.StatusNotFound},
+		{http.MethodGet, "/stacks/org/project/dev/up", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/stacks/dev/state", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/stacks/dev/preview", "{", http.StatusBadRequest},
+		{http.MethodPost, "/stacks/dev/preview", `{"parallel": "ten"}`, http.StatusBadRequest},
+		{http.MethodPost, "/stacks/dev/destroy", `{}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(c.method, "http://localhost:8080"+c.path, strings.NewReader(c.body))
+		r.Header.Set("Authorization", "Bearer "+token)
+		r.Header.Set("Content-Type", "application/json")
+		(&serveHandler{token: token}).ServeHTTP(w, r)
+		assert.Equal(t, c.status, w.Code, "%v %v", c.method, c.path)
+	}
+}
+
+func TestServeHandlerAuthorization(t *testing.T) {
+	t.Parallel()
+
+	const token = "secret"
+	cases := []struct {
+		name, url, authorization, contentType string
+		status                                int
+	}{
+		{"missing token", "http://localhost/stacks/dev/up", "", "application/json", http.StatusUnauthorized},
+		{"wrong token", "http://localhost/stacks/dev/up", "Bearer guess", "application/json", http.StatusUnauthorized},
+		{"other host", "http://attacker.example/stacks/dev/up", "Bearer " + token, "application/json",
+			http.StatusForbidden},
+		{"form body", "http://127.0.0.1:1234/stacks/dev/up", "Bearer " + token, "text/plain",
+			http.StatusUnsupportedMediaType},
+		{"IPv6 loopback", "http://[::1]:1234/stacks/dev/up", "Bearer " + token, "text/plain",
+			http.StatusUnsupportedMediaType},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, c.url, strings.NewReader(`{}`))
+		if c.authorization != "" {
+			r.Header.Set("Authorization", c.authorization)
+		}
+		r.Header.Set("Content-Type", c.contentType)
+		(&serveHandler{token: token}).ServeHTTP(w, r)
+		assert.Equal(t, c.status, w.Code, c.name)
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	t.Parallel()
+
+	for _, host := range []string{"localhost", "localhost:80", "127.0.0.1:1234", "::1", "[::1]", "[::1]:1234"} {
+		assert.True(t, isLoopbackHost(host), host)
+	}
+	for _, host := range []string{"", "attacker.example", "attacker.example:80", "10.0.0.1", "[::2]:1234"} {
+		assert.False(t, isLoopbackHost(host), host)
+	}
+}
+
+func TestServeGRPCAuthorization(t *testing.T) {
+	t.Parallel()
+
+	const token = "secret"
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := newServeGRPCServer(&serveHandler{token: token})
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	request := func(fields map[string]interface{}) *structpb.Struct {
+		req, err := marshalServeStruct(fields)
+		require.NoError(t, err)
+		return req
+	}
+	destroy := func(authority, authorization string, req *structpb.Struct) error {
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure(), grpc.WithAuthority(authority))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", authorization)
+		}
+		stream, err := pulumirpc.NewStackOperationsClient(conn).Destroy(ctx, req)
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	cases := []struct {
+		name, authority, authorization string
+		req                            *structpb.Struct
+		code                           codes.Code
+	}{
+		{"missing token", "localhost", "", request(map[string]interface{}{"stack": "dev"}), codes.Unauthenticated},
+		{"wrong token", "localhost", "Bearer guess", request(map[string]interface{}{"stack": "dev"}),
+			codes.Unauthenticated},
+		{"other host", "attacker.example", "Bearer " + token, request(map[string]interface{}{"stack": "dev"}),
+			codes.PermissionDenied},
+		{"missing stack", "[::1]:1234", "Bearer " + token, request(map[string]interface{}{"confirmDestroy": true}),
+			codes.InvalidArgument},
+		{"unconfirmed destroy", "127.0.0.1", "Bearer " + token, request(map[string]interface{}{"stack": "dev"}),
+			codes.InvalidArgument},
+	}
+	for _, c := range cases {
+		err := destroy(c.authority, c.authorization, c.req)
+		assert.Equal(t, c.code, status.Code(err), c.name)
+	}
+}
@@ -52,6 +52,10 @@ func newStackCmd() *cobra.Command {
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
+			if err := checkShowSecretsFlag(showSecrets); err != nil {
+				return err
+			}
+
 			s, err := requireStack(stackName, true, opts, false /*setCurrent*/)
 			if err != nil {
 				return err
@@ -135,7 +139,7 @@ func newStackCmd() *cobra.Command {
 					Prefix:  "    ",
 				})
 
-				outputs, err := getStackOutputs(snap, showSecrets)
+				outputs, err := getStackOutputs(snap, showSecrets, nil /*redactor*/)
 				if err == nil {
 					fmt.Printf("\n")
 					printStackOutputs(outputs)
@@ -164,11 +168,11 @@ func newStackCmd() *cobra.Command {
 		&showIDs, "show-ids", "i", false, "Display each resource's provider-assigned unique ID")
 	cmd.Flags().BoolVarP(
 		&showURNs, "show-urns", "u", false, "Display each resource's Pulumi-assigned globally unique URN")
-	cmd.Flags().BoolVar(
-		&showSecrets, "show-secrets", false, "Display stack outputs which are marked as secret in plaintext")
+	addShowSecretsFlag(cmd, &showSecrets)
 	cmd.Flags().BoolVar(
 		&showStackName, "show-name", false, "Display only the stack name")
 
+	cmd.AddCommand(newStackAliasCmd())
 	cmd.AddCommand(newStackExportCmd())
 	cmd.AddCommand(newStackGraphCmd())
 	cmd.AddCommand(newStackImportCmd())
@@ -176,11 +180,19 @@ func newStackCmd() *cobra.Command {
 	cmd.AddCommand(newStackLsCmd())
 	cmd.AddCommand(newStackOutputCmd())
 	cmd.AddCommand(newStackRmCmd())
+	cmd.AddCommand(newStackRollbackCmd())
 	cmd.AddCommand(newStackSelectCmd())
+	cmd.AddCommand(newStackSetTTLCmd())
+	cmd.AddCommand(newStackStatsCmd())
 	cmd.AddCommand(newStackTagCmd())
 	cmd.AddCommand(newStackRenameCmd())
 	cmd.AddCommand(newStackChangeSecretsProviderCmd())
+	cmd.AddCommand(newStackRotateSecretsCmd())
+	cmd.AddCommand(newStackAuditCmd())
+	cmd.AddCommand(newStackAuditSecretsCmd())
+	cmd.AddCommand(newStackDestroyTokenCmd())
 	cmd.AddCommand(newStackHistoryCmd())
+	cmd.AddCommand(newStackVerifyCmd())
 
 	return cmd
 }
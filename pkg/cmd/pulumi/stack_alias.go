@@ -0,0 +1,159 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/state"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func newStackAliasCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage stack aliases",
+		Long: "Manage stack aliases\n" +
+			"\n" +
+			"Stack aliases are short names for stacks that may be used anywhere a stack name is\n" +
+			"expected, e.g. `pulumi stack select prod` or `pulumi up -s prod`. Aliases are stored in\n" +
+			"the workspace settings of the current project, so each project may define its own.\n" +
+			"The `ls`, `rm`, and `set` commands can be used to manage aliases.\n",
+		Args: cmdutil.NoArgs,
+	}
+
+	cmd.AddCommand(newStackAliasLsCmd())
+	cmd.AddCommand(newStackAliasRmCmd())
+	cmd.AddCommand(newStackAliasSetCmd())
+
+	return cmd
+}
+
+func newStackAliasLsCmd() *cobra.Command {
+	var jsonOut bool
+	cmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List all stack aliases for the current project",
+		Args:  cmdutil.NoArgs,
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			aliases, err := state.StackAliases()
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				if aliases == nil {
+					aliases = map[string]string{}
+				}
+				return printJSON(aliases)
+			}
+
+			printStackAliases(aliases)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+func printStackAliases(aliases map[string]string) {
+	var names []string
+	for n := range aliases {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	rows := []cmdutil.TableRow{}
+	for _, name := range names {
+		rows = append(rows, cmdutil.TableRow{Columns: []string{name, aliases[name]}})
+	}
+
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"ALIAS", "STACK"},
+		Rows:    rows,
+	})
+}
+
+func newStackAliasRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rm <alias>",
+		Short: "Remove a stack alias",
+		Args:  cmdutil.SpecificArgs([]string{"alias"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			alias := args[0]
+
+			removed, err := state.RemoveStackAlias(alias)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("no stack alias named '%s' found", alias)
+			}
+			return nil
+		}),
+	}
+}
+
+func newStackAliasSetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <alias> <stack>",
+		Short: "Set a stack alias",
+		Long: "Set a stack alias\n" +
+			"\n" +
+			"This command makes <alias> stand for <stack> in the current project. The stack does not need\n" +
+			"to exist yet, which allows an alias to be used with `pulumi stack select --create`.",
+		Args: cmdutil.SpecificArgs([]string{"alias", "stack"}),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			alias, stackName := args[0], args[1]
+			if err := validateStackAlias(alias); err != nil {
+				return err
+			}
+
+			// Make sure that the stack name is one the current backend understands before recording it.
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			b, err := currentBackend(opts)
+			if err != nil {
+				return err
+			}
+			if _, err := b.ParseStackReference(stackName); err != nil {
+				return err
+			}
+
+			return state.SetStackAlias(alias, stackName)
+		}),
+	}
+}
+
+// validateStackAlias checks that an alias is a plain name. Aliases may not contain slashes so that they can never be
+// mistaken for a fully qualified stack name.
+func validateStackAlias(alias string) error {
+	if alias == "" {
+		return fmt.Errorf("a stack alias may not be empty")
+	}
+	if strings.Contains(alias, "/") {
+		return fmt.Errorf("stack alias '%s' may not contain '/'", alias)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// auditSigningKeyEnvVar is the environment variable that holds the key used to sign audit logs, if
+// --signing-key-file is not passed.
+const auditSigningKeyEnvVar = "PULUMI_AUDIT_SIGNING_KEY"
+
+func newStackAuditCmd() *cobra.Command {
+	var stack string
+	var output string
+	var keyFile string
+	var verify bool
+
+	var cmd = &cobra.Command{
+		Use:   "audit",
+		Args:  cmdutil.NoArgs,
+		Short: "Write a signed audit log of a stack's updates",
+		Long: "Write a signed audit log of a stack's updates.\n" +
+			"\n" +
+			"This command reconstructs who ran each of the stack's updates, and when, from the update history and\n" +
+			"checkpoints kept by a self-managed backend, and appends a record of every completed update that is not\n" +
+			"yet in the audit log at `--output` to it, one JSON object per line. Each record includes the update's\n" +
+			"kind, result, timing, user, message, resource changes, environment and a hash of its checkpoint. The\n" +
+			"user is the backend user that ran the update, and the record's identity is the CI or git identity\n" +
+			"of its environment.\n" +
+			"\n" +
+			"Each record is signed with an HMAC-SHA256 of its contents and the signature of the record before it,\n" +
+			"using the key in the file given by `--signing-key-file` or the `PULUMI_AUDIT_SIGNING_KEY` environment\n" +
+			"variable, so records cannot be changed, reordered or removed from the middle of the log without\n" +
+			"detection. Removing the newest records is not detected by the signatures alone; the next run appends\n" +
+			"them again from the stack's history, so keep a copy of the log elsewhere to detect it. The existing\n" +
+			"log is verified before anything is appended, and the command fails if it has been tampered with or if\n" +
+			"the stack's history no longer matches it. Pass `--verify` to only verify the log.\n" +
+			"\n" +
+			"The Pulumi Service keeps its own audit log, so this command is only supported for self-managed backends.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			if output == "" {
+				return errors.New("an audit log file must be given with --output")
+			}
+			key, err := readAuditSigningKey(keyFile)
+			if err != nil {
+				return err
+			}
+
+			if verify {
+				if _, err := os.Stat(output); err != nil {
+					return err
+				}
+			}
+			existing, err := readAuditLogFile(output, key)
+			if err != nil {
+				return err
+			}
+			if verify {
+				fmt.Printf("Verified %d record(s) in audit log %s\n", len(existing), output)
+				return nil
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			if _, ok := s.Backend().(filestate.Backend); !ok {
+				return fmt.Errorf("stack '%s' is not in a self-managed backend; the Pulumi Service keeps its own "+
+					"audit log", s.Ref())
+			}
+
+			ctx := commandContext()
+			updates, err := s.Backend().GetHistory(ctx, s.Ref(), 0, 0)
+			if err != nil {
+				return fmt.Errorf("getting history: %w", err)
+			}
+
+			checkpointHash := func(version int) string {
+				return getCheckpointHash(ctx, s, version)
+			}
+			records, err := newAuditRecords(s.Ref().String(), updates, existing, checkpointHash, key)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				fmt.Printf("Audit log %s is up to date with stack '%s'\n", output, s.Ref())
+				return nil
+			}
+
+			if err := appendAuditRecords(output, records); err != nil {
+				return err
+			}
+			fmt.Printf("Appended %d record(s) for stack '%s' to audit log %s\n", len(records), s.Ref(), output)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVarP(
+		&output, "output", "o", "", "The audit log file to append to")
+	cmd.PersistentFlags().StringVar(
+		&keyFile, "signing-key-file", "",
+		"A file containing the key used to sign the audit log. Defaults to the value of "+auditSigningKeyEnvVar)
+	cmd.PersistentFlags().BoolVar(
+		&verify, "verify", false, "Only verify the signatures of the audit log, without appending to it")
+
+	return cmd
+}
+
+// auditRecord is a single line of an audit log. While we can add fields to this structure in the future, we should
+// not change existing fields, as doing so would invalidate the signatures of existing logs.
+type auditRecord struct {
+	Stack           string            `json:"stack"`
+	Version         int               `json:"version"`
+	Kind            string            `json:"kind"`
+	Result          string            `json:"result"`
+	StartTime       string            `json:"startTime"`
+	EndTime         string            `json:"endTime"`
+	User            string            `json:"user,omitempty"`
+	Identity        string            `json:"identity,omitempty"`
+	Message         string            `json:"message,omitempty"`
+	ResourceChanges map[string]int    `json:"resourceChanges,omitempty"`
+	Environment     map[string]string `json:"environment,omitempty"`
+	CheckpointHash  string            `json:"checkpointHash,omitempty"`
+
+	// Previous is the signature of the record before this one, or empty for the first record of the log.
+	Previous string `json:"previous"`
+	// Signature is the HMAC-SHA256 of the rest of the record.
+	Signature string `json:"signature"`
+}
+
+// signedContents returns the contents of the record that are signed, which are all of its fields other than
+// Signature.
+func (r auditRecord) signedContents() []byte {
+	r.Signature = ""
+	b, err := json.Marshal(r)
+	contract.AssertNoError(err)
+	return b
+}
+
+// sign returns the signature of the record.
+func (r auditRecord) sign(key []byte) string {
+	return filestate.Sign(key, r.signedContents())
+}
+
+// readAuditSigningKey reads the key used to sign audit logs from the given file, or from the environment if no file
+// is given.
+func readAuditSigningKey(keyFile string) ([]byte, error) {
+	key, err := filestate.ReadSigningKey(keyFile, os.Getenv(auditSigningKeyEnvVar))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("a key to sign the audit log with is required; pass --signing-key-file or set %s",
+			auditSigningKeyEnvVar)
+	}
+	return key, nil
+}
+
+// readAuditLogFile reads and verifies the audit log at the given path. A log that does not exist yet is empty.
+func readAuditLogFile(path string, key []byte) ([]auditRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer contract.IgnoreClose(f)
+
+	records, err := readAuditLog(f, key)
+	if err != nil {
+		return nil, fmt.Errorf("audit log %s is invalid: %w", path, err)
+	}
+	return records, nil
+}
+
+// readAuditLog reads an audit log, checking that each record is signed with the given key and follows the record
+// before it.
+func readAuditLog(r io.Reader, key []byte) ([]auditRecord, error) {
+	var records []auditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	previous := ""
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+
+		var record auditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.Previous != previous {
+			return nil, fmt.Errorf("line %d: the record does not follow the record before it", line)
+		}
+		if !filestate.CheckSignature(key, record.signedContents(), record.Signature) {
+			return nil, fmt.Errorf("line %d: the record's signature does not match its contents", line)
+		}
+
+		records = append(records, record)
+		previous = record.Signature
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// newAuditRecords returns signed records for the completed updates in a stack's history that are not yet in its audit
+// log, oldest first. It fails if the updates already in the log no longer match the stack's history.
+func newAuditRecords(stackName string, updates []backend.UpdateInfo, existing []auditRecord,
+	checkpointHash func(version int) string, key []byte) ([]auditRecord, error) {
+
+	// History is returned newest first, but the log records updates oldest first.
+	chronological := make([]backend.UpdateInfo, len(updates))
+	copy(chronological, updates)
+	sort.Slice(chronological, func(i, j int) bool {
+		return chronological[i].Version < chronological[j].Version
+	})
+
+	byVersion := make(map[int]backend.UpdateInfo)
+	for _, update := range chronological {
+		byVersion[update.Version] = update
+	}
+
+	latest, previous := 0, ""
+	for _, record := range existing {
+		if record.Stack != stackName {
+			return nil, fmt.Errorf("the audit log has records for stack '%s', not '%s'", record.Stack, stackName)
+		}
+		update, has := byVersion[record.Version]
+		if !has || update.StartTime != auditTime(record.StartTime) || string(update.Kind) != record.Kind {
+			return nil, fmt.Errorf("the history of stack '%s' no longer matches the audit log at version %d",
+				stackName, record.Version)
+		}
+		latest, previous = record.Version, record.Signature
+	}
+
+	var records []auditRecord
+	for _, update := range chronological {
+		if update.Version <= latest {
+			continue
+		}
+		// Stop at the first update that has not finished, so that it is recorded once it has.
+		if update.Result == backend.InProgressResult {
+			break
+		}
+
+		record := auditRecord{
+			Stack:          stackName,
+			Version:        update.Version,
+			Kind:           string(update.Kind),
+			Result:         string(update.Result),
+			StartTime:      time.Unix(update.StartTime, 0).UTC().Format(time.RFC3339),
+			EndTime:        time.Unix(update.EndTime, 0).UTC().Format(time.RFC3339),
+			User:           update.Environment[backend.BackendUser],
+			Identity:       auditIdentity(update.Environment),
+			Message:        update.Message,
+			Environment:    update.Environment,
+			CheckpointHash: checkpointHash(update.Version),
+			Previous:       previous,
+		}
+		if len(update.ResourceChanges) > 0 {
+			record.ResourceChanges = make(map[string]int)
+			for op, count := range update.ResourceChanges {
+				record.ResourceChanges[string(op)] = count
+			}
+		}
+		record.Signature = record.sign(key)
+
+		records = append(records, record)
+		previous = record.Signature
+	}
+	return records, nil
+}
+
+// auditTime parses a time written to an audit record, returning 0 if it is invalid.
+func auditTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+// auditIdentity returns the identity in an update's environment: the user that triggered the CI build running it, or
+// otherwise the author or committer of the commit it was run from. Unlike the backend user that ran the update, these
+// are reported by the environment and are not authenticated.
+func auditIdentity(env map[string]string) string {
+	for _, key := range []string{backend.CIActor, backend.GitAuthor, backend.GitCommitter} {
+		if user := env[key]; user != "" {
+			if email := env[key+".email"]; email != "" {
+				return fmt.Sprintf("%s <%s>", user, email)
+			}
+			return user
+		}
+	}
+	return ""
+}
+
+// getCheckpointHash returns the SHA-256 hash of the checkpoint recorded for the given version of a stack, or an empty
+// string if it is not available.
+func getCheckpointHash(ctx context.Context, s backend.Stack, version int) string {
+	exporter, ok := s.Backend().(backend.SpecificDeploymentExporter)
+	if !ok {
+		return ""
+	}
+	deployment, err := exporter.ExportDeploymentForVersion(ctx, s, strconv.Itoa(version))
+	if err != nil {
+		logging.V(5).Infof("could not export version %d of stack '%s': %v", version, s.Ref(), err)
+		return ""
+	}
+	sum := sha256.Sum256(deployment.Deployment)
+	return hex.EncodeToString(sum[:])
+}
+
+// appendAuditRecords appends the given records to the audit log at the given path, creating it if necessary.
+func appendAuditRecords(path string, records []auditRecord) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, record := range records {
+		b, err := json.Marshal(record)
+		if err != nil {
+			contract.IgnoreClose(f)
+			return err
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			contract.IgnoreClose(f)
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		contract.IgnoreClose(f)
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,233 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+// minSecretLeakLength is the length below which plaintext values are not compared with known secrets, so that short
+// values such as `true` or `80` are not reported merely because a secret happens to have the same value.
+const minSecretLeakLength = 6
+
+// secretLeak is a plaintext value in a stack's state that may be a secret.
+type secretLeak struct {
+	URN      resource.URN `json:"urn"`
+	Property string       `json:"property"`
+	Reason   string       `json:"reason"`
+}
+
+func newStackAuditSecretsCmd() *cobra.Command {
+	var stack string
+	var patterns []string
+	var jsonOut bool
+
+	var cmd = &cobra.Command{
+		Use:   "audit-secrets",
+		Args:  cmdutil.NoArgs,
+		Short: "Find plaintext values in the stack's state that may be secrets",
+		Long: "Find plaintext values in the stack's state that may be secrets.\n" +
+			"\n" +
+			"This command scans the inputs and outputs of every resource in the stack's state for values that are\n" +
+			"not marked secret, but are equal to one of the stack's secret config values, are equal to a secret\n" +
+			"elsewhere in the state, or match one of the regular expressions listed by the project's\n" +
+			"`options.secretPatterns` or passed with `--pattern`. The values themselves are never printed.\n" +
+			"\n" +
+			"The command fails if any potential leaks are found, so that it can be used to check a stack's state\n" +
+			"before it is shared.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+
+			if proj, _, err := readProject(); err == nil && proj.Options != nil {
+				patterns = append(append([]string{}, proj.Options.SecretPatterns...), patterns...)
+			}
+			compiled := make([]*regexp.Regexp, len(patterns))
+			for i, p := range patterns {
+				if compiled[i], err = regexp.Compile(p); err != nil {
+					return fmt.Errorf("invalid secret pattern %q: %w", p, err)
+				}
+			}
+
+			secrets := map[string]string{}
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+			if ps.Config.HasSecureValue() {
+				dec, err := getStackDecrypter(s)
+				if err != nil {
+					return err
+				}
+				for _, key := range ps.Config.SecureKeys() {
+					v, err := ps.Config[key].Value(dec)
+					if err != nil {
+						return fmt.Errorf("could not decrypt configuration value: %w", err)
+					}
+					secrets[v] = fmt.Sprintf("equals the secret config value '%s'", prettyKey(key))
+				}
+			}
+
+			leaks := auditSecrets(snap, secrets, compiled)
+
+			if jsonOut {
+				if err := printJSON(leaks); err != nil {
+					return err
+				}
+			} else if len(leaks) == 0 {
+				fmt.Printf("No potential secret leaks found in stack '%s'\n", s.Ref())
+			} else {
+				rows := make([]cmdutil.TableRow, len(leaks))
+				for i, leak := range leaks {
+					rows[i] = cmdutil.TableRow{Columns: []string{string(leak.URN), leak.Property, leak.Reason}}
+				}
+				cmdutil.PrintTable(cmdutil.Table{
+					Headers: []string{"RESOURCE", "PROPERTY", "REASON"},
+					Rows:    rows,
+				})
+			}
+
+			if len(leaks) > 0 {
+				return fmt.Errorf("found %d plaintext value(s) in stack '%s' that may be secrets", len(leaks), s.Ref())
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().StringArrayVar(
+		&patterns, "pattern", nil,
+		"A regular expression matching values that must be secret; may be given more than once")
+	cmd.Flags().BoolVarP(
+		&jsonOut, "json", "j", false,
+		"Emit output as JSON")
+
+	return cmd
+}
+
+// auditSecrets returns the plaintext values in the inputs and outputs of a snapshot's resources that equal one of
+// the given secrets, which map each secret value to the reason to report, or a secret elsewhere in the snapshot, or
+// that match one of the given patterns.
+func auditSecrets(snap *deploy.Snapshot, secrets map[string]string, patterns []*regexp.Regexp) []secretLeak {
+	leaks := []secretLeak{}
+	if snap == nil {
+		return leaks
+	}
+
+	known := make(map[string]string, len(secrets))
+	for v, reason := range secrets {
+		known[v] = reason
+	}
+	for _, res := range snap.Resources {
+		for _, props := range []resource.PropertyMap{res.Inputs, res.Outputs} {
+			collectSecretStrings(resource.NewObjectProperty(props), false, func(s string) {
+				if _, ok := known[s]; !ok {
+					known[s] = "equals a secret value in the stack's state"
+				}
+			})
+		}
+	}
+
+	for _, res := range snap.Resources {
+		report := func(path, value string) {
+			if reason, ok := known[value]; ok && len(value) >= minSecretLeakLength {
+				leaks = append(leaks, secretLeak{URN: res.URN, Property: path, Reason: reason})
+				return
+			}
+			for _, p := range patterns {
+				if p.MatchString(value) {
+					leaks = append(leaks, secretLeak{
+						URN:      res.URN,
+						Property: path,
+						Reason:   fmt.Sprintf("matches the secret pattern %q", p.String()),
+					})
+					return
+				}
+			}
+		}
+		auditPropertyMap(res.Inputs, "inputs", report)
+		auditPropertyMap(res.Outputs, "outputs", report)
+	}
+	return leaks
+}
+
+// collectSecretStrings calls add with every string within a secret in the given value.
+func collectSecretStrings(v resource.PropertyValue, inSecret bool, add func(string)) {
+	switch {
+	case v.IsSecret():
+		collectSecretStrings(v.SecretValue().Element, true, add)
+	case v.IsOutput():
+		collectSecretStrings(v.OutputValue().Element, inSecret || v.OutputValue().Secret, add)
+	case v.IsString():
+		if inSecret {
+			add(v.StringValue())
+		}
+	case v.IsArray():
+		for _, e := range v.ArrayValue() {
+			collectSecretStrings(e, inSecret, add)
+		}
+	case v.IsObject():
+		for _, e := range v.ObjectValue() {
+			collectSecretStrings(e, inSecret, add)
+		}
+	}
+}
+
+// auditPropertyMap calls report with the path and value of every string within the given properties that is not
+// within a secret.
+func auditPropertyMap(props resource.PropertyMap, path string, report func(path, value string)) {
+	for _, k := range props.StableKeys() {
+		auditPropertyValue(props[k], fmt.Sprintf("%s.%s", path, k), report)
+	}
+}
+
+func auditPropertyValue(v resource.PropertyValue, path string, report func(path, value string)) {
+	switch {
+	case v.IsSecret():
+		return
+	case v.IsOutput():
+		if !v.OutputValue().Secret {
+			auditPropertyValue(v.OutputValue().Element, path, report)
+		}
+	case v.IsString():
+		report(path, v.StringValue())
+	case v.IsArray():
+		for i, e := range v.ArrayValue() {
+			auditPropertyValue(e, fmt.Sprintf("%s[%d]", path, i), report)
+		}
+	case v.IsObject():
+		auditPropertyMap(v.ObjectValue(), path, report)
+	}
+}
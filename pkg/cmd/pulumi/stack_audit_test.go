@@ -0,0 +1,136 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func TestAuditLog(t *testing.T) {
+	key := []byte("secret-key")
+	checkpointHash := func(version int) string {
+		return "hash-" + strconv.Itoa(version)
+	}
+	update := func(version int, result backend.UpdateResult) backend.UpdateInfo {
+		return backend.UpdateInfo{
+			Version:         version,
+			Kind:            apitype.UpdateUpdate,
+			Result:          result,
+			StartTime:       int64(1000 * version),
+			EndTime:         int64(1000*version + 10),
+			Message:         "update " + strconv.Itoa(version),
+			ResourceChanges: engine.ResourceChanges{deploy.OpCreate: version},
+			Environment: map[string]string{
+				backend.BackendUser:    "alice",
+				backend.GitAuthor:      "Alice",
+				backend.GitAuthorEmail: "alice@example.com",
+			},
+		}
+	}
+
+	// History is newest first.
+	updates := []backend.UpdateInfo{update(2, backend.SucceededResult), update(1, backend.FailedResult)}
+	records, err := newAuditRecords("dev", updates, nil, checkpointHash, key)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, 1, records[0].Version)
+	assert.Equal(t, "failed", records[0].Result)
+	assert.Equal(t, "alice", records[0].User)
+	assert.Equal(t, "Alice <alice@example.com>", records[0].Identity)
+	assert.Equal(t, "hash-1", records[0].CheckpointHash)
+	assert.Equal(t, map[string]int{"create": 1}, records[0].ResourceChanges)
+	assert.Equal(t, "", records[0].Previous)
+	assert.Equal(t, records[0].Signature, records[1].Previous)
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, appendAuditRecords(path, records))
+
+	// Appending only adds updates that are new and have finished.
+	existing, err := readAuditLogFile(path, key)
+	require.NoError(t, err)
+	assert.Equal(t, records, existing)
+	updates = append([]backend.UpdateInfo{
+		update(4, backend.SucceededResult), update(3, backend.InProgressResult),
+	}, updates...)
+	more, err := newAuditRecords("dev", updates, existing, checkpointHash, key)
+	require.NoError(t, err)
+	assert.Empty(t, more)
+
+	updates[1].Result = backend.SucceededResult
+	more, err = newAuditRecords("dev", updates, existing, checkpointHash, key)
+	require.NoError(t, err)
+	require.Len(t, more, 2)
+	assert.Equal(t, 3, more[0].Version)
+	assert.Equal(t, records[1].Signature, more[0].Previous)
+	require.NoError(t, appendAuditRecords(path, more))
+
+	existing, err = readAuditLogFile(path, key)
+	require.NoError(t, err)
+	assert.Len(t, existing, 4)
+
+	// The log must match the stack and its history.
+	_, err = newAuditRecords("prod", updates, existing, checkpointHash, key)
+	assert.Error(t, err)
+	_, err = newAuditRecords("dev", updates[1:], existing, checkpointHash, key)
+	assert.Error(t, err)
+
+	// The log can't be read with the wrong key, or once it has been changed.
+	_, err = readAuditLogFile(path, []byte("other-key"))
+	assert.Error(t, err)
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	tampered := strings.Replace(lines[1], `"result":"succeeded"`, `"result":"failed"`, 1)
+	require.NotEqual(t, lines[1], tampered)
+	_, err = readAuditLog(strings.NewReader(lines[0]+"\n"+tampered+"\n"), key)
+	assert.Error(t, err)
+
+	_, err = readAuditLog(strings.NewReader(lines[0]+"\n"+lines[2]+"\n"), key)
+	assert.Error(t, err)
+
+	verified, err := readAuditLog(strings.NewReader(strings.Join(lines[:2], "\n")), key)
+	assert.NoError(t, err)
+	assert.Len(t, verified, 2)
+}
+
+func TestReadAuditSigningKey(t *testing.T) {
+	t.Setenv(auditSigningKeyEnvVar, "")
+	_, err := readAuditSigningKey("")
+	assert.Error(t, err)
+
+	t.Setenv(auditSigningKeyEnvVar, " from-env\n")
+	key, err := readAuditSigningKey("")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("from-env"), key)
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte("from-file\n"), 0600))
+	key, err = readAuditSigningKey(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("from-file"), key)
+}
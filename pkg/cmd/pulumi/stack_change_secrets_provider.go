@@ -15,26 +15,34 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 	"github.com/spf13/cobra"
 )
 
 func newStackChangeSecretsProviderCmd() *cobra.Command {
+	var reencrypt bool
+
 	var cmd = &cobra.Command{
 		Use:   "change-secrets-provider <new-secrets-provider>",
 		Args:  cmdutil.ExactArgs(1),
 		Short: "Change the secrets provider for the current stack",
 		Long: "Change the secrets provider for the current stack. " +
-			"Valid secret providers types are `default`, `passphrase`, `awskms`, `azurekeyvault`, `gcpkms`, `hashivault`.\n\n" +
+			"Valid secret providers types are `default`, `passphrase`, `awskms`, `azurekeyvault`, `gcpkms`, `hashivault`, " +
+			"`age`.\n\n" +
 			"To change to using the Pulumi Default Secrets Provider, use the following:\n" +
 			"\n" +
 			"pulumi stack change-secrets-provider default" +
@@ -50,7 +58,13 @@ func newStackChangeSecretsProviderCmd() *cobra.Command {
 			"\"azurekeyvault://mykeyvaultname.vault.azure.net/keys/mykeyname\"`\n" +
 			"* `pulumi stack change-secrets-provider " +
 			"\"gcpkms://projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>\"`\n" +
-			"* `pulumi stack change-secrets-provider \"hashivault://mykey\"`",
+			"* `pulumi stack change-secrets-provider \"hashivault://mykey\"`\n" +
+			"* `pulumi stack change-secrets-provider \"age://<recipient>,<recipient>\"`\n" +
+			"\n" +
+			"With `--reencrypt`, every secret in the stack's configuration and state is decrypted with the old\n" +
+			"secrets provider before anything is changed, and the re-encrypted values are checked to decrypt to\n" +
+			"the same values with the new provider before they are saved. If any step fails, the stack keeps its\n" +
+			"old secrets provider.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -93,6 +107,13 @@ func newStackChangeSecretsProviderCmd() *cobra.Command {
 
 			secretsProvider := args[0]
 			rotatePassphraseProvider := secretsProvider == "passphrase"
+			if reencrypt {
+				return reencryptStackSecrets(commandContext(), currentStack, currentProjectStack, decrypter,
+					func() error {
+						return createSecretsManager(b, currentStack.Ref(), secretsProvider, rotatePassphraseProvider)
+					})
+			}
+
 			// Create the new secrets provider and set to the currentStack
 			if err := createSecretsManager(b, currentStack.Ref(), secretsProvider, rotatePassphraseProvider); err != nil {
 				return err
@@ -104,9 +125,151 @@ func newStackChangeSecretsProviderCmd() *cobra.Command {
 		}),
 	}
 
+	cmd.Flags().BoolVar(
+		&reencrypt, "reencrypt", false,
+		"Decrypt every secret with the old secrets provider and verify that it is re-encrypted with the new one "+
+			"before saving the stack")
+
 	return cmd
 }
 
+// reencryptStackSecrets reconfigures the secrets provider of a stack with the given function, and re-encrypts every
+// secret in its config and state with the new provider. All secrets are decrypted with the old provider before the
+// stack is changed, and the re-encrypted config and state must decrypt to the same values before they are saved. If
+// any step fails after the provider has been reconfigured, the stack's previous settings are restored.
+func reencryptStackSecrets(ctx context.Context, s backend.Stack, ps *workspace.ProjectStack,
+	decrypter config.Decrypter, configure func() error) error {
+
+	plaintextConfig, err := ps.Config.Decrypt(decrypter)
+	if err != nil {
+		return fmt.Errorf("decrypting configuration with the current secrets provider: %w", err)
+	}
+
+	checkpoint, err := s.ExportDeployment(ctx)
+	if err != nil {
+		return err
+	}
+	snap, err := stack.DeserializeUntypedDeployment(checkpoint, stack.DefaultSecretsProvider)
+	if err != nil {
+		return checkDeploymentVersionError(err, s.Ref().Name().String())
+	}
+	plaintextState, err := snapshotPlaintext(snap)
+	if err != nil {
+		return fmt.Errorf("decrypting state with the current secrets provider: %w", err)
+	}
+
+	// Keep a copy of the stack's settings, which configuring the new provider changes, so that they can be restored.
+	original := *ps
+	original.Config = make(config.Map, len(ps.Config))
+	for k, v := range ps.Config {
+		original.Config[k] = v
+	}
+
+	fmt.Printf("Re-encrypting configuration and state\n")
+	err = configure()
+	if err == nil {
+		err = reencryptConfigAndCheckpoint(ctx, s, ps.Config, plaintextConfig, decrypter, snap, plaintextState)
+	}
+	if err != nil {
+		if restoreErr := saveProjectStack(s, &original); restoreErr != nil {
+			return multierror.Append(err, fmt.Errorf("restoring the previous secrets provider: %w", restoreErr))
+		}
+		return fmt.Errorf("%w; the stack still uses its previous secrets provider", err)
+	}
+	return nil
+}
+
+// reencryptConfigAndCheckpoint re-encrypts a stack's config and state with its newly configured secrets manager,
+// checks that they decrypt to the given plaintext, and then saves them.
+func reencryptConfigAndCheckpoint(ctx context.Context, s backend.Stack, cfg config.Map,
+	plaintextConfig map[config.Key]string, decrypter config.Decrypter, snap *deploy.Snapshot,
+	plaintextState []byte) error {
+
+	sm, err := getStackSecretsManager(s)
+	if err != nil {
+		return err
+	}
+	encrypter, err := sm.Encrypter()
+	if err != nil {
+		return err
+	}
+	newDecrypter, err := sm.Decrypter()
+	if err != nil {
+		return err
+	}
+
+	newConfig, err := cfg.Copy(decrypter, encrypter)
+	if err != nil {
+		return err
+	}
+	if err := verifyReencryptedConfig(newConfig, newDecrypter, plaintextConfig); err != nil {
+		return err
+	}
+
+	deployment, err := stack.SerializeDeployment(snap, sm, false /*showSecrets*/)
+	if err != nil {
+		return err
+	}
+	dep, err := json.Marshal(deployment)
+	if err != nil {
+		return err
+	}
+	untyped := apitype.UntypedDeployment{
+		Version:    apitype.DeploymentSchemaVersionCurrent,
+		Deployment: dep,
+	}
+	newSnap, err := stack.DeserializeUntypedDeployment(&untyped, stack.DefaultSecretsProvider)
+	if err != nil {
+		return fmt.Errorf("decrypting re-encrypted state: %w", err)
+	}
+	newPlaintextState, err := snapshotPlaintext(newSnap)
+	if err != nil {
+		return fmt.Errorf("decrypting re-encrypted state: %w", err)
+	}
+	if !bytes.Equal(plaintextState, newPlaintextState) {
+		return fmt.Errorf("re-encrypted state does not match the original state")
+	}
+
+	reloadedProjectStack, err := loadProjectStack(s)
+	if err != nil {
+		return err
+	}
+	for key, val := range newConfig {
+		if err := reloadedProjectStack.Config.Set(key, val, false); err != nil {
+			return err
+		}
+	}
+	if err := saveProjectStack(s, reloadedProjectStack); err != nil {
+		return err
+	}
+	return s.ImportDeployment(ctx, &untyped)
+}
+
+// verifyReencryptedConfig checks that a re-encrypted config decrypts to the given plaintext values.
+func verifyReencryptedConfig(cfg config.Map, decrypter config.Decrypter, plaintext map[config.Key]string) error {
+	decrypted, err := cfg.Decrypt(decrypter)
+	if err != nil {
+		return fmt.Errorf("decrypting re-encrypted configuration: %w", err)
+	}
+	if !reflect.DeepEqual(decrypted, plaintext) {
+		return fmt.Errorf("re-encrypted configuration does not match the original configuration")
+	}
+	return nil
+}
+
+// snapshotPlaintext serializes the resources and pending operations of a snapshot with their secrets in plaintext, so
+// that snapshots encrypted with different secrets managers can be compared.
+func snapshotPlaintext(snap *deploy.Snapshot) ([]byte, error) {
+	deployment, err := stack.SerializeDeployment(snap, nil, true /*showSecrets*/)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Resources         []apitype.ResourceV3  `json:"resources"`
+		PendingOperations []apitype.OperationV2 `json:"pendingOperations"`
+	}{deployment.Resources, deployment.PendingOperations})
+}
+
 func migrateOldConfigAndCheckpointToNewSecretsProvider(ctx context.Context, currentStack backend.Stack,
 	currentConfig config.Map, decrypter config.Decrypter) error {
 	// The order of operations here should be to load the secrets manager current stack
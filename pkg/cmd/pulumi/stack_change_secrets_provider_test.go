@@ -0,0 +1,53 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+)
+
+func TestVerifyReencryptedConfig(t *testing.T) {
+	oldCrypter := config.NewSymmetricCrypter(make([]byte, config.SymmetricCrypterKeyBytes))
+	newKey := make([]byte, config.SymmetricCrypterKeyBytes)
+	newKey[0] = 1
+	newCrypter := config.NewSymmetricCrypter(newKey)
+
+	secret, err := oldCrypter.EncryptValue("hunter2")
+	require.NoError(t, err)
+	cfg := config.Map{
+		config.MustMakeKey("test", "plain"):  config.NewValue("value"),
+		config.MustMakeKey("test", "secret"): config.NewSecureValue(secret),
+	}
+	plaintext, err := cfg.Decrypt(oldCrypter)
+	require.NoError(t, err)
+
+	reencrypted, err := cfg.Copy(oldCrypter, newCrypter)
+	require.NoError(t, err)
+	assert.NoError(t, verifyReencryptedConfig(reencrypted, newCrypter, plaintext))
+
+	// The re-encrypted config cannot be read with the old key.
+	assert.Error(t, verifyReencryptedConfig(reencrypted, oldCrypter, plaintext))
+
+	// A value that decrypts to something else is rejected.
+	wrong, err := newCrypter.EncryptValue("hunter3")
+	require.NoError(t, err)
+	reencrypted[config.MustMakeKey("test", "secret")] = config.NewSecureValue(wrong)
+	assert.Error(t, verifyReencryptedConfig(reencrypted, newCrypter, plaintext))
+}
@@ -0,0 +1,164 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// destroyTokenTTL is how long a destroy confirmation token can be used after it is issued.
+const destroyTokenTTL = time.Hour
+
+func newStackDestroyTokenCmd() *cobra.Command {
+	var stack string
+	var cmd = &cobra.Command{
+		Use:   "destroy-token",
+		Args:  cmdutil.NoArgs,
+		Short: "Issue a one-time token that confirms a destroy of a stack",
+		Long: "Issue a one-time token that confirms a destroy of a stack.\n" +
+			"\n" +
+			"Stacks whose settings set `requireDestroyConfirmation` can only be destroyed without a prompt if\n" +
+			"PULUMI_DESTROY_CONFIRMATION is set to a token issued by this command, even if `--yes` is passed. The\n" +
+			"token can be used once, within an hour, by a `pulumi destroy` of the same stack that shares this\n" +
+			"machine's Pulumi home directory. Only a hash of the token is stored, and issuing a new token revokes\n" +
+			"the previous one.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			token, err := issueDestroyToken(s.Ref().String(), time.Now())
+			if err != nil {
+				return err
+			}
+			fmt.Println(token)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// destroyToken records a destroy confirmation token that has been issued for a stack.
+type destroyToken struct {
+	// Stack is the stack whose destroy the token confirms.
+	Stack string `json:"stack"`
+	// Hash is the hex-encoded SHA-256 hash of the token.
+	Hash string `json:"hash"`
+	// Expires is the time after which the token can no longer be used.
+	Expires time.Time `json:"expires"`
+}
+
+// destroyTokenPath returns the path of the file that records the token issued for a stack.
+func destroyTokenPath(stack string) (string, error) {
+	sum := sha256.Sum256([]byte(stack))
+	return workspace.GetPulumiPath("destroy-tokens", hex.EncodeToString(sum[:])+".json")
+}
+
+// hashDestroyToken returns the hex-encoded SHA-256 hash of a token.
+func hashDestroyToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueDestroyToken generates a random token that confirms one destroy of the stack, and records its hash so that it
+// can be checked by consumeDestroyToken.
+func issueDestroyToken(stack string, now time.Time) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating destroy confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	path, err := destroyTokenPath(stack)
+	if err != nil {
+		return "", err
+	}
+	contents, err := json.Marshal(destroyToken{
+		Stack:   stack,
+		Hash:    hashDestroyToken(token),
+		Expires: now.Add(destroyTokenTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("creating destroy token directory: %w", err)
+	}
+	if err = ioutil.WriteFile(path, contents, 0600); err != nil {
+		return "", fmt.Errorf("recording destroy confirmation token: %w", err)
+	}
+	return token, nil
+}
+
+// consumeDestroyToken checks that the token was issued for the stack and has not expired, and then revokes it so that
+// it cannot confirm another destroy.
+func consumeDestroyToken(stack, token string, now time.Time) error {
+	path, err := destroyTokenPath(stack)
+	if err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no destroy confirmation token has been issued for stack '%s'; "+
+			"run `pulumi stack destroy-token` to issue one", stack)
+	} else if err != nil {
+		return fmt.Errorf("reading destroy confirmation token: %w", err)
+	}
+	var issued destroyToken
+	if err = json.Unmarshal(contents, &issued); err != nil {
+		return fmt.Errorf("reading destroy confirmation token: %w", err)
+	}
+
+	if now.After(issued.Expires) {
+		contract.IgnoreError(os.Remove(path))
+		return fmt.Errorf("the destroy confirmation token for stack '%s' has expired; "+
+			"run `pulumi stack destroy-token` to issue another", stack)
+	}
+	if issued.Stack != stack ||
+		subtle.ConstantTimeCompare([]byte(hashDestroyToken(token)), []byte(issued.Hash)) != 1 {
+		return fmt.Errorf("%s is not the destroy confirmation token issued for stack '%s'",
+			destroyConfirmationEnvVar, stack)
+	}
+
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("revoking destroy confirmation token: %w", err)
+	}
+	return nil
+}
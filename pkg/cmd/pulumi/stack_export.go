@@ -43,13 +43,20 @@ func newStackExportCmd() *cobra.Command {
 			"The deployment can then be hand-edited and used to update the stack via\n" +
 			"`pulumi stack import`. This process may be used to correct inconsistencies\n" +
 			"in a stack's state due to failed deployments, manual changes to cloud\n" +
-			"resources, etc.",
+			"resources, etc.\n" +
+			"\n" +
+			"Secrets are exported encrypted unless --show-secrets is passed. With --redact-secrets,\n" +
+			"they are replaced with stable hashes instead; such a deployment cannot be imported.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			ctx := commandContext()
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
+			if err := checkShowSecretsFlag(showSecrets); err != nil {
+				return err
+			}
+
 			// Fetch the current stack and export its deployment
 			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
 			if err != nil {
@@ -88,11 +95,16 @@ func newStackExportCmd() *cobra.Command {
 				}
 			}
 
-			if showSecrets {
+			if redactor := newSecretRedactor(s); showSecrets || redactor != nil {
 				snap, err := stack.DeserializeUntypedDeployment(deployment, stack.DefaultSecretsProvider)
 				if err != nil {
 					return checkDeploymentVersionError(err, stackName)
 				}
+				if redactor != nil {
+					if err := redactor.redactSnapshot(snap); err != nil {
+						return err
+					}
+				}
 
 				serializedDeployment, err := stack.SerializeDeployment(snap, snap.SecretsManager, true)
 				if err != nil {
@@ -127,7 +139,6 @@ func newStackExportCmd() *cobra.Command {
 		&file, "file", "", "", "A filename to write stack output to")
 	cmd.PersistentFlags().StringVarP(
 		&version, "version", "", "", "Previous stack version to export. (If unset, will export the latest.)")
-	cmd.Flags().BoolVarP(
-		&showSecrets, "show-secrets", "", false, "Emit secrets in plaintext in exported stack. Defaults to `false`")
+	addShowSecretsFlag(cmd, &showSecrets)
 	return cmd
 }
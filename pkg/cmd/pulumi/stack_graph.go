@@ -16,14 +16,19 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/pkg/v3/graph"
 	"github.com/pulumi/pulumi/pkg/v3/graph/dotconv"
+	"github.com/pulumi/pulumi/pkg/v3/graph/mermaidconv"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/spf13/cobra"
 )
@@ -34,14 +39,32 @@ var ignoreParentEdges bool
 // Whether or not we should ignore dependency edges when building up our graph.
 var ignoreDependencyEdges bool
 
+// Whether or not we should ignore provider edges when building up our graph.
+var ignoreProviderEdges bool
+
 // The color of dependency edges in the graph. Defaults to #246C60, a blush-green.
 var dependencyEdgeColor string
 
 // The color of parent edges in the graph. Defaults to #AA6639, an orange.
 var parentEdgeColor string
 
+// The color of provider edges in the graph. Defaults to #4A5C9B, a slate blue.
+var providerEdgeColor string
+
+// The kinds of edges that may appear in a stack's dependency graph.
+const (
+	parentEdgeKind             = "parent"
+	dependencyEdgeKind         = "dependency"
+	propertyDependencyEdgeKind = "property-dependency"
+	providerEdgeKind           = "provider"
+)
+
 func newStackGraphCmd() *cobra.Command {
 	var stackName string
+	var format string
+	var focus string
+	var depth int
+	var includeStackReferences bool
 
 	cmd := &cobra.Command{
 		Use:   "graph [filename]",
@@ -50,8 +73,19 @@ func newStackGraphCmd() *cobra.Command {
 		Long: "Export a stack's dependency graph to a file.\n" +
 			"\n" +
 			"This command can be used to view the dependency graph that a Pulumi program\n" +
-			"admitted when it was ran. This graph is output in the DOT format. This command operates\n" +
-			"on your stack's most recent deployment.",
+			"admitted when it was ran. This graph is output in the DOT format by default; pass\n" +
+			"`--format mermaid` to produce a Mermaid flowchart or `--format json` to produce a list of\n" +
+			"nodes and edges. This command operates on your stack's most recent deployment.\n" +
+			"\n" +
+			"Edges are labeled with their kind: parent, provider, or dependency. Dependency edges that\n" +
+			"were introduced by specific properties are labeled with the names of those properties.\n" +
+			"\n" +
+			"Use `--focus <urn>` to restrict the graph to the resources connected to a single resource,\n" +
+			"and `--depth` to limit how many edges away from that resource the graph extends.\n" +
+			"\n" +
+			"Use `--include-stack-references` to instead export a graph of stacks: the stacks of the current\n" +
+			"project and every stack that they reference, with an edge from each referenced stack to the stacks\n" +
+			"that read its outputs, labeled with the names of those outputs.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -71,13 +105,54 @@ func newStackGraphCmd() *cobra.Command {
 				return fmt.Errorf("unable to find snapshot for stack %q", stackName)
 			}
 
-			dg := makeDependencyGraph(snap)
+			var writeGraph func(w io.Writer) error
+			if includeStackReferences {
+				if focus != "" {
+					return fmt.Errorf("--focus may not be used with --include-stack-references")
+				}
+				proj, _, err := readProject()
+				if err != nil {
+					return err
+				}
+				ctx := commandContext()
+				summaries, err := listProjectStacks(ctx, s.Backend(), proj.Name)
+				if err != nil {
+					return err
+				}
+				names := []string{s.Ref().String()}
+				for _, summary := range summaries {
+					names = append(names, summary.Name().String())
+				}
+				sort.Strings(names[1:])
+
+				g, err := makeStackReferenceGraph(names, backendStackSnapshotLoader(ctx, s.Backend()))
+				if err != nil {
+					return err
+				}
+				writeGraph = func(w io.Writer) error { return printStackReferenceGraph(g, format, w) }
+			} else {
+				printGraph, err := graphPrinter(format)
+				if err != nil {
+					return err
+				}
+
+				dg := makeDependencyGraph(snap)
+				if focus != "" {
+					if dg, err = focusDependencyGraph(dg, snap, resource.URN(focus), depth); err != nil {
+						return err
+					}
+				} else if cmd.Flags().Changed("depth") {
+					return fmt.Errorf("--depth may only be used with --focus")
+				}
+				writeGraph = func(w io.Writer) error { return printGraph(dg, w) }
+			}
+
 			file, err := os.Create(args[0])
 			if err != nil {
 				return err
 			}
 
-			if err := dotconv.Print(dg, file); err != nil {
+			if err := writeGraph(file); err != nil {
 				_ = file.Close()
 				return err
 			}
@@ -93,13 +168,157 @@ func newStackGraphCmd() *cobra.Command {
 		"Ignores edges introduced by parent/child resource relationships")
 	cmd.PersistentFlags().BoolVar(&ignoreDependencyEdges, "ignore-dependency-edges", false,
 		"Ignores edges introduced by dependency resource relationships")
+	cmd.PersistentFlags().BoolVar(&ignoreProviderEdges, "ignore-provider-edges", false,
+		"Ignores edges introduced by resource provider relationships")
 	cmd.PersistentFlags().StringVar(&dependencyEdgeColor, "dependency-edge-color", "#246C60",
 		"Sets the color of dependency edges in the graph")
 	cmd.PersistentFlags().StringVar(&parentEdgeColor, "parent-edge-color", "#AA6639",
 		"Sets the color of parent edges in the graph")
+	cmd.PersistentFlags().StringVar(&providerEdgeColor, "provider-edge-color", "#4A5C9B",
+		"Sets the color of provider edges in the graph")
+	cmd.PersistentFlags().StringVar(&format, "format", "dot",
+		"The format of the graph: dot, mermaid, or json")
+	cmd.PersistentFlags().StringVar(&focus, "focus", "",
+		"Only include resources connected to the resource with this URN")
+	cmd.PersistentFlags().IntVar(&depth, "depth", -1,
+		"The maximum number of edges between the focused resource and any other resource in the graph; "+
+			"negative values mean no limit")
+	cmd.PersistentFlags().BoolVar(&includeStackReferences, "include-stack-references", false,
+		"Export a graph of the project's stacks and the stacks they reference instead of a graph of resources")
 	return cmd
 }
 
+// graphPrinter returns the function that writes a dependency graph in the given format.
+func graphPrinter(format string) (func(dg *dependencyGraph, w io.Writer) error, error) {
+	switch format {
+	case "", "dot":
+		return func(dg *dependencyGraph, w io.Writer) error { return dotconv.Print(dg, w) }, nil
+	case "mermaid":
+		return func(dg *dependencyGraph, w io.Writer) error { return mermaidconv.Print(dg, w) }, nil
+	case "json":
+		return printDependencyGraphJSON, nil
+	default:
+		return nil, fmt.Errorf("unknown graph format %q; expected dot, mermaid, or json", format)
+	}
+}
+
+// graphNodeJSON is the JSON representation of a vertex in a stack's dependency graph.
+type graphNodeJSON struct {
+	URN  resource.URN `json:"urn"`
+	Type tokens.Type  `json:"type"`
+	ID   resource.ID  `json:"id,omitempty"`
+}
+
+// graphEdgeJSON is the JSON representation of an edge in a stack's dependency graph.
+type graphEdgeJSON struct {
+	From       resource.URN `json:"from"`
+	To         resource.URN `json:"to"`
+	Kind       string       `json:"kind"`
+	Properties []string     `json:"properties,omitempty"`
+}
+
+// graphJSON is the JSON representation of a stack's dependency graph.
+type graphJSON struct {
+	Nodes []graphNodeJSON `json:"nodes"`
+	Edges []graphEdgeJSON `json:"edges"`
+}
+
+// printDependencyGraphJSON writes the given graph as a JSON document listing its nodes and edges.
+func printDependencyGraphJSON(dg *dependencyGraph, w io.Writer) error {
+	result := graphJSON{
+		Nodes: []graphNodeJSON{},
+		Edges: []graphEdgeJSON{},
+	}
+	for _, vertex := range dg.order {
+		result.Nodes = append(result.Nodes, graphNodeJSON{
+			URN:  vertex.resource.URN,
+			Type: vertex.resource.Type,
+			ID:   vertex.resource.ID,
+		})
+		for _, out := range vertex.outgoingEdges {
+			edge := graphEdgeJSON{
+				From: out.From().(*dependencyVertex).resource.URN,
+				To:   out.To().(*dependencyVertex).resource.URN,
+				Kind: edgeKind(out),
+			}
+			if dep, ok := out.(*dependencyEdge); ok {
+				edge.Properties = dep.labels
+			}
+			result.Edges = append(result.Edges, edge)
+		}
+	}
+
+	jsonStr, err := makeJSONString(result)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, jsonStr)
+	return err
+}
+
+// edgeKind returns the kind of the given edge.
+func edgeKind(edge graph.Edge) string {
+	switch edge := edge.(type) {
+	case *parentEdge:
+		return parentEdgeKind
+	case *providerEdge:
+		return providerEdgeKind
+	case *dependencyEdge:
+		if len(edge.labels) > 0 {
+			return propertyDependencyEdgeKind
+		}
+		return dependencyEdgeKind
+	default:
+		return ""
+	}
+}
+
+// focusDependencyGraph returns a new graph that contains only those resources that are reachable from the resource
+// with the given URN within the given number of edges. Edges are traversed regardless of their direction. A negative
+// depth places no limit on the distance between the focused resource and the rest of the graph.
+func focusDependencyGraph(dg *dependencyGraph, snap *deploy.Snapshot, urn resource.URN,
+	depth int) (*dependencyGraph, error) {
+
+	root, ok := dg.vertices[urn]
+	if !ok {
+		return nil, fmt.Errorf("no resource named '%s' found", urn)
+	}
+
+	distance := map[*dependencyVertex]int{root: 0}
+	frontier := []*dependencyVertex{root}
+	for len(frontier) > 0 {
+		vertex := frontier[0]
+		frontier = frontier[1:]
+		if depth >= 0 && distance[vertex] >= depth {
+			continue
+		}
+
+		var neighbors []*dependencyVertex
+		for _, in := range vertex.incomingEdges {
+			neighbors = append(neighbors, in.From().(*dependencyVertex))
+		}
+		for _, out := range vertex.outgoingEdges {
+			neighbors = append(neighbors, out.To().(*dependencyVertex))
+		}
+		for _, neighbor := range neighbors {
+			if _, seen := distance[neighbor]; !seen {
+				distance[neighbor] = distance[vertex] + 1
+				frontier = append(frontier, neighbor)
+			}
+		}
+	}
+
+	focused := &deploy.Snapshot{Manifest: snap.Manifest}
+	for _, res := range snap.Resources {
+		if vertex, ok := dg.vertices[res.URN]; ok {
+			if _, included := distance[vertex]; included {
+				focused.Resources = append(focused.Resources, res)
+			}
+		}
+	}
+	return makeDependencyGraph(focused), nil
+}
+
 // All of the types and code within this file are to provide implementations of the interfaces
 // in the `graph` package, so that we can use the `dotconv` package to output our graph in the
 // DOT format.
@@ -117,7 +336,11 @@ func (edge *dependencyEdge) Data() interface{} {
 	return nil
 }
 
+// Dependency edges are labeled with the properties that introduced them, if any.
 func (edge *dependencyEdge) Label() string {
+	if len(edge.labels) == 0 {
+		return dependencyEdgeKind
+	}
 	return strings.Join(edge.labels, ", ")
 }
 
@@ -145,9 +368,8 @@ func (edge *parentEdge) Data() interface{} {
 	return nil
 }
 
-// In this simple case, edges have no label.
 func (edge *parentEdge) Label() string {
-	return ""
+	return parentEdgeKind
 }
 
 func (edge *parentEdge) To() graph.Vertex {
@@ -162,6 +384,33 @@ func (edge *parentEdge) Color() string {
 	return parentEdgeColor
 }
 
+// providerEdges represent edges in the provider graph. An edge exists from
+// node A to node B if node A is the provider that manages node B.
+type providerEdge struct {
+	to   *dependencyVertex
+	from *dependencyVertex
+}
+
+func (edge *providerEdge) Data() interface{} {
+	return nil
+}
+
+func (edge *providerEdge) Label() string {
+	return providerEdgeKind
+}
+
+func (edge *providerEdge) To() graph.Vertex {
+	return edge.to
+}
+
+func (edge *providerEdge) From() graph.Vertex {
+	return edge.from
+}
+
+func (edge *providerEdge) Color() string {
+	return providerEdgeColor
+}
+
 // A dependencyVertex contains a reference to the graph to which it belongs
 // and to the resource state that it represents. Incoming and outgoing edges
 // are calculated on-demand using the combination of the graph and the state.
@@ -195,13 +444,14 @@ func (vertex *dependencyVertex) Outs() []graph.Edge {
 // the graph. It is constructed directly from a snapshot.
 type dependencyGraph struct {
 	vertices map[resource.URN]*dependencyVertex
+	order    []*dependencyVertex // the vertices in snapshot order, so that output is deterministic.
 }
 
 // Roots are edges that point to the root set of our graph. In our case,
 // for simplicity, we define the root set of our dependency graph to be everything.
 func (dg *dependencyGraph) Roots() []graph.Edge {
 	rootEdges := []graph.Edge{}
-	for _, vertex := range dg.vertices {
+	for _, vertex := range dg.order {
 		edge := &dependencyEdge{
 			to:   vertex,
 			from: nil,
@@ -227,9 +477,10 @@ func makeDependencyGraph(snapshot *deploy.Snapshot) *dependencyGraph {
 		}
 
 		dg.vertices[resource.URN] = vertex
+		dg.order = append(dg.order, vertex)
 	}
 
-	for _, vertex := range dg.vertices {
+	for _, vertex := range dg.order {
 		if !ignoreDependencyEdges {
 			// If we have per-property dependency information, annotate the dependency edges
 			// we generate with the names of the properties associated with each dependency.
@@ -241,10 +492,30 @@ func makeDependencyGraph(snapshot *deploy.Snapshot) *dependencyGraph {
 			}
 
 			// Incoming edges are directly stored within the checkpoint file; they represent
-			// resources on which this vertex immediately depends upon.
-			for _, dep := range vertex.resource.Dependencies {
-				vertexWeDependOn := vertex.graph.vertices[dep]
-				edge := &dependencyEdge{to: vertex, from: vertexWeDependOn, labels: depBlame[dep]}
+			// resources on which this vertex immediately depends upon. Dependencies that are
+			// only recorded at the property level are included as well.
+			deps := append([]resource.URN{}, vertex.resource.Dependencies...)
+			seen := make(map[resource.URN]bool)
+			for _, dep := range deps {
+				seen[dep] = true
+			}
+			var propertyOnly []resource.URN
+			for dep := range depBlame {
+				if !seen[dep] {
+					propertyOnly = append(propertyOnly, dep)
+				}
+			}
+			sort.Slice(propertyOnly, func(i, j int) bool { return propertyOnly[i] < propertyOnly[j] })
+			deps = append(deps, propertyOnly...)
+
+			for _, dep := range deps {
+				vertexWeDependOn, ok := vertex.graph.vertices[dep]
+				if !ok {
+					continue
+				}
+				labels := depBlame[dep]
+				sort.Strings(labels)
+				edge := &dependencyEdge{to: vertex, from: vertexWeDependOn, labels: labels}
 				vertex.incomingEdges = append(vertex.incomingEdges, edge)
 				vertexWeDependOn.outgoingEdges = append(vertexWeDependOn.outgoingEdges, edge)
 			}
@@ -255,11 +526,22 @@ func makeDependencyGraph(snapshot *deploy.Snapshot) *dependencyGraph {
 		// edges.
 		if !ignoreParentEdges {
 			if parent := vertex.resource.Parent; parent != resource.URN("") {
-				parentVertex := dg.vertices[parent]
-				vertex.outgoingEdges = append(vertex.outgoingEdges, &parentEdge{
-					to:   parentVertex,
-					from: vertex,
-				})
+				if parentVertex, ok := dg.vertices[parent]; ok {
+					edge := &parentEdge{to: parentVertex, from: vertex}
+					vertex.outgoingEdges = append(vertex.outgoingEdges, edge)
+					parentVertex.incomingEdges = append(parentVertex.incomingEdges, edge)
+				}
+			}
+		}
+
+		// Likewise, each custom resource is linked to the provider that manages it.
+		if !ignoreProviderEdges && vertex.resource.Provider != "" {
+			if ref, err := providers.ParseReference(vertex.resource.Provider); err == nil {
+				if providerVertex, ok := dg.vertices[ref.URN()]; ok {
+					edge := &providerEdge{to: vertex, from: providerVertex}
+					providerVertex.outgoingEdges = append(providerVertex.outgoingEdges, edge)
+					vertex.incomingEdges = append(vertex.incomingEdges, edge)
+				}
 			}
 		}
 	}
@@ -0,0 +1,285 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/graph"
+	"github.com/pulumi/pulumi/pkg/v3/graph/dotconv"
+	"github.com/pulumi/pulumi/pkg/v3/graph/mermaidconv"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+// stackSnapshotLoader returns the canonical name of the named stack and its latest snapshot. The snapshot is nil if
+// the stack does not exist or has never been deployed.
+type stackSnapshotLoader func(name string) (string, *deploy.Snapshot, error)
+
+// backendStackSnapshotLoader returns a stackSnapshotLoader that reads stacks from the given backend, or from the
+// backend named by a reference to a stack in another backend. Names that cannot be resolved are kept as they are,
+// without a snapshot.
+func backendStackSnapshotLoader(ctx context.Context, b backend.Backend) stackSnapshotLoader {
+	return func(name string) (string, *deploy.Snapshot, error) {
+		sb, ref, err := backend.ResolveStackReference(ctx, b, name)
+		if err != nil {
+			cmdutil.Diag().Warningf(diag.Message("", "could not resolve stack reference %q: %v"), name, err)
+			return name, nil, nil
+		}
+		canonical := ref.String()
+		if url, _, ok := backend.SplitStackReferenceName(name); ok {
+			canonical = url + "#" + canonical
+		}
+
+		s, err := sb.GetStack(ctx, ref)
+		if err != nil {
+			return "", nil, fmt.Errorf("getting stack %v: %w", canonical, err)
+		}
+		if s == nil {
+			return canonical, nil, nil
+		}
+		snap, err := s.Snapshot(ctx)
+		if err != nil {
+			return "", nil, fmt.Errorf("getting snapshot of stack %v: %w", canonical, err)
+		}
+		return canonical, snap, nil
+	}
+}
+
+// makeStackReferenceGraph builds the graph of the given stacks and every stack that they transitively reference. An
+// edge leads from each referenced stack to the stack that references it, and is labeled with the names of the
+// outputs that the reference read.
+func makeStackReferenceGraph(names []string, load stackSnapshotLoader) (*stackReferenceGraph, error) {
+	g := &stackReferenceGraph{vertices: map[string]*stackReferenceVertex{}}
+
+	// Canonicalizing a name loads its snapshot, so remember snapshots by both the given and canonical names.
+	type loaded struct {
+		name string
+		snap *deploy.Snapshot
+	}
+	cache := map[string]loaded{}
+	resolve := func(name string) (loaded, error) {
+		if l, ok := cache[name]; ok {
+			return l, nil
+		}
+		canonical, snap, err := load(name)
+		if err != nil {
+			return loaded{}, err
+		}
+		l := loaded{name: canonical, snap: snap}
+		cache[name], cache[canonical] = l, l
+		return l, nil
+	}
+
+	var frontier []loaded
+	enqueue := func(l loaded) *stackReferenceVertex {
+		if v, ok := g.vertices[l.name]; ok {
+			return v
+		}
+		v := &stackReferenceVertex{name: l.name, deployed: l.snap != nil}
+		g.vertices[l.name] = v
+		g.order = append(g.order, v)
+		frontier = append(frontier, l)
+		return v
+	}
+
+	for _, name := range names {
+		l, err := resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		enqueue(l)
+	}
+
+	for len(frontier) > 0 {
+		l := frontier[0]
+		frontier = frontier[1:]
+		if l.snap == nil {
+			continue
+		}
+
+		referencing := g.vertices[l.name]
+		for _, res := range l.snap.Resources {
+			if res.Type != stackReferenceType || res.Delete {
+				continue
+			}
+			name, ok := res.Inputs["name"]
+			if !ok || !name.IsString() {
+				continue
+			}
+			target, err := resolve(name.StringValue())
+			if err != nil {
+				return nil, err
+			}
+			referenced := enqueue(target)
+
+			var outputs []string
+			if out, ok := res.Outputs["outputs"]; ok && out.IsObject() {
+				for k := range out.ObjectValue() {
+					outputs = append(outputs, string(k))
+				}
+				sort.Strings(outputs)
+			}
+
+			edge := &stackReferenceEdge{from: referenced, to: referencing, outputs: outputs}
+			referenced.outs = append(referenced.outs, edge)
+			referencing.ins = append(referencing.ins, edge)
+		}
+	}
+
+	return g, nil
+}
+
+// printStackReferenceGraph writes a graph of stack references in the given format.
+func printStackReferenceGraph(g *stackReferenceGraph, format string, w io.Writer) error {
+	switch format {
+	case "", "dot":
+		return dotconv.Print(g, w)
+	case "mermaid":
+		return mermaidconv.Print(g, w)
+	case "json":
+		return printStackReferenceGraphJSON(g, w)
+	default:
+		return fmt.Errorf("unknown graph format %q; expected dot, mermaid, or json", format)
+	}
+}
+
+// stackReferenceNodeJSON is the JSON representation of a stack in a graph of stack references.
+type stackReferenceNodeJSON struct {
+	Stack    string `json:"stack"`
+	Deployed bool   `json:"deployed"`
+}
+
+// stackReferenceEdgeJSON is the JSON representation of a reference from one stack to another. From is the referenced
+// stack, and To the stack that references it, as in a stack's dependency graph.
+type stackReferenceEdgeJSON struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Kind    string   `json:"kind"`
+	Outputs []string `json:"outputs,omitempty"`
+}
+
+// stackReferenceGraphJSON is the JSON representation of a graph of stack references.
+type stackReferenceGraphJSON struct {
+	Nodes []stackReferenceNodeJSON `json:"nodes"`
+	Edges []stackReferenceEdgeJSON `json:"edges"`
+}
+
+// printStackReferenceGraphJSON writes the given graph as a JSON document listing its stacks and references.
+func printStackReferenceGraphJSON(g *stackReferenceGraph, w io.Writer) error {
+	result := stackReferenceGraphJSON{
+		Nodes: []stackReferenceNodeJSON{},
+		Edges: []stackReferenceEdgeJSON{},
+	}
+	for _, vertex := range g.order {
+		result.Nodes = append(result.Nodes, stackReferenceNodeJSON{Stack: vertex.name, Deployed: vertex.deployed})
+		for _, out := range vertex.outs {
+			edge := out.(*stackReferenceEdge)
+			result.Edges = append(result.Edges, stackReferenceEdgeJSON{
+				From:    edge.from.name,
+				To:      edge.to.name,
+				Kind:    stackReferenceEdgeKind,
+				Outputs: edge.outputs,
+			})
+		}
+	}
+
+	jsonStr, err := makeJSONString(result)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, jsonStr)
+	return err
+}
+
+// stackReferenceEdgeKind is the kind of the edges in a graph of stack references.
+const stackReferenceEdgeKind = "stack-reference"
+
+// `stackReferenceGraph` implements graph.Graph, `stackReferenceVertex` implements graph.Vertex, and
+// `stackReferenceEdge` implements graph.Edge, so that graphs of stacks can be printed like dependency graphs.
+type stackReferenceGraph struct {
+	vertices map[string]*stackReferenceVertex
+	order    []*stackReferenceVertex // the vertices in the order they were found, so that output is deterministic.
+}
+
+func (g *stackReferenceGraph) Roots() []graph.Edge {
+	rootEdges := []graph.Edge{}
+	for _, vertex := range g.order {
+		rootEdges = append(rootEdges, &stackReferenceEdge{to: vertex})
+	}
+	return rootEdges
+}
+
+type stackReferenceVertex struct {
+	name     string
+	deployed bool
+	ins      []graph.Edge
+	outs     []graph.Edge
+}
+
+func (vertex *stackReferenceVertex) Data() interface{} {
+	return vertex.name
+}
+
+func (vertex *stackReferenceVertex) Label() string {
+	return vertex.name
+}
+
+func (vertex *stackReferenceVertex) Ins() []graph.Edge {
+	return vertex.ins
+}
+
+func (vertex *stackReferenceVertex) Outs() []graph.Edge {
+	return vertex.outs
+}
+
+type stackReferenceEdge struct {
+	from    *stackReferenceVertex
+	to      *stackReferenceVertex
+	outputs []string
+}
+
+func (edge *stackReferenceEdge) Data() interface{} {
+	return nil
+}
+
+// Stack reference edges are labeled with the outputs that the reference read, if any.
+func (edge *stackReferenceEdge) Label() string {
+	if len(edge.outputs) == 0 {
+		return stackReferenceEdgeKind
+	}
+	return strings.Join(edge.outputs, ", ")
+}
+
+func (edge *stackReferenceEdge) To() graph.Vertex {
+	return edge.to
+}
+
+func (edge *stackReferenceEdge) From() graph.Vertex {
+	if edge.from == nil {
+		return nil
+	}
+	return edge.from
+}
+
+func (edge *stackReferenceEdge) Color() string {
+	return dependencyEdgeColor
+}
@@ -0,0 +1,91 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func newStackReferenceSnapshot(refs map[string][]string) *deploy.Snapshot {
+	snap := &deploy.Snapshot{}
+	for name, outputs := range refs {
+		outs := resource.PropertyMap{}
+		for _, o := range outputs {
+			outs[resource.PropertyKey(o)] = resource.NewStringProperty("value")
+		}
+		snap.Resources = append(snap.Resources, &resource.State{
+			Type:   stackReferenceType,
+			URN:    resource.URN("urn:pulumi:test::test::" + string(stackReferenceType) + "::" + name),
+			Custom: true,
+			ID:     resource.ID(name),
+			Inputs: resource.PropertyMap{"name": resource.NewStringProperty(name)},
+			Outputs: resource.PropertyMap{
+				"name":    resource.NewStringProperty(name),
+				"outputs": resource.NewObjectProperty(outs),
+			},
+		})
+	}
+	return snap
+}
+
+func TestMakeStackReferenceGraph(t *testing.T) {
+	// app references network and db; db references network; network references a stack in another backend.
+	snaps := map[string]*deploy.Snapshot{
+		"org/proj/app":     newStackReferenceSnapshot(map[string][]string{"db": {"connectionString"}}),
+		"org/proj/db":      newStackReferenceSnapshot(map[string][]string{"network": {"subnetId", "vpcId"}}),
+		"org/proj/network": newStackReferenceSnapshot(map[string][]string{"other/shared/dns": {"zoneId"}}),
+	}
+	load := func(name string) (string, *deploy.Snapshot, error) {
+		if !strings.Contains(name, "/") {
+			name = "org/proj/" + name
+		}
+		return name, snaps[name], nil
+	}
+
+	g, err := makeStackReferenceGraph([]string{"org/proj/app"}, load)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, printStackReferenceGraph(g, "json", &buf))
+
+	var result stackReferenceGraphJSON
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, []stackReferenceNodeJSON{
+		{Stack: "org/proj/app", Deployed: true},
+		{Stack: "org/proj/db", Deployed: true},
+		{Stack: "org/proj/network", Deployed: true},
+		{Stack: "other/shared/dns", Deployed: false},
+	}, result.Nodes)
+	assert.Equal(t, []stackReferenceEdgeJSON{
+		{From: "org/proj/db", To: "org/proj/app", Kind: stackReferenceEdgeKind, Outputs: []string{"connectionString"}},
+		{From: "org/proj/network", To: "org/proj/db", Kind: stackReferenceEdgeKind, Outputs: []string{"subnetId", "vpcId"}},
+		{From: "other/shared/dns", To: "org/proj/network", Kind: stackReferenceEdgeKind, Outputs: []string{"zoneId"}},
+	}, result.Edges)
+
+	buf.Reset()
+	require.NoError(t, printStackReferenceGraph(g, "mermaid", &buf))
+	assert.Contains(t, buf.String(), `-->|"subnetId, vpcId"|`)
+
+	assert.Error(t, printStackReferenceGraph(g, "svg", &buf))
+}
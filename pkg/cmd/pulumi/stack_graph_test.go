@@ -0,0 +1,158 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func newGraphTestSnapshot() *deploy.Snapshot {
+	urn := func(typ, name string) resource.URN {
+		return resource.URN("urn:pulumi:test::test::" + typ + "::" + name)
+	}
+	root := urn("pulumi:pulumi:Stack", "test-test")
+	prov := urn("pulumi:providers:pkgA", "default")
+	a := urn("pkgA:m:typA", "a")
+	b := urn("pkgA:m:typB", "b")
+	c := urn("pkgA:m:typA", "c")
+
+	return &deploy.Snapshot{
+		Resources: []*resource.State{
+			{Type: "pulumi:pulumi:Stack", URN: root},
+			{Type: "pulumi:providers:pkgA", URN: prov, Custom: true, ID: "0", Parent: root},
+			{Type: "pkgA:m:typA", URN: a, Custom: true, ID: "a", Parent: root, Provider: string(prov) + "::0"},
+			{
+				Type: "pkgA:m:typB", URN: b, Custom: true, ID: "b", Parent: root, Provider: string(prov) + "::0",
+				Dependencies:         []resource.URN{a},
+				PropertyDependencies: map[resource.PropertyKey][]resource.URN{"foo": {a}, "bar": {a}},
+			},
+			{
+				Type: "pkgA:m:typA", URN: c, Custom: true, ID: "c", Parent: root, Provider: string(prov) + "::0",
+				Dependencies: []resource.URN{b},
+			},
+		},
+	}
+}
+
+func TestStackGraphJSON(t *testing.T) {
+	snap := newGraphTestSnapshot()
+	dg := makeDependencyGraph(snap)
+
+	var buf bytes.Buffer
+	require.NoError(t, printDependencyGraphJSON(dg, &buf))
+
+	var result graphJSON
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Len(t, result.Nodes, 5)
+
+	kinds := make(map[string]int)
+	for _, edge := range result.Edges {
+		kinds[edge.Kind]++
+		if edge.Kind == propertyDependencyEdgeKind {
+			assert.Equal(t, snap.Resources[2].URN, edge.From)
+			assert.Equal(t, snap.Resources[3].URN, edge.To)
+			assert.Equal(t, []string{"bar", "foo"}, edge.Properties)
+		}
+	}
+	assert.Equal(t, map[string]int{
+		parentEdgeKind:             4,
+		providerEdgeKind:           3,
+		dependencyEdgeKind:         1,
+		propertyDependencyEdgeKind: 1,
+	}, kinds)
+}
+
+func TestStackGraphMermaid(t *testing.T) {
+	printGraph, err := graphPrinter("mermaid")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, printGraph(makeDependencyGraph(newGraphTestSnapshot()), &buf))
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "graph TD\n"))
+	assert.Contains(t, out, `-->|"bar, foo"|`)
+	assert.Contains(t, out, `-->|"provider"|`)
+	assert.Contains(t, out, `-->|"parent"|`)
+	assert.Contains(t, out, "linkStyle 0 stroke:")
+
+	_, err = graphPrinter("svg")
+	assert.Error(t, err)
+}
+
+func TestFocusDependencyGraph(t *testing.T) {
+	snap := newGraphTestSnapshot()
+	c := snap.Resources[4].URN
+
+	urns := func(dg *dependencyGraph) []resource.URN {
+		var result []resource.URN
+		for _, vertex := range dg.order {
+			result = append(result, vertex.resource.URN)
+		}
+		return result
+	}
+
+	// With a depth of one, only c's immediate neighbors are included: its parent, provider and dependency.
+	dg, err := focusDependencyGraph(makeDependencyGraph(snap), snap, c, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []resource.URN{
+		snap.Resources[0].URN, snap.Resources[1].URN, snap.Resources[3].URN, c,
+	}, urns(dg))
+
+	// With no limit, everything connected to c is included.
+	dg, err = focusDependencyGraph(makeDependencyGraph(snap), snap, c, -1)
+	require.NoError(t, err)
+	assert.Len(t, urns(dg), 5)
+
+	// A depth of zero includes only the focused resource.
+	dg, err = focusDependencyGraph(makeDependencyGraph(snap), snap, c, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []resource.URN{c}, urns(dg))
+
+	_, err = focusDependencyGraph(makeDependencyGraph(snap), snap, "urn:pulumi:test::test::pkgA:m:typA::missing", 1)
+	assert.Error(t, err)
+}
+
+func TestStackGraphPropertyOnlyDependencies(t *testing.T) {
+	a := resource.URN("urn:pulumi:test::test::pkgA:m:typA::a")
+	b := resource.URN("urn:pulumi:test::test::pkgA:m:typA::b")
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{Type: "pkgA:m:typA", URN: a, Custom: true, ID: "a"},
+			{
+				Type: "pkgA:m:typA", URN: b, Custom: true, ID: "b",
+				PropertyDependencies: map[resource.PropertyKey][]resource.URN{"foo": {a}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, printDependencyGraphJSON(makeDependencyGraph(snap), &buf))
+
+	var result graphJSON
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+	assert.Equal(t, []graphEdgeJSON{
+		{From: a, To: b, Kind: propertyDependencyEdgeKind, Properties: []string{"foo"}},
+	}, result.Edges)
+}
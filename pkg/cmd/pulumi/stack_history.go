@@ -13,6 +13,9 @@ import (
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
@@ -27,6 +30,10 @@ func newStackHistoryCmd() *cobra.Command {
 	var pageSize int
 	var page int
 	var showFullDates bool
+	var filters []string
+	var since string
+	var user string
+	var detailed bool
 
 	cmd := &cobra.Command{
 		Use:        "history",
@@ -35,11 +42,27 @@ func newStackHistoryCmd() *cobra.Command {
 		Short:      "[PREVIEW] Display history for a stack",
 		Long: `Display history for a stack
 
-This command displays data about previous updates for a stack.`,
+This command displays data about previous updates for a stack.
+
+The updates shown can be narrowed with --filter, which accepts 'result=<result>' (one of
+'succeeded', 'failed' or 'in-progress') and 'kind=<kind>' (e.g. 'update' or 'destroy'), with
+--since, which accepts a relative duration ('3h', '72h') or an absolute timestamp, and with --user,
+which matches the git author or committer recorded for each update. Filters apply to the page of
+updates selected by --page and --page-size.
+
+Pass --detailed along with --json to also include each update's duration, user, total number of
+resource changes, stack output changes, and its environment metadata grouped by category.`,
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
 			}
+			if detailed && !jsonOut {
+				return fmt.Errorf("--detailed may only be used with --json")
+			}
+			filter, err := newHistoryFilter(filters, since, user, time.Now())
+			if err != nil {
+				return err
+			}
 			s, err := requireStack(stack, false /*offerNew */, opts, false /*setCurrent*/)
 			if err != nil {
 				return err
@@ -49,8 +72,17 @@ This command displays data about previous updates for a stack.`,
 			if err != nil {
 				return fmt.Errorf("getting history: %w", err)
 			}
+			matched := filterUpdates(updates, filter)
+			if err := checkShowSecretsFlag(showSecrets); err != nil {
+				return err
+			}
+
+			var redactor *secretRedactor
+			if jsonOut {
+				redactor = newSecretRedactor(s)
+			}
 			var decrypter config.Decrypter
-			if showSecrets {
+			if showSecrets || redactor != nil {
 				crypter, err := getStackDecrypter(s)
 				if err != nil {
 					return fmt.Errorf("decrypting secrets: %w", err)
@@ -59,19 +91,21 @@ This command displays data about previous updates for a stack.`,
 			}
 
 			if jsonOut {
-				return displayUpdatesJSON(updates, decrypter)
+				return displayUpdatesJSON(matched, decrypter, redactor, detailed)
 			}
 
-			return displayUpdatesConsole(updates, page, opts, showFullDates)
+			if len(matched) == 0 && len(updates) > 0 {
+				fmt.Println("No stack updates match the given filters")
+				return nil
+			}
+			return displayUpdatesConsole(matched, page, opts, showFullDates)
 		}),
 	}
 
 	cmd.PersistentFlags().StringVarP(
 		&stack, "stack", "s", "",
 		"Choose a stack other than the currently selected one")
-	cmd.Flags().BoolVar(
-		&showSecrets, "show-secrets", false,
-		"Show secret values when listing config instead of displaying blinded values")
+	addShowSecretsFlag(cmd, &showSecrets)
 	cmd.PersistentFlags().BoolVarP(
 		&jsonOut, "json", "j", false, "Emit output as JSON")
 	cmd.PersistentFlags().BoolVar(
@@ -80,6 +114,19 @@ This command displays data about previous updates for a stack.`,
 		&pageSize, "page-size", 10, "Used with 'page' to control number of results returned")
 	cmd.PersistentFlags().IntVar(
 		&page, "page", 1, "Used with 'page-size' to paginate results")
+	cmd.PersistentFlags().StringArrayVar(
+		&filters, "filter", nil,
+		"Only show updates matching the given 'key=value' filter, where key is 'result' or 'kind'. May be repeated")
+	cmd.PersistentFlags().StringVar(
+		&since, "since", "",
+		"Only show updates started after a relative duration ('5m', '3h', '72h') or absolute timestamp")
+	cmd.PersistentFlags().StringVar(
+		&user, "user", "", "Only show updates whose git author or committer name or email matches this value")
+	cmd.PersistentFlags().BoolVar(
+		&detailed, "detailed", false,
+		"Used with 'json' to include durations, users, change totals, output changes and grouped environment metadata")
+
+	cmd.AddCommand(newStackHistoryDiffCmd())
 	return cmd
 }
 
@@ -97,9 +144,159 @@ type updateInfoJSON struct {
 	// These values are only present once the update finishes
 	EndTime         *string         `json:"endTime,omitempty"`
 	ResourceChanges *map[string]int `json:"resourceChanges,omitempty"`
+
+	// These values are only present when --detailed is passed
+	Details *updateDetailsJSON `json:"details,omitempty"`
+}
+
+// updateDetailsJSON is the extra information about an update included in the --json --detailed output.
+type updateDetailsJSON struct {
+	User               string                       `json:"user,omitempty"`
+	DurationSeconds    *int64                       `json:"durationSeconds,omitempty"`
+	TotalChanges       *int                         `json:"totalResourceChanges,omitempty"`
+	OutputChanges      engine.OutputChanges         `json:"outputChanges,omitempty"`
+	EnvironmentDetails map[string]map[string]string `json:"environmentDetails,omitempty"`
+}
+
+// historyFilter selects the updates shown by `pulumi stack history`. An empty filter matches every update.
+type historyFilter struct {
+	results []backend.UpdateResult
+	kinds   []apitype.UpdateKind
+	since   *time.Time
+	user    string
+}
+
+// newHistoryFilter builds a historyFilter from the values of the --filter, --since and --user flags.
+func newHistoryFilter(filters []string, since, user string, now time.Time) (historyFilter, error) {
+	var filter historyFilter
+	for _, f := range filters {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return historyFilter{}, fmt.Errorf("invalid filter %q: expected 'key=value'", f)
+		}
+		switch key, value := kv[0], kv[1]; key {
+		case "result":
+			result := backend.UpdateResult(value)
+			switch result {
+			case backend.SucceededResult, backend.FailedResult, backend.InProgressResult:
+				filter.results = append(filter.results, result)
+			default:
+				return historyFilter{}, fmt.Errorf(
+					"invalid result %q: expected one of 'succeeded', 'failed' or 'in-progress'", value)
+			}
+		case "kind":
+			filter.kinds = append(filter.kinds, apitype.UpdateKind(value))
+		default:
+			return historyFilter{}, fmt.Errorf("unknown filter key %q: expected 'result' or 'kind'", key)
+		}
+	}
+
+	if since != "" {
+		startTime, err := parseSince(since, now)
+		if err != nil {
+			return historyFilter{}, fmt.Errorf(
+				"failed to parse argument to '--since' as duration or timestamp: %w", err)
+		}
+		filter.since = startTime
+	}
+
+	filter.user = strings.TrimSpace(user)
+	return filter, nil
 }
 
-func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter) error {
+// matches returns true if the given update satisfies every part of the filter. Repeated values for the same key
+// match if any of them match.
+func (f historyFilter) matches(update backend.UpdateInfo) bool {
+	if len(f.results) > 0 {
+		found := false
+		for _, result := range f.results {
+			found = found || update.Result == result
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.kinds) > 0 {
+		found := false
+		for _, kind := range f.kinds {
+			found = found || update.Kind == kind
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.since != nil && time.Unix(update.StartTime, 0).Before(*f.since) {
+		return false
+	}
+	if f.user != "" {
+		found := false
+		for _, key := range []string{
+			backend.GitAuthor, backend.GitAuthorEmail, backend.GitCommitter, backend.GitCommitterEmail,
+		} {
+			found = found || strings.EqualFold(update.Environment[key], f.user)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// filterUpdates returns the updates that match the given filter, preserving their order.
+func filterUpdates(updates []backend.UpdateInfo, filter historyFilter) []backend.UpdateInfo {
+	matched := []backend.UpdateInfo{}
+	for _, update := range updates {
+		if filter.matches(update) {
+			matched = append(matched, update)
+		}
+	}
+	return matched
+}
+
+// getUpdateDetails returns the extra information about an update that is included in the --json --detailed output.
+func getUpdateDetails(update backend.UpdateInfo) *updateDetailsJSON {
+	details := &updateDetailsJSON{
+		User:          update.Environment[backend.GitAuthor],
+		OutputChanges: update.OutputChanges,
+	}
+	if details.User == "" {
+		details.User = update.Environment[backend.GitCommitter]
+	}
+
+	if update.Result != backend.InProgressResult {
+		duration := update.EndTime - update.StartTime
+		details.DurationSeconds = &duration
+
+		total := 0
+		for op, count := range update.ResourceChanges {
+			if op != deploy.OpSame {
+				total += count
+			}
+		}
+		details.TotalChanges = &total
+	}
+
+	// Group the environment by the category that prefixes each key, e.g. "git.head" is reported as "head" under
+	// "git" and "toolchain.cli.version" as "cli.version" under "toolchain".
+	if len(update.Environment) > 0 {
+		details.EnvironmentDetails = make(map[string]map[string]string)
+		for k, v := range update.Environment {
+			category, name := "other", k
+			if idx := strings.Index(k, "."); idx > 0 {
+				category, name = k[:idx], k[idx+1:]
+			}
+			if details.EnvironmentDetails[category] == nil {
+				details.EnvironmentDetails[category] = make(map[string]string)
+			}
+			details.EnvironmentDetails[category][name] = v
+		}
+	}
+	return details
+}
+
+func displayUpdatesJSON(
+	updates []backend.UpdateInfo, decrypter config.Decrypter, redactor *secretRedactor, detailed bool) error {
+
 	makeStringRef := func(s string) *string {
 		return &s
 	}
@@ -136,6 +333,9 @@ func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter
 					}
 					configValue.ObjectValue = obj
 				}
+				if err == nil && redactor != nil {
+					configValue = redactor.redactConfigValue(configValue, value)
+				}
 			}
 			info.Config[k.String()] = configValue
 		}
@@ -148,6 +348,9 @@ func displayUpdatesJSON(updates []backend.UpdateInfo, decrypter config.Decrypter
 			}
 			info.ResourceChanges = &resourceChanges
 		}
+		if detailed {
+			info.Details = getUpdateDetails(update)
+		}
 		updatesJSON[idx] = info
 	}
 
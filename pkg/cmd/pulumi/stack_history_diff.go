@@ -0,0 +1,188 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func newStackHistoryDiffCmd() *cobra.Command {
+	var stackName string
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <from-version> <to-version>",
+		Args:  cmdutil.ExactArgs(2),
+		Short: "Show how a stack's resources changed between two versions",
+		Long: "Show how a stack's resources changed between two versions.\n" +
+			"\n" +
+			"This command retrieves the deployments that the backend recorded for the two given\n" +
+			"versions of the stack, as listed by `pulumi stack history`, and shows the resources\n" +
+			"that were added, removed or changed between them, along with the names of the\n" +
+			"properties that changed.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			ctx := commandContext()
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			from, err := getSnapshotForVersion(ctx, s, args[0])
+			if err != nil {
+				return err
+			}
+			to, err := getSnapshotForVersion(ctx, s, args[1])
+			if err != nil {
+				return err
+			}
+
+			diffs := diffSnapshotProperties(from, to)
+			if jsonOut {
+				return printJSON(historyDiffJSON{From: args[0], To: args[1], Resources: diffs})
+			}
+
+			if len(diffs) == 0 {
+				fmt.Printf("The resources of stack '%s' did not change between versions %s and %s\n",
+					s.Ref(), args[0], args[1])
+				return nil
+			}
+			fmt.Printf("Between versions %s and %s, %d resource(s) of stack '%s' changed:\n",
+				args[0], args[1], len(diffs), s.Ref())
+			printResourceDiffs(opts, diffs)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+
+	return cmd
+}
+
+// getSnapshotForVersion returns the snapshot that the backend recorded for the given version of the stack.
+func getSnapshotForVersion(ctx context.Context, s backend.Stack, version string) (*deploy.Snapshot, error) {
+	be := s.Backend()
+	specificExpBE, ok := be.(backend.SpecificDeploymentExporter)
+	if !ok {
+		return nil, fmt.Errorf(
+			"the current backend (%s) does not provide the ability to export previous deployments", be.Name())
+	}
+
+	deployment, err := specificExpBE.ExportDeploymentForVersion(ctx, s, version)
+	if err != nil {
+		return nil, fmt.Errorf("getting version %s: %w", version, err)
+	}
+	snap, err := stack.DeserializeUntypedDeployment(deployment, stack.DefaultSecretsProvider)
+	if err != nil {
+		return nil, checkDeploymentVersionError(err, s.Ref().Name().String())
+	}
+	return snap, nil
+}
+
+// historyDiffJSON is the shape of the --json output of `pulumi stack history diff`.
+type historyDiffJSON struct {
+	From      string             `json:"from"`
+	To        string             `json:"to"`
+	Resources []resourceDiffJSON `json:"resources"`
+}
+
+// resourceDiffJSON describes how a single resource changed between two snapshots. Properties are only listed for
+// resources that changed, and are the names of the top-level outputs that were added, removed or changed.
+type resourceDiffJSON struct {
+	URN     resource.URN  `json:"urn"`
+	Op      deploy.StepOp `json:"op"`
+	Added   []string      `json:"added,omitempty"`
+	Removed []string      `json:"removed,omitempty"`
+	Changed []string      `json:"changed,omitempty"`
+}
+
+// diffSnapshotProperties returns the resources that changed between the snapshots from and to, along with the
+// properties of each changed resource that changed.
+func diffSnapshotProperties(from, to *deploy.Snapshot) []resourceDiffJSON {
+	liveResources := func(snap *deploy.Snapshot) map[resource.URN]*resource.State {
+		byURN := make(map[resource.URN]*resource.State)
+		if snap == nil {
+			return byURN
+		}
+		for _, res := range snap.Resources {
+			if !res.Delete {
+				byURN[res.URN] = res
+			}
+		}
+		return byURN
+	}
+	fromByURN, toByURN := liveResources(from), liveResources(to)
+
+	diffs := []resourceDiffJSON{}
+	for _, change := range diffSnapshotResources(from, to) {
+		diff := resourceDiffJSON{URN: change.urn, Op: change.op}
+		if change.op == deploy.OpUpdate {
+			if objDiff := fromByURN[change.urn].Outputs.Diff(toByURN[change.urn].Outputs); objDiff != nil {
+				diff.Added = sortedPropertyKeys(objDiff.Adds)
+				diff.Removed = sortedPropertyKeys(objDiff.Deletes)
+				for k := range objDiff.Updates {
+					diff.Changed = append(diff.Changed, string(k))
+				}
+				sort.Strings(diff.Changed)
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func sortedPropertyKeys(props resource.PropertyMap) []string {
+	var keys []string
+	for _, k := range props.StableKeys() {
+		keys = append(keys, string(k))
+	}
+	return keys
+}
+
+// printResourceDiffs prints one line per changed resource, followed by a line per changed property.
+func printResourceDiffs(opts display.Options, diffs []resourceDiffJSON) {
+	for _, diff := range diffs {
+		fmt.Print(opts.Color.Colorize(
+			fmt.Sprintf("    %s%s %s%s\n", diff.Op.Color(), diff.Op.RawPrefix(), diff.URN, colors.Reset)))
+		for _, props := range []struct {
+			op   deploy.StepOp
+			keys []string
+		}{{deploy.OpCreate, diff.Added}, {deploy.OpDelete, diff.Removed}, {deploy.OpUpdate, diff.Changed}} {
+			for _, k := range props.keys {
+				fmt.Print(opts.Color.Colorize(
+					fmt.Sprintf("        %s%s%s%s\n", props.op.Color(), props.op.RawPrefix(), k, colors.Reset)))
+			}
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,70 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestDiffSnapshotProperties(t *testing.T) {
+	newState := func(name string, outputs resource.PropertyMap) *resource.State {
+		return &resource.State{
+			Type:    "pkgA:m:typA",
+			URN:     resource.URN("urn:pulumi:test::test::pkgA:m:typA::" + name),
+			Custom:  true,
+			ID:      resource.ID(name),
+			Inputs:  resource.PropertyMap{},
+			Outputs: outputs,
+		}
+	}
+
+	from := &deploy.Snapshot{Resources: []*resource.State{
+		newState("same", resource.PropertyMap{"a": resource.NewNumberProperty(1)}),
+		newState("changed", resource.PropertyMap{
+			"kept":    resource.NewStringProperty("x"),
+			"edited":  resource.NewStringProperty("old"),
+			"dropped": resource.NewBoolProperty(true),
+		}),
+		newState("removed", resource.PropertyMap{}),
+	}}
+	to := &deploy.Snapshot{Resources: []*resource.State{
+		newState("same", resource.PropertyMap{"a": resource.NewNumberProperty(1)}),
+		newState("changed", resource.PropertyMap{
+			"kept":   resource.NewStringProperty("x"),
+			"edited": resource.NewStringProperty("new"),
+			"new":    resource.NewNumberProperty(2),
+		}),
+		newState("added", resource.PropertyMap{}),
+	}}
+
+	assert.Equal(t, []resourceDiffJSON{
+		{
+			URN:     "urn:pulumi:test::test::pkgA:m:typA::changed",
+			Op:      deploy.OpUpdate,
+			Added:   []string{"new"},
+			Removed: []string{"dropped"},
+			Changed: []string{"edited"},
+		},
+		{URN: "urn:pulumi:test::test::pkgA:m:typA::removed", Op: deploy.OpDelete},
+		{URN: "urn:pulumi:test::test::pkgA:m:typA::added", Op: deploy.OpCreate},
+	}, diffSnapshotProperties(from, to))
+
+	assert.Equal(t, []resourceDiffJSON{}, diffSnapshotProperties(from, from))
+}
@@ -0,0 +1,137 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func TestHistoryFilter(t *testing.T) {
+	now := time.Unix(1_000_000, 0)
+	updates := []backend.UpdateInfo{
+		{
+			Version:     1,
+			Kind:        apitype.UpdateUpdate,
+			Result:      backend.SucceededResult,
+			StartTime:   now.Add(-48 * time.Hour).Unix(),
+			Environment: map[string]string{backend.GitAuthor: "Alice", backend.GitAuthorEmail: "alice@example.com"},
+		},
+		{
+			Version:     2,
+			Kind:        apitype.UpdateUpdate,
+			Result:      backend.FailedResult,
+			StartTime:   now.Add(-2 * time.Hour).Unix(),
+			Environment: map[string]string{backend.GitCommitter: "Bob"},
+		},
+		{
+			Version:     3,
+			Kind:        apitype.DestroyUpdate,
+			Result:      backend.FailedResult,
+			StartTime:   now.Add(-time.Hour).Unix(),
+			Environment: map[string]string{backend.GitAuthorEmail: "alice@example.com"},
+		},
+	}
+
+	versions := func(updates []backend.UpdateInfo) []int {
+		result := []int{}
+		for _, update := range updates {
+			result = append(result, update.Version)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		filters  []string
+		since    string
+		user     string
+		expected []int
+	}{
+		{name: "none", expected: []int{1, 2, 3}},
+		{name: "result", filters: []string{"result=failed"}, expected: []int{2, 3}},
+		{name: "result and kind", filters: []string{"result=failed", "kind=destroy"}, expected: []int{3}},
+		{name: "repeated kind", filters: []string{"kind=destroy", "kind=update"}, expected: []int{1, 2, 3}},
+		{name: "since", since: "3h", expected: []int{2, 3}},
+		{name: "user name", user: "bob", expected: []int{2}},
+		{name: "user email", user: "alice@example.com", expected: []int{1, 3}},
+		{name: "combined", filters: []string{"result=failed"}, since: "3h", user: "Alice@example.com", expected: []int{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newHistoryFilter(tt.filters, tt.since, tt.user, now)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, versions(filterUpdates(updates, filter)))
+		})
+	}
+}
+
+func TestHistoryFilterErrors(t *testing.T) {
+	for _, filters := range [][]string{{"result"}, {"result="}, {"result=broken"}, {"author=alice"}} {
+		_, err := newHistoryFilter(filters, "", "", time.Now())
+		assert.Error(t, err, "filters: %v", filters)
+	}
+
+	_, err := newHistoryFilter(nil, "not a time", "", time.Now())
+	assert.Error(t, err)
+}
+
+func TestGetUpdateDetails(t *testing.T) {
+	update := backend.UpdateInfo{
+		Result:    backend.SucceededResult,
+		StartTime: 100,
+		EndTime:   142,
+		ResourceChanges: engine.ResourceChanges{
+			deploy.OpCreate: 2,
+			deploy.OpUpdate: 1,
+			deploy.OpSame:   7,
+		},
+		OutputChanges: engine.OutputChanges{
+			"url": {Kind: engine.OutputAdded, New: "https://example.com"},
+		},
+		Environment: map[string]string{
+			backend.GitCommitter:        "Bob",
+			backend.GitHead:             "abc123",
+			backend.ToolchainCLIVersion: "3.0.0",
+			"custom":                    "value",
+		},
+	}
+
+	details := getUpdateDetails(update)
+	assert.Equal(t, "Bob", details.User)
+	require.NotNil(t, details.DurationSeconds)
+	assert.Equal(t, int64(42), *details.DurationSeconds)
+	require.NotNil(t, details.TotalChanges)
+	assert.Equal(t, 3, *details.TotalChanges)
+	assert.Equal(t, update.OutputChanges, details.OutputChanges)
+	assert.Equal(t, map[string]map[string]string{
+		"git":       {"committer": "Bob", "head": "abc123"},
+		"toolchain": {"cli.version": "3.0.0"},
+		"other":     {"custom": "value"},
+	}, details.EnvironmentDetails)
+
+	update.Result = backend.InProgressResult
+	details = getUpdateDetails(update)
+	assert.Nil(t, details.DurationSeconds)
+	assert.Nil(t, details.TotalChanges)
+}
@@ -28,11 +28,13 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 )
 
 func newStackImportCmd() *cobra.Command {
 	var force bool
+	var dryRun bool
 	var file string
 	var stackName string
 	cmd := &cobra.Command{
@@ -44,7 +46,11 @@ func newStackImportCmd() *cobra.Command {
 			"A deployment that was exported from a stack using `pulumi stack export` and\n" +
 			"hand-edited to correct inconsistencies due to failed updates, manual changes\n" +
 			"to cloud resources, etc. can be reimported to the stack using this command.\n" +
-			"The updated deployment will be read from standard in.",
+			"The updated deployment will be read from standard in.\n" +
+			"\n" +
+			"Pass --dry-run to check a deployment without importing it. The deployment is\n" +
+			"validated, its secrets are decrypted, and the resources whose state would change\n" +
+			"are shown, but the stack's state is left untouched.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
@@ -73,6 +79,18 @@ func newStackImportCmd() *cobra.Command {
 				return err
 			}
 
+			// Check that every secret in the deployment can be decrypted before deserializing it, so that a dry run
+			// can report each resource that is affected rather than only the first.
+			if dryRun {
+				if errs := deploymentSecretErrors(&deployment); len(errs) > 0 {
+					var result error
+					for _, err := range errs {
+						result = multierror.Append(result, err)
+					}
+					return result
+				}
+			}
+
 			// We do, however, now want to unmarshal the json.RawMessage into a real, typed deployment.  We do this so
 			// we can check that the deployment doesn't contain resources from a stack other than the selected one. This
 			// catches errors wherein someone imports the wrong stack's deployment (which can seriously hork things).
@@ -97,15 +115,38 @@ func newStackImportCmd() *cobra.Command {
 					}
 				}
 			}
-			// Validate the stack. If --force was passed, issue an error if validation fails. Otherwise, issue a warning.
-			if err := snapshot.VerifyIntegrity(); err != nil {
-				msg := fmt.Sprintf("state file contains errors: %v", err)
+			// Validate the stack. If --force was passed, issue a warning if validation fails. Otherwise, issue an error.
+			for _, v := range snapshot.IntegrityViolations() {
+				msg := fmt.Sprintf("state file contains errors: %v", v.Message)
 				if force {
 					cmdutil.Diag().Warningf(diag.Message("", msg))
 				} else {
 					result = multierror.Append(result, errors.New(msg))
 				}
 			}
+
+			if dryRun {
+				current, err := s.Snapshot(commandContext())
+				if err != nil {
+					return err
+				}
+				changes := diffSnapshotResources(current, snapshot)
+				if len(changes) == 0 {
+					fmt.Printf("Importing this deployment would not change the state of any resources\n")
+				} else {
+					fmt.Printf("Importing this deployment would change the state of %d resource(s):\n", len(changes))
+					printResourceStateChanges(opts, changes)
+				}
+				for _, op := range snapshot.PendingOperations {
+					fmt.Printf("Pending operation '%s' on '%s' would be removed\n", op.Type, op.Resource.URN)
+				}
+				if result != nil {
+					return result
+				}
+				fmt.Printf("Dry run successful; the deployment was not imported.\n")
+				return nil
+			}
+
 			if result != nil {
 				return multierror.Append(result,
 					errors.New("importing this file could be dangerous; rerun with --force to proceed anyway"))
@@ -150,8 +191,58 @@ func newStackImportCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVarP(
 		&force, "force", "f", false,
 		"Force the import to occur, even if apparent errors are discovered beforehand (not recommended)")
+	cmd.PersistentFlags().BoolVar(
+		&dryRun, "dry-run", false,
+		"Validate the deployment and show the changes it would make to the stack's state without importing it")
 	cmd.PersistentFlags().StringVarP(
 		&file, "file", "", "", "A filename to read stack input from")
 
 	return cmd
 }
+
+// noSecretsProviderDecrypter fails to decrypt any value. It stands in for the decrypter of a deployment that contains
+// secrets but does not name a secrets provider.
+type noSecretsProviderDecrypter struct{}
+
+func (noSecretsProviderDecrypter) DecryptValue(ciphertext string) (string, error) {
+	return "", errors.New("the deployment contains secrets but does not specify a secrets provider")
+}
+
+func (noSecretsProviderDecrypter) EncryptValue(plaintext string) (string, error) {
+	return "", errors.New("the deployment contains secrets but does not specify a secrets provider")
+}
+
+// deploymentSecretErrors attempts to decrypt the secrets in each of a deployment's resources, returning an error for
+// each resource that cannot be read. Deployments that are not in the current schema version are not checked.
+func deploymentSecretErrors(deployment *apitype.UntypedDeployment) []error {
+	if deployment.Version != apitype.DeploymentSchemaVersionCurrent {
+		return nil
+	}
+	var v3deployment apitype.DeploymentV3
+	if err := json.Unmarshal([]byte(deployment.Deployment), &v3deployment); err != nil {
+		return []error{err}
+	}
+
+	var dec config.Decrypter = noSecretsProviderDecrypter{}
+	var enc config.Encrypter = noSecretsProviderDecrypter{}
+	if sp := v3deployment.SecretsProviders; sp != nil && sp.Type != "" {
+		sm, err := stack.DefaultSecretsProvider.OfType(sp.Type, sp.State)
+		if err != nil {
+			return []error{fmt.Errorf("could not load secrets provider '%s': %w", sp.Type, err)}
+		}
+		if dec, err = sm.Decrypter(); err != nil {
+			return []error{fmt.Errorf("could not load secrets provider '%s': %w", sp.Type, err)}
+		}
+		if enc, err = sm.Encrypter(); err != nil {
+			return []error{fmt.Errorf("could not load secrets provider '%s': %w", sp.Type, err)}
+		}
+	}
+
+	var errs []error
+	for _, res := range v3deployment.Resources {
+		if _, err := stack.DeserializeResource(res, dec, enc); err != nil {
+			errs = append(errs, fmt.Errorf("resource '%s': %w", res.URN, err))
+		}
+	}
+	return errs
+}
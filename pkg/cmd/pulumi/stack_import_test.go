@@ -0,0 +1,70 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestDeploymentSecretErrors(t *testing.T) {
+	newDeployment := func(outputs map[string]interface{}) *apitype.UntypedDeployment {
+		bytes, err := json.Marshal(apitype.DeploymentV3{
+			Resources: []apitype.ResourceV3{
+				{
+					URN:     "urn:pulumi:test::test::pkgA:m:typA::plain",
+					Type:    "pkgA:m:typA",
+					Custom:  true,
+					ID:      "0",
+					Outputs: map[string]interface{}{"foo": "bar"},
+				},
+				{
+					URN:     "urn:pulumi:test::test::pkgA:m:typA::secret",
+					Type:    "pkgA:m:typA",
+					Custom:  true,
+					ID:      "1",
+					Outputs: outputs,
+				},
+			},
+		})
+		require.NoError(t, err)
+		return &apitype.UntypedDeployment{
+			Version:    apitype.DeploymentSchemaVersionCurrent,
+			Deployment: bytes,
+		}
+	}
+
+	// A deployment without secrets can always be read.
+	errs := deploymentSecretErrors(newDeployment(map[string]interface{}{"foo": "baz"}))
+	assert.Empty(t, errs)
+
+	// A deployment with encrypted secrets but no secrets provider cannot.
+	errs = deploymentSecretErrors(newDeployment(map[string]interface{}{
+		"foo": map[string]interface{}{
+			resource.SigKey: resource.SecretSig,
+			"ciphertext":    "AAABAFakeCiphertext",
+		},
+	}))
+	if assert.Len(t, errs, 1) {
+		assert.Contains(t, errs[0].Error(), "urn:pulumi:test::test::pkgA:m:typA::secret")
+		assert.Contains(t, errs[0].Error(), "does not specify a secrets provider")
+	}
+}
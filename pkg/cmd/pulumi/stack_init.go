@@ -20,19 +20,25 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
 
 const (
 	possibleSecretsProviderChoices = "The type of the provider that should be used to encrypt and decrypt secrets\n" +
-		"(possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault)"
+		"(possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault, age)"
 )
 
 func newStackInitCmd() *cobra.Command {
 	var secretsProvider string
 	var stackName string
 	var stackToCopy string
+	var profileName string
+	var batchFile string
+	var nameTemplate string
+	var configTemplates []string
 
 	cmd := &cobra.Command{
 		Use:   "init [<org-name>/]<stack-name>",
@@ -63,17 +69,59 @@ func newStackInitCmd() *cobra.Command {
 			"* `pulumi stack init --secrets-provider=\"gcpkms://projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>\"`\n" +
 			"* `pulumi stack init --secrets-provider=\"hashivault://mykey\"\n`" +
 			"\n" +
+			"To encrypt secrets to one or more age recipients, without a shared passphrase or a cloud KMS, use:\n" +
+			"\n" +
+			"* `pulumi stack init --secrets-provider=\"age://<recipient>,<recipient>\"`\n" +
+			"\n" +
+			"Secrets are then decrypted with the age identity in `PULUMI_CONFIG_AGE_IDENTITY`, or in the file\n" +
+			"named by `PULUMI_CONFIG_AGE_IDENTITY_FILE`, which defaults to `~/.pulumi/age/keys.txt`.\n" +
+			"\n" +
 			"A stack can be created based on the configuration of an existing stack by passing the\n" +
 			"`--copy-config-from` flag.\n" +
-			"* `pulumi stack init --copy-config-from dev`",
+			"* `pulumi stack init --copy-config-from dev`\n" +
+			"\n" +
+			"A stack can be created using the backend and secrets provider recorded in a named profile\n" +
+			"(see `pulumi profile create`) by passing the `--profile` flag. An explicit `--secrets-provider`\n" +
+			"takes precedence over the profile's secrets provider.\n" +
+			"* `pulumi stack init --profile team-prod`\n" +
+			"\n" +
+			"Many stacks can be created at once from a batch file, such as a list of tenants, by passing\n" +
+			"the `--batch` flag. The file is either a JSON array of objects or, if its name ends in `.csv`,\n" +
+			"a CSV file whose first row names the fields of each record. Each record is rendered through\n" +
+			"the Go template given by `--name-template` to produce the name of its stack, and through each\n" +
+			"`--config-template key=template` to produce the stack's configuration. Stacks that already\n" +
+			"exist are skipped.\n" +
+			"* `pulumi stack init --batch tenants.json --name-template '{{.tenant}}-prod' \\\n" +
+			"      --config-template 'tenantId={{.id}}'`",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
-			b, err := currentBackend(opts)
-			if err != nil {
-				return err
+			var b backend.Backend
+			if profileName != "" {
+				profile, ok, err := workspace.GetProfile(profileName)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("no profile named '%s' found", profileName)
+				}
+
+				if profile.SecretsProvider != "" && !cmd.Flags().Changed("secrets-provider") {
+					secretsProvider = profile.SecretsProvider
+				}
+				if profile.Backend != "" {
+					if b, err = loginToBackend(profile.Backend, opts); err != nil {
+						return fmt.Errorf("problem logging in to profile backend: %w", err)
+					}
+				}
+			}
+			if b == nil {
+				var err error
+				if b, err = currentBackend(opts); err != nil {
+					return err
+				}
 			}
 
 			if len(args) > 0 {
@@ -89,6 +137,15 @@ func newStackInitCmd() *cobra.Command {
 				return err
 			}
 
+			if batchFile != "" {
+				if stackName != "" {
+					return errors.New("a stack name may not be specified with --batch; use --name-template instead")
+				}
+				return batchInitStacks(b, batchFile, nameTemplate, configTemplates, secretsProvider, stackToCopy, opts)
+			} else if nameTemplate != "" || len(configTemplates) > 0 {
+				return errors.New("--name-template and --config-template may only be used with --batch")
+			}
+
 			if stackName == "" && cmdutil.Interactive() {
 				if b.SupportsOrganizations() {
 					fmt.Print("Please enter your desired stack name.\n" +
@@ -156,5 +213,14 @@ func newStackInitCmd() *cobra.Command {
 		&secretsProvider, "secrets-provider", "default", possibleSecretsProviderChoices)
 	cmd.PersistentFlags().StringVar(
 		&stackToCopy, "copy-config-from", "", "The name of the stack to copy existing config from")
+	cmd.PersistentFlags().StringVar(
+		&profileName, "profile", "", "The name of a profile whose backend and secrets provider the stack should use")
+	cmd.PersistentFlags().StringVar(
+		&batchFile, "batch", "", "Create a stack for each record in the given JSON or CSV file")
+	cmd.PersistentFlags().StringVar(
+		&nameTemplate, "name-template", "", "With --batch, a template that renders each record's stack name")
+	cmd.PersistentFlags().StringArrayVar(
+		&configTemplates, "config-template", []string{},
+		"With --batch, a key=template pair that renders a config value for each record's stack; may be repeated")
 	return cmd
 }
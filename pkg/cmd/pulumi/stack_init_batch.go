@@ -0,0 +1,262 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// stackTemplate is a template that is rendered once per record of a batch file.
+type stackTemplate struct {
+	tmpl *template.Template
+}
+
+// parseStackTemplate parses a template that refers to the fields of a batch record, e.g. `{{.tenant}}-prod`.
+// Referring to a field that a record does not have is an error.
+func parseStackTemplate(name, text string) (*stackTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	return &stackTemplate{tmpl: tmpl}, nil
+}
+
+func (t *stackTemplate) render(record map[string]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, record); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// configTemplate is a `key=template` pair passed to `--config-template`.
+type configTemplate struct {
+	key   config.Key
+	value *stackTemplate
+}
+
+func parseConfigTemplates(args []string) ([]configTemplate, error) {
+	var templates []configTemplate
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("config template '%s' must be of the form key=template", arg)
+		}
+
+		key, err := parseConfigKey(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid config key '%s': %w", parts[0], err)
+		}
+		value, err := parseStackTemplate(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, configTemplate{key: key, value: value})
+	}
+	return templates, nil
+}
+
+// readBatchRecords reads the records of a batch file. Files with a `.csv` extension are read as CSV, with the first
+// row naming the fields of each record; all other files are read as a JSON array of objects.
+func readBatchRecords(path string) ([]map[string]interface{}, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer contract.IgnoreClose(f)
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readBatchRecordsCSV(f)
+	}
+
+	var records []map[string]interface{}
+	if err := json.NewDecoder(f).Decode(&records); err != nil {
+		return nil, fmt.Errorf("could not parse %s as a JSON array of objects: %w", path, err)
+	}
+	return records, nil
+}
+
+func readBatchRecordsCSV(r io.Reader) ([]map[string]interface{}, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	records := make([]map[string]interface{}, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]interface{}, len(header))
+		for i, field := range header {
+			record[strings.TrimSpace(field)] = row[i]
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// batchStack is a stack to be created by a batch init, along with the record that describes it.
+type batchStack struct {
+	name   string
+	record map[string]interface{}
+}
+
+// planBatchStacks renders the name of each record's stack, ensuring that every name is valid and unique before any
+// stack is created.
+func planBatchStacks(b backend.Backend, records []map[string]interface{},
+	nameTemplate *stackTemplate) ([]batchStack, error) {
+
+	seen := make(map[string]int)
+	stacks := make([]batchStack, 0, len(records))
+	for i, record := range records {
+		name, err := nameTemplate.render(record)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: rendering stack name: %w", i+1, err)
+		}
+		name, err = buildStackName(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.ValidateStackName(name); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		if j, ok := seen[name]; ok {
+			return nil, fmt.Errorf("records %d and %d both produce the stack name '%s'", j, i+1, name)
+		}
+		seen[name] = i + 1
+
+		stacks = append(stacks, batchStack{name: name, record: record})
+	}
+	return stacks, nil
+}
+
+// batchInitStacks creates and configures a stack for each record in the given batch file. Stacks that already exist
+// are left untouched, so that a batch that failed partway through may simply be run again.
+func batchInitStacks(b backend.Backend, batchFile, nameTemplateText string, configTemplateArgs []string,
+	secretsProvider, stackToCopy string, opts display.Options) error {
+
+	if nameTemplateText == "" {
+		return errors.New("--name-template must be specified with --batch")
+	}
+	nameTemplate, err := parseStackTemplate("name", nameTemplateText)
+	if err != nil {
+		return err
+	}
+	configTemplates, err := parseConfigTemplates(configTemplateArgs)
+	if err != nil {
+		return err
+	}
+
+	records, err := readBatchRecords(batchFile)
+	if err != nil {
+		return fmt.Errorf("reading batch file: %w", err)
+	}
+	stacks, err := planBatchStacks(b, records, nameTemplate)
+	if err != nil {
+		return err
+	}
+
+	var copyStack backend.Stack
+	if stackToCopy != "" {
+		if copyStack, err = requireStack(stackToCopy, false, opts, false /*setCurrent*/); err != nil {
+			return err
+		}
+	}
+
+	created := 0
+	for _, s := range stacks {
+		stackRef, err := b.ParseStackReference(s.name)
+		if err != nil {
+			return err
+		}
+		existing, err := b.GetStack(commandContext(), stackRef)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			fmt.Printf("Stack '%s' already exists; skipping\n", s.name)
+			continue
+		}
+
+		newStack, err := createStack(b, stackRef, nil, false /*setCurrent*/, secretsProvider)
+		if err != nil {
+			return fmt.Errorf("creating stack '%s': %w", s.name, err)
+		}
+		if err := configureBatchStack(newStack, copyStack, configTemplates, s.record); err != nil {
+			return fmt.Errorf("configuring stack '%s': %w", s.name, err)
+		}
+
+		fmt.Printf("Created stack '%s'\n", s.name)
+		created++
+	}
+
+	fmt.Printf("Created %d of %d stacks\n", created, len(stacks))
+	return nil
+}
+
+// configureBatchStack copies configuration from copyStack, if any, and then sets each templated config value.
+func configureBatchStack(newStack, copyStack backend.Stack, configTemplates []configTemplate,
+	record map[string]interface{}) error {
+
+	if copyStack != nil {
+		copyProjectStack, err := loadProjectStack(copyStack)
+		if err != nil {
+			return err
+		}
+		newProjectStack, err := loadProjectStack(newStack)
+		if err != nil {
+			return err
+		}
+		if err := copyEntireConfigMap(copyStack, copyProjectStack, newStack, newProjectStack); err != nil {
+			return err
+		}
+	}
+
+	if len(configTemplates) == 0 {
+		return nil
+	}
+
+	ps, err := loadProjectStack(newStack)
+	if err != nil {
+		return err
+	}
+	if ps.Config == nil {
+		ps.Config = config.Map{}
+	}
+	for _, t := range configTemplates {
+		value, err := t.value.render(record)
+		if err != nil {
+			return fmt.Errorf("rendering config value for '%s': %w", t.key, err)
+		}
+		ps.Config[t.key] = config.NewValue(value)
+	}
+	return saveProjectStack(newStack, ps)
+}
@@ -0,0 +1,74 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+)
+
+func TestReadBatchRecords(t *testing.T) {
+	dir := t.TempDir()
+
+	csvPath := filepath.Join(dir, "tenants.csv")
+	require.NoError(t, ioutil.WriteFile(csvPath, []byte("tenant, id\nacme,1\nglobex,2\n"), 0600))
+	records, err := readBatchRecords(csvPath)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{"tenant": "acme", "id": "1"},
+		{"tenant": "globex", "id": "2"},
+	}, records)
+
+	jsonPath := filepath.Join(dir, "tenants.json")
+	require.NoError(t, ioutil.WriteFile(jsonPath, []byte(`[{"tenant": "acme", "id": 1}]`), 0600))
+	records, err = readBatchRecords(jsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"tenant": "acme", "id": float64(1)}}, records)
+
+	badPath := filepath.Join(dir, "tenants.txt")
+	require.NoError(t, ioutil.WriteFile(badPath, []byte(`{"tenant": "acme"}`), 0600))
+	_, err = readBatchRecords(badPath)
+	assert.Error(t, err)
+}
+
+func TestStackTemplates(t *testing.T) {
+	name, err := parseStackTemplate("name", "{{.tenant}}-prod")
+	require.NoError(t, err)
+
+	rendered, err := name.render(map[string]interface{}{"tenant": "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme-prod", rendered)
+
+	_, err = name.render(map[string]interface{}{"customer": "acme"})
+	assert.Error(t, err)
+
+	templates, err := parseConfigTemplates([]string{"app:tenantId={{.id}}", "app:url=https://{{.tenant}}.example.com"})
+	require.NoError(t, err)
+	require.Len(t, templates, 2)
+	assert.Equal(t, config.MustMakeKey("app", "tenantId"), templates[0].key)
+	rendered, err = templates[1].value.render(map[string]interface{}{"tenant": "acme"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(rendered, "https://acme."))
+
+	_, err = parseConfigTemplates([]string{"app:tenantId"})
+	assert.Error(t, err)
+}
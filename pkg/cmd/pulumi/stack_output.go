@@ -15,11 +15,20 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
 
 	"github.com/spf13/cobra"
 
+	"github.com/pulumi/pulumi/pkg/v3/backend"
 	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
@@ -30,6 +39,10 @@ func newStackOutputCmd() *cobra.Command {
 	var jsonOut bool
 	var showSecrets bool
 	var stackName string
+	var showChanges bool
+	var format string
+	var envPrefix string
+	var envPreserveCase bool
 
 	cmd := &cobra.Command{
 		Use:   "output [property-name]",
@@ -38,23 +51,66 @@ func newStackOutputCmd() *cobra.Command {
 		Long: "Show a stack's output properties.\n" +
 			"\n" +
 			"By default, this command lists all output properties exported from a stack.\n" +
-			"If a specific property-name is supplied, just that property's value is shown.",
+			"If a specific property-name is supplied, just that property's value is shown.\n" +
+			"\n" +
+			"Pass --changes to instead show how the outputs changed during the most recent update.\n" +
+			"Output changes are only recorded by self-managed backends.\n" +
+			"\n" +
+			"Pass --format to print the outputs in a form that scripts can consume directly:\n" +
+			"\n" +
+			"  --format env                           one KEY=VALUE line per output\n" +
+			"  --format go-template='{{.apiUrl}}'     the result of executing a Go template\n" +
+			"\n" +
+			"With --format env, output names are converted to environment variable names, e.g.\n" +
+			"apiUrl becomes API_URL. Use --env-prefix to prepend a prefix to each name, and\n" +
+			"--env-preserve-case to only replace characters that are not valid in a name.\n" +
+			"\n" +
+			"Secret outputs are left out of --format output unless --show-secrets is passed. With --json,\n" +
+			"--redact-secrets replaces the values of secret outputs with stable hashes.",
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
 				Color: cmdutil.GetGlobalColorization(),
 			}
 
+			if err := checkShowSecretsFlag(showSecrets); err != nil {
+				return err
+			}
+
+			var tmpl *template.Template
+			if format != "" {
+				if jsonOut {
+					return errors.New("only one of --json and --format may be specified")
+				}
+				if showChanges {
+					return errors.New("only one of --changes and --format may be specified")
+				}
+				t, err := parseStackOutputFormat(format)
+				if err != nil {
+					return err
+				}
+				tmpl = t
+			}
+
 			// Fetch the current stack and its output properties.
 			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
 			if err != nil {
 				return err
 			}
+
+			if showChanges {
+				return printStackOutputChanges(s, args, jsonOut)
+			}
+
 			snap, err := s.Snapshot(commandContext())
 			if err != nil {
 				return err
 			}
 
-			outputs, err := getStackOutputs(snap, showSecrets)
+			var redactor *secretRedactor
+			if jsonOut {
+				redactor = newSecretRedactor(s)
+			}
+			outputs, err := getStackOutputs(snap, showSecrets, redactor)
 			if err != nil {
 				return fmt.Errorf("getting outputs: %w", err)
 			}
@@ -62,6 +118,44 @@ func newStackOutputCmd() *cobra.Command {
 				outputs = make(map[string]interface{})
 			}
 
+			if format != "" {
+				hidden := []string{}
+				if !showSecrets {
+					if hidden, err = getSecretStackOutputNames(snap); err != nil {
+						return fmt.Errorf("getting outputs: %w", err)
+					}
+					for _, name := range hidden {
+						delete(outputs, name)
+					}
+				}
+				if len(args) > 0 {
+					name := args[0]
+					v, has := outputs[name]
+					if !has {
+						for _, h := range hidden {
+							if h == name {
+								return fmt.Errorf("output property '%v' is secret; pass --show-secrets to display it", name)
+							}
+						}
+						return fmt.Errorf("current stack does not have output property '%v'", name)
+					}
+					outputs = map[string]interface{}{name: v}
+				}
+
+				if tmpl == nil {
+					printStackOutputsAsEnv(outputs, envPrefix, envPreserveCase)
+					return nil
+				}
+				if err := tmpl.Execute(os.Stdout, outputs); err != nil {
+					if len(hidden) > 0 {
+						return fmt.Errorf("executing template: %w (secret outputs %s are hidden; "+
+							"pass --show-secrets to use them)", err, strings.Join(hidden, ", "))
+					}
+					return fmt.Errorf("executing template: %w", err)
+				}
+				return nil
+			}
+
 			// If there is an argument, just print that property.  Else, print them all (similar to `pulumi stack`).
 			if len(args) > 0 {
 				name := args[0]
@@ -92,13 +186,91 @@ func newStackOutputCmd() *cobra.Command {
 		&jsonOut, "json", "j", false, "Emit output as JSON")
 	cmd.PersistentFlags().StringVarP(
 		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	addShowSecretsFlag(cmd, &showSecrets)
 	cmd.PersistentFlags().BoolVar(
-		&showSecrets, "show-secrets", false, "Display outputs which are marked as secret in plaintext")
+		&showChanges, "changes", false, "Display how the outputs changed during the most recent update")
+	cmd.PersistentFlags().StringVar(
+		&format, "format", "", "Print the outputs in the given format: `env` or `go-template=<template>`")
+	cmd.PersistentFlags().StringVar(
+		&envPrefix, "env-prefix", "", "A prefix to prepend to each variable name when using --format env")
+	cmd.PersistentFlags().BoolVar(
+		&envPreserveCase, "env-preserve-case", false,
+		"Keep the case of output names when using --format env, only replacing characters that are not valid")
 
 	return cmd
 }
 
-func getStackOutputs(snap *deploy.Snapshot, showSecrets bool) (map[string]interface{}, error) {
+// printStackOutputChanges prints the output changes recorded for the most recent update of the given stack,
+// optionally restricted to the named outputs. Output changes are only recorded by self-managed backends.
+func printStackOutputChanges(s backend.Stack, names []string, jsonOut bool) error {
+	if _, ok := s.Backend().(filestate.Backend); !ok {
+		return fmt.Errorf("--changes is not supported by the %s backend, which does not record output changes; "+
+			"only self-managed backends do", s.Backend().Name())
+	}
+
+	updates, err := s.Backend().GetHistory(commandContext(), s.Ref(), 1 /*pageSize*/, 1 /*page*/)
+	if err != nil {
+		return fmt.Errorf("getting history: %w", err)
+	}
+
+	changes := engine.OutputChanges{}
+	if len(updates) > 0 && updates[0].OutputChanges != nil {
+		changes = updates[0].OutputChanges
+	}
+	if len(names) > 0 {
+		filtered := engine.OutputChanges{}
+		for _, name := range names {
+			if change, has := changes[name]; has {
+				filtered[name] = change
+			}
+		}
+		changes = filtered
+	}
+
+	if jsonOut {
+		return printJSON(changes)
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("No output changes were recorded for the most recent update\n")
+		return nil
+	}
+
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rows := []cmdutil.TableRow{}
+	for _, k := range keys {
+		change := changes[k]
+		oldValue, newValue := "", ""
+		if change.Secret {
+			oldValue, newValue = "[secret]", "[secret]"
+		} else {
+			if change.Old != nil {
+				oldValue = stringifyOutput(change.Old)
+			}
+			if change.New != nil {
+				newValue = stringifyOutput(change.New)
+			}
+		}
+		rows = append(rows, cmdutil.TableRow{Columns: []string{k, string(change.Kind), oldValue, newValue}})
+	}
+
+	cmdutil.PrintTable(cmdutil.Table{
+		Headers: []string{"OUTPUT", "CHANGE", "OLD", "NEW"},
+		Rows:    rows,
+	})
+	return nil
+}
+
+// getStackOutputs returns the stack's outputs. If the given redactor is not nil, secret outputs are replaced with
+// their hashes.
+func getStackOutputs(snap *deploy.Snapshot, showSecrets bool,
+	redactor *secretRedactor) (map[string]interface{}, error) {
+
 	state, err := stack.GetRootStackResource(snap)
 	if err != nil {
 		return nil, err
@@ -108,9 +280,115 @@ func getStackOutputs(snap *deploy.Snapshot, showSecrets bool) (map[string]interf
 		return map[string]interface{}{}, nil
 	}
 
+	if redactor != nil {
+		outputs, err := redactor.redactProperties(state.Outputs)
+		if err != nil {
+			return nil, err
+		}
+		return stack.SerializeProperties(display.MassageSecrets(outputs, true /*showSecrets*/),
+			config.NewPanicCrypter(), true /*showSecrets*/)
+	}
+
 	// massageSecrets will remove all the secrets from the property map, so it should be safe to pass a panic
 	// crypter. This also ensure that if for some reason we didn't remove everything, we don't accidentally disclose
 	// secret values!
 	return stack.SerializeProperties(display.MassageSecrets(state.Outputs, showSecrets),
 		config.NewPanicCrypter(), showSecrets)
 }
+
+// getSecretStackOutputNames returns the sorted names of the stack's outputs whose values contain secrets.
+func getSecretStackOutputNames(snap *deploy.Snapshot) ([]string, error) {
+	state, err := stack.GetRootStackResource(snap)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	if state == nil {
+		return names, nil
+	}
+	for k, v := range state.Outputs {
+		if v.ContainsSecrets() {
+			names = append(names, string(k))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// parseStackOutputFormat parses the value of `pulumi stack output --format`. It returns nil for the env format and the
+// parsed template for the go-template format.
+func parseStackOutputFormat(format string) (*template.Template, error) {
+	if format == "env" {
+		return nil, nil
+	}
+	if text := strings.TrimPrefix(format, "go-template="); text != format {
+		tmpl, err := template.New("output").
+			Option("missingkey=error").
+			Funcs(template.FuncMap{"json": stringifyOutput}).
+			Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("parsing template: %w", err)
+		}
+		return tmpl, nil
+	}
+	return nil, fmt.Errorf("unknown output format '%s'; expected `env` or `go-template=<template>`", format)
+}
+
+// printStackOutputsAsEnv prints one KEY=VALUE line per output, sorted by output name. Values are quoted so that the
+// lines can be evaluated by a POSIX shell.
+func printStackOutputsAsEnv(outputs map[string]interface{}, prefix string, preserveCase bool) {
+	keys := make([]string, 0, len(outputs))
+	for k := range outputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		fmt.Printf("%s=%s\n", envVarName(k, prefix, preserveCase), shellQuote(stringifyOutput(outputs[k])))
+	}
+}
+
+// envVarName converts an output name into an environment variable name. Characters other than ASCII letters and
+// digits are replaced by underscores. Unless preserveCase is set, word boundaries in camelCase names are also marked
+// by underscores and the result is upper-cased, so that `apiUrl` becomes `API_URL`.
+func envVarName(name, prefix string, preserveCase bool) string {
+	var b strings.Builder
+	b.WriteString(prefix)
+
+	runes := []rune(name)
+	for i, r := range runes {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune('_')
+			continue
+		}
+		if !preserveCase && i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteRune('_')
+			}
+		}
+		if !preserveCase {
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+	}
+
+	result := b.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "_" + result
+	}
+	return result
+}
+
+// shellQuote returns s quoted for a POSIX shell. Values that only contain characters that the shell does not treat
+// specially are returned unchanged.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) || strings.ContainsRune("_-./:@%+,=", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -38,3 +39,49 @@ func TestStringifyOutput(t *testing.T) {
 	assert.Equal(t, "{\"bar\":{\"baz\":true},\"foo\":42}", stringifyOutput(obj))
 	assert.Equal(t, "pass&word", stringifyOutput(specialChar))
 }
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "API_URL", envVarName("apiUrl", "", false))
+	assert.Equal(t, "BUCKET_ARN", envVarName("bucketARN", "", false))
+	assert.Equal(t, "ARN_VALUE", envVarName("ARNValue", "", false))
+	assert.Equal(t, "SUBNET2_ID", envVarName("subnet2Id", "", false))
+	assert.Equal(t, "MY_OUTPUT", envVarName("my-output", "", false))
+	assert.Equal(t, "APP_API_URL", envVarName("apiUrl", "APP_", false))
+	assert.Equal(t, "apiUrl", envVarName("apiUrl", "", true))
+	assert.Equal(t, "my_output", envVarName("my.output", "", true))
+	assert.Equal(t, "_1ST", envVarName("1st", "", false))
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "https://example.com/a", shellQuote("https://example.com/a"))
+	assert.Equal(t, "''", shellQuote(""))
+	assert.Equal(t, "'hello world'", shellQuote("hello world"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, `'{"foo":42}'`, shellQuote(`{"foo":42}`))
+}
+
+func TestParseStackOutputFormat(t *testing.T) {
+	tmpl, err := parseStackOutputFormat("env")
+	assert.NoError(t, err)
+	assert.Nil(t, tmpl)
+
+	tmpl, err = parseStackOutputFormat("go-template={{.apiUrl}} {{json .obj}}")
+	if assert.NoError(t, err) {
+		var b strings.Builder
+		err = tmpl.Execute(&b, map[string]interface{}{
+			"apiUrl": "https://example.com",
+			"obj":    map[string]interface{}{"foo": 42},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, `https://example.com {"foo":42}`, b.String())
+
+		err = tmpl.Execute(&b, map[string]interface{}{})
+		assert.Error(t, err)
+	}
+
+	_, err = parseStackOutputFormat("go-template={{.apiUrl")
+	assert.Error(t, err)
+
+	_, err = parseStackOutputFormat("yaml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+func newStackRollbackCmd() *cobra.Command {
+	var stackName string
+	var version string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Args:  cmdutil.NoArgs,
+		Short: "Restore a stack's state to the state recorded by an earlier update",
+		Long: "Restore a stack's state to the state recorded by an earlier update.\n" +
+			"\n" +
+			"This command retrieves the deployment that the backend recorded for the given version\n" +
+			"of the stack and makes it the stack's current state. The resources whose state would\n" +
+			"change are shown before anything is modified. This can be used to undo a bad\n" +
+			"`pulumi stack import` or `pulumi state` edit.\n" +
+			"\n" +
+			"Only the stack's state is changed; no cloud resources are created, updated or deleted.\n" +
+			"Run `pulumi refresh` afterwards to reconcile the restored state with reality.",
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			yes = yes || skipConfirmations()
+			ctx := commandContext()
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			if version == "" {
+				return result.Errorf("--version must be specified")
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			// Check that the stack and its backend supports the ability to do this.
+			be := s.Backend()
+			specificExpBE, ok := be.(backend.SpecificDeploymentExporter)
+			if !ok {
+				return result.Errorf(
+					"the current backend (%s) does not provide the ability to export previous deployments", be.Name())
+			}
+
+			deployment, err := specificExpBE.ExportDeploymentForVersion(ctx, s, version)
+			if err != nil {
+				return result.FromError(err)
+			}
+			target, err := stack.DeserializeUntypedDeployment(deployment, stack.DefaultSecretsProvider)
+			if err != nil {
+				return result.FromError(checkDeploymentVersionError(err, s.Ref().Name().String()))
+			}
+			if err = target.VerifyIntegrity(); err != nil {
+				return result.Errorf("the deployment for version %s contains errors: %v", version, err)
+			}
+
+			current, err := s.Snapshot(ctx)
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			changes := diffSnapshotResources(current, target)
+			if len(changes) == 0 {
+				fmt.Printf("The state of stack '%s' already matches version %s\n", s.Ref(), version)
+				return nil
+			}
+
+			fmt.Printf("Rolling back to version %s will change the state of %d resource(s):\n", version, len(changes))
+			printResourceStateChanges(opts, changes)
+
+			res := runTotalStateEdit(stackName, !yes, func(_ display.Options, snap *deploy.Snapshot) error {
+				snap.Resources = target.Resources
+				snap.PendingOperations = nil
+				return nil
+			})
+			if res != nil {
+				return res
+			}
+			fmt.Printf("Stack '%s' has been rolled back to version %s\n", s.Ref(), version)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVar(
+		&version, "version", "", "The version of the stack's state to restore")
+	cmd.PersistentFlags().BoolVarP(
+		&yes, "yes", "y", false, "Skip confirmation prompts")
+
+	return cmd
+}
+
+// resourceStateChange records how the state of a single resource differs between two snapshots.
+type resourceStateChange struct {
+	urn resource.URN
+	op  deploy.StepOp
+}
+
+// diffSnapshotResources returns the resources whose state would change if the snapshot current were replaced by the
+// snapshot target. Resources that are only present in target are reported as creates, resources that are only present
+// in current as deletes, and resources present in both but with different state as updates. Resources that are
+// pending deletion are ignored.
+func diffSnapshotResources(current, target *deploy.Snapshot) []resourceStateChange {
+	liveResources := func(snap *deploy.Snapshot) ([]*resource.State, map[resource.URN]*resource.State) {
+		if snap == nil {
+			return nil, nil
+		}
+		var list []*resource.State
+		byURN := make(map[resource.URN]*resource.State)
+		for _, res := range snap.Resources {
+			if !res.Delete {
+				list = append(list, res)
+				byURN[res.URN] = res
+			}
+		}
+		return list, byURN
+	}
+	currentList, currentByURN := liveResources(current)
+	targetList, targetByURN := liveResources(target)
+
+	var changes []resourceStateChange
+	for _, res := range currentList {
+		old, has := targetByURN[res.URN]
+		switch {
+		case !has:
+			changes = append(changes, resourceStateChange{urn: res.URN, op: deploy.OpDelete})
+		case !resourceStatesEqual(res, old):
+			changes = append(changes, resourceStateChange{urn: res.URN, op: deploy.OpUpdate})
+		}
+	}
+	for _, res := range targetList {
+		if _, has := currentByURN[res.URN]; !has {
+			changes = append(changes, resourceStateChange{urn: res.URN, op: deploy.OpCreate})
+		}
+	}
+	return changes
+}
+
+// printResourceStateChanges prints one line per change, colored and prefixed according to the change's operation.
+func printResourceStateChanges(opts display.Options, changes []resourceStateChange) {
+	for _, change := range changes {
+		fmt.Print(opts.Color.Colorize(
+			fmt.Sprintf("    %s%s %s%s\n", change.op.Color(), change.op.RawPrefix(), change.urn, colors.Reset)))
+	}
+	fmt.Println()
+}
+
+// resourceStatesEqual returns true if the two states record the same resource with the same properties.
+func resourceStatesEqual(a, b *resource.State) bool {
+	return a.Type == b.Type && a.ID == b.ID && a.Custom == b.Custom && a.Provider == b.Provider &&
+		a.Parent == b.Parent && a.Protect == b.Protect && a.External == b.External &&
+		reflect.DeepEqual(a.Dependencies, b.Dependencies) &&
+		a.Inputs.DeepEquals(b.Inputs) && a.Outputs.DeepEquals(b.Outputs)
+}
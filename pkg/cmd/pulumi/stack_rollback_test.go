@@ -0,0 +1,56 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestDiffSnapshotResources(t *testing.T) {
+	newState := func(name string, id resource.ID) *resource.State {
+		return &resource.State{
+			Type:    "pkgA:m:typA",
+			URN:     resource.URN("urn:pulumi:test::test::pkgA:m:typA::" + name),
+			Custom:  true,
+			ID:      id,
+			Inputs:  resource.PropertyMap{},
+			Outputs: resource.PropertyMap{},
+		}
+	}
+
+	current := &deploy.Snapshot{Resources: []*resource.State{
+		newState("same", "0"),
+		newState("changed", "1"),
+		newState("removed", "2"),
+	}}
+	target := &deploy.Snapshot{Resources: []*resource.State{
+		newState("same", "0"),
+		newState("changed", "10"),
+		newState("added", "3"),
+	}}
+
+	assert.Equal(t, []resourceStateChange{
+		{urn: "urn:pulumi:test::test::pkgA:m:typA::changed", op: deploy.OpUpdate},
+		{urn: "urn:pulumi:test::test::pkgA:m:typA::removed", op: deploy.OpDelete},
+		{urn: "urn:pulumi:test::test::pkgA:m:typA::added", op: deploy.OpCreate},
+	}, diffSnapshotResources(current, target))
+
+	assert.Empty(t, diffSnapshotResources(current, current))
+}
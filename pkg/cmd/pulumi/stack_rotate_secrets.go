@@ -0,0 +1,158 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/v3/backend/httpstate"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/age"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/passphrase"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func newStackRotateSecretsCmd() *cobra.Command {
+	var stack string
+
+	var cmd = &cobra.Command{
+		Use:   "rotate-secrets",
+		Args:  cmdutil.NoArgs,
+		Short: "Rotate the key that encrypts the stack's secrets",
+		Long: "Rotate the key that encrypts the stack's secrets.\n" +
+			"\n" +
+			"This command gives the stack's secrets provider a new key, and re-encrypts every secret in the stack's\n" +
+			"configuration and state with it. Cloud and `age` secrets providers generate a new data key, and the\n" +
+			"`passphrase` secrets provider generates a new salt; set `PULUMI_CONFIG_PASSPHRASE` or\n" +
+			"`PULUMI_CONFIG_PASSPHRASE_FILE` to keep the current passphrase. The keys of the Pulumi Service secrets\n" +
+			"provider are managed by the service, and cannot be rotated.\n" +
+			"\n" +
+			"The re-encrypted secrets are checked to decrypt to their previous values before they are saved, and the\n" +
+			"rotation is recorded in the stack's history.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			ps, err := loadProjectStack(s)
+			if err != nil {
+				return err
+			}
+
+			rotate, err := secretsRotation(s, ps)
+			if err != nil {
+				return err
+			}
+
+			var decrypter config.Decrypter = config.NewPanicCrypter()
+			if ps.Config.HasSecureValue() {
+				if decrypter, err = getStackDecrypter(s); err != nil {
+					return err
+				}
+			}
+
+			ctx := commandContext()
+			start := time.Now()
+			if err := reencryptStackSecrets(ctx, s, ps, decrypter, rotate); err != nil {
+				return err
+			}
+			if err := recordSecretsRotation(ctx, s, start); err != nil {
+				return fmt.Errorf("recording the rotation in the stack's history: %w", err)
+			}
+
+			fmt.Printf("Rotated the secrets key of stack '%s'\n", s.Ref())
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+
+	return cmd
+}
+
+// secretsRotation returns a function that gives a stack's secrets provider a new key: a new data key for the cloud
+// and age secrets providers, or a new salt for the passphrase secrets provider.
+func secretsRotation(s backend.Stack, ps *workspace.ProjectStack) (func() error, error) {
+	switch provider := ps.SecretsProvider; {
+	case provider != "" && provider != passphrase.Type && provider != "default":
+		return func() error {
+			ps.EncryptedKey = ""
+			if err := saveProjectStack(s, ps); err != nil {
+				return err
+			}
+			var err error
+			if strings.HasPrefix(provider, age.URLPrefix) {
+				_, err = newAgeSecretsManager(s.Ref().Name(), stackConfigFile, provider)
+			} else {
+				_, err = newCloudSecretsManager(s.Ref().Name(), stackConfigFile, provider)
+			}
+			return err
+		}, nil
+	case ps.EncryptionSalt != "":
+		return func() error {
+			ps.EncryptionSalt = ""
+			if err := saveProjectStack(s, ps); err != nil {
+				return err
+			}
+			_, err := newPassphraseSecretsManager(s.Ref().Name(), stackConfigFile,
+				false /* rotatePassphraseSecretsProvider */)
+			return err
+		}, nil
+	}
+
+	if _, ok := s.(httpstate.Stack); ok {
+		return nil, fmt.Errorf("stack '%s' uses the Pulumi Service secrets provider, whose keys are managed by "+
+			"the service and cannot be rotated", s.Ref())
+	}
+	return nil, fmt.Errorf("stack '%s' does not have a secrets key to rotate", s.Ref())
+}
+
+// recordSecretsRotation records a rotation of a stack's secrets in its history. The Pulumi Service records the import
+// of the re-encrypted state itself, so only stacks in other backends need a record to be added.
+func recordSecretsRotation(ctx context.Context, s backend.Stack, start time.Time) error {
+	b, ok := s.Backend().(filestate.Backend)
+	if !ok {
+		return nil
+	}
+
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return err
+	}
+	return b.AddToHistory(ctx, s.Ref(), backend.UpdateInfo{
+		Kind:      apitype.StackImportUpdate,
+		StartTime: start.Unix(),
+		Message:   "Rotated the secrets key",
+		Config:    ps.Config,
+		Result:    backend.SucceededResult,
+		EndTime:   time.Now().Unix(),
+	})
+}
@@ -0,0 +1,44 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestSecretsRotation(t *testing.T) {
+	s := &backend.MockStack{
+		RefF: func() backend.StackReference { return &mockStackReference{name: "dev"} },
+	}
+
+	rotate, err := secretsRotation(s, &workspace.ProjectStack{EncryptionSalt: "v1:salt:msg"})
+	assert.NoError(t, err)
+	assert.NotNil(t, rotate)
+
+	rotate, err = secretsRotation(s, &workspace.ProjectStack{
+		SecretsProvider: "awskms://alias/ExampleAlias?region=us-east-1",
+		EncryptedKey:    "key",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, rotate)
+
+	_, err = secretsRotation(s, &workspace.ProjectStack{})
+	assert.EqualError(t, err, "stack 'dev' does not have a secrets key to rotate")
+}
@@ -40,7 +40,9 @@ func newStackSelectCmd() *cobra.Command {
 			"without needing to type the stack name each time.\n" +
 			"\n" +
 			"If no <stack> argument is supplied, you will be prompted to select one interactively.\n" +
-			"If provided stack name is not found you may pass the --create flag to create and select it",
+			"If provided stack name is not found you may pass the --create flag to create and select it.\n" +
+			"\n" +
+			"The stack may also be given by one of the aliases defined with `pulumi stack alias set`.",
 		Args: cmdutil.MaximumNArgs(1),
 		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
 			opts := display.Options{
@@ -62,6 +64,7 @@ func newStackSelectCmd() *cobra.Command {
 
 			if stack != "" {
 				// A stack was given, ask the backend about it.
+				stack = state.ResolveStackAlias(stack)
 				stackRef, stackErr := b.ParseStackReference(stack)
 				if stackErr != nil {
 					return stackErr
@@ -74,7 +77,7 @@ func newStackSelectCmd() *cobra.Command {
 					return state.SetCurrentStack(stackRef.String())
 				}
 				// If create flag was passed and stack was not found, create it and select it.
-				if create && stack != "" {
+				if create {
 					s, err := stackInit(b, stack, false, secretsProvider)
 					if err != nil {
 						return err
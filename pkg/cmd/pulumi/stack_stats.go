@@ -0,0 +1,243 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+func newStackStatsCmd() *cobra.Command {
+	var stackName string
+	var jsonOut bool
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Args:  cmdutil.NoArgs,
+		Short: "Summarize the size and shape of a stack's state",
+		Long: "Summarize the size and shape of a stack's state.\n" +
+			"\n" +
+			"This command reports the number of resources in the stack's state by type and by\n" +
+			"provider, how many are protected or pending deletion, the number of pending\n" +
+			"operations, the size of the state, the longest chain of resources that depend on\n" +
+			"one another, and the resources with the largest inputs and outputs.",
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			deployment, err := s.ExportDeployment(commandContext())
+			if err != nil {
+				return err
+			}
+			snap, err := stack.DeserializeUntypedDeployment(deployment, stack.DefaultSecretsProvider)
+			if err != nil {
+				return checkDeploymentVersionError(err, stackName)
+			}
+
+			stats, err := computeStackStats(snap, len(deployment.Deployment), top)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				return printJSON(stats)
+			}
+			printStackStats(stats)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().IntVar(
+		&top, "top", 5, "The number of resources with the largest inputs and outputs to show")
+
+	return cmd
+}
+
+// stackStats summarizes a stack's state.
+type stackStats struct {
+	Resources         int `json:"resources"`
+	Protected         int `json:"protected"`
+	PendingDeletion   int `json:"pendingDeletion"`
+	PendingOperations int `json:"pendingOperations"`
+	// StateSize is the size of the serialized state, in bytes.
+	StateSize           int            `json:"stateSize"`
+	ResourcesByType     map[string]int `json:"resourcesByType"`
+	ResourcesByProvider map[string]int `json:"resourcesByProvider"`
+	// DeepestDependencyChain lists the longest chain of resources that depend upon one another, either directly or
+	// as children, starting with the resource that depends on no others.
+	DeepestDependencyChain []resource.URN `json:"deepestDependencyChain"`
+	// LargestPayloads lists the resources with the largest serialized inputs and outputs, largest first.
+	LargestPayloads []resourcePayload `json:"largestPayloads"`
+}
+
+// resourcePayload records the size, in bytes, of a resource's serialized inputs and outputs.
+type resourcePayload struct {
+	URN  resource.URN `json:"urn"`
+	Size int          `json:"size"`
+}
+
+// computeStackStats summarizes the given snapshot. stateSize is the size of its serialized form, and top is the
+// number of resources with the largest payloads to report.
+func computeStackStats(snap *deploy.Snapshot, stateSize, top int) (*stackStats, error) {
+	stats := &stackStats{
+		StateSize:              stateSize,
+		ResourcesByType:        make(map[string]int),
+		ResourcesByProvider:    make(map[string]int),
+		DeepestDependencyChain: []resource.URN{},
+		LargestPayloads:        []resourcePayload{},
+	}
+	if snap == nil {
+		return stats, nil
+	}
+	stats.PendingOperations = len(snap.PendingOperations)
+
+	// The resources are in dependency order, so the chain ending at each resource can be computed from the chains
+	// ending at the resources that it refers to.
+	chains := make(map[resource.URN][]resource.URN)
+	var payloads []resourcePayload
+	for _, res := range snap.Resources {
+		stats.Resources++
+		if res.Protect {
+			stats.Protected++
+		}
+		if res.Delete {
+			stats.PendingDeletion++
+		}
+		stats.ResourcesByType[string(res.Type)]++
+		if res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			if err != nil {
+				return nil, fmt.Errorf("resource %v has an invalid provider reference: %w", res.URN, err)
+			}
+			name := fmt.Sprintf("%s (%s)", providers.GetProviderPackage(ref.URN().Type()), ref.URN().Name())
+			stats.ResourcesByProvider[name]++
+		}
+
+		var longest []resource.URN
+		for _, dep := range append([]resource.URN{res.Parent}, res.Dependencies...) {
+			if chain := chains[dep]; len(chain) > len(longest) {
+				longest = chain
+			}
+		}
+		chain := append(append([]resource.URN{}, longest...), res.URN)
+		chains[res.URN] = chain
+		if len(chain) > len(stats.DeepestDependencyChain) {
+			stats.DeepestDependencyChain = chain
+		}
+
+		size, err := resourcePayloadSize(res)
+		if err != nil {
+			return nil, fmt.Errorf("measuring resource %v: %w", res.URN, err)
+		}
+		payloads = append(payloads, resourcePayload{URN: res.URN, Size: size})
+	}
+
+	sort.SliceStable(payloads, func(i, j int) bool { return payloads[i].Size > payloads[j].Size })
+	if top >= 0 && len(payloads) > top {
+		payloads = payloads[:top]
+	}
+	stats.LargestPayloads = append(stats.LargestPayloads, payloads...)
+	return stats, nil
+}
+
+// resourcePayloadSize returns the size of the JSON encoding of a resource's inputs and outputs.
+func resourcePayloadSize(res *resource.State) (int, error) {
+	size := 0
+	for _, props := range []resource.PropertyMap{res.Inputs, res.Outputs} {
+		serialized, err := stack.SerializeProperties(props, config.NopEncrypter, true /*showSecrets*/)
+		if err != nil {
+			return 0, err
+		}
+		b, err := json.Marshal(serialized)
+		if err != nil {
+			return 0, err
+		}
+		size += len(b)
+	}
+	return size, nil
+}
+
+func printStackStats(stats *stackStats) {
+	fmt.Printf("Resources: %d (%d protected, %d pending deletion)\n",
+		stats.Resources, stats.Protected, stats.PendingDeletion)
+	fmt.Printf("Pending operations: %d\n", stats.PendingOperations)
+	fmt.Printf("State size: %s\n", humanize.Bytes(uint64(stats.StateSize)))
+	fmt.Printf("Deepest dependency chain: %d\n", len(stats.DeepestDependencyChain))
+	for _, urn := range stats.DeepestDependencyChain {
+		fmt.Printf("    %s\n", urn)
+	}
+
+	printCounts := func(header string, counts map[string]int) {
+		if len(counts) == 0 {
+			return
+		}
+		keys := make([]string, 0, len(counts))
+		for k := range counts {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if counts[keys[i]] != counts[keys[j]] {
+				return counts[keys[i]] > counts[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+
+		rows := make([]cmdutil.TableRow, len(keys))
+		for i, k := range keys {
+			rows[i] = cmdutil.TableRow{Columns: []string{k, strconv.Itoa(counts[k])}}
+		}
+		fmt.Println()
+		cmdutil.PrintTable(cmdutil.Table{
+			Headers: []string{header, "RESOURCES"},
+			Rows:    rows,
+		})
+	}
+	printCounts("TYPE", stats.ResourcesByType)
+	printCounts("PROVIDER", stats.ResourcesByProvider)
+
+	if len(stats.LargestPayloads) > 0 {
+		rows := make([]cmdutil.TableRow, len(stats.LargestPayloads))
+		for i, p := range stats.LargestPayloads {
+			rows[i] = cmdutil.TableRow{Columns: []string{string(p.URN), humanize.Bytes(uint64(p.Size))}}
+		}
+		fmt.Println()
+		cmdutil.PrintTable(cmdutil.Table{
+			Headers: []string{"RESOURCE", "INPUTS AND OUTPUTS"},
+			Rows:    rows,
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestComputeStackStats(t *testing.T) {
+	urn := func(typ, name string) resource.URN {
+		return resource.URN("urn:pulumi:test::test::" + typ + "::" + name)
+	}
+	root := urn("pulumi:pulumi:Stack", "test-test")
+	prov := urn("pulumi:providers:pkgA", "default")
+	a := urn("pkgA:m:typA", "a")
+	b := urn("pkgA:m:typB", "b")
+	c := urn("pkgA:m:typA", "c")
+
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{Type: "pulumi:pulumi:Stack", URN: root},
+			{Type: "pulumi:providers:pkgA", URN: prov, Custom: true, ID: "0", Parent: root},
+			{
+				Type: "pkgA:m:typA", URN: a, Custom: true, ID: "1", Parent: root, Provider: string(prov) + "::0",
+				Protect: true,
+			},
+			{
+				Type: "pkgA:m:typB", URN: b, Custom: true, ID: "2", Parent: root, Provider: string(prov) + "::0",
+				Dependencies: []resource.URN{a},
+				Outputs: resource.PropertyMap{
+					"big": resource.NewStringProperty(strings.Repeat("x", 100)),
+				},
+			},
+			{
+				Type: "pkgA:m:typA", URN: c, Custom: true, ID: "3", Parent: root, Provider: string(prov) + "::0",
+				Delete: true,
+			},
+		},
+		PendingOperations: []resource.Operation{{Type: resource.OperationTypeCreating}},
+	}
+
+	stats, err := computeStackStats(snap, 1234, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 5, stats.Resources)
+	assert.Equal(t, 1, stats.Protected)
+	assert.Equal(t, 1, stats.PendingDeletion)
+	assert.Equal(t, 1, stats.PendingOperations)
+	assert.Equal(t, 1234, stats.StateSize)
+	assert.Equal(t, map[string]int{
+		"pulumi:pulumi:Stack":   1,
+		"pulumi:providers:pkgA": 1,
+		"pkgA:m:typA":           2,
+		"pkgA:m:typB":           1,
+	}, stats.ResourcesByType)
+	assert.Equal(t, map[string]int{"pkgA (default)": 3}, stats.ResourcesByProvider)
+	assert.Equal(t, []resource.URN{root, a, b}, stats.DeepestDependencyChain)
+	if assert.Len(t, stats.LargestPayloads, 2) {
+		assert.Equal(t, b, stats.LargestPayloads[0].URN)
+		assert.Greater(t, stats.LargestPayloads[0].Size, 100)
+	}
+
+	stats, err = computeStackStats(nil, 0, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 0, stats.Resources)
+	assert.Empty(t, stats.DeepestDependencyChain)
+}
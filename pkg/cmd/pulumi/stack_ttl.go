@@ -0,0 +1,206 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+)
+
+// stackExpiryKey is the stack configuration key that records when a stack expires, as an RFC 3339 timestamp.
+var stackExpiryKey = config.MustMakeKey("pulumi", "expiresAt")
+
+// stackExpiryTag is the stack tag that also records when a stack expires, for backends that support tags, so that
+// tools that reap expired stacks can find them without reading each stack's configuration.
+const stackExpiryTag apitype.StackTagName = "pulumi:expiresAt"
+
+func newStackSetTTLCmd() *cobra.Command {
+	var stack string
+	var clearTTL bool
+
+	cmd := &cobra.Command{
+		Use:   "set-ttl [<duration>]",
+		Short: "Set how long a stack lives before it expires",
+		Long: "Set how long a stack lives before it expires\n" +
+			"\n" +
+			"Records the time at which the stack expires, the given duration from now, in the stack's\n" +
+			"configuration as pulumi:expiresAt and, if the backend supports them, in its tags. The duration\n" +
+			"is given in hours, minutes or days, e.g. `72h` or `3d`.\n" +
+			"\n" +
+			"Nothing happens when a stack expires, but `pulumi destroy --expired-only` only destroys a stack\n" +
+			"that has expired, which makes it safe to run periodically against ephemeral stacks.",
+		Args: cmdutil.MaximumNArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			if clearTTL == (len(args) == 1) {
+				return errors.New("either a duration or --clear must be given")
+			}
+
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			s, err := requireStack(stack, false, opts, true /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+
+			var expiry time.Time
+			if !clearTTL {
+				ttl, err := parseStackTTL(args[0])
+				if err != nil {
+					return err
+				}
+				expiry = time.Now().UTC().Add(ttl).Truncate(time.Second)
+			}
+			if err = setStackExpiry(s, expiry); err != nil {
+				return err
+			}
+
+			if clearTTL {
+				fmt.Printf("Stack '%s' no longer expires\n", s.Ref())
+			} else {
+				fmt.Printf("Stack '%s' expires at %s\n", s.Ref(), expiry.Format(time.RFC3339))
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().BoolVar(
+		&clearTTL, "clear", false,
+		"Remove the stack's expiry instead of setting it")
+
+	return cmd
+}
+
+// parseStackTTL parses a stack's time to live, which is a positive Go duration such as `72h` or a whole number of
+// days such as `3d`.
+func parseStackTTL(s string) (time.Duration, error) {
+	var ttl time.Duration
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time to live %q: %w", s, err)
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid time to live %q: %w", s, err)
+		}
+		ttl = d
+	}
+	if ttl <= 0 {
+		return 0, fmt.Errorf("invalid time to live %q: it must be positive", s)
+	}
+	return ttl, nil
+}
+
+// setStackExpiry records the time at which a stack expires in its configuration and, if its backend supports them,
+// its tags. A zero time removes the stack's expiry.
+func setStackExpiry(s backend.Stack, expiry time.Time) error {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return err
+	}
+	if expiry.IsZero() {
+		delete(ps.Config, stackExpiryKey)
+	} else {
+		ps.Config[stackExpiryKey] = config.NewValue(expiry.Format(time.RFC3339))
+	}
+	if err = saveProjectStack(s, ps); err != nil {
+		return err
+	}
+
+	// The local backend does not persist tags.
+	if _, ok := s.Backend().(filestate.Backend); ok {
+		return nil
+	}
+	ctx := commandContext()
+	tags, err := backend.GetStackTags(ctx, s)
+	if err != nil {
+		return err
+	}
+	if tags == nil {
+		tags = make(map[apitype.StackTagName]string)
+	}
+	if expiry.IsZero() {
+		delete(tags, stackExpiryTag)
+	} else {
+		tags[stackExpiryTag] = expiry.Format(time.RFC3339)
+	}
+	return backend.UpdateStackTags(ctx, s, tags)
+}
+
+// getStackExpiry returns the time at which a stack expires, as recorded in its configuration or, failing that, its
+// tags. It returns false if the stack does not expire.
+func getStackExpiry(s backend.Stack) (time.Time, bool, error) {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	var value string
+	if v, ok := ps.Config[stackExpiryKey]; ok {
+		if value, err = v.Value(config.NopDecrypter); err != nil {
+			return time.Time{}, false, fmt.Errorf("reading %s: %w", stackExpiryKey, err)
+		}
+	} else if _, ok := s.Backend().(filestate.Backend); !ok {
+		tags, err := backend.GetStackTags(commandContext(), s)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		value = tags[stackExpiryTag]
+	}
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+
+	expiry, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("stack '%s' has an invalid expiry %q: %w", s.Ref(), value, err)
+	}
+	return expiry, true, nil
+}
+
+// checkStackExpired returns true if a stack has expired by the given time. If it has not, because its time to live
+// has yet to pass or it has none, it says so and returns false.
+func checkStackExpired(s backend.Stack, now time.Time) (bool, error) {
+	expiry, ok, err := getStackExpiry(s)
+	switch {
+	case err != nil:
+		return false, err
+	case !ok:
+		fmt.Printf("Stack '%s' has no time to live, so it is not destroyed\n", s.Ref())
+		return false, nil
+	case now.Before(expiry):
+		fmt.Printf("Stack '%s' expires at %s, so it is not destroyed yet\n", s.Ref(), expiry.Format(time.RFC3339))
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStackTTL(t *testing.T) {
+	ttl, err := parseStackTTL("72h")
+	assert.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, ttl)
+
+	ttl, err = parseStackTTL("90m")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, ttl)
+
+	ttl, err = parseStackTTL("3d")
+	assert.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, ttl)
+
+	for _, invalid := range []string{"", "0", "-1h", "0d", "1.5d", "d", "tomorrow"} {
+		_, err = parseStackTTL(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/backend/filestate"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+func newStackVerifyCmd() *cobra.Command {
+	var file string
+	var stackName string
+	var jsonOut bool
+	var signature bool
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Args:  cmdutil.NoArgs,
+		Short: "Check a stack's state for integrity problems",
+		Long: "Check a stack's state for integrity problems.\n" +
+			"\n" +
+			"This command runs the same integrity checks that Pulumi performs before using a\n" +
+			"stack's state: resources must come after their providers, parents and dependencies;\n" +
+			"provider references must be valid; and no two live resources may share a URN.\n" +
+			"Every violation found is reported, and the command exits with a non-zero status if\n" +
+			"there are any. No update is performed.\n" +
+			"\n" +
+			"By default the current stack's state is checked. Pass --file to check a deployment\n" +
+			"that was exported with `pulumi stack export` instead.\n" +
+			"\n" +
+			"Pass --signature to also check that the state of a stack in a self-managed backend\n" +
+			"matches the signature written alongside it when `PULUMI_CHECKPOINT_SIGNING_KEY` or\n" +
+			"`PULUMI_CHECKPOINT_SIGNING_KEY_FILE` is set, to detect changes made to the state file\n" +
+			"outside of Pulumi. Only the stack's current state is signed and checked; its backups\n" +
+			"and the checkpoints kept in its history are not.",
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			if signature && file != "" {
+				return result.Error("--signature may not be used with --file")
+			}
+
+			var deployment apitype.UntypedDeployment
+			if file != "" {
+				f, err := os.Open(file)
+				if err != nil {
+					return result.FromError(fmt.Errorf("could not open file: %w", err))
+				}
+				defer contract.IgnoreClose(f)
+
+				if err = json.NewDecoder(f).Decode(&deployment); err != nil {
+					return result.FromError(fmt.Errorf("could not read deployment: %w", err))
+				}
+			} else {
+				// The local backend refuses to load state that fails verification, which is exactly the state we
+				// want to look at here.
+				filestate.DisableIntegrityChecking = true
+
+				s, err := requireStack(stackName, false, opts, false /*setCurrent*/)
+				if err != nil {
+					return result.FromError(err)
+				}
+				if signature {
+					b, ok := s.Backend().(filestate.Backend)
+					if !ok {
+						return result.Errorf("stack '%s' is not in a self-managed backend, so its state is not signed",
+							s.Ref())
+					}
+					if err := b.VerifyCheckpointSignature(commandContext(), s.Ref()); err != nil {
+						return result.FromError(err)
+					}
+					if !jsonOut {
+						fmt.Println("The stack's state matches its signature")
+					}
+				}
+				exported, err := s.ExportDeployment(commandContext())
+				if err != nil {
+					return result.FromError(err)
+				}
+				deployment = *exported
+			}
+
+			snap, err := stack.DeserializeUntypedDeployment(&deployment, stack.DefaultSecretsProvider)
+			if err != nil {
+				return result.FromError(checkDeploymentVersionError(err, stackName))
+			}
+
+			violations := snap.IntegrityViolations()
+			if jsonOut {
+				if violations == nil {
+					violations = []deploy.IntegrityViolation{}
+				}
+				if err := printJSON(violations); err != nil {
+					return result.FromError(err)
+				}
+				if len(violations) > 0 {
+					return result.Bail()
+				}
+				return nil
+			}
+
+			if len(violations) == 0 {
+				fmt.Println("No integrity violations found")
+				return nil
+			}
+			for _, v := range violations {
+				cmdutil.Diag().Errorf(diag.Message(v.URN, "%s"), v.Message)
+			}
+			return result.Errorf("found %d integrity violation(s)", len(violations))
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stackName, "stack", "s", "", "The name of the stack to operate on. Defaults to the current stack")
+	cmd.PersistentFlags().StringVarP(
+		&file, "file", "", "", "A filename to read an exported deployment from instead of the current stack")
+	cmd.PersistentFlags().BoolVarP(
+		&jsonOut, "json", "j", false, "Emit output as JSON")
+	cmd.PersistentFlags().BoolVar(
+		&signature, "signature", false, "Also check the stack's state against its signature")
+
+	return cmd
+}
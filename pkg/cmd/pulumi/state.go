@@ -47,6 +47,12 @@ troubleshooting a stack or when performing specific edits that otherwise would r
 
 	cmd.AddCommand(newStateDeleteCommand())
 	cmd.AddCommand(newStateUnprotectCommand())
+	cmd.AddCommand(newStateLockCommand())
+	cmd.AddCommand(newStateUnlockCommand())
+	cmd.AddCommand(newStateDeprecateCommand())
+	cmd.AddCommand(newStateSearchCommand())
+	cmd.AddCommand(newStateExportSQLiteCommand())
+	cmd.AddCommand(newStateGCCommand())
 	return cmd
 }
 
@@ -0,0 +1,101 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/edit"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+
+	"github.com/spf13/cobra"
+)
+
+func newStateDeprecateCommand() *cobra.Command {
+	var after string
+	var clearDeprecation bool
+	var stack string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "deprecate <resource URN>",
+		Short: "Mark a resource in a stack's state as deprecated",
+		Long: `Mark a resource in a stack's state as deprecated
+
+This command schedules a resource for removal. Until the date given by --after, previews and updates
+warn that the resource is deprecated. The first update after that date deletes the resource, even if
+the program still declares it; resources that still depend on it must be changed first.
+
+The date may be given as YYYY-MM-DD, which is interpreted as midnight UTC, or as an RFC 3339 timestamp.
+Pass --clear instead of --after to remove a resource's deprecation.`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			yes = yes || skipConfirmations()
+			urn := resource.URN(args[0])
+
+			var removeAfter *time.Time
+			switch {
+			case clearDeprecation && after != "":
+				return result.Error("only one of --after and --clear may be specified")
+			case clearDeprecation:
+				// Leave removeAfter nil to clear the deprecation.
+			case after != "":
+				t, err := parseRemoveAfter(after)
+				if err != nil {
+					return result.FromError(err)
+				}
+				removeAfter = &t
+			default:
+				return result.Error("one of --after or --clear must be specified")
+			}
+
+			res := runStateEdit(stack, !yes, urn, edit.DeprecateResource(removeAfter))
+			if res != nil {
+				return res
+			}
+			if removeAfter == nil {
+				fmt.Println("Resource is no longer deprecated")
+			} else {
+				fmt.Printf("Resource successfully deprecated; it will be deleted after %s\n",
+					removeAfter.Format(time.RFC3339))
+			}
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().StringVar(&after, "after", "", "The date after which the resource will be deleted")
+	cmd.Flags().BoolVar(&clearDeprecation, "clear", false, "Remove the resource's deprecation")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+
+	return cmd
+}
+
+// parseRemoveAfter parses the removal date of a deprecated resource, which is either a date or an RFC 3339 timestamp.
+func parseRemoveAfter(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s': expected YYYY-MM-DD or an RFC 3339 timestamp", s)
+	}
+	return t, nil
+}
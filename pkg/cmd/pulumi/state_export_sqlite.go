@@ -0,0 +1,281 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/executable"
+)
+
+// sqliteSchema is the schema of the database written by `pulumi state export-sqlite`. Resources are identified by
+// their position in the snapshot, as a URN may appear more than once (e.g. for resources pending deletion).
+const sqliteSchema = `CREATE TABLE resources (
+    idx INTEGER PRIMARY KEY,
+    urn TEXT NOT NULL,
+    type TEXT NOT NULL,
+    name TEXT NOT NULL,
+    id TEXT,
+    custom INTEGER NOT NULL,
+    parent TEXT,
+    provider TEXT,
+    protect INTEGER NOT NULL,
+    external INTEGER NOT NULL,
+    pending_delete INTEGER NOT NULL,
+    pending_replacement INTEGER NOT NULL
+);
+CREATE INDEX resources_urn ON resources (urn);
+CREATE INDEX resources_type ON resources (type);
+CREATE TABLE dependencies (
+    resource INTEGER NOT NULL REFERENCES resources (idx),
+    dependency TEXT NOT NULL,
+    property TEXT
+);
+CREATE INDEX dependencies_dependency ON dependencies (dependency);
+CREATE TABLE properties (
+    resource INTEGER NOT NULL REFERENCES resources (idx),
+    kind TEXT NOT NULL,
+    name TEXT NOT NULL,
+    value TEXT,
+    secret INTEGER NOT NULL
+);
+CREATE INDEX properties_name ON properties (name);
+`
+
+func newStateExportSQLiteCommand() *cobra.Command {
+	var stack string
+	var sqlOnly bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "export-sqlite <file>",
+		Short: "Export a stack's state to a SQLite database",
+		Long: `Export a stack's state to a SQLite database
+
+This command writes the resources in the stack's most recent deployment to a new SQLite database so
+that the state may be queried with SQL. The database contains three tables:
+
+    resources      one row per resource, keyed by its position in the state (idx)
+    dependencies   one row per dependency of each resource; the property column names the
+                   property that introduced the dependency, if known
+    properties     one row per top-level input ('input') or output ('output') property of each
+                   resource, with its value encoded as JSON
+
+The values of secret properties are never exported; such rows have a NULL value and secret = 1.
+
+The database is written using the sqlite3 command-line tool, which must be on your PATH. Pass --sql
+to write the equivalent SQL script to <file> instead, e.g. to load it with another tool.
+
+With --force, an existing <file> is only replaced once the export has succeeded.`,
+		Args: cmdutil.ExactArgs(1),
+		Run: cmdutil.RunFunc(func(cmd *cobra.Command, args []string) error {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+			path := args[0]
+
+			if _, err := os.Stat(path); err == nil && !force {
+				return fmt.Errorf("'%s' already exists; pass --force to overwrite it", path)
+			}
+
+			var sqlite string
+			if !sqlOnly {
+				var err error
+				if sqlite, err = executable.FindExecutable("sqlite3"); err != nil {
+					return fmt.Errorf("could not find the sqlite3 executable on your PATH; install it, or pass --sql "+
+						"to write a SQL script instead: %w", err)
+				}
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return err
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return err
+			}
+			if snap == nil {
+				return fmt.Errorf("unable to find snapshot for stack %q", s.Ref())
+			}
+
+			// Export to a temporary file next to the destination, which replaces any existing file only once the
+			// export has succeeded.
+			tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*")
+			if err != nil {
+				return err
+			}
+			tmpPath := tmp.Name()
+			defer func() {
+				contract.IgnoreError(os.Remove(tmpPath)) // the file is gone if it was renamed.
+			}()
+
+			if sqlOnly {
+				if err := writeSQLiteExport(tmp, snap); err != nil {
+					contract.IgnoreClose(tmp)
+					return err
+				}
+				if err := tmp.Close(); err != nil {
+					return err
+				}
+			} else {
+				contract.IgnoreClose(tmp)
+
+				var script bytes.Buffer
+				if err := writeSQLiteExport(&script, snap); err != nil {
+					return err
+				}
+				sqliteCmd := exec.Command(sqlite, "-bail", tmpPath)
+				sqliteCmd.Stdin = &script
+				sqliteCmd.Stdout, sqliteCmd.Stderr = os.Stdout, os.Stderr
+				if err := sqliteCmd.Run(); err != nil {
+					return fmt.Errorf("running sqlite3: %w", err)
+				}
+			}
+
+			if err := os.Chmod(tmpPath, 0644); err != nil {
+				return err
+			}
+			if err := os.Rename(tmpPath, path); err != nil {
+				return err
+			}
+			if sqlOnly {
+				return nil
+			}
+
+			fmt.Printf("Exported %d resources to %s\n", len(snap.Resources), path)
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVar(&sqlOnly, "sql", false, "Write a SQL script rather than a SQLite database")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite the file if it already exists")
+
+	return cmd
+}
+
+// writeSQLiteExport writes a SQL script that creates the export schema and populates it from the given snapshot.
+func writeSQLiteExport(w io.Writer, snap *deploy.Snapshot) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("BEGIN TRANSACTION;\n")
+	b.WriteString(sqliteSchema)
+
+	for idx, res := range snap.Resources {
+		provider := ""
+		if res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			if err != nil {
+				return fmt.Errorf("parsing provider reference for %s: %w", res.URN, err)
+			}
+			provider = string(ref.URN())
+		}
+
+		fmt.Fprintf(b, "INSERT INTO resources VALUES (%d, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s);\n",
+			idx, sqlString(string(res.URN)), sqlString(string(res.Type)), sqlString(string(res.URN.Name())),
+			sqlNullString(string(res.ID)), sqlBool(res.Custom), sqlNullString(string(res.Parent)),
+			sqlNullString(provider), sqlBool(res.Protect), sqlBool(res.External), sqlBool(res.Delete),
+			sqlBool(res.PendingReplacement))
+
+		// Record the property that introduced each dependency, if any, and then any remaining dependencies.
+		attributed := make(map[resource.URN]bool)
+		keys := make([]string, 0, len(res.PropertyDependencies))
+		for key := range res.PropertyDependencies {
+			keys = append(keys, string(key))
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, dep := range res.PropertyDependencies[resource.PropertyKey(key)] {
+				attributed[dep] = true
+				fmt.Fprintf(b, "INSERT INTO dependencies VALUES (%d, %s, %s);\n",
+					idx, sqlString(string(dep)), sqlString(key))
+			}
+		}
+		for _, dep := range res.Dependencies {
+			if !attributed[dep] {
+				fmt.Fprintf(b, "INSERT INTO dependencies VALUES (%d, %s, NULL);\n", idx, sqlString(string(dep)))
+			}
+		}
+
+		if err := writeSQLiteProperties(b, idx, "input", res.Inputs); err != nil {
+			return err
+		}
+		if err := writeSQLiteProperties(b, idx, "output", res.Outputs); err != nil {
+			return err
+		}
+	}
+
+	b.WriteString("COMMIT;\n")
+	return b.Flush()
+}
+
+func writeSQLiteProperties(w io.Writer, idx int, kind string, props resource.PropertyMap) error {
+	for _, key := range props.StableKeys() {
+		v := props[key]
+		if v.ContainsSecrets() {
+			fmt.Fprintf(w, "INSERT INTO properties VALUES (%d, %s, %s, NULL, 1);\n",
+				idx, sqlString(kind), sqlString(string(key)))
+			continue
+		}
+
+		value, err := json.Marshal(v.Mappable())
+		if err != nil {
+			return fmt.Errorf("encoding property %s: %w", key, err)
+		}
+		fmt.Fprintf(w, "INSERT INTO properties VALUES (%d, %s, %s, %s, 0);\n",
+			idx, sqlString(kind), sqlString(string(key)), sqlString(string(value)))
+	}
+	return nil
+}
+
+// sqlString quotes a string as a SQL string literal.
+func sqlString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sqlNullString quotes a string as a SQL string literal, or returns NULL if it is empty.
+func sqlNullString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return sqlString(s)
+}
+
+func sqlBool(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
@@ -0,0 +1,70 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestWriteSQLiteExport(t *testing.T) {
+	prov := resource.URN("urn:pulumi:test::test::pulumi:providers:pkgA::default")
+	a := resource.URN("urn:pulumi:test::test::pkgA:m:typA::a")
+	b := resource.URN("urn:pulumi:test::test::pkgA:m:typA::o'brien")
+
+	snap := &deploy.Snapshot{
+		Resources: []*resource.State{
+			{Type: "pulumi:providers:pkgA", URN: prov, Custom: true, ID: "0"},
+			{Type: "pkgA:m:typA", URN: a, Custom: true, ID: "a-id", Provider: string(prov) + "::0", Protect: true},
+			{
+				Type: "pkgA:m:typA", URN: b, Custom: true, ID: "b-id", Provider: string(prov) + "::0",
+				Dependencies:         []resource.URN{a, prov},
+				PropertyDependencies: map[resource.PropertyKey][]resource.URN{"bucket": {a}},
+				Inputs: resource.PropertyMap{
+					"bucket":   resource.NewStringProperty("my-bucket"),
+					"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+				},
+				Outputs: resource.PropertyMap{
+					"tags": resource.NewObjectProperty(resource.PropertyMap{
+						"team": resource.NewStringProperty("payments"),
+					}),
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSQLiteExport(&buf, snap))
+	script := buf.String()
+
+	assert.Contains(t, script, "BEGIN TRANSACTION;\n")
+	assert.Contains(t, script, "INSERT INTO resources VALUES (1, '"+string(a)+"', 'pkgA:m:typA', 'a', 'a-id', 1, "+
+		"NULL, '"+string(prov)+"', 1, 0, 0, 0);\n")
+	assert.Contains(t, script, "'urn:pulumi:test::test::pkgA:m:typA::o''brien'")
+	assert.Contains(t, script, "INSERT INTO dependencies VALUES (2, '"+string(a)+"', 'bucket');\n")
+	assert.Contains(t, script, "INSERT INTO dependencies VALUES (2, '"+string(prov)+"', NULL);\n")
+	assert.NotContains(t, script, "INSERT INTO dependencies VALUES (2, '"+string(a)+"', NULL);\n")
+	assert.Contains(t, script, "INSERT INTO properties VALUES (2, 'input', 'bucket', '\"my-bucket\"', 0);\n")
+	assert.Contains(t, script, "INSERT INTO properties VALUES (2, 'input', 'password', NULL, 1);\n")
+	assert.Contains(t, script, "INSERT INTO properties VALUES (2, 'output', 'tags', '{\"team\":\"payments\"}', 0);\n")
+	assert.NotContains(t, script, "hunter2")
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("COMMIT;\n")))
+}
@@ -0,0 +1,98 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/edit"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+
+	"github.com/spf13/cobra"
+)
+
+func newStateGCCommand() *cobra.Command {
+	var stack string
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Removes unused provider resources from a stack's state",
+		Long: `Removes unused provider resources from a stack's state
+
+This command finds provider resources that no other resource in the stack's state refers to, whether as its
+provider, as a dependency, or as its parent, and removes them from the state. The providers that will be removed
+are shown before any change is made. Protected providers are never removed.`,
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			yes = yes || skipConfirmations()
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			s, err := requireStack(stack, true, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			unused := edit.UnusedProviders(snap)
+			if len(unused) == 0 {
+				fmt.Println("No unused providers found")
+				return nil
+			}
+
+			fmt.Printf("The following %d unused provider(s) will be removed from the state:\n", len(unused))
+			condemned := make(map[resource.URN]bool)
+			for _, provider := range unused {
+				condemned[provider.URN] = true
+				fmt.Print(opts.Color.Colorize(fmt.Sprintf("    %s- %s%s\n", colors.SpecDelete, provider.URN, colors.Reset)))
+			}
+			fmt.Println()
+
+			res := runTotalStateEdit(stack, !yes, func(_ display.Options, snap *deploy.Snapshot) error {
+				// Only remove the providers that were shown to the user and that are still unused.
+				for _, provider := range edit.UnusedProviders(snap) {
+					if !condemned[provider.URN] {
+						continue
+					}
+					if err := edit.DeleteResource(snap, provider); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if res != nil {
+				return res
+			}
+			fmt.Println("Unused providers removed successfully")
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts")
+	return cmd
+}
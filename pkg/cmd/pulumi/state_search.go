@@ -0,0 +1,217 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+
+	"github.com/spf13/cobra"
+)
+
+func newStateSearchCommand() *cobra.Command {
+	var stack string
+	var properties []string
+	var typ string
+	var id string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "search",
+		Short: "Find resources in a stack's state by type, ID or property value",
+		Long: `Find resources in a stack's state by type, ID or property value
+
+This command scans the inputs and outputs of every resource in the stack's state and prints the resources
+that match all of the given filters. Property filters take the form <path>=<value>, where <path> is a
+property path such as 'tags.Team' or 'ports[0]', e.g.
+
+    pulumi state search --property tags.Team=payments
+    pulumi state search --type aws:s3/bucket:Bucket --property bucket=my-bucket
+    pulumi state search --id i-0123456789abcdef0
+
+Only string, number and boolean values can be matched. Secret values are never searched.`,
+		Args: cmdutil.NoArgs,
+		Run: cmdutil.RunResultFunc(func(cmd *cobra.Command, args []string) result.Result {
+			opts := display.Options{
+				Color: cmdutil.GetGlobalColorization(),
+			}
+
+			if output != "table" && output != "json" {
+				return result.Errorf("unknown output format '%s'; expected 'table' or 'json'", output)
+			}
+			filter, err := newStateSearchFilter(typ, id, properties)
+			if err != nil {
+				return result.FromError(err)
+			}
+			if filter.empty() {
+				return result.Error("at least one of --property, --type or --id must be specified")
+			}
+
+			s, err := requireStack(stack, false, opts, false /*setCurrent*/)
+			if err != nil {
+				return result.FromError(err)
+			}
+			snap, err := s.Snapshot(commandContext())
+			if err != nil {
+				return result.FromError(err)
+			}
+
+			matches := filter.search(snap)
+			if output == "json" {
+				if err := printJSON(matches); err != nil {
+					return result.FromError(err)
+				}
+				return nil
+			}
+
+			if len(matches) == 0 {
+				fmt.Println("No matching resources found")
+				return nil
+			}
+			rows := make([]cmdutil.TableRow, len(matches))
+			for i, m := range matches {
+				idColumn := string(m.ID)
+				if idColumn == "" {
+					idColumn = naString
+				}
+				rows[i] = cmdutil.TableRow{Columns: []string{string(m.Type), idColumn, string(m.URN)}}
+			}
+			cmdutil.PrintTable(cmdutil.Table{
+				Headers: []string{"TYPE", "ID", "URN"},
+				Rows:    rows,
+			})
+			return nil
+		}),
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&stack, "stack", "s", "",
+		"The name of the stack to operate on. Defaults to the current stack")
+	cmd.Flags().StringArrayVar(&properties, "property", nil,
+		"Only show resources with an input or output property at <path> equal to <value>, given as <path>=<value>")
+	cmd.Flags().StringVar(&typ, "type", "", "Only show resources of the given type")
+	cmd.Flags().StringVar(&id, "id", "", "Only show resources with the given ID")
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "The output format: 'table' or 'json'")
+
+	return cmd
+}
+
+// stateSearchMatch describes a resource that matched a state search.
+type stateSearchMatch struct {
+	URN  resource.URN `json:"urn"`
+	Type tokens.Type  `json:"type"`
+	ID   resource.ID  `json:"id,omitempty"`
+	// Properties holds the matched value of each property filter, keyed by the filter's path.
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// stateSearchProperty requires the property at path to have the given value.
+type stateSearchProperty struct {
+	key   string
+	path  resource.PropertyPath
+	value string
+}
+
+// stateSearchFilter selects the resources in a snapshot that satisfy all of its conditions.
+type stateSearchFilter struct {
+	typ        tokens.Type
+	id         resource.ID
+	properties []stateSearchProperty
+}
+
+func newStateSearchFilter(typ, id string, properties []string) (*stateSearchFilter, error) {
+	filter := &stateSearchFilter{typ: tokens.Type(typ), id: resource.ID(id)}
+	for _, p := range properties {
+		eq := strings.Index(p, "=")
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid property filter '%s': expected <path>=<value>", p)
+		}
+		key := p[:eq]
+		path, err := resource.ParsePropertyPath(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid property path '%s': %w", key, err)
+		}
+		filter.properties = append(filter.properties, stateSearchProperty{key: key, path: path, value: p[eq+1:]})
+	}
+	return filter, nil
+}
+
+func (f *stateSearchFilter) empty() bool {
+	return f.typ == "" && f.id == "" && len(f.properties) == 0
+}
+
+// search returns the resources in the snapshot that match the filter, in snapshot order.
+func (f *stateSearchFilter) search(snap *deploy.Snapshot) []stateSearchMatch {
+	matches := []stateSearchMatch{}
+	if snap == nil {
+		return matches
+	}
+
+	for _, res := range snap.Resources {
+		if f.typ != "" && res.Type != f.typ {
+			continue
+		}
+		if f.id != "" && res.ID != f.id {
+			continue
+		}
+
+		match := stateSearchMatch{URN: res.URN, Type: res.Type, ID: res.ID}
+		matched := true
+		for _, p := range f.properties {
+			if !propertyHasValue(res.Inputs, p.path, p.value) && !propertyHasValue(res.Outputs, p.path, p.value) {
+				matched = false
+				break
+			}
+			if match.Properties == nil {
+				match.Properties = make(map[string]string)
+			}
+			match.Properties[p.key] = p.value
+		}
+		if matched {
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// propertyHasValue returns true if the property at the given path is a string, number or boolean whose textual form is
+// the given value.
+func propertyHasValue(props resource.PropertyMap, path resource.PropertyPath, value string) bool {
+	if props == nil {
+		return false
+	}
+	v, ok := path.Get(resource.NewObjectProperty(props))
+	if !ok {
+		return false
+	}
+	switch {
+	case v.IsString():
+		return v.StringValue() == value
+	case v.IsNumber():
+		return strconv.FormatFloat(v.NumberValue(), 'f', -1, 64) == value
+	case v.IsBool():
+		return strconv.FormatBool(v.BoolValue()) == value
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestStateSearch(t *testing.T) {
+	snap := &deploy.Snapshot{Resources: []*resource.State{
+		{
+			Type:   "aws:s3/bucket:Bucket",
+			URN:    "urn:pulumi:test::test::aws:s3/bucket:Bucket::payments",
+			Custom: true,
+			ID:     "payments-1234",
+			Inputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+				"tags": map[string]interface{}{"Team": "payments"},
+			}),
+			Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+				"versioning": []interface{}{map[string]interface{}{"enabled": true}},
+				"size":       42,
+			}),
+		},
+		{
+			Type:   "aws:s3/bucket:Bucket",
+			URN:    "urn:pulumi:test::test::aws:s3/bucket:Bucket::search",
+			Custom: true,
+			ID:     "search-5678",
+			Inputs: resource.PropertyMap{
+				"tags": resource.MakeSecret(resource.NewObjectProperty(resource.NewPropertyMapFromMap(
+					map[string]interface{}{"Team": "payments"}))),
+			},
+		},
+		{
+			Type:   "aws:ec2/instance:Instance",
+			URN:    "urn:pulumi:test::test::aws:ec2/instance:Instance::web",
+			Custom: true,
+			ID:     "i-0123456789abcdef0",
+			Outputs: resource.NewPropertyMapFromMap(map[string]interface{}{
+				"tags": map[string]interface{}{"Team": "web"},
+			}),
+		},
+	}}
+
+	search := func(typ, id string, properties ...string) []resource.URN {
+		filter, err := newStateSearchFilter(typ, id, properties)
+		require.NoError(t, err)
+		var urns []resource.URN
+		for _, m := range filter.search(snap) {
+			urns = append(urns, m.URN)
+		}
+		return urns
+	}
+	payments := resource.URN("urn:pulumi:test::test::aws:s3/bucket:Bucket::payments")
+	search5678 := resource.URN("urn:pulumi:test::test::aws:s3/bucket:Bucket::search")
+	web := resource.URN("urn:pulumi:test::test::aws:ec2/instance:Instance::web")
+
+	// Secret values are not searched.
+	assert.Equal(t, []resource.URN{payments}, search("", "", "tags.Team=payments"))
+	assert.Equal(t, []resource.URN{web}, search("", "", "tags.Team=web"))
+	assert.Equal(t, []resource.URN{payments}, search("", "", "versioning[0].enabled=true"))
+	assert.Equal(t, []resource.URN{payments}, search("", "", "size=42"))
+	assert.Equal(t, []resource.URN{payments, search5678}, search("aws:s3/bucket:Bucket", ""))
+	assert.Equal(t, []resource.URN{web}, search("", "i-0123456789abcdef0"))
+	assert.Nil(t, search("aws:s3/bucket:Bucket", "", "tags.Team=web"))
+	assert.Nil(t, search("", "", "tags.Team=payments", "size=43"))
+
+	filter, err := newStateSearchFilter("", "", []string{"tags.Team=payments"})
+	require.NoError(t, err)
+	matches := filter.search(snap)
+	require.Len(t, matches, 1)
+	assert.Equal(t, map[string]string{"tags.Team": "payments"}, matches[0].Properties)
+
+	_, err = newStateSearchFilter("", "", []string{"payments"})
+	assert.Error(t, err)
+	_, err = newStateSearchFilter("", "", []string{"=payments"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// outputTargetPrefix marks a target that selects the resources that contribute to a stack output, e.g.
+// `--target output:apiUrl`, rather than a URN or a glob.
+const outputTargetPrefix = "output:"
+
+// resolveOutputTarget returns the URNs of the resources that contribute to the named output of the stack whose
+// resources are given, in snapshot order.
+//
+// The resources that contribute to an output are those that it refers to, either as resource references or as the
+// recorded dependencies of output values, along with their children if they are components. Plain values that happen
+// to equal a resource's ID or outputs are not taken as evidence that the resource contributed to them.
+func resolveOutputTarget(resources []*resource.State, name string) ([]resource.URN, error) {
+	var root *resource.State
+	for _, res := range resources {
+		if res.Type == resource.RootStackType && res.Parent == "" {
+			root = res
+			break
+		}
+	}
+	if root == nil {
+		return nil, fmt.Errorf("cannot target output %q: the stack has no outputs", name)
+	}
+	output, ok := root.Outputs[resource.PropertyKey(name)]
+	if !ok {
+		return nil, fmt.Errorf("cannot target output %q: the stack has no output with that name", name)
+	}
+
+	var refs []resource.URN
+	collectOutputReferences(output, &refs)
+
+	contributors := make(map[resource.URN]bool)
+	dg := graph.NewDependencyGraph(resources)
+	for _, urn := range refs {
+		contributors[urn] = true
+		for _, child := range dg.TransitiveChildrenOf(urn) {
+			contributors[child.URN] = true
+		}
+	}
+
+	var urns []resource.URN
+	for _, res := range resources {
+		if contributors[res.URN] && !res.Delete {
+			contributors[res.URN] = false
+			urns = append(urns, res.URN)
+		}
+	}
+	if len(urns) == 0 {
+		return nil, fmt.Errorf("cannot target output %q: it does not refer to any resources in the stack, "+
+			"and no dependencies were recorded for it", name)
+	}
+	return urns, nil
+}
+
+// collectOutputReferences collects the URNs of the resources that the given value refers to, looking through secrets,
+// outputs, arrays, and objects. The dependencies of outputs are collected as references.
+func collectOutputReferences(v resource.PropertyValue, refs *[]resource.URN) {
+	switch {
+	case v.IsResourceReference():
+		*refs = append(*refs, v.ResourceReferenceValue().URN)
+	case v.IsSecret():
+		collectOutputReferences(v.SecretValue().Element, refs)
+	case v.IsOutput():
+		*refs = append(*refs, v.OutputValue().Dependencies...)
+		collectOutputReferences(v.OutputValue().Element, refs)
+	case v.IsArray():
+		for _, e := range v.ArrayValue() {
+			collectOutputReferences(e, refs)
+		}
+	case v.IsObject():
+		for _, e := range v.ObjectValue() {
+			collectOutputReferences(e, refs)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// readTargetFile reads the targets listed in the given file, one URN or glob per line. Blank lines and lines that
+// start with `#` are ignored.
+func readTargetFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading targets: %w", err)
+	}
+	defer contract.IgnoreClose(f)
+
+	var targets []string
+	scanner := bufio.NewScanner(f)
+	// URNs can be long, so allow for lines much longer than the scanner's default limit.
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		targets = append(targets, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading targets from %s: %w", path, err)
+	}
+	return targets, nil
+}
+
+// isTargetGlob returns true if the given target is a glob that selects resources by pattern rather than a URN.
+func isTargetGlob(target string) bool {
+	return strings.ContainsAny(target, "*?")
+}
+
+// compileTargetGlob compiles a target glob, in which `*` matches any sequence of characters, including `:` and `/`,
+// and `?` matches any single character.
+func compileTargetGlob(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// getTargetURNs returns the URNs selected by the targets given with --target and read from the --target-file, if
+// there is one. Globs and stack output targets are resolved against the stack's current snapshot. Stack output targets
+// are an error unless allowOutputTargets is true, as they are for destroys.
+func getTargetURNs(s backend.Stack, targets []string, targetFile string,
+	allowOutputTargets bool) ([]resource.URN, error) {
+
+	if targetFile != "" {
+		fromFile, err := readTargetFile(targetFile)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(append([]string{}, targets...), fromFile...)
+	}
+
+	var resources []*resource.State
+	needSnapshot := false
+	for _, t := range targets {
+		isOutput := strings.HasPrefix(t, outputTargetPrefix)
+		if isOutput && !allowOutputTargets {
+			return nil, fmt.Errorf("target %q: stack output targets cannot be used to destroy resources", t)
+		}
+		needSnapshot = needSnapshot || isTargetGlob(t) || isOutput
+	}
+	if needSnapshot {
+		snap, err := s.Snapshot(commandContext())
+		if err != nil {
+			return nil, fmt.Errorf("getting snapshot: %w", err)
+		}
+		if snap != nil {
+			resources = snap.Resources
+		}
+	}
+	return expandTargets(targets, resources)
+}
+
+// expandTargets returns the URNs selected by the given targets, in order and without duplicates. URNs are used as
+// they are, globs are replaced with the URNs of the existing resources that match them, and `output:<name>` targets
+// are replaced with the URNs of the resources that contribute to the named stack output. It is an error for a glob
+// or an output target to select no resources.
+func expandTargets(targets []string, resources []*resource.State) ([]resource.URN, error) {
+	urns := []resource.URN{}
+	seen := make(map[resource.URN]bool)
+	add := func(urn resource.URN) {
+		if !seen[urn] {
+			seen[urn] = true
+			urns = append(urns, urn)
+		}
+	}
+
+	for _, t := range targets {
+		switch {
+		case strings.HasPrefix(t, outputTargetPrefix):
+			contributors, err := resolveOutputTarget(resources, strings.TrimPrefix(t, outputTargetPrefix))
+			if err != nil {
+				return nil, err
+			}
+			for _, urn := range contributors {
+				add(urn)
+			}
+		case isTargetGlob(t):
+			glob, matched := compileTargetGlob(t), false
+			for _, res := range resources {
+				if glob.MatchString(string(res.URN)) {
+					add(res.URN)
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("target %q does not match any resources in the stack", t)
+			}
+		default:
+			add(resource.URN(t))
+		}
+	}
+	return urns, nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestReadTargetFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "targets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "targets.txt")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`# Buckets to rebuild
+urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs
+
+  urn:pulumi:dev::proj::aws:s3/bucket:Bucket::assets
+# Every instance
+urn:pulumi:dev::proj::aws:ec2/instance:Instance::*
+`), 0600))
+
+	targets, err := readTargetFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs",
+		"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::assets",
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::*",
+	}, targets)
+
+	_, err = readTargetFile(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
+
+func TestExpandTargets(t *testing.T) {
+	existing := []*resource.State{
+		{URN: "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs"},
+		{URN: "urn:pulumi:dev::proj::aws:ec2/instance:Instance::web-1"},
+		{URN: "urn:pulumi:dev::proj::aws:ec2/instance:Instance::web-2"},
+		{URN: "urn:pulumi:dev::proj::my:component:Web$aws:ec2/instance:Instance::web-3"},
+	}
+
+	urns, err := expandTargets([]string{
+		"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::new",
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::web-?",
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::web-1",
+	}, existing)
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{
+		"urn:pulumi:dev::proj::aws:s3/bucket:Bucket::new",
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::web-1",
+		"urn:pulumi:dev::proj::aws:ec2/instance:Instance::web-2",
+	}, urns)
+
+	// `*` matches across the separators in URNs.
+	urns, err = expandTargets([]string{"*aws:ec2/instance:Instance::*"}, existing)
+	assert.NoError(t, err)
+	assert.Len(t, urns, 3)
+
+	_, err = expandTargets([]string{"urn:pulumi:dev::proj::gcp:*"}, existing)
+	assert.Error(t, err)
+
+	urns, err = expandTargets(nil, existing)
+	assert.NoError(t, err)
+	assert.Empty(t, urns)
+}
+
+func TestResolveOutputTarget(t *testing.T) {
+	const (
+		stackURN  = resource.URN("urn:pulumi:dev::proj::pulumi:pulumi:Stack::proj-dev")
+		apiURN    = resource.URN("urn:pulumi:dev::proj::aws:apigateway/restApi:RestApi::api")
+		stageURN  = resource.URN("urn:pulumi:dev::proj::aws:apigateway/stage:Stage::stage")
+		bucketURN = resource.URN("urn:pulumi:dev::proj::aws:s3/bucket:Bucket::site")
+		webURN    = resource.URN("urn:pulumi:dev::proj::my:index:Web::web")
+		childURN  = resource.URN("urn:pulumi:dev::proj::my:index:Web$aws:ec2/instance:Instance::web")
+	)
+	resources := []*resource.State{
+		{
+			URN:  stackURN,
+			Type: resource.RootStackType,
+			Outputs: resource.PropertyMap{
+				"apiUrl": resource.NewOutputProperty(resource.Output{
+					Element:      resource.NewStringProperty("https://abc123xyz.execute-api.us-east-1.amazonaws.com/prod"),
+					Known:        true,
+					Dependencies: []resource.URN{apiURN, stageURN},
+				}),
+				"bucketName": resource.NewStringProperty("site-bucket-1a2b3c"),
+				"web":        resource.MakeComponentResourceReference(webURN, ""),
+			},
+		},
+		{URN: apiURN, ID: "abc123xyz", Custom: true},
+		{URN: stageURN, ID: "ags-abc123xyz-prod", Custom: true},
+		{URN: bucketURN, ID: "site-bucket-1a2b3c", Custom: true},
+		{URN: webURN},
+		{URN: childURN, Parent: webURN, Custom: true},
+	}
+
+	urns, err := resolveOutputTarget(resources, "apiUrl")
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{apiURN, stageURN}, urns)
+
+	// Referring to a component targets its children too.
+	urns, err = resolveOutputTarget(resources, "web")
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{webURN, childURN}, urns)
+
+	// A plain value is not traced back to the resource it happens to equal.
+	_, err = resolveOutputTarget(resources, "bucketName")
+	assert.Error(t, err)
+
+	_, err = resolveOutputTarget(resources, "missing")
+	assert.Error(t, err)
+
+	urns, err = expandTargets([]string{"output:web"}, resources)
+	assert.NoError(t, err)
+	assert.Equal(t, []resource.URN{webURN, childURN}, urns)
+}
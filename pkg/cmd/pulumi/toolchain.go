@@ -0,0 +1,124 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/blang/semver"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// toolchainHistoryPageSize is the number of recent updates that are searched for the last successful update when
+// checking for toolchain drift.
+const toolchainHistoryPageSize = 10
+
+// addToolchainMetadataToEnvironment records a fingerprint of the toolchain performing an update: the CLI version, the
+// operating system and architecture, and the project's language runtime and its version.
+func addToolchainMetadataToEnvironment(env map[string]string, proj *workspace.Project) {
+	env[backend.ToolchainCLIVersion] = getCLIAbout().Version
+	env[backend.ToolchainOS] = runtime.GOOS
+	env[backend.ToolchainArch] = runtime.GOARCH
+
+	if proj == nil {
+		return
+	}
+	env[backend.ToolchainRuntime] = proj.Runtime.Name()
+	runtimeAbout, err := getProjectRuntimeAbout(proj)
+	if err != nil {
+		logging.V(3).Infof("errors detecting language runtime version: %s", err)
+		return
+	}
+	env[backend.ToolchainRuntimeVersion] = runtimeAbout.Version
+}
+
+// toolchainDrift compares the toolchain fingerprints recorded in two update environments and describes each
+// significant difference. Differences in patch versions are not considered significant, nor are values that are
+// missing from either environment (e.g. because the previous update was performed by an older CLI).
+func toolchainDrift(prev, cur map[string]string) []string {
+	var drift []string
+	compare := func(key, what string, differ func(a, b string) bool) {
+		a, b := prev[key], cur[key]
+		if a != "" && b != "" && differ(a, b) {
+			drift = append(drift, fmt.Sprintf("%s changed from %s to %s", what, a, b))
+		}
+	}
+
+	compare(backend.ToolchainOS, "operating system", stringsDiffer)
+	compare(backend.ToolchainArch, "architecture", stringsDiffer)
+	compare(backend.ToolchainCLIVersion, "Pulumi CLI version", minorVersionsDiffer)
+	if prev[backend.ToolchainRuntime] != cur[backend.ToolchainRuntime] {
+		compare(backend.ToolchainRuntime, "language runtime", stringsDiffer)
+	} else {
+		compare(backend.ToolchainRuntimeVersion, cur[backend.ToolchainRuntime]+" version", minorVersionsDiffer)
+	}
+	return drift
+}
+
+func stringsDiffer(a, b string) bool {
+	return a != b
+}
+
+// minorVersionsDiffer returns true if two versions differ in their major or minor components. Versions that cannot be
+// parsed are compared as strings.
+func minorVersionsDiffer(a, b string) bool {
+	fieldsA, fieldsB := strings.Fields(a), strings.Fields(b)
+	if len(fieldsA) == 0 || len(fieldsB) == 0 {
+		return a != b
+	}
+
+	// Some runtimes report more than a version (e.g. `go version` also reports the platform), so only the first
+	// field is parsed.
+	va, errA := semver.ParseTolerant(fieldsA[0])
+	vb, errB := semver.ParseTolerant(fieldsB[0])
+	if errA != nil || errB != nil {
+		return a != b
+	}
+	return va.Major != vb.Major || va.Minor != vb.Minor
+}
+
+// warnOnToolchainDrift warns if the toolchain described by the given update environment differs significantly from
+// the toolchain that performed the stack's last successful update. Failing to fetch the stack's history is not an
+// error; the check is simply skipped.
+func warnOnToolchainDrift(ctx context.Context, s backend.Stack, env map[string]string) {
+	updates, err := s.Backend().GetHistory(ctx, s.Ref(), toolchainHistoryPageSize, 1 /*page*/)
+	if err != nil {
+		logging.V(3).Infof("unable to fetch history to check for toolchain drift: %s", err)
+		return
+	}
+
+	for _, update := range updates {
+		if update.Result != backend.SucceededResult || update.Kind != apitype.UpdateUpdate {
+			continue
+		}
+
+		drift := toolchainDrift(update.Environment, env)
+		if len(drift) > 0 {
+			msg := fmt.Sprintf("the toolchain differs from the one that performed the last successful update "+
+				"of this stack (version %d):\n    %s", update.Version, strings.Join(drift, "\n    "))
+			cmdutil.Diag().Warningf(diag.RawMessage("" /*urn*/, msg))
+		}
+		return
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/backend"
+)
+
+func TestToolchainDrift(t *testing.T) {
+	prev := map[string]string{
+		backend.ToolchainCLIVersion:     "3.10.1",
+		backend.ToolchainOS:             "linux",
+		backend.ToolchainArch:           "amd64",
+		backend.ToolchainRuntime:        "nodejs",
+		backend.ToolchainRuntimeVersion: "v14.17.0",
+	}
+
+	// Identical toolchains and patch-level differences are not drift.
+	assert.Empty(t, toolchainDrift(prev, prev))
+	assert.Empty(t, toolchainDrift(prev, map[string]string{
+		backend.ToolchainCLIVersion:     "3.10.3",
+		backend.ToolchainOS:             "linux",
+		backend.ToolchainArch:           "amd64",
+		backend.ToolchainRuntime:        "nodejs",
+		backend.ToolchainRuntimeVersion: "v14.17.6",
+	}))
+
+	// Updates performed by older CLIs have no fingerprint, which is not drift either.
+	assert.Empty(t, toolchainDrift(map[string]string{}, prev))
+
+	assert.Equal(t, []string{
+		"operating system changed from linux to darwin",
+		"architecture changed from amd64 to arm64",
+		"Pulumi CLI version changed from 3.10.1 to 3.12.0",
+		"nodejs version changed from v14.17.0 to v16.3.0",
+	}, toolchainDrift(prev, map[string]string{
+		backend.ToolchainCLIVersion:     "3.12.0",
+		backend.ToolchainOS:             "darwin",
+		backend.ToolchainArch:           "arm64",
+		backend.ToolchainRuntime:        "nodejs",
+		backend.ToolchainRuntimeVersion: "v16.3.0",
+	}))
+
+	// A change of language runtime is reported instead of a change in the runtime's version.
+	assert.Equal(t, []string{
+		"language runtime changed from nodejs to python",
+	}, toolchainDrift(prev, map[string]string{
+		backend.ToolchainRuntime:        "python",
+		backend.ToolchainRuntimeVersion: "v3.9.5",
+	}))
+}
+
+func TestMinorVersionsDiffer(t *testing.T) {
+	assert.False(t, minorVersionsDiffer("v1.16.5 linux/amd64", "v1.16.7 darwin/arm64"))
+	assert.True(t, minorVersionsDiffer("v1.16.5 linux/amd64", "v1.17 linux/amd64"))
+	assert.True(t, minorVersionsDiffer("dev", "v3.0.0"))
+	assert.False(t, minorVersionsDiffer("dev", "dev"))
+}
@@ -21,6 +21,8 @@ import (
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -29,6 +31,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
@@ -52,6 +55,7 @@ func newUpCmd() *cobra.Command {
 	var execAgent string
 	var stack string
 	var configArray []string
+	var saveConfigArray bool
 	var path bool
 	var client string
 
@@ -61,6 +65,7 @@ func newUpCmd() *cobra.Command {
 	var policyPackConfigPaths []string
 	var diffDisplay bool
 	var eventLogPath string
+	var reports []string
 	var parallel int
 	var refresh string
 	var showConfig bool
@@ -69,13 +74,32 @@ func newUpCmd() *cobra.Command {
 	var showReads bool
 	var skipPreview bool
 	var suppressOutputs bool
+	var ciAnnotations bool
+	var ciFormat string
+	var quiet bool
+	var verbosity int
+	var noTruncate bool
+	var displayWidth int
+	var notifyURL string
+	var eventSinks []string
+	var heartbeatInterval time.Duration
+	var waitForLock time.Duration
+	var preflight bool
+	var providerVersions []string
+	var profileResources bool
+	var slowStepThreshold time.Duration
 	var suppressPermalink string
 	var yes bool
 	var secretsProvider string
 	var targets []string
+	var targetFile string
 	var replaces []string
 	var targetReplaces []string
 	var targetDependents bool
+	var excludeProtected bool
+	var cascade bool
+	var cleanupDefaultProviders bool
+	var rollbackOnFailure bool
 
 	// up implementation used when the source of the Pulumi program is in the current working directory.
 	upWorkingDirectory := func(opts backend.UpdateOptions) result.Result {
@@ -84,9 +108,11 @@ func newUpCmd() *cobra.Command {
 			return result.FromError(err)
 		}
 
-		// Save any config values passed via flags.
-		if err := parseAndSaveConfigArray(s, configArray, path); err != nil {
-			return result.FromError(err)
+		// Save any config values passed via flags, unless they should only apply to this update.
+		if saveConfigArray {
+			if err := parseAndSaveConfigArray(s, configArray, path); err != nil {
+				return result.FromError(err)
+			}
 		}
 
 		proj, root, err := readProjectForUpdate(client)
@@ -94,11 +120,13 @@ func newUpCmd() *cobra.Command {
 			return result.FromError(err)
 		}
 
-		m, err := getUpdateMetadata(message, root, execKind, execAgent)
+		m, err := getUpdateMetadata(message, proj, root, execKind, execAgent)
 		if err != nil {
 			return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
 		}
 
+		warnOnToolchainDrift(commandContext(), s, m.Environment)
+
 		sm, err := getStackSecretsManager(s)
 		if err != nil {
 			return result.FromError(fmt.Errorf("getting secrets manager: %w", err))
@@ -108,10 +136,18 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
 		}
+		if !saveConfigArray {
+			if err = applyEphemeralConfig(&cfg, m, configArray, path); err != nil {
+				return result.FromError(err)
+			}
+		}
+		if err = proj.ValidateConfig(cfg.Config, cfg.Decrypter); err != nil {
+			return result.FromError(fmt.Errorf("validating stack configuration: %w", err))
+		}
 
-		targetURNs := []resource.URN{}
-		for _, t := range targets {
-			targetURNs = append(targetURNs, resource.URN(t))
+		targetURNs, err := getTargetURNs(s, targets, targetFile, true)
+		if err != nil {
+			return result.FromError(err)
 		}
 
 		replaceURNs := []resource.URN{}
@@ -128,8 +164,25 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return result.FromError(err)
 		}
+
+		var excludeURNs []resource.URN
+		if excludeProtected {
+			if excludeURNs, err = excludeProtectedResources(s, "update"); err != nil {
+				return result.FromError(err)
+			}
+		}
+
+		providerVersionPins, err := parseProviderVersions(providerVersions)
+		if err != nil {
+			return result.FromError(err)
+		}
+		stepHooks, err := getStepHooks(proj, s)
+		if err != nil {
+			return result.FromError(err)
+		}
+
 		opts.Engine = engine.UpdateOptions{
-			LocalPolicyPacks:          engine.MakeLocalPolicyPacks(policyPackPaths, policyPackConfigPaths),
+			LocalPolicyPacks:          makeLocalPolicyPacks(proj, root, policyPackPaths, policyPackConfigPaths),
 			Parallel:                  parallel,
 			Debug:                     debug,
 			Refresh:                   refreshOption,
@@ -141,9 +194,27 @@ func newUpCmd() *cobra.Command {
 			DisableOutputValues:       disableOutputValues(),
 			UpdateTargets:             targetURNs,
 			TargetDependents:          targetDependents,
+			ExcludeTargets:            excludeURNs,
+			CleanupDefaultProviders:   cleanupDefaultProviders,
+			Preflight:                 preflight,
+			ProviderVersions:          providerVersionPins,
+			StepHooks:                 stepHooks,
+		}
+		if err = applyApprovalGates(s, cmdutil.Interactive(), &opts); err != nil {
+			return result.FromError(err)
+		}
+		if err = applyRolloutWaves(s, cmdutil.Interactive(), &opts); err != nil {
+			return result.FromError(err)
+		}
+
+		var prev *deploy.Snapshot
+		if rollbackOnFailure {
+			if prev, err = s.Snapshot(commandContext()); err != nil {
+				return result.FromError(fmt.Errorf("getting snapshot to roll back to: %w", err))
+			}
 		}
 
-		changes, res := s.Update(commandContext(), backend.UpdateOperation{
+		op := backend.UpdateOperation{
 			Proj:               proj,
 			Root:               root,
 			M:                  m,
@@ -151,10 +222,16 @@ func newUpCmd() *cobra.Command {
 			StackConfiguration: cfg,
 			SecretsManager:     sm,
 			Scopes:             cancellationScopes,
-		})
+		}
+		notifier := newOperationNotifier(notifyURL, s, proj, apitype.UpdateUpdate, cfg)
+		notifier.started()
+		changes, res := s.Update(commandContext(), op)
+		notifier.finished(changes, res)
 		switch {
 		case res != nil && res.Error() == context.Canceled:
 			return result.FromError(errors.New("update cancelled"))
+		case res != nil && rollbackOnFailure:
+			return rollbackFailedUpdate(s, op, prev, res)
 		case res != nil:
 			return PrintEngineResult(res)
 		case expectNop && changes != nil && changes.HasChanges():
@@ -278,7 +355,7 @@ func newUpCmd() *cobra.Command {
 			return result.FromError(err)
 		}
 
-		m, err := getUpdateMetadata(message, root, execKind, execAgent)
+		m, err := getUpdateMetadata(message, proj, root, execKind, execAgent)
 		if err != nil {
 			return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
 		}
@@ -292,17 +369,38 @@ func newUpCmd() *cobra.Command {
 		if err != nil {
 			return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
 		}
+		if err = proj.ValidateConfig(cfg.Config, cfg.Decrypter); err != nil {
+			return result.FromError(fmt.Errorf("validating stack configuration: %w", err))
+		}
 
 		refreshOption, err := getRefreshOption(proj, refresh)
 		if err != nil {
 			return result.FromError(err)
 		}
 
+		providerVersionPins, err := parseProviderVersions(providerVersions)
+		if err != nil {
+			return result.FromError(err)
+		}
+		stepHooks, err := getStepHooks(proj, s)
+		if err != nil {
+			return result.FromError(err)
+		}
+
 		opts.Engine = engine.UpdateOptions{
-			LocalPolicyPacks: engine.MakeLocalPolicyPacks(policyPackPaths, policyPackConfigPaths),
+			LocalPolicyPacks: makeLocalPolicyPacks(proj, root, policyPackPaths, policyPackConfigPaths),
 			Parallel:         parallel,
 			Debug:            debug,
 			Refresh:          refreshOption,
+			Preflight:        preflight,
+			ProviderVersions: providerVersionPins,
+			StepHooks:        stepHooks,
+		}
+		if err = applyApprovalGates(s, cmdutil.Interactive(), &opts); err != nil {
+			return result.FromError(err)
+		}
+		if err = applyRolloutWaves(s, cmdutil.Interactive(), &opts); err != nil {
+			return result.FromError(err)
 		}
 
 		// TODO for the URL case:
@@ -310,7 +408,14 @@ func newUpCmd() *cobra.Command {
 		// - attempt `destroy` on any update errors.
 		// - show template.Quickstart?
 
-		changes, res := s.Update(commandContext(), backend.UpdateOperation{
+		var prev *deploy.Snapshot
+		if rollbackOnFailure {
+			if prev, err = s.Snapshot(commandContext()); err != nil {
+				return result.FromError(fmt.Errorf("getting snapshot to roll back to: %w", err))
+			}
+		}
+
+		op := backend.UpdateOperation{
 			Proj:               proj,
 			Root:               root,
 			M:                  m,
@@ -318,10 +423,16 @@ func newUpCmd() *cobra.Command {
 			StackConfiguration: cfg,
 			SecretsManager:     sm,
 			Scopes:             cancellationScopes,
-		})
+		}
+		notifier := newOperationNotifier(notifyURL, s, proj, apitype.UpdateUpdate, cfg)
+		notifier.started()
+		changes, res := s.Update(commandContext(), op)
+		notifier.finished(changes, res)
 		switch {
 		case res != nil && res.Error() == context.Canceled:
 			return result.FromError(errors.New("update cancelled"))
+		case res != nil && rollbackOnFailure:
+			return rollbackFailedUpdate(s, op, prev, res)
 		case res != nil:
 			return PrintEngineResult(res)
 		case expectNop && changes != nil && changes.HasChanges():
@@ -355,6 +466,10 @@ func newUpCmd() *cobra.Command {
 			if !interactive && !yes {
 				return result.FromError(errors.New("--yes must be passed in to proceed when running in non-interactive mode"))
 			}
+			if preflight && skipPreview {
+				return result.FromError(errors.New("--preflight checks the operations of the preview, so it cannot be " +
+					"used with --skip-preview"))
+			}
 
 			opts, err := updateFlagsToOptions(interactive, skipPreview, yes)
 			if err != nil {
@@ -382,7 +497,24 @@ func newUpCmd() *cobra.Command {
 				EventLogPath:         eventLogPath,
 				Debug:                debug,
 				JSONDisplay:          jsonDisplay,
+				CIAnnotations:        ciAnnotations,
+				EventSinks:           eventSinks,
+				HeartbeatInterval:    heartbeatInterval,
+				NoTruncate:           noTruncate,
+				DisplayWidth:         displayWidth,
+				ProfileResources:     profileResources,
+				SlowStepThreshold:    slowStepThreshold,
+			}
+			if err := applyReportFlags(reports, &opts.Display); err != nil {
+				return result.FromError(err)
+			}
+			if err := applyCIFormatFlag(ciFormat, &opts.Display); err != nil {
+				return result.FromError(err)
+			}
+			if err := applyVerbosityFlags(quiet, verbosity, &opts.Display); err != nil {
+				return result.FromError(err)
 			}
+			opts.WaitForLock = waitForLock
 
 			// we only suppress permalinks if the user passes true. the default is an empty string
 			// which we pass as 'false'
@@ -404,9 +536,18 @@ func newUpCmd() *cobra.Command {
 			}
 
 			if len(args) > 0 {
+				if cascade {
+					return result.FromError(errors.New("--cascade cannot be used with a template"))
+				}
 				return upTemplateNameOrURL(args[0], opts)
 			}
 
+			if cascade {
+				return runCascade(stack, opts.Display, cascadeUpdate, func(stackName string) result.Result {
+					stack = stackName
+					return upWorkingDirectory(opts)
+				})
+			}
 			return upWorkingDirectory(opts)
 		}),
 	}
@@ -429,6 +570,10 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&path, "config-path", false,
 		"Config keys contain a path to a property in a map or list to set")
+	cmd.PersistentFlags().BoolVar(
+		&saveConfigArray, "save-config", true,
+		"Save the values passed with --config to the stack's configuration. Pass --save-config=false to "+
+			"apply them to this update only")
 	cmd.PersistentFlags().StringVar(
 		&secretsProvider, "secrets-provider", "default", "The type of the provider that should be used to encrypt and "+
 			"decrypt secrets (possible choices: default, passphrase, awskms, azurekeyvault, gcpkms, hashivault). Only"+
@@ -440,12 +585,18 @@ func newUpCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
-		"Optional message to associate with the update operation")
+		"Optional message to associate with the update operation. May contain placeholders such as "+
+			"{{.GitCommit}}, {{.GitBranch}} and {{.User}} that are expanded from the environment")
 
 	cmd.PersistentFlags().StringArrayVarP(
 		&targets, "target", "t", []string{},
 		"Specify a single resource URN to update. Other resources will not be updated."+
-			" Multiple resources can be specified using --target urn1 --target urn2")
+			" Multiple resources can be specified using --target urn1 --target urn2."+
+			" Use --target output:<name> to target the resources that contribute to a stack output")
+	cmd.PersistentFlags().StringVar(
+		&targetFile, "target-file", "",
+		"Read resource URNs to update from a file, one per line. Lines starting with # are ignored, and targets "+
+			"containing * or ? are globs that match the URNs of the stack's existing resources")
 	cmd.PersistentFlags().StringArrayVar(
 		&replaces, "replace", []string{},
 		"Specify resources to replace. Multiple resources can be specified using --replace urn1 --replace urn2")
@@ -456,6 +607,19 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&targetDependents, "target-dependents", false,
 		"Allows updating of dependent targets discovered but not specified in --target list")
+	cmd.PersistentFlags().BoolVar(
+		&excludeProtected, "exclude-protected", false,
+		"Do not update, replace, or delete protected resources or the resources they depend on")
+	cmd.PersistentFlags().BoolVar(
+		&cascade, "cascade", false,
+		"Also update every stack of the project that depends on this one, in dependency order")
+	cmd.PersistentFlags().BoolVar(
+		&cleanupDefaultProviders, "cleanup-default-providers", false,
+		"Delete default providers that are no longer referenced by any resource")
+	cmd.PersistentFlags().BoolVar(
+		&rollbackOnFailure, "rollback-on-failure", false,
+		"If the update fails, roll the stack back to its state before the update: delete the resources that it "+
+			"created, restore the inputs of those that it changed and recreate those that it deleted")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -497,6 +661,55 @@ func newUpCmd() *cobra.Command {
 	cmd.PersistentFlags().BoolVar(
 		&suppressOutputs, "suppress-outputs", false,
 		"Suppress display of stack outputs (in case they contain sensitive values)")
+	cmd.PersistentFlags().BoolVar(
+		&ciAnnotations, "ci-annotations", defaultCIAnnotations(),
+		"Group output by resource and raise annotations for diagnostics when running under a supported CI system; "+
+			"defaults to the value of "+ciAnnotationsEnvVar)
+	cmd.PersistentFlags().StringVar(
+		&ciFormat, "ci-format", "",
+		"Write the log commands of the given CI system instead of the detected one: azdo, github or teamcity")
+	cmd.PersistentFlags().BoolVarP(
+		&quiet, "quiet", "q", false,
+		"Only show diagnostics and the summary, not the progress of each resource")
+	cmd.PersistentFlags().IntVar(
+		&verbosity, "verbosity", 0,
+		"Show more detail about each resource, from 0 to 3: 1 adds replacement steps and reads, 2 adds unchanged "+
+			"resources and 3 adds configuration")
+	cmd.PersistentFlags().BoolVar(
+		&noTruncate, "no-truncate", false,
+		"Wrap lines of the progress display that are too wide for the terminal instead of truncating them")
+	cmd.PersistentFlags().IntVar(
+		&displayWidth, "display-width", 0,
+		"Fit the lines of the progress display to this many columns instead of the width of the terminal")
+	cmd.PersistentFlags().StringVar(
+		&notifyURL, "notify-url", "",
+		"POST a JSON notification to this URL when the update starts, succeeds or fails; defaults to the "+
+			"pulumi:notifyUrl stack configuration value")
+	cmd.PersistentFlags().StringArrayVar(
+		&eventSinks, "event-sink", nil,
+		"Send the engine events of the operation to the named event sink plugin (pulumi-eventsink-<name>); "+
+			"may be specified more than once")
+	cmd.PersistentFlags().DurationVar(
+		&heartbeatInterval, "heartbeat-interval", defaultHeartbeatInterval,
+		"When the display is not interactive, list the resources still in progress this often (0 to disable)")
+	cmd.PersistentFlags().DurationVar(
+		&waitForLock, "wait-for-lock", 0,
+		"If another update of the stack is in progress, wait up to this long for it to finish (e.g. '10m') "+
+			"instead of failing immediately")
+	cmd.PersistentFlags().BoolVar(
+		&preflight, "preflight", false,
+		"During the preview, ask each provider to check that its credentials permit the update's operations. "+
+			"Experimental: providers must implement the pulumi:providers:checkPermissions function")
+	cmd.PersistentFlags().StringArrayVar(
+		&providerVersions, "provider-version", nil,
+		"Load this version of a package's provider plugin, as <package>=<version> (e.g. 'aws=4.1.0'), whatever "+
+			"version the program or the stack's state asks for; may be specified more than once")
+	cmd.PersistentFlags().BoolVar(
+		&profileResources, "profile-resources", false,
+		"Show how long the slowest resource operations of the update took once it completes")
+	cmd.PersistentFlags().DurationVar(
+		&slowStepThreshold, "slow-operation-threshold", defaultSlowStepThreshold,
+		"Flag resource operations that take longer than this in the interactive display (0 to disable)")
 	cmd.PersistentFlags().StringVar(
 		&suppressPermalink, "suppress-permalink", "",
 		"Suppress display of the state permalink")
@@ -505,11 +718,14 @@ func newUpCmd() *cobra.Command {
 		&yes, "yes", "y", false,
 		"Automatically approve and perform the update after previewing it")
 
-	if hasDebugCommands() {
-		cmd.PersistentFlags().StringVar(
-			&eventLogPath, "event-log", "",
-			"Log events to a file at this path")
-	}
+	cmd.PersistentFlags().StringArrayVar(
+		&reports, "report", nil,
+		"Write a report of each resource operation to a file, as <format>=<path>. The only supported format "+
+			"is junit, which writes JUnit XML")
+
+	cmd.PersistentFlags().StringVar(
+		&eventLogPath, "event-log", "",
+		"Log each engine event to a file at this path as a line of JSON, alongside the usual display")
 
 	// internal flags
 	cmd.PersistentFlags().StringVar(&execKind, "exec-kind", "", "")
@@ -539,6 +755,50 @@ func validatePolicyPackConfig(policyPackPaths []string, policyPackConfigPaths []
 	return nil
 }
 
+// makeLocalPolicyPacks returns the policy packs to run for an operation: those passed with `--policy-pack`, followed by
+// those the project requires. The paths of the project's policy packs are relative to its root directory.
+func makeLocalPolicyPacks(proj *workspace.Project, root string,
+	policyPackPaths []string, policyPackConfigPaths []string) []engine.LocalPolicyPack {
+
+	packs := engine.MakeLocalPolicyPacks(policyPackPaths, policyPackConfigPaths)
+	if proj == nil {
+		return packs
+	}
+	rooted := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(root, path)
+	}
+	for _, pack := range proj.PolicyPacks {
+		packs = append(packs, engine.LocalPolicyPack{
+			Path:   rooted(pack.Path),
+			Config: rooted(pack.Config),
+		})
+	}
+	return packs
+}
+
+// getStepHooks returns the hooks to run before and after the steps of an operation on a stack: the project's hooks,
+// followed by the stack's.
+func getStepHooks(proj *workspace.Project, s backend.Stack) ([]workspace.StepHook, error) {
+	ps, err := loadProjectStack(s)
+	if err != nil {
+		return nil, fmt.Errorf("loading stack settings: %w", err)
+	}
+	for i, hook := range ps.Hooks {
+		if err := hook.Validate(); err != nil {
+			return nil, fmt.Errorf("stack hook %d: %w", i, err)
+		}
+	}
+
+	var hooks []workspace.StepHook
+	if proj != nil {
+		hooks = append(hooks, proj.Hooks...)
+	}
+	return append(hooks, ps.Hooks...), nil
+}
+
 // handleConfig handles prompting for config values (as needed) and saving config.
 func handleConfig(
 	s backend.Stack,
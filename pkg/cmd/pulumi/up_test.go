@@ -16,9 +16,13 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
 
 func TestValidatePolicyPackConfig(t *testing.T) {
@@ -85,3 +89,25 @@ func TestValidatePolicyPackConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeLocalPolicyPacks(t *testing.T) {
+	root := filepath.Join("home", "project")
+	abs, err := filepath.Abs(filepath.Join("policies", "shared"))
+	assert.NoError(t, err)
+
+	proj := &workspace.Project{
+		PolicyPacks: []workspace.ProjectPolicyPack{
+			{Path: "policy", Config: "policy-config.json"},
+			{Path: abs},
+		},
+	}
+	packs := makeLocalPolicyPacks(proj, root, []string{"flag-pack"}, []string{"flag-config.json"})
+	assert.Equal(t, []engine.LocalPolicyPack{
+		{Path: "flag-pack", Config: "flag-config.json"},
+		{Path: filepath.Join(root, "policy"), Config: filepath.Join(root, "policy-config.json")},
+		{Path: abs},
+	}, packs)
+
+	assert.Equal(t, []engine.LocalPolicyPack{{Path: "flag-pack"}},
+		makeLocalPolicyPacks(nil, root, []string{"flag-pack"}, nil))
+}
@@ -24,11 +24,15 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"os/user"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
+	"time"
 
+	"github.com/blang/semver"
 	multierror "github.com/hashicorp/go-multierror"
 	opentracing "github.com/opentracing/opentracing-go"
 
@@ -43,11 +47,15 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/backend/state"
 	"github.com/pulumi/pulumi/pkg/v3/engine"
 	"github.com/pulumi/pulumi/pkg/v3/resource/stack"
+	"github.com/pulumi/pulumi/pkg/v3/secrets/age"
 	"github.com/pulumi/pulumi/pkg/v3/secrets/passphrase"
 	"github.com/pulumi/pulumi/pkg/v3/util/cancel"
 	"github.com/pulumi/pulumi/pkg/v3/util/tracing"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/constant"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/ciutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/cmdutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -122,6 +130,35 @@ func currentBackend(opts display.Options) (backend.Backend, error) {
 	return httpstate.Login(commandContext(), cmdutil.Diag(), url, opts)
 }
 
+// openReferencedBackend opens the backend at the given URL to resolve a stack reference to another backend. The
+// backend is opened with the credentials stored for its URL by `pulumi login`, without making it current.
+func openReferencedBackend(ctx context.Context, url string) (backend.Backend, error) {
+	if filestate.IsFileStateBackendURL(url) {
+		return filestate.New(cmdutil.Diag(), url)
+	}
+
+	account, err := workspace.GetAccount(httpstate.ValueOrDefaultURL(url))
+	if err != nil {
+		return nil, fmt.Errorf("getting stored credentials: %w", err)
+	}
+	if account.AccessToken == "" {
+		return nil, fmt.Errorf("no credentials are stored for %s; run `pulumi login %s` first", url, url)
+	}
+	return httpstate.New(cmdutil.Diag(), url)
+}
+
+// loginToBackend logs in to the backend at the given URL, making it the current backend.
+func loginToBackend(url string, opts display.Options) (backend.Backend, error) {
+	if err := validateCloudBackendType(url); err != nil {
+		return nil, err
+	}
+
+	if filestate.IsFileStateBackendURL(url) {
+		return filestate.Login(cmdutil.Diag(), url)
+	}
+	return httpstate.Login(commandContext(), cmdutil.Diag(), url, opts)
+}
+
 // This is used to control the contents of the tracing header.
 var tracingHeader = os.Getenv("PULUMI_TRACING_HEADER")
 
@@ -176,6 +213,10 @@ func createSecretsManager(b backend.Backend, stackRef backend.StackReference, se
 			rotatePassphraseSecretsProvider); pharseErr != nil {
 			return pharseErr
 		}
+	} else if strings.HasPrefix(secretsProvider, age.URLPrefix) {
+		if _, ageErr := newAgeSecretsManager(stackRef.Name(), stackConfigFile, secretsProvider); ageErr != nil {
+			return ageErr
+		}
 	} else if !isDefaultSecretsProvider {
 		// All other non-default secrets providers are handled by the cloud secrets provider which
 		// uses a URL schema to identify the provider
@@ -207,6 +248,9 @@ func createStack(
 	b backend.Backend, stackRef backend.StackReference, opts interface{}, setCurrent bool,
 	secretsProvider string) (backend.Stack, error) {
 
+	if err := backend.CheckWritable("create a stack"); err != nil {
+		return nil, err
+	}
 	stack, err := b.CreateStack(commandContext(), stackRef, opts)
 	if err != nil {
 		// If it's a well-known error, don't wrap it.
@@ -247,6 +291,7 @@ func requireStack(
 		return nil, err
 	}
 
+	stackName = state.ResolveStackAlias(stackName)
 	stackRef, err := b.ParseStackReference(stackName)
 	if err != nil {
 		return nil, err
@@ -443,6 +488,48 @@ func parseAndSaveConfigArray(s backend.Stack, configArray []string, path bool) e
 	return nil
 }
 
+// applyEphemeralConfig overlays the config values passed on the command line onto the given stack configuration for
+// the current operation only; the stack's configuration file is left untouched. The overridden keys are recorded in
+// the update metadata so that the update's history shows that ephemeral config was used.
+func applyEphemeralConfig(
+	cfg *backend.StackConfiguration, m *backend.UpdateMetadata, configArray []string, path bool) error {
+
+	if len(configArray) == 0 {
+		return nil
+	}
+
+	// Work on a copy so that the stack's configuration is not mutated.
+	overridden := make(config.Map, len(cfg.Config))
+	for k, v := range cfg.Config {
+		overridden[k] = v
+	}
+
+	keys := make([]string, 0, len(configArray))
+	for _, c := range configArray {
+		kvp := strings.SplitN(c, "=", 2)
+
+		key, err := parseConfigKey(kvp[0])
+		if err != nil {
+			return err
+		}
+
+		value := config.NewValue("")
+		if len(kvp) == 2 {
+			value = config.NewValue(kvp[1])
+		}
+
+		if err = overridden.Set(key, value, path); err != nil {
+			return err
+		}
+		keys = append(keys, kvp[0])
+	}
+	sort.Strings(keys)
+
+	cfg.Config = overridden
+	m.Environment[backend.EphemeralConfig] = strings.Join(keys, ",")
+	return nil
+}
+
 // readProjectForUpdate attempts to detect and read a Pulumi project for the current workspace. If
 // the project is successfully detected and read, it is returned along with the path to its
 // containing directory, which will be used as the root of the project's Pulumi program. If a
@@ -551,7 +638,8 @@ func isGitWorkTreeDirty(repoRoot string) (bool, error) {
 
 // getUpdateMetadata returns an UpdateMetadata object, with optional data about the environment
 // performing the update.
-func getUpdateMetadata(msg, root, execKind, execAgent string) (*backend.UpdateMetadata, error) {
+func getUpdateMetadata(
+	msg string, proj *workspace.Project, root, execKind, execAgent string) (*backend.UpdateMetadata, error) {
 	m := &backend.UpdateMetadata{
 		Message:     msg,
 		Environment: make(map[string]string),
@@ -565,9 +653,96 @@ func getUpdateMetadata(msg, root, execKind, execAgent string) (*backend.UpdateMe
 
 	addExecutionMetadataToEnvironment(m.Environment, execKind, execAgent)
 
+	addToolchainMetadataToEnvironment(m.Environment, proj)
+
+	// Only expand the message the user gave us, not one taken from the commit. A message that happens to contain
+	// "{{" without being meant as a template is used as it is.
+	if msg != "" {
+		expanded, err := expandUpdateMessage(msg, m.Environment)
+		if err != nil {
+			cmdutil.Diag().Warningf(diag.Message("", "using the update message as it is: %v"), err)
+		} else {
+			m.Message = expanded
+		}
+	}
+
 	return m, nil
 }
 
+// updateMessageData is the data available to the template placeholders in an update's --message, e.g.
+// "{{.GitCommit}}". Each value is blank if it could not be determined.
+type updateMessageData struct {
+	// GitCommit is the commit hash of HEAD.
+	GitCommit string
+	// GitShortCommit is the first seven characters of GitCommit.
+	GitShortCommit string
+	// GitBranch is the name of the branch at HEAD, e.g. "main".
+	GitBranch string
+	// GitAuthor is the name of the author of the commit at HEAD.
+	GitAuthor string
+	// User is the user that triggered the CI build running the update, or otherwise the author of the commit at
+	// HEAD, or otherwise the current OS user.
+	User string
+	// CISystem is the name of the CI system running the update.
+	CISystem string
+	// CIBuildID is the ID of the CI build running the update.
+	CIBuildID string
+	// CIBuildURL is the URL of the CI build running the update.
+	CIBuildURL string
+	// PRNumber is the number of the pull request that the CI build is running for.
+	PRNumber string
+	// Environment is the full set of metadata gathered about the environment running the update, keyed by the
+	// names used in UpdateInfo.Environment, e.g. {{index .Environment "git.dirty"}}.
+	Environment map[string]string
+}
+
+// expandUpdateMessage expands the template placeholders in an update message from the environment metadata gathered
+// by getUpdateMetadata. OS environment variables are available through the env function, e.g. {{env "BUILD_TAG"}}.
+// Messages without placeholders are returned unchanged.
+func expandUpdateMessage(msg string, env map[string]string) (string, error) {
+	if !strings.Contains(msg, "{{") {
+		return msg, nil
+	}
+
+	tmpl, err := template.New("message").
+		Funcs(template.FuncMap{"env": os.Getenv}).
+		Option("missingkey=zero").
+		Parse(msg)
+	if err != nil {
+		return "", fmt.Errorf("parsing update message template: %w", err)
+	}
+
+	data := updateMessageData{
+		GitCommit:   env[backend.GitHead],
+		GitBranch:   strings.TrimPrefix(env[backend.GitHeadName], "refs/heads/"),
+		GitAuthor:   env[backend.GitAuthor],
+		User:        env[backend.CIActor],
+		CISystem:    env[backend.CISystem],
+		CIBuildID:   env[backend.CIBuildID],
+		CIBuildURL:  env[backend.CIBuildURL],
+		PRNumber:    env[backend.CIPRNumber],
+		Environment: env,
+	}
+	data.GitShortCommit = data.GitCommit
+	if len(data.GitShortCommit) > 7 {
+		data.GitShortCommit = data.GitShortCommit[:7]
+	}
+	if data.User == "" {
+		data.User = data.GitAuthor
+	}
+	if data.User == "" {
+		if u, err := user.Current(); err == nil {
+			data.User = u.Username
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("expanding update message template: %w", err)
+	}
+	return buf.String(), nil
+}
+
 // addGitMetadata populate's the environment metadata bag with Git-related values.
 func addGitMetadata(repoRoot string, m *backend.UpdateMetadata) error {
 	var allErrors *multierror.Error
@@ -713,6 +888,7 @@ func addCIMetadataToEnvironment(env map[string]string) {
 	addIfSet(backend.CIBuildURL, vars.BuildURL)
 	addIfSet(backend.CIPRHeadSHA, vars.SHA)
 	addIfSet(backend.CIPRNumber, vars.PRNumber)
+	addIfSet(backend.CIActor, vars.Actor)
 }
 
 // addExecutionMetadataToEnvironment populates the environment metadata bag with execution-related values.
@@ -725,6 +901,8 @@ func addExecutionMetadataToEnvironment(env map[string]string, execKind, execAgen
 		break
 	case constant.ExecKindCLI:
 		break
+	case constant.ExecKindServe:
+		break
 	default:
 		execKind = constant.ExecKindCLI
 	}
@@ -831,6 +1009,92 @@ func updateFlagsToOptions(interactive, skipPreview, yes bool) (backend.UpdateOpt
 	}, nil
 }
 
+// defaultSlowStepThreshold is the default time after which the progress display flags a resource operation as slow.
+const defaultSlowStepThreshold = 5 * time.Minute
+
+// defaultHeartbeatInterval is the default interval at which a non-interactive display lists in-flight resources.
+const defaultHeartbeatInterval = time.Minute
+
+// applyReportFlags configures the reports requested by `--report <format>=<path>` flags.
+func applyReportFlags(reports []string, opts *display.Options) error {
+	for _, r := range reports {
+		i := strings.Index(r, "=")
+		if i <= 0 || i == len(r)-1 {
+			return fmt.Errorf("invalid report '%v'; expected <format>=<path>", r)
+		}
+		switch format, path := r[:i], r[i+1:]; format {
+		case "junit":
+			opts.JUnitReportPath = path
+		default:
+			return fmt.Errorf("unsupported report format '%v'; the only supported format is junit", format)
+		}
+	}
+	return nil
+}
+
+// ciAnnotationsEnvVar names the environment variable that turns on `--ci-annotations` by default. Annotations change
+// the output that CI systems show for a job, so they are opt-in.
+const ciAnnotationsEnvVar = "PULUMI_CI_ANNOTATIONS"
+
+// defaultCIAnnotations returns the default value of `--ci-annotations`.
+func defaultCIAnnotations() bool {
+	return cmdutil.IsTruthy(os.Getenv(ciAnnotationsEnvVar))
+}
+
+// applyCIFormatFlag configures the CI system whose log commands are written by `--ci-format <format>`, if set.
+func applyCIFormatFlag(format string, opts *display.Options) error {
+	switch format {
+	case "":
+	case "azdo":
+		opts.CIFormat = ciutil.AzurePipelines
+	case "github":
+		opts.CIFormat = ciutil.GitHubActions
+	case "teamcity":
+		opts.CIFormat = ciutil.TeamCity
+	default:
+		return fmt.Errorf("unsupported CI format '%v'; supported formats are azdo, github and teamcity", format)
+	}
+	return nil
+}
+
+// applyVerbosityFlags configures how much detail the display shows from `--quiet` and `--verbosity <level>`.
+func applyVerbosityFlags(quiet bool, verbosity int, opts *display.Options) error {
+	if quiet && verbosity > 0 {
+		return errors.New("only one of --quiet and --verbosity may be specified")
+	}
+	if verbosity < 0 || verbosity > display.MaxVerbosity {
+		return fmt.Errorf("--verbosity must be between 0 and %d", display.MaxVerbosity)
+	}
+	opts.Quiet = quiet
+	opts.Verbosity = verbosity
+	return nil
+}
+
+// parseProviderVersions parses the provider plugin versions pinned by `--provider-version <package>=<version>` flags.
+func parseProviderVersions(specs []string) (map[tokens.Package]*semver.Version, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	versions := make(map[tokens.Package]*semver.Version, len(specs))
+	for _, spec := range specs {
+		i := strings.Index(spec, "=")
+		if i <= 0 || i == len(spec)-1 {
+			return nil, fmt.Errorf("invalid provider version '%v'; expected <package>=<version>", spec)
+		}
+		pkg, v := tokens.Package(spec[:i]), spec[i+1:]
+		if _, has := versions[pkg]; has {
+			return nil, fmt.Errorf("provider version for package '%v' specified more than once", pkg)
+		}
+		version, err := semver.ParseTolerant(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version '%v' for package '%v': %w", v, pkg, err)
+		}
+		versions[pkg] = &version
+	}
+	return versions, nil
+}
+
 func checkDeploymentVersionError(err error, stackName string) error {
 	switch err {
 	case stack.ErrDeploymentSchemaVersionTooOld:
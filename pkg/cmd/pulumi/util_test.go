@@ -20,7 +20,10 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/pulumi/pulumi/pkg/v3/backend"
+	"github.com/pulumi/pulumi/pkg/v3/backend/display"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	pul_testing "github.com/pulumi/pulumi/sdk/v3/go/common/testing"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/ciutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/gitutil"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
@@ -329,3 +332,119 @@ func TestGetRefreshOption(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyEphemeralConfig(t *testing.T) {
+	region := config.MustMakeKey("aws", "region")
+	replicas := config.MustMakeKey("app", "replicas")
+
+	stackConfig := config.Map{region: config.NewValue("us-east-1")}
+	cfg := backend.StackConfiguration{Config: stackConfig}
+	md := &backend.UpdateMetadata{Environment: make(map[string]string)}
+
+	err := applyEphemeralConfig(&cfg, md, []string{"aws:region=us-west-2", "app:replicas=3"}, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, config.NewValue("us-west-2"), cfg.Config[region])
+	assert.Equal(t, config.NewValue("3"), cfg.Config[replicas])
+	assertEnvValue(t, md, backend.EphemeralConfig, "app:replicas,aws:region")
+
+	// The original stack configuration must be left untouched.
+	assert.Equal(t, config.Map{region: config.NewValue("us-east-1")}, stackConfig)
+}
+
+func TestApplyReportFlags(t *testing.T) {
+	var opts display.Options
+	assert.NoError(t, applyReportFlags([]string{"junit=out/report.xml"}, &opts))
+	assert.Equal(t, "out/report.xml", opts.JUnitReportPath)
+
+	assert.Error(t, applyReportFlags([]string{"junit"}, &opts))
+	assert.Error(t, applyReportFlags([]string{"junit="}, &opts))
+	assert.Error(t, applyReportFlags([]string{"html=report.html"}, &opts))
+}
+
+func TestApplyCIFormatFlag(t *testing.T) {
+	var opts display.Options
+	assert.NoError(t, applyCIFormatFlag("", &opts))
+	assert.Equal(t, ciutil.SystemName(""), opts.CIFormat)
+
+	assert.NoError(t, applyCIFormatFlag("azdo", &opts))
+	assert.Equal(t, ciutil.AzurePipelines, opts.CIFormat)
+	assert.NoError(t, applyCIFormatFlag("teamcity", &opts))
+	assert.Equal(t, ciutil.TeamCity, opts.CIFormat)
+
+	assert.Error(t, applyCIFormatFlag("jenkins", &opts))
+}
+
+func TestApplyVerbosityFlags(t *testing.T) {
+	var opts display.Options
+	assert.NoError(t, applyVerbosityFlags(true, 0, &opts))
+	assert.True(t, opts.Quiet)
+
+	assert.NoError(t, applyVerbosityFlags(false, 2, &opts))
+	assert.False(t, opts.Quiet)
+	assert.Equal(t, 2, opts.Verbosity)
+
+	assert.Error(t, applyVerbosityFlags(true, 1, &opts))
+	assert.Error(t, applyVerbosityFlags(false, display.MaxVerbosity+1, &opts))
+	assert.Error(t, applyVerbosityFlags(false, -1, &opts))
+}
+
+func TestParseProviderVersions(t *testing.T) {
+	versions, err := parseProviderVersions(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, versions)
+
+	versions, err = parseProviderVersions([]string{"aws=4.1.0", "kubernetes=v3.7"})
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "4.1.0", versions["aws"].String())
+	assert.Equal(t, "3.7.0", versions["kubernetes"].String())
+
+	for _, spec := range []string{"aws", "aws=", "=4.1.0", "aws=latest"} {
+		_, err = parseProviderVersions([]string{spec})
+		assert.Error(t, err, spec)
+	}
+	_, err = parseProviderVersions([]string{"aws=4.1.0", "aws=4.2.0"})
+	assert.Error(t, err)
+}
+
+func TestExpandUpdateMessage(t *testing.T) {
+	env := map[string]string{
+		backend.GitHead:     "0123456789abcdef",
+		backend.GitHeadName: "refs/heads/feature/x",
+		backend.GitAuthor:   "Alice",
+		backend.GitDirty:    "true",
+		backend.CIActor:     "bob",
+		backend.CIPRNumber:  "42",
+	}
+	os.Setenv("PULUMI_TEST_MESSAGE_VAR", "release-1")
+	defer os.Unsetenv("PULUMI_TEST_MESSAGE_VAR")
+
+	tests := []struct {
+		msg      string
+		expected string
+	}{
+		{"plain message", "plain message"},
+		{"deploy {{.GitShortCommit}} from {{.GitBranch}}", "deploy 0123456 from feature/x"},
+		{"{{.GitCommit}} by {{.User}} ({{.GitAuthor}})", "0123456789abcdef by bob (Alice)"},
+		{"PR #{{.PRNumber}}{{if .CIBuildURL}} {{.CIBuildURL}}{{end}}", "PR #42"},
+		{`dirty={{index .Environment "git.dirty"}}`, "dirty=true"},
+		{`{{env "PULUMI_TEST_MESSAGE_VAR"}}`, "release-1"},
+	}
+	for _, tt := range tests {
+		actual, err := expandUpdateMessage(tt.msg, env)
+		assert.NoError(t, err, tt.msg)
+		assert.Equal(t, tt.expected, actual, tt.msg)
+	}
+
+	// Without a CI actor, the user is the commit's author.
+	delete(env, backend.CIActor)
+	actual, err := expandUpdateMessage("{{.User}}", env)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", actual)
+
+	_, err = expandUpdateMessage("{{.GitCommit", env)
+	assert.Error(t, err)
+	_, err = expandUpdateMessage("{{.NoSuchField}}", env)
+	assert.Error(t, err)
+}
@@ -0,0 +1,46 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// verbositySteps are the verbose logging levels that a running command steps through when asked for more detail.
+var verbositySteps = []int{3, 5, 7, 9}
+
+// nextVerbosity returns the verbose logging level that follows the current one, or the current level if it is already
+// the most verbose step.
+func nextVerbosity(current int) int {
+	for _, step := range verbositySteps {
+		if step > current {
+			return step
+		}
+	}
+	return current
+}
+
+// openLogTail opens the file at the given path for appending and directs a copy of the log to it.
+func openLogTail(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening log tail: %w", err)
+	}
+	logging.SetTail(f)
+	return f, nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextVerbosity(t *testing.T) {
+	assert.Equal(t, 3, nextVerbosity(0))
+	assert.Equal(t, 5, nextVerbosity(3))
+	assert.Equal(t, 5, nextVerbosity(4))
+	assert.Equal(t, 9, nextVerbosity(7))
+	assert.Equal(t, 9, nextVerbosity(9))
+	assert.Equal(t, 11, nextVerbosity(11))
+}
@@ -0,0 +1,45 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// watchVerbositySignals raises the verbose logging level each time the process receives SIGUSR1, and restores the level
+// it was started with when it receives SIGUSR2. This allows more detail to be captured from a running operation, e.g.
+// one that appears to be stuck, without restarting it.
+func watchVerbositySignals() {
+	initial := logging.GetVerbosity()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for sig := range sigs {
+			level := initial
+			if sig == syscall.SIGUSR1 {
+				level = nextVerbosity(logging.GetVerbosity())
+			}
+			logging.SetVerbosity(level)
+			logging.Infof("verbose logging level set to %d", level)
+		}
+	}()
+}
@@ -100,7 +100,7 @@ func newWatchCmd() *cobra.Command {
 				return result.FromError(err)
 			}
 
-			m, err := getUpdateMetadata(message, root, execKind, "" /* execAgent */)
+			m, err := getUpdateMetadata(message, proj, root, execKind, "" /* execAgent */)
 			if err != nil {
 				return result.FromError(fmt.Errorf("gathering environment metadata: %w", err))
 			}
@@ -114,9 +114,13 @@ func newWatchCmd() *cobra.Command {
 			if err != nil {
 				return result.FromError(fmt.Errorf("getting stack configuration: %w", err))
 			}
+			stepHooks, err := getStepHooks(proj, s)
+			if err != nil {
+				return result.FromError(err)
+			}
 
 			opts.Engine = engine.UpdateOptions{
-				LocalPolicyPacks:          engine.MakeLocalPolicyPacks(policyPackPaths, policyPackConfigPaths),
+				LocalPolicyPacks:          makeLocalPolicyPacks(proj, root, policyPackPaths, policyPackConfigPaths),
 				Parallel:                  parallel,
 				Debug:                     debug,
 				Refresh:                   refresh,
@@ -124,6 +128,10 @@ func newWatchCmd() *cobra.Command {
 				DisableProviderPreview:    disableProviderPreview(),
 				DisableResourceReferences: disableResourceReferences(),
 				DisableOutputValues:       disableOutputValues(),
+				StepHooks:                 stepHooks,
+			}
+			if err = applyApprovalGates(s, cmdutil.Interactive(), &opts); err != nil {
+				return result.FromError(err)
 			}
 
 			res := s.Watch(commandContext(), backend.UpdateOperation{
@@ -173,7 +181,8 @@ func newWatchCmd() *cobra.Command {
 
 	cmd.PersistentFlags().StringVarP(
 		&message, "message", "m", "",
-		"Optional message to associate with each update operation")
+		"Optional message to associate with each update operation. May contain placeholders such as "+
+			"{{.GitCommit}}, {{.GitBranch}} and {{.User}} that are expanded from the environment")
 
 	// Flags for engine.UpdateOptions.
 	cmd.PersistentFlags().StringSliceVar(
@@ -0,0 +1,665 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// armPackage is the Pulumi package whose resources correspond to Azure Resource Manager resource types.
+const armPackage = "azure-native"
+
+// armResourceGroupName is the name of the configuration value that holds the name of the resource group into which
+// an ARM template's resources are deployed.
+const armResourceGroupName = "resourceGroupName"
+
+// armTemplates lists the names of the templates that are converted when the source is a directory, in order of
+// preference.
+var armTemplates = []string{"main.bicep", "azuredeploy.json", "template.json"}
+
+// armParameterTypes maps ARM template parameter types to PCL configuration types. Objects and arrays are untyped.
+var armParameterTypes = map[string]string{
+	"string":       "string",
+	"securestring": "string",
+	"int":          "int",
+	"bool":         "bool",
+}
+
+type armResource struct {
+	armType string
+	name    string
+	pclName string
+	res     *schema.Resource
+}
+
+type armConverter struct {
+	path      string
+	resources map[string]*schema.Resource
+	names     *nameTable
+	diags     hcl.Diagnostics
+
+	declared []*armResource
+}
+
+// ARMToPCL converts an Azure Resource Manager template into a PCL program. If path names a Bicep file, it is first
+// compiled into an ARM template using the Bicep CLI. If path is a directory, the template is read from the first of
+// main.bicep, azuredeploy.json, or template.json that exists.
+//
+// Parameters become configuration, variables become local variables, resources become resources of the azure-native
+// package, and outputs become outputs. Because ARM templates are deployed into a resource group, the converted
+// program has an additional resourceGroupName configuration value. Template expressions that use the parameters,
+// variables, concat, format, resourceGroup, resourceId, and reference functions are converted; other functions,
+// conditions, and copy loops are reported as warnings.
+func ARMToPCL(path string, loader schema.Loader) ([]byte, hcl.Diagnostics, error) {
+	path, err := findARMTemplate(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	src, err := readARMTemplate(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, nil, fmt.Errorf("could not parse %v: %w", path, err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%v is not an ARM template", path)
+	}
+	template := doc.Content[0]
+
+	pkg, err := loader.LoadPackage(armPackage, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load the %v package: %w", armPackage, err)
+	}
+
+	c := &armConverter{path: path, resources: map[string]*schema.Resource{}, names: newNameTable()}
+	for _, r := range pkg.Resources {
+		c.resources[strings.ToLower(r.Token)] = r
+	}
+	c.declare(template)
+
+	blocks := []string{fmt.Sprintf("config %s \"string\" {\n    description = %s\n}\n", armResourceGroupName,
+		strconv.Quote("The name of the resource group into which resources are deployed"))}
+	for i := 0; i+1 < len(template.Content); i += 2 {
+		key, value := template.Content[i], template.Content[i+1]
+		if value.Kind != yaml.MappingNode && value.Kind != yaml.SequenceNode {
+			continue
+		}
+		switch key.Value {
+		case "parameters":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				blocks = append(blocks, c.convertParameter(value.Content[j].Value, value.Content[j+1]))
+			}
+		case "variables":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				name, _ := c.names.lookup("variables." + value.Content[j].Value)
+				blocks = append(blocks, name+" = "+c.value(value.Content[j+1])+"\n")
+			}
+		case "resources":
+			for j, r := range value.Content {
+				if text := c.convertResource(c.declared[j], r); text != "" {
+					blocks = append(blocks, text)
+				}
+			}
+		case "outputs":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				blocks = append(blocks, c.convertOutput(value.Content[j].Value, value.Content[j+1]))
+			}
+		case "functions":
+			c.diags = append(c.diags, c.unsupported(key, "TODO: user-defined functions are not supported"))
+		}
+	}
+	return []byte(strings.Join(blocks, "\n")), c.diags, nil
+}
+
+func findARMTemplate(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range armTemplates {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no ARM template found in %v; expected one of %v", path, strings.Join(armTemplates, ", "))
+}
+
+// readARMTemplate reads the ARM template at path, compiling it with the Bicep CLI if it is a Bicep file.
+func readARMTemplate(path string) ([]byte, error) {
+	if filepath.Ext(path) != ".bicep" {
+		return ioutil.ReadFile(path)
+	}
+
+	var cmd *exec.Cmd
+	if bicep, err := exec.LookPath("bicep"); err == nil {
+		cmd = exec.Command(bicep, "build", "--stdout", path)
+	} else if az, err := exec.LookPath("az"); err == nil {
+		cmd = exec.Command(az, "bicep", "build", "--stdout", "--file", path)
+	} else {
+		return nil, fmt.Errorf("converting %v requires the Bicep CLI; install it from https://aka.ms/bicep-install",
+			path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not compile %v: %w\n%s", path, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// declare assigns PCL names to the template's parameters, variables, resources, and outputs, and resolves the Pulumi
+// resource that corresponds to each template resource.
+func (c *armConverter) declare(template *yaml.Node) {
+	c.names.declare(armResourceGroupName, armResourceGroupName)
+	c.declareSection(template, "parameters")
+	c.declareSection(template, "variables")
+	defer c.declareSection(template, "outputs")
+
+	resources := mappingValue(template, "resources")
+	if resources == nil || resources.Kind != yaml.SequenceNode {
+		return
+	}
+	for i, r := range resources.Content {
+		typ := mappingValue(r, "type")
+		if typ == nil {
+			c.declared = append(c.declared, nil)
+			continue
+		}
+
+		segments := strings.Split(typ.Value, "/")
+		decl := &armResource{
+			armType: strings.ToLower(typ.Value),
+			pclName: c.names.declare(fmt.Sprintf("resources.%d", i), singular(segments[len(segments)-1])),
+			res:     c.armResource(typ.Value),
+		}
+		if name := mappingValue(r, "name"); name != nil {
+			// Resolve the name without reporting diagnostics; they are reported when the resource is converted.
+			n := len(c.diags)
+			decl.name = c.value(name)
+			c.diags = c.diags[:n]
+		}
+		c.declared = append(c.declared, decl)
+	}
+}
+
+func (c *armConverter) declareSection(template *yaml.Node, section string) {
+	if value := mappingValue(template, section); value != nil && value.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			id := value.Content[i].Value
+			c.names.declare(section+"."+id, id)
+		}
+	}
+}
+
+// armResource returns the azure-native resource that corresponds to an ARM resource type, e.g.
+// `azure-native:storage:StorageAccount` for `Microsoft.Storage/storageAccounts`.
+func (c *armConverter) armResource(armType string) *schema.Resource {
+	segments := strings.Split(armType, "/")
+	if len(segments) < 2 || !strings.HasPrefix(segments[0], "Microsoft.") {
+		return nil
+	}
+	module := strings.ToLower(strings.TrimPrefix(segments[0], "Microsoft."))
+	name := segments[len(segments)-1]
+	for _, candidate := range []string{singular(name), strings.TrimSuffix(name, "es"), name} {
+		if r, ok := c.resources[strings.ToLower(armPackage+":"+module+":"+candidate)]; ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// singular returns the singular form of a plural ARM resource type name, e.g. `storageAccount` for
+// `storageAccounts`.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"):
+		return strings.TrimSuffix(name, "es")
+	default:
+		return strings.TrimSuffix(name, "s")
+	}
+}
+
+func (c *armConverter) convertParameter(id string, value *yaml.Node) string {
+	name, _ := c.names.lookup("parameters." + id)
+
+	header := "config " + name
+	var items []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		switch k.Value {
+		case "type":
+			if typ, ok := armParameterTypes[strings.ToLower(v.Value)]; ok {
+				header += " " + strconv.Quote(typ)
+			}
+			if strings.EqualFold(v.Value, "securestring") || strings.EqualFold(v.Value, "secureobject") {
+				c.diags = append(c.diags, c.unsupported(v, "TODO: the parameter %v is secure; set its value with "+
+					"`pulumi config set --secret`", id))
+			}
+		case "defaultValue":
+			items = append(items, "default = "+c.value(v))
+		case "metadata":
+			if description := mappingValue(v, "description"); description != nil {
+				items = append(items, "description = "+quoteTemplate(description.Value))
+			}
+		default:
+			c.diags = append(c.diags, c.unsupported(k, "TODO: the parameter constraint %v is not enforced", k.Value))
+		}
+	}
+	return header + " " + bodyText(items) + "\n"
+}
+
+func (c *armConverter) convertResource(decl *armResource, node *yaml.Node) string {
+	typ := mappingValue(node, "type")
+	if decl == nil || typ == nil {
+		c.diags = append(c.diags, c.unsupported(node, "the resource has no type"))
+		return ""
+	}
+	if decl.res == nil {
+		c.diags = append(c.diags, c.unsupported(typ, "no Pulumi resource corresponds to the ARM resource type %v",
+			typ.Value))
+		return ""
+	}
+	if strings.Count(typ.Value, "/") > 1 {
+		c.diags = append(c.diags, c.unsupported(typ, "TODO: %v is a child resource; set the name of its parent "+
+			"resource by hand", typ.Value))
+	}
+
+	var items, options []string
+	if findProperty(decl.res.InputProperties, armResourceGroupName) != nil {
+		items = append(items, armResourceGroupName+" = "+armResourceGroupName)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		switch k.Value {
+		case "type", "apiVersion", "comments", "metadata":
+		case "name":
+			if prop := c.nameProperty(decl); prop != "" {
+				items = append(items, prop+" = "+c.value(v))
+			} else {
+				c.diags = append(c.diags, c.unsupported(k, "TODO: the name of the resource cannot be converted"))
+			}
+		case "properties":
+			// azure-native flattens the properties of a resource into its inputs.
+			items = append(items, c.objectItems(v)...)
+		case "dependsOn":
+			var deps []string
+			for _, n := range v.Content {
+				if dep := c.dependency(n); dep != "" {
+					deps = append(deps, dep)
+				} else {
+					c.diags = append(c.diags, c.unsupported(n, "TODO: the dependency %v cannot be converted", n.Value))
+				}
+			}
+			options = append(options, "dependsOn = ["+strings.Join(deps, ", ")+"]")
+		case "condition", "copy", "resources", "scope":
+			c.diags = append(c.diags, c.unsupported(k, "TODO: the resource property %v is not supported", k.Value))
+		default:
+			items = append(items, objectKey(k.Value)+" = "+c.value(v))
+		}
+	}
+	if len(options) != 0 {
+		items = append(items, "options "+bodyText(options))
+	}
+	return fmt.Sprintf("resource %s %s %s\n", decl.pclName, strconv.Quote(decl.res.Token), bodyText(items))
+}
+
+// nameProperty returns the name of the input property that holds a resource's name. This is the only input other
+// than the resource group name whose name ends in "Name".
+func (c *armConverter) nameProperty(decl *armResource) string {
+	var candidates []string
+	for _, p := range decl.res.InputProperties {
+		if strings.HasSuffix(p.Name, "Name") && p.Name != armResourceGroupName {
+			candidates = append(candidates, p.Name)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return ""
+}
+
+// dependency returns the PCL name of the resource that a dependsOn entry refers to. Entries are either resource IDs
+// or the names of resources.
+func (c *armConverter) dependency(node *yaml.Node) string {
+	text := c.value(node)
+	for _, decl := range c.declared {
+		if decl != nil && (text == decl.pclName+".id" || text == decl.name) {
+			return decl.pclName
+		}
+	}
+	return ""
+}
+
+func (c *armConverter) convertOutput(id string, value *yaml.Node) string {
+	name, _ := c.names.lookup("outputs." + id)
+
+	var items []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		switch k.Value {
+		case "value":
+			items = append(items, "value = "+c.value(v))
+		case "type":
+		default:
+			c.diags = append(c.diags, c.unsupported(k, "TODO: the output property %v is not supported", k.Value))
+		}
+	}
+	return fmt.Sprintf("output %s %s\n", name, bodyText(items))
+}
+
+// value converts a JSON value into a PCL expression. Strings enclosed in brackets are template expressions.
+func (c *armConverter) value(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var elements []string
+		for _, n := range node.Content {
+			elements = append(elements, c.value(n))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case yaml.MappingNode:
+		return bodyText(c.objectItems(node))
+	}
+
+	switch node.Tag {
+	case "!!int", "!!float", "!!bool":
+		return node.Value
+	case "!!null":
+		return "null"
+	}
+
+	s := node.Value
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		if strings.HasPrefix(s, "[[") {
+			// A leading `[[` escapes a literal string that begins with `[`.
+			return quoteTemplate(s[1:])
+		}
+		p := &armExpressionParser{c: c, node: node, text: s[1 : len(s)-1]}
+		return p.parse()
+	}
+	return quoteTemplate(s)
+}
+
+func (c *armConverter) objectItems(node *yaml.Node) []string {
+	var items []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		items = append(items, objectKey(node.Content[i].Value)+" = "+c.value(node.Content[i+1]))
+	}
+	return items
+}
+
+func (c *armConverter) unsupported(node *yaml.Node, format string, args ...interface{}) *hcl.Diagnostic {
+	return unsupported(nodeRange(c.path, node), format, args...)
+}
+
+// armValue is a converted template expression. If the expression is a resource ID of a resource declared by the
+// template, resource is that resource.
+type armValue struct {
+	text     string
+	literal  *string
+	resource *armResource
+}
+
+// armExpressionParser converts an ARM template expression, e.g. `concat(parameters('prefix'), 'store')`, into PCL.
+type armExpressionParser struct {
+	c    *armConverter
+	node *yaml.Node
+	text string
+	pos  int
+}
+
+func (p *armExpressionParser) parse() string {
+	v, ok := p.expression()
+	if !ok || p.skipSpace() != len(p.text) {
+		p.c.diags = append(p.c.diags, p.c.unsupported(p.node, "TODO: the expression [%v] cannot be converted",
+			p.text))
+		return notImplemented("[" + p.text + "]")
+	}
+	return v.text
+}
+
+func (p *armExpressionParser) skipSpace() int {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+	return p.pos
+}
+
+func (p *armExpressionParser) expression() (armValue, bool) {
+	v, ok := p.primary()
+	for ok {
+		switch {
+		case p.skipSpace() < len(p.text) && p.text[p.pos] == '.':
+			p.pos++
+			name := p.identifier()
+			if name == "" {
+				return armValue{}, false
+			}
+			v = armValue{text: v.text + "." + name}
+		case p.pos < len(p.text) && p.text[p.pos] == '[':
+			p.pos++
+			index, ok := p.expression()
+			if !ok || p.skipSpace() == len(p.text) || p.text[p.pos] != ']' {
+				return armValue{}, false
+			}
+			p.pos++
+			v = armValue{text: v.text + "[" + index.text + "]"}
+		default:
+			return v, true
+		}
+	}
+	return armValue{}, false
+}
+
+func (p *armExpressionParser) identifier() string {
+	start := p.skipSpace()
+	for p.pos < len(p.text) {
+		ch := p.text[p.pos]
+		if !(ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || p.pos > start && ch >= '0' && ch <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *armExpressionParser) primary() (armValue, bool) {
+	if p.skipSpace() == len(p.text) {
+		return armValue{}, false
+	}
+
+	switch ch := p.text[p.pos]; {
+	case ch == '\'':
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.text); p.pos++ {
+			if p.text[p.pos] == '\'' {
+				// Quotes are escaped by doubling them.
+				if p.pos+1 < len(p.text) && p.text[p.pos+1] == '\'' {
+					b.WriteByte('\'')
+					p.pos++
+					continue
+				}
+				p.pos++
+				s := b.String()
+				return armValue{text: quoteTemplate(s), literal: &s}, true
+			}
+			b.WriteByte(p.text[p.pos])
+		}
+		return armValue{}, false
+	case ch == '-' || ch >= '0' && ch <= '9':
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.text) && p.text[p.pos] >= '0' && p.text[p.pos] <= '9' {
+			p.pos++
+		}
+		return armValue{text: p.text[start:p.pos]}, true
+	}
+
+	name := p.identifier()
+	if name == "" || p.skipSpace() == len(p.text) || p.text[p.pos] != '(' {
+		switch name {
+		case "true", "false", "null":
+			return armValue{text: name}, true
+		}
+		return armValue{}, false
+	}
+	p.pos++
+
+	var args []armValue
+	for {
+		if p.skipSpace() == len(p.text) {
+			return armValue{}, false
+		}
+		if p.text[p.pos] == ')' {
+			p.pos++
+			break
+		}
+		if len(args) != 0 {
+			if p.text[p.pos] != ',' {
+				return armValue{}, false
+			}
+			p.pos++
+		}
+		arg, ok := p.expression()
+		if !ok {
+			return armValue{}, false
+		}
+		args = append(args, arg)
+	}
+	return p.call(name, args)
+}
+
+// call converts a call to a template function.
+func (p *armExpressionParser) call(name string, args []armValue) (armValue, bool) {
+	c := p.c
+	switch strings.ToLower(name) {
+	case "parameters", "variables":
+		if len(args) == 1 && args[0].literal != nil {
+			if pclName, ok := c.names.lookup(strings.ToLower(name) + "." + *args[0].literal); ok {
+				return armValue{text: pclName}, true
+			}
+		}
+	case "concat":
+		var elements []string
+		for _, a := range args {
+			elements = append(elements, a.text)
+		}
+		return armValue{text: "join(\"\", [" + strings.Join(elements, ", ") + "])"}, true
+	case "format":
+		if len(args) > 0 && args[0].literal != nil {
+			return armValue{text: p.format(*args[0].literal, args[1:])}, true
+		}
+	case "length":
+		if len(args) == 1 {
+			return armValue{text: "length(" + args[0].text + ")"}, true
+		}
+	case "split":
+		if len(args) == 2 {
+			return armValue{text: "split(" + args[1].text + ", " + args[0].text + ")"}, true
+		}
+	case "resourcegroup":
+		if len(args) == 0 {
+			return armValue{text: fmt.Sprintf("invoke(\"%s:resources:getResourceGroup\", {\n    %s = %s\n})",
+				armPackage, armResourceGroupName, armResourceGroupName)}, true
+		}
+	case "resourceid":
+		if decl := c.resourceByID(args); decl != nil {
+			return armValue{text: decl.pclName + ".id", resource: decl}, true
+		}
+	case "reference":
+		if len(args) > 0 {
+			decl := args[0].resource
+			if decl == nil {
+				decl = c.resourceByName(args[0].text)
+			}
+			if decl != nil {
+				// azure-native flattens the properties of a resource into its outputs.
+				return armValue{text: decl.pclName}, true
+			}
+		}
+	default:
+		c.diags = append(c.diags, c.unsupported(p.node, "TODO: the template function %v is not supported", name))
+		return armValue{text: notImplemented(name)}, true
+	}
+
+	c.diags = append(c.diags, c.unsupported(p.node, "TODO: this call to %v cannot be converted", name))
+	return armValue{text: notImplemented(name)}, true
+}
+
+// format converts a call to the format function into a string template.
+func (p *armExpressionParser) format(format string, args []armValue) string {
+	var b strings.Builder
+	b.WriteString(`"`)
+	for {
+		start := strings.Index(format, "{")
+		end := strings.Index(format, "}")
+		if start == -1 || end < start {
+			break
+		}
+		b.WriteString(escapeTemplate(format[:start]))
+		if i, err := strconv.Atoi(format[start+1 : end]); err == nil && i < len(args) {
+			b.WriteString("${" + args[i].text + "}")
+		} else {
+			b.WriteString(escapeTemplate(format[start : end+1]))
+		}
+		format = format[end+1:]
+	}
+	b.WriteString(escapeTemplate(format))
+	b.WriteString(`"`)
+	return b.String()
+}
+
+// resourceByID returns the declared resource identified by the arguments of a call to resourceId, i.e. a resource
+// type followed by a resource name.
+func (c *armConverter) resourceByID(args []armValue) *armResource {
+	if len(args) != 2 || args[0].literal == nil {
+		return nil
+	}
+	for _, decl := range c.declared {
+		if decl != nil && decl.armType == strings.ToLower(*args[0].literal) && decl.name == args[1].text {
+			return decl
+		}
+	}
+	return nil
+}
+
+func (c *armConverter) resourceByName(name string) *armResource {
+	for _, decl := range c.declared {
+		if decl != nil && decl.name == name {
+			return decl
+		}
+	}
+	return nil
+}
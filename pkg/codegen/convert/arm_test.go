@@ -0,0 +1,143 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func TestSingular(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"storageAccounts": "storageAccount",
+		"registries":      "registry",
+		"addresses":       "address",
+		"sites":           "site",
+	}
+	for name, expected := range cases {
+		assert.Equal(t, expected, singular(name))
+	}
+}
+
+func TestARMToPCL(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "parameters": {
+    "storageName": {
+      "type": "string",
+      "defaultValue": "store",
+      "metadata": { "description": "The account name" }
+    }
+  },
+  "variables": {
+    "fullName": "[concat(parameters('storageName'), 'prod')]",
+    "suffix": "[uniqueString(resourceGroup().id)]"
+  },
+  "resources": [
+    {
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2021-02-01",
+      "name": "[variables('fullName')]",
+      "location": "[resourceGroup().location]",
+      "sku": { "name": "Standard_LRS" },
+      "kind": "StorageV2",
+      "properties": { "supportsHttpsTrafficOnly": true }
+    },
+    {
+      "type": "Microsoft.Network/virtualNetworks",
+      "apiVersion": "2021-02-01",
+      "name": "[format('{0}-vnet', parameters('storageName'))]",
+      "dependsOn": ["[resourceId('Microsoft.Storage/storageAccounts', variables('fullName'))]"],
+      "tags": { "cost-center": "42" }
+    }
+  ],
+  "outputs": {
+    "endpoint": {
+      "type": "string",
+      "value": "[reference(variables('fullName')).primaryEndpoints.blob]"
+    }
+  }
+}
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "azuredeploy.json"), []byte(source), 0600))
+
+	loader := testLoader{
+		"azure-native": {
+			Resources: []*schema.Resource{
+				{
+					Token: "azure-native:storage:StorageAccount",
+					InputProperties: []*schema.Property{
+						{Name: "accountName"}, {Name: "location"}, {Name: "resourceGroupName"},
+					},
+				},
+				{
+					Token: "azure-native:network:VirtualNetwork",
+					InputProperties: []*schema.Property{
+						{Name: "resourceGroupName"}, {Name: "tags"}, {Name: "virtualNetworkName"},
+					},
+				},
+			},
+		},
+	}
+	program, diags, err := ARMToPCL(dir, loader)
+	assert.NoError(t, err)
+
+	text := string(program)
+	assert.Contains(t, text, "config resourceGroupName \"string\" {\n")
+	assert.Contains(t, text, "config storageName \"string\" {\n"+
+		"    default = \"store\"\n"+
+		"    description = \"The account name\"\n"+
+		"}\n")
+	assert.Contains(t, text, "fullName = join(\"\", [storageName, \"prod\"])\n")
+	assert.Contains(t, text, "suffix = notImplemented(\"uniqueString\")\n")
+	assert.Contains(t, text, "resource storageAccount \"azure-native:storage:StorageAccount\" {\n"+
+		"    resourceGroupName = resourceGroupName\n"+
+		"    accountName = fullName\n"+
+		"    location = invoke(\"azure-native:resources:getResourceGroup\", {\n"+
+		"        resourceGroupName = resourceGroupName\n"+
+		"    }).location\n"+
+		"    sku = {\n"+
+		"        name = \"Standard_LRS\"\n"+
+		"    }\n"+
+		"    kind = \"StorageV2\"\n"+
+		"    supportsHttpsTrafficOnly = true\n"+
+		"}\n")
+	assert.Contains(t, text, "resource virtualNetwork \"azure-native:network:VirtualNetwork\" {\n"+
+		"    resourceGroupName = resourceGroupName\n"+
+		"    virtualNetworkName = \"${storageName}-vnet\"\n"+
+		"    tags = {\n"+
+		"        \"cost-center\" = \"42\"\n"+
+		"    }\n"+
+		"    options {\n"+
+		"        dependsOn = [storageAccount]\n"+
+		"    }\n"+
+		"}\n")
+	assert.Contains(t, text, "output endpoint {\n    value = storageAccount.primaryEndpoints.blob\n}\n")
+
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "TODO: the template function uniqueString is not supported", diags[0].Summary)
+	}
+}
@@ -0,0 +1,576 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// cloudFormationPackage is the Pulumi package whose resources correspond one-to-one with CloudFormation resource
+// types.
+const cloudFormationPackage = "aws-native"
+
+// samTransform is the transform that marks a template as an AWS SAM template.
+const samTransform = "AWS::Serverless-2016-10-31"
+
+// cloudFormationTemplates lists the names of the templates that are converted when the source is a directory, in
+// order of preference.
+var cloudFormationTemplates = []string{"template.yaml", "template.yml", "template.json"}
+
+// cloudFormationParameterTypes maps CloudFormation parameter types to PCL configuration types. AWS-specific parameter
+// types, such as `AWS::EC2::VPC::Id`, are strings.
+var cloudFormationParameterTypes = map[string]string{
+	"String":                    "string",
+	"Number":                    "number",
+	"List<Number>":              "list(number)",
+	"CommaDelimitedList":        "list(string)",
+	"AWS::SSM::Parameter::Name": "string",
+}
+
+type cloudFormationConverter struct {
+	path      string
+	resources map[string]*schema.Resource
+	names     *nameTable
+	diags     hcl.Diagnostics
+
+	// The logical IDs of the template's parameters, mappings, and resources.
+	parameters map[string]bool
+	mappings   map[string]bool
+	declared   map[string]*schema.Resource
+}
+
+// CloudFormationToPCL converts a CloudFormation template, written in YAML or JSON, into a PCL program. If path is a
+// directory, the template is read from the first of template.yaml, template.yml, or template.json that exists.
+// Parameters become configuration, mappings become local variables, resources become resources of the aws-native
+// package, and outputs become outputs. The intrinsic functions Ref, Fn::GetAtt, Fn::Sub, Fn::Join, Fn::Split,
+// Fn::Select, and Fn::FindInMap are converted to equivalent expressions; other intrinsics, conditions, and AWS SAM
+// resources are reported as warnings.
+func CloudFormationToPCL(path string, loader schema.Loader) ([]byte, hcl.Diagnostics, error) {
+	path, err := findCloudFormationTemplate(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, nil, fmt.Errorf("could not parse %v: %w", path, err)
+	}
+	if len(doc.Content) != 1 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("%v is not a CloudFormation template", path)
+	}
+	template := doc.Content[0]
+
+	pkg, err := loader.LoadPackage(cloudFormationPackage, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load the %v package: %w", cloudFormationPackage, err)
+	}
+
+	c := &cloudFormationConverter{
+		path:       path,
+		resources:  map[string]*schema.Resource{},
+		names:      newNameTable(),
+		parameters: map[string]bool{},
+		mappings:   map[string]bool{},
+		declared:   map[string]*schema.Resource{},
+	}
+	for _, r := range pkg.Resources {
+		c.resources[r.Token] = r
+	}
+	c.declare(template)
+
+	var blocks []string
+	for i := 0; i+1 < len(template.Content); i += 2 {
+		key, value := template.Content[i], template.Content[i+1]
+		switch key.Value {
+		case "Parameters":
+			blocks = append(blocks, c.convertSection(value, c.convertParameter)...)
+		case "Mappings":
+			blocks = append(blocks, c.convertSection(value, c.convertMapping)...)
+		case "Resources":
+			blocks = append(blocks, c.convertSection(value, c.convertResource)...)
+		case "Outputs":
+			blocks = append(blocks, c.convertSection(value, c.convertOutput)...)
+		case "Conditions", "Rules":
+			c.diags = append(c.diags, c.unsupported(key, "TODO: %v are not supported; the resources and "+
+				"outputs that use them are converted unconditionally", strings.ToLower(key.Value)))
+		case "Transform":
+			if !isSAMTransform(value) {
+				c.diags = append(c.diags, c.unsupported(key, "TODO: template transforms other than %v are "+
+					"not supported", samTransform))
+			}
+		case "Globals":
+			c.diags = append(c.diags, c.unsupported(key, "TODO: AWS SAM globals are not supported"))
+		case "AWSTemplateFormatVersion", "Description", "Metadata":
+			// These have no effect on the resources that are deployed.
+		default:
+			c.diags = append(c.diags, c.unsupported(key, "the template section %v is not supported", key.Value))
+		}
+	}
+	return []byte(strings.Join(blocks, "\n")), c.diags, nil
+}
+
+func findCloudFormationTemplate(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range cloudFormationTemplates {
+		candidate := filepath.Join(path, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no CloudFormation template found in %v; expected one of %v", path,
+		strings.Join(cloudFormationTemplates, ", "))
+}
+
+func isSAMTransform(node *yaml.Node) bool {
+	if node.Kind == yaml.SequenceNode {
+		for _, n := range node.Content {
+			if n.Value == samTransform {
+				return true
+			}
+		}
+		return false
+	}
+	return node.Value == samTransform
+}
+
+// declare assigns PCL names to the template's parameters, mappings, and resources. Parameters are named first so
+// that their names are not suffixed, as they become configuration keys.
+func (c *cloudFormationConverter) declare(template *yaml.Node) {
+	for _, section := range []string{"Parameters", "Mappings", "Resources", "Outputs"} {
+		value := mappingValue(template, section)
+		if value == nil || value.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			id := value.Content[i].Value
+			c.names.declare(section+"."+id, pascalToCamelCase(id))
+			switch section {
+			case "Parameters":
+				c.parameters[id] = true
+			case "Mappings":
+				c.mappings[id] = true
+			case "Resources":
+				if typ := mappingValue(value.Content[i+1], "Type"); typ != nil {
+					c.declared[id] = c.resources[cloudFormationToken(typ.Value)]
+				}
+			}
+		}
+	}
+}
+
+func (c *cloudFormationConverter) convertSection(section *yaml.Node,
+	convert func(id string, key, value *yaml.Node) string) []string {
+
+	if section.Kind != yaml.MappingNode {
+		c.diags = append(c.diags, c.unsupported(section, "expected a mapping"))
+		return nil
+	}
+	var blocks []string
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		if text := convert(section.Content[i].Value, section.Content[i], section.Content[i+1]); text != "" {
+			blocks = append(blocks, text)
+		}
+	}
+	return blocks
+}
+
+func (c *cloudFormationConverter) convertParameter(id string, key, value *yaml.Node) string {
+	name, _ := c.names.lookup("Parameters." + id)
+
+	header := "config " + name
+	var items []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		switch k.Value {
+		case "Type":
+			typ, ok := cloudFormationParameterTypes[v.Value]
+			switch {
+			case ok:
+			case strings.HasPrefix(v.Value, "List<"):
+				typ = "list(string)"
+			default:
+				typ = "string"
+			}
+			header += " " + strconv.Quote(typ)
+		case "Default":
+			items = append(items, "default = "+c.value(v, nil))
+		case "Description":
+			items = append(items, "description = "+c.value(v, nil))
+		case "NoEcho":
+			c.diags = append(c.diags, c.unsupported(k, "TODO: NoEcho is not converted; set the %v configuration "+
+				"value with `pulumi config set --secret` instead", name))
+		default:
+			c.diags = append(c.diags, c.unsupported(k, "the parameter property %v is not supported", k.Value))
+		}
+	}
+	return header + " " + bodyText(items) + "\n"
+}
+
+func (c *cloudFormationConverter) convertMapping(id string, key, value *yaml.Node) string {
+	name, _ := c.names.lookup("Mappings." + id)
+	return name + " = " + c.value(value, schema.AnyType) + "\n"
+}
+
+func (c *cloudFormationConverter) convertResource(id string, key, value *yaml.Node) string {
+	name, _ := c.names.lookup("Resources." + id)
+
+	typ := mappingValue(value, "Type")
+	if typ == nil {
+		c.diags = append(c.diags, c.unsupported(key, "the resource %v has no type", id))
+		return ""
+	}
+	if strings.HasPrefix(typ.Value, "AWS::Serverless::") {
+		c.diags = append(c.diags, c.unsupported(typ, "TODO: the AWS SAM resource type %v is not supported; "+
+			"expand the template with `sam package` or `aws cloudformation package` before converting it", typ.Value))
+		return ""
+	}
+	res := c.declared[id]
+	if res == nil {
+		c.diags = append(c.diags, c.unsupported(typ, "no Pulumi resource corresponds to the CloudFormation "+
+			"resource type %v", typ.Value))
+		return ""
+	}
+
+	var items, options []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		switch k.Value {
+		case "Type", "Metadata":
+		case "Properties":
+			items = c.convertObject(v, res.InputProperties)
+		case "DependsOn":
+			var deps []string
+			for _, n := range sequenceOrScalar(v) {
+				if dep, ok := c.names.lookup("Resources." + n.Value); ok {
+					deps = append(deps, dep)
+				} else {
+					c.diags = append(c.diags, c.unsupported(n, "unknown resource %v", n.Value))
+				}
+			}
+			options = append(options, "dependsOn = ["+strings.Join(deps, ", ")+"]")
+		case "DeletionPolicy":
+			if v.Value != "Delete" {
+				c.diags = append(c.diags, c.unsupported(k, "TODO: the deletion policy %v is not supported",
+					v.Value))
+			}
+		case "Condition":
+			c.diags = append(c.diags, c.unsupported(k, "TODO: the condition %v is not supported; the resource is "+
+				"created unconditionally", v.Value))
+		default:
+			c.diags = append(c.diags, c.unsupported(k, "TODO: the resource attribute %v is not supported", k.Value))
+		}
+	}
+	if len(options) != 0 {
+		items = append(items, "options "+bodyText(options))
+	}
+	return fmt.Sprintf("resource %s %s %s\n", name, strconv.Quote(res.Token), bodyText(items))
+}
+
+func (c *cloudFormationConverter) convertOutput(id string, key, value *yaml.Node) string {
+	name, _ := c.names.lookup("Outputs." + id)
+
+	var items []string
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		k, v := value.Content[i], value.Content[i+1]
+		switch k.Value {
+		case "Value":
+			items = append(items, "value = "+c.value(v, nil))
+		case "Description":
+		case "Export":
+			c.diags = append(c.diags, c.unsupported(k, "TODO: output exports are not supported; reference the "+
+				"output from other stacks with a stack reference instead"))
+		case "Condition":
+			c.diags = append(c.diags, c.unsupported(k, "TODO: the condition %v is not supported; the output is "+
+				"exported unconditionally", v.Value))
+		default:
+			c.diags = append(c.diags, c.unsupported(k, "the output property %v is not supported", k.Value))
+		}
+	}
+	return fmt.Sprintf("output %s %s\n", name, bodyText(items))
+}
+
+// convertObject converts the entries of a mapping into the items of a PCL object. Keys are converted to camelCase
+// unless the object's type is a map or is untyped, e.g. an IAM policy document.
+func (c *cloudFormationConverter) convertObject(node *yaml.Node, props []*schema.Property) []string {
+	var items []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		name := pascalToCamelCase(k.Value)
+		var typ schema.Type
+		if prop := findProperty(props, name); prop != nil {
+			typ = prop.Type
+		}
+		items = append(items, name+" = "+c.value(v, typ))
+	}
+	return items
+}
+
+// value converts a YAML value into a PCL expression. The type, if any, is the schema type of the value.
+func (c *cloudFormationConverter) value(node *yaml.Node, typ schema.Type) string {
+	if name, arg, ok := intrinsic(node); ok {
+		return c.intrinsic(node, name, arg)
+	}
+
+	typ = unwrapType(typ)
+	switch node.Kind {
+	case yaml.AliasNode:
+		return c.value(node.Alias, typ)
+	case yaml.SequenceNode:
+		var elementType schema.Type
+		if array, ok := typ.(*schema.ArrayType); ok {
+			elementType = array.ElementType
+		}
+		var elements []string
+		for _, n := range node.Content {
+			elements = append(elements, c.value(n, elementType))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case yaml.MappingNode:
+		var items []string
+		switch typ := typ.(type) {
+		case *schema.ObjectType:
+			items = c.convertObject(node, typ.Properties)
+		case *schema.MapType:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				items = append(items, strconv.Quote(node.Content[i].Value)+" = "+c.value(node.Content[i+1],
+					typ.ElementType))
+			}
+		default:
+			if typ == nil {
+				items = c.convertObject(node, nil)
+				break
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				items = append(items, strconv.Quote(node.Content[i].Value)+" = "+c.value(node.Content[i+1],
+					schema.AnyType))
+			}
+		}
+		return bodyText(items)
+	default:
+		switch node.Tag {
+		case "!!int", "!!float", "!!bool":
+			return node.Value
+		case "!!null":
+			return "null"
+		default:
+			return quoteTemplate(node.Value)
+		}
+	}
+}
+
+// intrinsic returns the name and argument of the intrinsic function call represented by a node, if any. Calls may
+// be written in their full form, e.g. `{"Fn::GetAtt": [...]}`, or in their short form, e.g. `!GetAtt ...`.
+func intrinsic(node *yaml.Node) (string, *yaml.Node, bool) {
+	if strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		name := node.Tag[1:]
+		if name != "Ref" && name != "Condition" {
+			name = "Fn::" + name
+		}
+		arg := *node
+		switch arg.Kind {
+		case yaml.SequenceNode:
+			arg.Tag = "!!seq"
+		case yaml.MappingNode:
+			arg.Tag = "!!map"
+		default:
+			arg.Tag = "!!str"
+		}
+		return name, &arg, true
+	}
+	if node.Kind == yaml.MappingNode && len(node.Content) == 2 {
+		if name := node.Content[0].Value; name == "Ref" || name == "Condition" || strings.HasPrefix(name, "Fn::") {
+			return name, node.Content[1], true
+		}
+	}
+	return "", nil, false
+}
+
+func (c *cloudFormationConverter) intrinsic(node *yaml.Node, name string, arg *yaml.Node) string {
+	switch name {
+	case "Ref":
+		if text, ok := c.ref(arg.Value); ok {
+			return text
+		}
+		if strings.HasPrefix(arg.Value, "AWS::") {
+			c.diags = append(c.diags, c.unsupported(node, "TODO: the pseudo parameter %v is not supported", arg.Value))
+			return notImplemented(arg.Value)
+		}
+	case "Fn::GetAtt":
+		var parts []string
+		if arg.Kind == yaml.SequenceNode {
+			for _, n := range arg.Content {
+				parts = append(parts, n.Value)
+			}
+		} else {
+			parts = strings.SplitN(arg.Value, ".", 2)
+		}
+		if len(parts) == 2 {
+			if text, ok := c.getAtt(parts[0], parts[1]); ok {
+				return text
+			}
+		}
+	case "Fn::Sub":
+		if text, ok := c.sub(arg); ok {
+			return text
+		}
+	case "Fn::Join":
+		if arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 {
+			return fmt.Sprintf("join(%s, %s)", c.value(arg.Content[0], nil), c.value(arg.Content[1], nil))
+		}
+	case "Fn::Split":
+		if arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 {
+			return fmt.Sprintf("split(%s, %s)", c.value(arg.Content[0], nil), c.value(arg.Content[1], nil))
+		}
+	case "Fn::Select":
+		if arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 {
+			return fmt.Sprintf("element(%s, %s)", c.value(arg.Content[1], nil), c.value(arg.Content[0], nil))
+		}
+	case "Fn::FindInMap":
+		if arg.Kind == yaml.SequenceNode && len(arg.Content) == 3 && c.mappings[arg.Content[0].Value] {
+			mapping, _ := c.names.lookup("Mappings." + arg.Content[0].Value)
+			return fmt.Sprintf("%s[%s][%s]", mapping, c.value(arg.Content[1], nil), c.value(arg.Content[2], nil))
+		}
+	default:
+		c.diags = append(c.diags, c.unsupported(node, "TODO: the intrinsic function %v is not supported", name))
+		return notImplemented(name)
+	}
+
+	c.diags = append(c.diags, c.unsupported(node, "TODO: this use of %v cannot be converted", name))
+	return notImplemented(name)
+}
+
+// ref converts a reference to a parameter or resource. References to resources evaluate to their IDs.
+func (c *cloudFormationConverter) ref(id string) (string, bool) {
+	if c.parameters[id] {
+		return c.names.lookup("Parameters." + id)
+	}
+	if name, ok := c.names.lookup("Resources." + id); ok {
+		return name + ".id", true
+	}
+	return "", false
+}
+
+// getAtt converts a reference to an attribute of a resource. Nested attributes, e.g. `Endpoint.Address`, become
+// property accesses.
+func (c *cloudFormationConverter) getAtt(id, attr string) (string, bool) {
+	name, ok := c.names.lookup("Resources." + id)
+	if !ok {
+		return "", false
+	}
+	for _, part := range strings.Split(attr, ".") {
+		name += "." + pascalToCamelCase(part)
+	}
+	return name, true
+}
+
+// sub converts an Fn::Sub call into a string template. Variables are references to parameters, resources, or
+// resource attributes, or are bound by the call's variable map.
+func (c *cloudFormationConverter) sub(arg *yaml.Node) (string, bool) {
+	format, vars := arg, map[string]string{}
+	if arg.Kind == yaml.SequenceNode {
+		if len(arg.Content) != 2 || arg.Content[1].Kind != yaml.MappingNode {
+			return "", false
+		}
+		format = arg.Content[0]
+		for i := 0; i+1 < len(arg.Content[1].Content); i += 2 {
+			vars[arg.Content[1].Content[i].Value] = c.value(arg.Content[1].Content[i+1], nil)
+		}
+	}
+	if format.Kind != yaml.ScalarNode {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString(`"`)
+	s := format.Value
+	for {
+		start := strings.Index(s, "${")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(s[start:], "}")
+		if end == -1 {
+			break
+		}
+		end += start
+
+		b.WriteString(escapeTemplate(s[:start]))
+		variable := s[start+2 : end]
+		s = s[end+1:]
+
+		if strings.HasPrefix(variable, "!") {
+			// `${!Literal}` is written as `${Literal}`.
+			b.WriteString("$${" + escapeTemplate(variable[1:]) + "}")
+			continue
+		}
+
+		text, ok := vars[variable]
+		if !ok {
+			if i := strings.Index(variable, "."); i != -1 {
+				text, ok = c.getAtt(variable[:i], variable[i+1:])
+			} else {
+				text, ok = c.ref(variable)
+			}
+		}
+		if !ok {
+			c.diags = append(c.diags, c.unsupported(format, "TODO: the variable %v cannot be converted", variable))
+			text = notImplemented(variable)
+		}
+		b.WriteString("${" + text + "}")
+	}
+	b.WriteString(escapeTemplate(s))
+	b.WriteString(`"`)
+	return b.String(), true
+}
+
+func (c *cloudFormationConverter) unsupported(node *yaml.Node, format string, args ...interface{}) *hcl.Diagnostic {
+	return unsupported(nodeRange(c.path, node), format, args...)
+}
+
+// cloudFormationToken returns the aws-native token that corresponds to a CloudFormation resource type, e.g.
+// `aws-native:s3:Bucket` for `AWS::S3::Bucket`.
+func cloudFormationToken(typ string) string {
+	components := strings.Split(typ, "::")
+	if len(components) != 3 || components[0] != "AWS" {
+		return ""
+	}
+	return cloudFormationPackage + ":" + strings.ToLower(components[1]) + ":" + components[2]
+}
+
+func sequenceOrScalar(node *yaml.Node) []*yaml.Node {
+	if node.Kind == yaml.SequenceNode {
+		return node.Content
+	}
+	return []*yaml.Node{node}
+}
@@ -0,0 +1,124 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func TestPascalToCamelCase(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"BucketName": "bucketName",
+		"Arn":        "arn",
+		"VPCId":      "vpcId",
+		"URL":        "url",
+		"name":       "name",
+	}
+	for name, expected := range cases {
+		assert.Equal(t, expected, pascalToCamelCase(name))
+	}
+}
+
+func TestCloudFormationToPCL(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := `AWSTemplateFormatVersion: "2010-09-09"
+Transform: AWS::Serverless-2016-10-31
+Parameters:
+  Environment:
+    Type: String
+    Default: dev
+Mappings:
+  Sizes:
+    dev:
+      Memory: 128
+Conditions:
+  IsProd: !Equals [!Ref Environment, prod]
+Resources:
+  SiteBucket:
+    Type: AWS::S3::Bucket
+    DeletionPolicy: Retain
+    Properties:
+      BucketName: !Sub "${Environment}-site-${AWS::Region}"
+      Tags:
+        - Key: env
+          Value: !Ref Environment
+  Queue:
+    Type: AWS::SQS::Queue
+    DependsOn: SiteBucket
+    Properties:
+      QueueName: !Join ["-", [!Ref SiteBucket, !GetAtt SiteBucket.Arn]]
+      DelaySeconds: !FindInMap [Sizes, !Ref Environment, Memory]
+  Handler:
+    Type: AWS::Serverless::Function
+Outputs:
+  BucketArn:
+    Value:
+      Fn::GetAtt: [SiteBucket, Arn]
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "template.yaml"), []byte(source), 0600))
+
+	loader := testLoader{
+		"aws-native": {
+			Resources: []*schema.Resource{
+				{Token: "aws-native:s3:Bucket"},
+				{Token: "aws-native:sqs:Queue"},
+			},
+		},
+	}
+	program, diags, err := CloudFormationToPCL(dir, loader)
+	assert.NoError(t, err)
+
+	text := string(program)
+	assert.Contains(t, text, "config environment \"string\" {\n    default = \"dev\"\n}\n")
+	assert.Contains(t, text, "sizes = {\n    \"dev\" = {\n        \"Memory\" = 128\n    }\n}\n")
+	assert.Contains(t, text, "resource siteBucket \"aws-native:s3:Bucket\" {\n"+
+		"    bucketName = \"${environment}-site-${notImplemented(\"AWS::Region\")}\"\n"+
+		"    tags = [{\n"+
+		"        key = \"env\"\n"+
+		"        value = environment\n"+
+		"    }]\n"+
+		"}\n")
+	assert.Contains(t, text, "resource queue \"aws-native:sqs:Queue\" {\n"+
+		"    queueName = join(\"-\", [siteBucket.id, siteBucket.arn])\n"+
+		"    delaySeconds = sizes[environment][\"Memory\"]\n"+
+		"    options {\n"+
+		"        dependsOn = [siteBucket]\n"+
+		"    }\n"+
+		"}\n")
+	assert.Contains(t, text, "output bucketArn {\n    value = siteBucket.arn\n}\n")
+	assert.NotContains(t, text, "handler")
+
+	var summaries []string
+	for _, d := range diags {
+		summaries = append(summaries, d.Summary)
+	}
+	assert.Equal(t, []string{
+		"TODO: conditions are not supported; the resources and outputs that use them are converted unconditionally",
+		"TODO: the deletion policy Retain is not supported",
+		"TODO: the variable AWS::Region cannot be converted",
+		"TODO: the AWS SAM resource type AWS::Serverless::Function is not supported; expand the template with " +
+			"`sam package` or `aws cloudformation package` before converting it",
+	}, summaries)
+}
@@ -0,0 +1,179 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package convert translates programs written for other infrastructure as code tools into PCL, Pulumi's
+// language-neutral program representation. Programs in any supported language can then be generated from the PCL.
+//
+// Each converter produces the PCL text of a single program along with diagnostics that describe the constructs that
+// could not be converted. Unconvertible constructs are left in place where possible so that the resulting program
+// fails to bind at the right location rather than silently dropping the construct.
+package convert
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// camelCase converts a snake_case name to camelCase, e.g. `bucket_prefix` to `bucketPrefix`.
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := false
+	for i, r := range name {
+		switch {
+		case r == '_' && i > 0:
+			upper = true
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pascalToCamelCase converts a PascalCase name to camelCase, lowering any leading acronym, e.g. `VPCId` to `vpcId`.
+func pascalToCamelCase(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// identifier matches the names that need not be quoted when used as PCL object keys.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// objectKey renders a PCL object key, quoting it if necessary.
+func objectKey(key string) string {
+	if identifier.MatchString(key) {
+		return key
+	}
+	return strconv.Quote(key)
+}
+
+// nameTable assigns unique PCL names to the declarations of a program. PCL declares configuration, local variables,
+// resources, and outputs in a single namespace.
+type nameTable struct {
+	taken map[string]bool
+	names map[string]string
+}
+
+func newNameTable() *nameTable {
+	return &nameTable{taken: map[string]bool{}, names: map[string]string{}}
+}
+
+// declare assigns a PCL name derived from name to the declaration identified by key.
+func (t *nameTable) declare(key, name string) string {
+	unique := name
+	for i := 2; t.taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	t.taken[unique] = true
+	t.names[key] = unique
+	return unique
+}
+
+// lookup returns the PCL name of the declaration identified by key.
+func (t *nameTable) lookup(key string) (string, bool) {
+	name, ok := t.names[key]
+	return name, ok
+}
+
+// textEdit replaces the bytes in [start, end) of a source file.
+type textEdit struct {
+	start, end int
+	text       string
+}
+
+// applyEdits applies a set of non-overlapping edits to the bytes in [start, end) of src and returns the result.
+func applyEdits(src []byte, start, end int, edits []textEdit) string {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var b strings.Builder
+	offset := start
+	for _, e := range edits {
+		if e.start < offset || e.end > end {
+			continue
+		}
+		b.Write(src[offset:e.start])
+		b.WriteString(e.text)
+		offset = e.end
+	}
+	b.Write(src[offset:end])
+	return b.String()
+}
+
+// unsupported returns a warning diagnostic that describes a construct that could not be converted.
+func unsupported(rng hcl.Range, format string, args ...interface{}) *hcl.Diagnostic {
+	return &hcl.Diagnostic{
+		Severity: hcl.DiagWarning,
+		Summary:  fmt.Sprintf(format, args...),
+		Subject:  rng.Ptr(),
+	}
+}
+
+// indent indents every line but the first of a block of text by one level.
+func indent(text string) string {
+	return strings.ReplaceAll(text, "\n", "\n    ")
+}
+
+// mappingValue returns the value of the entry in a mapping with the given key, if any.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// nodeRange returns the position of a YAML or JSON node in a source file.
+func nodeRange(path string, node *yaml.Node) hcl.Range {
+	pos := hcl.Pos{Line: node.Line, Column: node.Column}
+	return hcl.Range{Filename: path, Start: pos, End: pos}
+}
+
+// quoteTemplate renders a string as a PCL string literal, escaping template sequences.
+func quoteTemplate(s string) string {
+	return `"` + escapeTemplate(s) + `"`
+}
+
+func escapeTemplate(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = quoted[1 : len(quoted)-1]
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(quoted)
+}
+
+// notImplemented renders a call to an undefined function in place of a construct that could not be converted, so that
+// the converted program fails to bind where the construct was used.
+func notImplemented(construct string) string {
+	return fmt.Sprintf("notImplemented(%s)", strconv.Quote(construct))
+}
@@ -0,0 +1,331 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// kubernetesPackage is the Pulumi package whose resources correspond to Kubernetes kinds.
+const kubernetesPackage = "kubernetes"
+
+// kustomizations lists the names of the files that mark a directory as a kustomization.
+var kustomizations = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+type kubernetesObject struct {
+	path    string
+	node    *yaml.Node
+	token   string
+	kind    string
+	name    string
+	pclName string
+}
+
+type kubernetesConverter struct {
+	names *nameTable
+	diags hcl.Diagnostics
+
+	// namespaces maps the names of the namespaces declared by the manifests to their PCL names.
+	namespaces map[string]string
+}
+
+// KubernetesToPCL converts Kubernetes manifests into a PCL program. The path may name a YAML or JSON file, which may
+// contain multiple documents, or a directory. A directory that contains a kustomization is first built with
+// kustomize; otherwise, every .yaml, .yml, and .json file in the directory is converted. Objects of kind List are
+// expanded into their items.
+//
+// Each object becomes a resource of the kubernetes package whose inputs are the object's fields, with the exception
+// of its apiVersion, kind, and status. Namespaces and labels are preserved; objects in a namespace that the manifests
+// also declare refer to the namespace's resource, so that the namespace is created first. Objects whose kinds have no
+// corresponding resource, such as custom resources, are reported as warnings.
+func KubernetesToPCL(path string, loader schema.Loader) ([]byte, hcl.Diagnostics, error) {
+	docs, err := readKubernetesManifests(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pkg, err := loader.LoadPackage(kubernetesPackage, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load the %v package: %w", kubernetesPackage, err)
+	}
+	resources := map[string]*schema.Resource{}
+	for _, r := range pkg.Resources {
+		resources[r.Token] = r
+	}
+
+	c := &kubernetesConverter{names: newNameTable(), namespaces: map[string]string{}}
+	var objects []*kubernetesObject
+	for _, doc := range docs {
+		for _, obj := range kubernetesObjects(doc.path, doc.node) {
+			apiVersion, kind := mappingValue(obj.node, "apiVersion"), mappingValue(obj.node, "kind")
+			if apiVersion == nil || kind == nil {
+				c.diags = append(c.diags, unsupported(nodeRange(obj.path, obj.node),
+					"the object has no apiVersion or kind"))
+				continue
+			}
+			obj.kind, obj.token = kind.Value, kubernetesToken(apiVersion.Value, kind.Value)
+			if _, ok := resources[obj.token]; !ok {
+				c.diags = append(c.diags, unsupported(nodeRange(obj.path, kind),
+					"TODO: no Pulumi resource corresponds to the kind %v in %v; custom resources are not supported",
+					kind.Value, apiVersion.Value))
+				continue
+			}
+			if metadata := mappingValue(obj.node, "metadata"); metadata != nil {
+				if name := mappingValue(metadata, "name"); name != nil {
+					obj.name = name.Value
+				}
+			}
+			objects = append(objects, obj)
+		}
+	}
+
+	// Namespaces are named first so that references to them can be resolved.
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].token == "kubernetes:core/v1:Namespace" && objects[j].token != "kubernetes:core/v1:Namespace"
+	})
+	for _, obj := range objects {
+		obj.pclName = c.names.declare(obj.path+"/"+obj.kind+"/"+obj.name, kubernetesName(obj.name, obj.kind))
+		if obj.token == "kubernetes:core/v1:Namespace" {
+			c.namespaces[obj.name] = obj.pclName
+		}
+	}
+
+	var blocks []string
+	for _, obj := range objects {
+		blocks = append(blocks, c.convertObject(obj))
+	}
+	return []byte(strings.Join(blocks, "\n")), c.diags, nil
+}
+
+type kubernetesDocument struct {
+	path string
+	node *yaml.Node
+}
+
+// readKubernetesManifests reads the documents in the manifests at path.
+func readKubernetesManifests(path string) ([]kubernetesDocument, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	switch {
+	case !info.IsDir():
+		paths = []string{path}
+	case isKustomization(path):
+		src, err := buildKustomization(path)
+		if err != nil {
+			return nil, err
+		}
+		return decodeKubernetesManifests(path, src)
+	default:
+		for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+			matches, err := filepath.Glob(filepath.Join(path, pattern))
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, matches...)
+		}
+		sort.Strings(paths)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no Kubernetes manifests found in %v", path)
+		}
+	}
+
+	var docs []kubernetesDocument
+	for _, p := range paths {
+		src, err := ioutil.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		fileDocs, err := decodeKubernetesManifests(p, src)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, fileDocs...)
+	}
+	return docs, nil
+}
+
+func decodeKubernetesManifests(path string, src []byte) ([]kubernetesDocument, error) {
+	var docs []kubernetesDocument
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("could not parse %v: %w", path, err)
+		}
+		if len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode {
+			docs = append(docs, kubernetesDocument{path: path, node: doc.Content[0]})
+		}
+	}
+}
+
+func isKustomization(dir string) bool {
+	for _, name := range kustomizations {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// buildKustomization builds the kustomization in dir using kustomize, or kubectl if kustomize is not installed.
+func buildKustomization(dir string) ([]byte, error) {
+	var cmd *exec.Cmd
+	if kustomize, err := exec.LookPath("kustomize"); err == nil {
+		cmd = exec.Command(kustomize, "build", dir)
+	} else if kubectl, err := exec.LookPath("kubectl"); err == nil {
+		cmd = exec.Command(kubectl, "kustomize", dir)
+	} else {
+		return nil, fmt.Errorf("%v contains a kustomization, which requires kustomize or kubectl to build", dir)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("could not build the kustomization in %v: %w\n%s", dir, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// kubernetesObjects returns the objects in a document, expanding lists into their items.
+func kubernetesObjects(path string, node *yaml.Node) []*kubernetesObject {
+	kind := mappingValue(node, "kind")
+	if items := mappingValue(node, "items"); kind != nil && strings.HasSuffix(kind.Value, "List") && items != nil {
+		var objects []*kubernetesObject
+		for _, item := range items.Content {
+			if item.Kind == yaml.MappingNode {
+				objects = append(objects, kubernetesObjects(path, item)...)
+			}
+		}
+		return objects
+	}
+	return []*kubernetesObject{{path: path, node: node}}
+}
+
+// kubernetesToken returns the token of the resource that corresponds to a Kubernetes kind, e.g.
+// `kubernetes:apps/v1:Deployment` for the kind Deployment in apps/v1. Kinds in the core group, whose API version has
+// no group, are in the core module.
+func kubernetesToken(apiVersion, kind string) string {
+	if !strings.Contains(apiVersion, "/") {
+		apiVersion = "core/" + apiVersion
+	}
+	return kubernetesPackage + ":" + apiVersion + ":" + kind
+}
+
+// kubernetesName returns the PCL name of an object, e.g. `nginxDeployment` for the Deployment named `nginx`.
+func kubernetesName(name, kind string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	b.WriteString("_" + kind)
+
+	result := camelCase(strings.Trim(b.String(), "_"))
+	if result == "" || !unicode.IsLetter([]rune(result)[0]) {
+		result = camelCase("resource_" + result)
+	}
+	return result
+}
+
+func (c *kubernetesConverter) convertObject(obj *kubernetesObject) string {
+	var items []string
+	for i := 0; i+1 < len(obj.node.Content); i += 2 {
+		k, v := obj.node.Content[i], obj.node.Content[i+1]
+		switch k.Value {
+		case "apiVersion", "kind", "status":
+		case "metadata":
+			items = append(items, "metadata = "+bodyText(c.convertMetadata(obj, v)))
+		default:
+			items = append(items, objectKey(k.Value)+" = "+c.value(v))
+		}
+	}
+	return fmt.Sprintf("resource %s %s %s\n", obj.pclName, strconv.Quote(obj.token), bodyText(items))
+}
+
+// convertMetadata converts an object's metadata. References to namespaces that are declared by the manifests are
+// replaced with references to their resources. Fields that are set by the cluster are removed.
+func (c *kubernetesConverter) convertMetadata(obj *kubernetesObject, node *yaml.Node) []string {
+	var items []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], node.Content[i+1]
+		switch k.Value {
+		case "creationTimestamp", "generation", "managedFields", "resourceVersion", "selfLink", "uid":
+		case "namespace":
+			if ns, ok := c.namespaces[v.Value]; ok {
+				items = append(items, "namespace = "+ns+".metadata.name")
+			} else {
+				items = append(items, "namespace = "+c.value(v))
+			}
+		default:
+			items = append(items, objectKey(k.Value)+" = "+c.value(v))
+		}
+	}
+	return items
+}
+
+// value converts a YAML value into a PCL expression.
+func (c *kubernetesConverter) value(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return c.value(node.Alias)
+	case yaml.SequenceNode:
+		var elements []string
+		for _, n := range node.Content {
+			elements = append(elements, c.value(n))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case yaml.MappingNode:
+		var items []string
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			items = append(items, objectKey(node.Content[i].Value)+" = "+c.value(node.Content[i+1]))
+		}
+		return bodyText(items)
+	}
+
+	switch node.Tag {
+	case "!!int", "!!float", "!!bool":
+		return node.Value
+	case "!!null":
+		return "null"
+	default:
+		return quoteTemplate(node.Value)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+func TestKubernetesToken(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "kubernetes:apps/v1:Deployment", kubernetesToken("apps/v1", "Deployment"))
+	assert.Equal(t, "kubernetes:core/v1:ConfigMap", kubernetesToken("v1", "ConfigMap"))
+	assert.Equal(t, "nginxDeployment", kubernetesName("nginx", "Deployment"))
+	assert.Equal(t, "webFrontendService", kubernetesName("web-frontend", "Service"))
+	assert.Equal(t, "resource1appConfigMap", kubernetesName("1app", "ConfigMap"))
+}
+
+func TestKubernetesToPCL(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	app := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+  namespace: web
+  labels:
+    app.kubernetes.io/name: nginx
+  uid: 0b5d3c2e
+spec:
+  replicas: 2
+  template:
+    spec:
+      containers:
+        - name: nginx
+          image: nginx:1.21
+status:
+  readyReplicas: 2
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: settings
+      namespace: other
+    data:
+      config.json: '{"debug": true}'
+  - apiVersion: example.com/v1
+    kind: Widget
+    metadata:
+      name: gadget
+`
+	ns := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "app.yaml"), []byte(app), 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "namespace.yml"), []byte(ns), 0600))
+
+	loader := testLoader{
+		"kubernetes": {
+			Resources: []*schema.Resource{
+				{Token: "kubernetes:apps/v1:Deployment"},
+				{Token: "kubernetes:core/v1:ConfigMap"},
+				{Token: "kubernetes:core/v1:Namespace"},
+			},
+		},
+	}
+	program, diags, err := KubernetesToPCL(dir, loader)
+	assert.NoError(t, err)
+
+	expected := `resource webNamespace "kubernetes:core/v1:Namespace" {
+    metadata = {
+        name = "web"
+    }
+}
+
+resource nginxDeployment "kubernetes:apps/v1:Deployment" {
+    metadata = {
+        name = "nginx"
+        namespace = webNamespace.metadata.name
+        labels = {
+            "app.kubernetes.io/name" = "nginx"
+        }
+    }
+    spec = {
+        replicas = 2
+        template = {
+            spec = {
+                containers = [{
+                    name = "nginx"
+                    image = "nginx:1.21"
+                }]
+            }
+        }
+    }
+}
+
+resource settingsConfigMap "kubernetes:core/v1:ConfigMap" {
+    metadata = {
+        name = "settings"
+        namespace = "other"
+    }
+    data = {
+        "config.json" = "{\"debug\": true}"
+    }
+}
+`
+	assert.Equal(t, expected, string(program))
+
+	if assert.Len(t, diags, 1) {
+		assert.Equal(t, "TODO: no Pulumi resource corresponds to the kind Widget in example.com/v1; custom "+
+			"resources are not supported", diags[0].Summary)
+	}
+}
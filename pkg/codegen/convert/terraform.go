@@ -0,0 +1,825 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/python"
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+// terraformPackageAliases maps the names of Terraform providers to the names of the Pulumi packages that bridge them,
+// where the two differ.
+var terraformPackageAliases = map[string]string{
+	"azurerm": "azure",
+	"google":  "gcp",
+}
+
+// TerraformTypes maps Terraform resource and data source types to the resources and functions of the bridged Pulumi
+// packages. A bridged resource whose token is `pkg:mod/name:Type` manages the Terraform type `provider_mod_type` or
+// `provider_type`, and a bridged function whose token is `pkg:mod/getName:getName` reads the Terraform data source
+// `provider_mod_name` or `provider_name`.
+type TerraformTypes struct {
+	loader   schema.Loader
+	packages map[string]*terraformPackage
+}
+
+type terraformPackage struct {
+	resources map[string]*schema.Resource
+	functions map[string]*schema.Function
+}
+
+// NewTerraformTypes creates a type mapping that fetches the schemas of bridged packages using the given loader.
+func NewTerraformTypes(loader schema.Loader) *TerraformTypes {
+	return &TerraformTypes{loader: loader, packages: map[string]*terraformPackage{}}
+}
+
+// TerraformProviderName returns the name of the Terraform provider that manages a Terraform type, e.g. "aws" for
+// "aws_s3_bucket".
+func TerraformProviderName(tfType string) string {
+	if i := strings.Index(tfType, "_"); i != -1 {
+		return tfType[:i]
+	}
+	return tfType
+}
+
+// Resource returns the bridged resource that corresponds to a Terraform resource type managed by the given provider.
+func (t *TerraformTypes) Resource(provider, tfType string) (*schema.Resource, bool) {
+	p := t.packageFor(provider)
+	if p == nil {
+		return nil, false
+	}
+	r, ok := p.resources[tfType]
+	return r, ok
+}
+
+// DataSource returns the bridged function that corresponds to a Terraform data source managed by the given provider.
+func (t *TerraformTypes) DataSource(provider, tfType string) (*schema.Function, bool) {
+	p := t.packageFor(provider)
+	if p == nil {
+		return nil, false
+	}
+	f, ok := p.functions[tfType]
+	return f, ok
+}
+
+func (t *TerraformTypes) packageFor(provider string) *terraformPackage {
+	p, ok := t.packages[provider]
+	if !ok {
+		name := provider
+		if alias, ok := terraformPackageAliases[provider]; ok {
+			name = alias
+		}
+		if pkg, err := t.loader.LoadPackage(name, nil); err == nil {
+			p = newTerraformPackage(provider, pkg)
+		}
+		t.packages[provider] = p
+	}
+	return p
+}
+
+func newTerraformPackage(provider string, pkg *schema.Package) *terraformPackage {
+	p := &terraformPackage{
+		resources: map[string]*schema.Resource{},
+		functions: map[string]*schema.Function{},
+	}
+
+	unqualifiedResources := map[string]*schema.Resource{}
+	for _, r := range pkg.Resources {
+		if qualified, unqualified, ok := terraformNames(provider, r.Token, ""); ok {
+			if qualified != "" {
+				p.resources[qualified] = r
+			}
+			unqualifiedResources[unqualified] = r
+		}
+	}
+	for name, r := range unqualifiedResources {
+		if _, has := p.resources[name]; !has {
+			p.resources[name] = r
+		}
+	}
+
+	unqualifiedFunctions := map[string]*schema.Function{}
+	for _, f := range pkg.Functions {
+		if qualified, unqualified, ok := terraformNames(provider, f.Token, "get_"); ok {
+			if qualified != "" {
+				p.functions[qualified] = f
+			}
+			unqualifiedFunctions[unqualified] = f
+		}
+	}
+	for name, f := range unqualifiedFunctions {
+		if _, has := p.functions[name]; !has {
+			p.functions[name] = f
+		}
+	}
+
+	return p
+}
+
+// terraformNames returns the module-qualified and unqualified Terraform names that correspond to a Pulumi token. The
+// given prefix must be present on the token's name, and is removed. The qualified name is empty for tokens in the
+// index module.
+func terraformNames(provider, token, prefix string) (string, string, bool) {
+	components := strings.Split(token, ":")
+	if len(components) != 3 {
+		return "", "", false
+	}
+	mod, name := components[1], python.PyName(components[2])
+	if !strings.HasPrefix(name, prefix) {
+		return "", "", false
+	}
+	name = name[len(prefix):]
+	if i := strings.Index(mod, "/"); i != -1 {
+		mod = mod[:i]
+	}
+
+	qualified := ""
+	if mod != "" && mod != "index" {
+		qualified = provider + "_" + mod + "_" + name
+	}
+	return qualified, provider + "_" + name, true
+}
+
+// terraformFunctions maps the Terraform functions that have PCL equivalents to the names of those equivalents.
+var terraformFunctions = map[string]string{
+	"element":    "element",
+	"file":       "readFile",
+	"join":       "join",
+	"jsonencode": "toJSON",
+	"length":     "length",
+	"lookup":     "lookup",
+	"range":      "range",
+	"sensitive":  "secret",
+	"split":      "split",
+}
+
+type terraformFile struct {
+	src  []byte
+	body *hclsyntax.Body
+}
+
+// terraformConverter converts the configuration of a Terraform module. The root module is converted into a program,
+// and the local modules that it uses are converted alongside it, as if their declarations had been made in the root
+// module.
+type terraformConverter struct {
+	types *TerraformTypes
+	names *nameTable
+	diags hcl.Diagnostics
+
+	dir   string
+	files []*terraformFile
+
+	// prefix is prepended to the keys of the module's declarations in names, and namePrefix to their PCL names. Both
+	// are empty for the root module.
+	prefix     string
+	namePrefix string
+	// inputs holds the converted arguments of a module by the names of its variables. It is nil for the root module,
+	// whose variables become configuration.
+	inputs map[string]string
+	// modules holds the converters of the modules that the module uses, by the names of their module blocks.
+	modules map[string]*terraformConverter
+	// dirs holds the directories of the module and of the modules that use it, which the module must not use.
+	dirs map[string]bool
+}
+
+// TerraformToPCL converts the Terraform configuration in the `.tf` files of a directory into a PCL program. Variables
+// become configuration, locals become local variables, resources and data sources become resources and invokes, and
+// outputs become outputs. The declarations of modules that are loaded from local paths are converted as part of the
+// program, with their variables and outputs becoming local variables. Resource and data source types are mapped to
+// Pulumi types using types. Constructs that cannot be converted, such as remote modules and provider configuration,
+// are reported as warnings.
+func TerraformToPCL(dir string, types *TerraformTypes) ([]byte, hcl.Diagnostics, error) {
+	files, diagnostics, err := readTerraformFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if diagnostics.HasErrors() {
+		return nil, diagnostics, nil
+	}
+
+	c := &terraformConverter{
+		types:   types,
+		names:   newNameTable(),
+		dir:     dir,
+		files:   files,
+		modules: map[string]*terraformConverter{},
+		dirs:    map[string]bool{filepath.Clean(dir): true},
+	}
+	c.declare()
+	program := c.convert()
+	return []byte(program), append(diagnostics, c.diags...), nil
+}
+
+// readTerraformFiles reads and parses the `.tf` files of a directory.
+func readTerraformFiles(dir string) ([]*terraformFile, hcl.Diagnostics, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return nil, nil, fmt.Errorf("no Terraform files found in %v", dir)
+	}
+
+	var files []*terraformFile
+	var diagnostics hcl.Diagnostics
+	for _, path := range paths {
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		file, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+		diagnostics = append(diagnostics, diags...)
+		if diags.HasErrors() {
+			continue
+		}
+		files = append(files, &terraformFile{src: src, body: file.Body.(*hclsyntax.Body)})
+	}
+	return files, diagnostics, nil
+}
+
+// declareName assigns a PCL name derived from a Terraform name to the module's declaration identified by key.
+func (c *terraformConverter) declareName(key, name string) {
+	c.names.declare(c.prefix+key, c.namePrefix+name)
+}
+
+// lookupName returns the PCL name of the module's declaration identified by key.
+func (c *terraformConverter) lookupName(key string) (string, bool) {
+	return c.names.lookup(c.prefix + key)
+}
+
+// declare assigns PCL names to every declaration. Variables are named first so that they keep their names, as they
+// become configuration keys, and the declarations of modules are named last.
+func (c *terraformConverter) declare() {
+	for _, kind := range []string{"variable", "locals", "data", "resource", "output", "module"} {
+		for _, f := range c.files {
+			for _, block := range f.body.Blocks {
+				if block.Type != kind {
+					continue
+				}
+				switch {
+				case kind == "locals":
+					for _, attr := range sortedAttributes(block.Body) {
+						c.declareName("local."+attr.Name, attr.Name)
+					}
+				case kind == "variable" && len(block.Labels) == 1:
+					c.declareName("var."+block.Labels[0], block.Labels[0])
+				case kind == "output" && len(block.Labels) == 1:
+					c.declareName("output."+block.Labels[0], block.Labels[0])
+				case kind == "data" && len(block.Labels) == 2:
+					c.declareName("data."+block.Labels[0]+"."+block.Labels[1], block.Labels[1])
+				case kind == "resource" && len(block.Labels) == 2:
+					c.declareName(block.Labels[0]+"."+block.Labels[1], block.Labels[1])
+				case kind == "module" && len(block.Labels) == 1:
+					c.declareModule(block)
+				}
+			}
+		}
+	}
+}
+
+// declareModule loads the module that a module block uses, if it is a local module, and names its declarations.
+func (c *terraformConverter) declareModule(block *hclsyntax.Block) {
+	name := block.Labels[0]
+
+	attr, ok := block.Body.Attributes["source"]
+	if !ok {
+		c.diags = append(c.diags, unsupported(block.DefRange(), "TODO: the module %v has no source", name))
+		return
+	}
+	source, diags := attr.Expr.Value(nil)
+	if diags.HasErrors() || source.Type() != cty.String || source.IsNull() {
+		c.diags = append(c.diags, unsupported(attr.SrcRange, "TODO: the source of the module %v must be a string",
+			name))
+		return
+	}
+	path := source.AsString()
+	if !strings.HasPrefix(path, "./") && !strings.HasPrefix(path, "../") {
+		c.diags = append(c.diags, unsupported(attr.SrcRange, "TODO: the module %v is not converted; only modules "+
+			"with local sources are supported, so copy %v into a local directory", name, path))
+		return
+	}
+	dir := filepath.Clean(filepath.Join(c.dir, path))
+	if c.dirs[dir] {
+		c.diags = append(c.diags, unsupported(attr.SrcRange, "TODO: the module %v uses itself", name))
+		return
+	}
+
+	files, diags, err := readTerraformFiles(dir)
+	if err != nil {
+		c.diags = append(c.diags, unsupported(attr.SrcRange, "TODO: the module %v cannot be read: %v", name, err))
+		return
+	}
+	c.diags = append(c.diags, diags...)
+	if diags.HasErrors() {
+		return
+	}
+
+	dirs := map[string]bool{dir: true}
+	for d := range c.dirs {
+		dirs[d] = true
+	}
+	m := &terraformConverter{
+		types:      c.types,
+		names:      c.names,
+		dir:        dir,
+		files:      files,
+		prefix:     c.prefix + "module." + name + ".",
+		namePrefix: c.namePrefix + name + "_",
+		inputs:     map[string]string{},
+		modules:    map[string]*terraformConverter{},
+		dirs:       dirs,
+	}
+	m.declare()
+	c.modules[name] = m
+}
+
+// convert converts the declarations of the module.
+func (c *terraformConverter) convert() string {
+	var b strings.Builder
+	for _, f := range c.files {
+		for _, block := range f.body.Blocks {
+			text := c.convertBlock(f, block)
+			if text == "" {
+				continue
+			}
+			if b.Len() != 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(text)
+		}
+	}
+	return b.String()
+}
+
+func (c *terraformConverter) convertBlock(f *terraformFile, block *hclsyntax.Block) string {
+	switch block.Type {
+	case "variable":
+		return c.convertVariable(f, block)
+	case "locals":
+		var b strings.Builder
+		for _, attr := range sortedAttributes(block.Body) {
+			name, _ := c.lookupName("local." + attr.Name)
+			fmt.Fprintf(&b, "%s = %s\n", name, c.expr(f, attr.Expr))
+		}
+		return b.String()
+	case "resource":
+		return c.convertResource(f, block)
+	case "data":
+		return c.convertDataSource(f, block)
+	case "output":
+		return c.convertOutput(f, block)
+	case "module":
+		return c.convertModule(f, block)
+	case "provider":
+		c.diags = append(c.diags, unsupported(block.DefRange(), "TODO: provider configuration is not converted; "+
+			"set the equivalent configuration on the stack instead"))
+	case "terraform":
+		// Terraform settings, such as the backend and required providers, have no equivalent in a program.
+	default:
+		c.diags = append(c.diags, unsupported(block.DefRange(), "TODO: %v blocks are not supported", block.Type))
+	}
+	return ""
+}
+
+func (c *terraformConverter) convertVariable(f *terraformFile, block *hclsyntax.Block) string {
+	if len(block.Labels) != 1 {
+		return ""
+	}
+	name, _ := c.lookupName("var." + block.Labels[0])
+
+	// The variables of a module are set by the arguments of its module block.
+	if c.inputs != nil {
+		if input, ok := c.inputs[block.Labels[0]]; ok {
+			return fmt.Sprintf("%s = %s\n", name, input)
+		}
+		if def, ok := block.Body.Attributes["default"]; ok {
+			return fmt.Sprintf("%s = %s\n", name, c.expr(f, def.Expr))
+		}
+		c.diags = append(c.diags, unsupported(block.DefRange(), "TODO: the module variable %v is not set",
+			block.Labels[0]))
+		return fmt.Sprintf("%s = %s\n", name, notImplemented("var."+block.Labels[0]))
+	}
+
+	header := "config " + name
+	if attr, ok := block.Body.Attributes["type"]; ok {
+		typ := strings.Join(strings.Fields(string(attr.Expr.Range().SliceBytes(f.src))), "")
+		if !strings.Contains(typ, "any") {
+			header += " " + strconv.Quote(typ)
+		}
+	}
+
+	var items []string
+	for _, attr := range sortedAttributes(block.Body) {
+		switch attr.Name {
+		case "default", "description":
+			items = append(items, attr.Name+" = "+c.expr(f, attr.Expr))
+		case "type":
+		default:
+			c.diags = append(c.diags, unsupported(attr.NameRange, "TODO: the variable attribute %v is not supported",
+				attr.Name))
+		}
+	}
+	for _, b := range block.Body.Blocks {
+		c.diags = append(c.diags, unsupported(b.DefRange(), "TODO: variable %v blocks are not supported", b.Type))
+	}
+	return header + " " + bodyText(items) + "\n"
+}
+
+func (c *terraformConverter) convertResource(f *terraformFile, block *hclsyntax.Block) string {
+	if len(block.Labels) != 2 {
+		return ""
+	}
+	tfType := block.Labels[0]
+	name, _ := c.lookupName(tfType + "." + block.Labels[1])
+
+	res, ok := c.types.Resource(TerraformProviderName(tfType), tfType)
+	if !ok {
+		c.diags = append(c.diags, unsupported(block.DefRange(),
+			"TODO: no Pulumi resource corresponds to the Terraform resource type %v", tfType))
+		return ""
+	}
+
+	var options []string
+	for _, attr := range sortedAttributes(block.Body) {
+		switch attr.Name {
+		case "count", "for_each":
+			options = append(options, "range = "+c.expr(f, attr.Expr))
+		case "depends_on":
+			options = append(options, "dependsOn = "+c.expr(f, attr.Expr))
+		case "provider":
+			c.diags = append(c.diags, unsupported(attr.SrcRange, "TODO: explicit providers are not supported"))
+		}
+	}
+	for _, b := range block.Body.Blocks {
+		if b.Type == "lifecycle" {
+			options = append(options, c.convertLifecycle(f, b)...)
+		}
+	}
+
+	items := c.convertObject(f, block.Body, res.InputProperties, map[string]bool{
+		"count": true, "for_each": true, "depends_on": true, "provider": true, "lifecycle": true,
+	})
+	if len(options) != 0 {
+		items = append(items, "options "+bodyText(options))
+	}
+	return fmt.Sprintf("resource %s %s %s\n", name, strconv.Quote(res.Token), bodyText(items))
+}
+
+func (c *terraformConverter) convertLifecycle(f *terraformFile, block *hclsyntax.Block) []string {
+	var options []string
+	for _, attr := range sortedAttributes(block.Body) {
+		switch attr.Name {
+		case "prevent_destroy":
+			options = append(options, "protect = "+c.expr(f, attr.Expr))
+		case "ignore_changes":
+			tuple, ok := attr.Expr.(*hclsyntax.TupleConsExpr)
+			if !ok {
+				c.diags = append(c.diags, unsupported(attr.SrcRange, "TODO: ignore_changes must be a list of attributes"))
+				continue
+			}
+			var paths []string
+			for _, elem := range tuple.Exprs {
+				tr, diags := hcl.AbsTraversalForExpr(elem)
+				if diags.HasErrors() {
+					c.diags = append(c.diags, unsupported(elem.Range(), "TODO: ignore_changes must be a list of attributes"))
+					continue
+				}
+				paths = append(paths, camelCase(tr.RootName())+c.renderTraversal(tr[1:], true))
+			}
+			options = append(options, "ignoreChanges = ["+strings.Join(paths, ", ")+"]")
+		default:
+			c.diags = append(c.diags, unsupported(attr.NameRange, "TODO: the lifecycle setting %v is not supported",
+				attr.Name))
+		}
+	}
+	return options
+}
+
+func (c *terraformConverter) convertDataSource(f *terraformFile, block *hclsyntax.Block) string {
+	if len(block.Labels) != 2 {
+		return ""
+	}
+	tfType := block.Labels[0]
+	name, _ := c.lookupName("data." + tfType + "." + block.Labels[1])
+
+	fn, ok := c.types.DataSource(TerraformProviderName(tfType), tfType)
+	if !ok {
+		c.diags = append(c.diags, unsupported(block.DefRange(),
+			"TODO: no Pulumi function corresponds to the Terraform data source %v", tfType))
+		return ""
+	}
+
+	for _, attr := range sortedAttributes(block.Body) {
+		switch attr.Name {
+		case "count", "for_each", "depends_on", "provider":
+			c.diags = append(c.diags, unsupported(attr.NameRange, "TODO: %v is not supported for data sources", attr.Name))
+		}
+	}
+
+	var props []*schema.Property
+	if fn.Inputs != nil {
+		props = fn.Inputs.Properties
+	}
+	items := c.convertObject(f, block.Body, props, map[string]bool{
+		"count": true, "for_each": true, "depends_on": true, "provider": true,
+	})
+	return fmt.Sprintf("%s = invoke(%s, %s)\n", name, strconv.Quote(fn.Token), bodyText(items))
+}
+
+func (c *terraformConverter) convertOutput(f *terraformFile, block *hclsyntax.Block) string {
+	if len(block.Labels) != 1 {
+		return ""
+	}
+	name, _ := c.lookupName("output." + block.Labels[0])
+
+	value, ok := block.Body.Attributes["value"]
+	if !ok {
+		return ""
+	}
+	text := c.expr(f, value.Expr)
+	if sensitive, ok := block.Body.Attributes["sensitive"]; ok {
+		if v, diags := sensitive.Expr.Value(nil); !diags.HasErrors() && v.Type() == cty.Bool && v.True() {
+			text = "secret(" + text + ")"
+		}
+	}
+
+	// The outputs of a module are referred to by the module that uses it, rather than being stack outputs.
+	if c.inputs != nil {
+		return fmt.Sprintf("%s = %s\n", name, text)
+	}
+	return fmt.Sprintf("output %s %s\n", name, bodyText([]string{"value = " + text}))
+}
+
+// convertModule converts the declarations of a local module, setting its variables to the arguments of the module
+// block.
+func (c *terraformConverter) convertModule(f *terraformFile, block *hclsyntax.Block) string {
+	if len(block.Labels) != 1 {
+		return ""
+	}
+	m, ok := c.modules[block.Labels[0]]
+	if !ok {
+		return ""
+	}
+
+	for _, attr := range sortedAttributes(block.Body) {
+		switch attr.Name {
+		case "source", "version":
+		case "count", "for_each", "depends_on", "providers":
+			c.diags = append(c.diags, unsupported(attr.NameRange, "TODO: %v is not supported for modules", attr.Name))
+		default:
+			m.inputs[attr.Name] = c.expr(f, attr.Expr)
+		}
+	}
+
+	text := m.convert()
+	c.diags = append(c.diags, m.diags...)
+	return text
+}
+
+// convertObject converts the attributes and nested blocks of a Terraform body into the items of a PCL object. Nested
+// blocks become object values, or lists of object values if the corresponding property is a list or the block is
+// repeated.
+func (c *terraformConverter) convertObject(f *terraformFile, body *hclsyntax.Body, props []*schema.Property,
+	skip map[string]bool) []string {
+
+	var items []string
+	for _, attr := range sortedAttributes(body) {
+		if !skip[attr.Name] {
+			items = append(items, camelCase(attr.Name)+" = "+c.expr(f, attr.Expr))
+		}
+	}
+
+	var blockTypes []string
+	blocks := map[string][]*hclsyntax.Block{}
+	for _, b := range body.Blocks {
+		switch {
+		case skip[b.Type]:
+			continue
+		case b.Type == "dynamic":
+			c.diags = append(c.diags, unsupported(b.DefRange(), "TODO: dynamic blocks are not supported"))
+			continue
+		}
+		if _, has := blocks[b.Type]; !has {
+			blockTypes = append(blockTypes, b.Type)
+		}
+		blocks[b.Type] = append(blocks[b.Type], b)
+	}
+
+	for _, typ := range blockTypes {
+		name := camelCase(typ)
+		prop := findProperty(props, name)
+
+		var elementProps []*schema.Property
+		isList := len(blocks[typ]) > 1
+		if prop != nil {
+			t := unwrapType(prop.Type)
+			if array, ok := t.(*schema.ArrayType); ok {
+				isList, t = true, unwrapType(array.ElementType)
+			}
+			if obj, ok := t.(*schema.ObjectType); ok {
+				elementProps = obj.Properties
+			}
+		}
+
+		var values []string
+		for _, b := range blocks[typ] {
+			values = append(values, bodyText(c.convertObject(f, b.Body, elementProps, nil)))
+		}
+		if isList {
+			items = append(items, name+" = ["+strings.Join(values, ", ")+"]")
+		} else {
+			items = append(items, name+" = "+values[0])
+		}
+	}
+	return items
+}
+
+// expr converts a Terraform expression into PCL. References to variables, locals, resources, and data sources are
+// replaced with references to the corresponding PCL declarations, and functions are renamed to their PCL equivalents.
+func (c *terraformConverter) expr(f *terraformFile, x hclsyntax.Expression) string {
+	var edits []textEdit
+	for _, tr := range x.Variables() {
+		if text, ok := c.traversal(tr); ok {
+			rng := tr.SourceRange()
+			edits = append(edits, textEdit{start: rng.Start.Byte, end: rng.End.Byte, text: text})
+		}
+	}
+	diags := hclsyntax.VisitAll(x, func(n hclsyntax.Node) hcl.Diagnostics {
+		if call, ok := n.(*hclsyntax.FunctionCallExpr); ok {
+			name, ok := terraformFunctions[call.Name]
+			switch {
+			case !ok:
+				c.diags = append(c.diags, unsupported(call.NameRange,
+					"TODO: the Terraform function %v has no PCL equivalent", call.Name))
+			case name != call.Name:
+				edits = append(edits, textEdit{start: call.NameRange.Start.Byte, end: call.NameRange.End.Byte, text: name})
+			}
+		}
+		return nil
+	})
+	c.diags = append(c.diags, diags...)
+
+	rng := x.Range()
+	return applyEdits(f.src, rng.Start.Byte, rng.End.Byte, edits)
+}
+
+// traversal converts a reference to a Terraform declaration into a reference to the corresponding PCL declaration.
+func (c *terraformConverter) traversal(tr hcl.Traversal) (string, bool) {
+	attr := func(i int) string {
+		if i < len(tr) {
+			if a, ok := tr[i].(hcl.TraverseAttr); ok {
+				return a.Name
+			}
+		}
+		return ""
+	}
+
+	var key string
+	var rest hcl.Traversal
+	switch root := tr.RootName(); root {
+	case "var", "local":
+		key, rest = root+"."+attr(1), tr[min(2, len(tr)):]
+		if name, ok := c.lookupName(key); ok {
+			return name + c.renderTraversal(rest, false), true
+		}
+	case "count":
+		if attr(1) == "index" {
+			return "range.value" + c.renderTraversal(tr[2:], false), true
+		}
+	case "each":
+		switch attr(1) {
+		case "key":
+			return "range.key" + c.renderTraversal(tr[2:], false), true
+		case "value":
+			return "range.value" + c.renderTraversal(tr[2:], false), true
+		}
+	case "data":
+		key, rest = "data."+attr(1)+"."+attr(2), tr[min(3, len(tr)):]
+		if name, ok := c.lookupName(key); ok {
+			return name + c.renderTraversal(rest, true), true
+		}
+	case "module":
+		key, rest = "module."+attr(1)+".output."+attr(2), tr[min(3, len(tr)):]
+		if name, ok := c.lookupName(key); ok {
+			return name + c.renderTraversal(rest, false), true
+		}
+	case "path", "terraform", "self":
+		// These are not supported; fall through to the diagnostic below.
+	default:
+		key, rest = root+"."+attr(1), tr[min(2, len(tr)):]
+		if name, ok := c.lookupName(key); ok {
+			return name + c.renderTraversal(rest, true), true
+		}
+	}
+
+	c.diags = append(c.diags, unsupported(tr.SourceRange(), "TODO: the reference %v cannot be converted",
+		strings.TrimSpace(c.renderTraversal(tr, false))))
+	return "", false
+}
+
+// renderTraversal renders the steps of a traversal, optionally converting attribute names to camelCase.
+func (c *terraformConverter) renderTraversal(tr hcl.Traversal, camel bool) string {
+	var b strings.Builder
+	for _, step := range tr {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			b.WriteString(step.Name)
+		case hcl.TraverseAttr:
+			name := step.Name
+			if camel {
+				name = camelCase(name)
+			}
+			b.WriteString("." + name)
+		case hcl.TraverseIndex:
+			b.WriteString("[" + literal(step.Key) + "]")
+		}
+	}
+	return b.String()
+}
+
+// literal renders a primitive value as a PCL literal.
+func literal(v cty.Value) string {
+	switch {
+	case !v.IsKnown() || v.IsNull():
+		return "null"
+	case v.Type() == cty.String:
+		return strconv.Quote(v.AsString())
+	case v.Type() == cty.Number:
+		return v.AsBigFloat().Text('f', -1)
+	case v.Type() == cty.Bool:
+		return strconv.FormatBool(v.True())
+	default:
+		return fmt.Sprintf("%#v", v)
+	}
+}
+
+func findProperty(props []*schema.Property, name string) *schema.Property {
+	for _, p := range props {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func unwrapType(t schema.Type) schema.Type {
+	for {
+		switch u := t.(type) {
+		case *schema.InputType:
+			t = u.ElementType
+		case *schema.OptionalType:
+			t = u.ElementType
+		default:
+			return t
+		}
+	}
+}
+
+// sortedAttributes returns the attributes of a body in source order.
+func sortedAttributes(body *hclsyntax.Body) []*hclsyntax.Attribute {
+	attrs := make([]*hclsyntax.Attribute, 0, len(body.Attributes))
+	for _, attr := range body.Attributes {
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].SrcRange.Start.Byte < attrs[j].SrcRange.Start.Byte })
+	return attrs
+}
+
+// bodyText renders the items of a PCL block body or object.
+func bodyText(items []string) string {
+	if len(items) == 0 {
+		return "{}"
+	}
+	return "{\n    " + indent(strings.Join(items, "\n")) + "\n}"
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,231 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package convert
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/codegen/schema"
+)
+
+type testLoader map[string]*schema.Package
+
+func (l testLoader) LoadPackage(pkg string, version *semver.Version) (*schema.Package, error) {
+	if p, ok := l[pkg]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown package %v", pkg)
+}
+
+func TestTerraformTypes(t *testing.T) {
+	t.Parallel()
+
+	bucket := &schema.Resource{Token: "aws:s3/bucket:Bucket"}
+	instance := &schema.Resource{Token: "aws:ec2/instance:Instance"}
+	vpc := &schema.Resource{Token: "aws:ec2/vpc:Vpc"}
+	getAmi := &schema.Function{Token: "aws:ec2/getAmi:getAmi"}
+	group := &schema.Resource{Token: "azure:core/resourceGroup:ResourceGroup"}
+
+	types := NewTerraformTypes(testLoader{
+		"aws": {
+			Resources: []*schema.Resource{bucket, instance, vpc},
+			Functions: []*schema.Function{getAmi},
+		},
+		"azure": {Resources: []*schema.Resource{group}},
+	})
+
+	r, ok := types.Resource("aws", "aws_s3_bucket")
+	assert.True(t, ok)
+	assert.Equal(t, bucket, r)
+	r, ok = types.Resource("aws", "aws_instance")
+	assert.True(t, ok)
+	assert.Equal(t, instance, r)
+	r, ok = types.Resource("aws", "aws_vpc")
+	assert.True(t, ok)
+	assert.Equal(t, vpc, r)
+	r, ok = types.Resource("azurerm", "azurerm_resource_group")
+	assert.True(t, ok)
+	assert.Equal(t, group, r)
+
+	f, ok := types.DataSource("aws", "aws_ami")
+	assert.True(t, ok)
+	assert.Equal(t, getAmi, f)
+
+	_, ok = types.Resource("aws", "aws_lambda_function")
+	assert.False(t, ok)
+	_, ok = types.Resource("google", "google_storage_bucket")
+	assert.False(t, ok)
+}
+
+func TestTerraformToPCL(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	source := `variable "prefix" {
+  type        = string
+  default     = "site"
+  description = "The bucket prefix"
+}
+
+locals {
+  tags = { owner = var.prefix }
+}
+
+data "aws_ami" "ubuntu" {
+  most_recent = true
+}
+
+resource "aws_s3_bucket" "site" {
+  count         = 2
+  bucket_prefix = "${var.prefix}-${count.index}"
+  tags          = local.tags
+
+  website {
+    index_document = "index.html"
+  }
+
+  lifecycle {
+    prevent_destroy = true
+    ignore_changes  = [tags]
+  }
+}
+
+resource "aws_instance" "web" {
+  ami = data.aws_ami.ubuntu.image_id
+}
+
+output "bucket_name" {
+  value     = aws_s3_bucket.site[0].bucket_domain_name
+  sensitive = true
+}
+
+provider "aws" {
+  region = "us-west-2"
+}
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(source), 0600))
+
+	types := NewTerraformTypes(testLoader{
+		"aws": {
+			Resources: []*schema.Resource{
+				{Token: "aws:s3/bucket:Bucket"},
+				{Token: "aws:ec2/instance:Instance"},
+			},
+			Functions: []*schema.Function{{Token: "aws:ec2/getAmi:getAmi"}},
+		},
+	})
+	program, diags, err := TerraformToPCL(dir, types)
+	assert.NoError(t, err)
+
+	text := string(program)
+	assert.Contains(t, text, "config prefix \"string\" {\n"+
+		"    default = \"site\"\n"+
+		"    description = \"The bucket prefix\"\n"+
+		"}\n")
+	assert.Contains(t, text, "tags = { owner = prefix }\n")
+	assert.Contains(t, text, "ubuntu = invoke(\"aws:ec2/getAmi:getAmi\", {\n    mostRecent = true\n})\n")
+	assert.Contains(t, text, "resource site \"aws:s3/bucket:Bucket\" {\n"+
+		"    bucketPrefix = \"${prefix}-${range.value}\"\n"+
+		"    tags = tags\n"+
+		"    website = {\n"+
+		"        indexDocument = \"index.html\"\n"+
+		"    }\n"+
+		"    options {\n"+
+		"        range = 2\n"+
+		"        protect = true\n"+
+		"        ignoreChanges = [tags]\n"+
+		"    }\n"+
+		"}\n")
+	assert.Contains(t, text, "resource web \"aws:ec2/instance:Instance\" {\n    ami = ubuntu.imageId\n}\n")
+	assert.Contains(t, text, "output bucket_name {\n    value = secret(site[0].bucketDomainName)\n}\n")
+
+	if assert.Len(t, diags, 1) {
+		assert.Contains(t, diags[0].Summary, "provider configuration is not converted")
+	}
+}
+
+func TestTerraformModulesToPCL(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	main := `variable "env" {
+  default = "dev"
+}
+
+module "site" {
+  source = "./modules/site"
+  name   = "${var.env}-site"
+}
+
+module "vpc" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+
+output "bucket" {
+  value = module.site.bucket_name
+}
+`
+	site := `variable "name" {}
+
+variable "index" {
+  default = "index.html"
+}
+
+resource "aws_s3_bucket" "bucket" {
+  bucket = var.name
+
+  website {
+    index_document = var.index
+  }
+}
+
+output "bucket_name" {
+  value = aws_s3_bucket.bucket.bucket_domain_name
+}
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(main), 0600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "modules", "site"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "modules", "site", "main.tf"), []byte(site), 0600))
+
+	types := NewTerraformTypes(testLoader{
+		"aws": {Resources: []*schema.Resource{{Token: "aws:s3/bucket:Bucket"}}},
+	})
+	program, diags, err := TerraformToPCL(dir, types)
+	assert.NoError(t, err)
+
+	text := string(program)
+	assert.Contains(t, text, "site_name = \"${env}-site\"\n")
+	assert.Contains(t, text, "site_index = \"index.html\"\n")
+	assert.Contains(t, text, "resource site_bucket \"aws:s3/bucket:Bucket\" {\n"+
+		"    bucket = site_name\n"+
+		"    website = {\n"+
+		"        indexDocument = site_index\n"+
+		"    }\n"+
+		"}\n")
+	assert.Contains(t, text, "site_bucket_name = site_bucket.bucketDomainName\n")
+	assert.Contains(t, text, "output bucket {\n    value = site_bucket_name\n}\n")
+
+	// Modules from the registry can't be converted.
+	if assert.Len(t, diags, 1) {
+		assert.Contains(t, diags[0].Summary, "TODO: the module vpc is not converted")
+	}
+}
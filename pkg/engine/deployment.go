@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
@@ -25,6 +26,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
@@ -152,6 +154,10 @@ func newDeployment(ctx *Context, info *deploymentContext, opts deploymentOptions
 	if err != nil {
 		return nil, err
 	}
+	if err := pinProviderVersions(plugctx, opts.ProviderVersions); err != nil {
+		contract.IgnoreClose(plugctx)
+		return nil, err
+	}
 
 	opts.trustDependencies = proj.TrustResourceDependencies()
 	// Now create the state source.  This may issue an error if it can't create the source.  This entails,
@@ -244,22 +250,7 @@ func (deployment *deployment) run(cancelCtx *Context, actions runActions, policy
 	done := make(chan bool)
 	var walkResult result.Result
 	go func() {
-		opts := deploy.Options{
-			Events:                    actions,
-			Parallel:                  deployment.Options.Parallel,
-			Refresh:                   deployment.Options.Refresh,
-			RefreshOnly:               deployment.Options.isRefresh,
-			RefreshTargets:            deployment.Options.RefreshTargets,
-			ReplaceTargets:            deployment.Options.ReplaceTargets,
-			DestroyTargets:            deployment.Options.DestroyTargets,
-			UpdateTargets:             deployment.Options.UpdateTargets,
-			TargetDependents:          deployment.Options.TargetDependents,
-			TrustDependencies:         deployment.Options.trustDependencies,
-			UseLegacyDiff:             deployment.Options.UseLegacyDiff,
-			DisableResourceReferences: deployment.Options.DisableResourceReferences,
-			DisableOutputValues:       deployment.Options.DisableOutputValues,
-		}
-		walkResult = deployment.Deployment.Execute(ctx, opts, preview)
+		walkResult = deployment.Deployment.Execute(ctx, deployment.deployOptions(actions), preview)
 		close(done)
 	}()
 
@@ -288,11 +279,66 @@ func (deployment *deployment) run(cancelCtx *Context, actions runActions, policy
 	changes := actions.Changes()
 
 	// Emit a summary event.
-	deployment.Options.Events.summaryEvent(preview, actions.MaybeCorrupt(), duration, changes, policyPacks)
+	deployment.Options.Events.summaryEvent(preview, actions.MaybeCorrupt(), duration, changes, policyPacks,
+		deployment.Deployment.ProviderConfigureTimes())
 
 	return changes, res
 }
 
+// silentPreview previews the update without displaying anything, reporting its steps to the given actions instead. It
+// is used to find the steps of an update before the update is performed. If the preview succeeds, the caller must
+// close the returned deployment, whose providers remain loaded until then.
+func silentPreview(ctx *Context, info *deploymentContext, opts deploymentOptions,
+	actions deploy.Events) (*deployment, result.Result) {
+
+	quiet := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	opts.Diag, opts.StatusDiag = quiet, quiet
+
+	deployment, err := newDeployment(ctx, info, opts, true /*preview*/)
+	if err != nil {
+		return nil, result.FromError(err)
+	}
+
+	execCtx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+	go func() {
+		select {
+		case <-ctx.Cancel.Canceled():
+			cancelFunc()
+		case <-execCtx.Done():
+		}
+	}()
+
+	if res := deployment.Deployment.Execute(execCtx, deployment.deployOptions(actions), true); res != nil {
+		contract.IgnoreClose(deployment)
+		return nil, res
+	}
+	return deployment, nil
+}
+
+// deployOptions returns the options with which the deployment's steps are generated and executed, reporting step
+// events to the given actions.
+func (deployment *deployment) deployOptions(actions deploy.Events) deploy.Options {
+	return deploy.Options{
+		Events:                    actions,
+		Parallel:                  deployment.Options.Parallel,
+		Refresh:                   deployment.Options.Refresh,
+		RefreshOnly:               deployment.Options.isRefresh,
+		RefreshTargets:            deployment.Options.RefreshTargets,
+		ReplaceTargets:            deployment.Options.ReplaceTargets,
+		DestroyTargets:            deployment.Options.DestroyTargets,
+		UpdateTargets:             deployment.Options.UpdateTargets,
+		ExcludeTargets:            deployment.Options.ExcludeTargets,
+		RefreshIgnoreChanges:      deployment.Options.RefreshIgnoreChanges,
+		TargetDependents:          deployment.Options.TargetDependents,
+		TrustDependencies:         deployment.Options.trustDependencies,
+		UseLegacyDiff:             deployment.Options.UseLegacyDiff,
+		DisableResourceReferences: deployment.Options.DisableResourceReferences,
+		DisableOutputValues:       deployment.Options.DisableOutputValues,
+		CleanupDefaultProviders:   deployment.Options.CleanupDefaultProviders,
+	}
+}
+
 func (deployment *deployment) Close() error {
 	return deployment.Plugctx.Close()
 }
@@ -75,6 +75,12 @@ func newDestroySource(
 		return nil, err
 	}
 
+	// Like Update, load the required and local policy packs, so that a destroy runs the same guardrails and fails
+	// before it starts if any of them cannot be loaded or configured.
+	if err := loadPolicyPlugins(plugctx, opts, proj, target, dryRun); err != nil {
+		return nil, err
+	}
+
 	// Create a nil source.  This simply returns "nothing" as the new state, which will cause the
 	// engine to destroy the entire existing state.
 	return deploy.NullSource, nil
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
@@ -132,6 +133,23 @@ type SummaryEventPayload struct {
 	Duration        time.Duration     // the duration of the entire update operation (zero values for previews)
 	ResourceChanges ResourceChanges   // count of changed resources, useful for reporting
 	PolicyPacks     map[string]string // {policy-pack: version} for each policy pack applied
+	CostEstimates   *CostEstimates    // the estimated costs of the changed resources, if a cost estimator ran
+
+	// the time taken to configure each provider, in the order in which their configuration finished.
+	ProviderConfigureTimes []providers.ConfigureTime
+}
+
+// CostEstimates are the estimated monthly costs of the resources changed by an operation.
+type CostEstimates struct {
+	Currency  string                 // the currency of the estimates, e.g. USD.
+	Resources []ResourceCostEstimate // the estimates of each resource that the estimator could price.
+}
+
+// ResourceCostEstimate is the estimated monthly cost of a single resource.
+type ResourceCostEstimate struct {
+	URN              resource.URN // the resource's URN.
+	MonthlyCost      float64      // the estimated monthly cost of the resource after the operation.
+	MonthlyCostDelta float64      // the change in the resource's estimated monthly cost caused by the operation.
 }
 
 type ResourceOperationFailedPayload struct {
@@ -165,6 +183,7 @@ type StepEventMetadata struct {
 	DetailedDiff map[string]plugin.PropertyDiff // the rich, structured diff
 	Logical      bool                           // true if this step represents a logical operation in the program.
 	Provider     string                         // the provider that performed this step.
+	Duration     time.Duration                  // the wall-clock time the step took; set once an update's step ends.
 }
 
 // StepEventStateMetadata contains detailed metadata about a resource's state pertaining to a given step.
@@ -467,22 +486,28 @@ func (e *eventEmitter) Close() {
 }
 
 func (e *eventEmitter) resourceOperationFailedEvent(
-	step deploy.Step, status resource.Status, steps int, debug bool) {
+	step deploy.Step, status resource.Status, steps int, debug bool, duration time.Duration) {
 
 	contract.Requiref(e != nil, "e", "!= nil")
 
+	metadata := makeStepEventMetadata(step.Op(), step, debug)
+	metadata.Duration = duration
 	e.ch <- NewEvent(ResourceOperationFailed, ResourceOperationFailedPayload{
-		Metadata: makeStepEventMetadata(step.Op(), step, debug),
+		Metadata: metadata,
 		Status:   status,
 		Steps:    steps,
 	})
 }
 
-func (e *eventEmitter) resourceOutputsEvent(op deploy.StepOp, step deploy.Step, planning bool, debug bool) {
+func (e *eventEmitter) resourceOutputsEvent(op deploy.StepOp, step deploy.Step, planning bool, debug bool,
+	duration time.Duration) {
+
 	contract.Requiref(e != nil, "e", "!= nil")
 
+	metadata := makeStepEventMetadata(op, step, debug)
+	metadata.Duration = duration
 	e.ch <- NewEvent(ResourceOutputsEvent, ResourceOutputsEventPayload{
-		Metadata: makeStepEventMetadata(op, step, debug),
+		Metadata: metadata,
 		Planning: planning,
 		Debug:    debug,
 	})
@@ -518,16 +543,17 @@ func (e *eventEmitter) preludeEvent(isPreview bool, cfg config.Map) {
 }
 
 func (e *eventEmitter) summaryEvent(preview, maybeCorrupt bool, duration time.Duration, resourceChanges ResourceChanges,
-	policyPacks map[string]string) {
+	policyPacks map[string]string, providerConfigureTimes []providers.ConfigureTime) {
 
 	contract.Requiref(e != nil, "e", "!= nil")
 
 	e.ch <- NewEvent(SummaryEvent, SummaryEventPayload{
-		IsPreview:       preview,
-		MaybeCorrupt:    maybeCorrupt,
-		Duration:        duration,
-		ResourceChanges: resourceChanges,
-		PolicyPacks:     policyPacks,
+		IsPreview:              preview,
+		MaybeCorrupt:           maybeCorrupt,
+		Duration:               duration,
+		ResourceChanges:        resourceChanges,
+		PolicyPacks:            policyPacks,
+		ProviderConfigureTimes: providerConfigureTimes,
 	})
 }
 
@@ -66,6 +66,9 @@ func (entries JournalEntries) Snap(base *deploy.Snapshot) *deploy.Snapshot {
 		if e.Kind == JournalEntrySuccess {
 			switch e.Step.Op() {
 			case deploy.OpSame, deploy.OpUpdate:
+				if same, ok := e.Step.(*deploy.SameStep); ok && same.IsExpired() {
+					break
+				}
 				resources = append(resources, e.Step.New())
 				dones[e.Step.Old()] = true
 			case deploy.OpCreate, deploy.OpCreateReplacement:
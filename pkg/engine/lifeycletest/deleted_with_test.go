@@ -0,0 +1,116 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycletest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestDeletedWith(t *testing.T) {
+	var lock sync.Mutex
+	var deleted []resource.URN
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DeleteF: func(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+					timeout float64) (resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					deleted = append(deleted, urn)
+					return resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+
+		resB, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+			DeletedWith: resA,
+		})
+		assert.NoError(t, err)
+
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, deploytest.ResourceOptions{
+			DeletedWith: resB,
+		})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host},
+	}
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	resB := p.NewURN("pkgA:m:typA", "resB", "")
+	resC := p.NewURN("pkgA:m:typA", "resC", "")
+
+	// The DeletedWith option is recorded in the snapshot.
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+	require.Len(t, snap.Resources, 4)
+	assert.Equal(t, resource.URN(""), snap.Resources[1].DeletedWith)
+	assert.Equal(t, resA, snap.Resources[2].DeletedWith)
+	assert.Equal(t, resB, snap.Resources[3].DeletedWith)
+
+	validateDeletes := func(expected ...resource.URN) ValidateFunc {
+		return func(project workspace.Project, target deploy.Target, entries JournalEntries,
+			events []Event, res result.Result) result.Result {
+
+			// Every resource is removed from the snapshot, but only the containing resource is deleted by its provider.
+			for _, entry := range entries {
+				assert.Equal(t, deploy.OpDelete, entry.Step.Op())
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			assert.ElementsMatch(t, expected, deleted)
+			deleted = nil
+			return res
+		}
+	}
+
+	// Destroying the stack only deletes resA.
+	p.Steps = []TestStep{{Op: Destroy, SkipPreview: true, Validate: validateDeletes(resA)}}
+	p.Run(t, CloneSnapshot(t, snap))
+
+	// Targeting resA also removes the resources that are deleted with it, even without --target-dependents.
+	p.Options.DestroyTargets = []resource.URN{resA}
+	p.Steps = []TestStep{{Op: Destroy, SkipPreview: true, Validate: validateDeletes(resA)}}
+	p.Run(t, CloneSnapshot(t, snap))
+
+	// Targeting resB alone deletes resB through its provider, as resA is not being deleted.
+	p.Options.DestroyTargets = []resource.URN{resB}
+	p.Steps = []TestStep{{Op: Destroy, SkipPreview: true, Validate: validateDeletes(resB)}}
+	after := p.Run(t, CloneSnapshot(t, snap))
+	for _, res := range after.Resources {
+		assert.NotEqual(t, resC, res.URN)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycletest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestDeprecatedResource(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	registerDependent := false
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+
+		if registerDependent {
+			// This registration only fails during an update; a preview reports the error once the program exits.
+			_, _, _, _ = monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+				Dependencies: []resource.URN{resA},
+			})
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host},
+	}
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	newSnapshot := func(removeAfter time.Time) *deploy.Snapshot {
+		res := newResource(resURN, "", "1", string(provURN)+"::0", nil, nil, nil, true)
+		res.RemoveAfter = &removeAfter
+		return &deploy.Snapshot{
+			Resources: []*resource.State{
+				newResource(provURN, "", "0", "", nil, nil, nil, true),
+				res,
+			},
+		}
+	}
+
+	deprecationWarnings := func(events []Event) []string {
+		var warnings []string
+		for _, e := range events {
+			if e.Type == DiagEvent {
+				payload := e.Payload().(DiagEventPayload)
+				if payload.URN == resURN && payload.Severity == diag.Warning &&
+					strings.Contains(payload.Message, "deprecated") {
+					warnings = append(warnings, payload.Message)
+				}
+			}
+		}
+		return warnings
+	}
+
+	// Before its removal date, a deprecated resource is kept and a warning is issued.
+	future := time.Now().Add(24 * time.Hour).UTC()
+	p.Steps = []TestStep{{
+		Op: Update,
+		Validate: func(project workspace.Project, target deploy.Target, entries JournalEntries,
+			events []Event, res result.Result) result.Result {
+
+			assert.Len(t, deprecationWarnings(events), 1)
+			for _, entry := range entries {
+				assert.NotEqual(t, deploy.OpDelete, entry.Step.Op())
+			}
+
+			snap := entries.Snap(target.Snapshot)
+			require.Len(t, snap.Resources, 2)
+			if assert.NotNil(t, snap.Resources[1].RemoveAfter) {
+				assert.True(t, future.Equal(*snap.Resources[1].RemoveAfter))
+			}
+			return res
+		},
+	}}
+	p.Run(t, newSnapshot(future))
+
+	// After its removal date, a deprecated resource is deleted even though the program still registers it.
+	past := time.Now().Add(-24 * time.Hour).UTC()
+	p.Steps = []TestStep{{
+		Op: Update,
+		Validate: func(project workspace.Project, target deploy.Target, entries JournalEntries,
+			events []Event, res result.Result) result.Result {
+
+			assert.Len(t, deprecationWarnings(events), 1)
+			deleted := false
+			for _, entry := range entries {
+				if entry.Step.Op() == deploy.OpDelete && entry.Kind == JournalEntrySuccess {
+					assert.Equal(t, resURN, entry.Step.URN())
+					deleted = true
+				}
+			}
+			assert.True(t, deleted)
+
+			snap := entries.Snap(target.Snapshot)
+			for _, r := range snap.Resources {
+				assert.NotEqual(t, resURN, r.URN)
+			}
+			return res
+		},
+	}}
+	p.Run(t, newSnapshot(past))
+
+	// A resource that depends on an expired deprecated resource fails to register.
+	registerDependent = true
+	p.Steps = []TestStep{{Op: Update, ExpectFailure: true}}
+	p.Run(t, newSnapshot(past))
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
@@ -558,3 +559,56 @@ func TestImportIgnoreChanges(t *testing.T) {
 	assert.Len(t, snap.Resources, 2)
 	assert.Equal(t, resource.NewStringProperty("bar"), snap.Resources[1].Outputs["foo"])
 }
+
+func TestImportParentsFirst(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				GetSchemaF: func(version int) ([]byte, error) {
+					return []byte(importSchema), nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					return plugin.ReadResult{
+						Inputs: resource.PropertyMap{
+							"foo": resource.NewStringProperty("bar"),
+						},
+						Outputs: resource.PropertyMap{
+							"foo": resource.NewStringProperty("bar"),
+						},
+					}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+	host := deploytest.NewPluginHost(nil, nil, nil, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host, Parallel: 4},
+	}
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	resB := p.NewURN("pkgA:m:typA", "resB", resA)
+
+	// Import a chain of resources whose children are listed before their parents. Each parent must be imported, and
+	// appear in the snapshot, before its children.
+	project := p.GetProject()
+	snap, res := ImportOp([]deploy.Import{
+		{Type: "pkgA:m:typA", Name: "resC", ID: "id-c", Parent: resB},
+		{Type: "pkgA:m:typA", Name: "resB", ID: "id-b", Parent: resA},
+		{Type: "pkgA:m:typA", Name: "resA", ID: "id-a"},
+	}).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Nil(t, res)
+
+	var names []tokens.QName
+	var parents []resource.URN
+	for _, r := range snap.Resources {
+		if r.Type == "pkgA:m:typA" {
+			names = append(names, r.URN.Name())
+			parents = append(parents, r.Parent)
+		}
+	}
+	assert.Equal(t, []tokens.QName{"resA", "resB", "resC"}, names)
+	assert.Equal(t, []resource.URN{resA, resB}, parents[1:])
+	assert.NoError(t, snap.VerifyIntegrity())
+}
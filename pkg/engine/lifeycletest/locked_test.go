@@ -0,0 +1,128 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycletest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestLockedResource(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DiffF: func(urn resource.URN, id resource.ID, olds, news resource.PropertyMap,
+					ignoreChanges []string) (plugin.DiffResult, error) {
+
+					if !olds["foo"].DeepEquals(news["foo"]) {
+						return plugin.DiffResult{Changes: plugin.DiffSome}, nil
+					}
+					return plugin.DiffResult{Changes: plugin.DiffNone}, nil
+				},
+			}, nil
+		}),
+	}
+
+	inputs := resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		// This registration fails during an update when the resource is locked and would change.
+		_, _, _, _ = monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Inputs: inputs,
+		})
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host},
+	}
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	newSnapshot := func() *deploy.Snapshot {
+		res := newResource(resURN, "", "1", string(provURN)+"::0", nil, nil, nil, true)
+		res.Inputs = resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+		res.Locked = true
+		return &deploy.Snapshot{
+			Resources: []*resource.State{
+				newResource(provURN, "", "0", "", nil, nil, nil, true),
+				res,
+			},
+		}
+	}
+
+	lockErrors := func(events []Event) int {
+		count := 0
+		for _, e := range events {
+			if e.Type == DiagEvent {
+				payload := e.Payload().(DiagEventPayload)
+				if payload.URN == resURN && payload.Severity == diag.Error &&
+					strings.Contains(payload.Message, "locked") {
+					count++
+				}
+			}
+		}
+		return count
+	}
+
+	// A locked resource that doesn't change stays locked.
+	p.Steps = []TestStep{{
+		Op: Update,
+		Validate: func(project workspace.Project, target deploy.Target, entries JournalEntries,
+			events []Event, res result.Result) result.Result {
+
+			assert.Zero(t, lockErrors(events))
+			snap := entries.Snap(target.Snapshot)
+			require.Len(t, snap.Resources, 2)
+			assert.True(t, snap.Resources[1].Locked)
+			return res
+		},
+	}}
+	p.Run(t, newSnapshot())
+
+	// Updating a locked resource is refused, and the lock that blocked it is reported.
+	inputs = resource.PropertyMap{"foo": resource.NewStringProperty("baz")}
+	p.Steps = []TestStep{{
+		Op:            Update,
+		ExpectFailure: true,
+		Validate: func(project workspace.Project, target deploy.Target, entries JournalEntries,
+			events []Event, res result.Result) result.Result {
+
+			assert.Equal(t, 1, lockErrors(events))
+			for _, entry := range entries {
+				assert.NotEqual(t, deploy.OpUpdate, entry.Step.Op())
+			}
+			return res
+		},
+	}}
+	p.Run(t, newSnapshot())
+
+	// So is deleting it.
+	p.Steps = []TestStep{{Op: Destroy, ExpectFailure: true}}
+	p.Run(t, newSnapshot())
+}
@@ -0,0 +1,114 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycletest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	. "github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/pkg/v3/util/cancel"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+)
+
+// runPlan computes a plan for the given target, draining the plan's events.
+func runPlan(t *testing.T, p *TestPlan, target deploy.Target) *UpdatePlan {
+	cancelCtx, _ := cancel.NewContext(context.Background())
+	events := make(chan Event)
+	done := make(chan bool)
+	go func() {
+		for range events {
+		}
+		close(done)
+	}()
+
+	info := &updateInfo{project: p.GetProject(), target: target}
+	plan, res := Plan(info, &Context{Cancel: cancelCtx, Events: events}, p.Options)
+	close(events)
+	<-done
+
+	assert.Nil(t, res)
+	return plan
+}
+
+func TestPlanComputesStepsWithoutExecuting(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	inputs := resource.PropertyMap{"foo": resource.NewStringProperty("bar")}
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true, deploytest.ResourceOptions{
+			Inputs: inputs,
+		})
+		assert.NoError(t, err)
+
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+			Dependencies: []resource.URN{resA},
+		})
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host},
+	}
+	urnA := p.NewURN("pkgA:m:typA", "resA", "")
+	urnB := p.NewURN("pkgA:m:typA", "resB", "")
+
+	// Plan an update of an empty stack. Both resources should be created, and resB should depend on resA.
+	plan := runPlan(t, p, p.GetTarget(nil))
+	stepsByURN := make(map[resource.URN]PlannedStep)
+	for _, step := range plan.Steps {
+		stepsByURN[step.URN] = step
+	}
+	if assert.Contains(t, stepsByURN, urnA) {
+		assert.Equal(t, deploy.OpCreate, stepsByURN[urnA].Op)
+		assert.Empty(t, stepsByURN[urnA].Dependencies)
+	}
+	if assert.Contains(t, stepsByURN, urnB) {
+		assert.Equal(t, deploy.OpCreate, stepsByURN[urnB].Op)
+		assert.Equal(t, []resource.URN{urnA}, stepsByURN[urnB].Dependencies)
+	}
+	assert.Equal(t, 2, plan.Changes[deploy.OpCreate])
+
+	// Planning must not execute anything, so an update of the same empty stack still creates both resources.
+	snap, res := TestOp(Update).Run(p.GetProject(), p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Nil(t, res)
+	assert.Len(t, snap.Resources, 3)
+
+	// Change resA's inputs and plan again. Only resA should be updated, and its diff should name the changed key.
+	inputs = resource.PropertyMap{"foo": resource.NewStringProperty("baz")}
+	plan = runPlan(t, p, p.GetTarget(CloneSnapshot(t, snap)))
+	var updates []PlannedStep
+	for _, step := range plan.Steps {
+		if step.Op == deploy.OpUpdate {
+			updates = append(updates, step)
+		}
+	}
+	if assert.Len(t, updates, 1) {
+		assert.Equal(t, urnA, updates[0].URN)
+		assert.Equal(t, []resource.PropertyKey{"foo"}, updates[0].Diffs)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycletest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+func TestPreflightChecks(t *testing.T) {
+	var checked []string
+	denyCreates, supported, broken := false, true, false
+	creates := 0
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap, timeout float64,
+					preview bool) (resource.ID, resource.PropertyMap, resource.Status, error) {
+					if !preview {
+						creates++
+					}
+					return "created-id", news, resource.StatusOK, nil
+				},
+				InvokeF: func(tok tokens.ModuleMember,
+					args resource.PropertyMap) (resource.PropertyMap, []plugin.CheckFailure, error) {
+
+					if tok != CheckPermissionsFunction || !supported {
+						return nil, nil, fmt.Errorf("unknown function %v", tok)
+					}
+					if broken {
+						return nil, nil, errors.New("credentials expired")
+					}
+					var denied []resource.PropertyValue
+					for _, op := range args["operations"].ArrayValue() {
+						obj := op.ObjectValue()
+						checked = append(checked, obj["operation"].StringValue()+" "+obj["type"].StringValue())
+						if denyCreates && obj["operation"].StringValue() == "create" {
+							denied = append(denied, resource.NewObjectProperty(resource.PropertyMap{
+								"type":      obj["type"],
+								"operation": obj["operation"],
+								"reason":    resource.NewStringProperty("AccessDenied"),
+							}))
+						}
+					}
+					return resource.PropertyMap{"denied": resource.NewArrayProperty(denied)}, nil, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typB", "resB", true)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host, Preflight: true},
+	}
+	project := p.GetProject()
+
+	// A denied operation fails the preview, so the update is never started.
+	denyCreates = true
+	_, res := TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	require.NotNil(t, res)
+	assert.True(t, strings.Contains(res.Error().Error(), "create of pkgA:m:typA: AccessDenied"))
+	assert.Equal(t, []string{"create pkgA:m:typA", "create pkgA:m:typB"}, checked)
+	assert.Equal(t, 0, creates)
+
+	// When every operation is permitted, the preview succeeds.
+	denyCreates, checked = false, nil
+	_, res = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	assert.Nil(t, res)
+	assert.Len(t, checked, 2)
+
+	// The checks are only made by the preview, not by the update that follows it.
+	checked = nil
+	_, res = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, false, p.BackendClient, nil)
+	assert.Nil(t, res)
+	assert.Empty(t, checked)
+	assert.Equal(t, 2, creates)
+
+	// An error from a provider that implements the check fails the preview.
+	broken = true
+	_, res = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	require.NotNil(t, res)
+	assert.True(t, strings.Contains(res.Error().Error(), "credentials expired"))
+
+	// Providers that don't implement the check don't block the preview.
+	supported = false
+	_, res = TestOp(Update).Run(project, p.GetTarget(nil), p.Options, true, p.BackendClient, nil)
+	assert.Nil(t, res)
+}
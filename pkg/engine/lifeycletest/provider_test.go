@@ -1118,3 +1118,85 @@ func TestProviderVersionInputAndOption(t *testing.T) {
 
 	assert.Equal(t, "1.0.0", version)
 }
+
+func TestStaleDefaultProviderCleanup(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{}
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	staleURN := p.NewProviderURN("pkgA", "default_0_9_0", "")
+	resURN := p.NewURN("pkgA:m:typA", "resA", "")
+
+	// The old snapshot holds a default provider that nothing refers to any longer. A targeted update will not
+	// delete it on its own, as it was not named as a target.
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			newResource(provURN, "", "0", "", nil, nil, nil, true),
+			newResource(staleURN, "", "1", "", nil, nil, nil, true),
+			newResource(resURN, "", "2", string(provURN)+"::0", nil, nil, nil, true),
+		},
+	}
+
+	run := func(old *deploy.Snapshot, cleanup, expectDelete bool) {
+		p.Options = UpdateOptions{
+			Host:                    host,
+			UpdateTargets:           []resource.URN{resURN},
+			CleanupDefaultProviders: cleanup,
+		}
+		p.Steps = []TestStep{{
+			Op: Update,
+			Validate: func(project workspace.Project, target deploy.Target, entries JournalEntries,
+				_ []Event, res result.Result) result.Result {
+
+				deleted := false
+				for _, entry := range entries {
+					if entry.Step.Op() == deploy.OpDelete {
+						assert.Equal(t, staleURN, entry.Step.URN())
+						deleted = true
+					}
+				}
+				assert.Equal(t, expectDelete, deleted)
+
+				snap := entries.Snap(target.Snapshot)
+				kept := false
+				for _, r := range snap.Resources {
+					if r.URN == staleURN {
+						kept = true
+					}
+				}
+				assert.Equal(t, !expectDelete, kept)
+				return res
+			},
+		}}
+		p.Run(t, old)
+	}
+
+	// Stale default providers are only deleted if the user asks for it.
+	run(old, false, false)
+	run(old, true, true)
+
+	// A default provider that a remaining resource refers to in any way is not stale.
+	resBURN := p.NewURN("pkgA:m:typA", "resB", "")
+	for _, resB := range []*resource.State{
+		newResource(resBURN, "", "3", string(provURN)+"::0", []resource.URN{staleURN}, nil, nil, true),
+		newResource(resBURN, "", "3", string(provURN)+"::0", nil,
+			propertyDependencies{"foo": []resource.URN{staleURN}}, nil, true),
+		newResource(resBURN, staleURN, "3", string(provURN)+"::0", nil, nil, nil, true),
+	} {
+		run(&deploy.Snapshot{Resources: append(old.Resources[:3:3], resB)}, true, false)
+	}
+	deletedWith := newResource(resBURN, "", "3", string(provURN)+"::0", nil, nil, nil, true)
+	deletedWith.DeletedWith = staleURN
+	run(&deploy.Snapshot{Resources: append(old.Resources[:3:3], deletedWith)}, true, false)
+}
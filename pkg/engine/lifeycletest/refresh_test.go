@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/blang/semver"
@@ -78,6 +79,132 @@ func TestParallelRefresh(t *testing.T) {
 	assert.Equal(t, string(snap.Resources[4].URN.Name()), "resD")
 }
 
+func TestRefreshTargetDependents(t *testing.T) {
+	var lock sync.Mutex
+	read := make(map[resource.URN]bool)
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					read[urn] = true
+					return plugin.ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	// resB depends on resA through a property, resC depends on resB, and resD is unrelated.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		resA, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+
+		resB, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resB", true, deploytest.ResourceOptions{
+			Inputs:       resource.PropertyMap{"foo": resource.NewStringProperty("bar")},
+			PropertyDeps: map[resource.PropertyKey][]resource.URN{"foo": {resA}},
+		})
+		assert.NoError(t, err)
+
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resC", true, deploytest.ResourceOptions{
+			Dependencies: []resource.URN{resB},
+		})
+		assert.NoError(t, err)
+
+		_, _, _, err = monitor.RegisterResource("pkgA:m:typA", "resD", true)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host},
+	}
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	resB := p.NewURN("pkgA:m:typA", "resB", "")
+	resC := p.NewURN("pkgA:m:typA", "resC", "")
+
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+
+	refresh := func(targetDependents bool) map[resource.URN]bool {
+		read = make(map[resource.URN]bool)
+		p.Options.RefreshTargets = []resource.URN{resA}
+		p.Options.TargetDependents = targetDependents
+		p.Steps = []TestStep{{Op: Refresh, SkipPreview: true}}
+		p.Run(t, CloneSnapshot(t, snap))
+		return read
+	}
+
+	assert.Equal(t, map[resource.URN]bool{resA: true}, refresh(false))
+	assert.Equal(t, map[resource.URN]bool{resA: true, resB: true, resC: true}, refresh(true))
+}
+
+func TestRefreshIgnoreChanges(t *testing.T) {
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				CreateF: func(urn resource.URN, news resource.PropertyMap, timeout float64,
+					preview bool) (resource.ID, resource.PropertyMap, resource.Status, error) {
+
+					return "created-id", resource.PropertyMap{
+						"password": resource.NewStringProperty("old"),
+						"tags": resource.NewObjectProperty(resource.PropertyMap{
+							"owner":        resource.NewStringProperty("alice"),
+							"lastModified": resource.NewStringProperty("monday"),
+						}),
+					}, resource.StatusOK, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					return plugin.ReadResult{
+						Inputs: inputs,
+						Outputs: resource.PropertyMap{
+							"password": resource.NewStringProperty("rotated"),
+							"tags": resource.NewObjectProperty(resource.PropertyMap{
+								"owner":        resource.NewStringProperty("bob"),
+								"lastModified": resource.NewStringProperty("tuesday"),
+							}),
+							"volatile": resource.NewStringProperty("new"),
+						},
+					}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{Host: host},
+	}
+
+	p.Steps = []TestStep{{Op: Update}}
+	snap := p.Run(t, nil)
+
+	// Changes to the ignored properties are not adopted, while all other changes are.
+	p.Options.RefreshIgnoreChanges = []string{"password", "tags.lastModified", "volatile"}
+	p.Steps = []TestStep{{Op: Refresh}}
+	snap = p.Run(t, snap)
+
+	assert.Len(t, snap.Resources, 2)
+	assert.Equal(t, resource.PropertyMap{
+		"password": resource.NewStringProperty("old"),
+		"tags": resource.NewObjectProperty(resource.PropertyMap{
+			"owner":        resource.NewStringProperty("bob"),
+			"lastModified": resource.NewStringProperty("monday"),
+		}),
+	}, snap.Resources[1].Outputs)
+}
+
 func TestExternalRefresh(t *testing.T) {
 	loaders := []*deploytest.ProviderLoader{
 		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
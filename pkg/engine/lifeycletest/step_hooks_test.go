@@ -0,0 +1,138 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lifecycletest
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/pulumi/pulumi/pkg/v3/engine"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/deploytest"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestStepHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks in this test are shell commands")
+	}
+
+	dir, err := ioutil.TempDir("", "step-hooks")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	log := filepath.Join(dir, "hooks.log")
+
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{}, nil
+		}),
+	}
+
+	createB := true
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		_, _, _, err := monitor.RegisterResource("pkgA:m:typA", "resA", true)
+		assert.NoError(t, err)
+		if createB {
+			_, _, _, err = monitor.RegisterResource("pkgA:m:typB", "resB", true, deploytest.ResourceOptions{
+				Inputs: resource.PropertyMap{
+					"password": resource.MakeSecret(resource.NewStringProperty("hunter2")),
+				},
+			})
+			assert.NoError(t, err)
+		}
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{
+		Options: UpdateOptions{
+			Host: host,
+			StepHooks: []workspace.StepHook{
+				{When: workspace.StepHookAfter, Type: "pkgA:m:typB", Command: "cat >> " + log + "; echo >> " + log},
+				{When: workspace.StepHookBefore, Ops: []string{"delete"}, URN: "*::resB",
+					Command: "cat >> " + log + "; echo >> " + log},
+			},
+		},
+		Steps: []TestStep{{Op: Update}},
+	}
+	resBURN := p.NewURN("pkgA:m:typB", "resB", "")
+
+	type payload struct {
+		When string `json:"when"`
+		Op   string `json:"op"`
+		URN  string `json:"urn"`
+		New  *struct {
+			Inputs map[string]interface{} `json:"inputs"`
+		} `json:"new"`
+	}
+	readLog := func() []payload {
+		f, err := os.Open(log)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		require.NoError(t, err)
+		defer f.Close()
+
+		var payloads []payload
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var p payload
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &p))
+			payloads = append(payloads, p)
+		}
+		return payloads
+	}
+
+	// Creating resB runs its hook afterwards, which is given its inputs with secrets masked. Neither the preview nor
+	// the creation of resA runs a hook.
+	snap := p.Run(t, nil)
+	payloads := readLog()
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "after", payloads[0].When)
+	assert.Equal(t, "create", payloads[0].Op)
+	assert.Equal(t, string(resBURN), payloads[0].URN)
+	require.NotNil(t, payloads[0].New)
+	assert.Equal(t, "[secret]", payloads[0].New.Inputs["password"])
+
+	// Deleting resB runs both of its hooks.
+	createB = false
+	p.Run(t, snap)
+	payloads = readLog()
+	require.Len(t, payloads, 3)
+	assert.Equal(t, "before", payloads[1].When)
+	assert.Equal(t, "delete", payloads[1].Op)
+	assert.Equal(t, "after", payloads[2].When)
+	assert.Equal(t, "delete", payloads[2].Op)
+
+	// A hook that fails before a step fails the step.
+	createB = true
+	snap = p.Run(t, nil)
+	p.Options.StepHooks = []workspace.StepHook{
+		{When: workspace.StepHookBefore, Ops: []string{"delete"}, Command: "exit 1"},
+	}
+	createB = false
+	p.Steps = []TestStep{{Op: Update, ExpectFailure: true, SkipPreview: true}}
+	p.Run(t, snap)
+}
@@ -1,6 +1,7 @@
 package lifecycletest
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/blang/semver"
@@ -813,6 +814,78 @@ func destroySpecificTargetsWithChildren(
 	p.Run(t, old)
 }
 
+func TestExcludeTargets(t *testing.T) {
+	var lock sync.Mutex
+	var deleted, read []resource.URN
+	loaders := []*deploytest.ProviderLoader{
+		deploytest.NewProviderLoader("pkgA", semver.MustParse("1.0.0"), func() (plugin.Provider, error) {
+			return &deploytest.Provider{
+				DeleteF: func(urn resource.URN, id resource.ID, olds resource.PropertyMap,
+					timeout float64) (resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					deleted = append(deleted, urn)
+					return resource.StatusOK, nil
+				},
+				ReadF: func(urn resource.URN, id resource.ID,
+					inputs, state resource.PropertyMap) (plugin.ReadResult, resource.Status, error) {
+
+					lock.Lock()
+					defer lock.Unlock()
+					read = append(read, urn)
+					return plugin.ReadResult{Inputs: inputs, Outputs: state}, resource.StatusOK, nil
+				},
+			}, nil
+		}),
+	}
+
+	// The program no longer registers any resources, so every resource would normally be deleted.
+	program := deploytest.NewLanguageRuntime(func(_ plugin.RunInfo, monitor *deploytest.ResourceMonitor) error {
+		return nil
+	})
+	host := deploytest.NewPluginHost(nil, nil, program, loaders...)
+
+	p := &TestPlan{}
+	provURN := p.NewProviderURN("pkgA", "default", "")
+	provRef := string(provURN) + "::0"
+	resA := p.NewURN("pkgA:m:typA", "resA", "")
+	resB := p.NewURN("pkgA:m:typA", "resB", "")
+	resC := p.NewURN("pkgA:m:typA", "resC", "")
+
+	protected := newResource(resB, "", "2", provRef, []resource.URN{resA}, nil, nil, true)
+	protected.Protect = true
+	old := &deploy.Snapshot{
+		Resources: []*resource.State{
+			newResource(provURN, "", "0", "", nil, nil, nil, true),
+			newResource(resA, "", "1", provRef, nil, nil, nil, true),
+			protected,
+			newResource(resC, "", "3", provRef, nil, nil, nil, true),
+		},
+	}
+
+	p.Options = UpdateOptions{
+		Host:           host,
+		ExcludeTargets: []resource.URN{provURN, resA, resB},
+	}
+
+	// Refreshing skips the excluded resources.
+	p.Steps = []TestStep{{Op: Refresh, SkipPreview: true}}
+	p.Run(t, CloneSnapshot(t, old))
+	assert.Equal(t, []resource.URN{resC}, read)
+
+	// Updating leaves the excluded resources in place rather than failing to delete the protected one.
+	p.Steps = []TestStep{{Op: Update, SkipPreview: true}}
+	snap := p.Run(t, CloneSnapshot(t, old))
+	assert.Equal(t, []resource.URN{resC}, deleted)
+
+	var urns []resource.URN
+	for _, res := range snap.Resources {
+		urns = append(urns, res.URN)
+	}
+	assert.Equal(t, []resource.URN{provURN, resA, resB}, urns)
+}
+
 func newResource(urn, parent resource.URN, id resource.ID, provider string, dependencies []resource.URN,
 	propertyDeps propertyDependencies, outputs resource.PropertyMap, custom bool) *resource.State {
 
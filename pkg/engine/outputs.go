@@ -0,0 +1,86 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// OutputChangeKind describes the way in which a stack output changed between two updates.
+type OutputChangeKind string
+
+const (
+	// OutputAdded indicates that the output did not exist before the update.
+	OutputAdded OutputChangeKind = "add"
+	// OutputUpdated indicates that the output's value changed during the update.
+	OutputUpdated OutputChangeKind = "update"
+	// OutputDeleted indicates that the output no longer exists after the update.
+	OutputDeleted OutputChangeKind = "delete"
+)
+
+// OutputChange is a machine-readable record of a single stack output's change between two updates. Values that
+// contain secrets are never recorded; only the fact that they changed is.
+type OutputChange struct {
+	Kind   OutputChangeKind `json:"kind"`
+	Old    interface{}      `json:"old,omitempty"`
+	New    interface{}      `json:"new,omitempty"`
+	Secret bool             `json:"secret,omitempty"`
+}
+
+// OutputChanges maps the names of stack outputs to the changes they underwent.
+type OutputChanges map[string]OutputChange
+
+// StackOutputs returns the outputs of the root stack resource in the given snapshot, or nil if there are none.
+func StackOutputs(snap *deploy.Snapshot) resource.PropertyMap {
+	if snap == nil {
+		return nil
+	}
+	for _, res := range snap.Resources {
+		if res.Type == resource.RootStackType && !res.Delete {
+			return res.Outputs
+		}
+	}
+	return nil
+}
+
+// DiffStackOutputs computes the changes between two sets of stack outputs. Outputs whose values are unchanged are
+// omitted from the result.
+func DiffStackOutputs(olds, news resource.PropertyMap) OutputChanges {
+	changes := OutputChanges{}
+	for k, oldValue := range olds {
+		newValue, has := news[k]
+		switch {
+		case !has:
+			changes[string(k)] = makeOutputChange(OutputDeleted, oldValue, resource.PropertyValue{})
+		case !oldValue.DeepEquals(newValue):
+			changes[string(k)] = makeOutputChange(OutputUpdated, oldValue, newValue)
+		}
+	}
+	for k, newValue := range news {
+		if _, has := olds[k]; !has {
+			changes[string(k)] = makeOutputChange(OutputAdded, resource.PropertyValue{}, newValue)
+		}
+	}
+	return changes
+}
+
+// makeOutputChange records a change between two output values, either of which may be the zero value if absent.
+func makeOutputChange(kind OutputChangeKind, oldValue, newValue resource.PropertyValue) OutputChange {
+	if oldValue.ContainsSecrets() || newValue.ContainsSecrets() {
+		return OutputChange{Kind: kind, Secret: true}
+	}
+	return OutputChange{Kind: kind, Old: oldValue.Mappable(), New: newValue.Mappable()}
+}
@@ -0,0 +1,53 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestDiffStackOutputs(t *testing.T) {
+	olds := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"same":    "a",
+		"changed": "b",
+		"removed": 42,
+	})
+	olds["password"] = resource.MakeSecret(resource.NewStringProperty("hunter2"))
+
+	news := resource.NewPropertyMapFromMap(map[string]interface{}{
+		"same":    "a",
+		"changed": "c",
+		"added":   true,
+	})
+	news["password"] = resource.MakeSecret(resource.NewStringProperty("hunter3"))
+
+	changes := DiffStackOutputs(olds, news)
+	assert.Equal(t, OutputChanges{
+		"changed":  {Kind: OutputUpdated, Old: "b", New: "c"},
+		"removed":  {Kind: OutputDeleted, Old: float64(42)},
+		"added":    {Kind: OutputAdded, New: true},
+		"password": {Kind: OutputUpdated, Secret: true},
+	}, changes)
+}
+
+func TestDiffStackOutputsUnchanged(t *testing.T) {
+	outputs := resource.NewPropertyMapFromMap(map[string]interface{}{"url": "https://example.com"})
+	assert.Empty(t, DiffStackOutputs(outputs, outputs))
+	assert.Empty(t, DiffStackOutputs(nil, nil))
+}
@@ -0,0 +1,102 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// PlannedStep describes a single step that the engine would perform in order to apply an update.
+type PlannedStep struct {
+	StepEventMetadata
+
+	// Dependencies are the URNs of the resources that the step's resource depends upon.
+	Dependencies []resource.URN
+}
+
+// UpdatePlan is the typed result of planning an update: the steps that the engine would perform, in the order in which
+// it would begin them, along with the aggregate resource changes. Nothing in a plan has been executed.
+type UpdatePlan struct {
+	Steps   []PlannedStep
+	Changes ResourceChanges
+}
+
+// Plan computes the steps that an update of the given target would perform without performing any of them. This runs
+// the program exactly as a preview would, so the same events are published to ctx.Events, which the caller must drain.
+func Plan(u UpdateInfo, ctx *Context, opts UpdateOptions) (*UpdatePlan, result.Result) {
+	contract.Require(u != nil, "update")
+	contract.Require(ctx != nil, "ctx")
+
+	defer func() { ctx.Events <- cancelEvent() }()
+
+	info, err := newDeploymentContext(u, "plan", ctx.ParentSpan)
+	if err != nil {
+		return nil, result.FromError(err)
+	}
+	defer info.Close()
+
+	emitter, err := makeEventEmitter(ctx.Events, u)
+	if err != nil {
+		return nil, result.FromError(err)
+	}
+	defer emitter.Close()
+
+	logging.V(7).Infof("*** Starting Plan ***")
+	defer logging.V(7).Infof("*** Plan complete ***")
+
+	deploymentOpts := deploymentOptions{
+		UpdateOptions: opts,
+		SourceFunc:    newUpdateSource,
+		Events:        emitter,
+		Diag:          newEventSink(emitter, false),
+		StatusDiag:    newEventSink(emitter, true),
+	}
+
+	deployment, err := newDeployment(ctx, info, deploymentOpts, true /*preview*/)
+	if err != nil {
+		return nil, result.FromError(err)
+	}
+	defer contract.IgnoreClose(deployment)
+
+	actions := &planActions{previewActions: newPreviewActions(deploymentOpts)}
+	changes, res := deployment.run(ctx, actions, deploymentPolicies(deploymentOpts), true /*preview*/)
+	return &UpdatePlan{Steps: actions.steps, Changes: changes}, res
+}
+
+// planActions records each step that a preview begins, in addition to reporting it as a preview would.
+type planActions struct {
+	*previewActions
+
+	steps []PlannedStep
+}
+
+func (acts *planActions) OnResourceStepPre(step deploy.Step) (interface{}, error) {
+	planned := PlannedStep{
+		StepEventMetadata: makeStepEventMetadata(step.Op(), step, acts.Opts.Debug),
+	}
+	if res := step.Res(); res != nil {
+		planned.Dependencies = res.Dependencies
+	}
+
+	acts.MapLock.Lock()
+	acts.steps = append(acts.steps, planned)
+	acts.MapLock.Unlock()
+
+	return acts.previewActions.OnResourceStepPre(step)
+}
@@ -133,6 +133,7 @@ func gatherPluginsFromSnapshot(plugctx *plugin.Context, target *deploy.Target) (
 func ensurePluginsAreInstalled(plugins pluginSet) error {
 	logging.V(preparePluginLog).Infof("ensurePluginsAreInstalled(): beginning")
 	var installTasks errgroup.Group
+	installed := false
 	for _, plug := range plugins.Values() {
 		_, path, err := workspace.GetPluginPath(plug.Kind, plug.Name, plug.Version)
 		if err == nil && path != "" {
@@ -142,6 +143,7 @@ func ensurePluginsAreInstalled(plugins pluginSet) error {
 		}
 
 		// Launch an install task asynchronously and add it to the current error group.
+		installed = true
 		info := plug // don't close over the loop induction variable
 		installTasks.Go(func() error {
 			logging.V(preparePluginLog).Infof(
@@ -152,9 +154,32 @@ func ensurePluginsAreInstalled(plugins pluginSet) error {
 
 	err := installTasks.Wait()
 	logging.V(preparePluginLog).Infof("ensurePluginsAreInstalled(): completed")
+	if err == nil && installed {
+		autoPrunePlugins(plugins)
+	}
 	return err
 }
 
+// autoPrunePlugins prunes the plugin cache after plugins have been installed, if the plugin settings ask for it. The
+// plugins in the given set are never pruned. Failing to prune is not fatal, so errors are only logged.
+func autoPrunePlugins(plugins pluginSet) {
+	removed, err := workspace.AutoPrunePlugins(func(plug workspace.PluginInfo) bool {
+		for _, required := range plugins {
+			if required.Kind == plug.Kind && required.Name == plug.Name &&
+				(required.Version == nil || plug.Version == nil || required.Version.EQ(*plug.Version)) {
+				return true
+			}
+		}
+		return false
+	})
+	for _, plug := range removed {
+		logging.V(preparePluginLog).Infof("autoPrunePlugins(): pruned %s plugin %s", plug.Kind, plug)
+	}
+	if err != nil {
+		logging.V(preparePluginLog).Infof("autoPrunePlugins(): failed to prune plugins: %v", err)
+	}
+}
+
 // ensurePluginsAreLoaded ensures that all of the plugins in the given plugin set that match the given plugin flags are
 // loaded.
 func ensurePluginsAreLoaded(plugctx *plugin.Context, plugins pluginSet, kinds plugin.Flags) error {
@@ -290,3 +315,40 @@ func computeDefaultProviderPlugins(languagePlugins, allPlugins pluginSet) map[to
 
 	return defaultProviderVersions
 }
+
+// pinnedProviderHost is a plugin host that loads the pinned version of a package's provider plugin whatever version is
+// asked for, so that a bad provider release can be rolled back for an operation without changing the program or the
+// state.
+type pinnedProviderHost struct {
+	plugin.Host
+
+	versions map[tokens.Package]*semver.Version
+}
+
+func (h *pinnedProviderHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	if pinned, ok := h.versions[pkg]; ok {
+		logging.V(preparePluginLog).Infof("pinnedProviderHost.Provider(%s): loading pinned version %s instead of %s",
+			pkg, pinned, version)
+		version = pinned
+	}
+	return h.Host.Provider(pkg, version)
+}
+
+// pinProviderVersions installs the pinned version of each package's provider plugin, and replaces the host of the
+// given plugin context with one that loads the pinned versions.
+func pinProviderVersions(plugctx *plugin.Context, versions map[tokens.Package]*semver.Version) error {
+	if len(versions) == 0 {
+		return nil
+	}
+
+	plugins := newPluginSet()
+	for pkg, version := range versions {
+		plugins.Add(workspace.PluginInfo{Name: string(pkg), Kind: workspace.ResourcePlugin, Version: version})
+	}
+	if err := ensurePluginsAreInstalled(plugins); err != nil {
+		return fmt.Errorf("installing pinned provider plugins: %w", err)
+	}
+
+	plugctx.Host = &pinnedProviderHost{Host: plugctx.Host, versions: versions}
+	return nil
+}
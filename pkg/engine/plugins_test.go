@@ -20,6 +20,7 @@ import (
 	"github.com/blang/semver"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
 )
@@ -124,3 +125,34 @@ func TestDefaultProvidersSnapshotOverrides(t *testing.T) {
 	assert.NotNil(t, awsVer)
 	assert.Equal(t, "0.17.0", awsVer.String())
 }
+
+// providerRecordingHost is a plugin host that records the versions of the providers that it is asked to load.
+type providerRecordingHost struct {
+	plugin.Host
+
+	loaded map[tokens.Package]*semver.Version
+}
+
+func (h *providerRecordingHost) Provider(pkg tokens.Package, version *semver.Version) (plugin.Provider, error) {
+	h.loaded[pkg] = version
+	return nil, nil
+}
+
+func TestPinnedProviderHost(t *testing.T) {
+	inner := &providerRecordingHost{loaded: make(map[tokens.Package]*semver.Version)}
+	host := &pinnedProviderHost{
+		Host:     inner,
+		versions: map[tokens.Package]*semver.Version{"aws": mustMakeVersion("4.1.0")},
+	}
+
+	_, err := host.Provider("aws", mustMakeVersion("4.2.0"))
+	assert.NoError(t, err)
+	_, err = host.Provider("kubernetes", mustMakeVersion("3.7.0"))
+	assert.NoError(t, err)
+	_, err = host.Provider("random", nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, mustMakeVersion("4.1.0"), inner.loaded["aws"])
+	assert.Equal(t, mustMakeVersion("3.7.0"), inner.loaded["kubernetes"])
+	assert.Nil(t, inner.loaded["random"])
+}
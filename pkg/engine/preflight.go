@@ -0,0 +1,228 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil/rpcerror"
+)
+
+// CheckPermissionsFunction is the provider function that the engine invokes to check, before an update starts, that
+// the provider's credentials allow the operations that the update will perform. Its argument is an "operations" list
+// of objects with "type" and "operation" properties, where the operation is "create", "update" or "delete". It
+// returns a "denied" list of objects with "type", "operation" and "reason" properties for each operation that would
+// fail.
+//
+// The function is not yet part of the provider protocol, so few providers implement it. Providers that report it as
+// unknown are not checked.
+const CheckPermissionsFunction tokens.ModuleMember = "pulumi:providers:checkPermissions"
+
+// PermissionDenial is an operation that a provider reported its credentials do not allow.
+type PermissionDenial struct {
+	Provider  string      // the reference of the provider that denied the operation.
+	Type      tokens.Type // the type of resource that the operation is on, if known.
+	Operation string      // the operation: "create", "update" or "delete".
+	Reason    string      // why the operation would fail.
+}
+
+func (d PermissionDenial) String() string {
+	if d.Type == "" {
+		return d.Reason
+	}
+	return fmt.Sprintf("%s of %s: %s", d.Operation, d.Type, d.Reason)
+}
+
+// preflight checks that the providers of a previewed update allow the operations that the preview recorded, before
+// any of them is performed by the update.
+func preflight(d *deploy.Deployment, actions *preflightActions, sink diag.Sink) result.Result {
+	denials, err := checkPermissions(d, actions.operations, sink)
+	if err != nil {
+		return result.FromError(err)
+	}
+	if len(denials) > 0 {
+		msgs := make([]string, len(denials))
+		for i, d := range denials {
+			msgs[i] = "\n    " + d.String()
+		}
+		return result.Errorf("preflight checks failed; the update would not be permitted to perform these "+
+			"operations, so nothing was changed:%s", strings.Join(msgs, ""))
+	}
+	return nil
+}
+
+// preflightOperation is an operation that an update will perform on a resource of some type.
+type preflightOperation struct {
+	Type      tokens.Type
+	Operation string
+}
+
+// preflightActions records the operations of a previewed update by the provider that will perform them, in addition
+// to reporting the preview as usual.
+type preflightActions struct {
+	*previewActions
+
+	lock       sync.Mutex
+	operations map[string]map[preflightOperation]bool
+}
+
+func newPreflightActions(opts deploymentOptions) *preflightActions {
+	return &preflightActions{
+		previewActions: newPreviewActions(opts),
+		operations:     make(map[string]map[preflightOperation]bool),
+	}
+}
+
+// preflightOperationName returns the permission that a step needs from its provider, if any.
+func preflightOperationName(op deploy.StepOp) (string, bool) {
+	switch op {
+	case deploy.OpCreate, deploy.OpCreateReplacement:
+		return "create", true
+	case deploy.OpUpdate:
+		return "update", true
+	case deploy.OpDelete, deploy.OpDeleteReplaced:
+		return "delete", true
+	default:
+		return "", false
+	}
+}
+
+func (acts *preflightActions) OnResourceStepPre(step deploy.Step) (interface{}, error) {
+	name, ok := preflightOperationName(step.Op())
+	if ok && step.Provider() != "" && !providers.IsProviderType(step.Type()) {
+		acts.lock.Lock()
+		ops, has := acts.operations[step.Provider()]
+		if !has {
+			ops = make(map[preflightOperation]bool)
+			acts.operations[step.Provider()] = ops
+		}
+		ops[preflightOperation{Type: step.Type(), Operation: name}] = true
+		acts.lock.Unlock()
+	}
+	return acts.previewActions.OnResourceStepPre(step)
+}
+
+// isUnknownFunctionError returns true if an error from invoking CheckPermissionsFunction means that the provider does
+// not implement the function, rather than that the check failed. Providers report unknown functions differently: as
+// an unimplemented method, or as an error that names the function.
+func isUnknownFunctionError(err error) bool {
+	if rpcErr, ok := rpcerror.FromError(err); ok && rpcErr.Code() == codes.Unimplemented {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	if !strings.Contains(msg, strings.ToLower(string(CheckPermissionsFunction))) {
+		return false
+	}
+	for _, unknown := range []string{"unknown", "unrecognized", "not found", "not implemented", "unsupported"} {
+		if strings.Contains(msg, unknown) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPermissions asks the provider for each reference in operations to check the operations recorded for it, and
+// returns the operations that were denied.
+func checkPermissions(d *deploy.Deployment, operations map[string]map[preflightOperation]bool,
+	sink diag.Sink) ([]PermissionDenial, error) {
+
+	refs := make([]string, 0, len(operations))
+	for ref := range operations {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	var denials []PermissionDenial
+	for _, refString := range refs {
+		ref, err := providers.ParseReference(refString)
+		if err != nil {
+			return nil, err
+		}
+		prov, ok := d.GetProvider(ref)
+		if !ok {
+			return nil, fmt.Errorf("unknown provider '%v'", ref)
+		}
+
+		ops := make([]preflightOperation, 0, len(operations[refString]))
+		for op := range operations[refString] {
+			ops = append(ops, op)
+		}
+		sort.Slice(ops, func(i, j int) bool {
+			if ops[i].Type != ops[j].Type {
+				return ops[i].Type < ops[j].Type
+			}
+			return ops[i].Operation < ops[j].Operation
+		})
+		args := make([]resource.PropertyValue, len(ops))
+		for i, op := range ops {
+			args[i] = resource.NewObjectProperty(resource.PropertyMap{
+				"type":      resource.NewStringProperty(string(op.Type)),
+				"operation": resource.NewStringProperty(op.Operation),
+			})
+		}
+
+		ret, failures, err := prov.Invoke(CheckPermissionsFunction, resource.PropertyMap{
+			"operations": resource.NewArrayProperty(args),
+		})
+		if err != nil {
+			if !isUnknownFunctionError(err) {
+				return nil, fmt.Errorf("checking the permissions of provider %s: %w", ref.URN().Name(), err)
+			}
+			// Most providers don't implement permission checks yet, so this isn't fatal.
+			logging.V(7).Infof("preflight: provider %v does not check permissions: %v", ref, err)
+			sink.Infof(diag.Message(ref.URN(),
+				"skipping preflight checks for provider %s, which does not support them"), ref.URN().Name())
+			continue
+		}
+		for _, f := range failures {
+			denials = append(denials, PermissionDenial{Provider: refString, Reason: f.Reason})
+		}
+
+		denied, ok := ret["denied"]
+		if !ok || !denied.IsArray() {
+			continue
+		}
+		for _, v := range denied.ArrayValue() {
+			if !v.IsObject() {
+				continue
+			}
+			obj := v.ObjectValue()
+			denial := PermissionDenial{Provider: refString}
+			if t := obj["type"]; t.IsString() {
+				denial.Type = tokens.Type(t.StringValue())
+			}
+			if op := obj["operation"]; op.IsString() {
+				denial.Operation = op.StringValue()
+			}
+			if reason := obj["reason"]; reason.IsString() {
+				denial.Reason = reason.StringValue()
+			}
+			denials = append(denials, denial)
+		}
+	}
+	return denials, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func newRollbackSource(
+	client deploy.BackendClient, opts deploymentOptions, proj *workspace.Project, pwd, main string,
+	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
+
+	// Like Destroy, we don't run the user's program, so we only need the plugins of the providers in the current
+	// snapshot, which delete the resources that the failed update created, and in the snapshot we are rolling back
+	// to, which restore the resources that it changed.
+	current, err := gatherPluginsFromSnapshot(plugctx, target)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := gatherPluginsFromSnapshot(plugctx, &deploy.Target{Snapshot: opts.RollbackTo})
+	if err != nil {
+		return nil, err
+	}
+	plugins := current.Union(previous)
+
+	if err := ensurePluginsAreInstalled(plugins); err != nil {
+		logging.V(7).Infof("newRollbackSource(): failed to install missing plugins: %v", err)
+	}
+	if err := ensurePluginsAreLoaded(plugctx, plugins, plugin.AnalyzerPlugins); err != nil {
+		return nil, err
+	}
+	if err := loadPolicyPlugins(plugctx, opts, proj, target, dryRun); err != nil {
+		return nil, err
+	}
+
+	return deploy.NewRollbackSource(proj.Name, opts.RollbackTo), nil
+}
@@ -0,0 +1,366 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// rolloutWave is a rollout wave whose globs have been compiled.
+type rolloutWave struct {
+	workspace.RolloutWave
+
+	types []*regexp.Regexp
+	urns  []*regexp.Regexp
+}
+
+// rollout holds back the steps of each wave of a staged rollout until the waves before it have completed and passed
+// their checks.
+//
+// The members of each wave are the resources that a preview of the update deploys, i.e. creates, updates, replaces,
+// reads, imports or leaves the same. A wave is complete once each of its members has been deployed and none of its
+// steps is running. Steps of resources in no wave are never held back, and neither are deletes, which the engine only
+// performs once the program has finished.
+type rollout struct {
+	waves []rolloutWave
+	dir   string
+	diag  diag.Sink
+	// confirm asks whether the update may proceed once the named wave has completed, for waves that pause.
+	confirm func(wave string) (bool, error)
+	// canceled is closed if the update is canceled.
+	canceled <-chan struct{}
+	// parallel is the number of steps that the update may perform at once, or 0 if it is unbounded.
+	parallel int
+
+	lock sync.Mutex
+	// members holds the resources of each wave that the update will deploy. It is set by plan.
+	members []map[resource.URN]bool
+	// deployed holds the members whose deploying steps have finished successfully.
+	deployed map[resource.URN]bool
+	// current is the index of the wave whose steps are being performed. The waves before it have completed.
+	current int
+	// running is the number of steps of the current and earlier waves that have started but not finished.
+	running int
+	// waiting is the number of steps of later waves that are being held back.
+	waiting int
+	// advancing is true while the checks of the current wave run.
+	advancing bool
+	// err is the reason that the update is not proceeding to its next wave, if any.
+	err error
+	// changed is closed and replaced whenever the rollout's state changes.
+	changed chan struct{}
+}
+
+// newRollout compiles the given waves, whose health checks are run in the given directory and report their output to
+// the given sink. The parallel argument is the update's degree of parallelism, or 0 if it is unbounded. It returns nil
+// if there are no waves. The rollout must be planned before the update starts.
+func newRollout(waves []workspace.RolloutWave, dir string, sink diag.Sink,
+	confirm func(wave string) (bool, error), canceled <-chan struct{}, parallel int) *rollout {
+
+	if len(waves) == 0 {
+		return nil
+	}
+
+	compiled := make([]rolloutWave, len(waves))
+	for i, w := range waves {
+		compiled[i].RolloutWave = w
+		for _, typ := range w.Types {
+			compiled[i].types = append(compiled[i].types, compileStepHookGlob(typ))
+		}
+		for _, urn := range w.URNs {
+			compiled[i].urns = append(compiled[i].urns, compileStepHookGlob(urn))
+		}
+	}
+	return &rollout{
+		waves:    compiled,
+		dir:      dir,
+		diag:     sink,
+		confirm:  confirm,
+		canceled: canceled,
+		parallel: parallel,
+		deployed: make(map[resource.URN]bool),
+		changed:  make(chan struct{}),
+	}
+}
+
+// isRolloutDeployOp returns true if a step with the given operation deploys its resource. Each resource that the
+// program registers is deployed by exactly one such step, whatever other steps, e.g. the deletion of the resource that
+// it replaces, accompany it.
+func isRolloutDeployOp(op deploy.StepOp) bool {
+	switch op {
+	case deploy.OpSame, deploy.OpCreate, deploy.OpUpdate, deploy.OpCreateReplacement, deploy.OpRead,
+		deploy.OpReadReplacement, deploy.OpImport, deploy.OpImportReplacement:
+		return true
+	default:
+		return false
+	}
+}
+
+// waveOf returns the index of the first wave that the given resource belongs to, or -1 if it is in none.
+func (r *rollout) waveOf(urn resource.URN, typ tokens.Type) int {
+	for i, w := range r.waves {
+		for _, t := range w.types {
+			if t.MatchString(string(typ)) {
+				return i
+			}
+		}
+		for _, u := range w.urns {
+			if u.MatchString(string(urn)) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// plan finds the members of each wave from a silent preview of the update. It fails if a member of a wave depends on,
+// or is the child of, a resource in a later wave, as that wave could then never complete.
+func (r *rollout) plan(ctx *Context, info *deploymentContext, opts deploymentOptions) result.Result {
+	if r == nil {
+		return nil
+	}
+
+	actions := &rolloutPlanActions{}
+	deployment, res := silentPreview(ctx, info, opts, actions)
+	if res != nil {
+		return res
+	}
+	contract.IgnoreClose(deployment)
+
+	members := make([]map[resource.URN]bool, len(r.waves))
+	for i := range members {
+		members[i] = make(map[resource.URN]bool)
+	}
+	waves := make(map[resource.URN]int)
+	for _, step := range actions.steps {
+		wave := r.waveOf(step.URN(), step.Type())
+		waves[step.URN()] = wave
+		if wave >= 0 {
+			members[wave][step.URN()] = true
+		}
+	}
+	for _, step := range actions.steps {
+		wave := waves[step.URN()]
+		if wave < 0 {
+			continue
+		}
+		for _, dep := range rolloutDependencies(step) {
+			if w, ok := waves[dep]; ok && w > wave {
+				return result.Errorf("%s in rollout wave %q depends on %s in the later rollout wave %q, so the "+
+					"update cannot be staged", step.URN(), r.waves[wave].Name, dep, r.waves[w].Name)
+			}
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.members = members
+	return nil
+}
+
+// rolloutDependencies returns the resources that must be deployed before the given step can start.
+func rolloutDependencies(step deploy.Step) []resource.URN {
+	res := step.Res()
+	if res == nil {
+		return nil
+	}
+	deps := append([]resource.URN{}, res.Dependencies...)
+	if res.Parent != "" {
+		deps = append(deps, res.Parent)
+	}
+	if res.Provider != "" {
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			deps = append(deps, ref.URN())
+		}
+	}
+	return deps
+}
+
+// rolloutPlanActions records the deploying steps of a previewed update.
+type rolloutPlanActions struct {
+	lock  sync.Mutex
+	steps []deploy.Step
+}
+
+func (acts *rolloutPlanActions) OnResourceStepPre(step deploy.Step) (interface{}, error) {
+	if isRolloutDeployOp(step.Op()) {
+		acts.lock.Lock()
+		acts.steps = append(acts.steps, step)
+		acts.lock.Unlock()
+	}
+	return nil, nil
+}
+
+func (acts *rolloutPlanActions) OnResourceStepPost(ctx interface{}, step deploy.Step, status resource.Status,
+	err error) error {
+	return nil
+}
+
+func (acts *rolloutPlanActions) OnResourceOutputs(step deploy.Step) error {
+	return nil
+}
+
+func (acts *rolloutPlanActions) OnPolicyViolation(resource.URN, plugin.AnalyzeDiagnostic) {}
+
+// complete returns true if the current wave has completed. It must be called with the lock held.
+func (r *rollout) complete() bool {
+	if r.running > 0 {
+		return false
+	}
+	for urn := range r.members[r.current] {
+		if !r.deployed[urn] {
+			return false
+		}
+	}
+	return true
+}
+
+// broadcast wakes the steps that are waiting for the rollout to change. It must be called with the lock held.
+func (r *rollout) broadcast() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// start waits until the given step may start, which is once its wave has been reached. It returns an error if the
+// update will not reach the step's wave. Each step that starts successfully must be finished.
+func (r *rollout) start(step deploy.Step) error {
+	if r == nil {
+		return nil
+	}
+	wave := r.waveOf(step.URN(), step.Type())
+	if wave < 0 {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	contract.Assertf(r.members != nil, "the rollout was not planned")
+	for {
+		switch {
+		case r.err != nil:
+			return fmt.Errorf("the %s of %s was not started: %w", step.Op(), step.URN(), r.err)
+		case wave <= r.current:
+			r.running++
+			return nil
+		case !r.advancing && r.complete():
+			r.advance()
+			continue
+		case !r.advancing && r.running == 0 && r.parallel > 0 && r.waiting+1 >= r.parallel:
+			// Every step that may run at once is waiting for the current wave, so none of its steps can start.
+			r.err = fmt.Errorf("rollout wave %q cannot complete because all %d parallel steps are waiting for it; "+
+				"raise --parallel", r.waves[r.current].Name, r.parallel)
+			r.broadcast()
+			continue
+		}
+
+		r.waiting++
+		changed := r.changed
+		r.lock.Unlock()
+		select {
+		case <-changed:
+		case <-r.canceled:
+			r.lock.Lock()
+			r.waiting--
+			return fmt.Errorf("the %s of %s was not started because the update was canceled", step.Op(), step.URN())
+		}
+		r.lock.Lock()
+		r.waiting--
+	}
+}
+
+// advance runs the checks of the current wave, which has completed, and proceeds to the next wave if they pass. It
+// must be called with the lock held, which it releases while the checks run.
+func (r *rollout) advance() {
+	r.advancing = true
+	wave := r.waves[r.current]
+	r.lock.Unlock()
+	err := r.check(wave)
+	r.lock.Lock()
+	r.advancing = false
+
+	if err != nil {
+		r.err = err
+	} else {
+		r.current++
+	}
+	r.broadcast()
+}
+
+// check runs the health check of a wave that has completed and asks whether to proceed if the wave pauses.
+func (r *rollout) check(wave rolloutWave) error {
+	if wave.HealthCheck != "" {
+		logging.V(7).Infof("running health check %q of rollout wave %q", wave.HealthCheck, wave.Name)
+		output, err := runHookCommand(wave.HealthCheck, r.dir, nil, "PULUMI_ROLLOUT_WAVE="+wave.Name)
+		if err != nil {
+			return fmt.Errorf("the health check of rollout wave %q failed: %w", wave.Name, err)
+		}
+		if output != "" {
+			r.diag.Infof(diag.RawMessage("", output+"\n"))
+		}
+	}
+	if wave.Pause {
+		if r.confirm == nil {
+			return fmt.Errorf("rollout wave %q pauses for confirmation, which requires an interactive session",
+				wave.Name)
+		}
+		proceed, err := r.confirm(wave.Name)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			return fmt.Errorf("the update was stopped after rollout wave %q", wave.Name)
+		}
+	}
+	return nil
+}
+
+// finish records that a step that started has finished. A step that failed, whether or not it is in a wave, stops the
+// update from proceeding to any later wave.
+func (r *rollout) finish(step deploy.Step, failed bool) {
+	if r == nil {
+		return
+	}
+	wave := r.waveOf(step.URN(), step.Type())
+	if wave < 0 && !failed {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if wave >= 0 {
+		r.running--
+		if !failed && isRolloutDeployOp(step.Op()) {
+			r.deployed[step.URN()] = true
+		}
+	}
+	if failed && r.err == nil {
+		r.err = errors.New("an earlier step of the update failed")
+	}
+	r.broadcast()
+}
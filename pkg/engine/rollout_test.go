@@ -0,0 +1,135 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+func TestRolloutWaves(t *testing.T) {
+	step := func(name string) deploy.Step {
+		return deploy.NewDeleteStep(nil, &resource.State{
+			URN:  resource.URN("urn:pulumi:dev::proj::aws:ec2/instance:Instance::" + name),
+			Type: "aws:ec2/instance:Instance",
+		})
+	}
+	web0, web1, web2 := step("web-0"), step("web-1"), step("web-2")
+	other := deploy.NewDeleteStep(nil, &resource.State{
+		URN:  "urn:pulumi:dev::proj::aws:s3/bucket:Bucket::logs",
+		Type: "aws:s3/bucket:Bucket",
+	})
+
+	var confirmed []string
+	proceed := true
+	sink := diag.DefaultSink(ioutil.Discard, ioutil.Discard, diag.FormatOptions{Color: colors.Never})
+	newPlannedRollout := func(waves []workspace.RolloutWave, confirm func(string) (bool, error),
+		parallel int) *rollout {
+
+		r := newRollout(waves, ".", sink, confirm, nil, parallel)
+		r.members = []map[resource.URN]bool{
+			{web0.URN(): true},
+			{web1.URN(): true, web2.URN(): true},
+		}
+		return r
+	}
+	confirm := func(wave string) (bool, error) {
+		confirmed = append(confirmed, wave)
+		return proceed, nil
+	}
+	r := newPlannedRollout([]workspace.RolloutWave{
+		{Name: "canary", URNs: []string{"*::web-0"}, Pause: true},
+		{Name: "fleet", Types: []string{"aws:ec2/*"}, Pause: true},
+	}, confirm, 0)
+
+	started := func(s deploy.Step) <-chan error {
+		c := make(chan error, 1)
+		go func() { c <- r.start(s) }()
+		return c
+	}
+	waitFor := func(c <-chan error) error {
+		select {
+		case err := <-c:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("step was not started")
+			return nil
+		}
+	}
+	isHeld := func(c <-chan error) bool {
+		select {
+		case <-c:
+			return false
+		case <-time.After(50 * time.Millisecond):
+			return true
+		}
+	}
+
+	// Resources in no wave are never held back.
+	assert.NoError(t, waitFor(started(other)))
+
+	// The fleet waits for the canary to be deployed, even if the canary has not been registered yet, and for its
+	// pause to be confirmed.
+	fleet := started(web1)
+	assert.True(t, isHeld(fleet))
+	assert.NoError(t, waitFor(started(web0)))
+	assert.True(t, isHeld(fleet))
+	r.finish(web0, false)
+	assert.NoError(t, waitFor(fleet))
+	assert.Equal(t, []string{"canary"}, confirmed)
+
+	// Nothing follows the last wave, so its steps start freely.
+	assert.NoError(t, waitFor(started(web2)))
+	r.finish(web1, false)
+	r.finish(web2, false)
+
+	// A wave whose pause is not confirmed stops the update before the next wave.
+	confirmed, proceed = nil, false
+	r = newPlannedRollout([]workspace.RolloutWave{
+		{Name: "canary", URNs: []string{"*::web-0"}, Pause: true},
+		{Name: "fleet", Types: []string{"aws:ec2/*"}},
+	}, confirm, 0)
+	assert.NoError(t, waitFor(started(web0)))
+	fleet = started(web1)
+	r.finish(web0, false)
+	assert.Error(t, waitFor(fleet))
+	assert.Equal(t, []string{"canary"}, confirmed)
+
+	// So does a step that fails.
+	r = newPlannedRollout([]workspace.RolloutWave{
+		{Name: "canary", URNs: []string{"*::web-0"}},
+		{Name: "fleet", Types: []string{"aws:ec2/*"}},
+	}, nil, 0)
+	assert.NoError(t, waitFor(started(web0)))
+	fleet = started(web1)
+	r.finish(web0, true)
+	assert.Error(t, waitFor(fleet))
+
+	// A wave that can never complete because every parallel step is waiting for it stops the update.
+	r = newPlannedRollout([]workspace.RolloutWave{
+		{Name: "canary", URNs: []string{"*::web-0"}},
+		{Name: "fleet", Types: []string{"aws:ec2/*"}},
+	}, nil, 1)
+	assert.Error(t, waitFor(started(web1)))
+}
@@ -0,0 +1,220 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// stepHookPayload is the JSON that a step hook is given on its standard input.
+type stepHookPayload struct {
+	When     string         `json:"when"`
+	Op       deploy.StepOp  `json:"op"`
+	URN      resource.URN   `json:"urn"`
+	Type     tokens.Type    `json:"type"`
+	Provider string         `json:"provider,omitempty"`
+	Old      *stepHookState `json:"old,omitempty"`
+	New      *stepHookState `json:"new,omitempty"`
+}
+
+// stepHookState is the state of a resource before or after a step, as given to a step hook. Secret values are masked.
+type stepHookState struct {
+	ID      resource.ID            `json:"id,omitempty"`
+	Inputs  map[string]interface{} `json:"inputs,omitempty"`
+	Outputs map[string]interface{} `json:"outputs,omitempty"`
+}
+
+func newStepHookState(s *resource.State) *stepHookState {
+	if s == nil {
+		return nil
+	}
+	maskSecrets := func(v resource.PropertyValue) (interface{}, bool) {
+		if v.IsSecret() {
+			return "[secret]", true
+		}
+		return nil, false
+	}
+	return &stepHookState{
+		ID:      s.ID,
+		Inputs:  s.Inputs.MapRepl(nil, maskSecrets),
+		Outputs: s.Outputs.MapRepl(nil, maskSecrets),
+	}
+}
+
+// stepHook is a step hook whose type and URN globs have been compiled.
+type stepHook struct {
+	workspace.StepHook
+
+	ops  map[deploy.StepOp]bool
+	typ  *regexp.Regexp
+	urn  *regexp.Regexp
+	name string
+}
+
+// stepHooks runs the hooks that match each step of an update.
+type stepHooks struct {
+	hooks []stepHook
+	dir   string
+	diag  diag.Sink
+}
+
+// newStepHooks compiles the given hooks, which are run in the given directory and report their output to the given
+// sink. It returns nil if there are no hooks.
+func newStepHooks(hooks []workspace.StepHook, dir string, sink diag.Sink) *stepHooks {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	compiled := make([]stepHook, len(hooks))
+	for i, h := range hooks {
+		compiled[i] = stepHook{StepHook: h, name: fmt.Sprintf("%s hook %q", h.When, h.Command)}
+		if len(h.Ops) > 0 {
+			compiled[i].ops = make(map[deploy.StepOp]bool)
+			for _, op := range h.Ops {
+				compiled[i].ops[deploy.StepOp(op)] = true
+			}
+		}
+		if h.Type != "" {
+			compiled[i].typ = compileStepHookGlob(h.Type)
+		}
+		if h.URN != "" {
+			compiled[i].urn = compileStepHookGlob(h.URN)
+		}
+	}
+	return &stepHooks{hooks: compiled, dir: dir, diag: sink}
+}
+
+// compileStepHookGlob compiles a glob in which `*` matches any sequence of characters, including `:` and `/`, and `?`
+// matches any single character.
+func compileStepHookGlob(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// matches returns true if the hook runs at the given time for the given step. A hook without ops runs for every step
+// that changes a resource, rather than for sames and reads.
+func (h *stepHook) matches(when string, step deploy.Step) bool {
+	if h.When != when {
+		return false
+	}
+	op := step.Op()
+	if h.ops != nil {
+		if !h.ops[op] {
+			return false
+		}
+	} else {
+		switch op {
+		case deploy.OpSame, deploy.OpRead, deploy.OpReadDiscard, deploy.OpReadReplacement, deploy.OpRefresh:
+			return false
+		}
+	}
+	if h.typ != nil && !h.typ.MatchString(string(step.Type())) {
+		return false
+	}
+	return h.urn == nil || h.urn.MatchString(string(step.URN()))
+}
+
+// run runs the hooks that match the given step at the given time, one after another, stopping at the first that
+// fails. The output of each hook is reported as diagnostics of the step's resource.
+func (hs *stepHooks) run(when string, step deploy.Step) error {
+	if hs == nil {
+		return nil
+	}
+
+	var payload []byte
+	for i := range hs.hooks {
+		h := &hs.hooks[i]
+		if !h.matches(when, step) {
+			continue
+		}
+
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(stepHookPayload{
+				When:     when,
+				Op:       step.Op(),
+				URN:      step.URN(),
+				Type:     step.Type(),
+				Provider: step.Provider(),
+				Old:      newStepHookState(step.Old()),
+				New:      newStepHookState(step.New()),
+			})
+			if err != nil {
+				return fmt.Errorf("encoding step for %s: %w", h.name, err)
+			}
+		}
+
+		logging.V(7).Infof("running %s for %s of %v", h.name, step.Op(), step.URN())
+		output, err := runHookCommand(h.Command, hs.dir, payload,
+			"PULUMI_HOOK_WHEN="+when,
+			"PULUMI_HOOK_OP="+string(step.Op()),
+			"PULUMI_HOOK_URN="+string(step.URN()),
+			"PULUMI_HOOK_TYPE="+string(step.Type()))
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", h.name, err)
+		}
+		if output != "" {
+			hs.diag.Infof(diag.RawMessage(step.URN(), output+"\n"))
+		}
+	}
+	return nil
+}
+
+// runHookCommand runs a command with the system shell in the given directory, with the given standard input and
+// environment variables in addition to the engine's own. It returns the command's combined output, which is part of
+// the error if the command fails.
+func runHookCommand(command, dir string, stdin []byte, env ...string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = bytes.NewReader(stdin)
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil && output != "" {
+		return output, fmt.Errorf("%w\n%s", err, output)
+	}
+	return output, err
+}
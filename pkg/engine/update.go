@@ -23,6 +23,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/blang/semver"
 
@@ -125,6 +126,13 @@ type UpdateOptions struct {
 	// Specific resources to update during an update operation.
 	UpdateTargets []resource.URN
 
+	// Specific resources to leave untouched during an update or refresh operation. These resources are not
+	// refreshed, updated, replaced, or deleted.
+	ExcludeTargets []resource.URN
+
+	// Property paths whose provider-reported changes are not adopted into the snapshot during a refresh.
+	RefreshIgnoreChanges []string
+
 	// true if we're allowing dependent targets to change, even if not specified in one of the above
 	// XXXTargets lists.
 	TargetDependents bool
@@ -141,6 +149,36 @@ type UpdateOptions struct {
 	// true if the engine should disable output value support.
 	DisableOutputValues bool
 
+	// true if the engine should delete default providers that are no longer referenced by any resource.
+	CleanupDefaultProviders bool
+
+	// the version of the provider plugin to load for each of these packages, whatever version the program or the
+	// state asks for.
+	ProviderVersions map[tokens.Package]*semver.Version
+
+	// true if the preview of an update should ask each provider to check that its credentials permit the operations
+	// that the update will perform. Updates that are not previewed are not checked.
+	Preflight bool
+
+	// commands to run before and after the steps of the update that match them. Hooks are not run by previews.
+	StepHooks []workspace.StepHook
+
+	// an optional callback that approves each step of the update before it is applied. A step that is not approved
+	// fails. Steps are not approved during previews.
+	ApproveStep func(step deploy.Step) (bool, error)
+
+	// the waves of a staged rollout. The steps of each wave are held back until the waves before it have completed and
+	// passed their checks. Previews are not staged.
+	RolloutWaves []workspace.RolloutWave
+
+	// an optional callback that asks whether the update may proceed once the named rollout wave has completed, for
+	// waves that pause. Without it, waves that pause stop the update.
+	ConfirmRolloutWave func(wave string) (bool, error)
+
+	// if set, the update rolls the stack back to this snapshot, which was taken before an update that failed, instead
+	// of running the program. An empty snapshot rolls back to an empty stack.
+	RollbackTo *deploy.Snapshot
+
 	// true if we should report events for steps that involve default providers.
 	reportDefaultProviderSteps bool
 
@@ -186,9 +224,14 @@ func Update(u UpdateInfo, ctx *Context, opts UpdateOptions, dryRun bool) (Resour
 	logging.V(7).Infof("*** Starting Update(preview=%v) ***", dryRun)
 	defer logging.V(7).Infof("*** Update(preview=%v) complete ***", dryRun)
 
+	sourceFunc := newUpdateSource
+	if opts.RollbackTo != nil {
+		sourceFunc = newRollbackSource
+	}
+
 	return update(ctx, info, deploymentOptions{
 		UpdateOptions: opts,
-		SourceFunc:    newUpdateSource,
+		SourceFunc:    sourceFunc,
 		Events:        emitter,
 		Diag:          newEventSink(emitter, false),
 		StatusDiag:    newEventSink(emitter, true),
@@ -360,6 +403,25 @@ func installAndLoadPolicyPlugins(plugctx *plugin.Context, d diag.Sink, policies
 	return nil
 }
 
+// loadPolicyPlugins installs and loads the required and local policy packs that run as part of a deployment.
+func loadPolicyPlugins(plugctx *plugin.Context, opts deploymentOptions, proj *workspace.Project,
+	target *deploy.Target, dryRun bool) error {
+
+	// Decrypt the configuration.
+	config, err := target.Config.Decrypt(target.Decrypter)
+	if err != nil {
+		return err
+	}
+	analyzerOpts := plugin.PolicyAnalyzerOptions{
+		Project: proj.Name.String(),
+		Stack:   target.Name.String(),
+		Config:  config,
+		DryRun:  dryRun,
+	}
+	return installAndLoadPolicyPlugins(plugctx, opts.Diag, opts.RequiredPolicies, opts.LocalPolicyPacks,
+		&analyzerOpts)
+}
+
 func newUpdateSource(
 	client deploy.BackendClient, opts deploymentOptions, proj *workspace.Project, pwd, main string,
 	target *deploy.Target, plugctx *plugin.Context, dryRun bool) (deploy.Source, error) {
@@ -386,19 +448,7 @@ func newUpdateSource(
 	// Step 2: Install and load policy plugins.
 	//
 
-	// Decrypt the configuration.
-	config, err := target.Config.Decrypt(target.Decrypter)
-	if err != nil {
-		return nil, err
-	}
-	analyzerOpts := plugin.PolicyAnalyzerOptions{
-		Project: proj.Name.String(),
-		Stack:   target.Name.String(),
-		Config:  config,
-		DryRun:  dryRun,
-	}
-	if err := installAndLoadPolicyPlugins(plugctx, opts.Diag, opts.RequiredPolicies, opts.LocalPolicyPacks,
-		&analyzerOpts); err != nil {
+	if err := loadPolicyPlugins(plugctx, opts, proj, target, dryRun); err != nil {
 		return nil, err
 	}
 
@@ -422,25 +472,22 @@ func newUpdateSource(
 func update(ctx *Context, info *deploymentContext, opts deploymentOptions,
 	preview bool) (ResourceChanges, result.Result) {
 
-	// Refresh and Import do not execute Policy Packs.
-	policies := map[string]string{}
-	if !opts.isRefresh && !opts.isImport {
-		for _, p := range opts.RequiredPolicies {
-			policies[p.Name()] = p.Version()
-		}
-		for _, pack := range opts.LocalPolicyPacks {
-			path := abbreviateFilePath(pack.Path)
-			packName := fmt.Sprintf("%s (%s)", pack.Name, path)
-			policies[packName] = "(local)"
-		}
-	}
+	policies := deploymentPolicies(opts)
 
-	// Create an appropriate set of event listeners.
+	// Create an appropriate set of event listeners. Preflight checks record the operations of the preview.
 	var actions runActions
-	if preview {
+	var checks *preflightActions
+	if preview && opts.Preflight {
+		checks = newPreflightActions(opts)
+		actions = checks
+	} else if preview {
 		actions = newPreviewActions(opts)
 	} else {
-		actions = newUpdateActions(ctx, info.Update, opts)
+		updateActions := newUpdateActions(ctx, info.Update, opts)
+		if res := updateActions.rollout.plan(ctx, info, opts); res != nil {
+			return nil, res
+		}
+		actions = updateActions
 	}
 
 	deployment, err := newDeployment(ctx, info, opts, preview)
@@ -449,7 +496,29 @@ func update(ctx *Context, info *deploymentContext, opts deploymentOptions,
 	}
 	defer contract.IgnoreClose(deployment)
 
-	return deployment.run(ctx, actions, policies, preview)
+	changes, res := deployment.run(ctx, actions, policies, preview)
+	if res == nil && checks != nil {
+		// Check that the providers will permit the update before it changes anything.
+		res = preflight(deployment.Deployment, checks, opts.Diag)
+	}
+	return changes, res
+}
+
+// deploymentPolicies returns the names and versions of the policy packs that will run as part of a deployment.
+func deploymentPolicies(opts deploymentOptions) map[string]string {
+	// Refresh and Import do not execute Policy Packs.
+	policies := map[string]string{}
+	if !opts.isRefresh && !opts.isImport {
+		for _, p := range opts.RequiredPolicies {
+			policies[p.Name()] = p.Version()
+		}
+		for _, pack := range opts.LocalPolicyPacks {
+			path := abbreviateFilePath(pack.Path)
+			packName := fmt.Sprintf("%s (%s)", pack.Name, path)
+			policies[packName] = "(local)"
+		}
+	}
+	return policies
 }
 
 // abbreviateFilePath is a helper function that cleans up and shortens a provided file path.
@@ -484,27 +553,62 @@ type updateActions struct {
 	Steps   int
 	Ops     map[deploy.StepOp]int
 	Seen    map[resource.URN]deploy.Step
+	Started map[deploy.Step]time.Time
 	MapLock sync.Mutex
 	Update  UpdateInfo
 	Opts    deploymentOptions
 
+	hooks        *stepHooks
+	rollout      *rollout
 	maybeCorrupt bool
 }
 
 func newUpdateActions(context *Context, u UpdateInfo, opts deploymentOptions) *updateActions {
+	parallel := 0
+	if o := (deploy.Options{Parallel: opts.Parallel}); !o.InfiniteParallelism() {
+		parallel = o.DegreeOfParallelism()
+	}
 	return &updateActions{
 		Context: context,
 		Ops:     make(map[deploy.StepOp]int),
 		Seen:    make(map[resource.URN]deploy.Step),
+		Started: make(map[deploy.Step]time.Time),
 		Update:  u,
 		Opts:    opts,
+		hooks:   newStepHooks(opts.StepHooks, u.GetRoot(), opts.Diag),
+		rollout: newRollout(opts.RolloutWaves, u.GetRoot(), opts.Diag, opts.ConfirmRolloutWave,
+			context.Cancel.Canceled(), parallel),
 	}
 }
 
 func (acts *updateActions) OnResourceStepPre(step deploy.Step) (interface{}, error) {
+	// Wait for the step's rollout wave, if any, before anything else.
+	if err := acts.rollout.start(step); err != nil {
+		return nil, err
+	}
+
+	if acts.Opts.ApproveStep != nil {
+		approved, err := acts.Opts.ApproveStep(step)
+		if err != nil {
+			acts.rollout.finish(step, true)
+			return nil, err
+		}
+		if !approved {
+			acts.rollout.finish(step, true)
+			return nil, fmt.Errorf("the %s of %s was not approved", step.Op(), step.URN())
+		}
+	}
+
+	// A hook that fails before a step fails the step before it has begun.
+	if err := acts.hooks.run(workspace.StepHookBefore, step); err != nil {
+		acts.rollout.finish(step, true)
+		return nil, err
+	}
+
 	// Ensure we've marked this step as observed.
 	acts.MapLock.Lock()
 	acts.Seen[step.URN()] = step
+	acts.Started[step] = time.Now()
 	acts.MapLock.Unlock()
 
 	// Skip reporting if necessary.
@@ -513,15 +617,27 @@ func (acts *updateActions) OnResourceStepPre(step deploy.Step) (interface{}, err
 	}
 
 	// Inform the snapshot service that we are about to perform a step.
-	return acts.Context.SnapshotManager.BeginMutation(step)
+	mutation, err := acts.Context.SnapshotManager.BeginMutation(step)
+	if err != nil {
+		acts.rollout.finish(step, true)
+		return nil, err
+	}
+	return mutation, nil
 }
 
 func (acts *updateActions) OnResourceStepPost(
 	ctx interface{}, step deploy.Step,
 	status resource.Status, err error) error {
 
+	acts.rollout.finish(step, err != nil)
+
 	acts.MapLock.Lock()
 	assertSeen(acts.Seen, step)
+	var duration time.Duration
+	if started, ok := acts.Started[step]; ok {
+		duration = time.Since(started)
+		delete(acts.Started, step)
+	}
 	acts.MapLock.Unlock()
 
 	// If we've already been terminated, exit without writing the checkpoint. We explicitly want to leave the
@@ -546,7 +662,7 @@ func (acts *updateActions) OnResourceStepPost(
 		// Issue a true, bonafide error.
 		acts.Opts.Diag.Errorf(diag.GetResourceOperationFailedError(errorURN), err)
 		if reportStep {
-			acts.Opts.Events.resourceOperationFailedEvent(step, status, acts.Steps, acts.Opts.Debug)
+			acts.Opts.Events.resourceOperationFailedEvent(step, status, acts.Steps, acts.Opts.Debug, duration)
 		}
 	} else if reportStep {
 		op, record := step.Op(), step.Logical()
@@ -571,7 +687,7 @@ func (acts *updateActions) OnResourceStepPost(
 		// not show outputs for component resources at this point: any that exist must be from a previous execution of
 		// the Pulumi program, as component resources only report outputs via calls to RegisterResourceOutputs.
 		if step.Res().Custom || acts.Opts.Refresh && step.Op() == deploy.OpRefresh {
-			acts.Opts.Events.resourceOutputsEvent(op, step, false /*planning*/, acts.Opts.Debug)
+			acts.Opts.Events.resourceOutputsEvent(op, step, false /*planning*/, acts.Opts.Debug, duration)
 		}
 	}
 
@@ -603,7 +719,15 @@ func (acts *updateActions) OnResourceStepPost(
 	// Write out the current snapshot. Note that even if a failure has occurred, we should still have a
 	// safe checkpoint.  Note that any error that occurs when writing the checkpoint trumps the error
 	// reported above.
-	return ctx.(SnapshotMutation).End(step, err == nil || status == resource.StatusPartialFailure)
+	if endErr := ctx.(SnapshotMutation).End(step, err == nil || status == resource.StatusPartialFailure); endErr != nil {
+		return endErr
+	}
+
+	// Hooks only run after steps that succeed.
+	if err != nil {
+		return nil
+	}
+	return acts.hooks.run(workspace.StepHookAfter, step)
 }
 
 func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
@@ -613,7 +737,7 @@ func (acts *updateActions) OnResourceOutputs(step deploy.Step) error {
 
 	// Skip reporting if necessary.
 	if shouldReportStep(step, acts.Opts) {
-		acts.Opts.Events.resourceOutputsEvent(step.Op(), step, false /*planning*/, acts.Opts.Debug)
+		acts.Opts.Events.resourceOutputsEvent(step.Op(), step, false /*planning*/, acts.Opts.Debug, 0)
 	}
 
 	// There's a chance there are new outputs that weren't written out last time.
@@ -716,7 +840,7 @@ func (acts *previewActions) OnResourceStepPost(ctx interface{},
 			acts.MapLock.Unlock()
 		}
 
-		acts.Opts.Events.resourceOutputsEvent(op, step, true /*planning*/, acts.Opts.Debug)
+		acts.Opts.Events.resourceOutputsEvent(op, step, true /*planning*/, acts.Opts.Debug, 0)
 	}
 
 	return nil
@@ -733,7 +857,7 @@ func (acts *previewActions) OnResourceOutputs(step deploy.Step) error {
 	}
 
 	// Print the resource outputs separately.
-	acts.Opts.Events.resourceOutputsEvent(step.Op(), step, true /*planning*/, acts.Opts.Debug)
+	acts.Opts.Events.resourceOutputsEvent(step.Op(), step, true /*planning*/, acts.Opts.Debug, 0)
 
 	return nil
 }
@@ -0,0 +1,109 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mermaidconv converts a resource graph into a Mermaid flowchart.  Mermaid diagrams can be embedded directly in
+// Markdown documents on many sites, which makes them useful for documentation.  Please see
+// https://mermaid-js.github.io/mermaid/#/flowchart for a description of the flowchart syntax.
+package mermaidconv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/graph"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// Print prints a resource graph.
+func Print(g graph.Graph, w io.Writer) error {
+	// As in dotconv, we ignore write errors until the end, opting instead to return the result of flushing the buffer.
+	b := bufio.NewWriter(w)
+	if _, err := b.WriteString("graph TD\n"); err != nil {
+		return err
+	}
+
+	// Initialize the frontier with unvisited graph vertices.
+	queued := make(map[graph.Vertex]bool)
+	frontier := make([]graph.Vertex, 0, len(g.Roots()))
+	for _, root := range g.Roots() {
+		to := root.To()
+		queued[to] = true
+		frontier = append(frontier, to)
+	}
+
+	c := 0
+	ids := make(map[graph.Vertex]string)
+	getID := func(v graph.Vertex) string {
+		if id, has := ids[v]; has {
+			return id
+		}
+		id := "Resource" + strconv.Itoa(c)
+		c++
+		ids[v] = id
+		return id
+	}
+
+	// Mermaid styles links by their index in the order in which they are declared.
+	var linkStyles []string
+	links := 0
+
+	indent := "    "
+	emitted := make(map[graph.Vertex]bool)
+	for len(frontier) > 0 {
+		// Dequeue the head of the frontier.
+		v := frontier[0]
+		frontier = frontier[1:]
+		contract.Assert(!emitted[v])
+		emitted[v] = true
+
+		id := getID(v)
+		if label := v.Label(); label != "" {
+			b.WriteString(fmt.Sprintf("%s%s[\"%s\"]\n", indent, id, escape(label)))
+		} else {
+			b.WriteString(fmt.Sprintf("%s%s\n", indent, id))
+		}
+
+		// Now print out each outgoing edge, adding the vertices we haven't seen to the frontier.
+		for _, out := range v.Outs() {
+			to := out.To()
+			if label := out.Label(); label != "" {
+				b.WriteString(fmt.Sprintf("%s%s -->|\"%s\"| %s\n", indent, id, escape(label), getID(to)))
+			} else {
+				b.WriteString(fmt.Sprintf("%s%s --> %s\n", indent, id, getID(to)))
+			}
+			if color := out.Color(); color != "" {
+				linkStyles = append(linkStyles, fmt.Sprintf("%slinkStyle %d stroke:%s\n", indent, links, color))
+			}
+			links++
+
+			if _, q := queued[to]; !q {
+				queued[to] = true
+				frontier = append(frontier, to)
+			}
+		}
+	}
+
+	for _, style := range linkStyles {
+		b.WriteString(style)
+	}
+	return b.Flush()
+}
+
+// escape replaces the characters that may not appear in a quoted Mermaid label with their entity codes.
+func escape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
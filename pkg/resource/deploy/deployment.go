@@ -57,11 +57,15 @@ type Options struct {
 	ReplaceTargets            []resource.URN // Specific resources to replace.
 	DestroyTargets            []resource.URN // Specific resources to destroy.
 	UpdateTargets             []resource.URN // Specific resources to update.
+	ExcludeTargets            []resource.URN // Specific resources to leave untouched during an update or refresh.
+	RefreshIgnoreChanges      []string       // Property paths whose changes are not adopted during a refresh.
 	TargetDependents          bool           // true if we're allowing things to proceed, even with unspecified targets
 	TrustDependencies         bool           // whether or not to trust the resource dependency graph.
 	UseLegacyDiff             bool           // whether or not to use legacy diffing behavior.
 	DisableResourceReferences bool           // true to disable resource reference support.
 	DisableOutputValues       bool           // true to disable output value support.
+	// true to delete default providers that are no longer referenced at the end of an update.
+	CleanupDefaultProviders bool
 }
 
 // DegreeOfParallelism returns the degree of parallelism that should be used during the
@@ -369,6 +373,11 @@ func (d *Deployment) GetProvider(ref providers.Reference) (plugin.Provider, bool
 	return d.providers.GetProvider(ref)
 }
 
+// ProviderConfigureTimes returns the time taken to configure each of the deployment's providers.
+func (d *Deployment) ProviderConfigureTimes() []providers.ConfigureTime {
+	return d.providers.ConfigureTimes()
+}
+
 // generateURN generates a resource's URN from its parent, type, and name under the scope of the deployment's stack and
 // project.
 func (d *Deployment) generateURN(parent resource.URN, ty tokens.Type, name tokens.QName) resource.URN {
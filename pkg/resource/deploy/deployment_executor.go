@@ -329,22 +329,19 @@ func (ex *deploymentExecutor) performDeletes(
 		return res
 	}
 
-	deletes := ex.stepGen.ScheduleDeletes(deleteSteps)
-
-	// ScheduleDeletes gives us a list of lists of steps. Each list of steps can safely be executed
-	// in parallel, but each list must execute completes before the next list can safely begin
-	// executing.
-	//
-	// This is not "true" delete parallelism, since there may be resources that could safely begin
-	// deleting but we won't until the previous set of deletes fully completes. This approximation
-	// is conservative, but correct.
-	for _, antichain := range deletes {
-		logging.V(4).Infof("deploymentExecutor.Execute(...): beginning delete antichain")
-		tok := ex.stepExec.ExecuteParallel(antichain)
-		tok.Wait(ctx)
-		logging.V(4).Infof("deploymentExecutor.Execute(...): antichain complete")
+	// Default providers that nothing references any longer would otherwise linger in the snapshot, e.g. after a
+	// targeted update or a provider version change. Clean them up if the user has asked us to.
+	if destroyTargetsOpt == nil && ex.stepGen.opts.CleanupDefaultProviders && !ex.stepGen.Errored() {
+		deleteSteps = append(deleteSteps, ex.stepGen.GenerateStaleDefaultProviderDeletes(deleteSteps)...)
 	}
 
+	// Each delete begins as soon as the deletes of the resources that depend on its resource have completed, so
+	// independent subtrees of the dependency graph are deleted concurrently, up to the degree of parallelism.
+	logging.V(4).Infof("deploymentExecutor.Execute(...): beginning deletes")
+	tok := ex.stepExec.ExecuteDeletes(ex.stepGen.ScheduleDeletes(deleteSteps))
+	tok.Wait(ctx)
+	logging.V(4).Infof("deploymentExecutor.Execute(...): deletes complete")
+
 	// After executing targeted deletes, we may now have resources that depend on the resource that
 	// were deleted.  Go through and clean things up accordingly for them.
 	if targetsOpt != nil {
@@ -405,17 +402,14 @@ func (ex *deploymentExecutor) retirePendingDeletes(callerCtx context.Context, op
 	ctx, cancel := context.WithCancel(callerCtx)
 
 	stepExec := newStepExecutor(ctx, cancel, ex.deployment, opts, preview, false)
-	antichains := ex.stepGen.ScheduleDeletes(steps)
-	// Submit the deletes for execution and wait for them all to retire.
-	for _, antichain := range antichains {
-		for _, step := range antichain {
-			ex.deployment.Ctx().StatusDiag.Infof(diag.RawMessage(step.URN(), "completing deletion from previous update"))
-		}
-
-		tok := stepExec.ExecuteParallel(antichain)
-		tok.Wait(ctx)
+	for _, step := range steps {
+		ex.deployment.Ctx().StatusDiag.Infof(diag.RawMessage(step.URN(), "completing deletion from previous update"))
 	}
 
+	// Submit the deletes for execution and wait for them all to retire.
+	tok := stepExec.ExecuteDeletes(ex.stepGen.ScheduleDeletes(steps))
+	tok.Wait(ctx)
+
 	stepExec.SignalCompletion()
 	stepExec.WaitForCompletion()
 
@@ -485,11 +479,31 @@ func (ex *deploymentExecutor) refresh(callerCtx context.Context, opts Options, p
 	// If the user did not provide any --target's, create a refresh step for each resource in the
 	// old snapshot.  If they did provider --target's then only create refresh steps for those
 	// specific targets.
+	// If dependents were requested, also refresh every resource that depends upon a target, so that resources whose
+	// inputs derive from a target's outputs are refreshed along with it.
+	if targetMapOpt != nil && opts.TargetDependents {
+		dg := graph.NewDependencyGraph(prev.Resources)
+		for _, res := range prev.Resources {
+			if !targetMapOpt[res.URN] {
+				continue
+			}
+			for _, dep := range dg.DependingOn(res, nil, false) {
+				logging.V(7).Infof("Refresh adding dependent target '%v' of '%v'", dep.URN, res.URN)
+				targetMapOpt[dep.URN] = true
+			}
+		}
+	}
+
+	excludeTargets := createTargetMap(opts.ExcludeTargets)
 	steps := []Step{}
 	resourceToStep := map[*resource.State]Step{}
 	for _, res := range prev.Resources {
+		if excludeTargets[res.URN] {
+			logging.V(7).Infof("Refresh skipping excluded resource '%v'", res.URN)
+			continue
+		}
 		if targetMapOpt == nil || targetMapOpt[res.URN] {
-			step := NewRefreshStep(ex.deployment, res, nil)
+			step := NewRefreshStep(ex.deployment, res, opts.RefreshIgnoreChanges, nil)
 			steps = append(steps, step)
 			resourceToStep[res] = step
 		}
@@ -101,6 +101,7 @@ type ResourceOptions struct {
 	SupportsPartialValues *bool
 	Remote                bool
 	Providers             map[string]string
+	DeletedWith           resource.URN
 
 	DisableSecrets            bool
 	DisableResourceReferences bool
@@ -188,6 +189,7 @@ func (rm *ResourceMonitor) RegisterResource(t tokens.Type, name string, custom b
 		Remote:                     opts.Remote,
 		ReplaceOnChanges:           opts.ReplaceOnChanges,
 		Providers:                  opts.Providers,
+		DeletedWith:                string(opts.DeletedWith),
 	}
 
 	// submit request
@@ -292,7 +292,7 @@ func (i *importer) importResources(ctx context.Context) result.Result {
 		return res
 	}
 
-	// Create a step per resource to import and execute them in parallel. If there are duplicates, fail the import.
+	// Create a step per resource to import. If there are duplicates, fail the import.
 	urns := map[resource.URN]struct{}{}
 	steps := make([]Step, 0, len(i.deployment.imports))
 	for _, imp := range i.deployment.imports {
@@ -337,8 +337,12 @@ func (i *importer) importResources(ctx context.Context) result.Result {
 		steps = append(steps, newImportDeploymentStep(i.deployment, new))
 	}
 
-	if !i.executeParallel(ctx, steps...) {
-		return nil
+	// Execute the steps in waves so that each resource is imported after its parent, if its parent is also being
+	// imported. The resources within each wave are imported in parallel.
+	for _, wave := range importWaves(steps) {
+		if !i.executeParallel(ctx, wave...) {
+			return nil
+		}
 	}
 
 	if createdStack {
@@ -347,3 +351,37 @@ func (i *importer) importResources(ctx context.Context) result.Result {
 
 	return nil
 }
+
+// importWaves partitions import steps into waves such that each step appears in a later wave than the step that
+// imports its parent, if any. The order of the steps within each wave is preserved.
+func importWaves(steps []Step) [][]Step {
+	parents := make(map[resource.URN]resource.URN, len(steps))
+	for _, step := range steps {
+		parents[step.URN()] = step.New().Parent
+	}
+
+	depths := make(map[resource.URN]int, len(steps))
+	var depthOf func(urn resource.URN) int
+	depthOf = func(urn resource.URN) int {
+		if d, ok := depths[urn]; ok {
+			return d
+		}
+		depths[urn] = 0 // guard against cycles
+		if parent := parents[urn]; parent != "" {
+			if _, ok := parents[parent]; ok {
+				depths[urn] = depthOf(parent) + 1
+			}
+		}
+		return depths[urn]
+	}
+
+	var waves [][]Step
+	for _, step := range steps {
+		d := depthOf(step.URN())
+		for len(waves) <= d {
+			waves = append(waves, nil)
+		}
+		waves[d] = append(waves[d], step)
+	}
+	return waves
+}
@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/blang/semver"
 	uuid "github.com/gofrs/uuid"
@@ -67,6 +68,16 @@ type Registry struct {
 	builtins  plugin.Provider
 	aliases   map[resource.URN]resource.URN
 	m         sync.RWMutex
+
+	configureTimes     []ConfigureTime
+	configureTimesLock sync.Mutex
+}
+
+// ConfigureTime is the wall-clock time taken to configure a provider.
+type ConfigureTime struct {
+	URN      resource.URN
+	Duration time.Duration
+	Failed   bool
 }
 
 var _ plugin.Provider = (*Registry)(nil)
@@ -84,6 +95,9 @@ func loadProvider(pkg tokens.Package, version *semver.Version, host plugin.Host,
 // NewRegistry creates a new provider registry using the given host and old resources. Each provider present in the old
 // resources will be loaded, configured, and added to the returned registry under its reference. If any provider is not
 // loadable/configurable or has an invalid ID, this function returns an error.
+//
+// Providers are loaded one at a time, but configured concurrently: configuration often involves network round trips
+// (e.g. to validate credentials), and there is no reason for one provider to wait on another.
 func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool,
 	builtins plugin.Provider) (*Registry, error) {
 
@@ -95,6 +109,14 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool,
 		aliases:   make(map[resource.URN]resource.URN),
 	}
 
+	type loadedProvider struct {
+		res      *resource.State
+		ref      Reference
+		provider plugin.Provider
+	}
+
+	var loaded []loadedProvider
+	seen := make(map[Reference]bool)
 	for _, res := range prev {
 		urn := res.URN
 		if !IsProviderType(urn.Type()) {
@@ -109,13 +131,14 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool,
 
 		// Ensure that we have no duplicates.
 		ref := mustNewReference(urn, res.ID)
-		if _, ok := r.providers[ref]; ok {
+		if seen[ref] {
 			return nil, fmt.Errorf("duplicate provider found in old state: '%v'", ref)
 		}
+		seen[ref] = true
 
 		providerPkg := GetProviderPackage(urn.Type())
 
-		// Parse the provider version, then load, configure, and register the provider.
+		// Parse the provider version, then load the provider.
 		version, err := GetProviderVersion(res.Inputs)
 		if err != nil {
 			return nil, fmt.Errorf("could not parse version for %v provider '%v': %v", providerPkg, urn, err)
@@ -127,19 +150,66 @@ func NewRegistry(host plugin.Host, prev []*resource.State, isPreview bool,
 		if provider == nil {
 			return nil, fmt.Errorf("could not find plugin for %v provider '%v' at version %v", providerPkg, urn, version)
 		}
-		if err := provider.Configure(res.Inputs); err != nil {
-			closeErr := host.CloseProvider(provider)
-			contract.IgnoreError(closeErr)
-			return nil, fmt.Errorf("could not configure provider '%v': %v", urn, err)
+
+		loaded = append(loaded, loadedProvider{res: res, ref: ref, provider: provider})
+	}
+
+	// Now configure all of the loaded providers in parallel.
+	start := time.Now()
+	errs := make([]error, len(loaded))
+	var wg sync.WaitGroup
+	for i := range loaded {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			l := loaded[i]
+			if err := r.configure(l.res.URN, l.provider, l.res.Inputs); err != nil {
+				closeErr := host.CloseProvider(l.provider)
+				contract.IgnoreError(closeErr)
+				errs[i] = fmt.Errorf("could not configure provider '%v': %v", l.res.URN, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	logging.V(4).Infof("configured %d provider(s) from the old state in %v", len(loaded), time.Since(start))
+
+	// Report the first failure in snapshot order, so that errors are deterministic.
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		logging.V(7).Infof("loaded provider %v", ref)
-		r.providers[ref] = provider
+	for _, l := range loaded {
+		logging.V(7).Infof("loaded provider %v", l.ref)
+		r.providers[l.ref] = l.provider
 	}
 
 	return r, nil
 }
 
+// configure configures the provider with the given URN and records how long it took.
+func (r *Registry) configure(urn resource.URN, provider plugin.Provider, inputs resource.PropertyMap) error {
+	start := time.Now()
+	err := provider.Configure(inputs)
+	duration := time.Since(start)
+	logging.V(7).Infof("configured provider %v in %v", urn, duration)
+
+	r.configureTimesLock.Lock()
+	defer r.configureTimesLock.Unlock()
+	r.configureTimes = append(r.configureTimes, ConfigureTime{URN: urn, Duration: duration, Failed: err != nil})
+	return err
+}
+
+// ConfigureTimes returns the time taken by each provider that the registry has configured, in the order in which their
+// configuration finished.
+func (r *Registry) ConfigureTimes() []ConfigureTime {
+	r.configureTimesLock.Lock()
+	defer r.configureTimesLock.Unlock()
+	return append([]ConfigureTime(nil), r.configureTimes...)
+}
+
 // GetProvider returns the provider plugin that is currently registered under the given reference, if any.
 func (r *Registry) GetProvider(ref Reference) (plugin.Provider, bool) {
 	r.m.RLock()
@@ -348,7 +418,7 @@ func (r *Registry) Create(urn resource.URN, news resource.PropertyMap, timeout f
 	provider, ok := r.GetProvider(mustNewReference(urn, UnknownID))
 	contract.Assertf(ok, "'Check' must be called before 'Create' (%v)", urn)
 
-	if err := provider.Configure(news); err != nil {
+	if err := r.configure(urn, provider, news); err != nil {
 		return "", nil, resource.StatusOK, err
 	}
 
@@ -381,7 +451,7 @@ func (r *Registry) Update(urn resource.URN, id resource.ID, olds, news resource.
 	provider, ok := r.GetProvider(mustNewReference(urn, UnknownID))
 	contract.Assertf(ok, "'Check' and 'Diff' must be called before 'Update' (%v)", urn)
 
-	if err := provider.Configure(news); err != nil {
+	if err := r.configure(urn, provider, news); err != nil {
 		return nil, resource.StatusUnknown, err
 	}
 
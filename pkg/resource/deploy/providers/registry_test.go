@@ -17,7 +17,9 @@ package providers
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/blang/semver"
 
@@ -406,6 +408,70 @@ func TestNewRegistryOldStateDuplicates(t *testing.T) {
 	assert.Nil(t, r)
 }
 
+func TestNewRegistryOldStateConfiguresInParallel(t *testing.T) {
+	olds := []*resource.State{
+		newProviderState("pkgA", "a", "id1", false, nil),
+		newProviderState("pkgA", "b", "id2", false, nil),
+		newProviderState("pkgA", "c", "id3", false, nil),
+	}
+
+	// Each Configure call waits until all of the providers have begun configuring, which can only happen if they are
+	// configured concurrently.
+	var m sync.Mutex
+	started := 0
+	allStarted := make(chan struct{})
+	configure := func(resource.PropertyMap) error {
+		m.Lock()
+		started++
+		if started == len(olds) {
+			close(allStarted)
+		}
+		m.Unlock()
+
+		select {
+		case <-allStarted:
+			return nil
+		case <-time.After(10 * time.Second):
+			return errors.New("providers were not configured concurrently")
+		}
+	}
+	loaders := []*providerLoader{
+		newSimpleLoader(t, "pkgA", "", configure),
+	}
+	host := newPluginHost(t, loaders)
+
+	r, err := NewRegistry(host, olds, false, nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, r)
+	assert.Equal(t, len(olds), len(r.providers))
+
+	// The time taken to configure each provider is recorded.
+	times := r.ConfigureTimes()
+	assert.Len(t, times, len(olds))
+	for _, configured := range times {
+		assert.False(t, configured.Failed)
+	}
+}
+
+func TestNewRegistryOldStateConfigureFailure(t *testing.T) {
+	olds := []*resource.State{
+		newProviderState("pkgA", "a", "id1", false, nil),
+		newProviderState("pkgB", "a", "id1", false, nil),
+	}
+	loaders := []*providerLoader{
+		newSimpleLoader(t, "pkgA", "", nil),
+		newSimpleLoader(t, "pkgB", "", func(resource.PropertyMap) error {
+			return errors.New("bad credentials")
+		}),
+	}
+	host := newPluginHost(t, loaders)
+
+	r, err := NewRegistry(host, olds, false, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "bad credentials")
+	assert.Nil(t, r)
+}
+
 func TestCRUD(t *testing.T) {
 	olds := []*resource.State{
 		newProviderState("pkgA", "a", "id1", false, nil),
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
 	"github.com/pulumi/pulumi/pkg/v3/secrets"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -117,6 +118,42 @@ func (snap *Snapshot) NormalizeURNReferences() error {
 	return nil
 }
 
+// IntegrityViolationKind identifies the invariant that an IntegrityViolation breaks.
+type IntegrityViolationKind string
+
+const (
+	// ViolationMagicMismatch indicates that the snapshot's magic cookie does not match its manifest.
+	ViolationMagicMismatch IntegrityViolationKind = "magic-mismatch"
+	// ViolationInvalidProvider indicates that a provider resource is not referenceable.
+	ViolationInvalidProvider IntegrityViolationKind = "invalid-provider"
+	// ViolationUnknownProvider indicates that a resource refers to a provider that does not precede it.
+	ViolationUnknownProvider IntegrityViolationKind = "unknown-provider"
+	// ViolationParentOrder indicates that a resource's parent comes after it.
+	ViolationParentOrder IntegrityViolationKind = "parent-order"
+	// ViolationMissingParent indicates that a resource's parent is not in the snapshot.
+	ViolationMissingParent IntegrityViolationKind = "missing-parent"
+	// ViolationDependencyOrder indicates that one of a resource's dependencies comes after it.
+	ViolationDependencyOrder IntegrityViolationKind = "dependency-order"
+	// ViolationMissingDependency indicates that one of a resource's dependencies is not in the snapshot.
+	ViolationMissingDependency IntegrityViolationKind = "missing-dependency"
+	// ViolationDuplicateURN indicates that more than one resource with the same URN is not pending deletion.
+	ViolationDuplicateURN IntegrityViolationKind = "duplicate-urn"
+	// ViolationDependencyCycle indicates that resources depend upon one another in a cycle.
+	ViolationDependencyCycle IntegrityViolationKind = "dependency-cycle"
+)
+
+// IntegrityViolation describes a single way in which a snapshot is not well-formed.
+type IntegrityViolation struct {
+	Kind    IntegrityViolationKind `json:"kind"`
+	URN     resource.URN           `json:"urn,omitempty"`
+	Message string                 `json:"message"`
+}
+
+// Error returns the violation's message, so that a violation may be used as an error.
+func (v IntegrityViolation) Error() string {
+	return v.Message
+}
+
 // VerifyIntegrity checks a snapshot to ensure it is well-formed.  Because of the cost of this operation,
 // integrity verification is only performed on demand, and not automatically during snapshot construction.
 //
@@ -127,71 +164,108 @@ func (snap *Snapshot) NormalizeURNReferences() error {
 //  4. Dependents must precede their dependencies in the resource list
 //  5. For every URN in the snapshot, there must be at most one resource with that URN that is not pending deletion
 //  6. The magic manifest number should change every time the snapshot is mutated
+//  7. Resources must not depend upon one another in a cycle
+//
+// Only the first violation found is returned. Use IntegrityViolations to find all of them.
 func (snap *Snapshot) VerifyIntegrity() error {
-	if snap != nil {
-		// Ensure the magic cookie checks out.
-		if snap.Manifest.Magic != snap.Manifest.NewMagic() {
-			return fmt.Errorf("magic cookie mismatch; possible tampering/corruption detected")
-		}
+	if violations := snap.IntegrityViolations(); len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// IntegrityViolations checks the same invariants as VerifyIntegrity, but rather than stopping at the first problem
+// it returns every violation found, in snapshot order.
+func (snap *Snapshot) IntegrityViolations() []IntegrityViolation {
+	if snap == nil {
+		return nil
+	}
+
+	var violations []IntegrityViolation
+	violate := func(kind IntegrityViolationKind, urn resource.URN, format string, args ...interface{}) {
+		violations = append(violations, IntegrityViolation{
+			Kind:    kind,
+			URN:     urn,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Ensure the magic cookie checks out.
+	if snap.Manifest.Magic != snap.Manifest.NewMagic() {
+		violate(ViolationMagicMismatch, "", "magic cookie mismatch; possible tampering/corruption detected")
+	}
+
+	// Now check the resources.  For now, we just verify that parents come before children, and that there aren't
+	// any duplicate URNs.
+	urns := make(map[resource.URN]*resource.State)
+	provs := make(map[providers.Reference]struct{})
+	for i, state := range snap.Resources {
+		urn := state.URN
 
-		// Now check the resources.  For now, we just verify that parents come before children, and that there aren't
-		// any duplicate URNs.
-		urns := make(map[resource.URN]*resource.State)
-		provs := make(map[providers.Reference]struct{})
-		for i, state := range snap.Resources {
-			urn := state.URN
-
-			if providers.IsProviderType(state.Type) {
-				ref, err := providers.NewReference(urn, state.ID)
-				if err != nil {
-					return fmt.Errorf("provider %s is not referenceable: %v", urn, err)
+		// comesLater returns true if the given URN belongs to a resource later in the snapshot. This is only used to
+		// give better error messages, as neither case should ever happen.
+		comesLater := func(target resource.URN) bool {
+			for _, other := range snap.Resources[i+1:] {
+				if other.URN == target {
+					return true
 				}
+			}
+			return false
+		}
+
+		if providers.IsProviderType(state.Type) {
+			ref, err := providers.NewReference(urn, state.ID)
+			if err != nil {
+				violate(ViolationInvalidProvider, urn, "provider %s is not referenceable: %v", urn, err)
+			} else {
 				provs[ref] = struct{}{}
 			}
-			if provider := state.Provider; provider != "" {
-				ref, err := providers.ParseReference(provider)
-				if err != nil {
-					return fmt.Errorf("failed to parse provider reference for resource %s: %v", urn, err)
-				}
-				if _, has := provs[ref]; !has {
-					return fmt.Errorf("resource %s refers to unknown provider %s", urn, ref)
-				}
+		}
+		if provider := state.Provider; provider != "" {
+			ref, err := providers.ParseReference(provider)
+			if err != nil {
+				violate(ViolationUnknownProvider, urn,
+					"failed to parse provider reference for resource %s: %v", urn, err)
+			} else if _, has := provs[ref]; !has {
+				violate(ViolationUnknownProvider, urn, "resource %s refers to unknown provider %s", urn, ref)
 			}
+		}
 
-			if par := state.Parent; par != "" {
-				if _, has := urns[par]; !has {
-					// The parent isn't there; to give a good error message, see whether it's missing entirely, or
-					// whether it comes later in the snapshot (neither of which should ever happen).
-					for _, other := range snap.Resources[i+1:] {
-						if other.URN == par {
-							return fmt.Errorf("child resource %s's parent %s comes after it", urn, par)
-						}
-					}
-					return fmt.Errorf("child resource %s refers to missing parent %s", urn, par)
+		if par := state.Parent; par != "" {
+			if _, has := urns[par]; !has {
+				if comesLater(par) {
+					violate(ViolationParentOrder, urn, "child resource %s's parent %s comes after it", urn, par)
+				} else {
+					violate(ViolationMissingParent, urn, "child resource %s refers to missing parent %s", urn, par)
 				}
 			}
+		}
 
-			for _, dep := range state.Dependencies {
-				if _, has := urns[dep]; !has {
-					// same as above - doing this for better error messages
-					for _, other := range snap.Resources[i+1:] {
-						if other.URN == dep {
-							return fmt.Errorf("resource %s's dependency %s comes after it", urn, other.URN)
-						}
-					}
-
-					return fmt.Errorf("resource %s dependency %s refers to missing resource", urn, dep)
+		for _, dep := range state.Dependencies {
+			if _, has := urns[dep]; !has {
+				if comesLater(dep) {
+					violate(ViolationDependencyOrder, urn, "resource %s's dependency %s comes after it", urn, dep)
+				} else {
+					violate(ViolationMissingDependency, urn,
+						"resource %s dependency %s refers to missing resource", urn, dep)
 				}
 			}
+		}
 
-			if _, has := urns[urn]; has && !state.Delete {
-				// The only time we should have duplicate URNs is when all but one of them are marked for deletion.
-				return fmt.Errorf("duplicate resource %s (not marked for deletion)", urn)
-			}
-
-			urns[urn] = state
+		if _, has := urns[urn]; has && !state.Delete {
+			// The only time we should have duplicate URNs is when all but one of them are marked for deletion.
+			violate(ViolationDuplicateURN, urn, "duplicate resource %s (not marked for deletion)", urn)
 		}
+
+		urns[urn] = state
 	}
 
-	return nil
+	// Any cycle necessarily contains a reference to a later resource, which has been reported above, but reporting the
+	// whole chain of resources makes it much easier to see how to break the cycle.
+	if cycle := graph.NewDependencyGraph(snap.Resources).FindCycle(); cycle != nil {
+		err := &graph.ValidationError{Kind: graph.ValidationCycle, Chain: cycle}
+		violate(ViolationDependencyCycle, cycle[0], "%v", err)
+	}
+
+	return violations
 }
@@ -0,0 +1,77 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestIntegrityViolations(t *testing.T) {
+	a, b, c := newResource("a"), newResource("b"), newResource("c")
+	b.Parent = c.URN
+	b.Dependencies = []resource.URN{a.URN, "urn:pulumi:teststack::pkg::test::missing"}
+	b.Provider = "urn:pulumi:teststack::pkg::pulumi:providers:pkgA::default::0"
+	dup := newResource("a")
+
+	snap := newSnapshot([]*resource.State{a, b, c, dup}, nil)
+	snap.Manifest.Magic = snap.Manifest.NewMagic()
+
+	violations := snap.IntegrityViolations()
+	kinds := make([]IntegrityViolationKind, len(violations))
+	for i, v := range violations {
+		kinds[i] = v.Kind
+	}
+	assert.Equal(t, []IntegrityViolationKind{
+		ViolationUnknownProvider,
+		ViolationParentOrder,
+		ViolationMissingDependency,
+		ViolationDuplicateURN,
+	}, kinds)
+	assert.Equal(t, b.URN, violations[0].URN)
+
+	// VerifyIntegrity reports the first violation.
+	assert.Equal(t, violations[0], snap.VerifyIntegrity())
+}
+
+func TestIntegrityViolationsValid(t *testing.T) {
+	a, b := newResource("a"), newResource("b")
+	b.Parent = a.URN
+	b.Dependencies = []resource.URN{a.URN}
+
+	snap := newSnapshot([]*resource.State{a, b}, nil)
+	snap.Manifest.Magic = snap.Manifest.NewMagic()
+	assert.Empty(t, snap.IntegrityViolations())
+	assert.NoError(t, snap.VerifyIntegrity())
+}
+
+func TestIntegrityViolationsCycle(t *testing.T) {
+	a, b, c := newResource("a"), newResource("b"), newResource("c")
+	a.Dependencies = []resource.URN{c.URN}
+	b.Dependencies = []resource.URN{a.URN}
+	c.Dependencies = []resource.URN{b.URN}
+
+	snap := newSnapshot([]*resource.State{a, b, c}, nil)
+	snap.Manifest.Magic = snap.Manifest.NewMagic()
+
+	violations := snap.IntegrityViolations()
+	assert.Len(t, violations, 2)
+	assert.Equal(t, ViolationDependencyOrder, violations[0].Kind)
+	assert.Equal(t, ViolationDependencyCycle, violations[1].Kind)
+	assert.Contains(t, violations[1].Message, string(a.URN)+" -> "+string(c.URN)+" -> "+string(b.URN))
+}
@@ -312,27 +312,12 @@ func (d *defaultProviders) newRegisterDefaultProviderEvent(
 	return event, done, nil
 }
 
-// handleRequest services a single default provider request. If the request is for a default provider that we have
-// already loaded, we will return its reference. If the request is for a default provider that has not yet been
-// loaded, we will send a register resource request to the engine, wait for it to complete, and then cache and return
-// the reference of the loaded provider.
-//
-// Note that this function must not be called from two goroutines concurrently; it is the responsibility of d.serve()
-// to ensure this.
-func (d *defaultProviders) handleRequest(req providers.ProviderRequest) (providers.Reference, error) {
+// registerDefaultProvider registers the default provider for a request that has not been loaded yet: it sends a
+// register resource request to the engine, waits for it to complete, and returns the reference of the loaded
+// provider.
+func (d *defaultProviders) registerDefaultProvider(req providers.ProviderRequest) (providers.Reference, error) {
 	logging.V(5).Infof("handling default provider request for package %s", req)
 
-	// Have we loaded this provider before? Use the existing reference, if so.
-	//
-	// Note that we are using the request's String as the key for the provider map. Go auto-derives hash and equality
-	// functions for aggregates, but the one auto-derived for ProviderRequest does not have the semantics we want. The
-	// use of a string key here is hacky but gets us the desired semantics - that ProviderRequest is a tuple of
-	// optional value-typed Version and a package.
-	ref, ok := d.providers[req.String()]
-	if ok {
-		return ref, nil
-	}
-
 	event, done, err := d.newRegisterDefaultProviderEvent(req)
 	if err != nil {
 		return providers.Reference{}, err
@@ -360,22 +345,59 @@ func (d *defaultProviders) handleRequest(req providers.ProviderRequest) (provide
 		id = providers.UnknownID
 	}
 
-	ref, err = providers.NewReference(result.State.URN, id)
+	ref, err := providers.NewReference(result.State.URN, id)
 	contract.Assert(err == nil)
-	d.providers[req.String()] = ref
-
 	return ref, nil
 }
 
-// serve is the primary loop responsible for handling default provider requests.
+// defaultProviderRegistration is the outcome of registering the default provider for a request.
+type defaultProviderRegistration struct {
+	key string
+	ref providers.Reference
+	err error
+}
+
+// serve services default provider requests. A request for a default provider that has already been loaded is answered
+// with its reference. The default providers of different requests are registered concurrently, so that one provider
+// being created and configured does not hold up the others, and requests for a default provider that is being
+// registered wait for that registration to complete.
+//
+// Note that we are using the request's String as the key for the provider map. Go auto-derives hash and equality
+// functions for aggregates, but the one auto-derived for ProviderRequest does not have the semantics we want. The use
+// of a string key here is hacky but gets us the desired semantics - that ProviderRequest is a tuple of optional
+// value-typed Version and a package.
 func (d *defaultProviders) serve() {
+	pending := make(map[string][]chan<- defaultProviderResponse)
+	registered := make(chan defaultProviderRegistration)
 	for {
+		// Note that we do not need to handle cancellation when sending a response: every response channel is
+		// buffered.
 		select {
 		case req := <-d.requests:
-			// Note that we do not need to handle cancellation when sending the response: every message we receive is
-			// guaranteed to have something waiting on the other end of the response channel.
-			ref, err := d.handleRequest(req.req)
-			req.response <- defaultProviderResponse{ref: ref, err: err}
+			key := req.req.String()
+			if ref, ok := d.providers[key]; ok {
+				req.response <- defaultProviderResponse{ref: ref}
+				continue
+			}
+			waiters, ok := pending[key]
+			pending[key] = append(waiters, req.response)
+			if !ok {
+				go func(key string, req providers.ProviderRequest) {
+					ref, err := d.registerDefaultProvider(req)
+					select {
+					case registered <- defaultProviderRegistration{key: key, ref: ref, err: err}:
+					case <-d.cancel:
+					}
+				}(key, req.req)
+			}
+		case reg := <-registered:
+			if reg.err == nil {
+				d.providers[reg.key] = reg.ref
+			}
+			for _, response := range pending[reg.key] {
+				response <- defaultProviderResponse{ref: reg.ref, err: reg.err}
+			}
+			delete(pending, reg.key)
 		case <-d.cancel:
 			return
 		}
@@ -384,14 +406,18 @@ func (d *defaultProviders) serve() {
 
 // getDefaultProviderRef fetches the provider reference for the default provider for a particular package.
 func (d *defaultProviders) getDefaultProviderRef(req providers.ProviderRequest) (providers.Reference, error) {
-	response := make(chan defaultProviderResponse)
+	response := make(chan defaultProviderResponse, 1)
 	select {
 	case d.requests <- defaultProviderRequest{req: req, response: response}:
 	case <-d.cancel:
 		return providers.Reference{}, context.Canceled
 	}
-	res := <-response
-	return res.ref, res.err
+	select {
+	case res := <-response:
+		return res.ref, res.err
+	case <-d.cancel:
+		return providers.Reference{}, context.Canceled
+	}
 }
 
 // resmon implements the pulumirpc.ResourceMonitor interface and acts as the gateway between a language runtime's
@@ -854,6 +880,7 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	replaceOnChanges := req.GetReplaceOnChanges()
 	id := resource.ID(req.GetImportId())
 	customTimeouts := req.GetCustomTimeouts()
+	deletedWith := resource.URN(req.GetDeletedWith())
 
 	// Custom resources must have a three-part type so that we can 1) identify if they are providers and 2) retrieve the
 	// provider responsible for managing a particular resource (based on the type's Package).
@@ -979,9 +1006,9 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 	logging.V(5).Infof(
 		"ResourceMonitor.RegisterResource received: t=%v, name=%v, custom=%v, #props=%v, parent=%v, protect=%v, "+
 			"provider=%v, deps=%v, deleteBeforeReplace=%v, ignoreChanges=%v, aliases=%v, customTimeouts=%v, "+
-			"providers=%v, replaceOnChanges=%v",
+			"providers=%v, replaceOnChanges=%v, deletedWith=%v",
 		t, name, custom, len(props), parent, protect, providerRef, dependencies, deleteBeforeReplace, ignoreChanges,
-		aliases, timeouts, providerRefs, replaceOnChanges)
+		aliases, timeouts, providerRefs, replaceOnChanges, deletedWith)
 
 	// If this is a remote component, fetch its provider and issue the construct call. Otherwise, register the resource.
 	var result *RegisterResult
@@ -1022,6 +1049,7 @@ func (rm *resmon) RegisterResource(ctx context.Context,
 				additionalSecretOutputs, aliases, id, &timeouts, replaceOnChanges),
 			done: make(chan *RegisterResult),
 		}
+		step.goal.DeletedWith = deletedWith
 
 		select {
 		case rm.regChan <- step:
@@ -0,0 +1,255 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/result"
+)
+
+// NewRollbackSource returns a source that registers the resources of the given snapshot, which is the snapshot of a
+// stack before an update that failed, with the inputs that they had then. Deploying it against the stack's current
+// snapshot deletes the resources that the update created, updates the resources that it changed back to their old
+// inputs and recreates the resources that it deleted, where their providers allow it. Resources that are pending
+// deletion are not registered. A nil snapshot rolls back to an empty stack.
+func NewRollbackSource(project tokens.PackageName, prev *Snapshot) Source {
+	var resources []*resource.State
+	if prev != nil {
+		for _, res := range prev.Resources {
+			if !res.Delete {
+				resources = append(resources, res)
+			}
+		}
+	}
+	return &rollbackSource{project: project, resources: resources}
+}
+
+// A rollbackSource registers the resources of a previous snapshot.
+type rollbackSource struct {
+	project   tokens.PackageName
+	resources []*resource.State
+}
+
+func (src *rollbackSource) Close() error                { return nil }
+func (src *rollbackSource) Project() tokens.PackageName { return src.project }
+func (src *rollbackSource) Info() interface{}           { return nil }
+
+func (src *rollbackSource) Iterate(
+	ctx context.Context, opts Options, providers ProviderSource) (SourceIterator, result.Result) {
+
+	contract.Ignore(providers)
+	return &rollbackSourceIterator{
+		ctx:        ctx,
+		src:        src,
+		registered: make(map[resource.URN]*rollbackEvent),
+		refs:       make(map[resource.URN]string),
+		closed:     make(chan struct{}),
+	}, nil
+}
+
+// rollbackSourceIterator returns an event for each resource of the previous snapshot in turn. Unlike a program, which
+// waits for the resources that a resource depends on before it registers it, the snapshot is known up front, so the
+// iterator waits for a resource's parent, provider and dependencies to be registered before it returns its event.
+type rollbackSourceIterator struct {
+	ctx     context.Context
+	src     *rollbackSource
+	current int
+	// outputs is the component whose outputs are to be registered next, if any.
+	outputs *rollbackEvent
+
+	registered map[resource.URN]*rollbackEvent
+	// refs maps the URN of each provider that has been registered to its new reference, as a provider that is
+	// recreated gets a new ID. It is written as steps complete.
+	refs     map[resource.URN]string
+	refsLock sync.Mutex
+	closed   chan struct{}
+}
+
+func (iter *rollbackSourceIterator) Close() error {
+	close(iter.closed)
+	return nil
+}
+
+func (iter *rollbackSourceIterator) Next() (SourceEvent, result.Result) {
+	// The outputs of a component, such as the stack's own outputs, are only registered once the component is.
+	if e := iter.outputs; e != nil {
+		iter.outputs = nil
+		if res := iter.wait(e.state.URN); res != nil {
+			return nil, res
+		}
+		return &rollbackOutputsEvent{urn: e.state.URN, outputs: e.state.Outputs}, nil
+	}
+
+	if iter.current >= len(iter.src.resources) {
+		return nil, nil
+	}
+	state := iter.src.resources[iter.current]
+	iter.current++
+
+	var waitFor []resource.URN
+	if state.Parent != "" {
+		waitFor = append(waitFor, state.Parent)
+	}
+	provider := state.Provider
+	if provider != "" {
+		ref, err := providers.ParseReference(provider)
+		if err != nil {
+			return nil, result.Errorf("bad provider reference '%v' for resource %v: %v", provider, state.URN, err)
+		}
+		waitFor = append(waitFor, ref.URN())
+	}
+	waitFor = append(waitFor, state.Dependencies...)
+	for _, deps := range state.PropertyDependencies {
+		waitFor = append(waitFor, deps...)
+	}
+	if res := iter.wait(waitFor...); res != nil {
+		return nil, res
+	}
+	if provider != "" {
+		ref, _ := providers.ParseReference(provider)
+		iter.refsLock.Lock()
+		if newRef, ok := iter.refs[ref.URN()]; ok {
+			provider = newRef
+		}
+		iter.refsLock.Unlock()
+	}
+
+	e := &rollbackEvent{state: state, provider: provider, done: make(chan struct{})}
+	iter.registered[state.URN] = e
+	if providers.IsProviderType(state.Type) {
+		e.onDone = func(new *resource.State) {
+			if ref, err := providers.NewReference(new.URN, new.ID); err == nil {
+				iter.refsLock.Lock()
+				iter.refs[new.URN] = ref.String()
+				iter.refsLock.Unlock()
+			}
+		}
+	}
+	if state.External {
+		return &rollbackReadEvent{e}, nil
+	}
+	if !state.Custom && len(state.Outputs) > 0 {
+		iter.outputs = e
+	}
+	return &rollbackRegisterEvent{e}, nil
+}
+
+// wait waits for the resources with the given URNs that are part of the previous snapshot to be registered. It
+// returns a bail result if the deployment is canceled or ends first.
+func (iter *rollbackSourceIterator) wait(urns ...resource.URN) result.Result {
+	for _, urn := range urns {
+		e, ok := iter.registered[urn]
+		if !ok {
+			continue
+		}
+		select {
+		case <-e.done:
+		case <-iter.ctx.Done():
+			return result.Bail()
+		case <-iter.closed:
+			return result.Bail()
+		}
+	}
+	return nil
+}
+
+// rollbackEvent is the registration of a resource of the previous snapshot.
+type rollbackEvent struct {
+	state    *resource.State
+	provider string
+	done     chan struct{}
+	onDone   func(new *resource.State)
+}
+
+func (e *rollbackEvent) finish(new *resource.State) {
+	if e.onDone != nil && new != nil {
+		e.onDone(new)
+	}
+	close(e.done)
+}
+
+// rollbackRegisterEvent registers a resource of the previous snapshot with the inputs that it had then.
+type rollbackRegisterEvent struct {
+	*rollbackEvent
+}
+
+var _ RegisterResourceEvent = (*rollbackRegisterEvent)(nil)
+
+func (e *rollbackRegisterEvent) event() {}
+
+func (e *rollbackRegisterEvent) Goal() *resource.Goal {
+	s := e.state
+	goal := resource.NewGoal(s.Type, s.URN.Name(), s.Custom, s.Inputs, s.Parent, s.Protect, s.Dependencies,
+		e.provider, s.InitErrors, s.PropertyDependencies, nil, nil, s.AdditionalSecretOutputs, s.Aliases, "",
+		&s.CustomTimeouts, nil)
+	goal.DeletedWith = s.DeletedWith
+	return goal
+}
+
+func (e *rollbackRegisterEvent) Done(result *RegisterResult) {
+	var new *resource.State
+	if result != nil {
+		new = result.State
+	}
+	e.finish(new)
+}
+
+// rollbackReadEvent reads an external resource of the previous snapshot again.
+type rollbackReadEvent struct {
+	*rollbackEvent
+}
+
+var _ ReadResourceEvent = (*rollbackReadEvent)(nil)
+
+func (e *rollbackReadEvent) event() {}
+
+func (e *rollbackReadEvent) ID() resource.ID                  { return e.state.ID }
+func (e *rollbackReadEvent) Name() tokens.QName               { return e.state.URN.Name() }
+func (e *rollbackReadEvent) Type() tokens.Type                { return e.state.Type }
+func (e *rollbackReadEvent) Provider() string                 { return e.provider }
+func (e *rollbackReadEvent) Parent() resource.URN             { return e.state.Parent }
+func (e *rollbackReadEvent) Properties() resource.PropertyMap { return e.state.Inputs }
+func (e *rollbackReadEvent) Dependencies() []resource.URN     { return e.state.Dependencies }
+
+func (e *rollbackReadEvent) AdditionalSecretOutputs() []resource.PropertyKey {
+	return e.state.AdditionalSecretOutputs
+}
+
+func (e *rollbackReadEvent) Done(result *ReadResult) {
+	var new *resource.State
+	if result != nil {
+		new = result.State
+	}
+	e.finish(new)
+}
+
+// rollbackOutputsEvent registers the outputs that a component of the previous snapshot had.
+type rollbackOutputsEvent struct {
+	urn     resource.URN
+	outputs resource.PropertyMap
+}
+
+var _ RegisterResourceOutputsEvent = (*rollbackOutputsEvent)(nil)
+
+func (e *rollbackOutputsEvent) event()                        {}
+func (e *rollbackOutputsEvent) URN() resource.URN             { return e.urn }
+func (e *rollbackOutputsEvent) Outputs() resource.PropertyMap { return e.outputs }
+func (e *rollbackOutputsEvent) Done()                         {}
@@ -64,6 +64,9 @@ type SameStep struct {
 	// If this is a same-step for a resource being created but which was not --target'ed by the user
 	// (and thus was skipped).
 	skippedCreate bool
+
+	// If this is a same-step for a deprecated resource whose removal date has passed.
+	expired bool
 }
 
 var _ Step = (*SameStep)(nil)
@@ -109,6 +112,32 @@ func NewSkippedCreateStep(deployment *Deployment, reg RegisterResourceEvent, new
 	}
 }
 
+// NewExpiredStep produces a SameStep for a deprecated resource whose removal date has passed but that is still
+// registered by the program. The program receives the resource's last known state, but, like a skipped create, the
+// step is not written to the checkpoint, as the resource will be deleted once the program has finished.
+func NewExpiredStep(deployment *Deployment, reg RegisterResourceEvent, old, new *resource.State) Step {
+	contract.Assert(old != nil)
+	contract.Assert(old.URN != "")
+	contract.Assert(old.ID != "" || !old.Custom)
+	contract.Assert(!old.Delete)
+	contract.Assert(old.RemoveAfter != nil)
+	contract.Assert(new != nil)
+	contract.Assert(new.URN != "")
+	contract.Assert(new.ID == "")
+	contract.Assert(!new.Delete)
+
+	// Use a copy of the old state so that completing this step does not remove the resource from the checkpoint
+	// before it has been deleted.
+	expired := *old
+	return &SameStep{
+		deployment: deployment,
+		reg:        reg,
+		old:        &expired,
+		new:        new,
+		expired:    true,
+	}
+}
+
 func (s *SameStep) Op() StepOp              { return OpSame }
 func (s *SameStep) Deployment() *Deployment { return s.deployment }
 func (s *SameStep) Type() tokens.Type       { return s.new.Type }
@@ -144,6 +173,10 @@ func (s *SameStep) IsSkippedCreate() bool {
 	return s.skippedCreate
 }
 
+func (s *SameStep) IsExpired() bool {
+	return s.expired
+}
+
 // CreateStep is a mutating step that creates an entirely new resource.
 type CreateStep struct {
 	deployment    *Deployment                    // the current deployment.
@@ -270,6 +303,9 @@ type DeleteStep struct {
 	deployment *Deployment     // the current deployment.
 	old        *resource.State // the state of the existing resource.
 	replacing  bool            // true if part of a replacement.
+	// true if the resource is deleted along with the resource named by its DeletedWith field, which is also being
+	// deleted, in which case the provider's Delete is not called.
+	deletedWith bool
 }
 
 var _ Step = (*DeleteStep)(nil)
@@ -344,8 +380,9 @@ func (s *DeleteStep) Apply(preview bool) (resource.Status, StepCompleteFunc, err
 			"`pulumi state unprotect %s`", s.old.URN, s.old.URN)
 	}
 
-	// Deleting an External resource is a no-op, since Pulumi does not own the lifecycle.
-	if !preview && !s.old.External {
+	// Deleting an External resource is a no-op, since Pulumi does not own the lifecycle. Likewise, a resource that is
+	// deleted with another resource that is also being deleted will be removed by the provider when that resource is.
+	if !preview && !s.old.External && !s.deletedWith {
 		if s.old.Custom {
 			// Invoke the Delete RPC function for this provider:
 			prov, err := getProvider(s)
@@ -674,22 +711,24 @@ func (s *ReadStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error
 // resource by reading its current state from its provider plugin. These steps are not issued by the step generator;
 // instead, they are issued by the deployment executor as the optional first step in deployment execution.
 type RefreshStep struct {
-	deployment *Deployment     // the deployment that produced this refresh
-	old        *resource.State // the old resource state, if one exists for this urn
-	new        *resource.State // the new resource state, to be used to query the provider
-	done       chan<- bool     // the channel to use to signal completion, if any
+	deployment    *Deployment     // the deployment that produced this refresh
+	old           *resource.State // the old resource state, if one exists for this urn
+	new           *resource.State // the new resource state, to be used to query the provider
+	ignoreChanges []string        // a list of property paths whose refreshed values are not adopted.
+	done          chan<- bool     // the channel to use to signal completion, if any
 }
 
-// NewRefreshStep creates a new Refresh step.
-func NewRefreshStep(deployment *Deployment, old *resource.State, done chan<- bool) Step {
+// NewRefreshStep creates a new Refresh step. Changes to the properties named by ignoreChanges are not adopted.
+func NewRefreshStep(deployment *Deployment, old *resource.State, ignoreChanges []string, done chan<- bool) Step {
 	contract.Assert(old != nil)
 
 	// NOTE: we set the new state to the old state by default so that we don't interpret step failures as deletes.
 	return &RefreshStep{
-		deployment: deployment,
-		old:        old,
-		new:        old,
-		done:       done,
+		deployment:    deployment,
+		old:           old,
+		new:           old,
+		ignoreChanges: ignoreChanges,
+		done:          done,
 	}
 }
 
@@ -762,6 +801,12 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 	}
 
 	if outputs != nil {
+		// Keep the current values of any properties whose changes are ignored.
+		if len(s.ignoreChanges) != 0 {
+			inputs = ignoreRefreshedChanges(s.old.Inputs, inputs, s.ignoreChanges)
+			outputs = ignoreRefreshedChanges(s.old.Outputs, outputs, s.ignoreChanges)
+		}
+
 		// There is a chance that the ID has changed. We want to allow this change to happen
 		// it will have changed already in the outputs, but we need to persist this change
 		// at a state level because the Id
@@ -774,6 +819,9 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 			s.old.Parent, s.old.Protect, s.old.External, s.old.Dependencies, initErrors, s.old.Provider,
 			s.old.PropertyDependencies, s.old.PendingReplacement, s.old.AdditionalSecretOutputs, s.old.Aliases,
 			&s.old.CustomTimeouts, s.old.ImportID)
+		s.new.RemoveAfter = s.old.RemoveAfter
+		s.new.DeletedWith = s.old.DeletedWith
+		s.new.Locked = s.old.Locked
 	} else {
 		s.new = nil
 	}
@@ -781,6 +829,25 @@ func (s *RefreshStep) Apply(preview bool) (resource.Status, StepCompleteFunc, er
 	return rst, complete, err
 }
 
+// ignoreRefreshedChanges returns a copy of the refreshed properties in which each of the given property paths holds
+// its old value, or is absent if it had no old value. Paths that cannot be set are left as refreshed.
+func ignoreRefreshedChanges(olds, news resource.PropertyMap, ignoreChanges []string) resource.PropertyMap {
+	ignored := resource.NewObjectProperty(news.Copy())
+	for _, ignoreChange := range ignoreChanges {
+		path, err := resource.ParsePropertyPath(ignoreChange)
+		if err != nil {
+			continue
+		}
+
+		if oldValue, hasOld := path.Get(resource.NewObjectProperty(olds)); hasOld {
+			path.Set(ignored, oldValue)
+		} else {
+			path.Delete(ignored)
+		}
+	}
+	return ignored.ObjectValue()
+}
+
 type ImportStep struct {
 	deployment    *Deployment                    // the current deployment.
 	reg           RegisterResourceEvent          // the registration intent to convey a URN back to.
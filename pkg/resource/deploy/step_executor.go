@@ -43,20 +43,14 @@ var (
 	errStepApplyFailed = errors.New("step application failed")
 )
 
-// The step executor operates in terms of "chains" and "antichains". A chain is set of steps that are totally ordered
-// when ordered by dependency; each step in a chain depends directly on the step that comes before it. An antichain
-// is a set of steps that is completely incomparable when ordered by dependency. The step executor is aware that chains
-// must be executed serially and antichains can be executed concurrently.
-//
-// See https://en.wikipedia.org/wiki/Antichain for more complete definitions. The below type aliases are useful for
-// documentation purposes.
+// The step executor operates in terms of "chains". A chain is set of steps that are totally ordered when ordered by
+// dependency; each step in a chain depends directly on the step that comes before it, so the steps of a chain must be
+// executed serially. Steps that do not depend on one another are executed concurrently, either all at once, as by
+// ExecuteParallel, or as the steps they depend on complete, as by ExecuteDeletes.
 
 // A Chain is a sequence of Steps that must be executed in the given order.
 type chain = []Step
 
-// An Antichain is a set of Steps that can be executed in parallel.
-type antichain = []Step
-
 // A CompletionToken is a token returned by the step executor that is completed when the chain has completed execution.
 // Callers can use it to optionally wait synchronously on the completion of a chain.
 type completionToken struct {
@@ -121,15 +115,15 @@ func (se *stepExecutor) ExecuteSerial(chain chain) completionToken {
 	return completionToken{channel: completion}
 }
 
-// ExecuteParallel submits an antichain for parallel execution. All of the steps within the antichain are submitted for
-// concurrent execution.
-func (se *stepExecutor) ExecuteParallel(antichain antichain) completionToken {
+// ExecuteParallel submits a set of steps that do not depend on one another, such as the steps of a refresh, for
+// parallel execution. All of the steps are submitted for concurrent execution.
+func (se *stepExecutor) ExecuteParallel(steps []Step) completionToken {
 	var wg sync.WaitGroup
 
 	// ExecuteParallel is implemented in terms of ExecuteSerial - it executes each step individually and waits for all
 	// of the steps to complete.
-	wg.Add(len(antichain))
-	for _, step := range antichain {
+	wg.Add(len(steps))
+	for _, step := range steps {
 		tok := se.ExecuteSerial(chain{step})
 		go func() {
 			defer wg.Done()
@@ -146,6 +140,52 @@ func (se *stepExecutor) ExecuteParallel(antichain antichain) completionToken {
 	return completionToken{channel: done}
 }
 
+// ExecuteDeletes submits the steps of a delete schedule for execution, each as soon as the steps that it waits for have
+// completed. The degree of parallelism is bounded by the executor's workers as usual. The returned token completes once
+// every step has completed or the executor has been canceled.
+func (se *stepExecutor) ExecuteDeletes(schedule *deleteSchedule) completionToken {
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+
+		waitingOn := make(map[Step]int, len(schedule.waitingOn))
+		for step, n := range schedule.waitingOn {
+			waitingOn[step] = n
+		}
+
+		completed := make(chan Step, len(schedule.steps))
+		submit := func(step Step) {
+			tok := se.ExecuteSerial(chain{step})
+			go func() {
+				tok.Wait(se.ctx)
+				completed <- step
+			}()
+		}
+		for _, step := range schedule.steps {
+			if waitingOn[step] == 0 {
+				submit(step)
+			}
+		}
+
+		for remaining := len(schedule.steps); remaining > 0; remaining-- {
+			var step Step
+			select {
+			case step = <-completed:
+			case <-se.ctx.Done():
+				return
+			}
+			for _, next := range schedule.unblocks[step] {
+				waitingOn[next]--
+				if waitingOn[next] == 0 {
+					submit(next)
+				}
+			}
+		}
+	}()
+
+	return completionToken{channel: done}
+}
+
 // ExecuteRegisterResourceOutputs services a RegisterResourceOutputsEvent synchronously on the calling goroutine.
 func (se *stepExecutor) ExecuteRegisterResourceOutputs(e RegisterResourceOutputsEvent) {
 	// Look up the final state in the pending registration list.
@@ -0,0 +1,133 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// funcStep is a step that calls a function instead of changing anything when it is applied.
+type funcStep struct {
+	Step
+	apply func()
+}
+
+func (s *funcStep) Apply(preview bool) (resource.Status, StepCompleteFunc, error) {
+	s.apply()
+	return resource.StatusOK, nil, nil
+}
+
+// executeSteps runs an executor with the given degree of parallelism, submits steps to it, and waits for them to
+// complete, failing if they have not after a while.
+func executeSteps(t *testing.T, parallel int, submit func(se *stepExecutor) completionToken) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	se := newStepExecutor(ctx, cancel, &Deployment{}, Options{Parallel: parallel}, false, false)
+	tok := submit(se)
+
+	select {
+	case <-tok.channel:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the steps did not complete")
+	}
+	se.SignalCompletion()
+	se.WaitForCompletion()
+}
+
+func TestExecuteParallel(t *testing.T) {
+	// Each step waits for every other to start, so they only complete if they are executed concurrently.
+	const count = 3
+	var started sync.WaitGroup
+	started.Add(count)
+
+	var steps []Step
+	for i := 0; i < count; i++ {
+		steps = append(steps, &funcStep{
+			Step: NewDeleteStep(nil, newResource("res")),
+			apply: func() {
+				started.Done()
+				started.Wait()
+			},
+		})
+	}
+
+	executeSteps(t, count, func(se *stepExecutor) completionToken {
+		return se.ExecuteParallel(steps)
+	})
+}
+
+func TestExecuteDeletes(t *testing.T) {
+	vpc := newResource("vpc")
+	subnetA, subnetB := newResource("subnetA"), newResource("subnetB")
+	subnetA.Dependencies = []resource.URN{vpc.URN}
+	subnetB.Dependencies = []resource.URN{vpc.URN}
+	webA, webB := newResource("webA"), newResource("webB")
+	webA.Dependencies = []resource.URN{subnetA.URN}
+	webB.Dependencies = []resource.URN{subnetB.URN}
+	resources := []*resource.State{vpc, subnetA, subnetB, webA, webB}
+
+	// webB's delete does not complete until subnetA has been deleted, which is only possible if subnetA's delete does
+	// not also wait for webB's.
+	var lock sync.Mutex
+	var deleted []*resource.State
+	subnetADeleted := make(chan bool)
+
+	var steps []Step
+	for i := len(resources) - 1; i >= 0; i-- {
+		res := resources[i]
+		steps = append(steps, &funcStep{
+			Step: NewDeleteStep(nil, res),
+			apply: func() {
+				switch res {
+				case webB:
+					<-subnetADeleted
+				case subnetA:
+					close(subnetADeleted)
+				}
+				lock.Lock()
+				deleted = append(deleted, res)
+				lock.Unlock()
+			},
+		})
+	}
+
+	sg := &stepGenerator{
+		deployment: &Deployment{depGraph: graph.NewDependencyGraph(resources)},
+		opts:       Options{TrustDependencies: true},
+	}
+	executeSteps(t, 2, func(se *stepExecutor) completionToken {
+		return se.ExecuteDeletes(sg.ScheduleDeletes(steps))
+	})
+
+	// Each resource is deleted after the resources that depend on it.
+	index := make(map[*resource.State]int)
+	for i, res := range deleted {
+		index[res] = i
+	}
+	assert.Len(t, deleted, len(resources))
+	assert.Less(t, index[webA], index[subnetA])
+	assert.Less(t, index[webB], index[subnetB])
+	assert.Less(t, index[subnetA], index[vpc])
+	assert.Less(t, index[subnetB], index[vpc])
+}
@@ -17,6 +17,7 @@ package deploy
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
@@ -39,6 +40,7 @@ type stepGenerator struct {
 
 	updateTargetsOpt  map[resource.URN]bool // the set of resources to update; resources not in this set will be same'd
 	replaceTargetsOpt map[resource.URN]bool // the set of resoures to replace
+	excludeTargets    map[resource.URN]bool // the set of resources to leave untouched; these are never changed
 
 	// signals that one or more errors have been reported to the user, and the deployment should terminate
 	// in error. This primarily allows `preview` to aggregate many policy violation events and
@@ -52,6 +54,7 @@ type stepGenerator struct {
 	updates  map[resource.URN]bool // set of URNs updated in this deployment
 	creates  map[resource.URN]bool // set of URNs created in this deployment
 	sames    map[resource.URN]bool // set of URNs that were not changed in this deployment
+	expired  map[resource.URN]bool // set of URNs of deprecated resources that are past their removal date
 
 	// set of URNs that would have been created, but were filtered out because the user didn't
 	// specify them with --target
@@ -73,6 +76,9 @@ func (sg *stepGenerator) isTargetedUpdate() bool {
 }
 
 func (sg *stepGenerator) isTargetedForUpdate(urn resource.URN) bool {
+	if sg.excludeTargets[urn] {
+		return false
+	}
 	return sg.updateTargetsOpt == nil || sg.updateTargetsOpt[urn]
 }
 
@@ -154,6 +160,12 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 		contract.Assert(len(steps) == 0)
 		return nil, res
 	}
+	if res := sg.checkExpiredDependencies(steps); res != nil {
+		return nil, res
+	}
+	if res := sg.checkLockedResources(steps); res != nil {
+		return nil, res
+	}
 	if !sg.isTargetedUpdate() {
 		return steps, nil
 	}
@@ -197,6 +209,84 @@ func (sg *stepGenerator) GenerateSteps(event RegisterResourceEvent) ([]Step, res
 	return steps, nil
 }
 
+// checkExpiredDependencies issues an error for each step whose resource depends upon a deprecated resource that is past
+// its removal date, as deleting that resource would leave the step's resource referring to a resource that no longer
+// exists.
+func (sg *stepGenerator) checkExpiredDependencies(steps []Step) result.Result {
+	if len(sg.expired) == 0 {
+		return nil
+	}
+
+	sawExpired := false
+	for _, step := range steps {
+		new := step.New()
+		if new == nil {
+			continue
+		}
+		if same, ok := step.(*SameStep); ok && same.IsExpired() {
+			continue
+		}
+
+		refs := append([]resource.URN{new.Parent}, new.Dependencies...)
+		if new.Provider != "" {
+			if ref, err := providers.ParseReference(new.Provider); err == nil {
+				refs = append(refs, ref.URN())
+			}
+		}
+		for _, urn := range refs {
+			if sg.expired[urn] {
+				sg.deployment.Diag().Errorf(diag.RawMessage(new.URN, fmt.Sprintf(
+					"resource depends on deprecated resource %v, which is past its removal date and would be deleted; "+
+						"remove the dependency or change the resource's removal date with `pulumi state deprecate`",
+					urn)))
+				sg.sawError = true
+				sawExpired = true
+			}
+		}
+	}
+
+	if sawExpired && !sg.deployment.preview {
+		// As with targeted updates, keep going during a preview so that every problem is reported at once.
+		return result.Bail()
+	}
+	return nil
+}
+
+// lockedOps are the operations that are refused for locked resources: everything that changes the resource itself,
+// rather than merely the recorded state of it.
+var lockedOps = map[StepOp]bool{
+	OpUpdate:            true,
+	OpReplace:           true,
+	OpCreateReplacement: true,
+	OpDeleteReplaced:    true,
+	OpDelete:            true,
+}
+
+// checkLockedResources issues an error for each locked resource that the given steps would update, replace or delete,
+// naming the lock that blocks it. A replacement consists of several steps, but is only reported once.
+func (sg *stepGenerator) checkLockedResources(steps []Step) result.Result {
+	sawLocked := false
+	reported := make(map[resource.URN]bool)
+	for _, step := range steps {
+		old := step.Old()
+		if old == nil || !old.Locked || !lockedOps[step.Op()] || reported[old.URN] {
+			continue
+		}
+		reported[old.URN] = true
+		sg.deployment.Diag().Errorf(diag.RawMessage(old.URN, fmt.Sprintf(
+			"the %s of this resource is blocked because the resource is locked; unlock it with "+
+				"`pulumi state unlock %s` to allow it to change", step.Op(), old.URN)))
+		sg.sawError = true
+		sawLocked = true
+	}
+
+	if sawLocked && !sg.deployment.preview {
+		// As with targeted updates, keep going during a preview so that every problem is reported at once.
+		return result.Bail()
+	}
+	return nil
+}
+
 func (sg *stepGenerator) generateSteps(event RegisterResourceEvent) ([]Step, result.Result) {
 	var invalid bool // will be set to true if this object fails validation.
 
@@ -252,6 +342,7 @@ func (sg *stepGenerator) generateSteps(event RegisterResourceEvent) ([]Step, res
 	new := resource.NewState(goal.Type, urn, goal.Custom, false, "", inputs, nil, goal.Parent, goal.Protect, false,
 		goal.Dependencies, goal.InitErrors, goal.Provider, goal.PropertyDependencies, false,
 		goal.AdditionalSecretOutputs, goal.Aliases, &goal.CustomTimeouts, "")
+	new.DeletedWith = goal.DeletedWith
 
 	// Mark the URN/resource as having been seen. So we can run analyzers on all resources seen, as well as
 	// lookup providers for calculating replacement of resources that use the provider.
@@ -372,6 +463,29 @@ func (sg *stepGenerator) generateSteps(event RegisterResourceEvent) ([]Step, res
 		return nil, result.Bail()
 	}
 
+	// A resource stays locked until it is unlocked with `pulumi state unlock`.
+	if hasOld {
+		new.Locked = old.Locked
+	}
+
+	// If the resource has been deprecated, warn that it will be removed. Once its removal date has passed, the program
+	// is given the resource's last known state and the resource is deleted along with any unregistered resources.
+	if hasOld && old.RemoveAfter != nil {
+		removeAfter := old.RemoveAfter.Format(time.RFC3339)
+		if time.Now().Before(*old.RemoveAfter) {
+			sg.deployment.Diag().Warningf(diag.RawMessage(urn,
+				fmt.Sprintf("resource is deprecated and will be deleted after %s", removeAfter)))
+			new.RemoveAfter = old.RemoveAfter
+		} else {
+			logging.V(7).Infof("Planner decided to delete deprecated resource '%v' (removal date %v)", urn, removeAfter)
+			sg.deployment.Diag().Warningf(diag.RawMessage(urn,
+				fmt.Sprintf("resource is deprecated and its removal date (%s) has passed; it will be deleted",
+					removeAfter)))
+			sg.expired[urn] = true
+			return []Step{NewExpiredStep(sg.deployment, event, old, new)}, nil
+		}
+	}
+
 	// There are four cases we need to consider when figuring out what to do with this resource.
 	//
 	// Case 1: recreating
@@ -741,9 +855,34 @@ func (sg *stepGenerator) GenerateDeletes(targetsOpt map[resource.URN]bool) ([]St
 		dels = filtered
 	}
 
+	// Never delete resources that were excluded from the deployment.
+	if len(sg.excludeTargets) > 0 {
+		kept := []Step{}
+		for _, step := range dels {
+			if sg.excludeTargets[step.URN()] {
+				logging.V(7).Infof("Planner decided not to delete excluded resource '%v'", step.URN())
+				continue
+			}
+			kept = append(kept, step)
+		}
+		dels = kept
+	}
+
+	// Resources that are deleted with another resource that is itself being deleted do not need to be deleted
+	// separately: the provider will remove them when it deletes that resource.
+	markDeletedWith(dels)
+
+	if res := sg.checkLockedResources(dels); res != nil {
+		return nil, res
+	}
+
 	deletingUnspecifiedTarget := false
 	for _, step := range dels {
 		urn := step.URN()
+		if del, ok := step.(*DeleteStep); ok && del.deletedWith {
+			// Deleting a target implies deleting the resources that are deleted with it.
+			continue
+		}
 		if targetsOpt != nil && !targetsOpt[urn] && !sg.opts.TargetDependents {
 			d := diag.GetResourceWillBeDestroyedButWasNotSpecifiedInTargetList(urn)
 
@@ -772,6 +911,84 @@ func (sg *stepGenerator) GenerateDeletes(targetsOpt map[resource.URN]bool) ([]St
 	return dels, nil
 }
 
+// markDeletedWith flags the logical delete steps in the given list whose resources are deleted with a resource that
+// also has a delete step in the list.
+func markDeletedWith(dels []Step) {
+	deleting := make(map[resource.URN]bool)
+	for _, step := range dels {
+		if _, ok := step.(*DeleteStep); ok {
+			deleting[step.URN()] = true
+		}
+	}
+	for _, step := range dels {
+		if del, ok := step.(*DeleteStep); ok && !del.replacing && deleting[del.old.DeletedWith] {
+			logging.V(7).Infof("Planner will not delete '%v' separately: it is deleted with '%v'",
+				del.old.URN, del.old.DeletedWith)
+			del.deletedWith = true
+		}
+	}
+}
+
+// GenerateStaleDefaultProviderDeletes returns delete steps for default providers in the previous snapshot that were
+// not registered during this deployment and that no surviving resource refers to, whether as its provider, as its
+// parent, as a dependency or property dependency, or as the resource it is deleted with. The given steps are the
+// deletes that have already been generated.
+func (sg *stepGenerator) GenerateStaleDefaultProviderDeletes(dels []Step) []Step {
+	prev := sg.deployment.prev
+	if prev == nil {
+		return nil
+	}
+
+	deleted := make(map[*resource.State]bool)
+	for _, step := range dels {
+		deleted[step.Res()] = true
+	}
+
+	// Collect everything that the resources that will remain in the snapshot refer to. Resources that were
+	// registered with anything other than a same step will be written with their new state, which can only refer to
+	// providers registered during this deployment, so their old references do not count.
+	referenced := make(map[resource.URN]bool)
+	for _, res := range prev.Resources {
+		if res.Delete || deleted[res] || (sg.urns[res.URN] && !sg.sames[res.URN]) {
+			continue
+		}
+		if res.Provider != "" {
+			ref, err := providers.ParseReference(res.Provider)
+			contract.Assert(err == nil)
+			referenced[ref.URN()] = true
+		}
+		if res.Parent != "" {
+			referenced[res.Parent] = true
+		}
+		for _, dep := range res.Dependencies {
+			referenced[dep] = true
+		}
+		for _, deps := range res.PropertyDependencies {
+			for _, dep := range deps {
+				referenced[dep] = true
+			}
+		}
+		if res.DeletedWith != "" {
+			referenced[res.DeletedWith] = true
+		}
+	}
+
+	// Walk the old resources backwards, as GenerateDeletes does, so that the steps are in dependency order.
+	var steps []Step
+	for i := len(prev.Resources) - 1; i >= 0; i-- {
+		res := prev.Resources[i]
+		if !providers.IsDefaultProvider(res.URN) || res.Delete || res.Protect || deleted[res] ||
+			sg.urns[res.URN] || referenced[res.URN] || sg.excludeTargets[res.URN] {
+			continue
+		}
+
+		logging.V(7).Infof("Planner decided to delete stale default provider '%v'", res.URN)
+		sg.deletes[res.URN] = true
+		steps = append(steps, NewDeleteStep(sg.deployment, res))
+	}
+	return steps
+}
+
 // getTargetDependents returns the (transitive) set of dependents on the target resources.
 // This includes both implicit and explicit dependents in the DAG itself, as well as children.
 func (sg *stepGenerator) getTargetDependents(targetsOpt map[resource.URN]bool) map[resource.URN]bool {
@@ -883,91 +1100,61 @@ func (sg *stepGenerator) GeneratePendingDeletes() []Step {
 	return dels
 }
 
-// ScheduleDeletes takes a list of steps that will delete resources and "schedules" them by producing a list of list of
-// steps, where each list can be executed in parallel but a previous list must be executed to completion before
-// advancing to the next list.
-//
-// In lieu of tracking per-step dependencies and orienting the step executor around these dependencies, this function
-// provides a conservative approximation of what deletions can safely occur in parallel. The insight here is that the
-// resource dependency graph is a partially-ordered set and all partially-ordered sets can be easily decomposed into
-// antichains - subsets of the set that are all not comparable to one another. (In this definition, "not comparable"
-// means "do not depend on one another").
-//
-// The algorithm for decomposing a poset into antichains is:
-//  1. While there exist elements in the poset,
-//    1a. There must exist at least one "maximal" element of the poset. Let E_max be those elements.
-//    2a. Remove all elements E_max from the poset. E_max is an antichain.
-//    3a. Goto 1.
-//
-// Translated to our dependency graph:
-//  1. While the set of condemned resources is not empty:
-//    1a. Remove all resources with no outgoing edges from the graph and add them to the current antichain.
-//    2a. Goto 1.
+// deleteSchedule orders a set of delete steps by the dependencies between the resources that they delete. A step may
+// begin once the steps that delete the resources that depend on its resource have completed, so independent subtrees
+// of the dependency graph are deleted concurrently.
+type deleteSchedule struct {
+	// steps are the delete steps, in the order in which they were generated.
+	steps []Step
+	// waitingOn maps each step to the number of steps that must complete before it may begin.
+	waitingOn map[Step]int
+	// unblocks maps each step to the steps that wait for it to complete.
+	unblocks map[Step][]Step
+}
+
+// ScheduleDeletes takes a list of steps that will delete resources and schedules them by the reverse edges of the
+// dependency graph: each step waits only for the steps that delete the resources that depend on its resource. A
+// resource's dependents are those that refer to it as a dependency, a property dependency, their provider, their
+// parent or the resource they are deleted with.
 //
-// The resulting list of antichains is a list of list of steps that can be safely executed in parallel. Since we must
-// process deletes in reverse (so we don't delete resources upon which other resources depend), we reverse the list and
-// hand it back to the deployment executor for safe execution.
-func (sg *stepGenerator) ScheduleDeletes(deleteSteps []Step) []antichain {
-	var antichains []antichain                // the list of parallelizable steps we intend to return.
-	dg := sg.deployment.depGraph              // the current deployment's dependency graph.
-	condemned := make(graph.ResourceSet)      // the set of condemned resources.
-	stepMap := make(map[*resource.State]Step) // a map from resource states to the steps that delete them.
+// If the deployment does not trust the dependency graph, each step instead waits for the step before it, which deletes
+// the resources serially in the order that they were given.
+func (sg *stepGenerator) ScheduleDeletes(deleteSteps []Step) *deleteSchedule {
+	schedule := &deleteSchedule{
+		steps:     deleteSteps,
+		waitingOn: make(map[Step]int),
+		unblocks:  make(map[Step][]Step),
+	}
 
-	// If we don't trust the dependency graph we've been given, we must be conservative and delete everything serially.
 	if !sg.opts.TrustDependencies {
 		logging.V(7).Infof("Planner does not trust dependency graph, scheduling deletions serially")
-		for _, step := range deleteSteps {
-			antichains = append(antichains, antichain{step})
+		for i := 1; i < len(deleteSteps); i++ {
+			schedule.waitingOn[deleteSteps[i]] = 1
+			schedule.unblocks[deleteSteps[i-1]] = []Step{deleteSteps[i]}
 		}
-
-		return antichains
+		return schedule
 	}
 
-	logging.V(7).Infof("Planner trusts dependency graph, scheduling deletions in parallel")
+	logging.V(7).Infof("Planner trusts dependency graph, scheduling deletions by their dependents")
 
-	// For every step we've been given, record it as condemned and save the step that will be used to delete it. We'll
-	// iteratively place these steps into antichains as we remove elements from the condemned set.
+	dg := sg.deployment.depGraph
+	stepMap := make(map[*resource.State]Step) // a map from resource states to the steps that delete them.
+	condemned := make(graph.ResourceSet)      // the set of condemned resources.
 	for _, step := range deleteSteps {
 		condemned[step.Res()] = true
 		stepMap[step.Res()] = step
 	}
 
-	for len(condemned) > 0 {
-		var steps antichain
-		logging.V(7).Infof("Planner beginning schedule of new deletion antichain")
-		for res := range condemned {
-			// Does res have any outgoing edges to resources that haven't already been removed from the graph?
-			condemnedDependencies := dg.DependenciesOf(res).Intersect(condemned)
-			if len(condemnedDependencies) == 0 {
-				// If not, it's safe to delete res at this stage.
-				logging.V(7).Infof("Planner scheduling deletion of '%v'", res.URN)
-				steps = append(steps, stepMap[res])
-			}
-
-			// If one of this resource's dependencies or this resource's parent hasn't been removed from the graph yet,
-			// it can't be deleted this round.
-		}
-
-		// For all reosurces that are to be deleted in this round, remove them from the graph.
-		for _, step := range steps {
-			delete(condemned, step.Res())
+	// Each edge from a condemned resource to a condemned dependency means that the dependency's delete must wait for
+	// the resource's delete.
+	for _, step := range deleteSteps {
+		for dep := range dg.DependenciesOf(step.Res()).Intersect(condemned) {
+			depStep := stepMap[dep]
+			schedule.waitingOn[depStep]++
+			schedule.unblocks[step] = append(schedule.unblocks[step], depStep)
 		}
-
-		antichains = append(antichains, steps)
-	}
-
-	// Up until this point, all logic has been "backwards" - we're scheduling resources for deletion when all of their
-	// dependencies finish deletion, but that's exactly the opposite of what we need to do. We can only delete a
-	// resource when all *resources that depend on it* complete deletion. Our solution is still correct, though, it's
-	// just backwards.
-	//
-	// All we have to do here is reverse the list and then our solution is correct.
-	for i := len(antichains)/2 - 1; i >= 0; i-- {
-		opp := len(antichains) - 1 - i
-		antichains[i], antichains[opp] = antichains[opp], antichains[i]
 	}
-
-	return antichains
+	return schedule
 }
 
 // providerChanged diffs the Provider field of old and new resources, returning true if the rest of the step generator
@@ -1498,10 +1685,12 @@ func newStepGenerator(
 		opts:                 opts,
 		updateTargetsOpt:     updateTargetsOpt,
 		replaceTargetsOpt:    replaceTargetsOpt,
+		excludeTargets:       createTargetMap(opts.ExcludeTargets),
 		urns:                 make(map[resource.URN]bool),
 		reads:                make(map[resource.URN]bool),
 		creates:              make(map[resource.URN]bool),
 		sames:                make(map[resource.URN]bool),
+		expired:              make(map[resource.URN]bool),
 		replaces:             make(map[resource.URN]bool),
 		updates:              make(map[resource.URN]bool),
 		deletes:              make(map[resource.URN]bool),
@@ -3,6 +3,7 @@ package deploy
 import (
 	"testing"
 
+	"github.com/pulumi/pulumi/pkg/v3/resource/graph"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/plugin"
 	"github.com/stretchr/testify/assert"
@@ -194,3 +195,53 @@ func TestApplyReplaceOnChangesEmptyDetailedDiff(t *testing.T) {
 	}
 
 }
+
+func TestScheduleDeletes(t *testing.T) {
+	newState := func(name string, deps ...resource.URN) *resource.State {
+		return &resource.State{
+			URN:          resource.URN("urn:pulumi:dev::proj::pkg:m:typ::" + name),
+			Type:         "pkg:m:typ",
+			Dependencies: deps,
+		}
+	}
+	vpc := newState("vpc")
+	subnetA := newState("subnetA", vpc.URN)
+	subnetB := newState("subnetB", vpc.URN)
+	webA := newState("webA", subnetA.URN)
+	webB := newState("webB", subnetB.URN)
+	resources := []*resource.State{vpc, subnetA, subnetB, webA, webB}
+
+	var steps []Step
+	stepOf := make(map[*resource.State]Step)
+	for i := len(resources) - 1; i >= 0; i-- {
+		step := NewDeleteStep(nil, resources[i])
+		steps = append(steps, step)
+		stepOf[resources[i]] = step
+	}
+
+	sg := &stepGenerator{
+		deployment: &Deployment{depGraph: graph.NewDependencyGraph(resources)},
+		opts:       Options{TrustDependencies: true},
+	}
+
+	// Each delete waits only for the deletes of the resources that depend on it, so subnetA may be deleted as soon as
+	// webA has been, whether or not webB has.
+	schedule := sg.ScheduleDeletes(steps)
+	assert.Equal(t, 0, schedule.waitingOn[stepOf[webA]])
+	assert.Equal(t, 0, schedule.waitingOn[stepOf[webB]])
+	assert.Equal(t, 1, schedule.waitingOn[stepOf[subnetA]])
+	assert.Equal(t, 1, schedule.waitingOn[stepOf[subnetB]])
+	assert.Equal(t, 2, schedule.waitingOn[stepOf[vpc]])
+	assert.Equal(t, []Step{stepOf[subnetA]}, schedule.unblocks[stepOf[webA]])
+	assert.Equal(t, []Step{stepOf[vpc]}, schedule.unblocks[stepOf[subnetA]])
+	assert.Empty(t, schedule.unblocks[stepOf[vpc]])
+
+	// Without trusting the dependency graph, each delete waits for the one before it.
+	sg.opts.TrustDependencies = false
+	schedule = sg.ScheduleDeletes(steps)
+	assert.Equal(t, 0, schedule.waitingOn[steps[0]])
+	for i := 1; i < len(steps); i++ {
+		assert.Equal(t, 1, schedule.waitingOn[steps[i]])
+		assert.Equal(t, []Step{steps[i]}, schedule.unblocks[steps[i-1]])
+	}
+}
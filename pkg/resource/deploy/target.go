@@ -15,6 +15,10 @@
 package deploy
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
@@ -28,7 +32,21 @@ type Target struct {
 	Snapshot  *Snapshot        // the last snapshot deployed to the target.
 }
 
-// GetPackageConfig returns the set of configuration parameters for the indicated package, if any.
+// DefaultProviderConfigKey is the stack configuration key that configures default providers. Its value is an object
+// that maps each package name to the settings of that package's default provider, for example:
+//
+//     config:
+//       pulumi:providers:
+//         aws:
+//           region: us-west-2
+//           profile: ephemeral
+//
+// These settings are given to the default provider in addition to the package's own configuration (e.g. `aws:region`),
+// and take precedence over it, so that an environment can be re-pointed without changes to its program.
+var DefaultProviderConfigKey = config.MustMakeKey("pulumi", "providers")
+
+// GetPackageConfig returns the set of configuration parameters for the indicated package, if any. This includes the
+// settings for the package under DefaultProviderConfigKey.
 func (t *Target) GetPackageConfig(pkg tokens.Package) (resource.PropertyMap, error) {
 	result := resource.PropertyMap{}
 	if t == nil {
@@ -51,5 +69,80 @@ func (t *Target) GetPackageConfig(pkg tokens.Package) (resource.PropertyMap, err
 		}
 		result[resource.PropertyKey(k.Name())] = propertyValue
 	}
+
+	settings, err := t.defaultProviderSettings()
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := settings[string(pkg)]
+	if !ok {
+		return result, nil
+	}
+	pkgSettings, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v.%v must be an object of provider settings", DefaultProviderConfigKey, pkg)
+	}
+	for name, setting := range pkgSettings {
+		// Round-trip each setting through a config value so that it is decrypted, and objects are encoded as JSON,
+		// in the same way as the package's own configuration.
+		b, err := json.Marshal(setting)
+		if err != nil {
+			return nil, err
+		}
+		var c config.Value
+		if err = json.Unmarshal(b, &c); err != nil {
+			return nil, err
+		}
+		v, err := c.Value(t.Decrypter)
+		if err != nil {
+			return nil, err
+		}
+
+		propertyValue := resource.NewStringProperty(v)
+		if c.Secure() {
+			propertyValue = resource.MakeSecret(propertyValue)
+		}
+		result[resource.PropertyKey(name)] = propertyValue
+	}
 	return result, nil
 }
+
+// GetDefaultProviderPackages returns the names of the packages whose default providers are configured under
+// DefaultProviderConfigKey.
+func (t *Target) GetDefaultProviderPackages() ([]tokens.Package, error) {
+	settings, err := t.defaultProviderSettings()
+	if err != nil {
+		return nil, err
+	}
+	pkgs := make([]tokens.Package, 0, len(settings))
+	for pkg := range settings {
+		pkgs = append(pkgs, tokens.Package(pkg))
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i] < pkgs[j] })
+	return pkgs, nil
+}
+
+// defaultProviderSettings returns the still-encrypted value of DefaultProviderConfigKey, if it is set.
+func (t *Target) defaultProviderSettings() (map[string]interface{}, error) {
+	if t == nil {
+		return nil, nil
+	}
+	c, ok := t.Config[DefaultProviderConfigKey]
+	if !ok {
+		return nil, nil
+	}
+	if !c.Object() {
+		return nil, fmt.Errorf("%v must be an object that maps package names to provider settings",
+			DefaultProviderConfigKey)
+	}
+	obj, err := c.ToObject()
+	if err != nil {
+		return nil, err
+	}
+	settings, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v must be an object that maps package names to provider settings",
+			DefaultProviderConfigKey)
+	}
+	return settings, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+func TestGetPackageConfigDefaultProviderSettings(t *testing.T) {
+	target := &Target{
+		Config: config.Map{
+			config.MustMakeKey("aws", "region"):  config.NewValue("us-east-1"),
+			config.MustMakeKey("aws", "profile"): config.NewValue("default"),
+			DefaultProviderConfigKey: config.NewSecureObjectValue(
+				`{"aws":{"region":"us-west-2","maxRetries":3,"token":{"secure":"s3cr3t"}},"gcp":{"project":"p"}}`),
+		},
+		Decrypter: config.NopDecrypter,
+	}
+
+	aws, err := target.GetPackageConfig("aws")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{
+		"region":     resource.NewStringProperty("us-west-2"),
+		"profile":    resource.NewStringProperty("default"),
+		"maxRetries": resource.NewStringProperty("3"),
+		"token":      resource.MakeSecret(resource.NewStringProperty("s3cr3t")),
+	}, aws)
+
+	gcp, err := target.GetPackageConfig("gcp")
+	assert.NoError(t, err)
+	assert.Equal(t, resource.PropertyMap{"project": resource.NewStringProperty("p")}, gcp)
+
+	pkgs, err := target.GetDefaultProviderPackages()
+	assert.NoError(t, err)
+	assert.Equal(t, []tokens.Package{"aws", "gcp"}, pkgs)
+
+	target.Config[DefaultProviderConfigKey] = config.NewObjectValue(`{"aws":"us-west-2"}`)
+	_, err = target.GetPackageConfig("aws")
+	assert.Error(t, err)
+}
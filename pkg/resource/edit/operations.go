@@ -16,6 +16,7 @@ package edit
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy"
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
@@ -73,12 +74,79 @@ func DeleteResource(snapshot *deploy.Snapshot, condemnedRes *resource.State) err
 	return nil
 }
 
+// UnusedProviders returns the provider resources in the given snapshot that no other resource references, whether as
+// its provider, as a dependency, or as its parent. Protected providers are never considered unused.
+func UnusedProviders(snapshot *deploy.Snapshot) []*resource.State {
+	if snapshot == nil {
+		return nil
+	}
+
+	// Resources with pending operations may refer to providers that are otherwise unreferenced.
+	pendingRefs := make(map[string]bool)
+	for _, op := range snapshot.PendingOperations {
+		if op.Resource.Provider != "" {
+			pendingRefs[op.Resource.Provider] = true
+		}
+	}
+
+	dg := graph.NewDependencyGraph(snapshot.Resources)
+
+	var unused []*resource.State
+	for _, res := range snapshot.Resources {
+		if !providers.IsProviderType(res.Type) || res.Protect {
+			continue
+		}
+
+		ref, err := providers.NewReference(res.URN, res.ID)
+		contract.AssertNoErrorf(err, "failed to generate provider reference from valid provider")
+		if pendingRefs[ref.String()] {
+			continue
+		}
+
+		if len(dg.DependingOn(res, nil, true /*includeChildren*/)) == 0 {
+			unused = append(unused, res)
+		}
+	}
+
+	return unused
+}
+
 // UnprotectResource unprotects a resource.
 func UnprotectResource(_ *deploy.Snapshot, res *resource.State) error {
 	res.Protect = false
 	return nil
 }
 
+// LockResource locks a resource, so that the engine refuses to update, replace or delete it.
+func LockResource(_ *deploy.Snapshot, res *resource.State) error {
+	res.Locked = true
+	return nil
+}
+
+// UnlockResource unlocks a resource.
+func UnlockResource(_ *deploy.Snapshot, res *resource.State) error {
+	res.Locked = false
+	return nil
+}
+
+// DeprecateResource returns an OperationFunc that marks a resource as deprecated, so that it is deleted by the first
+// update after removeAfter. A nil removeAfter clears the resource's deprecation. Providers and the root stack resource
+// cannot be deprecated.
+func DeprecateResource(removeAfter *time.Time) OperationFunc {
+	return func(_ *deploy.Snapshot, res *resource.State) error {
+		if removeAfter != nil {
+			if providers.IsProviderType(res.Type) {
+				return fmt.Errorf("provider resource %v cannot be deprecated", res.URN)
+			}
+			if res.Type == resource.RootStackType {
+				return fmt.Errorf("the root stack resource %v cannot be deprecated", res.URN)
+			}
+		}
+		res.RemoveAfter = removeAfter
+		return nil
+	}
+}
+
 // LocateResource returns all resources in the given snapshot that have the given URN.
 func LocateResource(snap *deploy.Snapshot, urn resource.URN) []*resource.State {
 	// If there is no snapshot then return no resources
@@ -185,6 +185,31 @@ func TestFailedDeletionParentDependency(t *testing.T) {
 	assert.Equal(t, []*resource.State{pA, a, b, c}, snap.Resources)
 }
 
+func TestUnusedProviders(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	pB := NewProviderResource("b", "p2", "1")
+	pC := NewProviderResource("c", "p3", "2")
+	pC.Protect = true
+	pD := NewProviderResource("d", "p4", "3")
+	a := NewResource("a", pA)
+	b := NewResource("b", nil, pD.URN)
+	snap := NewSnapshot([]*resource.State{
+		pA,
+		pB,
+		pC,
+		pD,
+		a,
+		b,
+	})
+
+	unused := UnusedProviders(snap)
+	assert.Equal(t, []*resource.State{pB}, unused)
+
+	err := DeleteResource(snap, pB)
+	assert.NoError(t, err)
+	assert.Empty(t, UnusedProviders(snap))
+}
+
 func TestUnprotectResource(t *testing.T) {
 	pA := NewProviderResource("a", "p1", "0")
 	a := NewResource("a", pA)
@@ -205,6 +230,27 @@ func TestUnprotectResource(t *testing.T) {
 	assert.False(t, a.Protect)
 }
 
+func TestDeprecateResource(t *testing.T) {
+	pA := NewProviderResource("a", "p1", "0")
+	a := NewResource("a", pA)
+	snap := NewSnapshot([]*resource.State{pA, a})
+
+	removeAfter := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	err := DeprecateResource(&removeAfter)(snap, a)
+	assert.NoError(t, err)
+	if assert.NotNil(t, a.RemoveAfter) {
+		assert.Equal(t, removeAfter, *a.RemoveAfter)
+	}
+
+	err = DeprecateResource(nil)(snap, a)
+	assert.NoError(t, err)
+	assert.Nil(t, a.RemoveAfter)
+
+	err = DeprecateResource(&removeAfter)(snap, pA)
+	assert.Error(t, err)
+	assert.Nil(t, pA.RemoveAfter)
+}
+
 func TestLocateResourceNotFound(t *testing.T) {
 	pA := NewProviderResource("a", "p1", "0")
 	a := NewResource("a", pA)
@@ -3,6 +3,9 @@
 package graph
 
 import (
+	"sort"
+	"sync"
+
 	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -13,72 +16,162 @@ type DependencyGraph struct {
 	index      map[*resource.State]int // A mapping of resource pointers to indexes within the snapshot
 	resources  []*resource.State       // The list of resources, obtained from the snapshot
 	childrenOf map[resource.URN][]int  // Pre-computed map of transitive children for each resource
+	urnIndex   map[resource.URN]int    // A mapping of URNs to indexes within the snapshot
+
+	dependentsOnce sync.Once              // Guards the lazy construction of dependents
+	dependents     map[resource.URN][]int // Indexes of the resources that depend directly upon each URN
+
+	transitiveLock sync.Mutex                      // Protects transitive
+	transitive     map[*resource.State]ResourceSet // Memoized results of TransitiveDependenciesOf
 }
 
 // DependingOn returns a slice containing all resources that directly or indirectly
 // depend upon the given resource. The returned slice is guaranteed to be in topological
 // order with respect to the snapshot dependency graph.
 //
-// The time complexity of DependingOn is linear with respect to the number of resources.
+// The first call to DependingOn builds an index of the edges in the graph in time linear with
+// respect to the number of resources. Each call then takes time proportional to the number of
+// dependents it returns (and the edges that lead to them), so repeatedly querying a large graph
+// remains cheap.
 func (dg *DependencyGraph) DependingOn(res *resource.State,
 	ignore map[resource.URN]bool, includeChildren bool) []*resource.State {
 	// This implementation relies on the detail that snapshots are stored in a valid
 	// topological order.
-	var dependents []*resource.State
-	dependentSet := make(map[resource.URN]bool)
-
 	cursorIndex, ok := dg.index[res]
 	contract.Assert(ok)
-	dependentSet[res.URN] = true
 
-	isDependent := func(candidate *resource.State) bool {
-		if ignore[candidate.URN] {
-			return false
-		}
-		if includeChildren && candidate.Parent == res.URN {
-			return true
-		}
-		for _, dependency := range candidate.Dependencies {
-			if dependentSet[dependency] {
-				return true
-			}
-		}
-		if candidate.Provider != "" {
-			ref, err := providers.ParseReference(candidate.Provider)
-			contract.Assert(err == nil)
-			if dependentSet[ref.URN()] {
-				return true
-			}
-		}
-		return false
-	}
+	dg.dependentsOnce.Do(dg.indexDependents)
 
 	// The dependency graph encoded directly within the snapshot is the reverse of
 	// the graph that we actually want to operate upon. Edges in the snapshot graph
 	// originate in a resource and go to that resource's dependencies.
 	//
-	// The `DependingOn` is simpler when operating on the reverse of the snapshot graph,
+	// `DependingOn` is simpler when operating on the reverse of the snapshot graph,
 	// where edges originate in a resource and go to resources that depend on that resource.
 	// In this graph, `DependingOn` for a resource is the set of resources that are reachable from the
-	// given resource.
+	// given resource, which we find with a breadth-first search of the reverse index.
 	//
-	// To accomplish this without building up an entire graph data structure, we'll do a linear
-	// scan of the resource list starting at the requested resource and ending at the end of
-	// the list. All resources that depend directly or indirectly on `res` are prepended
-	// onto `dependents`.
-	for i := cursorIndex + 1; i < len(dg.resources); i++ {
-		candidate := dg.resources[i]
-		if isDependent(candidate) {
-			dependents = append(dependents, candidate)
-			dependentSet[candidate.URN] = true
+	// Only resources that follow a dependency in the snapshot may depend upon it. Because a URN may
+	// appear more than once in a snapshot (e.g. for resources that are pending deletion), we track
+	// the earliest index at which each URN became a dependent, and only follow edges into resources
+	// beyond that index.
+	dependents := make(map[int]bool)
+	earliest := map[resource.URN]int{res.URN: cursorIndex}
+	frontier := []resource.URN{res.URN}
+
+	visit := func(idx, after int) {
+		candidate := dg.resources[idx]
+		if idx <= after || dependents[idx] || ignore[candidate.URN] {
+			return
+		}
+		dependents[idx] = true
+		if prev, ok := earliest[candidate.URN]; !ok || idx < prev {
+			earliest[candidate.URN] = idx
+			frontier = append(frontier, candidate.URN)
+		}
+	}
+
+	if includeChildren {
+		for _, idx := range dg.childrenOf[res.URN] {
+			if dg.resources[idx].Parent == res.URN {
+				visit(idx, cursorIndex)
+			}
+		}
+	}
+	for len(frontier) > 0 {
+		urn := frontier[0]
+		frontier = frontier[1:]
+		for _, idx := range dg.dependents[urn] {
+			visit(idx, earliest[urn])
+		}
+	}
+
+	if len(dependents) == 0 {
+		return nil
+	}
+	indices := make([]int, 0, len(dependents))
+	for idx := range dependents {
+		indices = append(indices, idx)
+	}
+	sort.Ints(indices)
+
+	result := make([]*resource.State, len(indices))
+	for i, idx := range indices {
+		result[i] = dg.resources[idx]
+	}
+	return result
+}
+
+// indexDependents builds the reverse adjacency index used by DependingOn, which maps each URN
+// to the indexes of the resources that refer to it as a dependency, a property dependency,
+// their provider, or the resource they are deleted with.
+func (dg *DependencyGraph) indexDependents() {
+	dg.dependents = make(map[resource.URN][]int)
+	for idx, res := range dg.resources {
+		for dep := range directDependencies(res) {
+			dg.dependents[dep] = append(dg.dependents[dep], idx)
 		}
 	}
+}
 
-	return dependents
+// directDependencies returns the URNs upon which a resource directly depends, excluding its parent. Each URN maps to
+// the names of the properties that introduced the dependency, if any.
+func directDependencies(res *resource.State) map[resource.URN][]resource.PropertyKey {
+	deps := make(map[resource.URN][]resource.PropertyKey)
+	for _, dep := range res.Dependencies {
+		deps[dep] = nil
+	}
+	for key, propDeps := range res.PropertyDependencies {
+		for _, dep := range propDeps {
+			deps[dep] = append(deps[dep], key)
+		}
+	}
+	if res.Provider != "" {
+		ref, err := providers.ParseReference(res.Provider)
+		contract.Assert(err == nil)
+		if _, has := deps[ref.URN()]; !has {
+			deps[ref.URN()] = nil
+		}
+	}
+	if res.DeletedWith != "" {
+		if _, has := deps[res.DeletedWith]; !has {
+			deps[res.DeletedWith] = nil
+		}
+	}
+	for _, keys := range deps {
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	}
+	return deps
+}
+
+// DependencyEdge describes a direct dependency of one resource upon another.
+type DependencyEdge struct {
+	// Dependency is the resource that is depended upon.
+	Dependency *resource.State
+	// Properties lists the properties of the dependent resource that introduced the dependency, if known.
+	Properties []resource.PropertyKey
+}
+
+// DependencyEdgesOf returns the direct dependencies of the given resource, including those that are only recorded as
+// property dependencies, along with the properties that induced each of them. Unlike DependenciesOf, the result does
+// not include the resource's parent or the children of its dependencies. Edges are returned in snapshot order.
+func (dg *DependencyGraph) DependencyEdgesOf(res *resource.State) []DependencyEdge {
+	cursorIndex, ok := dg.index[res]
+	contract.Assert(ok)
+
+	deps := directDependencies(res)
+	var edges []DependencyEdge
+	for i := 0; i < cursorIndex; i++ {
+		candidate := dg.resources[i]
+		if properties, ok := deps[candidate.URN]; ok {
+			edges = append(edges, DependencyEdge{Dependency: candidate, Properties: properties})
+		}
+	}
+	return edges
 }
 
 // DependenciesOf returns a ResourceSet of resources upon which the given resource depends. The resource's parent is
-// included in the returned set.
+// included in the returned set, as are any resources that are only recorded as property dependencies.
 func (dg *DependencyGraph) DependenciesOf(res *resource.State) ResourceSet {
 	set := make(ResourceSet)
 
@@ -86,6 +179,11 @@ func (dg *DependencyGraph) DependenciesOf(res *resource.State) ResourceSet {
 	for _, dep := range res.Dependencies {
 		dependentUrns[dep] = true
 	}
+	for _, deps := range res.PropertyDependencies {
+		for _, dep := range deps {
+			dependentUrns[dep] = true
+		}
+	}
 
 	if res.Provider != "" {
 		ref, err := providers.ParseReference(res.Provider)
@@ -93,6 +191,10 @@ func (dg *DependencyGraph) DependenciesOf(res *resource.State) ResourceSet {
 		dependentUrns[ref.URN()] = true
 	}
 
+	if res.DeletedWith != "" {
+		dependentUrns[res.DeletedWith] = true
+	}
+
 	cursorIndex, ok := dg.index[res]
 	contract.Assert(ok)
 	for i := cursorIndex - 1; i >= 0; i-- {
@@ -123,6 +225,104 @@ func (dg *DependencyGraph) DependenciesOf(res *resource.State) ResourceSet {
 	return set
 }
 
+// TransitiveDependenciesOf returns a ResourceSet of resources upon which the given resource directly or indirectly
+// depends. As with DependenciesOf, parents are treated as dependencies.
+//
+// Results are memoized, so computing the transitive dependencies of many resources in the same graph takes time
+// proportional to the size of the graph rather than to the number of queries. The returned set is owned by the caller.
+func (dg *DependencyGraph) TransitiveDependenciesOf(res *resource.State) ResourceSet {
+	dg.transitiveLock.Lock()
+	defer dg.transitiveLock.Unlock()
+
+	set := make(ResourceSet)
+	for dep := range dg.transitiveDependenciesOf(res) {
+		set[dep] = true
+	}
+	return set
+}
+
+// transitiveDependenciesOf computes the memoized transitive dependencies of a resource. The caller must hold
+// transitiveLock. Because DependenciesOf only considers resources that precede the given resource in the snapshot,
+// the recursion always terminates.
+func (dg *DependencyGraph) transitiveDependenciesOf(res *resource.State) ResourceSet {
+	if set, ok := dg.transitive[res]; ok {
+		return set
+	}
+
+	set := make(ResourceSet)
+	for dep := range dg.DependenciesOf(res) {
+		set[dep] = true
+		for transitive := range dg.transitiveDependenciesOf(dep) {
+			set[transitive] = true
+		}
+	}
+
+	if dg.transitive == nil {
+		dg.transitive = make(map[*resource.State]ResourceSet)
+	}
+	dg.transitive[res] = set
+	return set
+}
+
+// ChildrenOf returns the resources whose parent is the resource with the given URN, in snapshot order.
+func (dg *DependencyGraph) ChildrenOf(urn resource.URN) []*resource.State {
+	var children []*resource.State
+	for _, idx := range dg.childrenOf[urn] {
+		if child := dg.resources[idx]; child.Parent == urn {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// TransitiveChildrenOf returns the resources that are descended from the resource with the given URN, i.e. its
+// children, their children, and so on, in snapshot order.
+func (dg *DependencyGraph) TransitiveChildrenOf(urn resource.URN) []*resource.State {
+	var children []*resource.State
+	for _, idx := range dg.childrenOf[urn] {
+		children = append(children, dg.resources[idx])
+	}
+	return children
+}
+
+// ParentOf returns the parent of the resource with the given URN, or nil if the resource has no parent or either of
+// them is not present in the graph.
+func (dg *DependencyGraph) ParentOf(urn resource.URN) *resource.State {
+	idx, ok := dg.urnIndex[urn]
+	if !ok {
+		return nil
+	}
+	parentIdx, ok := dg.urnIndex[dg.resources[idx].Parent]
+	if !ok {
+		return nil
+	}
+	return dg.resources[parentIdx]
+}
+
+// Ancestors is the transitive variant of ParentOf: it returns the parent of the resource with the given URN, that
+// resource's parent, and so on up to the root of the parent hierarchy. The immediate parent comes first. Parents that
+// are not present in the graph end the walk, as does a parent that has already been visited, so that snapshots with
+// parent cycles cannot cause it to loop.
+func (dg *DependencyGraph) Ancestors(urn resource.URN) []*resource.State {
+	idx, ok := dg.urnIndex[urn]
+	if !ok {
+		return nil
+	}
+
+	var ancestors []*resource.State
+	visited := map[resource.URN]bool{urn: true}
+	for parent := dg.resources[idx].Parent; parent != "" && !visited[parent]; {
+		parentIdx, ok := dg.urnIndex[parent]
+		if !ok {
+			break
+		}
+		visited[parent] = true
+		ancestors = append(ancestors, dg.resources[parentIdx])
+		parent = dg.resources[parentIdx].Parent
+	}
+	return ancestors
+}
+
 // NewDependencyGraph creates a new DependencyGraph from a list of resources.
 // The resources should be in topological order with respect to their dependencies, including
 // parents appearing before children.
@@ -134,12 +334,18 @@ func NewDependencyGraph(resources []*resource.State) *DependencyGraph {
 	for idx, res := range resources {
 		index[res] = idx
 		urnIndex[res.URN] = idx
+		// Walk up the parent hierarchy. The walk stops at parents that have not been seen yet, and is bounded by the
+		// number of resources, so that snapshots with missing parents or parent cycles cannot cause it to loop.
 		parent := res.Parent
-		for parent != "" {
+		for depth := 0; parent != "" && depth < len(resources); depth++ {
 			childrenOf[parent] = append(childrenOf[parent], idx)
-			parent = resources[urnIndex[parent]].Parent
+			parentIdx, ok := urnIndex[parent]
+			if !ok {
+				break
+			}
+			parent = resources[parentIdx].Parent
 		}
 	}
 
-	return &DependencyGraph{index, resources, childrenOf}
+	return &DependencyGraph{index: index, resources: resources, childrenOf: childrenOf, urnIndex: urnIndex}
 }
@@ -229,3 +229,173 @@ func TestDependenciesOfRemoteComponentsNoCycle(t *testing.T) {
 	assert.True(t, rDependencies[parent])
 	assert.False(t, rDependencies[child])
 }
+
+func TestTransitiveDependenciesOf(t *testing.T) {
+	pA := NewProviderResource("test", "pA", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	pB := NewProviderResource("test", "pB", "1", b.URN)
+	c := NewResource("c", pB)
+	d := NewResource("d", nil)
+	d.Parent = c.URN
+	e := NewResource("e", nil)
+
+	dg := NewDependencyGraph([]*resource.State{
+		pA,
+		a,
+		b,
+		pB,
+		c,
+		d,
+		e,
+	})
+
+	assert.Equal(t, ResourceSet{}, dg.TransitiveDependenciesOf(pA))
+	assert.Equal(t, ResourceSet{pA: true}, dg.TransitiveDependenciesOf(a))
+	assert.Equal(t, ResourceSet{pA: true, a: true}, dg.TransitiveDependenciesOf(b))
+	assert.Equal(t, ResourceSet{pA: true, a: true, b: true, pB: true}, dg.TransitiveDependenciesOf(c))
+	assert.Equal(t, ResourceSet{pA: true, a: true, b: true, pB: true, c: true}, dg.TransitiveDependenciesOf(d))
+	assert.Equal(t, ResourceSet{}, dg.TransitiveDependenciesOf(e))
+
+	// Mutating a returned set must not affect later queries.
+	bDeps := dg.TransitiveDependenciesOf(b)
+	bDeps[e] = true
+	assert.Equal(t, ResourceSet{pA: true, a: true}, dg.TransitiveDependenciesOf(b))
+}
+
+func TestParentHierarchy(t *testing.T) {
+	pA := NewProviderResource("test", "pA", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA)
+	b.Parent = a.URN
+	c := NewResource("c", pA)
+	c.Parent = b.URN
+	d := NewResource("d", pA)
+	d.Parent = a.URN
+	e := NewResource("e", pA)
+
+	dg := NewDependencyGraph([]*resource.State{
+		pA,
+		a,
+		b,
+		c,
+		d,
+		e,
+	})
+
+	assert.Equal(t, []*resource.State{b, d}, dg.ChildrenOf(a.URN))
+	assert.Equal(t, []*resource.State{c}, dg.ChildrenOf(b.URN))
+	assert.Nil(t, dg.ChildrenOf(e.URN))
+
+	assert.Equal(t, []*resource.State{b, c, d}, dg.TransitiveChildrenOf(a.URN))
+	assert.Equal(t, []*resource.State{c}, dg.TransitiveChildrenOf(b.URN))
+	assert.Nil(t, dg.TransitiveChildrenOf(c.URN))
+
+	assert.Equal(t, []*resource.State{b, a}, dg.Ancestors(c.URN))
+	assert.Equal(t, []*resource.State{a}, dg.Ancestors(d.URN))
+	assert.Nil(t, dg.Ancestors(a.URN))
+	assert.Nil(t, dg.Ancestors("urn:pulumi:test::test::test:test:test::missing"))
+
+	assert.Equal(t, b, dg.ParentOf(c.URN))
+	assert.Equal(t, a, dg.ParentOf(d.URN))
+	assert.Nil(t, dg.ParentOf(a.URN))
+	assert.Nil(t, dg.ParentOf("urn:pulumi:test::test::test:test:test::missing"))
+
+	// A parent cycle ends the walk instead of looping.
+	x := NewResource("x", pA)
+	y := NewResource("y", pA)
+	x.Parent, y.Parent = y.URN, x.URN
+	dg = NewDependencyGraph([]*resource.State{pA, x, y})
+	assert.Equal(t, []*resource.State{y}, dg.Ancestors(x.URN))
+	assert.Equal(t, []*resource.State{x}, dg.Ancestors(y.URN))
+}
+
+// Tests that DependingOn only follows edges into resources that appear after a dependent in the snapshot, even when a
+// URN appears more than once.
+func TestDependingOnDuplicateURNs(t *testing.T) {
+	a := NewResource("a", nil)
+	oldB := NewResource("b", nil)
+	c := NewResource("c", nil, oldB.URN)
+	b := NewResource("b", nil, a.URN)
+	d := NewResource("d", nil, b.URN)
+	e := NewResource("e", nil)
+	e.Parent = a.URN
+	f := NewResource("f", nil, e.URN)
+
+	dg := NewDependencyGraph([]*resource.State{
+		a,
+		oldB,
+		c,
+		b,
+		d,
+		e,
+		f,
+	})
+
+	// c depends on the old copy of b, which precedes the copy of b that depends on a.
+	assert.Equal(t, []*resource.State{b, d}, dg.DependingOn(a, nil, false))
+	assert.Equal(t, []*resource.State{b, d, e, f}, dg.DependingOn(a, nil, true))
+	assert.Equal(t, []*resource.State{c, d}, dg.DependingOn(oldB, nil, false))
+
+	// Repeated queries reuse the same index and give the same answers.
+	assert.Equal(t, []*resource.State{b, d}, dg.DependingOn(a, nil, false))
+	assert.Equal(t, []*resource.State{e, f}, dg.DependingOn(a, map[resource.URN]bool{b.URN: true}, true))
+}
+
+func TestPropertyDependencies(t *testing.T) {
+	pA := NewProviderResource("test", "pA", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA)
+	c := NewResource("c", pA, a.URN)
+	c.PropertyDependencies = map[resource.PropertyKey][]resource.URN{
+		"foo": {a.URN},
+		"bar": {a.URN, b.URN},
+	}
+	d := NewResource("d", nil)
+	d.PropertyDependencies = map[resource.PropertyKey][]resource.URN{
+		"baz": {c.URN},
+	}
+
+	dg := NewDependencyGraph([]*resource.State{
+		pA,
+		a,
+		b,
+		c,
+		d,
+	})
+
+	// b and c are only related through a property dependency.
+	assert.Equal(t, []*resource.State{c, d}, dg.DependingOn(b, nil, false))
+	assert.Equal(t, ResourceSet{pA: true, a: true, b: true}, dg.DependenciesOf(c))
+	assert.Equal(t, ResourceSet{c: true}, dg.DependenciesOf(d))
+
+	assert.Equal(t, []DependencyEdge{
+		{Dependency: pA},
+		{Dependency: a, Properties: []resource.PropertyKey{"bar", "foo"}},
+		{Dependency: b, Properties: []resource.PropertyKey{"bar"}},
+	}, dg.DependencyEdgesOf(c))
+	assert.Equal(t, []DependencyEdge{
+		{Dependency: c, Properties: []resource.PropertyKey{"baz"}},
+	}, dg.DependencyEdgesOf(d))
+}
+
+func TestDeletedWith(t *testing.T) {
+	pA := NewProviderResource("test", "pA", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA)
+	b.DeletedWith = a.URN
+	c := NewResource("c", nil)
+	c.DeletedWith = b.URN
+
+	dg := NewDependencyGraph([]*resource.State{
+		pA,
+		a,
+		b,
+		c,
+	})
+
+	assert.Equal(t, []*resource.State{b, c}, dg.DependingOn(a, nil, false))
+	assert.Equal(t, ResourceSet{pA: true, a: true}, dg.DependenciesOf(b))
+	assert.Equal(t, ResourceSet{b: true}, dg.DependenciesOf(c))
+	assert.Equal(t, []DependencyEdge{{Dependency: b}}, dg.DependencyEdgesOf(c))
+}
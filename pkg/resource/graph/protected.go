@@ -0,0 +1,60 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// SeparateProtected partitions resources into those that can be deleted without invalidating a protected resource
+// and those that cannot. A resource is protected if it is marked as protected, or if a protected resource depends on
+// it, whether directly, through a property or provider reference, or as a descendant. For example, given
+//
+//	A
+//	B: Parent = A
+//	C: Parent = A, Protect = true
+//	D: Parent = C
+//
+// the unprotected resources are B and D, and the protected resources are A and C.
+//
+// The resources must be topologically sorted, as they are in a snapshot. Both results preserve the order of the
+// input. The closure is computed in a single backwards pass rather than recursively, so arbitrarily deep graphs are
+// supported.
+func SeparateProtected(resources []*resource.State) (unprotected, protected []*resource.State) {
+	dg := NewDependencyGraph(resources)
+
+	// Every dependency of a resource precedes it, so by the time a resource is visited, all of the protected
+	// resources that depend upon it have already been visited and have marked it.
+	marked := make(ResourceSet)
+	for i := len(resources) - 1; i >= 0; i-- {
+		res := resources[i]
+		if !res.Protect && !marked[res] {
+			continue
+		}
+		marked[res] = true
+		for dep := range dg.DependenciesOf(res) {
+			marked[dep] = true
+		}
+	}
+
+	for _, res := range resources {
+		if marked[res] {
+			protected = append(protected, res)
+		} else {
+			unprotected = append(unprotected, res)
+		}
+	}
+	return unprotected, protected
+}
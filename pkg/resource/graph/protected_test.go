@@ -0,0 +1,55 @@
+// Copyright 2016-2021, Pulumi Corporation.  All rights reserved.
+
+package graph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeparateProtected(t *testing.T) {
+	pA := NewProviderResource("test", "pA", "0")
+	a := NewResource("a", nil)
+	b := NewResource("b", nil)
+	b.Parent = a.URN
+	c := NewResource("c", pA)
+	c.Parent = a.URN
+	c.Protect = true
+	d := NewResource("d", nil)
+	d.Parent = c.URN
+	e := NewResource("e", nil)
+	f := NewResource("f", nil)
+	f.PropertyDependencies = map[resource.PropertyKey][]resource.URN{
+		"foo": {e.URN},
+	}
+	f.Protect = true
+
+	unprotected, protected := SeparateProtected([]*resource.State{pA, a, b, c, d, e, f})
+	assert.Equal(t, []*resource.State{b, d}, unprotected)
+	assert.Equal(t, []*resource.State{pA, a, c, e, f}, protected)
+
+	unprotected, protected = SeparateProtected(nil)
+	assert.Empty(t, unprotected)
+	assert.Empty(t, protected)
+}
+
+func TestSeparateProtectedDeepGraph(t *testing.T) {
+	// A long chain of dependencies ending in a protected resource protects the whole chain.
+	const depth = 5000
+	resources := make([]*resource.State, depth)
+	for i := range resources {
+		res := NewResource(fmt.Sprintf("r%d", i), nil)
+		if i > 0 {
+			res.Dependencies = []resource.URN{resources[i-1].URN}
+		}
+		resources[i] = res
+	}
+	resources[depth-1].Protect = true
+
+	unprotected, protected := SeparateProtected(resources)
+	assert.Empty(t, unprotected)
+	assert.Equal(t, resources, protected)
+}
@@ -0,0 +1,196 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pulumi/pulumi/pkg/v3/resource/deploy/providers"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+// ValidationErrorKind identifies the way in which a dependency graph is invalid.
+type ValidationErrorKind string
+
+const (
+	// ValidationCycle indicates that resources depend upon one another in a cycle.
+	ValidationCycle ValidationErrorKind = "cycle"
+	// ValidationForwardReference indicates that a resource refers to a resource that comes after it in the snapshot.
+	ValidationForwardReference ValidationErrorKind = "forward-reference"
+)
+
+// ValidationError describes a violation of the topological order of a dependency graph.
+type ValidationError struct {
+	Kind ValidationErrorKind
+	// Chain lists the resources involved in the violation. Each resource in the chain refers to the next, whether as a
+	// dependency, a property dependency, its provider, or its parent. For a cycle, the chain begins and ends with the
+	// same resource; for a forward reference, it holds the referring resource followed by the resource it refers to.
+	Chain []resource.URN
+}
+
+func (e *ValidationError) Error() string {
+	switch e.Kind {
+	case ValidationCycle:
+		chain := make([]string, len(e.Chain))
+		for i, urn := range e.Chain {
+			chain[i] = string(urn)
+		}
+		return fmt.Sprintf("resources depend upon one another in a cycle: %s", strings.Join(chain, " -> "))
+	default:
+		return fmt.Sprintf("resource %s refers to %s, which comes after it", e.Chain[0], e.Chain[1])
+	}
+}
+
+// Validate checks that the graph's resources are in a valid topological order: that no resource refers to a resource
+// that comes after it, and that no resources depend upon one another in a cycle. Such graphs cannot be produced by
+// the engine, but may result from aliases or manual edits to a stack's state.
+//
+// If the graph is invalid, Validate returns a *ValidationError that describes the offending chain of resources.
+// Cycles are reported in preference to forward references, as every cycle contains at least one forward reference.
+// References to resources that are not in the graph are not considered.
+func (dg *DependencyGraph) Validate() error {
+	if cycle := dg.FindCycle(); cycle != nil {
+		return &ValidationError{Kind: ValidationCycle, Chain: cycle}
+	}
+
+	edges := dg.referenceEdges()
+	for from, tos := range edges {
+		for _, to := range tos {
+			if to > from {
+				return &ValidationError{
+					Kind:  ValidationForwardReference,
+					Chain: []resource.URN{dg.resources[from].URN, dg.resources[to].URN},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// FindCycle returns a chain of resources that depend upon one another in a cycle, beginning and ending with the same
+// resource, or nil if there is no such cycle.
+func (dg *DependencyGraph) FindCycle() []resource.URN {
+	edges := dg.referenceEdges()
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(dg.resources))
+
+	// Perform an iterative depth-first search from each resource. The stack holds the path from the search's root to
+	// the current resource, along with the index of the next edge to follow from each resource on the path.
+	type frame struct {
+		idx  int
+		next int
+	}
+	for root := range dg.resources {
+		if state[root] != unvisited {
+			continue
+		}
+
+		stack := []frame{{idx: root}}
+		state[root] = visiting
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			if top.next == len(edges[top.idx]) {
+				state[top.idx] = visited
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			to := edges[top.idx][top.next]
+			top.next++
+			switch state[to] {
+			case unvisited:
+				state[to] = visiting
+				stack = append(stack, frame{idx: to})
+			case visiting:
+				// We have found a cycle: it consists of the path from the first visit of `to` to the current resource.
+				var cycle []resource.URN
+				for i := len(stack) - 1; i >= 0; i-- {
+					cycle = append([]resource.URN{dg.resources[stack[i].idx].URN}, cycle...)
+					if stack[i].idx == to {
+						break
+					}
+				}
+				return append(cycle, dg.resources[to].URN)
+			}
+		}
+	}
+	return nil
+}
+
+// referenceEdges resolves the references of each resource in the graph to the indexes of the resources to which they
+// refer. A reference resolves to the closest preceding resource with the referenced URN; if there is none, it
+// resolves to the first following resource with that URN. References to URNs that are not in the graph are dropped.
+func (dg *DependencyGraph) referenceEdges() [][]int {
+	indices := make(map[resource.URN][]int)
+	for idx, res := range dg.resources {
+		indices[res.URN] = append(indices[res.URN], idx)
+	}
+
+	resolve := func(from int, urn resource.URN) (int, bool) {
+		candidates := indices[urn]
+		if len(candidates) == 0 {
+			return 0, false
+		}
+		resolved := candidates[0]
+		for _, idx := range candidates {
+			if idx < from {
+				resolved = idx
+			}
+		}
+		return resolved, resolved != from
+	}
+
+	edges := make([][]int, len(dg.resources))
+	for from, res := range dg.resources {
+		seen := make(map[int]bool)
+		for _, urn := range references(res) {
+			if to, ok := resolve(from, urn); ok && !seen[to] {
+				seen[to] = true
+				edges[from] = append(edges[from], to)
+			}
+		}
+	}
+	return edges
+}
+
+// references returns the URNs to which a resource refers, in a stable order. Unlike directDependencies, references
+// includes the resource's parent, and ignores provider references that cannot be parsed rather than failing, as it is
+// used to diagnose snapshots that may be malformed.
+func references(res *resource.State) []resource.URN {
+	refs := append([]resource.URN{}, res.Dependencies...)
+	for _, deps := range res.PropertyDependencies {
+		refs = append(refs, deps...)
+	}
+	if res.Provider != "" {
+		if ref, err := providers.ParseReference(res.Provider); err == nil {
+			refs = append(refs, ref.URN())
+		}
+	}
+	if res.Parent != "" {
+		refs = append(refs, res.Parent)
+	}
+	if res.DeletedWith != "" {
+		refs = append(refs, res.DeletedWith)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i] < refs[j] })
+	return refs
+}
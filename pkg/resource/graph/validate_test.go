@@ -0,0 +1,76 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+)
+
+func TestValidateValid(t *testing.T) {
+	pA := NewProviderResource("test", "pA", "0")
+	a := NewResource("a", pA)
+	b := NewResource("b", pA, a.URN)
+	b.Parent = a.URN
+
+	// A resource pending deletion may share its URN with a later resource without creating a cycle.
+	oldC := NewResource("c", pA)
+	oldC.Delete = true
+	d := NewResource("d", pA, oldC.URN)
+	c := NewResource("c", pA, d.URN)
+
+	dg := NewDependencyGraph([]*resource.State{pA, a, b, oldC, d, c})
+	assert.NoError(t, dg.Validate())
+	assert.Nil(t, dg.FindCycle())
+}
+
+func TestValidateForwardReference(t *testing.T) {
+	a := NewResource("a", nil)
+	b := NewResource("b", nil)
+	a.PropertyDependencies = map[resource.PropertyKey][]resource.URN{"foo": {b.URN}}
+
+	dg := NewDependencyGraph([]*resource.State{a, b})
+	err := dg.Validate()
+	require.Error(t, err)
+
+	verr, ok := err.(*ValidationError)
+	require.True(t, ok)
+	assert.Equal(t, ValidationForwardReference, verr.Kind)
+	assert.Equal(t, []resource.URN{a.URN, b.URN}, verr.Chain)
+	assert.Nil(t, dg.FindCycle())
+}
+
+func TestValidateCycle(t *testing.T) {
+	a := NewResource("a", nil)
+	b := NewResource("b", nil, a.URN)
+	c := NewResource("c", nil, b.URN)
+	d := NewResource("d", nil)
+	// a's parent is c, which depends on b, which depends on a.
+	a.Parent = c.URN
+
+	dg := NewDependencyGraph([]*resource.State{a, b, c, d})
+	err := dg.Validate()
+	require.Error(t, err)
+
+	verr, ok := err.(*ValidationError)
+	require.True(t, ok)
+	assert.Equal(t, ValidationCycle, verr.Kind)
+	assert.Equal(t, []resource.URN{a.URN, c.URN, b.URN, a.URN}, verr.Chain)
+	assert.Contains(t, err.Error(), string(a.URN)+" -> "+string(c.URN))
+}
@@ -0,0 +1,183 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package age implements support for a secrets manager that encrypts secrets to one or more age recipients.
+package age
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/pulumi/pulumi/pkg/v3/secrets"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+)
+
+// Type is the type of secrets managed by this secrets provider
+const Type = "age"
+
+// URLPrefix is the prefix of the URLs of age secrets providers, which list the recipients that a stack's secrets are
+// encrypted to, separated by commas: `age://<recipient>[,<recipient>...]`.
+const URLPrefix = "age://"
+
+type ageSecretsManagerState struct {
+	URL          string `json:"url"`
+	EncryptedKey []byte `json:"encryptedkey"`
+}
+
+// ParseRecipients returns the age recipients listed by an age secrets provider URL.
+func ParseRecipients(url string) ([]age.Recipient, error) {
+	if !strings.HasPrefix(url, URLPrefix) {
+		return nil, fmt.Errorf("invalid age secrets provider %q; expected %s<recipient>[,<recipient>...]", url, URLPrefix)
+	}
+
+	var recipients []age.Recipient
+	for _, s := range strings.Split(strings.TrimPrefix(url, URLPrefix), ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid age recipient %q: %w", s, err)
+		}
+		recipients = append(recipients, r)
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("age secrets provider %q does not list any recipients", url)
+	}
+	return recipients, nil
+}
+
+// GenerateNewDataKey generates a new data key seeded by a fresh random 32-byte key and encrypted to every recipient
+// listed by the given age secrets provider URL.
+func GenerateNewDataKey(url string) ([]byte, error) {
+	recipients, err := ParseRecipients(url)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintextDataKey := make([]byte, config.SymmetricCrypterKeyBytes)
+	if _, err := rand.Read(plaintextDataKey); err != nil {
+		return nil, err
+	}
+
+	var encrypted bytes.Buffer
+	w, err := age.Encrypt(&encrypted, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintextDataKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return encrypted.Bytes(), nil
+}
+
+// NewAgeSecretsManagerFromState deserializes configuration from state and returns a secrets manager that decrypts
+// its data key with the local age identities.
+func NewAgeSecretsManagerFromState(state json.RawMessage) (secrets.Manager, error) {
+	var s ageSecretsManagerState
+	if err := json.Unmarshal(state, &s); err != nil {
+		return nil, fmt.Errorf("unmarshalling state: %w", err)
+	}
+
+	return NewAgeSecretsManager(s.URL, s.EncryptedKey)
+}
+
+// NewAgeSecretsManager returns a secrets manager that uses the local age identities to decrypt a data key, which was
+// encrypted to the recipients of the given URL and is used for envelope encryption of secrets values.
+func NewAgeSecretsManager(url string, encryptedDataKey []byte) (*Manager, error) {
+	identities, err := loadIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(encryptedDataKey), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data key; none of the local age identities is a recipient of %s: %w",
+			url, err)
+	}
+	plaintextDataKey, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(plaintextDataKey) != config.SymmetricCrypterKeyBytes {
+		return nil, errors.New("decrypted data key has the wrong length")
+	}
+
+	return &Manager{
+		crypter: config.NewSymmetricCrypter(plaintextDataKey),
+		state: ageSecretsManagerState{
+			URL:          url,
+			EncryptedKey: encryptedDataKey,
+		},
+	}, nil
+}
+
+// loadIdentities reads the local age identities from `PULUMI_CONFIG_AGE_IDENTITY`, which holds them directly, or
+// from the file named by `PULUMI_CONFIG_AGE_IDENTITY_FILE`, which defaults to `~/.pulumi/age/keys.txt`.
+func loadIdentities() ([]age.Identity, error) {
+	var r io.Reader
+	if identity, ok := os.LookupEnv("PULUMI_CONFIG_AGE_IDENTITY"); ok {
+		r = strings.NewReader(identity)
+	} else {
+		path, ok := os.LookupEnv("PULUMI_CONFIG_AGE_IDENTITY_FILE")
+		if !ok {
+			p, err := workspace.GetPulumiPath("age", "keys.txt")
+			if err != nil {
+				return nil, err
+			}
+			path = p
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, fmt.Errorf("no age identity found at %s; please set `PULUMI_CONFIG_AGE_IDENTITY` or "+
+					"`PULUMI_CONFIG_AGE_IDENTITY_FILE` to allow the operation to continue", path)
+			}
+			return nil, fmt.Errorf("unable to read age identities: %w", err)
+		}
+		r = bytes.NewReader(b)
+	}
+
+	identities, err := age.ParseIdentities(r)
+	if err != nil {
+		return nil, fmt.Errorf("parsing age identities: %w", err)
+	}
+	return identities, nil
+}
+
+// Manager is the secrets.Manager implementation for age recipients
+type Manager struct {
+	state   ageSecretsManagerState
+	crypter config.Crypter
+}
+
+func (m *Manager) Type() string                         { return Type }
+func (m *Manager) State() interface{}                   { return m.state }
+func (m *Manager) Encrypter() (config.Encrypter, error) { return m.crypter, nil }
+func (m *Manager) Decrypter() (config.Decrypter, error) { return m.crypter, nil }
+func (m *Manager) EncryptedKey() []byte                 { return m.state.EncryptedKey }
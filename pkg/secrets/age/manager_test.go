@@ -0,0 +1,105 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package age
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setIdentityTestEnvVars(identity string) func() {
+	oldIdentity, hadIdentity := os.LookupEnv("PULUMI_CONFIG_AGE_IDENTITY")
+	os.Setenv("PULUMI_CONFIG_AGE_IDENTITY", identity)
+	return func() {
+		if hadIdentity {
+			os.Setenv("PULUMI_CONFIG_AGE_IDENTITY", oldIdentity)
+		} else {
+			os.Unsetenv("PULUMI_CONFIG_AGE_IDENTITY")
+		}
+	}
+}
+
+func TestParseRecipients(t *testing.T) {
+	alice, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	bob, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	recipients, err := ParseRecipients(URLPrefix + alice.Recipient().String() + "," + bob.Recipient().String())
+	assert.NoError(t, err)
+	assert.Len(t, recipients, 2)
+
+	_, err = ParseRecipients(URLPrefix)
+	assert.Error(t, err)
+	_, err = ParseRecipients(URLPrefix + "not-a-recipient")
+	assert.Error(t, err)
+	_, err = ParseRecipients("awskms://alias/ExampleAlias")
+	assert.Error(t, err)
+}
+
+func TestAgeManagerSharedByRecipients(t *testing.T) {
+	alice, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	bob, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	url := URLPrefix + alice.Recipient().String() + "," + bob.Recipient().String()
+
+	dataKey, err := GenerateNewDataKey(url)
+	require.NoError(t, err)
+
+	// Alice encrypts a secret...
+	restore := setIdentityTestEnvVars(alice.String())
+	manager, err := NewAgeSecretsManager(url, dataKey)
+	restore()
+	require.NoError(t, err)
+	enc, err := manager.Encrypter()
+	require.NoError(t, err)
+	ciphertext, err := enc.EncryptValue("hunter2")
+	require.NoError(t, err)
+
+	// ...that Bob can decrypt from the manager's state.
+	state, err := json.Marshal(manager.State())
+	require.NoError(t, err)
+	restore = setIdentityTestEnvVars(bob.String())
+	bobManager, err := NewAgeSecretsManagerFromState(state)
+	restore()
+	require.NoError(t, err)
+	dec, err := bobManager.Decrypter()
+	require.NoError(t, err)
+	plaintext, err := dec.DecryptValue(ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter2", plaintext)
+}
+
+func TestAgeManagerRequiresRecipientIdentity(t *testing.T) {
+	alice, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	eve, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	url := URLPrefix + alice.Recipient().String()
+
+	dataKey, err := GenerateNewDataKey(url)
+	require.NoError(t, err)
+
+	restore := setIdentityTestEnvVars(eve.String())
+	defer restore()
+	_, err = NewAgeSecretsManager(url, dataKey)
+	assert.Error(t, err)
+}
@@ -52,6 +52,13 @@ func TargetDependents() Option {
 	})
 }
 
+// ExcludeProtected skips protected resources, and the resources they depend on, instead of failing the destroy
+func ExcludeProtected() Option {
+	return optionFunc(func(opts *Options) {
+		opts.ExcludeProtected = true
+	})
+}
+
 // ProgressStreams allows specifying one or more io.Writers to redirect incremental destroy output
 func ProgressStreams(writers ...io.Writer) Option {
 	return optionFunc(func(opts *Options) {
@@ -98,6 +105,8 @@ type Options struct {
 	Target []string
 	// Allows updating of dependent targets discovered but not specified in the Target list
 	TargetDependents bool
+	// Skips protected resources, and the resources they depend on, instead of failing the destroy
+	ExcludeProtected bool
 	// ProgressStreams allows specifying one or more io.Writers to redirect incremental destroy output
 	ProgressStreams []io.Writer
 	// EventStreams allows specifying one or more channels to receive the Pulumi event stream
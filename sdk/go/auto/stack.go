@@ -479,6 +479,9 @@ func (s *Stack) Destroy(ctx context.Context, opts ...optdestroy.Option) (Destroy
 	if destroyOpts.TargetDependents {
 		args = append(args, "--target-dependents")
 	}
+	if destroyOpts.ExcludeProtected {
+		args = append(args, "--exclude-protected")
+	}
 	if destroyOpts.Parallel > 0 {
 		args = append(args, fmt.Sprintf("--parallel=%d", destroyOpts.Parallel))
 	}
@@ -324,6 +324,14 @@ type ResourceV3 struct {
 	CustomTimeouts *resource.CustomTimeouts `json:"customTimeouts,omitempty" yaml:"customTimeouts,omitempty"`
 	// ImportID is the import input used for imported resources.
 	ImportID resource.ID `json:"importID,omitempty" yaml:"importID,omitempty"`
+	// RemoveAfter is set when the resource has been deprecated, and is the time after which it will be deleted.
+	RemoveAfter *time.Time `json:"removeAfter,omitempty" yaml:"removeAfter,omitempty"`
+	// DeletedWith is the URN of a resource whose deletion also deletes this resource. Pulumi will not call Delete
+	// for this resource when that resource is deleted in the same deployment.
+	DeletedWith resource.URN `json:"deletedWith,omitempty" yaml:"deletedWith,omitempty"`
+	// Locked is true when the resource has been locked with `pulumi state lock`. Unlike a protected resource, which
+	// only cannot be deleted, a locked resource cannot be updated or replaced either.
+	Locked bool `json:"locked,omitempty" yaml:"locked,omitempty"`
 }
 
 // ManifestV1 captures meta-information about this checkpoint file, such as versions of binaries, etc.
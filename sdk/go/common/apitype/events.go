@@ -184,6 +184,10 @@ type ResOpFailedEvent struct {
 // EngineEvent describes a Pulumi engine event, such as a change to a resource or diagnostic
 // message. EngineEvent is a discriminated union of all possible event types, and exactly one
 // field will be non-nil.
+//
+// An event log written by `--event-log` contains one EngineEvent per line, as JSON. That format is
+// stable: fields may be added to these types, but existing fields are never removed, renamed or
+// given a different meaning.
 type EngineEvent struct {
 	// Sequence is a unique, and monotonically increasing number for each engine event sent to the
 	// Pulumi Service. Since events may be sent concurrently, and/or delayed via network routing,
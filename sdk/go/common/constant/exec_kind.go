@@ -11,3 +11,7 @@ const ExecKindAutoInline = "auto.inline"
 // ExecKindCLI is a flag used to indentify a command as originating
 // from the CLI using a traditional Pulumi project.
 const ExecKindCLI = "cli"
+
+// ExecKindServe is a flag used to indentify a command as originating
+// from a request to `pulumi serve`.
+const ExecKindServe = "serve"
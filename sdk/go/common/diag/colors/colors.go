@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
@@ -129,10 +130,34 @@ func writeDirective(w io.StringWriter, c Colorization, directive Color) {
 	case Black: // command("fg 0") // Only use with background colors.
 		writeCodes(w, "38", "5", "0")
 	default:
+		if codes, ok := paletteCodes(directive); ok {
+			writeCodes(w, codes...)
+			return
+		}
 		contract.Failf("Unrecognized color code: %q", directive)
 	}
 }
 
+// paletteCodes returns the escape codes for a "fg <n>" or "bg <n>" directive that names a color in the 256-color
+// palette.
+func paletteCodes(directive Color) ([]string, bool) {
+	fields := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(directive, colorLeft), colorRight))
+	if len(fields) != 2 {
+		return nil, false
+	}
+	if n, err := strconv.Atoi(fields[1]); err != nil || n < 0 || n > 255 {
+		return nil, false
+	}
+	switch fields[0] {
+	case "fg":
+		return []string{"38", "5", fields[1]}, true
+	case "bg":
+		return []string{"48", "5", fields[1]}, true
+	default:
+		return nil, false
+	}
+}
+
 func colorizeText(s string, c Colorization, maxLen int) string {
 	var buf bytes.Buffer
 
@@ -58,6 +58,8 @@ func TestColorizer(t *testing.T) {
 		{YellowBackground, codes("48", "5", "3")},
 		{BlueBackground, codes("48", "5", "4")},
 		{Black, codes("38", "5", "0")},
+		{command("fg 208"), codes("38", "5", "208")},
+		{command("bg 33"), codes("48", "5", "33")},
 	}
 
 	for _, c := range cases {
@@ -104,3 +106,16 @@ func TestTrimColorizedString(t *testing.T) {
 	actual = TrimColorizedString(plain, len("hello"))
 	assert.Equal(t, "hello", actual)
 }
+
+func TestWrapColorizedString(t *testing.T) {
+	str := "hello, " + Green + "world" + Reset + "!!"
+
+	assert.Equal(t, []string{str}, WrapColorizedString(str, 0))
+	assert.Equal(t, []string{str}, WrapColorizedString(str, len("hello, world!!")))
+	assert.Equal(t, []string{"hello, " + Green + "wo" + Reset, Green + "rld" + Reset + "!!"},
+		WrapColorizedString(str, len("hello, wo")))
+	assert.Equal(t, []string{"hello", ", " + Green + "wor" + Reset, Green + "ld" + Reset + "!!"},
+		WrapColorizedString(str, len("hello")))
+
+	assert.Equal(t, []string{"héllo", "wörld"}, WrapColorizedString("héllowörld", 5))
+}
@@ -15,6 +15,9 @@
 package colors
 
 import (
+	"strings"
+	"unicode/utf8"
+
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
 
@@ -57,3 +60,49 @@ func (c Colorization) Colorize(v string) string {
 func TrimColorizedString(v string, maxRuneLength int) string {
 	return colorizeText(v, Raw, maxRuneLength)
 }
+
+// WrapColorizedString takes a string with embedded color tags and splits it into lines (still with embedded color
+// tags) such that the length of the *non-tag* portion of each line is no greater than maxRuneLength.  A line that
+// ends with a color in effect is reset, and that color is restored at the start of the next line.
+func WrapColorizedString(v string, maxRuneLength int) []string {
+	if maxRuneLength <= 0 {
+		return []string{v}
+	}
+
+	var lines []string
+	var line strings.Builder
+	active, length := "", 0
+	for len(v) > 0 {
+		if strings.HasPrefix(v, colorLeft) {
+			end := strings.Index(v, colorRight)
+			if end == -1 {
+				// Drop a partial command, as colorization would.
+				break
+			}
+			directive := v[:end+len(colorRight)]
+			if directive == Reset {
+				active = ""
+			} else {
+				active += directive
+			}
+			line.WriteString(directive)
+			v = v[len(directive):]
+			continue
+		}
+
+		if length == maxRuneLength {
+			if active != "" {
+				line.WriteString(Reset)
+			}
+			lines = append(lines, line.String())
+			line.Reset()
+			line.WriteString(active)
+			length = 0
+		}
+		r, size := utf8.DecodeRuneInString(v)
+		line.WriteRune(r)
+		length++
+		v = v[size:]
+	}
+	return append(lines, line.String())
+}
@@ -0,0 +1,111 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colors
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Theme is the set of colors used to show the kinds of change that an update makes to resources.
+type Theme struct {
+	Create            Color
+	Update            Color
+	Replace           Color
+	Delete            Color
+	CreateReplacement Color
+	DeleteReplaced    Color
+	Read              Color
+}
+
+// DefaultTheme is the theme used unless another is selected.
+var DefaultTheme = Theme{
+	Create:            Green,
+	Update:            Yellow,
+	Replace:           BrightMagenta,
+	Delete:            Red,
+	CreateReplacement: BrightGreen,
+	DeleteReplaced:    BrightRed,
+	Read:              BrightCyan,
+}
+
+// ColorblindTheme is a theme that tells creates and deletes apart by blue and orange rather than by green and red, so
+// that it remains readable with the common forms of color blindness.
+var ColorblindTheme = Theme{
+	Create:            command("fg 33"),
+	Update:            Yellow,
+	Replace:           BrightMagenta,
+	Delete:            command("fg 208"),
+	CreateReplacement: command("fg 75"),
+	DeleteReplaced:    command("fg 166"),
+	Read:              BrightCyan,
+}
+
+// Themes maps the names of the built-in themes to the themes themselves.
+var Themes = map[string]Theme{
+	"default":    DefaultTheme,
+	"colorblind": ColorblindTheme,
+}
+
+// SetTheme sets the colors used to show the kinds of change that an update makes to resources. Colors that the theme
+// leaves empty are unchanged.
+func SetTheme(theme Theme) {
+	set := func(spec *Color, c Color) {
+		if c != "" {
+			*spec = c
+		}
+	}
+	set(&SpecCreate, theme.Create)
+	set(&SpecUpdate, theme.Update)
+	set(&SpecReplace, theme.Replace)
+	set(&SpecDelete, theme.Delete)
+	set(&SpecCreateReplacement, theme.CreateReplacement)
+	set(&SpecDeleteReplaced, theme.DeleteReplaced)
+	set(&SpecRead, theme.Read)
+}
+
+// namedColors maps the names accepted by ParseColor to their colors.
+var namedColors = map[string]Color{
+	"red":            Red,
+	"green":          Green,
+	"yellow":         Yellow,
+	"blue":           Blue,
+	"magenta":        Magenta,
+	"cyan":           Cyan,
+	"bright-red":     BrightRed,
+	"bright-green":   BrightGreen,
+	"bright-blue":    BrightBlue,
+	"bright-magenta": BrightMagenta,
+	"bright-cyan":    BrightCyan,
+}
+
+// ParseColor parses a foreground color given either by name (e.g. "blue" or "bright-red") or as a number in the
+// 256-color palette, optionally prefixed by "bold ".
+func ParseColor(s string) (Color, error) {
+	var prefix Color
+	s = strings.TrimSpace(s)
+	if rest := strings.TrimPrefix(s, "bold "); rest != s {
+		prefix, s = Bold, strings.TrimSpace(rest)
+	}
+
+	if c, ok := namedColors[strings.ToLower(s)]; ok {
+		return prefix + c, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return prefix + command(fmt.Sprintf("fg %d", n)), nil
+	}
+	return "", fmt.Errorf("unknown color '%s'; expected a color name such as 'blue' or a number from 0 to 255", s)
+}
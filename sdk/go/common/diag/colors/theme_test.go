@@ -0,0 +1,58 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package colors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]Color{
+		"blue":          Blue,
+		"Bright-Red":    BrightRed,
+		"208":           command("fg 208"),
+		"bold green":    Bold + Green,
+		" bold  0 ":     Bold + command("fg 0"),
+		"bold bold red": "",
+		"256":           "",
+		"orange":        "",
+	}
+	for s, expected := range cases {
+		c, err := ParseColor(s)
+		if expected == "" {
+			assert.Error(t, err, s)
+		} else {
+			assert.NoError(t, err, s)
+			assert.Equal(t, expected, c, s)
+		}
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	defer SetTheme(DefaultTheme)
+
+	SetTheme(Theme{Create: Blue})
+	assert.Equal(t, Blue, SpecCreate)
+	assert.Equal(t, Red, SpecDelete)
+
+	SetTheme(ColorblindTheme)
+	assert.Equal(t, codes("38", "5", "208")+"-"+codes("0"), Always.Colorize(SpecDelete+"-"+Reset))
+
+	SetTheme(DefaultTheme)
+	assert.Equal(t, Green, SpecCreate)
+	assert.Equal(t, Red, SpecDelete)
+}
@@ -0,0 +1,34 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"io"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// EventSink provides a pluggable interface for receiving the engine events of an operation as they happen, so that
+// they can be shipped to systems the CLI knows nothing about.  Events are delivered in order, and a sink's failure to
+// handle an event does not affect the operation.
+type EventSink interface {
+	// Closer closes any underlying OS resources associated with this sink (like processes, RPC channels, etc).
+	io.Closer
+	// Name fetches an event sink's qualified name.
+	Name() tokens.QName
+	// OnEvent delivers a single engine event to the sink.
+	OnEvent(event apitype.EngineEvent) error
+}
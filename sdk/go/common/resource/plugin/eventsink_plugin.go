@@ -0,0 +1,110 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/rpcutil/rpcerror"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/workspace"
+	pulumirpc "github.com/pulumi/pulumi/sdk/v3/proto/go"
+)
+
+// eventSink reflects an event sink plugin, loaded dynamically for the duration of a single operation.
+type eventSink struct {
+	ctx    *Context
+	name   tokens.QName
+	plug   *plugin
+	client pulumirpc.EventSinkClient
+}
+
+var _ EventSink = (*eventSink)(nil)
+
+// NewEventSink binds to a given event sink's plugin by name and creates a gRPC connection to it.  If the associated
+// plugin could not be found by name on the PATH, or an error occurs while creating the child process, an error is
+// returned.
+func NewEventSink(host Host, ctx *Context, name tokens.QName) (EventSink, error) {
+	// Load the plugin's path by using the standard workspace logic.
+	_, path, err := workspace.GetPluginPath(
+		workspace.EventSinkPlugin, strings.Replace(string(name), tokens.QNameDelimiter, "_", -1), nil)
+	if err != nil {
+		return nil, rpcerror.Convert(err)
+	} else if path == "" {
+		return nil, workspace.NewMissingError(workspace.PluginInfo{
+			Kind: workspace.EventSinkPlugin,
+			Name: string(name),
+		})
+	}
+
+	plug, err := newPlugin(ctx, ctx.Pwd, path, fmt.Sprintf("%v (event sink)", name),
+		[]string{host.ServerAddr(), ctx.Pwd}, nil /*env*/)
+	if err != nil {
+		return nil, err
+	}
+	contract.Assertf(plug != nil, "unexpected nil event sink plugin for %s", name)
+
+	return &eventSink{
+		ctx:    ctx,
+		name:   name,
+		plug:   plug,
+		client: pulumirpc.NewEventSinkClient(plug.Conn),
+	}, nil
+}
+
+// Name fetches an event sink's qualified name.
+func (s *eventSink) Name() tokens.QName { return s.name }
+
+// label returns a base label for tracing functions.
+func (s *eventSink) label() string {
+	return fmt.Sprintf("EventSink[%s]", s.name)
+}
+
+// OnEvent delivers a single engine event to the sink as the JSON form of the event.
+func (s *eventSink) OnEvent(event apitype.EngineEvent) error {
+	label := fmt.Sprintf("%s.OnEvent(%d)", s.label(), event.Sequence)
+	logging.V(9).Infof("%s executing", label)
+
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	var obj map[string]interface{}
+	if err = json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+	mevent, err := MarshalProperties(resource.NewPropertyMapFromMap(obj), MarshalOptions{Label: label})
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.client.OnEvent(s.ctx.Request(), mevent); err != nil {
+		rpcError := rpcerror.Convert(err)
+		logging.V(7).Infof("%s failed: err=%v", label, rpcError)
+		return rpcError
+	}
+	return nil
+}
+
+// Close tears down the underlying plugin RPC connection and process.
+func (s *eventSink) Close() error {
+	return s.plug.Close()
+}
@@ -0,0 +1,85 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	pbempty "github.com/golang/protobuf/ptypes/empty"
+	structpb "github.com/golang/protobuf/ptypes/struct"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/apitype"
+)
+
+func TestEventSinkOnEvent(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan *structpb.Struct, 1)
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "pulumirpc.EventSink",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "OnEvent",
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error,
+				_ grpc.UnaryServerInterceptor) (interface{}, error) {
+
+				event := &structpb.Struct{}
+				if err := dec(event); err != nil {
+					return nil, err
+				}
+				received <- event
+				return &pbempty.Empty{}, nil
+			},
+		}},
+	}, struct{}{})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if !assert.NoError(t, err) {
+		return
+	}
+	sink := &eventSink{ctx: &Context{}, name: "test", plug: &plugin{Conn: conn}}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	err = sink.OnEvent(apitype.EngineEvent{
+		Sequence:    3,
+		StdoutEvent: &apitype.StdoutEngineEvent{Message: "hello", Color: "never"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	event := <-received
+	assert.Equal(t, float64(3), event.Fields["sequence"].GetNumberValue())
+	stdout := event.Fields["stdoutEvent"].GetStructValue()
+	if assert.NotNil(t, stdout) {
+		assert.Equal(t, "hello", stdout.Fields["message"].GetStringValue())
+	}
+}
@@ -351,6 +351,8 @@ func (host *defaultHost) EnsurePlugins(plugins []workspace.PluginInfo, kinds Fla
 						errors.Wrapf(err, "failed to load analyzer plugin %s", plugin.Name))
 				}
 			}
+		case workspace.EventSinkPlugin:
+			// Event sinks are not used by the engine; the CLI loads them for the operations that request them.
 		case workspace.LanguagePlugin:
 			if kinds&LanguagePlugins != 0 {
 				if _, err := host.LanguageRuntime(plugin.Name); err != nil {
@@ -285,7 +285,7 @@ func execPlugin(bin string, pluginArgs []string, pwd string, env []string) (*plu
 		tracingToFile:   cmdutil.TracingToFile,
 		logFlow:         logging.LogFlow,
 		logToStderr:     logging.LogToStderr,
-		verbose:         logging.Verbose,
+		verbose:         logging.GetVerbosity(),
 	})
 	cmd := exec.Command(bin, args...)
 	cmdutil.RegisterProcessGroup(cmd)
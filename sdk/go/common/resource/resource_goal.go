@@ -38,6 +38,7 @@ type Goal struct {
 	ID                      ID                    // the expected ID of the resource, if any.
 	CustomTimeouts          CustomTimeouts        // an optional config object for resource options
 	ReplaceOnChanges        []string              // a list of property paths that if changed should force a replacement.
+	DeletedWith             URN                   // an optional resource whose deletion also deletes this resource.
 }
 
 // NewGoal allocates a new resource goal state.
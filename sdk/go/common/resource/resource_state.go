@@ -15,6 +15,8 @@
 package resource
 
 import (
+	"time"
+
 	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
 	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
 )
@@ -43,6 +45,9 @@ type State struct {
 	Aliases                 []URN                 // TODO
 	CustomTimeouts          CustomTimeouts        // A config block that will be used to configure timeouts for CRUD operations
 	ImportID                ID                    // the resource's import id, if this was an imported resource.
+	RemoveAfter             *time.Time            // if set, the resource is deprecated and will be deleted after this time.
+	DeletedWith             URN                   // if set, the resource is deleted implicitly when this resource is.
+	Locked                  bool                  // true if the resource is locked, and cannot be updated, replaced or deleted.
 }
 
 // NewState creates a new resource value from existing resource state information.
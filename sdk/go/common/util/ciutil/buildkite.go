@@ -0,0 +1,44 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciutil
+
+import (
+	"os"
+)
+
+// buildkiteCI represents the Buildkite CI system.
+type buildkiteCI struct {
+	baseCI
+}
+
+// DetectVars detects the Buildkite env vars.
+// See https://buildkite.com/docs/pipelines/environment-variables.
+func (b buildkiteCI) DetectVars() Vars {
+	v := Vars{Name: b.Name}
+	v.BuildID = os.Getenv("BUILDKITE_BUILD_ID")
+	v.BuildNumber = os.Getenv("BUILDKITE_BUILD_NUMBER")
+	v.BuildType = os.Getenv("BUILDKITE_SOURCE")
+	v.BuildURL = os.Getenv("BUILDKITE_BUILD_URL")
+	v.SHA = os.Getenv("BUILDKITE_COMMIT")
+	v.BranchName = os.Getenv("BUILDKITE_BRANCH")
+	v.CommitMessage = os.Getenv("BUILDKITE_MESSAGE")
+	// BUILDKITE_PULL_REQUEST is "false" for builds that are not of a pull request.
+	if pr := os.Getenv("BUILDKITE_PULL_REQUEST"); pr != "false" {
+		v.PRNumber = pr
+	}
+	v.Actor = os.Getenv("BUILDKITE_BUILD_CREATOR")
+
+	return v
+}
@@ -16,6 +16,7 @@ package ciutil
 
 import (
 	"os"
+	"strings"
 )
 
 // circleCICI represents the "Circle CI" CI system.
@@ -31,6 +32,15 @@ func (c circleCICI) DetectVars() Vars {
 	v.BuildURL = os.Getenv("CIRCLE_BUILD_URL")
 	v.SHA = os.Getenv("CIRCLE_SHA1")
 	v.BranchName = os.Getenv("CIRCLE_BRANCH")
+	v.PRNumber = os.Getenv("CIRCLE_PR_NUMBER")
+	if v.PRNumber == "" {
+		// CIRCLE_PR_NUMBER is only set for builds of forked PRs, but every PR build has the PR's URL, which ends
+		// in its number.
+		if pr := os.Getenv("CIRCLE_PULL_REQUEST"); pr != "" {
+			v.PRNumber = pr[strings.LastIndex(pr, "/")+1:]
+		}
+	}
+	v.Actor = os.Getenv("CIRCLE_USERNAME")
 
 	return v
 }
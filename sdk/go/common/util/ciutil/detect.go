@@ -48,9 +48,11 @@ var detectors = map[SystemName]system{
 			EnvVarsToDetect: []string{"TF_BUILD"},
 		},
 	},
-	Buildkite: baseCI{
-		Name:            Buildkite,
-		EnvVarsToDetect: []string{"BUILDKITE"},
+	Buildkite: buildkiteCI{
+		baseCI: baseCI{
+			Name:            Buildkite,
+			EnvVarsToDetect: []string{"BUILDKITE"},
+		},
 	},
 	CircleCI: circleCICI{
 		baseCI: baseCI{
@@ -68,9 +70,11 @@ var detectors = map[SystemName]system{
 		Name:              Codeship,
 		EnvValuesToDetect: map[string]string{"CI_NAME": "codeship"},
 	},
-	Drone: baseCI{
-		Name:            Drone,
-		EnvVarsToDetect: []string{"DRONE"},
+	Drone: droneCI{
+		baseCI: baseCI{
+			Name:            Drone,
+			EnvVarsToDetect: []string{"DRONE"},
+		},
 	},
 
 	// GenericCI is used when a CI system in which the CLI is being run,
@@ -122,9 +126,11 @@ var detectors = map[SystemName]system{
 		Name:            TaskCluster,
 		EnvVarsToDetect: []string{"TASK_ID", "RUN_ID"},
 	},
-	TeamCity: baseCI{
-		Name:            TeamCity,
-		EnvVarsToDetect: []string{"TEAMCITY_VERSION"},
+	TeamCity: teamCityCI{
+		baseCI: baseCI{
+			Name:            TeamCity,
+			EnvVarsToDetect: []string{"TEAMCITY_VERSION"},
+		},
 	},
 	Travis: travisCI{
 		baseCI: baseCI{
@@ -132,6 +138,12 @@ var detectors = map[SystemName]system{
 			EnvVarsToDetect: []string{"TRAVIS"},
 		},
 	},
+	Woodpecker: woodpeckerCI{
+		baseCI: baseCI{
+			Name:              Woodpecker,
+			EnvValuesToDetect: map[string]string{"CI": "woodpecker"},
+		},
+	},
 }
 
 // IsCI returns true if we are running in a known CI system.
@@ -147,10 +159,15 @@ func detectSystem() system {
 		return nil
 	}
 
-	for _, system := range detectors {
-		if system.IsCI() {
+	for name, system := range detectors {
+		if name != GenericCI && system.IsCI() {
 			return system
 		}
 	}
+	// Only fall back to the generic CI system if we don't recognize the one we're running in, since the generic
+	// `PULUMI_CI_*` env vars are applied on top of the detected system's vars anyway.
+	if generic := detectors[GenericCI]; generic.IsCI() {
+		return generic
+	}
 	return nil
 }
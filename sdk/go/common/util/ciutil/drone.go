@@ -0,0 +1,46 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciutil
+
+import (
+	"os"
+)
+
+// droneCI represents the Drone CI system.
+type droneCI struct {
+	baseCI
+}
+
+// IsCI returns true if the Drone env vars are set. Older versions of Woodpecker CI also set them for compatibility,
+// so we don't consider those to be Drone.
+func (d droneCI) IsCI() bool {
+	return d.baseCI.IsCI() && os.Getenv("CI") != "woodpecker"
+}
+
+// DetectVars detects the Drone env vars.
+// See https://docs.drone.io/pipeline/environment/reference/.
+func (d droneCI) DetectVars() Vars {
+	v := Vars{Name: d.Name}
+	v.BuildID = os.Getenv("DRONE_BUILD_NUMBER")
+	v.BuildType = os.Getenv("DRONE_BUILD_EVENT")
+	v.BuildURL = os.Getenv("DRONE_BUILD_LINK")
+	v.SHA = os.Getenv("DRONE_COMMIT_SHA")
+	v.BranchName = os.Getenv("DRONE_SOURCE_BRANCH")
+	v.CommitMessage = os.Getenv("DRONE_COMMIT_MESSAGE")
+	v.PRNumber = os.Getenv("DRONE_PULL_REQUEST")
+	v.Actor = os.Getenv("DRONE_COMMIT_AUTHOR")
+
+	return v
+}
@@ -28,16 +28,35 @@ type genericCICI struct {
 
 // DetectVars detects the env vars for a Generic CI system.
 func (g genericCICI) DetectVars() Vars {
-	v := Vars{}
-	v.Name = SystemName(os.Getenv("PULUMI_CI_SYSTEM"))
-	v.BranchName = os.Getenv("PULUMI_CI_BRANCH_NAME")
-	v.BuildID = os.Getenv("PULUMI_CI_BUILD_ID")
-	v.BuildNumber = os.Getenv("PULUMI_CI_BUILD_NUMBER")
-	v.BuildType = os.Getenv("PULUMI_CI_BUILD_TYPE")
-	v.BuildURL = os.Getenv("PULUMI_CI_BUILD_URL")
-	v.CommitMessage = os.Getenv("PULUMI_COMMIT_MESSAGE")
-	v.PRNumber = os.Getenv("PULUMI_PR_NUMBER")
-	v.SHA = os.Getenv("PULUMI_CI_PULL_REQUEST_SHA")
+	return overrideVars(Vars{})
+}
+
+// genericVars maps the env vars that users can set to describe their CI build to the fields of `Vars` they set.
+var genericVars = []struct {
+	envVar string
+	field  func(v *Vars) *string
+}{
+	{"PULUMI_CI_BRANCH_NAME", func(v *Vars) *string { return &v.BranchName }},
+	{"PULUMI_CI_BUILD_ID", func(v *Vars) *string { return &v.BuildID }},
+	{"PULUMI_CI_BUILD_NUMBER", func(v *Vars) *string { return &v.BuildNumber }},
+	{"PULUMI_CI_BUILD_TYPE", func(v *Vars) *string { return &v.BuildType }},
+	{"PULUMI_CI_BUILD_URL", func(v *Vars) *string { return &v.BuildURL }},
+	{"PULUMI_COMMIT_MESSAGE", func(v *Vars) *string { return &v.CommitMessage }},
+	{"PULUMI_PR_NUMBER", func(v *Vars) *string { return &v.PRNumber }},
+	{"PULUMI_CI_PULL_REQUEST_SHA", func(v *Vars) *string { return &v.SHA }},
+	{"PULUMI_CI_ACTOR", func(v *Vars) *string { return &v.Actor }},
+}
 
+// overrideVars replaces the fields of the given `Vars` with the values of any of the generic `PULUMI_CI_*` env vars
+// that are set. This lets users fill in, or correct, the metadata for any CI system, not just unrecognized ones.
+func overrideVars(v Vars) Vars {
+	if name := os.Getenv("PULUMI_CI_SYSTEM"); name != "" {
+		v.Name = SystemName(name)
+	}
+	for _, generic := range genericVars {
+		if value := os.Getenv(generic.envVar); value != "" {
+			*generic.field(&v) = value
+		}
+	}
 	return v
 }
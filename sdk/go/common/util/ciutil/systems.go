@@ -47,6 +47,7 @@ const (
 	TaskCluster   SystemName = "TaskCluster"
 	TeamCity      SystemName = "TeamCity"
 	Travis        SystemName = "Travis CI"
+	Woodpecker    SystemName = "Woodpecker CI"
 )
 
 // SystemName is a recognized CI system.
@@ -86,6 +87,8 @@ type Vars struct {
 	CommitMessage string
 	// PRNumber is the pull-request ID/number in the source control system.
 	PRNumber string
+	// Actor is the user or account that triggered this build/job.
+	Actor string
 }
 
 // baseCI implements the `System` interface with default
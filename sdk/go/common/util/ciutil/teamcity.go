@@ -0,0 +1,36 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciutil
+
+import (
+	"os"
+)
+
+// teamCityCI represents the TeamCity CI system.
+type teamCityCI struct {
+	baseCI
+}
+
+// DetectVars detects the TeamCity env vars. TeamCity doesn't expose the build's URL or who triggered it as env vars
+// by default, so those are left blank unless they are set with the generic `PULUMI_CI_*` env vars.
+// See https://www.jetbrains.com/help/teamcity/predefined-build-parameters.html.
+func (tc teamCityCI) DetectVars() Vars {
+	v := Vars{Name: tc.Name}
+	v.BuildID = os.Getenv("BUILD_NUMBER")
+	v.BuildType = os.Getenv("TEAMCITY_BUILDCONF_NAME")
+	v.SHA = os.Getenv("BUILD_VCS_NUMBER")
+
+	return v
+}
@@ -34,5 +34,5 @@ func DetectVars() Vars {
 	// Detect the vars for the respective CI system and
 	v = system.DetectVars()
 
-	return v
+	return overrideVars(v)
 }
@@ -35,6 +35,13 @@ func TestDetectVars(t *testing.T) {
 			"BUILD_BUILDID":  buildNumber,
 			"GITHUB_ACTIONS": "",
 		},
+		Buildkite: {
+			"TRAVIS":                 "",
+			"BUILDKITE":              "true",
+			"BUILDKITE_BUILD_ID":     buildID,
+			"BUILDKITE_BUILD_NUMBER": buildNumber,
+			"GITHUB_ACTIONS":         "",
+		},
 		CircleCI: {
 			"TRAVIS":           "",
 			"CIRCLECI":         "true",
@@ -47,6 +54,12 @@ func TestDetectVars(t *testing.T) {
 			"CF_BUILD_ID":    buildNumber,
 			"GITHUB_ACTIONS": "",
 		},
+		Drone: {
+			"TRAVIS":             "",
+			"DRONE":              "true",
+			"DRONE_BUILD_NUMBER": buildNumber,
+			"GITHUB_ACTIONS":     "",
+		},
 		GenericCI: {
 			"TRAVIS":             "",
 			"PULUMI_CI_SYSTEM":   "generic-ci-system",
@@ -60,12 +73,25 @@ func TestDetectVars(t *testing.T) {
 			"CI_PIPELINE_IID": buildNumber,
 			"GITHUB_ACTIONS":  "",
 		},
+		TeamCity: {
+			"TRAVIS":           "",
+			"TEAMCITY_VERSION": "2021.1",
+			"BUILD_NUMBER":     buildNumber,
+			"GITHUB_ACTIONS":   "",
+		},
 		Travis: {
 			"TRAVIS":            "true",
 			"TRAVIS_JOB_ID":     buildID,
 			"TRAVIS_JOB_NUMBER": buildNumber,
 			"GITHUB_ACTIONS":    "",
 		},
+		Woodpecker: {
+			"TRAVIS":          "",
+			"CI":              "woodpecker",
+			"DRONE":           "true",
+			"CI_BUILD_NUMBER": buildNumber,
+			"GITHUB_ACTIONS":  "",
+		},
 	}
 
 	for system := range systemAndEnvVars {
@@ -169,3 +195,36 @@ func TestDetectVarsDisableCIDetection(t *testing.T) {
 	os.Setenv("TRAVIS", "")
 	os.Setenv("TRAVIS_JOB_ID", "")
 }
+
+func TestDetectVarsOverrides(t *testing.T) {
+	t.Setenv("TRAVIS", "")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("CIRCLECI", "true")
+	t.Setenv("CIRCLE_BUILD_NUM", "123")
+	t.Setenv("CIRCLE_BUILD_URL", "https://circleci.com/gh/org/repo/123")
+	t.Setenv("CIRCLE_PULL_REQUEST", "https://github.com/org/repo/pull/42")
+	t.Setenv("CIRCLE_USERNAME", "alice")
+
+	v := DetectVars()
+	assert.Equal(t, CircleCI, v.Name)
+	assert.Equal(t, "123", v.BuildID)
+	assert.Equal(t, "https://circleci.com/gh/org/repo/123", v.BuildURL)
+	assert.Equal(t, "42", v.PRNumber)
+	assert.Equal(t, "alice", v.Actor)
+
+	// The generic env vars take precedence over the ones detected for the CI system, but don't change the detected
+	// system unless PULUMI_CI_SYSTEM is set.
+	t.Setenv("PULUMI_CI_ACTOR", "bob")
+	t.Setenv("PULUMI_CI_BUILD_URL", "https://example.com/builds/123")
+
+	v = DetectVars()
+	assert.Equal(t, CircleCI, v.Name)
+	assert.Equal(t, "123", v.BuildID)
+	assert.Equal(t, "https://example.com/builds/123", v.BuildURL)
+	assert.Equal(t, "bob", v.Actor)
+
+	t.Setenv("PULUMI_CI_SYSTEM", "my-ci")
+	v = DetectVars()
+	assert.Equal(t, SystemName("my-ci"), v.Name)
+	assert.Equal(t, "123", v.BuildID)
+}
@@ -0,0 +1,48 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ciutil
+
+import (
+	"os"
+)
+
+// woodpeckerCI represents the Woodpecker CI system.
+type woodpeckerCI struct {
+	baseCI
+}
+
+// DetectVars detects the Woodpecker CI env vars. Older versions of Woodpecker name them CI_BUILD_* rather than
+// CI_PIPELINE_*, so we fall back to those.
+// See https://woodpecker-ci.org/docs/usage/environment.
+func (w woodpeckerCI) DetectVars() Vars {
+	getenv := func(key, fallback string) string {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+		return os.Getenv(fallback)
+	}
+
+	v := Vars{Name: w.Name}
+	v.BuildID = getenv("CI_PIPELINE_NUMBER", "CI_BUILD_NUMBER")
+	v.BuildType = getenv("CI_PIPELINE_EVENT", "CI_BUILD_EVENT")
+	v.BuildURL = getenv("CI_PIPELINE_URL", "CI_BUILD_LINK")
+	v.SHA = os.Getenv("CI_COMMIT_SHA")
+	v.BranchName = os.Getenv("CI_COMMIT_BRANCH")
+	v.CommitMessage = os.Getenv("CI_COMMIT_MESSAGE")
+	v.PRNumber = os.Getenv("CI_COMMIT_PULL_REQUEST")
+	v.Actor = os.Getenv("CI_COMMIT_AUTHOR")
+
+	return v
+}
@@ -0,0 +1,28 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	ps "github.com/mitchellh/go-ps"
+)
+
+// ProcessExists returns true if a process with the given ID is running on this machine.
+func ProcessExists(pid int) (bool, error) {
+	proc, err := ps.FindProcess(pid)
+	if err != nil {
+		return false, err
+	}
+	return proc != nil, nil
+}
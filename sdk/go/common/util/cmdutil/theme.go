@@ -0,0 +1,99 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+)
+
+// DisplayThemeEnvVar is the environment variable that selects a built-in color theme, overriding the theme named in
+// the display settings file.
+const DisplayThemeEnvVar = "PULUMI_DISPLAY_THEME"
+
+// displaySettings is the document in the display settings file, e.g.:
+//
+//	theme: colorblind
+//	colors:
+//	  create: bold blue
+//	  delete: 208
+type displaySettings struct {
+	Theme  string            `yaml:"theme,omitempty"`
+	Colors map[string]string `yaml:"colors,omitempty"`
+}
+
+// LoadDisplayTheme sets the colors used to show the kinds of change an update makes to resources from the display
+// settings file at the given path, if it exists, and the PULUMI_DISPLAY_THEME environment variable, if it is set.
+func LoadDisplayTheme(path string) error {
+	var settings displaySettings
+	b, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if err = yaml.Unmarshal(b, &settings); err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	if name := os.Getenv(DisplayThemeEnvVar); name != "" {
+		settings.Theme = name
+	}
+
+	theme, err := settings.theme()
+	if err != nil {
+		return errors.Wrapf(err, "loading the display theme from %s", path)
+	}
+	colors.SetTheme(theme)
+	return nil
+}
+
+// theme returns the built-in theme named by the settings, with any colors they set overriding its own.
+func (settings displaySettings) theme() (colors.Theme, error) {
+	name := settings.Theme
+	if name == "" {
+		name = "default"
+	}
+	theme, ok := colors.Themes[name]
+	if !ok {
+		return colors.Theme{}, errors.Errorf("unknown theme '%s'; the built-in themes are default and colorblind", name)
+	}
+
+	fields := map[string]*colors.Color{
+		"create":            &theme.Create,
+		"update":            &theme.Update,
+		"replace":           &theme.Replace,
+		"delete":            &theme.Delete,
+		"createReplacement": &theme.CreateReplacement,
+		"deleteReplaced":    &theme.DeleteReplaced,
+		"read":              &theme.Read,
+	}
+	for kind, value := range settings.Colors {
+		field, ok := fields[kind]
+		if !ok {
+			return colors.Theme{}, errors.Errorf("unknown kind of change '%s' in colors", kind)
+		}
+		c, err := colors.ParseColor(value)
+		if err != nil {
+			return colors.Theme{}, errors.Wrapf(err, "color for '%s'", kind)
+		}
+		*field = c
+	}
+	return theme, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmdutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/diag/colors"
+)
+
+func TestDisplaySettingsTheme(t *testing.T) {
+	theme, err := displaySettings{}.theme()
+	assert.NoError(t, err)
+	assert.Equal(t, colors.DefaultTheme, theme)
+
+	theme, err = displaySettings{
+		Theme:  "colorblind",
+		Colors: map[string]string{"create": "bold blue", "deleteReplaced": "9"},
+	}.theme()
+	assert.NoError(t, err)
+	assert.Equal(t, colors.Bold+colors.Blue, theme.Create)
+	assert.Equal(t, colors.BrightRed, theme.DeleteReplaced)
+	assert.Equal(t, colors.ColorblindTheme.Delete, theme.Delete)
+
+	_, err = displaySettings{Theme: "solarized"}.theme()
+	assert.Error(t, err)
+	_, err = displaySettings{Colors: map[string]string{"import": "blue"}}.theme()
+	assert.Error(t, err)
+	_, err = displaySettings{Colors: map[string]string{"create": "teal"}}.theme()
+	assert.Error(t, err)
+}
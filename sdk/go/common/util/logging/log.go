@@ -25,9 +25,12 @@ package logging
 import (
 	"flag"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/glog"
 )
@@ -37,26 +40,102 @@ type Filter interface {
 }
 
 var LogToStderr = false // true if logging is being redirected to stderr.
-var Verbose = 0         // >0 if verbose logging is enabled at a particular level.
+var Verbose = 0         // >0 if verbose logging was enabled at startup; see GetVerbosity for the current level.
 var LogFlow = false     // true to flow logging settings to child processes.
 
+// verbosity is the current verbose logging level, which may change while the process runs. It is accessed atomically.
+var verbosity int32
+
 var rwLock sync.RWMutex
 var filters []Filter
 
-func V(level glog.Level) glog.Verbose {
-	return glog.V(level)
+var tailLock sync.Mutex
+var tail io.Writer
+
+// VerboseLogger is a boolean type that implements Info, Infoln and Infof. Messages are forwarded to glog's verbose
+// logging and are also written to the log tail, if one has been set.
+type VerboseLogger glog.Verbose
+
+func V(level glog.Level) VerboseLogger {
+	return VerboseLogger(glog.V(level))
+}
+
+func (v VerboseLogger) Info(args ...interface{}) {
+	if v {
+		msg := fmt.Sprint(args...)
+		glog.InfoDepth(1, msg)
+		writeTail("I", msg)
+	}
+}
+
+func (v VerboseLogger) Infoln(args ...interface{}) {
+	if v {
+		msg := fmt.Sprintln(args...)
+		glog.InfoDepth(1, msg)
+		writeTail("I", msg)
+	}
+}
+
+func (v VerboseLogger) Infof(format string, args ...interface{}) {
+	if v {
+		msg := fmt.Sprintf(format, args...)
+		glog.InfoDepth(1, msg)
+		writeTail("I", msg)
+	}
 }
 
 func Errorf(format string, args ...interface{}) {
-	glog.Errorf("%s", FilterString(fmt.Sprintf(format, args...)))
+	msg := FilterString(fmt.Sprintf(format, args...))
+	glog.ErrorDepth(1, msg)
+	writeTail("E", msg)
 }
 
 func Infof(format string, args ...interface{}) {
-	glog.Infof("%s", FilterString(fmt.Sprintf(format, args...)))
+	msg := FilterString(fmt.Sprintf(format, args...))
+	glog.InfoDepth(1, msg)
+	writeTail("I", msg)
 }
 
 func Warningf(format string, args ...interface{}) {
-	glog.Warningf("%s", FilterString(fmt.Sprintf(format, args...)))
+	msg := FilterString(fmt.Sprintf(format, args...))
+	glog.WarningDepth(1, msg)
+	writeTail("W", msg)
+}
+
+// GetVerbosity returns the current verbose logging level of the process.
+func GetVerbosity() int {
+	return int(atomic.LoadInt32(&verbosity))
+}
+
+// SetVerbosity changes the verbose logging level of the current process. Plugins that have already been started keep
+// the level they were started with.
+func SetVerbosity(verbose int) {
+	atomic.StoreInt32(&verbosity, int32(verbose))
+	err := flag.Lookup("v").Value.Set(strconv.Itoa(verbose))
+	assertNoError(err)
+}
+
+// SetTail directs a copy of each message that is logged to w, in addition to the usual log destination, so that the log
+// can be followed while an operation runs. Messages written to the tail are filtered like those passed to Infof.
+// Passing nil stops copying messages.
+func SetTail(w io.Writer) {
+	tailLock.Lock()
+	defer tailLock.Unlock()
+	tail = w
+}
+
+func writeTail(severity, msg string) {
+	tailLock.Lock()
+	defer tailLock.Unlock()
+	if tail == nil {
+		return
+	}
+	msg = strings.TrimSuffix(FilterString(msg), "\n")
+	_, err := fmt.Fprintf(tail, "%s%s %s\n", severity, time.Now().Format("0102 15:04:05.000000"), msg)
+	if err != nil {
+		// Stop writing to a tail that has failed rather than failing every subsequent log call.
+		tail = nil
+	}
 }
 
 func Flush() {
@@ -68,6 +147,7 @@ func InitLogging(logToStderr bool, verbose int, logFlow bool) {
 	// Remember the settings in case someone inquires.
 	LogToStderr = logToStderr
 	Verbose = verbose
+	atomic.StoreInt32(&verbosity, int32(verbose))
 	LogFlow = logFlow
 
 	// glog uses golang's built in flags package to set configuration values, which is incompatible with how
@@ -15,6 +15,8 @@
 package logging
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,3 +54,28 @@ func TestFilter(t *testing.T) {
 	msg4 := filter4.Filter("These are my secrets: a, my, 123")
 	assert.Equal(t, msg4, "These are my secrets: a, my, [creds]")
 }
+
+func TestTail(t *testing.T) {
+	InitLogging(LogToStderr, Verbose, LogFlow)
+	defer SetVerbosity(GetVerbosity())
+
+	var buf bytes.Buffer
+	SetTail(&buf)
+	defer SetTail(nil)
+
+	SetVerbosity(5)
+	V(5).Infof("visible at %d", 5)
+	V(7).Infof("hidden at %d", 7)
+
+	// Raising the verbosity takes effect immediately.
+	SetVerbosity(7)
+	assert.Equal(t, 7, GetVerbosity())
+	V(7).Infof("visible at %d", 7)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasPrefix(lines[0], "I"))
+		assert.True(t, strings.HasSuffix(lines[0], " visible at 5"))
+		assert.True(t, strings.HasSuffix(lines[1], " visible at 7"))
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+	"github.com/pulumi/pulumi/sdk/v3/go/common/tokens"
+)
+
+// The types that a config key may be declared with in a project's config schema.
+const (
+	ConfigTypeString  = "string"
+	ConfigTypeInteger = "integer"
+	ConfigTypeNumber  = "number"
+	ConfigTypeBoolean = "boolean"
+	ConfigTypeArray   = "array"
+	ConfigTypeObject  = "object"
+)
+
+// ProjectConfigType declares the type of a config key in a project's config schema, and the constraints that the
+// stacks of the project must satisfy when they set it.
+type ProjectConfigType struct {
+	// Type is the type of the value: string, integer, number, boolean, array or object. Defaults to string.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Description is an optional description of the config key.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Required may be set to true to require every stack to set the config key.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+	// AllowedValues optionally lists the only values the config key may be set to.
+	AllowedValues []string `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+	// Secret may be set to true to require the value to be encrypted.
+	Secret bool `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// configSchemaKey returns the config key that a key of the project's config schema refers to. Keys without a
+// namespace are in the project's namespace.
+func (proj *Project) configSchemaKey(name string) (config.Key, error) {
+	if !strings.Contains(name, tokens.TokenDelimiter) {
+		name = fmt.Sprintf("%s:%s", proj.Name, name)
+	}
+	return config.ParseKey(name)
+}
+
+// validateConfigSchema checks that the project's config schema is well-formed.
+func (proj *Project) validateConfigSchema() error {
+	for name, t := range proj.ConfigSchema {
+		if _, err := proj.configSchemaKey(name); err != nil {
+			return fmt.Errorf("invalid config schema key '%s': %w", name, err)
+		}
+		switch t.Type {
+		case "", ConfigTypeString, ConfigTypeInteger, ConfigTypeNumber, ConfigTypeBoolean,
+			ConfigTypeArray, ConfigTypeObject:
+		default:
+			return fmt.Errorf("config schema key '%s' has unknown type '%s'", name, t.Type)
+		}
+	}
+	return nil
+}
+
+// lookupConfigType returns the schema of the given config key, if the project declares one.
+func (proj *Project) lookupConfigType(key config.Key) (ProjectConfigType, bool) {
+	for name, t := range proj.ConfigSchema {
+		if k, err := proj.configSchemaKey(name); err == nil && k == key {
+			return t, true
+		}
+	}
+	return ProjectConfigType{}, false
+}
+
+// ValidateConfigValue checks a single config value against the project's config schema. Keys that the schema does
+// not declare are always valid.
+func (proj *Project) ValidateConfigValue(key config.Key, value string, secret bool) error {
+	t, ok := proj.lookupConfigType(key)
+	if !ok {
+		return nil
+	}
+	if t.Secret && !secret {
+		return fmt.Errorf("config key '%s' must be a secret; set it with --secret", key)
+	}
+	return t.check(key, value)
+}
+
+// ValidateConfig checks a stack's config against the project's config schema, reporting every key that is missing
+// or invalid. Secure values are decrypted with the given decrypter to check their types, and are never included in
+// the returned error; if the decrypter is nil, only their presence is checked.
+func (proj *Project) ValidateConfig(cfg config.Map, dec config.Decrypter) error {
+	names := make([]string, 0, len(proj.ConfigSchema))
+	for name := range proj.ConfigSchema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result error
+	for _, name := range names {
+		t := proj.ConfigSchema[name]
+		key, err := proj.configSchemaKey(name)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("invalid config schema key '%s': %w", name, err))
+			continue
+		}
+
+		v, ok := cfg[key]
+		if !ok {
+			if t.Required {
+				result = multierror.Append(result, fmt.Errorf("missing required config key '%s'", key))
+			}
+			continue
+		}
+		if t.Secret && !v.Secure() {
+			result = multierror.Append(result, fmt.Errorf("config key '%s' must be a secret", key))
+			continue
+		}
+		if v.Secure() && dec == nil {
+			continue
+		}
+
+		value, err := v.Value(dec)
+		if err != nil {
+			result = multierror.Append(result, fmt.Errorf("could not decrypt config key '%s': %w", key, err))
+			continue
+		}
+		if err := t.check(key, value); err != nil {
+			if v.Secure() {
+				err = fmt.Errorf("secret config key '%s' is not a valid %s", key, t.typeName())
+			}
+			result = multierror.Append(result, err)
+		}
+	}
+	return result
+}
+
+// typeName returns the name of the type, which defaults to string.
+func (t ProjectConfigType) typeName() string {
+	if t.Type == "" {
+		return ConfigTypeString
+	}
+	return t.Type
+}
+
+// check checks that a value has the declared type and is one of the allowed values, if any.
+func (t ProjectConfigType) check(key config.Key, value string) error {
+	var ok bool
+	switch t.typeName() {
+	case ConfigTypeString:
+		ok = true
+	case ConfigTypeInteger:
+		_, err := strconv.ParseInt(value, 10, 64)
+		ok = err == nil
+	case ConfigTypeNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		ok = err == nil
+	case ConfigTypeBoolean:
+		_, err := strconv.ParseBool(value)
+		ok = err == nil
+	case ConfigTypeArray:
+		var v []interface{}
+		ok = json.Unmarshal([]byte(value), &v) == nil
+	case ConfigTypeObject:
+		var v map[string]interface{}
+		ok = json.Unmarshal([]byte(value), &v) == nil && v != nil
+	}
+	if !ok {
+		return fmt.Errorf("config key '%s' must be a valid %s; got '%s'", key, t.typeName(), value)
+	}
+
+	if len(t.AllowedValues) > 0 {
+		for _, allowed := range t.AllowedValues {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("config key '%s' must be one of %s; got '%s'",
+			key, strings.Join(t.AllowedValues, ", "), value)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/resource/config"
+)
+
+func newConfigSchemaProject(t *testing.T) *Project {
+	var proj Project
+	err := yaml.Unmarshal([]byte(`
+name: proj
+runtime: nodejs
+configSchema:
+  region:
+    required: true
+    allowedValues: [us-east-1, us-west-2]
+  instanceCount:
+    type: integer
+  dbPassword:
+    secret: true
+    required: true
+  aws:tags:
+    type: object
+`), &proj)
+	assert.NoError(t, err)
+	assert.NoError(t, proj.Validate())
+	return &proj
+}
+
+func TestValidateConfig(t *testing.T) {
+	proj := newConfigSchemaProject(t)
+
+	valid := config.Map{
+		config.MustMakeKey("proj", "region"):        config.NewValue("us-west-2"),
+		config.MustMakeKey("proj", "instanceCount"): config.NewValue("3"),
+		config.MustMakeKey("proj", "dbPassword"):    config.NewSecureValue("c2VjcmV0"),
+		config.MustMakeKey("aws", "tags"):           config.NewObjectValue(`{"team":"infra"}`),
+		config.MustMakeKey("proj", "undeclared"):    config.NewValue("anything"),
+	}
+	assert.NoError(t, proj.ValidateConfig(valid, config.NewBlindingDecrypter()))
+	assert.NoError(t, proj.ValidateConfig(valid, nil))
+
+	invalid := config.Map{
+		config.MustMakeKey("proj", "region"):        config.NewValue("eu-west-1"),
+		config.MustMakeKey("proj", "instanceCount"): config.NewValue("three"),
+		config.MustMakeKey("aws", "tags"):           config.NewValue("infra"),
+	}
+	err := proj.ValidateConfig(invalid, nil)
+	assert.Error(t, err)
+	msg := err.Error()
+	assert.Contains(t, msg, "config key 'aws:tags' must be a valid object")
+	assert.Contains(t, msg, "missing required config key 'proj:dbPassword'")
+	assert.Contains(t, msg, "config key 'proj:instanceCount' must be a valid integer")
+	assert.Contains(t, msg, "config key 'proj:region' must be one of us-east-1, us-west-2")
+
+	plaintext := config.Map{
+		config.MustMakeKey("proj", "region"):     config.NewValue("us-east-1"),
+		config.MustMakeKey("proj", "dbPassword"): config.NewValue("hunter2"),
+	}
+	err = proj.ValidateConfig(plaintext, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "config key 'proj:dbPassword' must be a secret")
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestValidateConfigValue(t *testing.T) {
+	proj := newConfigSchemaProject(t)
+
+	assert.NoError(t, proj.ValidateConfigValue(config.MustMakeKey("proj", "region"), "us-east-1", false))
+	assert.Error(t, proj.ValidateConfigValue(config.MustMakeKey("proj", "region"), "mars-1", false))
+	assert.Error(t, proj.ValidateConfigValue(config.MustMakeKey("proj", "instanceCount"), "1.5", false))
+	assert.Error(t, proj.ValidateConfigValue(config.MustMakeKey("proj", "dbPassword"), "hunter2", false))
+	assert.NoError(t, proj.ValidateConfigValue(config.MustMakeKey("proj", "dbPassword"), "hunter2", true))
+	assert.NoError(t, proj.ValidateConfigValue(config.MustMakeKey("other", "region"), "anything", false))
+}
+
+func TestValidateConfigSchema(t *testing.T) {
+	proj := &Project{
+		Name:         "proj",
+		Runtime:      NewProjectRuntimeInfo("nodejs", nil),
+		ConfigSchema: map[string]ProjectConfigType{"count": {Type: "int"}},
+	}
+	assert.EqualError(t, proj.Validate(), "config schema key 'count' has unknown type 'int'")
+}
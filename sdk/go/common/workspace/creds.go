@@ -256,8 +256,15 @@ type BackendConfig struct {
 	DefaultOrg string `json:"defaultOrg,omitempty"` // The default org for this backend config.
 }
 
+// Profile is a named set of backend and secrets provider settings that can be applied when creating stacks.
+type Profile struct {
+	Backend         string `json:"backend,omitempty"`         // The backend URL stacks should be created in.
+	SecretsProvider string `json:"secretsProvider,omitempty"` // The secrets provider stacks should use.
+}
+
 type PulumiConfig struct {
 	BackendConfig map[string]BackendConfig `json:"backends,omitempty"` // a map of arbitrary backends configs.
+	Profiles      map[string]Profile       `json:"profiles,omitempty"` // a map of profile names to profiles.
 }
 
 func getConfigFilePath() (string, error) {
@@ -371,3 +378,45 @@ func GetBackendConfigDefaultOrg() (string, error) {
 
 	return "", nil
 }
+
+// GetProfile returns the profile stored underneath the given name. The second return value is false if no such
+// profile exists.
+func GetProfile(name string) (Profile, bool, error) {
+	config, err := GetPulumiConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return Profile{}, false, err
+	}
+
+	profile, ok := config.Profiles[name]
+	return profile, ok, nil
+}
+
+// StoreProfile saves the given profile underneath the given name, replacing any existing profile with that name.
+func StoreProfile(name string, profile Profile) error {
+	config, err := GetPulumiConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if config.Profiles == nil {
+		config.Profiles = make(map[string]Profile)
+	}
+	config.Profiles[name] = profile
+
+	return StorePulumiConfig(config)
+}
+
+// DeleteProfile removes the profile stored underneath the given name, if any.
+func DeleteProfile(name string) error {
+	config, err := GetPulumiConfig()
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if _, ok := config.Profiles[name]; !ok {
+		return errors.Errorf("no profile named '%s' found", name)
+	}
+	delete(config.Profiles, name)
+
+	return StorePulumiConfig(config)
+}
@@ -28,3 +28,29 @@ func TestConcurrentCredentialsWrites(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestProfiles(t *testing.T) {
+	t.Setenv(PulumiCredentialsPathEnvVar, t.TempDir())
+
+	_, ok, err := GetProfile("team-prod")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	expected := Profile{
+		Backend:         "s3://my-bucket",
+		SecretsProvider: "awskms://alias/ExampleAlias?region=us-east-1",
+	}
+	assert.NoError(t, StoreProfile("team-prod", expected))
+
+	actual, ok, err := GetProfile("team-prod")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, expected, actual)
+
+	assert.NoError(t, DeleteProfile("team-prod"))
+	_, ok, err = GetProfile("team-prod")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.Error(t, DeleteProfile("team-prod"))
+}
@@ -0,0 +1,157 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"sort"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/logging"
+)
+
+// PluginPrunePolicy decides which plugins are removed from the plugin cache by `pulumi plugin prune`. A plugin is
+// removed if any of the policy's limits call for it. The zero value removes nothing.
+type PluginPrunePolicy struct {
+	// KeepLatest is the number of versions of each plugin to keep, newest first, if it is not zero.
+	KeepLatest int `yaml:"keepLatest,omitempty"`
+	// MaxSizeMB is the size in megabytes that the plugin cache is kept under, if it is not zero, by removing the
+	// plugins that were used least recently.
+	MaxSizeMB int64 `yaml:"maxSizeMB,omitempty"`
+	// UnusedForDays removes the plugins that have not been used for this many days, if it is not zero.
+	UnusedForDays int `yaml:"unusedForDays,omitempty"`
+	// Auto prunes the plugin cache with this policy whenever plugins are installed.
+	Auto bool `yaml:"auto,omitempty"`
+}
+
+// IsZero returns true if the policy has no limits, and so removes nothing.
+func (p PluginPrunePolicy) IsZero() bool {
+	return p.KeepLatest == 0 && p.MaxSizeMB == 0 && p.UnusedForDays == 0
+}
+
+// lastUsed returns when a plugin was last used, falling back to when it was installed if that is unknown.
+func lastUsed(plugin PluginInfo) time.Time {
+	if !plugin.LastUsedTime.IsZero() {
+		return plugin.LastUsedTime
+	}
+	return plugin.InstallTime
+}
+
+// SelectPluginsToPrune returns the plugins that the policy removes from the given plugins, which must have their file
+// metadata set. Plugins for which keep returns true are never removed; keep may be nil.
+func (p PluginPrunePolicy) SelectPluginsToPrune(plugins []PluginInfo, keep func(PluginInfo) bool,
+	now time.Time) []PluginInfo {
+
+	pruned := make(map[string]bool)
+	prune := func(plugin PluginInfo, reason string) {
+		if (keep != nil && keep(plugin)) || pruned[plugin.Dir()] {
+			return
+		}
+		logging.V(7).Infof("pruning %s plugin %s: %s", plugin.Kind, plugin, reason)
+		pruned[plugin.Dir()] = true
+	}
+
+	if p.KeepLatest > 0 {
+		byName := make(map[string][]PluginInfo)
+		for _, plugin := range plugins {
+			key := string(plugin.Kind) + "-" + plugin.Name
+			byName[key] = append(byName[key], plugin)
+		}
+		for _, versions := range byName {
+			if len(versions) <= p.KeepLatest {
+				continue
+			}
+			sort.Sort(sort.Reverse(SortedPluginInfo(versions)))
+			for _, plugin := range versions[p.KeepLatest:] {
+				prune(plugin, "newer versions are kept")
+			}
+		}
+	}
+
+	if p.UnusedForDays > 0 {
+		cutoff := now.Add(-time.Duration(p.UnusedForDays) * 24 * time.Hour)
+		for _, plugin := range plugins {
+			if used := lastUsed(plugin); !used.IsZero() && used.Before(cutoff) {
+				prune(plugin, "not used recently")
+			}
+		}
+	}
+
+	if p.MaxSizeMB > 0 {
+		var size int64
+		var remaining []PluginInfo
+		for _, plugin := range plugins {
+			if !pruned[plugin.Dir()] {
+				size += plugin.Size
+				remaining = append(remaining, plugin)
+			}
+		}
+		sort.SliceStable(remaining, func(i, j int) bool {
+			return lastUsed(remaining[i]).Before(lastUsed(remaining[j]))
+		})
+		for _, plugin := range remaining {
+			if size <= p.MaxSizeMB*1024*1024 {
+				break
+			}
+			prune(plugin, "the plugin cache is too large")
+			if pruned[plugin.Dir()] {
+				size -= plugin.Size
+			}
+		}
+	}
+
+	var result []PluginInfo
+	for _, plugin := range plugins {
+		if pruned[plugin.Dir()] {
+			result = append(result, plugin)
+		}
+	}
+	return result
+}
+
+// PrunePlugins removes the plugins that the policy selects from the plugin cache, except those for which keep returns
+// true, and returns the plugins that it removed.
+func PrunePlugins(policy PluginPrunePolicy, keep func(PluginInfo) bool) ([]PluginInfo, error) {
+	plugins, err := GetPluginsWithMetadata()
+	if err != nil {
+		return nil, errors.Wrap(err, "loading plugins")
+	}
+
+	var removed []PluginInfo
+	var result error
+	for _, plugin := range policy.SelectPluginsToPrune(plugins, keep, time.Now()) {
+		if err := plugin.Delete(); err != nil {
+			result = multierror.Append(result, errors.Wrapf(err, "failed to delete %s plugin %s", plugin.Kind, plugin))
+			continue
+		}
+		removed = append(removed, plugin)
+	}
+	return removed, result
+}
+
+// AutoPrunePlugins prunes the plugin cache with the policy in the plugin settings file, if it asks for the cache to
+// be pruned whenever plugins are installed. Plugins for which keep returns true are never removed.
+func AutoPrunePlugins(keep func(PluginInfo) bool) ([]PluginInfo, error) {
+	registries, err := GetPluginRegistries()
+	if err != nil {
+		return nil, err
+	}
+	if registries.Prune == nil || !registries.Prune.Auto || registries.Prune.IsZero() {
+		return nil, nil
+	}
+	return PrunePlugins(*registries.Prune, keep)
+}
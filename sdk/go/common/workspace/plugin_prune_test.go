@@ -0,0 +1,93 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectPluginsToPrune(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	plugin := func(name, version string, sizeMB int64, usedDaysAgo int) PluginInfo {
+		v := semver.MustParse(version)
+		return PluginInfo{
+			Kind:         ResourcePlugin,
+			Name:         name,
+			Version:      &v,
+			Size:         sizeMB * 1024 * 1024,
+			LastUsedTime: now.Add(-time.Duration(usedDaysAgo) * 24 * time.Hour),
+		}
+	}
+	names := func(plugins []PluginInfo) []string {
+		var result []string
+		for _, p := range plugins {
+			result = append(result, p.Dir())
+		}
+		return result
+	}
+
+	plugins := []PluginInfo{
+		plugin("aws", "4.0.0", 100, 1),
+		plugin("aws", "4.2.0", 100, 5),
+		plugin("aws", "4.1.0", 100, 30),
+		plugin("gcp", "5.0.0", 50, 200),
+		plugin("random", "3.0.0", 10, 2),
+	}
+
+	assert.Empty(t, PluginPrunePolicy{}.SelectPluginsToPrune(plugins, nil, now))
+
+	assert.Equal(t,
+		[]string{"resource-aws-v4.0.0", "resource-aws-v4.1.0"},
+		names(PluginPrunePolicy{KeepLatest: 1}.SelectPluginsToPrune(plugins, nil, now)))
+
+	assert.Equal(t,
+		[]string{"resource-gcp-v5.0.0"},
+		names(PluginPrunePolicy{UnusedForDays: 90}.SelectPluginsToPrune(plugins, nil, now)))
+
+	// The least recently used plugins are removed until the cache fits.
+	assert.Equal(t,
+		[]string{"resource-aws-v4.1.0", "resource-gcp-v5.0.0"},
+		names(PluginPrunePolicy{MaxSizeMB: 250}.SelectPluginsToPrune(plugins, nil, now)))
+
+	// Policies combine, and the size limit accounts for the plugins that the other policies remove.
+	assert.Equal(t,
+		[]string{"resource-aws-v4.0.0", "resource-aws-v4.1.0", "resource-gcp-v5.0.0"},
+		names(PluginPrunePolicy{KeepLatest: 1, UnusedForDays: 90, MaxSizeMB: 200}.
+			SelectPluginsToPrune(plugins, nil, now)))
+
+	// Kept plugins are never removed, even to meet the size limit.
+	keep := func(p PluginInfo) bool { return p.Name == "gcp" }
+	assert.Equal(t,
+		[]string{"resource-aws-v4.2.0", "resource-aws-v4.1.0"},
+		names(PluginPrunePolicy{MaxSizeMB: 200}.SelectPluginsToPrune(plugins, keep, now)))
+}
+
+func TestGetPluginRegistriesPrunePolicy(t *testing.T) {
+	restore := writePluginRegistries(t, `
+prune:
+  keepLatest: 2
+  maxSizeMB: 4096
+  auto: true
+`)
+	defer restore()
+
+	registries, err := GetPluginRegistries()
+	assert.NoError(t, err)
+	assert.Equal(t, &PluginPrunePolicy{KeepLatest: 2, MaxSizeMB: 4096, Auto: true}, registries.Prune)
+}
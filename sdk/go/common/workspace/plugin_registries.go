@@ -0,0 +1,227 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/pulumi/pulumi/sdk/v3/go/common/util/contract"
+)
+
+// PluginRegistriesFile is the name of the file in the Pulumi home directory that configures the servers that plugins
+// are downloaded from.
+const PluginRegistriesFile = "plugins.yaml"
+
+// PluginRegistries configures how plugins are downloaded from private plugin servers, such as internal mirrors of
+// Pulumi's provider plugins. It is read from ~/.pulumi/plugins.yaml:
+//
+//     registries:
+//       - host: plugins.example.com
+//         token: ${PLUGIN_MIRROR_TOKEN}
+//         verifyChecksums: true
+//       - host: artifacts.example.com:8443
+//         username: ci
+//         password: ${ARTIFACTS_PASSWORD}
+//     checksums:
+//       pulumi-resource-aws-v4.1.0-linux-amd64.tar.gz: <hex-encoded SHA-256 checksum of the archive>
+//     prune:
+//       keepLatest: 2
+//       maxSizeMB: 4096
+//       auto: true
+//
+// Credentials may refer to environment variables, so that they need not be stored in the file.
+type PluginRegistries struct {
+	// Registries configures authentication and verification for each plugin server.
+	Registries []PluginRegistry `yaml:"registries,omitempty"`
+	// Checksums maps the file names of plugin archives to their expected hex-encoded SHA-256 checksums. An archive
+	// that is listed here is verified whichever server it is downloaded from.
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+	// Prune is the default policy of `pulumi plugin prune`, and, if it is automatic, is applied after installs.
+	Prune *PluginPrunePolicy `yaml:"prune,omitempty"`
+}
+
+// PluginRegistry configures how plugins are downloaded from a single plugin server.
+type PluginRegistry struct {
+	// Host is the host, and port if it is not the default, of the server's URLs.
+	Host string `yaml:"host"`
+	// Token is sent as a bearer token with each request to the server.
+	Token string `yaml:"token,omitempty"`
+	// Username and Password are sent with basic authentication with each request to the server.
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// VerifyChecksums requires that the server publishes a `<archive>.sha256` file alongside each plugin archive, and
+	// that each archive that is downloaded matches it.
+	VerifyChecksums bool `yaml:"verifyChecksums,omitempty"`
+}
+
+// getPluginRegistriesPath returns the path to the plugin registries file.
+func getPluginRegistriesPath() (string, error) {
+	return GetPulumiPath(PluginRegistriesFile)
+}
+
+// GetPluginRegistries reads the plugin registries file, if there is one.
+func GetPluginRegistries() (PluginRegistries, error) {
+	path, err := getPluginRegistriesPath()
+	if err != nil {
+		return PluginRegistries{}, err
+	}
+
+	b, err := readFileStripUTF8BOM(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return PluginRegistries{}, nil
+		}
+		return PluginRegistries{}, errors.Wrapf(err, "reading '%s'", path)
+	}
+
+	var registries PluginRegistries
+	if err = yaml.Unmarshal(b, &registries); err != nil {
+		return PluginRegistries{}, errors.Wrapf(err, "failed to read plugin registries file '%s'", path)
+	}
+	for i, r := range registries.Registries {
+		if r.Host == "" {
+			return PluginRegistries{}, errors.Errorf("%s: registry %d has no host", path, i)
+		}
+		if r.Token != "" && (r.Username != "" || r.Password != "") {
+			return PluginRegistries{}, errors.Errorf("%s: registry '%s' may use either token or username and "+
+				"password authentication, not both", path, r.Host)
+		}
+	}
+	return registries, nil
+}
+
+// registryFor returns the configuration of the server of the given URL, if there is one.
+func (r PluginRegistries) registryFor(u *url.URL) (PluginRegistry, bool) {
+	for _, reg := range r.Registries {
+		if strings.EqualFold(reg.Host, u.Host) {
+			return reg, true
+		}
+	}
+	return PluginRegistry{}, false
+}
+
+// authorize adds the credentials, if any, for the registry to the given request.
+func (reg PluginRegistry) authorize(req *http.Request) {
+	switch {
+	case reg.Token != "":
+		req.Header.Set("Authorization", "Bearer "+os.ExpandEnv(reg.Token))
+	case reg.Username != "":
+		req.SetBasicAuth(os.ExpandEnv(reg.Username), os.ExpandEnv(reg.Password))
+	}
+}
+
+// expectedChecksum returns the checksum that the plugin archive at the given URL must have, if it must be verified.
+// The checksum is looked up in the registries file's checksums, and then, if the server's registry verifies
+// checksums, fetched from the server.
+func (r PluginRegistries) expectedChecksum(endpoint *url.URL, archive string,
+	get func(*http.Request) (*http.Response, error)) (string, error) {
+
+	if sum, ok := r.Checksums[archive]; ok {
+		return strings.ToLower(sum), nil
+	}
+
+	reg, ok := r.registryFor(endpoint)
+	if !ok || !reg.VerifyChecksums {
+		return "", nil
+	}
+
+	sumURL := *endpoint
+	sumURL.Path += ".sha256"
+	sumURL.RawPath = ""
+	if endpoint.RawPath != "" {
+		sumURL.RawPath = endpoint.RawPath + ".sha256"
+	}
+	req, err := http.NewRequest("GET", sumURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	reg.authorize(req)
+	resp, err := get(req)
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching checksum of %s", archive)
+	}
+	defer contract.IgnoreClose(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", errors.Errorf("%d HTTP error fetching checksum from %s", resp.StatusCode, sumURL.String())
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching checksum of %s", archive)
+	}
+
+	// Accept both a bare checksum and the `<checksum>  <file>` format written by sha256sum.
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return "", errors.Errorf("empty checksum file at %s", sumURL.String())
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+// downloadVerified downloads a plugin archive to a temporary file and checks it against the expected checksum, so
+// that nothing is extracted from an archive that does not match. It returns a reader of the archive that removes the
+// temporary file when it is closed.
+func downloadVerified(body io.ReadCloser, archive, expected string) (io.ReadCloser, error) {
+	defer contract.IgnoreClose(body)
+
+	f, err := ioutil.TempFile("", "pulumi-plugin-*.tar.gz")
+	if err != nil {
+		return nil, err
+	}
+	verified := false
+	defer func() {
+		if !verified {
+			contract.IgnoreClose(f)
+			contract.IgnoreError(os.Remove(f.Name()))
+		}
+	}()
+
+	hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, hash), body); err != nil {
+		return nil, errors.Wrapf(err, "downloading %s", archive)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", archive, expected, actual)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	verified = true
+	return &tempFileReader{File: f}, nil
+}
+
+// tempFileReader reads a temporary file, and removes it when it is closed.
+type tempFileReader struct {
+	*os.File
+}
+
+func (r *tempFileReader) Close() error {
+	err := r.File.Close()
+	if rmErr := os.Remove(r.Name()); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+	return err
+}
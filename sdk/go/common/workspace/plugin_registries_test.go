@@ -0,0 +1,176 @@
+// Copyright 2016-2021, Pulumi Corporation.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+)
+
+// writePluginRegistries points the Pulumi home directory at a temporary directory that contains the given plugin
+// registries file, returning a function that restores it.
+func writePluginRegistries(t *testing.T, contents string) func() {
+	dir, err := ioutil.TempDir("", "pulumi-home")
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, PluginRegistriesFile), []byte(contents), 0600)
+	assert.NoError(t, err)
+
+	old, had := os.LookupEnv(PulumiHomeEnvVar)
+	os.Setenv(PulumiHomeEnvVar, dir)
+	return func() {
+		if had {
+			os.Setenv(PulumiHomeEnvVar, old)
+		} else {
+			os.Unsetenv(PulumiHomeEnvVar)
+		}
+		os.RemoveAll(dir)
+	}
+}
+
+func TestGetPluginRegistries(t *testing.T) {
+	restore := writePluginRegistries(t, `
+registries:
+  - host: plugins.example.com
+    token: ${TEST_PLUGIN_TOKEN}
+    verifyChecksums: true
+  - host: artifacts.example.com:8443
+    username: ci
+    password: hunter2
+checksums:
+  pulumi-resource-aws-v4.1.0-linux-amd64.tar.gz: ABCDEF
+`)
+	defer restore()
+
+	registries, err := GetPluginRegistries()
+	assert.NoError(t, err)
+	assert.Len(t, registries.Registries, 2)
+	assert.Equal(t, "ABCDEF", registries.Checksums["pulumi-resource-aws-v4.1.0-linux-amd64.tar.gz"])
+
+	os.Setenv("TEST_PLUGIN_TOKEN", "s3cr3t")
+	defer os.Unsetenv("TEST_PLUGIN_TOKEN")
+
+	u, err := url.Parse("https://plugins.example.com/releases/pulumi-resource-aws-v4.1.0-linux-amd64.tar.gz")
+	assert.NoError(t, err)
+	reg, ok := registries.registryFor(u)
+	assert.True(t, ok)
+	req := httptest.NewRequest("GET", u.String(), nil)
+	reg.authorize(req)
+	assert.Equal(t, "Bearer s3cr3t", req.Header.Get("Authorization"))
+
+	u, err = url.Parse("https://artifacts.example.com:8443/pulumi-resource-gcp-v5.0.0-linux-amd64.tar.gz")
+	assert.NoError(t, err)
+	reg, ok = registries.registryFor(u)
+	assert.True(t, ok)
+	req = httptest.NewRequest("GET", u.String(), nil)
+	reg.authorize(req)
+	username, password, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "ci", username)
+	assert.Equal(t, "hunter2", password)
+
+	u, err = url.Parse("https://get.pulumi.com/releases/plugins/pulumi-resource-aws-v4.1.0-linux-amd64.tar.gz")
+	assert.NoError(t, err)
+	_, ok = registries.registryFor(u)
+	assert.False(t, ok)
+}
+
+func TestGetPluginRegistriesRejectsAmbiguousAuth(t *testing.T) {
+	restore := writePluginRegistries(t, `
+registries:
+  - host: plugins.example.com
+    token: abc
+    username: ci
+`)
+	defer restore()
+
+	_, err := GetPluginRegistries()
+	assert.Error(t, err)
+}
+
+func TestDownloadFromPrivateRegistry(t *testing.T) {
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		t.Skip("plugins are not published for this architecture")
+	}
+
+	archive := fmt.Sprintf("pulumi-resource-test-v1.0.0-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+	contents := []byte("plugin archive")
+	sum := sha256.Sum256(contents)
+	checksum := hex.EncodeToString(sum[:])
+
+	served := contents
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch strings.TrimPrefix(r.URL.Path, "/") {
+		case archive:
+			_, err := w.Write(served)
+			assert.NoError(t, err)
+		case archive + ".sha256":
+			_, err := fmt.Fprintf(w, "%s  %s\n", checksum, archive)
+			assert.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.NoError(t, err)
+
+	version := semver.MustParse("1.0.0")
+	info := PluginInfo{Name: "test", Kind: ResourcePlugin, Version: &version, ServerURL: server.URL}
+
+	// Without credentials, the server refuses the download.
+	restore := writePluginRegistries(t, "")
+	_, _, err = info.Download()
+	assert.Error(t, err)
+	restore()
+
+	restore = writePluginRegistries(t, fmt.Sprintf(`
+registries:
+  - host: %s
+    token: s3cr3t
+    verifyChecksums: true
+`, u.Host))
+	defer restore()
+
+	tarball, _, err := info.Download()
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(tarball)
+	assert.NoError(t, err)
+	assert.Equal(t, contents, b)
+	assert.NoError(t, tarball.Close())
+
+	// If the archive doesn't match its checksum, the download fails before anything can be extracted from it.
+	served = []byte("tampered archive")
+	_, _, err = info.Download()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+}
@@ -236,12 +236,17 @@ func (info PluginInfo) Download() (io.ReadCloser, int64, error) {
 	logging.V(1).Infof("%s downloading from %s", info.Name, serverURL)
 
 	// URL escape the path value to ensure we have the correct path for S3/CloudFront.
-	endpoint := fmt.Sprintf("%s/%s",
-		serverURL,
-		url.QueryEscape(fmt.Sprintf("pulumi-%s-%s-v%s-%s-%s.tar.gz", info.Kind, info.Name, info.Version, os, arch)))
+	archive := fmt.Sprintf("pulumi-%s-%s-v%s-%s-%s.tar.gz", info.Kind, info.Name, info.Version, os, arch)
+	endpoint := fmt.Sprintf("%s/%s", serverURL, url.QueryEscape(archive))
 
 	logging.V(9).Infof("full plugin download url: %s", endpoint)
 
+	// Private plugin servers may require credentials, and their plugins may need to be checked against checksums.
+	registries, err := GetPluginRegistries()
+	if err != nil {
+		return nil, -1, err
+	}
+
 	req, err := http.NewRequest("GET", endpoint, nil)
 	if err != nil {
 		return nil, -1, err
@@ -252,7 +257,20 @@ func (info PluginInfo) Download() (io.ReadCloser, int64, error) {
 
 	logging.V(9).Infof("plugin install request headers: %v", req.Header)
 
-	resp, err := httputil.DoWithRetry(req, http.DefaultClient)
+	registry, hasRegistry := registries.registryFor(req.URL)
+	if hasRegistry {
+		registry.authorize(req)
+	}
+
+	get := func(req *http.Request) (*http.Response, error) {
+		return httputil.DoWithRetry(req, http.DefaultClient)
+	}
+	checksum, err := registries.expectedChecksum(req.URL, archive, get)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	resp, err := get(req)
 	if err != nil {
 		return nil, -1, err
 	}
@@ -260,9 +278,21 @@ func (info PluginInfo) Download() (io.ReadCloser, int64, error) {
 	logging.V(9).Infof("plugin install response headers: %v", resp.Header)
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		contract.IgnoreClose(resp.Body)
+		if hasRegistry && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			return nil, -1, errors.Errorf("%d HTTP error fetching plugin from %s; check the credentials for %s in %s",
+				resp.StatusCode, endpoint, registry.Host, PluginRegistriesFile)
+		}
 		return nil, -1, errors.Errorf("%d HTTP error fetching plugin from %s", resp.StatusCode, endpoint)
 	}
 
+	if checksum != "" {
+		tarball, err := downloadVerified(resp.Body, archive, checksum)
+		if err != nil {
+			return nil, -1, err
+		}
+		return tarball, resp.ContentLength, nil
+	}
 	return resp.Body, resp.ContentLength, nil
 }
 
@@ -435,6 +465,8 @@ type PluginKind string
 const (
 	// AnalyzerPlugin is a plugin that can be used as a resource analyzer.
 	AnalyzerPlugin PluginKind = "analyzer"
+	// EventSinkPlugin is a plugin that receives the engine events of an operation.
+	EventSinkPlugin PluginKind = "eventsink"
 	// LanguagePlugin is a plugin that can be used as a language host.
 	LanguagePlugin PluginKind = "language"
 	// ResourcePlugin is a plugin that can be used as a resource provider for custom CRUD operations.
@@ -444,7 +476,7 @@ const (
 // IsPluginKind returns true if k is a valid plugin kind, and false otherwise.
 func IsPluginKind(k string) bool {
 	switch PluginKind(k) {
-	case AnalyzerPlugin, LanguagePlugin, ResourcePlugin:
+	case AnalyzerPlugin, EventSinkPlugin, LanguagePlugin, ResourcePlugin:
 		return true
 	default:
 		return false
@@ -61,6 +61,12 @@ type ProjectBackend struct {
 type ProjectOptions struct {
 	// Refresh is the ability to always run a refresh as part of a pulumi update / preview / destroy
 	Refresh string `json:"refresh,omitempty" yaml:"refresh,omitempty"`
+	// InterpolateConfigEnv resolves `${env:VAR}` placeholders in plaintext stack config values from the environment
+	// when the config is loaded for an operation.
+	InterpolateConfigEnv bool `json:"interpolateConfigEnv,omitempty" yaml:"interpolateConfigEnv,omitempty"`
+	// SecretPatterns lists regular expressions matching values, such as access keys, that must always be secret.
+	// `pulumi stack audit-secrets` reports the plaintext values in a stack's state that match any of them.
+	SecretPatterns []string `json:"secretPatterns,omitempty" yaml:"secretPatterns,omitempty"`
 }
 
 // Project is a Pulumi project manifest.
@@ -98,6 +104,63 @@ type Project struct {
 
 	// Options is an optional set of project options
 	Options *ProjectOptions `json:"options,omitempty" yaml:"options,omitempty"`
+
+	// StackDependencies optionally maps the name of each stack to the names of the stacks of this project that it
+	// depends on, in addition to those discovered from its StackReferences.
+	StackDependencies map[string][]string `json:"stackDependencies,omitempty" yaml:"stackDependencies,omitempty"`
+
+	// ConfigSchema optionally declares the types and constraints of the config keys that the project's stacks set.
+	// Keys without a namespace are in the project's namespace.
+	ConfigSchema map[string]ProjectConfigType `json:"configSchema,omitempty" yaml:"configSchema,omitempty"`
+
+	// PolicyPacks optionally lists local policy packs that every preview, update and destroy of the project's stacks
+	// must run, in addition to any passed with --policy-pack.
+	PolicyPacks []ProjectPolicyPack `json:"policyPacks,omitempty" yaml:"policyPacks,omitempty"`
+
+	// Hooks optionally lists commands that the engine runs before or after the steps of the project's updates and
+	// destroys, in addition to the hooks of the stack being updated.
+	Hooks []StepHook `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+}
+
+// The times at which a step hook may run.
+const (
+	StepHookBefore = "before"
+	StepHookAfter  = "after"
+)
+
+// StepHook is a command that the engine runs before or after each step of an update that matches it, e.g. to drain a
+// node before it is deleted or to warm a cache after it is created. The command is run by the shell in the project's
+// directory, and is given the step as JSON on its standard input. A hook that fails before a step fails the step.
+type StepHook struct {
+	// When is either "before" or "after". Hooks that run after a step only run if the step succeeds.
+	When string `json:"when" yaml:"when"`
+	// Ops optionally limits the hook to steps with these operations, e.g. create, update, replace or delete.
+	Ops []string `json:"ops,omitempty" yaml:"ops,omitempty"`
+	// Type optionally limits the hook to resources whose type matches this glob, e.g. `kubernetes:core/v1:Node`.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// URN optionally limits the hook to resources whose URN matches this glob.
+	URN string `json:"urn,omitempty" yaml:"urn,omitempty"`
+	// Command is the command to run.
+	Command string `json:"command" yaml:"command"`
+}
+
+// Validate checks that a step hook runs at a valid time and has a command.
+func (h StepHook) Validate() error {
+	if h.When != StepHookBefore && h.When != StepHookAfter {
+		return errors.Errorf("hook 'when' must be %q or %q, not %q", StepHookBefore, StepHookAfter, h.When)
+	}
+	if h.Command == "" {
+		return errors.New("hook is missing a 'command' attribute")
+	}
+	return nil
+}
+
+// ProjectPolicyPack is a local policy pack that a project requires.
+type ProjectPolicyPack struct {
+	// Path is the path to the policy pack, relative to the project's directory.
+	Path string `json:"path" yaml:"path"`
+	// Config is an optional path to the policy pack's JSON config file, relative to the project's directory.
+	Config string `json:"config,omitempty" yaml:"config,omitempty"`
 }
 
 func (proj *Project) Validate() error {
@@ -107,8 +170,18 @@ func (proj *Project) Validate() error {
 	if proj.Runtime.Name() == "" {
 		return errors.New("project is missing a 'runtime' attribute")
 	}
+	for i, pack := range proj.PolicyPacks {
+		if pack.Path == "" {
+			return errors.Errorf("project policy pack %d is missing a 'path' attribute", i)
+		}
+	}
+	for i, hook := range proj.Hooks {
+		if err := hook.Validate(); err != nil {
+			return errors.Wrapf(err, "project hook %d", i)
+		}
+	}
 
-	return nil
+	return proj.validateConfigSchema()
 }
 
 // TrustResourceDependencies returns whether or not this project's runtime can be trusted to accurately report
@@ -187,6 +260,60 @@ type ProjectStack struct {
 	EncryptionSalt string `json:"encryptionsalt,omitempty" yaml:"encryptionsalt,omitempty"`
 	// Config is an optional config bag.
 	Config config.Map `json:"config,omitempty" yaml:"config,omitempty"`
+	// RequireDestroyConfirmation, when true, requires `pulumi destroy` to be confirmed by typing the stack's name, even
+	// when --yes is passed. Non-interactive destroys must instead set PULUMI_DESTROY_CONFIRMATION to a one-time token
+	// issued by `pulumi stack destroy-token`.
+	// nolint: lll
+	RequireDestroyConfirmation bool `json:"requireDestroyConfirmation,omitempty" yaml:"requireDestroyConfirmation,omitempty"`
+	// RefreshIgnoreChanges lists property paths whose changes are not adopted by `pulumi refresh`, e.g. credentials
+	// that are rotated outside of Pulumi.
+	RefreshIgnoreChanges []string `json:"refreshIgnoreChanges,omitempty" yaml:"refreshIgnoreChanges,omitempty"`
+	// Hooks lists commands that the engine runs before or after the steps of the stack's updates and destroys, in
+	// addition to the project's hooks.
+	Hooks []StepHook `json:"hooks,omitempty" yaml:"hooks,omitempty"`
+	// ApprovalGates lists the steps that must each be approved interactively while the stack is updated or destroyed,
+	// even when the operation as a whole is approved with --yes.
+	ApprovalGates []ApprovalGate `json:"approvalGates,omitempty" yaml:"approvalGates,omitempty"`
+	// RolloutWaves stages the stack's updates: the steps of each wave's resources are performed before those of the
+	// next wave, whose steps wait until the wave has completed and passed its checks.
+	RolloutWaves []RolloutWave `json:"rolloutWaves,omitempty" yaml:"rolloutWaves,omitempty"`
+}
+
+// ApprovalGate requires the steps of an update that match it to be approved one by one, e.g. every delete of a
+// database.
+type ApprovalGate struct {
+	// Type is a glob that matches the types of the gated resources, e.g. `aws:rds/instance:Instance`.
+	Type string `json:"type" yaml:"type"`
+	// Ops optionally limits the gate to steps with these operations, e.g. replace or delete. By default, every step
+	// that changes a gated resource must be approved.
+	Ops []string `json:"ops,omitempty" yaml:"ops,omitempty"`
+}
+
+// RolloutWave is a stage of a staged rollout, e.g. the canary instances of a fleet that are updated before the rest.
+type RolloutWave struct {
+	// Name names the wave in prompts and errors.
+	Name string `json:"name" yaml:"name"`
+	// Types are globs that match the types of the wave's resources.
+	Types []string `json:"types,omitempty" yaml:"types,omitempty"`
+	// URNs are globs that match the URNs of the wave's resources, which lets individual resources be put in a wave.
+	URNs []string `json:"urns,omitempty" yaml:"urns,omitempty"`
+	// HealthCheck is an optional command that is run once the wave's steps have completed, i.e. once each of its
+	// resources that a preview of the update deploys has been deployed. If it fails, the update fails without starting
+	// the next wave.
+	HealthCheck string `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+	// Pause asks for confirmation once the wave's steps have completed, before the next wave is started.
+	Pause bool `json:"pause,omitempty" yaml:"pause,omitempty"`
+}
+
+// Validate checks that a rollout wave names its resources.
+func (w RolloutWave) Validate() error {
+	if w.Name == "" {
+		return errors.New("rollout wave is missing a 'name' attribute")
+	}
+	if len(w.Types) == 0 && len(w.URNs) == 0 {
+		return errors.Errorf("rollout wave %q has neither 'types' nor 'urns'", w.Name)
+	}
+	return nil
 }
 
 // Save writes a project definition to a file.
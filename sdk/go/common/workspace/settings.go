@@ -18,10 +18,13 @@ package workspace
 type Settings struct {
 	// Stack is an optional default stack to use.
 	Stack string `json:"stack,omitempty" yaml:"env,omitempty"`
+	// StackAliases maps short names to the stack names that they stand for, e.g. "prod" to
+	// "acmecorp/website/production".
+	StackAliases map[string]string `json:"stackAliases,omitempty" yaml:"stackAliases,omitempty"`
 }
 
-// IsEmpty returns true when the settings object is logically empty (no selected stack and nothing in the deprecated
-// configuration bag).
+// IsEmpty returns true when the settings object is logically empty (no selected stack, no stack aliases, and nothing in
+// the deprecated configuration bag).
 func (s *Settings) IsEmpty() bool {
-	return s.Stack == ""
+	return s.Stack == "" && len(s.StackAliases) == 0
 }
@@ -822,6 +822,7 @@ func (ctx *Context) registerResource(
 				Version:                 inputs.version,
 				Remote:                  remote,
 				ReplaceOnChanges:        inputs.replaceOnChanges,
+				DeletedWith:             inputs.deletedWith,
 			})
 			if err != nil {
 				logging.V(9).Infof("RegisterResource(%s, %s): error: %v", t, name, err)
@@ -1165,6 +1166,7 @@ type resourceInputs struct {
 	additionalSecretOutputs []string
 	version                 string
 	replaceOnChanges        []string
+	deletedWith             string
 }
 
 // prepareResourceInputs prepares the inputs for a resource operation, shared between read and register.
@@ -1250,6 +1252,7 @@ func (ctx *Context) prepareResourceInputs(res Resource, props Input, t string, o
 		additionalSecretOutputs: resOpts.additionalSecretOutputs,
 		version:                 state.version,
 		replaceOnChanges:        resOpts.replaceOnChanges,
+		deletedWith:             string(resOpts.deletedWithURN),
 	}, nil
 }
 
@@ -1275,6 +1278,7 @@ type resourceOpts struct {
 	ignoreChanges           []string
 	additionalSecretOutputs []string
 	replaceOnChanges        []string
+	deletedWithURN          URN
 }
 
 // getOpts returns a set of resource options from an array of them. This includes the parent URN, any dependency URNs,
@@ -1302,6 +1306,15 @@ func (ctx *Context) getOpts(res Resource, t string, provider ProviderResource, o
 		parentURN = urn
 	}
 
+	var deletedWithURN URN
+	if opts.DeletedWith != nil {
+		urn, _, _, err := opts.DeletedWith.URN().awaitURN(context.TODO())
+		if err != nil {
+			return resourceOpts{}, err
+		}
+		deletedWithURN = urn
+	}
+
 	var depURNs []URN
 	if opts.DependsOn != nil {
 		depSet := urnSet{}
@@ -1349,6 +1362,7 @@ func (ctx *Context) getOpts(res Resource, t string, provider ProviderResource, o
 		ignoreChanges:           opts.IgnoreChanges,
 		additionalSecretOutputs: opts.AdditionalSecretOutputs,
 		replaceOnChanges:        opts.ReplaceOnChanges,
+		deletedWithURN:          deletedWithURN,
 	}, nil
 }
 
@@ -256,6 +256,9 @@ type resourceOptions struct {
 	CustomTimeouts *CustomTimeouts
 	// DeleteBeforeReplace, when set to true, ensures that this resource is deleted prior to replacement.
 	DeleteBeforeReplace bool
+	// DeletedWith is an optional resource whose deletion also deletes this resource. When both are deleted in the
+	// same deployment, Pulumi does not ask this resource's provider to delete it.
+	DeletedWith Resource
 	// DependsOn is an optional array of explicit dependencies on other resources.
 	DependsOn []func(ctx context.Context) (urnSet, error)
 	// IgnoreChanges ignores changes to any of the specified properties.
@@ -402,6 +405,15 @@ func DependsOnInputs(o ResourceArrayInput) ResourceOption {
 	})
 }
 
+// DeletedWith marks this resource as deleted along with the given resource, such as a Kubernetes object that is
+// removed when its namespace is. When both resources are deleted in the same deployment, Pulumi skips the provider
+// call to delete this resource.
+func DeletedWith(r Resource) ResourceOption {
+	return resourceOption(func(ro *resourceOptions) {
+		ro.DeletedWith = r
+	})
+}
+
 // Ignore changes to any of the specified properties.
 func IgnoreChanges(o []string) ResourceOption {
 	return resourceOption(func(ro *resourceOptions) {
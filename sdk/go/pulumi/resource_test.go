@@ -183,6 +183,19 @@ func TestResourceOptionMergingDeleteBeforeReplace(t *testing.T) {
 	assert.Equal(t, false, opts.DeleteBeforeReplace)
 }
 
+func TestResourceOptionMergingDeletedWith(t *testing.T) {
+	r1 := &testRes{foo: "a"}
+	r2 := &testRes{foo: "b"}
+
+	// last value wins
+	opts := merge(DeletedWith(r1), DeletedWith(r2))
+	assert.Equal(t, r2, opts.DeletedWith)
+
+	// second value nil
+	opts = merge(DeletedWith(r1), DeletedWith(nil))
+	assert.Nil(t, opts.DeletedWith)
+}
+
 func TestResourceOptionMergingImport(t *testing.T) {
 	id1 := ID("a")
 	id2 := ID("a")
@@ -307,6 +307,7 @@ type RegisterResourceRequest struct {
 	AcceptResources            bool                                                     `protobuf:"varint,21,opt,name=acceptResources,proto3" json:"acceptResources,omitempty"`
 	Providers                  map[string]string                                        `protobuf:"bytes,22,rep,name=providers,proto3" json:"providers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	ReplaceOnChanges           []string                                                 `protobuf:"bytes,23,rep,name=replaceOnChanges,proto3" json:"replaceOnChanges,omitempty"`
+	DeletedWith                string                                                   `protobuf:"bytes,24,opt,name=deletedWith,proto3" json:"deletedWith,omitempty"`
 	XXX_NoUnkeyedLiteral       struct{}                                                 `json:"-"`
 	XXX_unrecognized           []byte                                                   `json:"-"`
 	XXX_sizecache              int32                                                    `json:"-"`
@@ -498,6 +499,13 @@ func (m *RegisterResourceRequest) GetReplaceOnChanges() []string {
 	return nil
 }
 
+func (m *RegisterResourceRequest) GetDeletedWith() string {
+	if m != nil {
+		return m.DeletedWith
+	}
+	return ""
+}
+
 // PropertyDependencies describes the resources that a particular property depends on.
 type RegisterResourceRequest_PropertyDependencies struct {
 	Urns                 []string `protobuf:"bytes,1,rep,name=urns,proto3" json:"urns,omitempty"`
@@ -792,71 +800,71 @@ func init() {
 func init() { proto.RegisterFile("resource.proto", fileDescriptor_d1b72f771c35e3b8) }
 
 var fileDescriptor_d1b72f771c35e3b8 = []byte{
-	// 1010 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0x5f, 0x6f, 0x1b, 0x45,
-	0x10, 0x8f, 0xed, 0xd4, 0xb1, 0x27, 0xa9, 0x13, 0x36, 0xa9, 0xbd, 0x3d, 0x50, 0x08, 0x07, 0x0f,
-	0xa6, 0x0f, 0x4e, 0x13, 0x90, 0x9a, 0xa2, 0x02, 0x12, 0x69, 0x41, 0x7d, 0x28, 0x29, 0x17, 0x84,
-	0x00, 0x09, 0xa4, 0x8d, 0x6f, 0x92, 0x1e, 0x39, 0xdf, 0x5e, 0x77, 0xf7, 0x22, 0xf9, 0x0d, 0xbe,
-	0x07, 0x9f, 0x06, 0xf1, 0xca, 0x77, 0x42, 0xbb, 0x7b, 0xeb, 0xde, 0xf9, 0xce, 0x89, 0xd3, 0xbe,
-	0xdd, 0xfc, 0xe6, 0x9f, 0x77, 0xe6, 0x37, 0xb3, 0x6b, 0xe8, 0x09, 0x94, 0x3c, 0x13, 0x63, 0x1c,
-	0xa5, 0x82, 0x2b, 0x4e, 0xba, 0x69, 0x16, 0x67, 0x93, 0x48, 0xa4, 0x63, 0xef, 0xfd, 0x0b, 0xce,
-	0x2f, 0x62, 0xdc, 0x37, 0x8a, 0xb3, 0xec, 0x7c, 0x1f, 0x27, 0xa9, 0x9a, 0x5a, 0x3b, 0xef, 0x83,
-	0x79, 0xa5, 0x54, 0x22, 0x1b, 0xab, 0x5c, 0xdb, 0x4b, 0x05, 0xbf, 0x8a, 0x42, 0x14, 0x56, 0xf6,
-	0x87, 0xd0, 0x3f, 0xcd, 0xd2, 0x94, 0x0b, 0x25, 0xbf, 0x45, 0xa6, 0x32, 0x81, 0x01, 0xbe, 0xce,
-	0x50, 0x2a, 0xd2, 0x83, 0x66, 0x14, 0xd2, 0xc6, 0x5e, 0x63, 0xd8, 0x0d, 0x9a, 0x51, 0xe8, 0x3f,
-	0x86, 0x41, 0xc5, 0x52, 0xa6, 0x3c, 0x91, 0x48, 0x76, 0x01, 0x5e, 0x31, 0x99, 0x6b, 0x8d, 0x4b,
-	0x27, 0x28, 0x20, 0xfe, 0xdf, 0x2d, 0xd8, 0x0e, 0x90, 0x85, 0x41, 0x7e, 0xa2, 0x05, 0x29, 0x08,
-	0x81, 0x55, 0x35, 0x4d, 0x91, 0x36, 0x0d, 0x62, 0xbe, 0x35, 0x96, 0xb0, 0x09, 0xd2, 0x96, 0xc5,
-	0xf4, 0x37, 0xe9, 0x43, 0x3b, 0x65, 0x02, 0x13, 0x45, 0x57, 0x0d, 0x9a, 0x4b, 0xe4, 0x11, 0x40,
-	0x2a, 0x78, 0x8a, 0x42, 0x45, 0x28, 0xe9, 0x9d, 0xbd, 0xc6, 0x70, 0xfd, 0x70, 0x30, 0xb2, 0xf5,
-	0x18, 0xb9, 0x7a, 0x8c, 0x4e, 0x4d, 0x3d, 0x82, 0x82, 0x29, 0xf1, 0x61, 0x23, 0xc4, 0x14, 0x93,
-	0x10, 0x93, 0xb1, 0x76, 0x6d, 0xef, 0xb5, 0x86, 0xdd, 0xa0, 0x84, 0x11, 0x0f, 0x3a, 0xae, 0x76,
-	0x74, 0xcd, 0xa4, 0x9d, 0xc9, 0x84, 0xc2, 0xda, 0x15, 0x0a, 0x19, 0xf1, 0x84, 0x76, 0x8c, 0xca,
-	0x89, 0xe4, 0x13, 0xb8, 0xcb, 0xc6, 0x63, 0x4c, 0xd5, 0x29, 0x8e, 0x05, 0x2a, 0x49, 0xbb, 0xa6,
-	0x3a, 0x65, 0x90, 0x1c, 0xc1, 0x80, 0x85, 0x61, 0xa4, 0x22, 0x9e, 0xb0, 0xd8, 0x82, 0x27, 0x99,
-	0x4a, 0x33, 0x25, 0x29, 0x98, 0x9f, 0xb2, 0x48, 0xad, 0x33, 0xb3, 0x38, 0x62, 0x12, 0x25, 0x5d,
-	0x37, 0x96, 0x4e, 0x24, 0x43, 0xd8, 0xb4, 0x49, 0x5c, 0xd5, 0x25, 0xdd, 0x30, 0xb9, 0xe7, 0x61,
-	0x9f, 0xc1, 0x4e, 0xb9, 0x3b, 0x79, 0x5b, 0xb7, 0xa0, 0x95, 0x89, 0x24, 0xef, 0x8f, 0xfe, 0x9c,
-	0x2b, 0x70, 0x73, 0xe9, 0x02, 0xfb, 0xff, 0x01, 0x0c, 0x02, 0xbc, 0x88, 0xa4, 0x42, 0x31, 0xcf,
-	0x02, 0xd7, 0xf5, 0x46, 0x4d, 0xd7, 0x9b, 0xb5, 0x5d, 0x6f, 0x95, 0xba, 0xde, 0x87, 0xf6, 0x38,
-	0x93, 0x8a, 0x4f, 0x0c, 0x1b, 0x3a, 0x41, 0x2e, 0x91, 0x7d, 0x68, 0xf3, 0xb3, 0x3f, 0x70, 0xac,
-	0x6e, 0x62, 0x42, 0x6e, 0xa6, 0x6b, 0xa9, 0x55, 0xda, 0xa3, 0x6d, 0x22, 0x39, 0xb1, 0xc2, 0x8f,
-	0xb5, 0x1b, 0xf8, 0xd1, 0x99, 0xe3, 0x47, 0x0a, 0x3b, 0x79, 0x31, 0xa6, 0x4f, 0x8b, 0x71, 0xba,
-	0x7b, 0xad, 0xe1, 0xfa, 0xe1, 0x93, 0xd1, 0x6c, 0xb4, 0x47, 0x0b, 0x8a, 0x34, 0x7a, 0x59, 0xe3,
-	0xfe, 0x2c, 0x51, 0x62, 0x1a, 0xd4, 0x46, 0x26, 0x0f, 0x61, 0x3b, 0xc4, 0x18, 0x15, 0x7e, 0x83,
-	0xe7, 0x5c, 0x8f, 0x6a, 0x1a, 0xb3, 0x31, 0x52, 0x30, 0xe7, 0xaa, 0x53, 0x15, 0x39, 0xbc, 0x5e,
-	0xe1, 0x70, 0x74, 0x91, 0x70, 0x81, 0xc7, 0xaf, 0x58, 0x72, 0x61, 0x78, 0xa4, 0x8f, 0x5f, 0x06,
-	0xab, 0x4c, 0xbf, 0x7b, 0x4b, 0xa6, 0xf7, 0x96, 0x66, 0xfa, 0x66, 0x99, 0xe9, 0x1e, 0x74, 0xa2,
-	0x89, 0x5e, 0x34, 0xcf, 0x43, 0xba, 0x65, 0x2b, 0xef, 0x64, 0xf2, 0x0b, 0xf4, 0x2c, 0x1d, 0x7e,
-	0x8c, 0x26, 0xc8, 0x75, 0x9a, 0xf7, 0x0c, 0x19, 0x0e, 0x96, 0xa8, 0xf9, 0x71, 0xc9, 0x31, 0x98,
-	0x0b, 0x44, 0xbe, 0x02, 0xaf, 0xa6, 0x8e, 0x4f, 0xf1, 0x3c, 0x4a, 0x30, 0xa4, 0xc4, 0x9c, 0xfe,
-	0x1a, 0x0b, 0xf2, 0x39, 0xdc, 0x93, 0xf9, 0x42, 0x7d, 0xc9, 0x84, 0x8a, 0x58, 0xfc, 0x13, 0x8b,
-	0x33, 0x94, 0x74, 0xdb, 0xb8, 0xd6, 0x2b, 0x35, 0xdb, 0x05, 0x4e, 0xb8, 0x42, 0xba, 0x63, 0xd9,
-	0x6e, 0xa5, 0xba, 0x71, 0xbf, 0x57, 0x3b, 0xee, 0xe4, 0x04, 0xba, 0x8e, 0x98, 0x92, 0xf6, 0x0d,
-	0x03, 0x0f, 0x96, 0x63, 0xa0, 0xf5, 0xb1, 0xb4, 0x7b, 0x13, 0x83, 0x3c, 0x80, 0x2d, 0x61, 0x8f,
-	0x76, 0x92, 0x38, 0x8a, 0x0c, 0x4c, 0x8b, 0x2a, 0xb8, 0xf7, 0x00, 0x76, 0xea, 0xa8, 0xac, 0x07,
-	0x3e, 0x13, 0x89, 0xa4, 0x0d, 0xe3, 0x67, 0xbe, 0xbd, 0x9f, 0xa1, 0x57, 0x6e, 0x81, 0x19, 0x75,
-	0x81, 0x4c, 0xb9, 0x65, 0x91, 0x4b, 0x1a, 0xcf, 0xd2, 0x50, 0xe3, 0x76, 0x61, 0xe4, 0x92, 0xc6,
-	0x6d, 0x03, 0xdc, 0xca, 0xb0, 0x92, 0xf7, 0x67, 0x03, 0xee, 0x2f, 0x9c, 0x28, 0xbd, 0xf7, 0x2e,
-	0x71, 0xea, 0xf6, 0xde, 0x25, 0x4e, 0xc9, 0x0b, 0xb8, 0x73, 0xa5, 0xcb, 0x9f, 0xaf, 0xbc, 0x47,
-	0x6f, 0x39, 0xb0, 0x81, 0x8d, 0xf2, 0x45, 0xf3, 0xa8, 0xe1, 0x3d, 0x81, 0x5e, 0xb9, 0xa2, 0x35,
-	0x69, 0x77, 0x8a, 0x69, 0xbb, 0x05, 0x6f, 0xff, 0x9f, 0x16, 0xd0, 0x6a, 0xe6, 0x85, 0x7b, 0xdb,
-	0x5e, 0xb4, 0xcd, 0xd9, 0x45, 0xfb, 0x66, 0x35, 0xb6, 0x96, 0x5b, 0x8d, 0x7d, 0x68, 0x4b, 0xc5,
-	0xce, 0x62, 0x74, 0x3b, 0xd6, 0x4a, 0x7a, 0x28, 0xed, 0x97, 0xbe, 0x6e, 0xcd, 0x50, 0xe6, 0x22,
-	0x79, 0xbd, 0x60, 0xe5, 0xb5, 0x0d, 0xe1, 0xbe, 0xbc, 0xb6, 0x82, 0xf6, 0x1c, 0xb7, 0xdd, 0x79,
-	0xb7, 0xe2, 0xd6, 0x5f, 0xb7, 0x64, 0xc0, 0xf7, 0x65, 0x06, 0x1c, 0xbd, 0xed, 0xef, 0x2f, 0x36,
-	0x11, 0x61, 0x77, 0xde, 0x37, 0x5f, 0x76, 0xee, 0x6a, 0xac, 0x76, 0xf2, 0x00, 0xd6, 0x78, 0xbe,
-	0x2f, 0x6f, 0xb8, 0x7e, 0x9d, 0xdd, 0xe1, 0xbf, 0xab, 0xb0, 0xe9, 0xe2, 0xbf, 0xe0, 0x49, 0xa4,
-	0xb8, 0x20, 0xbf, 0xc2, 0xe6, 0xdc, 0x63, 0x8e, 0x7c, 0x54, 0x38, 0x52, 0xfd, 0x93, 0xd0, 0xf3,
-	0xaf, 0x33, 0xb1, 0x87, 0xf6, 0x57, 0xc8, 0xd7, 0xd0, 0x7e, 0x9e, 0x5c, 0xf1, 0x4b, 0x24, 0xb4,
-	0x60, 0x6f, 0x21, 0x17, 0xe9, 0x7e, 0x8d, 0x66, 0x16, 0xe0, 0x3b, 0xd8, 0x38, 0x55, 0x02, 0xd9,
-	0xe4, 0x9d, 0xc2, 0x3c, 0x6c, 0x90, 0xc7, 0xb0, 0x7a, 0xcc, 0xe2, 0x98, 0xf4, 0x0b, 0x66, 0x1a,
-	0x70, 0xee, 0x83, 0x0a, 0x3e, 0xfb, 0x0d, 0x3f, 0xc0, 0x46, 0xf1, 0x4d, 0x44, 0x76, 0x4b, 0x0d,
-	0xaf, 0x3c, 0x65, 0xbd, 0x0f, 0x17, 0xea, 0x67, 0x21, 0x7f, 0x83, 0xad, 0xf9, 0x76, 0x13, 0xff,
-	0xe6, 0x4d, 0xe2, 0x7d, 0xbc, 0x04, 0xd7, 0xfc, 0x15, 0xf2, 0x7b, 0xf5, 0x85, 0xe5, 0xae, 0xce,
-	0x4f, 0xaf, 0x89, 0x50, 0x66, 0x9c, 0xd7, 0xaf, 0xd0, 0xe9, 0x99, 0xfe, 0x6f, 0xe1, 0xaf, 0x9c,
-	0xb5, 0x0d, 0xf2, 0xd9, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff, 0xf1, 0x1f, 0xaf, 0x2d, 0x98, 0x0c,
-	0x00, 0x00,
+	// 1018 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0x03, 0xa5, 0x57, 0xdd, 0x72, 0xdb, 0x44,
+	0x14, 0xae, 0xed, 0xd4, 0xb1, 0x8f, 0x53, 0x27, 0x6c, 0x5c, 0x5b, 0x15, 0x4c, 0x08, 0x2a, 0x17,
+	0xa1, 0x17, 0x4e, 0x13, 0x98, 0x69, 0xca, 0x14, 0x98, 0x21, 0x2d, 0x4c, 0x2f, 0x4a, 0x8a, 0xc2,
+	0x50, 0x60, 0x06, 0x66, 0x36, 0xd2, 0x89, 0x23, 0x2a, 0x4b, 0xea, 0x6a, 0x95, 0x19, 0xdf, 0xc1,
+	0x7b, 0xf0, 0x34, 0x0c, 0xef, 0xc3, 0x2b, 0xb0, 0x3f, 0x5a, 0x57, 0xb2, 0xe4, 0xc4, 0x29, 0x57,
+	0xde, 0xf3, 0xaf, 0x3d, 0xe7, 0xdb, 0x6f, 0xd7, 0xd0, 0x67, 0x98, 0xc6, 0x19, 0xf3, 0x70, 0x9c,
+	0xb0, 0x98, 0xc7, 0xa4, 0x9b, 0x64, 0x61, 0x36, 0x0d, 0x58, 0xe2, 0xd9, 0xef, 0x4f, 0xe2, 0x78,
+	0x12, 0xe2, 0xbe, 0x32, 0x9c, 0x65, 0xe7, 0xfb, 0x38, 0x4d, 0xf8, 0x4c, 0xfb, 0xd9, 0x1f, 0x2c,
+	0x1a, 0x53, 0xce, 0x32, 0x8f, 0xe7, 0xd6, 0xbe, 0xf8, 0xb9, 0x0c, 0x7c, 0x64, 0x5a, 0x76, 0xf6,
+	0x60, 0x78, 0x9a, 0x25, 0x49, 0xcc, 0x78, 0xfa, 0x0d, 0x52, 0x9e, 0x31, 0x74, 0xf1, 0x4d, 0x86,
+	0x29, 0x27, 0x7d, 0x68, 0x06, 0xbe, 0xd5, 0xd8, 0x6d, 0xec, 0x75, 0x5d, 0xb1, 0x72, 0x1e, 0xc3,
+	0xa8, 0xe2, 0x99, 0x26, 0x71, 0x94, 0x22, 0xd9, 0x01, 0xb8, 0xa0, 0x69, 0x6e, 0x55, 0x21, 0x1d,
+	0xb7, 0xa0, 0x71, 0xfe, 0x6a, 0xc1, 0xb6, 0x8b, 0xd4, 0x77, 0xf3, 0x1d, 0x2d, 0x29, 0x41, 0x08,
+	0xac, 0xf1, 0x59, 0x82, 0x56, 0x53, 0x69, 0xd4, 0x5a, 0xea, 0x22, 0x3a, 0x45, 0xab, 0xa5, 0x75,
+	0x72, 0x4d, 0x86, 0xd0, 0x4e, 0x28, 0xc3, 0x88, 0x5b, 0x6b, 0x4a, 0x9b, 0x4b, 0xe4, 0x11, 0x80,
+	0xd8, 0x55, 0x82, 0x8c, 0x07, 0x98, 0x5a, 0xb7, 0x85, 0xad, 0x77, 0x38, 0x1a, 0xeb, 0x7e, 0x8c,
+	0x4d, 0x3f, 0xc6, 0xa7, 0xaa, 0x1f, 0x6e, 0xc1, 0x95, 0x38, 0xb0, 0xe1, 0x63, 0x82, 0x91, 0x8f,
+	0x91, 0x27, 0x43, 0xdb, 0xbb, 0x2d, 0x91, 0xb6, 0xa4, 0x23, 0x36, 0x74, 0x4c, 0xef, 0xac, 0x75,
+	0x55, 0x76, 0x2e, 0x13, 0x0b, 0xd6, 0x2f, 0x91, 0xa5, 0x41, 0x1c, 0x59, 0x1d, 0x65, 0x32, 0x22,
+	0xf9, 0x18, 0xee, 0x50, 0xcf, 0xc3, 0x84, 0x9f, 0xa2, 0xc7, 0x90, 0xa7, 0x56, 0x57, 0x75, 0xa7,
+	0xac, 0x24, 0x47, 0x30, 0xa2, 0xbe, 0x1f, 0x70, 0x11, 0x41, 0x43, 0xad, 0x3c, 0xc9, 0x78, 0x92,
+	0x09, 0x7f, 0x50, 0x9f, 0xb2, 0xcc, 0x2c, 0x2b, 0xd3, 0x30, 0xa0, 0xa9, 0xf8, 0xe8, 0x9e, 0xf2,
+	0x34, 0x22, 0xd9, 0x83, 0x4d, 0x5d, 0xc4, 0x74, 0x3d, 0xb5, 0x36, 0x54, 0xed, 0x45, 0xb5, 0x43,
+	0x61, 0x50, 0x9e, 0x4e, 0x3e, 0xd6, 0x2d, 0x68, 0x65, 0x2c, 0xca, 0xe7, 0x23, 0x97, 0x0b, 0x0d,
+	0x6e, 0xae, 0xdc, 0x60, 0xe7, 0x5f, 0x80, 0x91, 0x8b, 0x93, 0x20, 0xe5, 0xc8, 0x16, 0x51, 0x60,
+	0xa6, 0xde, 0xa8, 0x99, 0x7a, 0xb3, 0x76, 0xea, 0xad, 0xd2, 0xd4, 0x85, 0xde, 0xcb, 0x52, 0x1e,
+	0x4f, 0x15, 0x1a, 0x3a, 0x6e, 0x2e, 0x91, 0x7d, 0x68, 0xc7, 0x67, 0xbf, 0xa3, 0xc7, 0xaf, 0x43,
+	0x42, 0xee, 0x26, 0x7b, 0x29, 0x4d, 0x32, 0xa2, 0xad, 0x32, 0x19, 0xb1, 0x82, 0x8f, 0xf5, 0x6b,
+	0xf0, 0xd1, 0x59, 0xc0, 0x47, 0x02, 0x83, 0xbc, 0x19, 0xb3, 0xa7, 0xc5, 0x3c, 0x5d, 0x91, 0xa7,
+	0x77, 0xf8, 0x64, 0x3c, 0x3f, 0xda, 0xe3, 0x25, 0x4d, 0x1a, 0xbf, 0xac, 0x09, 0x7f, 0x16, 0x71,
+	0x36, 0x73, 0x6b, 0x33, 0x93, 0x87, 0xb0, 0xed, 0x63, 0x88, 0x1c, 0xbf, 0xc6, 0xf3, 0x58, 0x1e,
+	0xd5, 0x24, 0xa4, 0x1e, 0x0a, 0x34, 0xc9, 0x7d, 0xd5, 0x99, 0x8a, 0x18, 0xee, 0x55, 0x30, 0x1c,
+	0x4c, 0x22, 0xe1, 0x7a, 0x7c, 0x41, 0xa3, 0x89, 0xc2, 0x91, 0xdc, 0x7e, 0x59, 0x59, 0x45, 0xfa,
+	0x9d, 0x1b, 0x22, 0xbd, 0xbf, 0x32, 0xd2, 0x37, 0xcb, 0x48, 0x17, 0x9d, 0x0f, 0xa6, 0x92, 0x68,
+	0x9e, 0xfb, 0xd6, 0x96, 0xee, 0xbc, 0x91, 0xc9, 0xcf, 0xd0, 0xd7, 0x70, 0xf8, 0x21, 0x98, 0x62,
+	0x2c, 0xcb, 0xbc, 0xa7, 0xc0, 0x70, 0xb0, 0x42, 0xcf, 0x8f, 0x4b, 0x81, 0xee, 0x42, 0x22, 0xf2,
+	0x25, 0xd8, 0x35, 0x7d, 0x7c, 0x8a, 0xe7, 0x41, 0x84, 0xbe, 0x45, 0xd4, 0xee, 0xaf, 0xf0, 0x20,
+	0x9f, 0xc1, 0xdd, 0x34, 0x27, 0xd4, 0x97, 0x54, 0x1c, 0x13, 0x1a, 0xfe, 0x48, 0x43, 0x51, 0xd8,
+	0xda, 0x56, 0xa1, 0xf5, 0x46, 0x89, 0x76, 0x86, 0x53, 0x01, 0x4b, 0x6b, 0xa0, 0xd1, 0xae, 0xa5,
+	0xba, 0xe3, 0x7e, 0xb7, 0xf6, 0xb8, 0x93, 0x13, 0xe8, 0x1a, 0x60, 0xa6, 0xd6, 0x50, 0x21, 0xf0,
+	0x60, 0x35, 0x04, 0xea, 0x18, 0x0d, 0xbb, 0xb7, 0x39, 0xc8, 0x03, 0xd8, 0x62, 0x7a, 0x6b, 0x27,
+	0x91, 0x81, 0xc8, 0x48, 0x8d, 0xa8, 0xa2, 0x27, 0xbb, 0xd0, 0xd3, 0x2d, 0xf1, 0x5f, 0x05, 0xfc,
+	0xc2, 0xb2, 0xd4, 0xb8, 0x8a, 0x2a, 0xfb, 0x01, 0x0c, 0xea, 0xc0, 0x2e, 0x29, 0x41, 0x50, 0x50,
+	0x2a, 0x68, 0x42, 0x66, 0x56, 0x6b, 0xfb, 0x27, 0xe8, 0x97, 0x87, 0xa4, 0xc8, 0x80, 0x89, 0xeb,
+	0xc9, 0xd0, 0x49, 0x2e, 0x49, 0x7d, 0x96, 0xf8, 0x52, 0xaf, 0x29, 0x25, 0x97, 0xa4, 0x5e, 0x17,
+	0x37, 0xa4, 0xa2, 0x25, 0xfb, 0x8f, 0x06, 0xdc, 0x5b, 0x7a, 0xe6, 0x24, 0x33, 0xbe, 0xc6, 0x99,
+	0x61, 0x46, 0xb1, 0x24, 0x2f, 0xe0, 0xf6, 0xa5, 0x1c, 0x50, 0x4e, 0x8a, 0x8f, 0xde, 0xf1, 0x48,
+	0xbb, 0x3a, 0xcb, 0xe7, 0xcd, 0xa3, 0x86, 0xfd, 0x04, 0xfa, 0xe5, 0x9e, 0xd7, 0x94, 0x1d, 0x14,
+	0xcb, 0x76, 0x0b, 0xd1, 0xce, 0xdf, 0x2d, 0xb0, 0xaa, 0x95, 0x97, 0x32, 0xbb, 0xbe, 0x8a, 0x9b,
+	0xf3, 0xab, 0xf8, 0x2d, 0x79, 0xb6, 0x56, 0x23, 0x4f, 0xd1, 0xc8, 0x94, 0xd3, 0xb3, 0x10, 0x0d,
+	0x0b, 0x6b, 0x49, 0x1e, 0x5b, 0xbd, 0x92, 0x17, 0xb2, 0x3a, 0xb6, 0xb9, 0x48, 0xde, 0x2c, 0x21,
+	0xc5, 0xb6, 0x82, 0xe4, 0x17, 0x57, 0x76, 0x50, 0xef, 0xe3, 0xa6, 0xac, 0x78, 0x23, 0x6c, 0xfd,
+	0x79, 0x43, 0x04, 0x7c, 0x57, 0x46, 0xc0, 0xd1, 0xbb, 0x7e, 0x7f, 0x71, 0x88, 0x08, 0x3b, 0x8b,
+	0xb1, 0x39, 0x1d, 0x9a, 0xcb, 0xb3, 0x3a, 0xc9, 0x03, 0x58, 0x8f, 0x73, 0x46, 0xbd, 0xe6, 0x82,
+	0x36, 0x7e, 0x87, 0xff, 0xac, 0xc1, 0xa6, 0xc9, 0xff, 0x22, 0x8e, 0x02, 0x1e, 0x33, 0xf2, 0x0b,
+	0x6c, 0x2e, 0x3c, 0xf7, 0xc8, 0x47, 0x85, 0x2d, 0xd5, 0x3f, 0x1a, 0x6d, 0xe7, 0x2a, 0x17, 0xbd,
+	0x69, 0xe7, 0x16, 0xf9, 0x0a, 0xda, 0xcf, 0xa3, 0xcb, 0xf8, 0xb5, 0x40, 0x47, 0xc1, 0x5f, 0xab,
+	0x4c, 0xa6, 0x7b, 0x35, 0x96, 0x79, 0x82, 0x6f, 0x61, 0x43, 0xec, 0x01, 0xe9, 0xf4, 0x7f, 0xa5,
+	0x79, 0xd8, 0x20, 0x8f, 0x61, 0xed, 0x98, 0x86, 0x21, 0x19, 0x16, 0xdc, 0xa4, 0xc2, 0x84, 0x8f,
+	0x2a, 0xfa, 0xf9, 0x37, 0x7c, 0x0f, 0x1b, 0xc5, 0x57, 0x13, 0xd9, 0x29, 0x0d, 0xbc, 0xf2, 0xd8,
+	0xb5, 0x3f, 0x5c, 0x6a, 0x9f, 0xa7, 0xfc, 0x15, 0xb6, 0x16, 0xc7, 0x4d, 0x9c, 0xeb, 0x99, 0xc4,
+	0xbe, 0xbf, 0x02, 0xd6, 0x44, 0xfa, 0xdf, 0xaa, 0x6f, 0x30, 0x73, 0xb9, 0x7e, 0x72, 0x45, 0x86,
+	0x32, 0xe2, 0xec, 0x61, 0x05, 0x4e, 0xcf, 0xe4, 0xbf, 0x0f, 0xe7, 0xd6, 0x59, 0x5b, 0x69, 0x3e,
+	0xfd, 0x0f, 0xda, 0xfb, 0x73, 0x9b, 0xba, 0x0c, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.